@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+var (
+	apiKeyName string
+	apiKeyRole string
+	apiKeyID   int64
+)
+
+// apikeyCmd represents the apikey command
+var apikeyCmd = &cobra.Command{
+	Use:     "apikey",
+	Aliases: []string{"apikeys"},
+	Short:   "Manage HTTP API keys",
+	Long: `Manage the API keys accepted by the HTTP API's /api/v1 routes.
+
+Only a hash of each key is stored, so a key is shown once, when it is created.
+Once any key exists (here or in server.api_keys), requests must send one as
+"Authorization: Bearer <key>" or "X-API-Key: <key>".
+
+Each key has a role:
+  read-only  GET requests only
+  operator   also generate, sync, undo, pause, and other changes
+  admin      also /api/v1/admin routes; keys in server.api_keys are admins
+
+Examples:
+  # Create a key for a dashboard
+  program-director apikey create --name grafana --role read-only
+
+  # Create a key that may trigger generations
+  program-director apikey create --name home-assistant --role operator
+
+  # List keys
+  program-director apikey list
+
+  # Revoke a key
+  program-director apikey revoke --id 3`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// apikeyCreateCmd creates an API key
+var apikeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key",
+	RunE:  runAPIKeyCreate,
+}
+
+// apikeyListCmd lists API keys
+var apikeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys",
+	RunE:  runAPIKeyList,
+}
+
+// apikeyRevokeCmd revokes an API key
+var apikeyRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke an API key",
+	RunE:  runAPIKeyRevoke,
+}
+
+func init() {
+	apikeyCmd.AddCommand(apikeyCreateCmd)
+	apikeyCmd.AddCommand(apikeyListCmd)
+	apikeyCmd.AddCommand(apikeyRevokeCmd)
+
+	apikeyCreateCmd.Flags().StringVar(&apiKeyName, "name", "", "what the key is for")
+	apikeyCreateCmd.Flags().StringVar(&apiKeyRole, "role", string(models.APIKeyRoleAdmin), "read-only, operator, or admin")
+	apikeyRevokeCmd.Flags().Int64Var(&apiKeyID, "id", 0, "ID of the key to revoke")
+}
+
+// apiKeyRepository opens the database and returns an API key repository
+func apiKeyRepository(ctx context.Context) (*repository.APIKeyRepository, func(), error) {
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize services: %w", err)
+	}
+	return repository.NewAPIKeyRepository(services.db), cleanup, nil
+}
+
+func runAPIKeyCreate(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	role, err := models.ParseAPIKeyRole(apiKeyRole)
+	if err != nil {
+		return err
+	}
+	apiKey, key, err := models.NewAPIKey(apiKeyName)
+	if err != nil {
+		return err
+	}
+	apiKey.Role = role
+
+	repo, cleanup, err := apiKeyRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := repo.Create(ctx, apiKey); err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	fmt.Printf("Created %s API key %d (%s). It will not be shown again:\n\n  %s\n", apiKey.Role, apiKey.ID, apiKey.Name, key)
+	return nil
+}
+
+func runAPIKeyList(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	repo, cleanup, err := apiKeyRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	keys, err := repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		fmt.Println("No API keys")
+		return nil
+	}
+
+	for _, k := range keys {
+		lastUsed := "never used"
+		if k.LastUsedAt != nil {
+			lastUsed = "last used " + k.LastUsedAt.Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%4d  %-24s %-9s created %s, %s\n", k.ID, k.Name, k.Role, k.CreatedAt.Format("2006-01-02"), lastUsed)
+	}
+
+	return nil
+}
+
+func runAPIKeyRevoke(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	if apiKeyID <= 0 {
+		return errors.New("--id is required")
+	}
+
+	repo, cleanup, err := apiKeyRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	removed, err := repo.Delete(ctx, apiKeyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("API key %d not found", apiKeyID)
+	}
+
+	fmt.Println("Revoked")
+	return nil
+}
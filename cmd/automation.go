@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+)
+
+var automationReason string
+
+// automationCmd represents the automation command
+var automationCmd = &cobra.Command{
+	Use:   "automation",
+	Short: "Pause or resume automated actions",
+	Long: `Pause or resume automated actions of serve instances sharing the database.
+
+While paused, scheduled jobs (generation, sync, history pruning, maintenance)
+skip their runs and Radarr/Sonarr webhooks don't refresh media, so Tunarr
+can be maintained without channel programming being rewritten meanwhile.
+Read endpoints, manual generation, and the CLI keep working.
+
+Examples:
+  # Pause before Tunarr maintenance
+  program-director automation pause --reason "tunarr upgrade"
+
+  # Show whether automation is paused
+  program-director automation status
+
+  # Resume afterwards
+  program-director automation resume`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// automationPauseCmd pauses automated actions
+var automationPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause scheduled jobs and webhook-triggered refreshes",
+	RunE:  runAutomationPause,
+}
+
+// automationResumeCmd resumes automated actions
+var automationResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume scheduled jobs and webhook-triggered refreshes",
+	RunE:  runAutomationResume,
+}
+
+// automationStatusCmd shows whether automated actions are paused
+var automationStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether automated actions are paused",
+	RunE:  runAutomationStatus,
+}
+
+func init() {
+	automationCmd.AddCommand(automationPauseCmd)
+	automationCmd.AddCommand(automationResumeCmd)
+	automationCmd.AddCommand(automationStatusCmd)
+
+	automationPauseCmd.Flags().StringVar(&automationReason, "reason", "", "why automation is paused")
+}
+
+// pauseRepository opens the database and returns a pause repository
+func pauseRepository(ctx context.Context) (*repository.PauseRepository, func(), error) {
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize services: %w", err)
+	}
+	return repository.NewPauseRepository(services.db), cleanup, nil
+}
+
+func runAutomationPause(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	repo, cleanup, err := pauseRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	pause, err := repo.Pause(ctx, automationReason)
+	if err != nil {
+		return fmt.Errorf("failed to pause automation: %w", err)
+	}
+
+	fmt.Printf("Automation paused since %s\n", pause.PausedAt.Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+func runAutomationResume(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	repo, cleanup, err := pauseRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resumed, err := repo.Resume(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resume automation: %w", err)
+	}
+	if !resumed {
+		fmt.Println("Automation was not paused")
+		return nil
+	}
+
+	fmt.Println("Automation resumed")
+	return nil
+}
+
+func runAutomationStatus(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	repo, cleanup, err := pauseRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	pause, err := repo.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get automation pause: %w", err)
+	}
+	if pause == nil {
+		fmt.Println("Automation running")
+		return nil
+	}
+
+	fmt.Printf("Automation paused since %s", pause.PausedAt.Format("2006-01-02 15:04:05"))
+	if pause.Reason != "" {
+		fmt.Printf(": %s", pause.Reason)
+	}
+	fmt.Println()
+	return nil
+}
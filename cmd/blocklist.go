@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+var (
+	blocklistMediaID int64
+	blocklistIMDBID  string
+	blocklistReason  string
+)
+
+// blocklistCmd represents the blocklist command
+var blocklistCmd = &cobra.Command{
+	Use:   "blocklist",
+	Short: "Manage media that is never scheduled",
+	Long: `Manage the global blocklist of media that is never scheduled on any channel.
+
+Entries block a library media ID or an IMDB ID and apply to every theme,
+including pinned titles, independent of per-theme excludes.
+
+Examples:
+  # List blocked media
+  program-director blocklist list
+
+  # Block a title by IMDB ID
+  program-director blocklist add --imdb-id tt0368226 --reason "never again"
+
+  # Unblock a media ID
+  program-director blocklist remove --media-id 42`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// blocklistListCmd lists blocked media
+var blocklistListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List blocked media",
+	RunE:  runBlocklistList,
+}
+
+// blocklistAddCmd blocks media
+var blocklistAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Block a media ID or IMDB ID",
+	RunE:  runBlocklistAdd,
+}
+
+// blocklistRemoveCmd unblocks media
+var blocklistRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Unblock a media ID or IMDB ID",
+	RunE:  runBlocklistRemove,
+}
+
+func init() {
+	blocklistCmd.AddCommand(blocklistListCmd)
+	blocklistCmd.AddCommand(blocklistAddCmd)
+	blocklistCmd.AddCommand(blocklistRemoveCmd)
+
+	for _, c := range []*cobra.Command{blocklistAddCmd, blocklistRemoveCmd} {
+		c.Flags().Int64Var(&blocklistMediaID, "media-id", 0, "library media ID")
+		c.Flags().StringVar(&blocklistIMDBID, "imdb-id", "", "IMDB ID (e.g. tt0368226)")
+	}
+	blocklistAddCmd.Flags().StringVar(&blocklistReason, "reason", "", "why the media is blocked")
+}
+
+// blocklistRepository opens the database and returns a blocklist repository
+func blocklistRepository(ctx context.Context) (*repository.BlocklistRepository, func(), error) {
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize services: %w", err)
+	}
+	return repository.NewBlocklistRepository(services.db), cleanup, nil
+}
+
+func runBlocklistList(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	repo, cleanup, err := blocklistRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	entries, err := repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list blocklist: %w", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No blocked media")
+		return nil
+	}
+
+	for _, e := range entries {
+		target := ""
+		if e.MediaID != nil {
+			target = fmt.Sprintf("media %d", *e.MediaID)
+		} else if e.IMDBID != nil {
+			target = *e.IMDBID
+		}
+		fmt.Printf("%-16s %s  %s\n", target, e.CreatedAt.Format("2006-01-02"), e.Reason)
+	}
+
+	return nil
+}
+
+func runBlocklistAdd(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	entry, err := models.NewBlocklistEntry(blocklistMediaID, blocklistIMDBID, blocklistReason)
+	if err != nil {
+		return err
+	}
+
+	repo, cleanup, err := blocklistRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	created, err := repo.Add(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to add blocklist entry: %w", err)
+	}
+	if !created {
+		fmt.Println("Already blocked")
+		return nil
+	}
+
+	fmt.Println("Blocked")
+	return nil
+}
+
+func runBlocklistRemove(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	if _, err := models.NewBlocklistEntry(blocklistMediaID, blocklistIMDBID, ""); err != nil {
+		return err
+	}
+
+	repo, cleanup, err := blocklistRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	removed, err := repo.Remove(ctx, blocklistMediaID, blocklistIMDBID)
+	if err != nil {
+		return fmt.Errorf("failed to remove blocklist entry: %w", err)
+	}
+	if removed == 0 {
+		return errors.New("not blocked")
+	}
+
+	fmt.Println("Unblocked")
+	return nil
+}
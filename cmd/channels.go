@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+)
+
+// channelsCmd represents the channels command
+var channelsCmd = &cobra.Command{
+	Use:   "channels",
+	Short: "Inspect Tunarr channels",
+	Long: `Inspect the channels configured in Tunarr.
+
+Examples:
+  # List channel IDs to copy into a theme's channel_id
+  program-director channels list`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// channelsListCmd lists Tunarr channels
+var channelsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List Tunarr channels with their IDs and the themes using them",
+	RunE:  runChannelsList,
+}
+
+func init() {
+	channelsCmd.AddCommand(channelsListCmd)
+}
+
+func runChannelsList(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	channels, err := tunarr.New(&cfg.Tunarr).GetChannels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list channels: %w", err)
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].Number < channels[j].Number
+	})
+
+	themesByChannel := make(map[string][]string)
+	for _, theme := range cfg.Themes {
+		themesByChannel[theme.ChannelID] = append(themesByChannel[theme.ChannelID], theme.Name)
+	}
+
+	fmt.Println()
+	fmt.Printf("%-38s %6s  %-24s %8s  %s\n", "ID", "NUMBER", "NAME", "PROGRAMS", "THEMES")
+	for _, ch := range channels {
+		fmt.Printf("%-38s %6d  %-24s %8d  %s\n", ch.ID, ch.Number, ch.Name, ch.ProgramCount, strings.Join(themesByChannel[ch.ID], ", "))
+		delete(themesByChannel, ch.ID)
+	}
+	fmt.Printf("\n%d channels\n", len(channels))
+
+	// Themes pointing at channels Tunarr does not have
+	if len(themesByChannel) > 0 {
+		fmt.Println("\nThemes with unknown channel_id:")
+		for _, theme := range cfg.Themes {
+			if _, ok := themesByChannel[theme.ChannelID]; ok {
+				fmt.Printf("  %s (channel_id: %s)\n", theme.Name, theme.ChannelID)
+			}
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
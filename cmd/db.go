@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/database"
+)
+
+var (
+	dbExportOutput string
+
+	migrateDownTo     int
+	migrateDownDryRun bool
+
+	maintenanceVacuum bool
+)
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the database",
+	Long: `Manage the program-director database.
+
+Examples:
+  # Move from SQLite to PostgreSQL without re-syncing or losing history
+  program-director db export -o catalog.ndjson
+  program-director --db-driver postgres db import catalog.ndjson
+
+  # Show applied and pending migrations
+  program-director db migrate status
+
+  # Revert every migration after 22, e.g. before going back to an older release
+  program-director db migrate down --to 22
+
+  # Checkpoint the SQLite WAL, refresh planner statistics, and reclaim free space
+  program-director db maintenance --vacuum`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// dbExportCmd dumps the database
+var dbExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export media, history, cooldowns, blocklist, lineups, generations, audit log, and API keys as NDJSON",
+	// Logs go to stderr so the dump can be piped from stdout
+	Annotations: map[string]string{stdoutDataAnnotation: ""},
+	RunE:        runDBExport,
+}
+
+// dbImportCmd loads a dump into an empty database
+var dbImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a dump written by db export into an empty database (- reads stdin)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDBImport,
+}
+
+// dbMigrateCmd groups the schema migration commands
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Inspect and revert schema migrations",
+	Long: `Inspect and revert schema migrations.
+
+Pending migrations are applied by every command that opens the database, so after
+reverting migrations run the release the schema was reverted for.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// dbMigrateStatusCmd lists migrations and whether they are applied
+var dbMigrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied and pending migrations",
+	RunE:  runDBMigrateStatus,
+}
+
+// dbMigrateDownCmd reverts migrations
+var dbMigrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the migrations applied after a version",
+	RunE:  runDBMigrateDown,
+}
+
+// dbMaintenanceCmd runs SQLite maintenance
+var dbMaintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Checkpoint the WAL and run ANALYZE (and VACUUM) on a SQLite database",
+	RunE:  runDBMaintenance,
+}
+
+func init() {
+	dbCmd.AddCommand(dbExportCmd)
+	dbCmd.AddCommand(dbImportCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbMigrateCmd.AddCommand(dbMigrateStatusCmd)
+	dbMigrateCmd.AddCommand(dbMigrateDownCmd)
+	dbCmd.AddCommand(dbMaintenanceCmd)
+
+	dbExportCmd.Flags().StringVarP(&dbExportOutput, "output", "o", "", "write to this file instead of stdout")
+
+	dbMigrateDownCmd.Flags().IntVar(&migrateDownTo, "to", -1, "revert migrations newer than this version (0 reverts all)")
+	dbMigrateDownCmd.Flags().BoolVarP(&migrateDownDryRun, "dry-run", "n", false, "list the migrations that would be reverted")
+	if err := dbMigrateDownCmd.MarkFlagRequired("to"); err != nil {
+		panic(fmt.Sprintf("failed to mark --to required: %v", err))
+	}
+
+	dbMaintenanceCmd.Flags().BoolVar(&maintenanceVacuum, "vacuum", false, "also rebuild the database file to reclaim free space")
+}
+
+// connectDatabase connects to the configured database without migrating it
+func connectDatabase(ctx context.Context) (database.DB, func(), error) {
+	db, err := database.New(ctx, &cfg.Database, componentLogger("database"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	cleanup := func() {
+		if err := db.Close(); err != nil {
+			logger.Error("failed to close database", "error", err)
+		}
+	}
+	return db, cleanup, nil
+}
+
+// openDatabase connects to the configured database and applies pending migrations
+func openDatabase(ctx context.Context) (database.DB, func(), error) {
+	db, cleanup, err := connectDatabase(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := db.Migrate(ctx); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return db, cleanup, nil
+}
+
+func runDBExport(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	db, cleanup, err := openDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var out io.Writer = os.Stdout
+	if dbExportOutput != "" {
+		f, err := os.Create(dbExportOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	counts, err := database.Export(ctx, db, out)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	for _, c := range counts {
+		logger.Info("exported table", "table", c.Table, "rows", c.Rows)
+	}
+	if dbExportOutput != "" {
+		printTableCounts("Exported to "+dbExportOutput, counts)
+	}
+	return nil
+}
+
+func runDBImport(_ *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	var in io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open dump: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+
+	db, cleanup, err := openDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	counts, err := database.Import(ctx, db, in)
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	printTableCounts(fmt.Sprintf("Imported into %s", cfg.Database.Driver), counts)
+	return nil
+}
+
+func runDBMigrateStatus(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	db, cleanup, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	states, err := database.MigrationStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	fmt.Printf("\nMigrations (%s)\n", db.Driver())
+	fmt.Println("========================================")
+	for _, s := range states {
+		status := "pending"
+		switch {
+		case s.Unknown:
+			status = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05") + " (unknown to this build)"
+		case s.Applied:
+			status = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		default:
+			pending++
+		}
+		fmt.Printf("  %03d  %-36s %s\n", s.Version, s.Name, status)
+	}
+	fmt.Printf("\n%d pending\n\n", pending)
+	return nil
+}
+
+func runDBMigrateDown(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	db, cleanup, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if migrateDownDryRun {
+		revert, err := database.RevertibleMigrations(ctx, db, migrateDownTo)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Would revert %d migrations:\n", len(revert))
+		for _, m := range revert {
+			fmt.Printf("  %03d  %s\n", m.Version, m.Name)
+		}
+		return nil
+	}
+
+	reverted, err := database.MigrateDown(ctx, db, migrateDownTo)
+	for _, m := range reverted {
+		logger.Info("migration reverted", "version", m.Version, "name", m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed after reverting %d migrations: %w", len(reverted), err)
+	}
+	fmt.Printf("Reverted %d migrations; the schema is now at version %d\n", len(reverted), migrateDownTo)
+	return nil
+}
+
+func runDBMaintenance(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	db, cleanup, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	maintainer, ok := db.(database.Maintainer)
+	if !ok {
+		return fmt.Errorf("maintenance only applies to SQLite; %s is maintained by autovacuum", db.Driver())
+	}
+
+	result, err := maintainer.Maintain(ctx, maintenanceVacuum)
+	if err != nil {
+		return fmt.Errorf("maintenance failed: %w", err)
+	}
+
+	fmt.Println("\nSQLite Maintenance")
+	fmt.Println("========================================")
+	if result.WAL {
+		fmt.Printf("WAL checkpoint:  %d frames\n", result.Checkpointed)
+	} else {
+		fmt.Println("WAL checkpoint:  skipped (not in WAL mode)")
+	}
+	fmt.Println("ANALYZE:         done")
+	if result.Vacuumed {
+		fmt.Println("VACUUM:          done")
+	}
+	fmt.Printf("Size:            %d -> %d bytes\n", result.SizeBefore, result.SizeAfter)
+	fmt.Printf("Duration:        %s\n\n", result.Duration.Round(time.Millisecond))
+	return nil
+}
+
+// printTableCounts prints the rows per table under a title
+func printTableCounts(title string, counts []database.TableCount) {
+	fmt.Println()
+	fmt.Println(title)
+	fmt.Println("========================================")
+	for _, c := range counts {
+		fmt.Printf("  %-20s %d\n", c.Table, c.Rows)
+	}
+	fmt.Println()
+}
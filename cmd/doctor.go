@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/clients/httpx"
+	"github.com/geekxflood/program-director/internal/clients/ollama"
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
+)
+
+// doctorTimeout bounds each network check made by doctor
+const doctorTimeout = 10 * time.Second
+
+// configErr holds the config load error for doctor, which reports it instead of aborting
+var configErr error
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Run preflight checks against config, database, and upstreams",
+	Long: `Check that program-director is ready to run.
+
+This command validates the configuration, connects to the database and
+looks for pending migrations, checks each Radarr, Sonarr, Tunarr, and
+Ollama server, verifies that the Ollama model is pulled, and that every
+theme's channel_id exists in Tunarr. No migrations are applied and
+nothing is changed upstream.
+
+Each check is reported as PASS, WARN, or FAIL; the command exits with an
+error when any check fails.
+
+Examples:
+  # Check the default config
+  program-director doctor
+
+  # Check another config file
+  program-director doctor --config /etc/program-director/config.yaml`,
+	RunE:         runDoctor,
+	SilenceUsage: true,
+}
+
+// checkLevel is the outcome of a doctor check
+type checkLevel string
+
+const (
+	checkPass checkLevel = "PASS"
+	checkWarn checkLevel = "WARN"
+	checkFail checkLevel = "FAIL"
+)
+
+// doctorReport collects and prints check results
+type doctorReport struct {
+	failed int
+	warned int
+}
+
+func (r *doctorReport) add(level checkLevel, name, detail string) {
+	switch level {
+	case checkFail:
+		r.failed++
+	case checkWarn:
+		r.warned++
+	}
+	fmt.Printf("  [%s] %-28s %s\n", level, name, detail)
+}
+
+// check runs fn with a timeout and reports PASS with detail, or FAIL with its error
+func (r *doctorReport) check(ctx context.Context, name, detail string, fn func(ctx context.Context) error) bool {
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	if err := fn(checkCtx); err != nil {
+		msg := err.Error()
+		if errors.Is(err, httpx.ErrUnauthorized) {
+			msg = "API key rejected: " + msg
+		}
+		r.add(checkFail, name, msg)
+		return false
+	}
+	r.add(checkPass, name, detail)
+	return true
+}
+
+// finish prints the summary and returns an error when any check failed
+func (r *doctorReport) finish() error {
+	fmt.Printf("\n%d failed, %d warnings\n\n", r.failed, r.warned)
+	if r.failed > 0 {
+		return fmt.Errorf("%d preflight checks failed", r.failed)
+	}
+	return nil
+}
+
+func runDoctor(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	var report doctorReport
+	fmt.Println()
+	fmt.Println("Preflight Checks")
+	fmt.Println("================")
+
+	if configErr != nil {
+		var ve *config.ValidationError
+		if !errors.As(configErr, &ve) {
+			report.add(checkFail, "config", configErr.Error())
+			return report.finish()
+		}
+		for _, fe := range ve.Errors {
+			report.add(checkFail, "config", fe.Error())
+		}
+		return report.finish()
+	}
+	report.add(checkPass, "config", fmt.Sprintf("%d themes", len(cfg.Themes)))
+
+	doctorDatabase(ctx, &report)
+
+	if len(cfg.Radarr) == 0 && len(cfg.Sonarr) == 0 {
+		report.add(checkWarn, "media servers", "no radarr or sonarr instances configured")
+	}
+	for _, c := range newRadarrClients() {
+		report.check(ctx, "radarr "+c.Name(), "reachable", c.HealthCheck)
+	}
+	for _, c := range newSonarrClients() {
+		report.check(ctx, "sonarr "+c.Name(), "reachable", c.HealthCheck)
+	}
+
+	doctorTunarr(ctx, &report, tunarr.New(&cfg.Tunarr))
+	if cfg.LLM.Enabled {
+		doctorOllama(ctx, &report, ollama.New(&cfg.Ollama))
+	} else {
+		report.add(checkWarn, "ollama", "llm disabled, using heuristic scoring and ordering only")
+	}
+
+	return report.finish()
+}
+
+// doctorDatabase checks connectivity and pending migrations
+func doctorDatabase(ctx context.Context, report *doctorReport) {
+	db, err := database.New(ctx, &cfg.Database, componentLogger("database"))
+	if err != nil {
+		report.add(checkFail, "database", err.Error())
+		return
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Error("failed to close database", "error", err)
+		}
+	}()
+
+	if !report.check(ctx, "database", cfg.Database.Driver+" connected", db.Ping) {
+		return
+	}
+
+	pending, err := database.PendingMigrations(ctx, db)
+	switch {
+	case err != nil:
+		report.add(checkWarn, "migrations", "schema not initialized; it is created on first run")
+	case len(pending) > 0:
+		report.add(checkWarn, "migrations", fmt.Sprintf("%d pending, applied on next run (first: %03d_%s)", len(pending), pending[0].Version, pending[0].Name))
+	default:
+		report.add(checkPass, "migrations", "up to date")
+	}
+}
+
+// doctorTunarr checks Tunarr and that each theme's channel exists
+func doctorTunarr(ctx context.Context, report *doctorReport, client *tunarr.Client) {
+	if !report.check(ctx, "tunarr", "reachable", client.HealthCheck) {
+		return
+	}
+
+	for _, theme := range cfg.Themes {
+		name := "channel " + theme.Name
+		report.check(ctx, name, theme.ChannelID, func(ctx context.Context) error {
+			_, err := client.GetChannel(ctx, theme.ChannelID)
+			if errors.Is(err, httpx.ErrNotFound) {
+				return fmt.Errorf("channel %s not found in tunarr", theme.ChannelID)
+			}
+			return err
+		})
+	}
+}
+
+// doctorOllama checks Ollama and that the configured model is pulled
+func doctorOllama(ctx context.Context, report *doctorReport, client *ollama.Client) {
+	if !report.check(ctx, "ollama", "reachable", client.HealthCheck) {
+		return
+	}
+
+	report.check(ctx, "ollama model", cfg.Ollama.Model, func(ctx context.Context) error {
+		models, err := client.ListModels(ctx)
+		if err != nil {
+			return err
+		}
+		if !ollama.HasModel(models, cfg.Ollama.Model) {
+			return fmt.Errorf("model %s is not pulled (run: program-director llm pull)", cfg.Ollama.Model)
+		}
+		return nil
+	})
+}
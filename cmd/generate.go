@@ -7,23 +7,36 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"os/user"
 	"syscall"
 
 	"github.com/spf13/cobra"
 
+	"github.com/geekxflood/program-director/internal/alerts"
+	"github.com/geekxflood/program-director/internal/clients/animelists"
 	"github.com/geekxflood/program-director/internal/clients/ollama"
+	"github.com/geekxflood/program-director/internal/clients/overseerr"
+	"github.com/geekxflood/program-director/internal/clients/radarr"
+	"github.com/geekxflood/program-director/internal/clients/sonarr"
+	"github.com/geekxflood/program-director/internal/clients/tmdb"
+	"github.com/geekxflood/program-director/internal/clients/trakt"
 	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/config"
 	"github.com/geekxflood/program-director/internal/database"
 	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/notify"
 	"github.com/geekxflood/program-director/internal/services/cooldown"
+	"github.com/geekxflood/program-director/internal/services/media"
 	"github.com/geekxflood/program-director/internal/services/playlist"
 	"github.com/geekxflood/program-director/internal/services/similarity"
+	"github.com/geekxflood/program-director/pkg/models"
 )
 
 var (
 	themeName string
 	allThemes bool
 	dryRun    bool
+	force     bool
 )
 
 // generateCmd represents the generate command
@@ -40,7 +53,10 @@ Examples:
   program-director generate --all-themes
 
   # Preview without applying
-  program-director generate --theme horror-night --dry-run`,
+  program-director generate --theme horror-night --dry-run
+
+  # Generate all themes, including those not scheduled today
+  program-director generate --all-themes --force`,
 	RunE: runGenerate,
 }
 
@@ -48,6 +64,7 @@ func init() {
 	generateCmd.Flags().StringVarP(&themeName, "theme", "t", "", "theme name to generate")
 	generateCmd.Flags().BoolVarP(&allThemes, "all-themes", "a", false, "generate all configured themes")
 	generateCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "preview without applying to Tunarr")
+	generateCmd.Flags().BoolVar(&force, "force", false, "with --all-themes, also generate themes whose days_of_week exclude today")
 }
 
 func runGenerate(_ *cobra.Command, _ []string) error {
@@ -75,6 +92,7 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 		"all_themes", allThemes,
 		"theme", themeName,
 		"dry_run", dryRun,
+		"force", force,
 		"config_file", cfgFile,
 	)
 
@@ -88,43 +106,66 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 	defer cleanup()
 	logger.Debug("services initialized successfully")
 
+	action := models.AuditActionGenerate
+	if allThemes {
+		action = models.AuditActionGenerateAll
+	}
+	recordCLIAudit(ctx, services.db, action, themeName, models.AuditParameters{"dry_run": dryRun, "force": force})
+
 	if allThemes {
 		logger.Info("generating all themes", "count", len(cfg.Themes))
 
-		results, err := services.generator.GenerateAll(ctx, cfg.Themes, dryRun)
+		summary, err := services.generator.GenerateAll(ctx, cfg.Themes, dryRun, force)
 		if err != nil {
 			logger.Error("generation error", "error", err)
 			return fmt.Errorf("generation error: %w", err)
 		}
+		if !dryRun {
+			services.notifier.NotifyGeneration(ctx, summary, cfg.Themes)
+			services.alerts.RecordGenerations(ctx, summary.Results)
+		}
 
 		// Report results with summary
-		var successful, failed int
-		for _, result := range results {
+		for _, result := range summary.Results {
 			if result.Error != nil {
-				failed++
 				logger.Error("theme generation failed",
 					"theme", result.ThemeName,
 					"channel_id", result.ChannelID,
 					"error", result.Error,
+					"attempts", result.Attempts,
 					"duration", result.Duration,
 				)
+			} else if result.SkipReason != "" {
+				logger.Info("theme generation skipped",
+					"theme", result.ThemeName,
+					"reason", result.SkipReason,
+				)
 			} else {
-				successful++
 				logger.Info("theme generation completed",
 					"theme", result.ThemeName,
 					"channel_id", result.ChannelID,
 					"items", result.ItemCount,
 					"total_score", fmt.Sprintf("%.2f", result.TotalScore),
+					"attempts", result.Attempts,
 					"duration", result.Duration,
 					"generated", result.Generated,
 				)
+				printDiff(result.ThemeName, result.Diff)
+			}
+			if result.Degraded != "" {
+				logger.Warn("theme generated without full LLM support",
+					"theme", result.ThemeName,
+					"degraded", result.Degraded,
+				)
 			}
 		}
 
 		logger.Info("all themes processed",
-			"total", len(results),
-			"successful", successful,
-			"failed", failed,
+			"total", len(summary.Results),
+			"successful", len(summary.Generated),
+			"failed", len(summary.Failed),
+			"skipped", len(summary.Skipped),
+			"retried", summary.Retried,
 		)
 	} else {
 		// Find the specific theme
@@ -141,6 +182,9 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 				)
 
 				result := services.generator.Generate(ctx, &theme, dryRun)
+				if !dryRun {
+					services.alerts.RecordGenerations(ctx, []playlist.GenerationResult{result})
+				}
 
 				if result.Error != nil {
 					logger.Error("generation failed",
@@ -159,6 +203,13 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 					"duration", result.Duration,
 					"generated", result.Generated,
 				)
+				if result.Degraded != "" {
+					logger.Warn("theme generated without full LLM support",
+						"theme", result.ThemeName,
+						"degraded", result.Degraded,
+					)
+				}
+				printDiff(result.ThemeName, result.Diff)
 				break
 			}
 		}
@@ -172,10 +223,53 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
+// printDiff prints a dry run's changes against the channel's current lineup
+func printDiff(theme string, diff *playlist.LineupDiff) {
+	if diff == nil {
+		return
+	}
+
+	fmt.Printf("\n%s: %d added, %d removed, %d kept\n", theme, len(diff.Added), len(diff.Removed), len(diff.Kept))
+	for _, section := range []struct {
+		sign    string
+		entries []playlist.DiffEntry
+	}{
+		{"+", diff.Added},
+		{"-", diff.Removed},
+		{"=", diff.Kept},
+	} {
+		for _, e := range section.entries {
+			fmt.Printf("  %s %s (%d)\n", section.sign, e.Title, e.Year)
+		}
+	}
+}
+
 // services holds initialized service instances
 type services struct {
 	db        database.DB
 	generator *playlist.Generator
+	notifier  *notify.Notifier
+	alerts    *alerts.Monitor
+}
+
+// recordCLIAudit records a CLI-triggered action in the audit log, attributed to user@host.
+// A failure is logged without failing the command.
+func recordCLIAudit(ctx context.Context, db database.DB, action, theme string, params models.AuditParameters) {
+	actor, _ := os.Hostname()
+	if u, err := user.Current(); err == nil {
+		actor = u.Username + "@" + actor
+	}
+
+	entry := &models.AuditEntry{
+		ActorType:  models.AuditActorCLI,
+		Actor:      actor,
+		Action:     action,
+		ThemeName:  theme,
+		Parameters: params,
+	}
+	if err := repository.NewAuditRepository(db).Create(ctx, entry); err != nil {
+		logger.Warn("failed to record audit entry", "action", action, "error", err)
+	}
 }
 
 // initializeServices sets up all required services
@@ -187,7 +281,7 @@ func initializeServices(ctx context.Context) (*services, func(), error) {
 	)
 
 	// Initialize database
-	db, err := database.New(ctx, &cfg.Database, logger)
+	db, err := database.New(ctx, &cfg.Database, componentLogger("database"))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
@@ -205,23 +299,25 @@ func initializeServices(ctx context.Context) (*services, func(), error) {
 	mediaRepo := repository.NewMediaRepository(db)
 	historyRepo := repository.NewHistoryRepository(db)
 	cooldownRepo := repository.NewCooldownRepository(db)
+	snapshotRepo := repository.NewSnapshotRepository(db)
+	listRepo := repository.NewListRepository(db)
+	watchRepo := repository.NewWatchHistoryRepository(db)
+	blocklistRepo := repository.NewBlocklistRepository(db)
+	generationRepo := repository.NewGenerationRepository(db)
+	llmUsageRepo := repository.NewLLMUsageRepository(db)
+	lineupRepo := repository.NewLineupRepository(db)
 	logger.Debug("repositories initialized")
 
 	// Initialize Tunarr client
 	logger.Debug("initializing tunarr client", "url", cfg.Tunarr.URL)
 	tunarrClient := tunarr.New(&cfg.Tunarr)
 
-	// Initialize Ollama client
-	logger.Debug("initializing ollama client",
-		"url", cfg.Ollama.URL,
-		"model", cfg.Ollama.Model,
-		"temperature", cfg.Ollama.Temperature,
-	)
-	ollamaClient := ollama.New(&cfg.Ollama)
-
 	// Initialize similarity scorer
 	logger.Debug("initializing similarity scorer")
-	scorer := similarity.NewScorer(mediaRepo, ollamaClient, logger)
+	scorer := similarity.NewScorer(mediaRepo, newOllamaClient(), newOverseerrClient(), newTraktClient(), listRepo, blocklistRepo, componentLogger("similarity"))
+	scorer.SetLLMLogger(componentLogger("ollama"))
+	scorer.SetFallbackModels(cfg.LLM.FallbackModels)
+	scorer.SetPrompts(cfg.LLM.Prompts)
 
 	// Initialize cooldown manager
 	logger.Debug("initializing cooldown manager",
@@ -229,11 +325,16 @@ func initializeServices(ctx context.Context) (*services, func(), error) {
 		"series_days", cfg.Cooldown.SeriesDays,
 		"anime_days", cfg.Cooldown.AnimeDays,
 	)
-	cooldownManager := cooldown.NewManager(cooldownRepo, historyRepo, &cfg.Cooldown, logger)
+	cooldownManager := cooldown.NewManager(cooldownRepo, historyRepo, watchRepo, &cfg.Cooldown, componentLogger("cooldown"))
 
 	// Initialize playlist generator
 	logger.Debug("initializing playlist generator")
-	generator := playlist.NewGenerator(tunarrClient, scorer, cooldownManager, logger)
+	generator := playlist.NewGenerator(tunarrClient, scorer, cooldownManager, snapshotRepo, generationRepo, llmUsageRepo, lineupRepo, &cfg.Generation, componentLogger("generation"))
+	if cfg.LLM.Traces.Enabled {
+		generator.SetTraces(repository.NewLLMTraceRepository(db), cfg.LLM.Traces.RetentionDays)
+	}
+
+	notifier := notify.New(&cfg.Notify, componentLogger("notify"))
 
 	cleanup := func() {
 		logger.Debug("cleaning up resources")
@@ -246,5 +347,89 @@ func initializeServices(ctx context.Context) (*services, func(), error) {
 	return &services{
 		db:        db,
 		generator: generator,
+		notifier:  notifier,
+		alerts:    alerts.New(&cfg.Alerts, generationRepo, notifier, componentLogger("notify")),
 	}, cleanup, nil
 }
+
+// newOverseerrClient returns an Overseerr client, or nil when Overseerr isn't configured
+func newOverseerrClient() *overseerr.Client {
+	if cfg.Overseerr.URL == "" {
+		return nil
+	}
+	logger.Debug("initializing overseerr client", "url", cfg.Overseerr.URL)
+	return overseerr.New(&cfg.Overseerr)
+}
+
+// newOllamaClient returns an Ollama client, or nil when the LLM is disabled
+func newOllamaClient() *ollama.Client {
+	if !cfg.LLM.Enabled {
+		logger.Debug("llm disabled, using heuristic scoring and ordering only")
+		return nil
+	}
+	logger.Debug("initializing ollama client",
+		"url", cfg.Ollama.URL,
+		"model", cfg.Ollama.Model,
+		"temperature", cfg.Ollama.Temperature,
+		"fallback_models", cfg.LLM.FallbackModels,
+	)
+	return ollama.New(&cfg.Ollama)
+}
+
+// newTraktClient returns a Trakt client, or nil when no Trakt client ID is configured
+func newTraktClient() *trakt.Client {
+	if cfg.Trakt.ClientID == "" {
+		return nil
+	}
+	return trakt.New(&cfg.Trakt)
+}
+
+// newTMDBClient returns a TMDB client, or nil when no TMDB API key is configured
+func newTMDBClient() *tmdb.Client {
+	if cfg.TMDB.APIKey == "" {
+		return nil
+	}
+	return tmdb.New(&cfg.TMDB)
+}
+
+// newAnimeDetector returns the configured anime detection for synced series
+func newAnimeDetector() *media.AnimeDetector {
+	if cfg.Sync.AnimeDetection == media.AnimeDetectionMapping {
+		logger.Debug("initializing anime ID mapping", "url", cfg.Sync.AnimeMappingURL)
+		return media.NewAnimeDetector(cfg.Sync.AnimeDetection, animelists.New(cfg.Sync.AnimeMappingURL))
+	}
+	return media.NewAnimeDetector(cfg.Sync.AnimeDetection, nil)
+}
+
+// newRadarrClients returns a client per configured Radarr instance, in config order
+func newRadarrClients() []*radarr.Client {
+	clients := make([]*radarr.Client, 0, len(cfg.Radarr))
+	for i := range cfg.Radarr {
+		clients = append(clients, radarr.New(&cfg.Radarr[i]))
+	}
+	return clients
+}
+
+// newSonarrClients returns a client per configured Sonarr instance, in config order
+func newSonarrClients() []*sonarr.Client {
+	clients := make([]*sonarr.Client, 0, len(cfg.Sonarr))
+	for i := range cfg.Sonarr {
+		clients = append(clients, sonarr.New(&cfg.Sonarr[i]))
+	}
+	return clients
+}
+
+// instanceURLs lists "name=url" for each configured instance, for logging
+func instanceURLs[T config.RadarrConfig | config.SonarrConfig](instances []T) []string {
+	urls := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		c := struct {
+			Name, URL, APIKey, APIKeyFile string
+			Timeout                       int
+			Proxy                         string
+			TLS                           config.TLSConfig
+		}(inst)
+		urls = append(urls, c.Name+"="+c.URL)
+	}
+	return urls
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	healthcheckURL     string
+	healthcheckTimeout time.Duration
+)
+
+// healthcheckCmd represents the healthcheck command
+var healthcheckCmd = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Check that a running server is ready",
+	Long: `Request /ready from a running program-director server and exit with an
+error unless it answers 200, so container health probes work without curl
+or wget in the image. No config is loaded.
+
+Examples:
+  # Check the server in this container
+  program-director healthcheck
+
+  # Docker
+  HEALTHCHECK CMD ["/app/program-director", "healthcheck", "--url", "http://localhost:8080"]`,
+	RunE:         runHealthcheck,
+	SilenceUsage: true,
+}
+
+func init() {
+	healthcheckCmd.Flags().StringVar(&healthcheckURL, "url", "http://localhost:8080", "base URL of the server")
+	healthcheckCmd.Flags().DurationVar(&healthcheckTimeout, "timeout", 3*time.Second, "how long to wait for the answer")
+}
+
+func runHealthcheck(cmd *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), healthcheckTimeout)
+	defer cancel()
+
+	url := strings.TrimSuffix(healthcheckURL, "/") + "/ready"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("server unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server not ready: %s answered %s", url, resp.Status)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "ready: %s\n", url)
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// executeHealthcheck runs the healthcheck command as main does; an error means exit code 1
+func executeHealthcheck(t *testing.T, url string, timeout time.Duration) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(io.Discard)
+	rootCmd.SetArgs([]string{"healthcheck", "--url", url, "--timeout", timeout.String()})
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	})
+	err := Execute()
+	return out.String(), err
+}
+
+func TestHealthcheck(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ready", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	healthy := httptest.NewServer(mux)
+	defer healthy.Close()
+
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+
+	// Nothing listens on a closed server's address
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string // Empty for exit code 0
+	}{
+		{name: "ready", url: healthy.URL},
+		{name: "trailing slash", url: healthy.URL + "/"},
+		{name: "not ready", url: unhealthy.URL, wantErr: "503"},
+		{name: "unreachable", url: closed.URL, wantErr: "server unreachable"},
+		{name: "timeout", url: slow.URL, wantErr: "server unreachable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeHealthcheck(t, tt.url, 200*time.Millisecond)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("healthcheck error = %v, want exit code 0", err)
+				}
+				if !strings.Contains(out, "ready: "+healthy.URL+"/ready") {
+					t.Errorf("healthcheck output = %q, want the ready URL", out)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("healthcheck error = %v, want exit code 1 with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/history"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+var (
+	pruneDays   int
+	pruneDryRun bool
+
+	exportFormat  string
+	exportOutput  string
+	exportSince   string
+	exportUntil   string
+	exportChannel string
+	exportTheme   string
+	exportSource  string
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Manage play history",
+	Long: `Manage the play history recorded for lineups and Plex airings.
+
+Examples:
+  # Delete history older than history.retention_days
+  program-director history prune
+
+  # Count history older than a year without deleting it
+  program-director history prune --days 365 --dry-run
+
+  # Export this year's history of one theme as CSV
+  program-director history export --theme noir --since 2026-01-01 -o noir.csv
+
+  # Export everything Plex reported as aired as JSON to stdout
+  program-director history export --format json --source plex`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// historyPruneCmd deletes old play history
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete play history older than the retention period",
+	RunE:  runHistoryPrune,
+}
+
+// historyExportCmd writes play history as CSV or JSON
+var historyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export play history as CSV or JSON",
+	// Logs go to stderr so the export can be piped from stdout
+	Annotations: map[string]string{stdoutDataAnnotation: ""},
+	RunE:        runHistoryExport,
+}
+
+func init() {
+	historyCmd.AddCommand(historyPruneCmd)
+	historyCmd.AddCommand(historyExportCmd)
+
+	historyPruneCmd.Flags().IntVar(&pruneDays, "days", 0, "delete history older than this many days (default: history.retention_days)")
+	historyPruneCmd.Flags().BoolVarP(&pruneDryRun, "dry-run", "n", false, "count the history that would be deleted")
+
+	historyExportCmd.Flags().StringVar(&exportFormat, "format", "csv", "export format (csv or json)")
+	historyExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "write to this file instead of stdout")
+	historyExportCmd.Flags().StringVar(&exportSince, "since", "", "only history played on or after this date (YYYY-MM-DD or RFC 3339)")
+	historyExportCmd.Flags().StringVar(&exportUntil, "until", "", "only history played up to this date, inclusive (YYYY-MM-DD or RFC 3339)")
+	historyExportCmd.Flags().StringVar(&exportChannel, "channel", "", "only history of this channel ID")
+	historyExportCmd.Flags().StringVar(&exportTheme, "theme", "", "only history of this theme")
+	historyExportCmd.Flags().StringVar(&exportSource, "source", "", "only history from this source (lineup or plex)")
+}
+
+func runHistoryPrune(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	days := pruneDays
+	if days == 0 {
+		days = cfg.History.RetentionDays
+	}
+	if days <= 0 {
+		return errors.New("no retention period: set history.retention_days or pass --days")
+	}
+	before := time.Now().AddDate(0, 0, -days)
+
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer cleanup()
+
+	repo := repository.NewHistoryRepository(services.db)
+	if pruneDryRun {
+		count, err := repo.Count(ctx, repository.ListHistoryOptions{Until: before})
+		if err != nil {
+			return fmt.Errorf("failed to count history: %w", err)
+		}
+		fmt.Printf("Would delete %d history entries from before %s\n", count, before.Format(time.DateOnly))
+		return nil
+	}
+
+	deleted, err := repo.DeleteOlderThan(ctx, before)
+	if err != nil {
+		return fmt.Errorf("failed to prune history: %w", err)
+	}
+	logger.Info("pruned play history", "deleted", deleted, "before", before.Format(time.DateOnly))
+	fmt.Printf("Deleted %d history entries from before %s\n", deleted, before.Format(time.DateOnly))
+	return nil
+}
+
+func runHistoryExport(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	format, err := history.ParseFormat(exportFormat)
+	if err != nil {
+		return err
+	}
+	opts := repository.ListHistoryOptions{
+		ChannelID: exportChannel,
+		ThemeName: exportTheme,
+		Source:    models.PlaySource(exportSource),
+	}
+	switch opts.Source {
+	case "", models.PlaySourceLineup, models.PlaySourcePlex:
+	default:
+		return fmt.Errorf("invalid --source %q (must be lineup or plex)", exportSource)
+	}
+	if opts.Since, err = parseDateFlag("since", exportSince, false); err != nil {
+		return err
+	}
+	if opts.Until, err = parseDateFlag("until", exportUntil, true); err != nil {
+		return err
+	}
+
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer cleanup()
+
+	var out io.Writer = os.Stdout
+	if exportOutput != "" {
+		f, err := os.Create(exportOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	count, err := history.Export(ctx, repository.NewHistoryRepository(services.db), out, format, opts)
+	if err != nil {
+		return err
+	}
+	if exportOutput != "" {
+		fmt.Printf("Exported %d history entries to %s\n", count, exportOutput)
+	}
+	return nil
+}
+
+// parseDateFlag parses a YYYY-MM-DD date in local time or an RFC 3339 time. A date given
+// as an end of range covers the whole day.
+func parseDateFlag(name, value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q (must be YYYY-MM-DD or RFC 3339)", name, value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/clients/ollama"
+)
+
+// llmCmd represents the llm command
+var llmCmd = &cobra.Command{
+	Use:   "llm",
+	Short: "Manage Ollama models",
+	Long: `Manage the models on the configured Ollama server.
+
+Examples:
+  # Make sure the configured model is pulled
+  program-director llm pull
+
+  # List pulled models
+  program-director llm list
+
+  # Show a model's family, size, and parameters
+  program-director llm show llama3.1:8b`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// llmListCmd lists pulled models
+var llmListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the models pulled on the Ollama server",
+	RunE:  runLLMList,
+}
+
+// llmPullCmd pulls a model
+var llmPullCmd = &cobra.Command{
+	Use:   "pull [model]",
+	Short: "Pull a model, the configured one by default",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runLLMPull,
+}
+
+// llmShowCmd shows a model's details
+var llmShowCmd = &cobra.Command{
+	Use:   "show [model]",
+	Short: "Show a model's details, the configured one by default",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runLLMShow,
+}
+
+func init() {
+	llmCmd.AddCommand(llmListCmd)
+	llmCmd.AddCommand(llmPullCmd)
+	llmCmd.AddCommand(llmShowCmd)
+}
+
+// modelArg returns the model named in args, or the configured model
+func modelArg(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return cfg.Ollama.Model
+}
+
+func runLLMList(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	models, err := ollama.New(&cfg.Ollama).ListModels(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("%-40s %10s  %s\n", "NAME", "SIZE", "MODIFIED")
+	for _, m := range models {
+		name := m.Name
+		if ollama.HasModel([]ollama.Model{m}, cfg.Ollama.Model) {
+			name += " *"
+		}
+		fmt.Printf("%-40s %10s  %s\n", name, formatBytes(m.Size), m.ModifiedAt.Format("2006-01-02 15:04"))
+	}
+	fmt.Printf("\n%d models (* = configured)\n", len(models))
+	if !ollama.HasModel(models, cfg.Ollama.Model) {
+		fmt.Printf("\nThe configured model %s is not pulled (run: program-director llm pull)\n", cfg.Ollama.Model)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func runLLMPull(_ *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	model := modelArg(args)
+	fmt.Printf("Pulling %s from %s\n", model, cfg.Ollama.URL)
+
+	var status string
+	err := ollama.New(&cfg.Ollama).PullModel(ctx, model, func(p ollama.PullProgress) {
+		if p.Status != status {
+			if status != "" {
+				fmt.Println()
+			}
+			status = p.Status
+			fmt.Print(status)
+		}
+		if p.Total > 0 {
+			fmt.Printf("\r%s %3d%% of %s", status, p.Completed*100/p.Total, formatBytes(p.Total))
+		}
+	})
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s is ready\n\n", model)
+	return nil
+}
+
+func runLLMShow(_ *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	model := modelArg(args)
+	info, err := ollama.New(&cfg.Ollama).ShowModel(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("Model:         %s\n", model)
+	fmt.Printf("Family:        %s\n", info.Details.Family)
+	fmt.Printf("Parameters:    %s\n", info.Details.ParameterSize)
+	fmt.Printf("Quantization:  %s\n", info.Details.QuantizationLevel)
+	fmt.Printf("Format:        %s\n", info.Details.Format)
+	if !info.ModifiedAt.IsZero() {
+		fmt.Printf("Modified:      %s\n", info.ModifiedAt.Format("2006-01-02 15:04"))
+	}
+	if params := strings.TrimSpace(info.Parameters); params != "" {
+		fmt.Println("\nDefault options:")
+		for _, line := range strings.Split(params, "\n") {
+			fmt.Printf("  %s\n", strings.Join(strings.Fields(line), " "))
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// formatBytes formats a size such as a model's in GB or MB
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
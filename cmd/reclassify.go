@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/media"
+)
+
+var reclassifyDryRun bool
+
+// reclassifyCmd represents the reclassify command
+var reclassifyCmd = &cobra.Command{
+	Use:   "reclassify",
+	Short: "Re-apply anime detection to stored series",
+	Long: `Re-classify stored Sonarr series as anime or series.
+
+This command fetches the series from Sonarr and applies the configured
+sync.anime_detection mode (heuristic, series_type, or mapping) to the
+series already in the database, fixing rows stored by an earlier mode
+without running a full sync.
+
+Examples:
+  # Show which series would change type
+  program-director reclassify --dry-run
+
+  # Update the stored media types
+  program-director reclassify`,
+	RunE: runReclassify,
+}
+
+func init() {
+	reclassifyCmd.Flags().BoolVarP(&reclassifyDryRun, "dry-run", "n", false, "report changes without writing them")
+}
+
+func runReclassify(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	logger.Info("reclassifying series",
+		"anime_detection", cfg.Sync.AnimeDetection,
+		"dry_run", reclassifyDryRun,
+		"sonarr", instanceURLs(cfg.Sonarr),
+	)
+
+	db, err := database.New(ctx, &cfg.Database, componentLogger("database"))
+	if err != nil {
+		logger.Error("failed to initialize database", "error", err)
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Error("failed to close database", "error", err)
+		}
+	}()
+
+	if err := db.Migrate(ctx); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	syncService := media.NewSyncService(nil, newSonarrClients(), nil, newAnimeDetector(), repository.NewMediaRepository(db), nil, nil, logger)
+
+	result, err := syncService.ReclassifyAnime(ctx, reclassifyDryRun)
+	if err != nil {
+		return fmt.Errorf("reclassification failed: %w", err)
+	}
+
+	fmt.Println()
+	if reclassifyDryRun {
+		fmt.Println("Reclassify Dry Run (nothing was written)")
+		fmt.Println("========================================")
+	} else {
+		fmt.Println("Reclassify Summary")
+		fmt.Println("==================")
+	}
+	fmt.Printf("\nChecked: %d series, %d changed\n", result.Checked, len(result.Changes))
+	for _, change := range result.Changes {
+		fmt.Printf("  ~ %s\n", change)
+	}
+	fmt.Println()
+
+	return nil
+}
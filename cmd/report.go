@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+var (
+	reportMediaTypes []string
+	reportTitles     int
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report on the library and channels",
+	Long: `Report on how the library is used by the channels.
+
+Examples:
+  # Genres with the most titles never scheduled on any channel
+  program-director report utilization
+
+  # Only movies, listing every never-scheduled title
+  program-director report utilization --media-type movie --titles 0`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// reportUtilizationCmd lists never-scheduled media by genre
+var reportUtilizationCmd = &cobra.Command{
+	Use:   "utilization",
+	Short: "List media with files that has never been scheduled, grouped by genre",
+	RunE:  runReportUtilization,
+}
+
+func init() {
+	reportCmd.AddCommand(reportUtilizationCmd)
+
+	reportUtilizationCmd.Flags().StringSliceVar(&reportMediaTypes, "media-type", nil, "only these media types (movie, series, anime)")
+	reportUtilizationCmd.Flags().IntVar(&reportTitles, "titles", 10, "titles to list per genre (0 for all)")
+}
+
+func runReportUtilization(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	if reportTitles < 0 {
+		return errors.New("--titles must not be negative")
+	}
+	mediaTypes := make([]models.MediaType, 0, len(reportMediaTypes))
+	for _, mt := range reportMediaTypes {
+		switch t := models.MediaType(mt); t {
+		case models.MediaTypeMovie, models.MediaTypeSeries, models.MediaTypeAnime:
+			mediaTypes = append(mediaTypes, t)
+		default:
+			return fmt.Errorf("invalid --media-type %q (must be movie, series, or anime)", mt)
+		}
+	}
+
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer cleanup()
+
+	report, err := media.Utilization(ctx, repository.NewMediaRepository(services.db), mediaTypes, reportTitles)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nLibrary Utilization")
+	fmt.Println("========================================")
+	fmt.Printf("\n%d of %d titles with files have never been scheduled\n", report.Unscheduled, report.Available)
+
+	for _, g := range report.Genres {
+		fmt.Printf("\n%s: %d of %d never scheduled\n", g.Genre, g.Unscheduled, g.Available)
+		for _, t := range g.Titles {
+			fmt.Printf("  %6d  %4.1f  %s (%d) [%s]\n", t.ID, t.IMDBRating, t.Title, t.Year, t.MediaType)
+		}
+		if more := g.Unscheduled - len(g.Titles); more > 0 {
+			fmt.Printf("  ... and %d more\n", more)
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -2,27 +2,37 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/geekxflood/program-director/internal/clients/httpx"
+	"github.com/geekxflood/program-director/internal/clients/upstream"
 	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/logging"
 )
 
 var (
-	cfgFile   string
-	debug     bool
-	dbDriver  string
-	jsonLogs  bool
-	cfg       *config.Config
-	logger    *slog.Logger
-	version   = "dev"
-	commit    = "none"
-	buildDate = "unknown"
+	cfgFile    string
+	debug      bool
+	dbDriver   string
+	jsonLogs   bool
+	cfg        *config.Config
+	logger     *slog.Logger
+	logHandler slog.Handler         // Shared by logger and component loggers
+	logConfig  config.LoggingConfig // Levels of component loggers
+	version    = "dev"
+	commit     = "none"
+	buildDate  = "unknown"
 )
 
+// stdoutDataAnnotation marks commands that write their output data to stdout, so their logs
+// go to stderr instead
+const stdoutDataAnnotation = "stdout-data"
+
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
 	Use:   "program-director",
@@ -33,11 +43,23 @@ using AI to select appropriate content from your media library.
 It integrates with Radarr, Sonarr, and Tunarr to create intelligent
 programming schedules based on configurable themes.`,
 	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
-		// Skip config loading for version command
-		if cmd.Name() == "version" {
+		// Skip config loading for the version and healthcheck commands
+		if cmd.Name() == "version" || cmd.Name() == "healthcheck" {
 			return nil
 		}
-		return initConfig()
+		var logOutput io.Writer = os.Stdout
+		if _, ok := cmd.Annotations[stdoutDataAnnotation]; ok {
+			logOutput = os.Stderr
+		}
+		if err := initConfig(logOutput); err != nil {
+			// doctor reports an invalid config as a failed check instead of aborting
+			if cmd.Name() == "doctor" && logger != nil {
+				configErr = err
+				return nil
+			}
+			return err
+		}
+		return nil
 	},
 }
 
@@ -56,8 +78,8 @@ func SetVersionInfo(v, c, d string) {
 func init() {
 	// Persistent flags available to all commands
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is ./config.yaml)")
-	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug logging")
-	rootCmd.PersistentFlags().BoolVar(&jsonLogs, "json", false, "output logs in JSON format")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug logging (overrides logging.level)")
+	rootCmd.PersistentFlags().BoolVar(&jsonLogs, "json", false, "output logs in JSON format (overrides logging.format)")
 	rootCmd.PersistentFlags().StringVar(&dbDriver, "db-driver", "", "database driver override (postgres/sqlite)")
 
 	// Bind flags to viper
@@ -72,26 +94,85 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(reclassifyCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(themesCmd)
+	rootCmd.AddCommand(channelsCmd)
+	rootCmd.AddCommand(llmCmd)
 	rootCmd.AddCommand(scanCmd)
 	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(traktCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(blocklistCmd)
+	rootCmd.AddCommand(apikeyCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(dbCmd)
+	rootCmd.AddCommand(automationCmd)
+	rootCmd.AddCommand(healthcheckCmd)
 }
 
-func initConfig() error {
-	// Initialize logger with enhanced formatting
-	logLevel := slog.LevelInfo
-	if debug {
-		logLevel = slog.LevelDebug
+func initConfig(logOutput io.Writer) error {
+	// Load configuration; logging falls back to its defaults when it fails, so the error
+	// is still logged (and reported by doctor)
+	var err error
+	cfg, err = config.Load(cfgFile)
+	logCfg := config.LoggingConfig{}
+	if err == nil {
+		logCfg = cfg.Logging
+	}
+	if lerr := initLogger(logOutput, logCfg); lerr != nil {
+		return lerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	upstream.Configure(&cfg.Upstream, componentLogger("upstream"))
+	httpx.SetLogger(componentLogger("upstream"))
+
+	logger.Info("configuration loaded",
+		"config_file", func() string {
+			if cfgFile != "" {
+				return cfgFile
+			}
+			return "default"
+		}(),
+		"database_driver", cfg.Database.Driver,
+		"themes_count", len(cfg.Themes),
+	)
+
+	return nil
+}
+
+// initLogger sets logger from the logging config, with --debug forcing the debug level and
+// --json the JSON format. Logs go to logOutput and, when set, the log file as well.
+func initLogger(logOutput io.Writer, logCfg config.LoggingConfig) error {
+	logLevel := levelOf(logCfg.SlogLevel())
+	// The shared handler takes the lowest level of any component; each logger filters above it
+	minLevel := logLevel
+	for component := range logCfg.Levels {
+		minLevel = min(minLevel, levelOf(logCfg.ComponentLevel(component)))
+	}
+	format := logCfg.Format
+	if jsonLogs || format == "" {
+		format = map[bool]string{true: "json", false: "text"}[jsonLogs]
+	}
+
+	if logCfg.File != "" {
+		file, err := logging.OpenRotatingFile(logCfg.File, logCfg.MaxSizeMB, logCfg.MaxBackups)
+		if err != nil {
+			return err
+		}
+		logOutput = io.MultiWriter(logOutput, file)
 	}
 
 	// Choose handler based on format preference
-	var handler slog.Handler
 	handlerOpts := &slog.HandlerOptions{
-		Level:     logLevel,
-		AddSource: debug, // Add source file/line in debug mode
+		Level:     minLevel,
+		AddSource: minLevel <= slog.LevelDebug, // Add source file/line in debug mode
 		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
 			// Customize time format for text output
-			if a.Key == slog.TimeKey && !jsonLogs {
+			if a.Key == slog.TimeKey && format == "text" {
 				return slog.Attr{
 					Key:   a.Key,
 					Value: slog.StringValue(a.Value.Time().Format("2006-01-02 15:04:05")),
@@ -101,42 +182,47 @@ func initConfig() error {
 		},
 	}
 
-	if jsonLogs {
-		handler = slog.NewJSONHandler(os.Stdout, handlerOpts)
+	if format == "json" {
+		logHandler = slog.NewJSONHandler(logOutput, handlerOpts)
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, handlerOpts)
+		logHandler = slog.NewTextHandler(logOutput, handlerOpts)
 	}
-
-	// Add application context
-	logger = slog.New(handler).With(
-		"version", version,
-		"app", "program-director",
-	)
+	logConfig = logCfg
+	logger = newLogger(logLevel)
 
 	logger.Debug("logger initialized",
 		"level", logLevel.String(),
-		"format", map[bool]string{true: "json", false: "text"}[jsonLogs],
+		"format", format,
+		"file", logCfg.File,
+		"levels", logCfg.Levels,
 	)
+	return nil
+}
 
-	// Load configuration
-	var err error
-	cfg, err = config.Load(cfgFile)
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+// levelOf returns level, info for an invalid level, or debug with --debug
+func levelOf(level slog.Level, err error) slog.Level {
+	switch {
+	case debug:
+		return slog.LevelDebug
+	case err != nil:
+		return slog.LevelInfo
 	}
+	return level
+}
 
-	logger.Info("configuration loaded",
-		"config_file", func() string {
-			if cfgFile != "" {
-				return cfgFile
-			}
-			return "default"
-		}(),
-		"database_driver", cfg.Database.Driver,
-		"themes_count", len(cfg.Themes),
+// newLogger returns a logger of the shared handler that logs at level and above
+func newLogger(level slog.Level) *slog.Logger {
+	// Add application context; request IDs are added from the context of each record
+	return slog.New(logging.NewContextHandler(logging.NewLevelHandler(level, logHandler))).With(
+		"version", version,
+		"app", "program-director",
 	)
+}
 
-	return nil
+// componentLogger returns the logger of one of config.LogComponents, at its logging.levels
+// level, tagging its records with the component
+func componentLogger(component string) *slog.Logger {
+	return newLogger(levelOf(logConfig.ComponentLevel(component))).With("component", component)
 }
 
 // versionCmd represents the version command
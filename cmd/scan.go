@@ -65,7 +65,7 @@ func runScan(_ *cobra.Command, _ []string) error {
 	)
 
 	logger.Debug("initializing database connection")
-	db, err := database.New(ctx, &cfg.Database, logger)
+	db, err := database.New(ctx, &cfg.Database, componentLogger("database"))
 	if err != nil {
 		logger.Error("failed to initialize database", "error", err)
 		return fmt.Errorf("failed to initialize database: %w", err)
@@ -5,22 +5,33 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"reflect"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/geekxflood/program-director/internal/alerts"
+	"github.com/geekxflood/program-director/internal/clients/mdblist"
 	"github.com/geekxflood/program-director/internal/clients/ollama"
 	"github.com/geekxflood/program-director/internal/clients/radarr"
 	"github.com/geekxflood/program-director/internal/clients/sonarr"
+	"github.com/geekxflood/program-director/internal/clients/tautulli"
 	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/clients/upstream"
+	"github.com/geekxflood/program-director/internal/config"
 	"github.com/geekxflood/program-director/internal/database"
 	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/events"
+	"github.com/geekxflood/program-director/internal/notify"
 	"github.com/geekxflood/program-director/internal/scheduler"
 	"github.com/geekxflood/program-director/internal/server"
 	"github.com/geekxflood/program-director/internal/services/cooldown"
+	"github.com/geekxflood/program-director/internal/services/lists"
 	"github.com/geekxflood/program-director/internal/services/media"
 	"github.com/geekxflood/program-director/internal/services/playlist"
 	"github.com/geekxflood/program-director/internal/services/similarity"
+	"github.com/geekxflood/program-director/internal/services/watched"
 )
 
 var (
@@ -28,6 +39,7 @@ var (
 	serveEnableScheduler bool
 	serveScheduleCron    string
 	serveMetricsEnabled  bool
+	serveWatchConfig     bool
 )
 
 // serveCmd represents the serve command
@@ -52,7 +64,10 @@ Examples:
   program-director serve --enable-scheduler
 
   # Disable prometheus metrics
-  program-director serve --metrics=false`,
+  program-director serve --metrics=false
+
+  # Reload themes after editing the config (also happens on file change)
+  kill -HUP $(pidof program-director)`,
 	RunE: runServe,
 }
 
@@ -61,6 +76,7 @@ func init() {
 	serveCmd.Flags().BoolVar(&serveEnableScheduler, "enable-scheduler", false, "enable built-in cron scheduler")
 	serveCmd.Flags().StringVar(&serveScheduleCron, "schedule", "0 2 * * *", "cron schedule for automated generation (default: daily at 2 AM)")
 	serveCmd.Flags().BoolVar(&serveMetricsEnabled, "metrics", true, "enable prometheus metrics endpoint")
+	serveCmd.Flags().BoolVar(&serveWatchConfig, "watch-config", true, "reload themes, cooldowns, and the sync schedule on SIGHUP or config file changes")
 }
 
 func runServe(_ *cobra.Command, _ []string) error {
@@ -80,12 +96,13 @@ func runServe(_ *cobra.Command, _ []string) error {
 		"port", servePort,
 		"scheduler", serveEnableScheduler,
 		"metrics", serveMetricsEnabled,
+		"read_only", cfg.Server.ReadOnly,
 	)
 
 	logger.Debug("initializing database connection")
 
 	// Initialize database
-	db, err := database.New(ctx, &cfg.Database, logger)
+	db, err := database.New(ctx, &cfg.Database, componentLogger("database"))
 	if err != nil {
 		logger.Error("failed to initialize database", "error", err)
 		return fmt.Errorf("failed to initialize database: %w", err)
@@ -108,34 +125,77 @@ func runServe(_ *cobra.Command, _ []string) error {
 	mediaRepo := repository.NewMediaRepository(db)
 	historyRepo := repository.NewHistoryRepository(db)
 	cooldownRepo := repository.NewCooldownRepository(db)
+	snapshotRepo := repository.NewSnapshotRepository(db)
+	listRepo := repository.NewListRepository(db)
+	watchRepo := repository.NewWatchHistoryRepository(db)
+	blocklistRepo := repository.NewBlocklistRepository(db)
+	generationRepo := repository.NewGenerationRepository(db)
+	llmUsageRepo := repository.NewLLMUsageRepository(db)
+	lineupRepo := repository.NewLineupRepository(db)
+	pauseRepo := repository.NewPauseRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
 
 	logger.Debug("initializing API clients",
-		"radarr_url", cfg.Radarr.URL,
-		"sonarr_url", cfg.Sonarr.URL,
+		"radarr", instanceURLs(cfg.Radarr),
+		"sonarr", instanceURLs(cfg.Sonarr),
 		"tunarr_url", cfg.Tunarr.URL,
 		"ollama_url", cfg.Ollama.URL,
 	)
 
 	// Initialize API clients
-	radarrClient := radarr.New(&cfg.Radarr)
-	sonarrClient := sonarr.New(&cfg.Sonarr)
 	tunarrClient := tunarr.New(&cfg.Tunarr)
-	ollamaClient := ollama.New(&cfg.Ollama)
+	ollamaClient := newOllamaClient()
+	radarrClients := newRadarrClients()
+	sonarrClients := newSonarrClients()
+
+	if ollamaClient != nil && (cfg.Ollama.AutoPull || cfg.Ollama.WarmUp) {
+		go prepareOllama(ctx, ollamaClient)
+	}
 
 	logger.Debug("initializing services")
 
 	// Initialize services
-	syncService := media.NewSyncService(radarrClient, sonarrClient, mediaRepo, logger)
-	cooldownManager := cooldown.NewManager(cooldownRepo, historyRepo, &cfg.Cooldown, logger)
-	similarityScorer := similarity.NewScorer(mediaRepo, ollamaClient, logger)
-	playlistGenerator := playlist.NewGenerator(tunarrClient, similarityScorer, cooldownManager, logger)
+	syncService := media.NewSyncService(radarrClients, sonarrClients, newTMDBClient(), newAnimeDetector(), mediaRepo, repository.NewCollectionRepository(db), repository.NewSyncCheckpointRepository(db), componentLogger("sync"))
+	cooldownManager := cooldown.NewManager(cooldownRepo, historyRepo, watchRepo, &cfg.Cooldown, componentLogger("cooldown"))
+	similarityScorer := similarity.NewScorer(mediaRepo, ollamaClient, newOverseerrClient(), newTraktClient(), listRepo, blocklistRepo, componentLogger("similarity"))
+	similarityScorer.SetLLMLogger(componentLogger("ollama"))
+	similarityScorer.SetFallbackModels(cfg.LLM.FallbackModels)
+	similarityScorer.SetPrompts(cfg.LLM.Prompts)
+	playlistGenerator := playlist.NewGenerator(tunarrClient, similarityScorer, cooldownManager, snapshotRepo, generationRepo, llmUsageRepo, lineupRepo, &cfg.Generation, componentLogger("generation"))
+	traceRepo := repository.NewLLMTraceRepository(db)
+	if cfg.LLM.Traces.Enabled {
+		playlistGenerator.SetTraces(traceRepo, cfg.LLM.Traces.RetentionDays)
+	}
+
+	// Live events for /api/v1/ws clients
+	eventBus := events.NewBus()
+	cooldownManager.SetEvents(eventBus)
+	playlistGenerator.SetEvents(eventBus)
 
 	logger.Debug("initializing HTTP server")
 
+	reloader := config.NewReloader(cfg.File, cfg, logger)
+	notifier := notify.New(&cfg.Notify, componentLogger("notify"))
+	alertMonitor := alerts.New(&cfg.Alerts, generationRepo, notifier, componentLogger("notify"))
+
 	// Create HTTP server
 	serverCfg := &server.Config{
 		Port:           servePort,
 		MetricsEnabled: serveMetricsEnabled,
+		Upstreams:      upstreams(radarrClients, sonarrClients, tunarrClient, ollamaClient),
+		Reloader:       reloader,
+		Notifier:       notifier,
+		Alerts:         alertMonitor,
+		DB:             db,
+		Lineups:        lineupRepo,
+		Tunarr:         tunarrClient,
+		Pause:          pauseRepo,
+		Audit:          auditRepo,
+		Events:         eventBus,
+		Traces:         traceRepo,
+
+		ShutdownTimeout: time.Duration(cfg.Server.ShutdownTimeout) * time.Second,
 	}
 
 	httpServer := server.NewServer(
@@ -144,10 +204,14 @@ func runServe(_ *cobra.Command, _ []string) error {
 		mediaRepo,
 		historyRepo,
 		cooldownRepo,
+		blocklistRepo,
+		generationRepo,
+		apiKeyRepo,
+		llmUsageRepo,
 		syncService,
 		playlistGenerator,
 		cooldownManager,
-		logger,
+		componentLogger("server"),
 	)
 
 	// Print server info
@@ -160,20 +224,59 @@ func runServe(_ *cobra.Command, _ []string) error {
 		fmt.Println("  GET  /metrics             - Prometheus metrics")
 	}
 	fmt.Println("  GET  /api/v1/media        - List media")
+	fmt.Println("  GET  /api/v1/media/:id    - Media detail and history")
+	fmt.Println("  GET  /api/v1/media/search - Search titles and overviews")
+	fmt.Println("  POST /api/v1/media/{source}/{external_id}/refresh - Refresh one title")
 	fmt.Println("  POST /api/v1/media/sync   - Trigger sync")
+	fmt.Println("  GET  /api/v1/media/sync/status - Sync progress")
+	fmt.Println("  GET  /api/v1/media/sync/events - Sync progress stream (SSE)")
 	fmt.Println("  GET  /api/v1/themes       - List themes")
+	fmt.Println("  GET  /api/v1/themes/:name/candidates - Preview ranked candidates")
+	fmt.Println("  GET  /api/v1/themes/:name/compare - Compare scoring strategies")
 	fmt.Println("  POST /api/v1/generate     - Generate all playlists")
 	fmt.Println("  POST /api/v1/generate/:id - Generate specific theme")
+	fmt.Println("  POST /api/v1/undo/:id     - Restore previous lineup")
 	fmt.Println("  GET  /api/v1/history      - Play history")
+	fmt.Println("  GET  /api/v1/history/export - Export play history as CSV or JSON")
 	fmt.Println("  GET  /api/v1/cooldowns    - Current cooldowns")
-	fmt.Println("  POST /api/v1/webhooks     - Webhook triggers")
+	fmt.Println("  *    /api/v1/blocklist    - List, add, or remove blocked media")
+	fmt.Println("  GET  /api/v1/generations  - Generation runs")
+	fmt.Println("  GET  /api/v1/generations/:id/traces - LLM prompts and responses of a run")
+	fmt.Println("  GET  /api/v1/lineups      - Applied lineups with air times")
+	fmt.Println("  GET  /api/v1/guide.xml    - XMLTV guide of applied lineups")
+	fmt.Println("  GET  /api/v1/channels.m3u - M3U playlist of managed channels")
+	fmt.Println("  GET  /api/v1/scheduler    - Scheduled generation per theme")
+	fmt.Println("  POST /api/v1/scheduler/:theme/run|pause|resume - Control a theme's schedule")
+	fmt.Println("  *    /api/v1/pause        - Show, pause (POST), or resume (DELETE) automation")
+	fmt.Println("  GET  /api/v1/audit        - Who triggered generations")
+	fmt.Println("  GET  /api/v1/stats/llm    - LLM usage per theme")
+	fmt.Println("  GET  /api/v1/reports/utilization - Never-scheduled media by genre")
+	fmt.Println("  GET  /api/v1/status       - Upstream dependency health")
+	fmt.Println("  POST /api/v1/admin/reload - Reload config and show changes")
+	fmt.Println("  POST /api/v1/webhooks/radarr - Radarr events")
+	fmt.Println("  POST /api/v1/webhooks/sonarr - Sonarr events")
+	fmt.Println("  POST /api/v1/webhooks/tunarr - Tunarr events")
+	fmt.Println("  POST /api/v1/webhooks/plex - Plex play events")
 	fmt.Println()
 
-	// Initialize scheduler if enabled
+	// Initialize scheduler if generation or sync is scheduled
+	// Only SQLite needs maintenance; PostgreSQL has autovacuum
+	maintainer, _ := db.(database.Maintainer)
+	maintenanceSchedule := ""
+	if maintainer != nil {
+		maintenanceSchedule = cfg.Database.SQLite.MaintenanceSchedule
+	}
+
 	var sched *scheduler.Scheduler
-	if serveEnableScheduler {
+	if serveEnableScheduler || cfg.Sync.Schedule != "" || cfg.History.RetentionDays > 0 || maintenanceSchedule != "" {
+		generationSchedule := ""
+		if serveEnableScheduler {
+			generationSchedule = serveScheduleCron
+		}
+
 		logger.Info("initializing scheduler",
-			"schedule", serveScheduleCron,
+			"schedule", generationSchedule,
+			"sync_schedule", cfg.Sync.Schedule,
 			"themes", len(cfg.Themes),
 		)
 
@@ -183,25 +286,115 @@ func runServe(_ *cobra.Command, _ []string) error {
 		}
 
 		var err error
-		sched, err = scheduler.NewScheduler(schedulerCfg, playlistGenerator, cfg.Themes, logger)
+		sched, err = scheduler.NewScheduler(schedulerCfg, playlistGenerator, cfg.Themes, componentLogger("scheduler"))
 		if err != nil {
 			return fmt.Errorf("failed to create scheduler: %w", err)
 		}
+		sched.SetNotifier(notifier)
+		sched.SetAlerts(alertMonitor)
+		sched.SetPause(pauseRepo)
+		sched.SetAudit(auditRepo)
+		sched.SetEvents(eventBus)
+		// Scheduled syncs also import lists and watch history, as a plain `sync` does
+		if len(cfg.Lists) > 0 {
+			sched.SetListSync(lists.NewSyncService(mdblist.New(), listRepo, componentLogger("sync")), cfg.Lists)
+		}
+		if cfg.Tautulli.URL != "" {
+			sched.SetWatchedSync(watched.NewSyncService(tautulli.New(&cfg.Tautulli), mediaRepo, watchRepo, componentLogger("sync")))
+		}
+		// Only one of several replicas sharing the database runs scheduled jobs
+		if elector, ok := db.(database.Elector); ok {
+			sched.SetLeaderLock(elector.LeaderLock())
+		}
+
+		httpServer.SetScheduler(sched)
+
+		if cfg.Sync.Schedule != "" {
+			if err := sched.ScheduleSync(cfg.Sync.Schedule, cfg.Sync.Cleanup, syncService); err != nil {
+				return fmt.Errorf("failed to schedule sync: %w", err)
+			}
+		}
+		if err := sched.SchedulePrune(cfg.History, historyRepo); err != nil {
+			return fmt.Errorf("failed to schedule history pruning: %w", err)
+		}
+		if maintenanceSchedule != "" {
+			if err := sched.ScheduleMaintenance(maintenanceSchedule, cfg.Database.SQLite.Vacuum, maintainer); err != nil {
+				return fmt.Errorf("failed to schedule database maintenance: %w", err)
+			}
+		}
 
 		// Start scheduler in goroutine
 		go func() {
-			if err := sched.Start(ctx, serveScheduleCron, false); err != nil {
+			if err := sched.Start(ctx, generationSchedule, false); err != nil {
 				logger.Error("scheduler error", "error", err)
 			}
 		}()
 
-		fmt.Printf("Scheduler: Enabled (cron: %s)\n", serveScheduleCron)
+		if serveEnableScheduler {
+			fmt.Printf("Scheduler: Enabled (cron: %s)\n", serveScheduleCron)
+		}
+		if cfg.Sync.Schedule != "" {
+			fmt.Printf("Sync: Enabled (cron: %s, cleanup: %t)\n", cfg.Sync.Schedule, cfg.Sync.Cleanup)
+		}
+		if cfg.History.RetentionDays > 0 {
+			fmt.Printf("History pruning: Enabled (cron: %s, retention: %d days)\n", cfg.History.PruneSchedule, cfg.History.RetentionDays)
+		}
+		if maintenanceSchedule != "" {
+			fmt.Printf("SQLite maintenance: Enabled (cron: %s, vacuum: %t)\n", maintenanceSchedule, cfg.Database.SQLite.Vacuum)
+		}
 		if nextRun := sched.GetNextRun(); !nextRun.IsZero() {
 			fmt.Printf("Next run: %s\n", nextRun.Format("2006-01-02 15:04:05 MST"))
 		}
 		fmt.Println()
 	}
 
+	// Apply theme, cooldown, and sync schedule changes on SIGHUP, when the config file
+	// changes, or on POST /api/v1/admin/reload
+	reloader.OnReload(func(old, updated *config.Config) error {
+		sections := restartSections(old, updated)
+		if sched == nil && updated.Sync.Schedule != old.Sync.Schedule {
+			sections = append(sections, "sync.schedule")
+		}
+		if sched == nil && updated.History != old.History {
+			sections = append(sections, "history")
+		}
+		if len(sections) > 0 {
+			logger.Warn("config changes need a restart to take effect", "sections", sections)
+		}
+		return nil
+	})
+	if sched != nil {
+		reloader.OnReload(func(old, updated *config.Config) error {
+			if updated.Sync != old.Sync {
+				if err := sched.ScheduleSync(updated.Sync.Schedule, updated.Sync.Cleanup, syncService); err != nil {
+					return fmt.Errorf("failed to reschedule sync: %w", err)
+				}
+			}
+			if updated.History != old.History {
+				if err := sched.SchedulePrune(updated.History, historyRepo); err != nil {
+					return fmt.Errorf("failed to reschedule history pruning: %w", err)
+				}
+			}
+			sched.SetThemes(updated.Themes)
+			return nil
+		})
+	}
+	reloader.OnReload(func(_, updated *config.Config) error {
+		cooldownManager.SetConfig(&updated.Cooldown)
+		upstream.Configure(&updated.Upstream, componentLogger("upstream"))
+		notifier.SetConfig(&updated.Notify)
+		alertMonitor.SetConfig(&updated.Alerts)
+		httpServer.SetConfig(updated)
+		return nil
+	})
+	if serveWatchConfig {
+		go func() {
+			if err := reloader.Watch(ctx); err != nil {
+				logger.Error("config watcher stopped", "error", err)
+			}
+		}()
+	}
+
 	// Start HTTP server (blocking)
 	if err := httpServer.Start(ctx, servePort); err != nil {
 		return fmt.Errorf("server error: %w", err)
@@ -218,3 +411,78 @@ func runServe(_ *cobra.Command, _ []string) error {
 	logger.Info("server shutdown complete")
 	return nil
 }
+
+// prepareOllama pulls the model when it is missing and auto_pull is set, then loads it so
+// the first generation doesn't wait for it. It runs in the background.
+func prepareOllama(ctx context.Context, client *ollama.Client) {
+	if cfg.Ollama.AutoPull {
+		logger.Info("checking ollama model", "model", cfg.Ollama.Model)
+		pulled, err := client.EnsureModel(ctx, nil)
+		if err != nil {
+			logger.Error("failed to pull ollama model", "model", cfg.Ollama.Model, "error", err)
+			return
+		}
+		if pulled {
+			logger.Info("ollama model pulled", "model", cfg.Ollama.Model)
+		}
+	}
+	if !cfg.Ollama.WarmUp {
+		return
+	}
+
+	start := time.Now()
+	if err := client.WarmUp(ctx); err != nil {
+		logger.Warn("ollama warm-up failed", "model", cfg.Ollama.Model, "error", err)
+		return
+	}
+	logger.Info("ollama model loaded", "model", cfg.Ollama.Model, "keep_alive", cfg.Ollama.KeepAlive, "duration", time.Since(start))
+}
+
+// upstreams lists the dependencies reported by /api/v1/status
+func upstreams(radarrs []*radarr.Client, sonarrs []*sonarr.Client, tunarrClient *tunarr.Client, ollamaClient *ollama.Client) []server.Upstream {
+	list := make([]server.Upstream, 0, len(radarrs)+len(sonarrs)+2)
+	for _, c := range radarrs {
+		list = append(list, server.Upstream{Name: "radarr:" + c.Name(), Check: c.HealthCheck})
+	}
+	for _, c := range sonarrs {
+		list = append(list, server.Upstream{Name: "sonarr:" + c.Name(), Check: c.HealthCheck})
+	}
+	list = append(list, server.Upstream{Name: "tunarr", Check: tunarrClient.HealthCheck})
+	if ollamaClient != nil {
+		list = append(list, server.Upstream{Name: "ollama", Check: ollamaClient.HealthCheck})
+	}
+	return list
+}
+
+// restartSections lists the changed config sections that are only read at startup
+func restartSections(old, updated *config.Config) []string {
+	var sections []string
+	for _, section := range []struct {
+		name       string
+		old, value any
+	}{
+		{"database", old.Database, updated.Database},
+		{"radarr", old.Radarr, updated.Radarr},
+		{"sonarr", old.Sonarr, updated.Sonarr},
+		{"tunarr", old.Tunarr, updated.Tunarr},
+		{"ollama", old.Ollama, updated.Ollama},
+		{"llm", old.LLM, updated.LLM},
+		{"trakt", old.Trakt, updated.Trakt},
+		{"overseerr", old.Overseerr, updated.Overseerr},
+		{"tmdb", old.TMDB, updated.TMDB},
+		{"tautulli", old.Tautulli, updated.Tautulli},
+		{"generation", old.Generation, updated.Generation},
+		{"lists", old.Lists, updated.Lists},
+	} {
+		if !reflect.DeepEqual(section.old, section.value) {
+			sections = append(sections, section.name)
+		}
+	}
+	if old.Sync.AnimeDetection != updated.Sync.AnimeDetection || old.Sync.AnimeMappingURL != updated.Sync.AnimeMappingURL {
+		sections = append(sections, "sync.anime_detection")
+	}
+	if old.Server.RateLimit != updated.Server.RateLimit || old.Server.RateBurst != updated.Server.RateBurst {
+		sections = append(sections, "server.rate_limit")
+	}
+	return sections
+}
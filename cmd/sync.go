@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,17 +10,23 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/geekxflood/program-director/internal/clients/radarr"
-	"github.com/geekxflood/program-director/internal/clients/sonarr"
+	"github.com/geekxflood/program-director/internal/clients/mdblist"
+	"github.com/geekxflood/program-director/internal/clients/tautulli"
 	"github.com/geekxflood/program-director/internal/database"
 	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/notify"
+	"github.com/geekxflood/program-director/internal/services/lists"
 	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/internal/services/watched"
 )
 
 var (
 	syncMovies  bool
 	syncSeries  bool
 	syncCleanup bool
+	syncLists   bool
+	syncWatched bool
+	syncDryRun  bool
 )
 
 // syncCmd represents the sync command
@@ -30,7 +37,10 @@ var syncCmd = &cobra.Command{
 
 This command fetches all media metadata from your media management
 applications and stores it in the local database for fast querying
-during playlist generation.
+during playlist generation. Configured mdblist and IMDb lists are
+imported as well, for use by themes' include_lists and exclude_lists,
+and when Tautulli is configured the household's watch history is
+imported so recently watched titles can be avoided.
 
 Examples:
   # Sync all media (movies and series)
@@ -42,8 +52,17 @@ Examples:
   # Sync only series (TV shows and anime)
   program-director sync --series
 
+  # Import only the configured mdblist/IMDb lists
+  program-director sync --lists
+
+  # Import only watch history from Tautulli
+  program-director sync --watched
+
   # Sync and cleanup removed media
-  program-director sync --cleanup`,
+  program-director sync --cleanup
+
+  # Show what a cleanup sync would create, update, and delete without writing
+  program-director sync --cleanup --dry-run`,
 	RunE: runSync,
 }
 
@@ -51,6 +70,9 @@ func init() {
 	syncCmd.Flags().BoolVar(&syncMovies, "movies", false, "sync only movies from Radarr")
 	syncCmd.Flags().BoolVar(&syncSeries, "series", false, "sync only series from Sonarr")
 	syncCmd.Flags().BoolVar(&syncCleanup, "cleanup", false, "remove media no longer in source")
+	syncCmd.Flags().BoolVar(&syncLists, "lists", false, "sync only configured mdblist/IMDb lists")
+	syncCmd.Flags().BoolVar(&syncWatched, "watched", false, "sync only watch history from Tautulli")
+	syncCmd.Flags().BoolVarP(&syncDryRun, "dry-run", "n", false, "report movie/series changes without writing them")
 }
 
 func runSync(_ *cobra.Command, _ []string) error {
@@ -67,24 +89,36 @@ func runSync(_ *cobra.Command, _ []string) error {
 	}()
 
 	// Default to syncing everything if no specific flags
-	syncAll := !syncMovies && !syncSeries
+	syncAll := !syncMovies && !syncSeries && !syncLists && !syncWatched
 	if syncAll {
 		syncMovies = true
 		syncSeries = true
+		// Dry runs only cover the Radarr/Sonarr catalog
+		syncLists = len(cfg.Lists) > 0 && !syncDryRun
+		syncWatched = cfg.Tautulli.URL != "" && !syncDryRun
+	} else if syncDryRun && (syncLists || syncWatched) {
+		return errors.New("--dry-run only applies to movies and series")
+	} else if syncLists && len(cfg.Lists) == 0 {
+		return errors.New("no lists configured")
+	} else if syncWatched && cfg.Tautulli.URL == "" {
+		return errors.New("tautulli is not configured")
 	}
 
 	logger.Info("starting media sync",
 		"movies", syncMovies,
 		"series", syncSeries,
+		"lists", syncLists,
+		"watched", syncWatched,
 		"cleanup", syncCleanup,
-		"radarr_url", cfg.Radarr.URL,
-		"sonarr_url", cfg.Sonarr.URL,
+		"dry_run", syncDryRun,
+		"radarr", instanceURLs(cfg.Radarr),
+		"sonarr", instanceURLs(cfg.Sonarr),
 	)
 
 	logger.Debug("initializing sync services")
 
 	// Initialize database
-	db, err := database.New(ctx, &cfg.Database, logger)
+	db, err := database.New(ctx, &cfg.Database, componentLogger("database"))
 	if err != nil {
 		logger.Error("failed to initialize database", "error", err)
 		return fmt.Errorf("failed to initialize database: %w", err)
@@ -105,36 +139,71 @@ func runSync(_ *cobra.Command, _ []string) error {
 	mediaRepo := repository.NewMediaRepository(db)
 
 	// Initialize API clients
-	radarrClient := radarr.New(&cfg.Radarr)
-	sonarrClient := sonarr.New(&cfg.Sonarr)
 
 	// Create sync service
-	syncService := media.NewSyncService(radarrClient, sonarrClient, mediaRepo, logger)
+	syncService := media.NewSyncService(newRadarrClients(), newSonarrClients(), newTMDBClient(), newAnimeDetector(), mediaRepo, repository.NewCollectionRepository(db), repository.NewSyncCheckpointRepository(db), componentLogger("sync"))
 
 	var results []media.SyncResult
+	opts := media.SyncOptions{Cleanup: syncCleanup, DryRun: syncDryRun}
+
+	// Summaries of library syncs go to the configured notification targets
+	var runs []notify.SyncRun
+	notifier := notify.New(&cfg.Notify, componentLogger("notify"))
+	notifyRuns := func() {
+		if !syncDryRun && len(runs) > 0 {
+			notifier.NotifySync(ctx, runs)
+		}
+	}
 
 	if syncMovies {
 		logger.Info("syncing movies from Radarr",
-			"url", cfg.Radarr.URL,
+			"instances", instanceURLs(cfg.Radarr),
 		)
-		result, err := syncService.SyncMovies(ctx, syncCleanup)
+		result, err := syncService.SyncMovies(ctx, opts)
 		if err != nil {
 			logger.Error("movie sync failed", "error", err)
+			runs = append(runs, notify.SyncRun{Kind: "movies", Err: err})
+			notifyRuns()
 			return fmt.Errorf("movie sync failed: %w", err)
 		}
 		results = append(results, *result)
+		runs = append(runs, notify.SyncRun{Kind: "movies", Result: result})
 	}
 
 	if syncSeries {
 		logger.Info("syncing series from Sonarr",
-			"url", cfg.Sonarr.URL,
+			"instances", instanceURLs(cfg.Sonarr),
 		)
-		result, err := syncService.SyncSeries(ctx, syncCleanup)
+		result, err := syncService.SyncSeries(ctx, opts)
 		if err != nil {
 			logger.Error("series sync failed", "error", err)
+			runs = append(runs, notify.SyncRun{Kind: "series", Err: err})
+			notifyRuns()
 			return fmt.Errorf("series sync failed: %w", err)
 		}
 		results = append(results, *result)
+		runs = append(runs, notify.SyncRun{Kind: "series", Result: result})
+	}
+	notifyRuns()
+
+	var listResults []lists.SyncResult
+	if syncLists {
+		logger.Info("syncing lists", "count", len(cfg.Lists))
+		listService := lists.NewSyncService(mdblist.New(), repository.NewListRepository(db), componentLogger("sync"))
+		listResults = listService.SyncAll(ctx, cfg.Lists)
+	}
+
+	var watchResult *watched.SyncResult
+	if syncWatched {
+		logger.Info("syncing watch history from Tautulli", "url", cfg.Tautulli.URL)
+		watchService := watched.NewSyncService(
+			tautulli.New(&cfg.Tautulli), mediaRepo, repository.NewWatchHistoryRepository(db), componentLogger("sync"),
+		)
+		watchResult, err = watchService.Sync(ctx)
+		if err != nil {
+			logger.Error("watch history sync failed", "error", err)
+			return fmt.Errorf("watch history sync failed: %w", err)
+		}
 	}
 
 	// Calculate totals
@@ -157,6 +226,11 @@ func runSync(_ *cobra.Command, _ []string) error {
 		"errors", totalErrors,
 	)
 
+	if syncDryRun {
+		printSyncChanges(results)
+		return nil
+	}
+
 	// Display summary
 	fmt.Println()
 	fmt.Println("Sync Summary")
@@ -168,12 +242,81 @@ func runSync(_ *cobra.Command, _ []string) error {
 		if syncCleanup {
 			fmt.Printf("  Deleted:  %d\n", result.Deleted)
 		}
+		if result.Duplicates > 0 {
+			fmt.Printf("  Duplicates: %d\n", result.Duplicates)
+		}
+		if result.Resumed > 0 {
+			fmt.Printf("  Resumed:  %d (stored by the interrupted sync)\n", result.Resumed)
+		}
+		if result.Enriched > 0 {
+			fmt.Printf("  Enriched: %d\n", result.Enriched)
+		}
 		if result.Errors > 0 {
 			fmt.Printf("  Errors:   %d\n", result.Errors)
 		}
 		fmt.Printf("  Duration: %s\n", result.Duration)
 	}
+
+	if watchResult != nil {
+		fmt.Printf("\ntautulli:\n")
+		fmt.Printf("  Fetched:   %d\n", watchResult.Fetched)
+		fmt.Printf("  Created:   %d\n", watchResult.Created)
+		fmt.Printf("  Unmatched: %d\n", watchResult.Unmatched)
+		if watchResult.Errors > 0 {
+			fmt.Printf("  Errors:    %d\n", watchResult.Errors)
+		}
+		fmt.Printf("  Duration:  %s\n", watchResult.Duration)
+	}
+
+	listFailures := 0
+	if len(listResults) > 0 {
+		fmt.Printf("\nlists:\n")
+		for _, result := range listResults {
+			if result.Error != nil {
+				listFailures++
+				fmt.Printf("  %-20s failed: %v\n", result.Name, result.Error)
+				continue
+			}
+			fmt.Printf("  %-20s %d items (%s)\n", result.Name, result.Items, result.Duration)
+		}
+	}
 	fmt.Println()
 
+	if listFailures > 0 {
+		return fmt.Errorf("%d list(s) failed to sync", listFailures)
+	}
+
 	return nil
 }
+
+// printSyncChanges prints the titles a dry-run sync would create, update, and delete
+func printSyncChanges(results []media.SyncResult) {
+	fmt.Println()
+	fmt.Println("Sync Dry Run (nothing was written)")
+	fmt.Println("==================================")
+	for _, result := range results {
+		changes := result.Changes
+		if changes == nil {
+			continue
+		}
+
+		fmt.Printf("\n%s: %d to create, %d to update, %d to delete\n",
+			result.Source, len(changes.Created), len(changes.Updated), len(changes.Deleted))
+		for _, section := range []struct {
+			sign   string
+			titles []string
+		}{
+			{"+", changes.Created},
+			{"~", changes.Updated},
+			{"-", changes.Deleted},
+		} {
+			for _, title := range section.titles {
+				fmt.Printf("  %s %s\n", section.sign, title)
+			}
+		}
+	}
+	if !syncCleanup {
+		fmt.Println("\nDeletions are only reported with --cleanup")
+	}
+	fmt.Println()
+}
@@ -0,0 +1,503 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/services/playlist"
+)
+
+var (
+	previewWithLLM bool
+	previewLimit   int
+	validateStrict bool
+
+	compareStrategies []string
+	compareLimit      int
+
+	snapshotDir      string
+	snapshotSeed     uint64
+	snapshotWithLLM  bool
+	snapshotLimit    int
+	snapshotMaxDrift float64
+)
+
+// themesCmd represents the themes command
+var themesCmd = &cobra.Command{
+	Use:   "themes",
+	Short: "Inspect configured themes",
+	Long: `Inspect configured themes without generating playlists.
+
+Examples:
+  # Show the ranked candidates for a theme
+  program-director themes preview sci-fi-night
+
+  # Include LLM refinement and show the top 50
+  program-director themes preview sci-fi-night --with-llm --limit 50
+
+  # Rank with and without the LLM side by side
+  program-director themes compare sci-fi-night --strategy heuristic --strategy llm
+
+  # Check every theme against the library, failing on warnings
+  program-director themes validate --strict
+
+  # Save golden lineups, then check for drift after a config or model change
+  program-director themes snapshot save
+  program-director themes snapshot check --max-drift 0.2`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// themesPreviewCmd shows a theme's ranked candidates
+var themesPreviewCmd = &cobra.Command{
+	Use:   "preview <name>",
+	Short: "Show a theme's ranked candidates without touching Tunarr or cooldowns",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runThemesPreview,
+}
+
+// themesCompareCmd ranks a theme's candidates with several strategies side by side
+var themesCompareCmd = &cobra.Command{
+	Use:   "compare <name>",
+	Short: "Compare the rankings of scoring strategies for a theme",
+	Long: `Rank a theme's candidates with each scoring strategy and show the rankings
+side by side, with how long each took, the LLM tokens it used, and how much
+each pair of rankings overlaps, to decide whether the LLM is worth its latency.
+
+Strategies are heuristic (genre/keyword scores) and llm (scores refined by
+the LLM, as generation does). Nothing is written.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runThemesCompare,
+}
+
+// themesValidateCmd checks themes against the catalog
+var themesValidateCmd = &cobra.Command{
+	Use:   "validate [name...]",
+	Short: "Check themes against the library for filters that cannot be satisfied",
+	Long: `Check themes against the media library before a scheduled run fails silently.
+
+Each theme (all of them unless names are given) is checked for genres that
+match no media, a min_rating that excludes every genre match, no candidates
+left after cooldowns and the blocklist, and a target duration the remaining
+candidates cannot fill. The LLM is not called and nothing is written.`,
+	RunE: runThemesValidate,
+}
+
+// themesSnapshotCmd groups the golden lineup commands
+var themesSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save golden lineups for themes and check regenerations for drift",
+	Long: `Save the lineup selected for each theme with a fixed seed as a golden file,
+then select again after config, library, or model changes and flag themes
+whose lineup drifted more than expected.
+
+Cooldowns and watch history are ignored so that a lineup only moves when
+scoring does. Nothing is written to the database or Tunarr.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("failed to show help: %w", err)
+		}
+		return nil
+	},
+}
+
+// themesSnapshotSaveCmd saves golden lineups
+var themesSnapshotSaveCmd = &cobra.Command{
+	Use:   "save [name...]",
+	Short: "Save the golden lineup of themes (all of them unless names are given)",
+	RunE:  runThemesSnapshotSave,
+}
+
+// themesSnapshotCheckCmd compares regenerations to golden lineups
+var themesSnapshotCheckCmd = &cobra.Command{
+	Use:   "check [name...]",
+	Short: "Select themes again and compare them to their golden lineups",
+	Long: `Select each theme (all of them unless names are given) again with the seed,
+LLM setting, and limit of its golden lineup, and report the titles added and
+removed. Drift is the share of titles in either lineup that are not in both,
+from 0 to 1; the command fails when any theme exceeds --max-drift.`,
+	RunE: runThemesSnapshotCheck,
+}
+
+func init() {
+	themesCmd.AddCommand(themesPreviewCmd)
+	themesCmd.AddCommand(themesValidateCmd)
+	themesCmd.AddCommand(themesCompareCmd)
+	themesCmd.AddCommand(themesSnapshotCmd)
+	themesSnapshotCmd.AddCommand(themesSnapshotSaveCmd)
+	themesSnapshotCmd.AddCommand(themesSnapshotCheckCmd)
+
+	themesPreviewCmd.Flags().BoolVar(&previewWithLLM, "with-llm", false, "let the LLM refine the ranking, as generation does")
+	themesPreviewCmd.Flags().IntVar(&previewLimit, "limit", 0, "number of candidates to show (default: the theme's max_items)")
+	themesValidateCmd.Flags().BoolVar(&validateStrict, "strict", false, "exit with an error when any theme has warnings")
+	themesCompareCmd.Flags().StringSliceVar(&compareStrategies, "strategy",
+		[]string{playlist.StrategyHeuristic, playlist.StrategyLLM}, "strategy to compare (heuristic, llm); repeat for each")
+	themesCompareCmd.Flags().IntVar(&compareLimit, "limit", 0, "number of candidates to rank (default: the theme's max_items)")
+	themesSnapshotCmd.PersistentFlags().StringVar(&snapshotDir, "dir", "snapshots", "directory of golden lineup files")
+	themesSnapshotSaveCmd.Flags().Uint64Var(&snapshotSeed, "seed", 1, "seed for weighted_random selection")
+	themesSnapshotSaveCmd.Flags().BoolVar(&snapshotWithLLM, "with-llm", false, "let the LLM refine the ranking, as generation does")
+	themesSnapshotSaveCmd.Flags().IntVar(&snapshotLimit, "limit", 0, "number of titles to save (default: the theme's max_items)")
+	themesSnapshotCheckCmd.Flags().Float64Var(&snapshotMaxDrift, "max-drift", 0.3, "highest drift (0-1) accepted before failing")
+}
+
+func runThemesPreview(_ *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	theme := findTheme(args[0])
+	if theme == nil {
+		return fmt.Errorf("theme %q not found in configuration", args[0])
+	}
+	if previewLimit < 0 {
+		return errors.New("--limit must not be negative")
+	}
+
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer cleanup()
+
+	candidates, err := services.generator.Preview(ctx, theme, previewWithLLM, previewLimit)
+	if err != nil {
+		return fmt.Errorf("preview failed for theme %s: %w", theme.Name, err)
+	}
+
+	fmt.Printf("\nCandidates for %s (channel %s)\n", theme.Name, theme.ChannelID)
+	fmt.Println("========================================")
+	if len(candidates) == 0 {
+		fmt.Println("\nNo candidates match this theme.")
+		fmt.Println()
+		return nil
+	}
+
+	var totalRuntime int
+	fmt.Println()
+	for i, c := range candidates {
+		totalRuntime += c.Runtime
+		fmt.Printf("%3d. %5.2f  %s (%d) [%s] %s\n", i+1, c.Score, c.Title, c.Year, c.MediaType, c.MatchReason)
+	}
+	fmt.Printf("\n%d candidates, %d minutes\n\n", len(candidates), totalRuntime)
+
+	return nil
+}
+
+func runThemesCompare(_ *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	theme := findTheme(args[0])
+	if theme == nil {
+		return fmt.Errorf("theme %q not found in configuration", args[0])
+	}
+	if compareLimit < 0 {
+		return errors.New("--limit must not be negative")
+	}
+
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer cleanup()
+
+	comparison, err := services.generator.Compare(ctx, theme, compareStrategies, compareLimit)
+	if err != nil {
+		return fmt.Errorf("comparison failed for theme %s: %w", theme.Name, err)
+	}
+
+	const width = 40
+	fmt.Printf("\nStrategies for %s (channel %s)\n", theme.Name, theme.ChannelID)
+	fmt.Println("========================================")
+	fmt.Println()
+
+	rows := 0
+	header := "     "
+	for _, r := range comparison.Rankings {
+		rows = max(rows, len(r.Candidates))
+		header += fmt.Sprintf("%-*s", width, truncate(fmt.Sprintf("%s (%s, %d tokens)", r.Strategy,
+			time.Duration(r.DurationMS)*time.Millisecond, r.PromptTokens+r.EvalTokens), width-2))
+	}
+	fmt.Println(strings.TrimRight(header, " "))
+	for i := 0; i < rows; i++ {
+		line := fmt.Sprintf("%3d. ", i+1)
+		for _, r := range comparison.Rankings {
+			cell := ""
+			if i < len(r.Candidates) {
+				c := r.Candidates[i]
+				cell = fmt.Sprintf("%s (%d)", c.Title, c.Year)
+			}
+			line += fmt.Sprintf("%-*s", width, truncate(cell, width-2))
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+
+	fmt.Println()
+	for _, r := range comparison.Rankings {
+		for _, d := range r.Degraded {
+			fmt.Printf("%s degraded: %s\n", r.Strategy, d)
+		}
+	}
+	for _, o := range comparison.Overlap {
+		fmt.Printf("%s vs %s: %d shared, Jaccard %.2f, mean rank shift %.1f\n",
+			o.A, o.B, o.Shared, o.Jaccard, o.MeanRankShift)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// truncate shortens s to at most n runes, marking the cut with "…"
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func runThemesValidate(_ *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	themes, err := findThemes(args)
+	if err != nil {
+		return err
+	}
+
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer cleanup()
+
+	fmt.Println()
+	fmt.Println("Theme Validation")
+	fmt.Println("================")
+
+	var warned int
+	for _, theme := range themes {
+		warnings, err := services.generator.CheckTheme(ctx, theme)
+		if err != nil {
+			return fmt.Errorf("validation failed for theme %s: %w", theme.Name, err)
+		}
+
+		if len(warnings) == 0 {
+			fmt.Printf("\n[OK]   %s\n", theme.Name)
+			continue
+		}
+		warned++
+		fmt.Printf("\n[WARN] %s\n", theme.Name)
+		for _, w := range warnings {
+			fmt.Printf("  - %s: %s\n", w.Check, w.Message)
+		}
+	}
+	fmt.Printf("\n%d of %d themes have warnings\n\n", warned, len(themes))
+
+	if validateStrict && warned > 0 {
+		return fmt.Errorf("%d themes have warnings", warned)
+	}
+	return nil
+}
+
+func runThemesSnapshotSave(_ *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	themes, err := findThemes(args)
+	if err != nil {
+		return err
+	}
+	if snapshotSeed == 0 {
+		return errors.New("--seed must not be 0")
+	}
+	if snapshotLimit < 0 {
+		return errors.New("--limit must not be negative")
+	}
+	if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer cleanup()
+
+	fmt.Println()
+	for _, theme := range themes {
+		golden, err := services.generator.SelectGolden(ctx, theme, snapshotSeed, snapshotWithLLM, snapshotLimit)
+		if err != nil {
+			return fmt.Errorf("snapshot failed for theme %s: %w", theme.Name, err)
+		}
+
+		data, err := json.MarshalIndent(golden, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode snapshot for theme %s: %w", theme.Name, err)
+		}
+		path := snapshotPath(theme.Name)
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write snapshot for theme %s: %w", theme.Name, err)
+		}
+		fmt.Printf("Saved %d titles for %s to %s\n", len(golden.Items), theme.Name, path)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func runThemesSnapshotCheck(_ *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	themes, err := findThemes(args)
+	if err != nil {
+		return err
+	}
+	if snapshotMaxDrift < 0 || snapshotMaxDrift > 1 {
+		return errors.New("--max-drift must be between 0 and 1")
+	}
+
+	// Load every golden lineup up front so a missing file fails before any scoring
+	goldens := make([]*playlist.Golden, len(themes))
+	for i, theme := range themes {
+		data, err := os.ReadFile(snapshotPath(theme.Name))
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot for theme %s (run themes snapshot save first): %w", theme.Name, err)
+		}
+		goldens[i] = &playlist.Golden{}
+		if err := json.Unmarshal(data, goldens[i]); err != nil {
+			return fmt.Errorf("failed to decode snapshot for theme %s: %w", theme.Name, err)
+		}
+	}
+
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer cleanup()
+
+	fmt.Println()
+	fmt.Println("Snapshot Drift")
+	fmt.Println("==============")
+
+	var drifted int
+	for i, theme := range themes {
+		drift, err := services.generator.CheckGolden(ctx, theme, goldens[i])
+		if err != nil {
+			return fmt.Errorf("check failed for theme %s: %w", theme.Name, err)
+		}
+
+		status := "[OK]   "
+		if drift.Drift > snapshotMaxDrift {
+			status = "[DRIFT]"
+			drifted++
+		}
+		fmt.Printf("\n%s %s: drift %.2f, %d shared, mean rank shift %.1f (saved %s)\n", status, theme.Name,
+			drift.Drift, drift.Overlap.Shared, drift.Overlap.MeanRankShift, goldens[i].CreatedAt.Format(time.DateOnly))
+		for _, item := range drift.Added {
+			fmt.Printf("  + %s (%d)\n", item.Title, item.Year)
+		}
+		for _, item := range drift.Removed {
+			fmt.Printf("  - %s (%d)\n", item.Title, item.Year)
+		}
+	}
+	fmt.Printf("\n%d of %d themes drifted more than %.2f\n\n", drifted, len(themes), snapshotMaxDrift)
+
+	if drifted > 0 {
+		return fmt.Errorf("%d themes drifted more than %.2f", drifted, snapshotMaxDrift)
+	}
+	return nil
+}
+
+// snapshotPath returns the golden lineup file of a theme in --dir
+func snapshotPath(theme string) string {
+	return filepath.Join(snapshotDir, strings.ReplaceAll(theme, "/", "_")+".json")
+}
+
+// findThemes returns the configured themes with the given names, or every theme when none are given
+func findThemes(names []string) ([]*config.ThemeConfig, error) {
+	themes := make([]*config.ThemeConfig, 0, len(cfg.Themes))
+	if len(names) == 0 {
+		for i := range cfg.Themes {
+			themes = append(themes, &cfg.Themes[i])
+		}
+	}
+	for _, name := range names {
+		theme := findTheme(name)
+		if theme == nil {
+			return nil, fmt.Errorf("theme %q not found in configuration", name)
+		}
+		themes = append(themes, theme)
+	}
+	return themes, nil
+}
+
+// findTheme returns the configured theme with the given name, or nil
+func findTheme(name string) *config.ThemeConfig {
+	for i := range cfg.Themes {
+		if cfg.Themes[i].Name == name {
+			return &cfg.Themes[i]
+		}
+	}
+	return nil
+}
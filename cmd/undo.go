@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/geekxflood/program-director/internal/services/playlist"
+)
+
+var (
+	undoTheme   string
+	undoChannel string
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Restore a channel's previous lineup",
+	Long: `Restore the Tunarr lineup a channel had before the last playlist was applied.
+
+Every apply stores a snapshot of the channel's existing programming. This
+command restores the most recent snapshot that has not been restored yet, so
+running it repeatedly walks back through earlier lineups.
+
+Examples:
+  # Undo the last generation for a theme
+  program-director undo --theme sci-fi-night
+
+  # Undo by Tunarr channel ID
+  program-director undo --channel 3f1c2a4e-...`,
+	RunE: runUndo,
+}
+
+func init() {
+	undoCmd.Flags().StringVarP(&undoTheme, "theme", "t", "", "theme whose channel should be restored")
+	undoCmd.Flags().StringVar(&undoChannel, "channel", "", "Tunarr channel ID to restore")
+}
+
+func runUndo(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	if undoTheme == "" && undoChannel == "" {
+		return errors.New("specify --theme or --channel")
+	}
+	if undoTheme != "" && undoChannel != "" {
+		return errors.New("cannot use both --theme and --channel")
+	}
+
+	channelID := undoChannel
+	if undoTheme != "" {
+		for _, theme := range cfg.Themes {
+			if theme.Name == undoTheme {
+				channelID = theme.ChannelID
+				break
+			}
+		}
+		if channelID == "" {
+			return fmt.Errorf("theme %q not found in configuration", undoTheme)
+		}
+	}
+
+	services, cleanup, err := initializeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer cleanup()
+
+	snapshot, err := services.generator.Undo(ctx, channelID)
+	if err != nil {
+		if errors.Is(err, playlist.ErrNoSnapshot) {
+			return fmt.Errorf("nothing to undo for channel %s", channelID)
+		}
+		return fmt.Errorf("undo failed: %w", err)
+	}
+
+	fmt.Printf("Restored lineup for channel %s\n", channelID)
+	fmt.Printf("  Snapshot: #%d (taken %s)\n", snapshot.ID, snapshot.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Programs: %d\n", snapshot.ProgramCount)
+
+	return nil
+}
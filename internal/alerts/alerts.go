@@ -0,0 +1,240 @@
+// Package alerts tracks failures that repeat, such as a theme failing every night or Tunarr
+// being down, and notifies when they cross the configured thresholds.
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/notify"
+	"github.com/geekxflood/program-director/internal/services/playlist"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// themeHistory is how many of a theme's latest runs are read to measure its failure streak
+const themeHistory = 100
+
+// Alert kinds
+const (
+	KindTheme    = "theme"
+	KindUpstream = "upstream"
+)
+
+// Alert is a theme or upstream that has failed at least the configured number of times in a row
+type Alert struct {
+	Kind      string    `json:"kind"` // KindTheme or KindUpstream
+	Name      string    `json:"name"`
+	Failures  int       `json:"consecutive_failures"`
+	Since     time.Time `json:"since"` // First failure of the streak
+	LastError string    `json:"last_error"`
+}
+
+// streak is a run of consecutive failures
+type streak struct {
+	failures  int
+	since     time.Time
+	lastError string
+}
+
+// Monitor measures failure streaks and sends an alert when one reaches its threshold, and
+// again when it ends. Theme streaks are read from the generation history, so runs made by
+// other processes such as the CLI count too; upstream streaks are kept in memory.
+type Monitor struct {
+	generations *repository.GenerationRepository
+	notifier    *notify.Notifier
+	config      atomic.Pointer[config.AlertsConfig]
+	logger      *slog.Logger
+
+	mu        sync.Mutex
+	upstreams map[string]streak
+}
+
+// New creates a Monitor
+func New(cfg *config.AlertsConfig, generations *repository.GenerationRepository, notifier *notify.Notifier, logger *slog.Logger) *Monitor {
+	m := &Monitor{
+		generations: generations,
+		notifier:    notifier,
+		logger:      logger,
+		upstreams:   make(map[string]streak),
+	}
+	m.config.Store(cfg)
+	return m
+}
+
+// SetConfig swaps in reloaded alert settings
+func (m *Monitor) SetConfig(cfg *config.AlertsConfig) {
+	m.config.Store(cfg)
+}
+
+// RecordUpstream records the outcome of an upstream health check
+func (m *Monitor) RecordUpstream(ctx context.Context, name string, err error) {
+	if m == nil {
+		return
+	}
+	threshold := m.config.Load().UpstreamFailures
+
+	m.mu.Lock()
+	prev := m.upstreams[name]
+	cur := streak{}
+	if err != nil {
+		cur = prev
+		if cur.failures == 0 {
+			cur.since = time.Now()
+		}
+		cur.failures++
+		cur.lastError = err.Error()
+	}
+	m.upstreams[name] = cur
+	m.mu.Unlock()
+
+	if threshold == 0 {
+		return
+	}
+	switch {
+	case cur.failures == threshold:
+		m.fire(ctx, Alert{Kind: KindUpstream, Name: name, Failures: cur.failures, Since: cur.since, LastError: cur.lastError})
+	case err == nil && prev.failures >= threshold:
+		m.resolve(ctx, KindUpstream, name, prev.failures)
+	}
+}
+
+// RecordGenerations checks the themes of non-dry generation results for streaks that just
+// reached the threshold or just ended. Runs refused because the channel was busy are not
+// recorded, so they are ignored.
+func (m *Monitor) RecordGenerations(ctx context.Context, results []playlist.GenerationResult) {
+	if m == nil || m.generations == nil {
+		return
+	}
+	threshold := m.config.Load().ThemeFailures
+	if threshold == 0 {
+		return
+	}
+
+	for _, r := range results {
+		if r.SkipReason != "" || errors.Is(r.Error, playlist.ErrChannelBusy) {
+			continue
+		}
+		runs, err := m.themeRuns(ctx, r.ThemeName)
+		if err != nil {
+			m.logger.WarnContext(ctx, "failed to read generation history for alerts", "theme", r.ThemeName, "error", err)
+			continue
+		}
+
+		if r.Error != nil {
+			if s := failureStreak(runs); s.failures == threshold {
+				m.fire(ctx, Alert{Kind: KindTheme, Name: r.ThemeName, Failures: s.failures, Since: s.since, LastError: s.lastError})
+			}
+			continue
+		}
+		// The run just stored ended the streak that came before it
+		if len(runs) > 0 && runs[0].Error == "" {
+			if s := failureStreak(runs[1:]); s.failures >= threshold {
+				m.resolve(ctx, KindTheme, r.ThemeName, s.failures)
+			}
+		}
+	}
+}
+
+// Alerts lists the themes and upstreams whose failure streaks are at or past their thresholds
+func (m *Monitor) Alerts(ctx context.Context, themes []config.ThemeConfig) ([]Alert, error) {
+	alerts := []Alert{}
+	if m == nil {
+		return alerts, nil
+	}
+	cfg := m.config.Load()
+
+	if cfg.ThemeFailures > 0 && m.generations != nil {
+		for _, theme := range themes {
+			runs, err := m.themeRuns(ctx, theme.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read generation history: %w", err)
+			}
+			if s := failureStreak(runs); s.failures >= cfg.ThemeFailures {
+				alerts = append(alerts, Alert{Kind: KindTheme, Name: theme.Name, Failures: s.failures, Since: s.since, LastError: s.lastError})
+			}
+		}
+	}
+
+	if cfg.UpstreamFailures > 0 {
+		m.mu.Lock()
+		var upstreams []Alert
+		for name, s := range m.upstreams {
+			if s.failures >= cfg.UpstreamFailures {
+				upstreams = append(upstreams, Alert{Kind: KindUpstream, Name: name, Failures: s.failures, Since: s.since, LastError: s.lastError})
+			}
+		}
+		m.mu.Unlock()
+		sort.Slice(upstreams, func(i, j int) bool { return upstreams[i].Name < upstreams[j].Name })
+		alerts = append(alerts, upstreams...)
+	}
+
+	return alerts, nil
+}
+
+// themeRuns returns a theme's latest non-dry runs, newest first
+func (m *Monitor) themeRuns(ctx context.Context, theme string) ([]models.Generation, error) {
+	dryRun := false
+	return m.generations.List(ctx, repository.ListGenerationOptions{
+		ThemeName: theme,
+		DryRun:    &dryRun,
+		Limit:     themeHistory,
+	})
+}
+
+// failureStreak measures the failures at the head of runs, newest first. Skipped runs
+// neither extend nor end a streak.
+func failureStreak(runs []models.Generation) streak {
+	var s streak
+	for _, run := range runs {
+		switch {
+		case run.Error != "":
+			if s.failures == 0 {
+				s.lastError = run.Error
+			}
+			s.failures++
+			s.since = run.CreatedAt
+		case run.SkipReason != "":
+			continue
+		default:
+			return s
+		}
+	}
+	return s
+}
+
+// fire logs and sends an alert
+func (m *Monitor) fire(ctx context.Context, a Alert) {
+	m.logger.ErrorContext(ctx, "alert",
+		"kind", a.Kind,
+		"name", a.Name,
+		"consecutive_failures", a.Failures,
+		"since", a.Since,
+		"error", a.LastError,
+	)
+	m.notifier.Send(ctx, notify.Message{
+		Event:  notify.EventAlert,
+		Title:  fmt.Sprintf("Alert: %s %s failed %d times in a row", a.Kind, a.Name, a.Failures),
+		Body:   fmt.Sprintf("Failing since %s\nLast error: %s", a.Since.Format(time.RFC1123), a.LastError),
+		Failed: true,
+		Data:   a,
+	})
+}
+
+// resolve logs and sends the end of an alert
+func (m *Monitor) resolve(ctx context.Context, kind, name string, failures int) {
+	m.logger.InfoContext(ctx, "alert resolved", "kind", kind, "name", name, "consecutive_failures", failures)
+	m.notifier.Send(ctx, notify.Message{
+		Event: notify.EventAlert,
+		Title: fmt.Sprintf("Resolved: %s %s is working again", kind, name),
+		Body:  fmt.Sprintf("Succeeded after %d failures in a row", failures),
+		Data:  Alert{Kind: kind, Name: name},
+	})
+}
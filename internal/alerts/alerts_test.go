@@ -0,0 +1,105 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/notify"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestFailureStreak(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 3, 0, 0, 0, time.UTC) }
+	failed := func(d int, msg string) models.Generation { return models.Generation{Error: msg, CreatedAt: day(d)} }
+	skipped := func(d int) models.Generation {
+		return models.Generation{SkipReason: "no candidates found", CreatedAt: day(d)}
+	}
+	generated := func(d int) models.Generation { return models.Generation{CreatedAt: day(d)} }
+
+	tests := []struct {
+		name      string
+		runs      []models.Generation // Newest first
+		failures  int
+		since     time.Time
+		lastError string
+	}{
+		{"no runs", nil, 0, time.Time{}, ""},
+		{"latest succeeded", []models.Generation{generated(3), failed(2, "a")}, 0, time.Time{}, ""},
+		{"failing since a success", []models.Generation{failed(4, "b"), failed(3, "a"), generated(2), failed(1, "old")}, 2, day(3), "b"},
+		{"skips do not break a streak", []models.Generation{failed(4, "b"), skipped(3), failed(2, "a"), generated(1)}, 2, day(2), "b"},
+		{"never succeeded", []models.Generation{failed(2, "b"), failed(1, "a")}, 2, day(1), "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := failureStreak(tt.runs)
+			if s.failures != tt.failures || !s.since.Equal(tt.since) || s.lastError != tt.lastError {
+				t.Errorf("failureStreak() = %d since %v (%q), want %d since %v (%q)",
+					s.failures, s.since, s.lastError, tt.failures, tt.since, tt.lastError)
+			}
+		})
+	}
+}
+
+func TestRecordUpstream(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		sent []notify.Message
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg notify.Message
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		mu.Lock()
+		sent = append(sent, msg)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	notifier := notify.New(&config.NotifyConfig{Targets: []config.NotifyTarget{{Type: "webhook", URL: hook.URL}}}, logger)
+	m := New(&config.AlertsConfig{UpstreamFailures: 2}, nil, notifier, logger)
+	ctx := context.Background()
+	down := errors.New("connection refused")
+
+	m.RecordUpstream(ctx, "tunarr", down)
+	if alerts, _ := m.Alerts(ctx, nil); len(alerts) != 0 {
+		t.Fatalf("alerts after one failure = %v, want none", alerts)
+	}
+	m.RecordUpstream(ctx, "tunarr", down)
+	m.RecordUpstream(ctx, "tunarr", down) // Past the threshold, already alerted
+	m.RecordUpstream(ctx, "ollama", nil)
+
+	alerts, err := m.Alerts(ctx, nil)
+	if err != nil {
+		t.Fatalf("Alerts() error = %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Name != "tunarr" || alerts[0].Failures != 3 || alerts[0].LastError != down.Error() {
+		t.Fatalf("Alerts() = %+v, want tunarr with 3 failures", alerts)
+	}
+
+	m.RecordUpstream(ctx, "tunarr", nil)
+	if alerts, _ := m.Alerts(ctx, nil); len(alerts) != 0 {
+		t.Errorf("alerts after recovery = %v, want none", alerts)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 2 {
+		t.Fatalf("sent %d notifications, want an alert and a resolution", len(sent))
+	}
+	if sent[0].Event != notify.EventAlert || !sent[0].Failed {
+		t.Errorf("first notification = %+v, want a failed alert", sent[0])
+	}
+	if sent[1].Event != notify.EventAlert || sent[1].Failed {
+		t.Errorf("second notification = %+v, want a resolved alert", sent[1])
+	}
+}
@@ -0,0 +1,84 @@
+// Package animelists provides a client for fetching anime ID mappings such as Fribb/anime-lists.
+package animelists
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/upstream"
+)
+
+// DefaultURL is the Fribb/anime-lists mapping of AniDB and AniList IDs to TVDB, TMDB, and IMDb
+const DefaultURL = "https://raw.githubusercontent.com/Fribb/anime-lists/master/anime-list-mini.json"
+
+// Client fetches an anime ID mapping from a JSON URL
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// New creates a new client for the mapping at mappingURL, or DefaultURL when it is empty
+func New(mappingURL string) *Client {
+	if mappingURL == "" {
+		mappingURL = DefaultURL
+	}
+	return &Client{
+		url: mappingURL,
+		httpClient: &http.Client{
+			Timeout:   60 * time.Second,
+			Transport: upstream.Transport("animelists", nil),
+		},
+	}
+}
+
+// Entry maps one AniDB/AniList title to its other provider IDs; unknown IDs are 0
+type Entry struct {
+	AniDBID   int64 `json:"anidb_id"`
+	AniListID int64 `json:"anilist_id"`
+	TVDBID    int64 `json:"thetvdb_id"`
+}
+
+// GetMapping retrieves every entry of the mapping
+func (c *Client) GetMapping(ctx context.Context) ([]Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var entries []Entry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return entries, nil
+}
+
+// TVDBIDs returns the set of TVDB IDs of entries that are known to AniDB or AniList
+func TVDBIDs(entries []Entry) map[int64]bool {
+	ids := make(map[int64]bool, len(entries))
+	for _, e := range entries {
+		if e.TVDBID > 0 && (e.AniDBID > 0 || e.AniListID > 0) {
+			ids[e.TVDBID] = true
+		}
+	}
+	return ids
+}
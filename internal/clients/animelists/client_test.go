@@ -0,0 +1,55 @@
+package animelists
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetMapping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"anidb_id": 69, "anilist_id": 21, "thetvdb_id": 81797, "type": "TV"},
+			{"anidb_id": 4563, "thetvdb_id": 79824},
+			{"anilist_id": 1535, "themoviedb_id": 13916},
+			{"thetvdb_id": 12345}
+		]`))
+	}))
+	defer srv.Close()
+
+	entries, err := New(srv.URL).GetMapping(context.Background())
+	if err != nil {
+		t.Fatalf("GetMapping() error = %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("GetMapping() returned %d entries, want 4", len(entries))
+	}
+
+	ids := TVDBIDs(entries)
+	tests := []struct {
+		tvdbID int64
+		want   bool
+	}{
+		{81797, true},
+		{79824, true},
+		{12345, false}, // not known to AniDB or AniList
+		{0, false},
+	}
+	for _, tt := range tests {
+		if got := ids[tt.tvdbID]; got != tt.want {
+			t.Errorf("TVDBIDs()[%d] = %v, want %v", tt.tvdbID, got, tt.want)
+		}
+	}
+}
+
+func TestGetMappingError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if _, err := New(srv.URL).GetMapping(context.Background()); err == nil {
+		t.Fatal("GetMapping() error = nil, want error")
+	}
+}
@@ -0,0 +1,187 @@
+// Package httpx holds what the Radarr, Sonarr, Tunarr, and Ollama clients share around HTTP:
+// per-request metrics, debug logs with secrets redacted, and the error of non-2xx answers.
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/metrics"
+)
+
+// maxErrorBody caps how much of an error answer is kept in a StatusError
+const maxErrorBody = 64 << 10
+
+// Errors a *StatusError matches with errors.Is, by status code
+var (
+	ErrUnauthorized = errors.New("unauthorized") // 401 or 403, usually a wrong API key
+	ErrNotFound     = errors.New("not found")    // 404
+	ErrServer       = errors.New("server error") // 5xx
+
+	// ErrUpstreamUnavailable is matched by 5xx answers, requests that got no answer, and
+	// requests refused while the upstream's circuit is open
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+)
+
+// StatusError is returned when an upstream answers with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error: status %d, body: %s", e.StatusCode, e.Body)
+}
+
+// Is matches ErrUnauthorized, ErrNotFound, ErrServer, and ErrUpstreamUnavailable by status code
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrServer, ErrUpstreamUnavailable:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// Temporary reports whether the request may succeed if retried
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// CheckResponse returns a *StatusError holding the start of the body of a non-2xx response,
+// and nil otherwise. The caller still closes the body.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		return fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
+	}
+	return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+}
+
+// unavailableError is a request that got no answer, for reasons other than its context
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string   { return e.err.Error() }
+func (e *unavailableError) Unwrap() []error { return []error{ErrUpstreamUnavailable, e.err} }
+
+// Send sends req with client, returning the error of a non-2xx answer as a *StatusError and
+// one of a request that got no answer, unless its context ended, as matching
+// ErrUpstreamUnavailable. The caller closes the body of the response it returns.
+func Send(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		if req.Context().Err() == nil {
+			err = &unavailableError{err: err}
+		}
+		return nil, err
+	}
+	if err := CheckResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Do sends req like Send and decodes the JSON answer into v, unless v is nil
+func Do(client *http.Client, req *http.Request, v any) error {
+	resp, err := Send(client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// logger receives the debug line of each request; nil until SetLogger
+var logger atomic.Pointer[slog.Logger]
+
+// SetLogger makes every instrumented transport log its requests to l at debug level
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+// instrumented records the latency and status of each request sent through next
+type instrumented struct {
+	name string
+	next http.RoundTripper
+}
+
+// Instrument wraps next, recording the latency and status of every request to the upstream
+// called name and logging it at debug level with API keys and passwords redacted
+func Instrument(name string, next http.RoundTripper) http.RoundTripper {
+	return &instrumented{name: name, next: next}
+}
+
+func (t *instrumented) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.UpstreamRequestDuration.WithLabelValues(t.name, req.Method, status).Observe(duration.Seconds())
+
+	ctx := req.Context()
+	if l := logger.Load(); l != nil && l.Enabled(ctx, slog.LevelDebug) {
+		attrs := []any{"upstream", t.name, "method", req.Method, "url", Redact(req.URL), "status", status, "duration", duration}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		}
+		l.DebugContext(ctx, "upstream request", attrs...)
+	}
+
+	return resp, err
+}
+
+// Redact returns u without its password and with the values of query parameters that look
+// like secrets, such as apikey, api_key, or X-Plex-Token, replaced
+func Redact(u *url.URL) string {
+	clone := *u
+	if clone.RawQuery != "" {
+		query := clone.Query()
+		for name := range query {
+			if secretParam(name) {
+				query.Set(name, "REDACTED")
+			}
+		}
+		clone.RawQuery = query.Encode()
+	}
+	return clone.Redacted()
+}
+
+// secretParam reports whether a query parameter name looks like it carries a secret
+func secretParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"key", "token", "secret", "password"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
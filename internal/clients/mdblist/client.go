@@ -0,0 +1,93 @@
+// Package mdblist provides a client for fetching public mdblist.com lists.
+package mdblist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/upstream"
+)
+
+// Client fetches public mdblist lists through their JSON export
+type Client struct {
+	httpClient *http.Client
+}
+
+// New creates a new mdblist client
+func New() *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: upstream.Transport("mdblist", nil),
+		},
+	}
+}
+
+// Item represents an entry of an mdblist list
+type Item struct {
+	ID          int64  `json:"id"` // TMDB ID
+	Rank        int    `json:"rank"`
+	Title       string `json:"title"`
+	IMDBID      string `json:"imdb_id"`
+	TVDBID      int64  `json:"tvdb_id"`
+	MediaType   string `json:"mediatype"` // movie, show
+	ReleaseYear int    `json:"release_year"`
+}
+
+// GetList retrieves the items of a public list given its web URL
+func (c *Client) GetList(ctx context.Context, listURL string) ([]Item, error) {
+	jsonURL, err := JSONURL(listURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", jsonURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var items []Item
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return items, nil
+}
+
+// JSONURL converts a list URL such as https://mdblist.com/lists/user/slug into its JSON export URL
+func JSONURL(listURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(listURL))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid mdblist URL %q", listURL)
+	}
+
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(u.Path, "/json") {
+		u.Path += "/json"
+	}
+
+	return u.String(), nil
+}
@@ -0,0 +1,67 @@
+package mdblist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lists/someone/heist-movies/json" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[
+			{"id": 949, "rank": 1, "title": "Heat", "imdb_id": "tt0113277", "tvdb_id": null, "mediatype": "movie", "release_year": 1995},
+			{"id": 1396, "rank": 2, "title": "Breaking Bad", "imdb_id": "tt0903747", "tvdb_id": 81189, "mediatype": "show", "release_year": 2008}
+		]`))
+	}))
+	defer server.Close()
+
+	client := New()
+	items, err := client.GetList(context.Background(), server.URL+"/lists/someone/heist-movies/")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	if items[0].ID != 949 || items[0].IMDBID != "tt0113277" || items[0].TVDBID != 0 {
+		t.Errorf("unexpected first item %+v", items[0])
+	}
+
+	if items[1].MediaType != "show" || items[1].TVDBID != 81189 {
+		t.Errorf("unexpected second item %+v", items[1])
+	}
+}
+
+func TestJSONURL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "https://mdblist.com/lists/someone/heist-movies", want: "https://mdblist.com/lists/someone/heist-movies/json"},
+		{in: "https://mdblist.com/lists/someone/heist-movies/?sort=score", want: "https://mdblist.com/lists/someone/heist-movies/json"},
+		{in: "https://mdblist.com/lists/someone/heist-movies/json", want: "https://mdblist.com/lists/someone/heist-movies/json"},
+		{in: "someone/heist-movies", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := JSONURL(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("JSONURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("JSONURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
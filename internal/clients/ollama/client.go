@@ -5,13 +5,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/geekxflood/program-director/internal/clients/httpx"
+	"github.com/geekxflood/program-director/internal/clients/upstream"
 	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/logging"
+	"github.com/geekxflood/program-director/internal/metrics"
 )
 
 // Client is an Ollama API client
@@ -21,28 +29,72 @@ type Client struct {
 	temperature float64
 	numCtx      int
 	httpClient  *http.Client
+	slots       chan struct{} // Limits concurrent requests; nil for no limit
+	retries     int
+	retryDelay  time.Duration
+	keepAlive   any // Seconds as a number, a duration string, or nil for the server default
 }
 
 // New creates a new Ollama client
 func New(cfg *config.OllamaConfig) *Client {
+	var slots chan struct{}
+	if cfg.MaxConcurrent > 0 {
+		slots = make(chan struct{}, cfg.MaxConcurrent)
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Minute // LLM requests can take a while
+	}
+
 	return &Client{
 		baseURL:     cfg.URL,
 		model:       cfg.Model,
 		temperature: cfg.Temperature,
 		numCtx:      cfg.NumCtx,
-		httpClient: &http.Client{
-			Timeout: 5 * time.Minute, // LLM requests can take a while
-		},
+		httpClient:  upstream.Client("ollama", timeout, cfg.Proxy, cfg.TLS),
+		slots:       slots,
+		retries:     cfg.Retries,
+		retryDelay:  time.Duration(cfg.RetryDelay) * time.Second,
+		keepAlive:   keepAlive(cfg.KeepAlive),
+	}
+}
+
+// keepAlive converts the keep_alive setting to what Ollama accepts: a number of seconds,
+// where negative keeps the model loaded, or a duration string such as "30m"
+func keepAlive(setting string) any {
+	if setting == "" {
+		return nil
+	}
+	if seconds, err := strconv.Atoi(setting); err == nil {
+		return seconds
 	}
+	return setting
 }
 
+// Model returns the model the client chats with
+func (c *Client) Model() string {
+	return c.model
+}
+
+// WithModel returns a client for another model on the same server, sharing its request slots
+func (c *Client) WithModel(model string) *Client {
+	clone := *c
+	clone.model = model
+	return &clone
+}
+
+// APIError is returned when Ollama answers with a non-2xx status
+type APIError = httpx.StatusError
+
 // ChatRequest represents a chat completion request
 type ChatRequest struct {
-	Model    string        `json:"model"`
-	Messages []ChatMessage `json:"messages"`
-	Stream   bool          `json:"stream"`
-	Options  Options       `json:"options,omitempty"`
-	Format   string        `json:"format,omitempty"` // "json" for JSON output
+	Model     string        `json:"model"`
+	Messages  []ChatMessage `json:"messages"`
+	Stream    bool          `json:"stream"`
+	Options   Options       `json:"options,omitempty"`
+	Format    string        `json:"format,omitempty"` // "json" for JSON output
+	KeepAlive any           `json:"keep_alive,omitempty"`
 }
 
 // ChatMessage represents a message in the conversation
@@ -71,16 +123,19 @@ type ChatResponse struct {
 	PromptEvalCount int         `json:"prompt_eval_count"`
 	EvalCount       int         `json:"eval_count"`
 	EvalDuration    int64       `json:"eval_duration"`
+
+	PromptEvalDuration int64 `json:"prompt_eval_duration"`
 }
 
 // GenerateRequest represents a text generation request
 type GenerateRequest struct {
-	Model   string  `json:"model"`
-	Prompt  string  `json:"prompt"`
-	System  string  `json:"system,omitempty"`
-	Stream  bool    `json:"stream"`
-	Options Options `json:"options,omitempty"`
-	Format  string  `json:"format,omitempty"`
+	Model     string  `json:"model"`
+	Prompt    string  `json:"prompt"`
+	System    string  `json:"system,omitempty"`
+	Stream    bool    `json:"stream"`
+	Options   Options `json:"options,omitempty"`
+	Format    string  `json:"format,omitempty"`
+	KeepAlive any     `json:"keep_alive,omitempty"`
 }
 
 // GenerateResponse represents the response from text generation
@@ -102,69 +157,306 @@ func (c *Client) ChatWithJSON(ctx context.Context, messages []ChatMessage) (*Cha
 			Temperature: c.temperature,
 			NumCtx:      c.numCtx,
 		},
+		KeepAlive: c.keepAlive,
 	}
 
 	return c.doChat(ctx, &req)
 }
 
-// doChat executes a chat completion request
+// doChat executes a chat completion request, retrying with exponential backoff while it
+// times out or fails transiently
 func (c *Client) doChat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	delay := c.retryDelay
+	for attempt := 1; ; attempt++ {
+		resp, err := c.chatOnce(ctx, req.Model, body)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt > c.retries || !retryable(ctx, err) {
+			if attempt > 1 {
+				return nil, fmt.Errorf("%w (after %d attempts)", err, attempt)
+			}
+			return nil, err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// retryable reports whether a failed chat may succeed if sent again: network errors and
+// timeouts, truncated responses, and 429 or 5xx responses, unless ctx itself is done
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Temporary()
+}
+
+// chatOnce sends one chat completion request
+func (c *Client) chatOnce(ctx context.Context, model string, body []byte) (*ChatResponse, error) {
 	httpReq, err := c.newRequest(ctx, "POST", "/api/chat", bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 
+	if err := c.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer c.release()
+
+	start := time.Now()
 	var resp ChatResponse
-	if err := c.do(httpReq, &resp); err != nil {
+	err = c.do(httpReq, &resp)
+	metrics.LLMRequestDuration.WithLabelValues(model, metrics.Status(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
 		return nil, fmt.Errorf("failed to chat: %w", err)
 	}
+	metrics.LLMTokens.WithLabelValues(model, "prompt").Add(float64(resp.PromptEvalCount))
+	metrics.LLMTokens.WithLabelValues(model, "eval").Add(float64(resp.EvalCount))
 
 	return &resp, nil
 }
 
-// newRequest creates a new HTTP request
-func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
-	u, err := url.Parse(c.baseURL + path)
+// acquire waits for a free request slot
+func (c *Client) acquire(ctx context.Context) error {
+	if c.slots == nil {
+		return nil
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a request slot taken by acquire
+func (c *Client) release() {
+	if c.slots != nil {
+		<-c.slots
+	}
+}
+
+// WarmUp loads the model into memory for keep_alive, without generating anything
+func (c *Client) WarmUp(ctx context.Context) error {
+	body, err := json.Marshal(GenerateRequest{Model: c.model, KeepAlive: c.keepAlive})
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	req, err := c.newRequest(ctx, "POST", "/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if err := c.acquire(ctx); err != nil {
+		return err
+	}
+	defer c.release()
+
+	if err := c.do(req, nil); err != nil {
+		return fmt.Errorf("failed to load model %s: %w", c.model, err)
+	}
+
+	return nil
+}
+
+// HealthCheck verifies that Ollama is reachable
+func (c *Client) HealthCheck(ctx context.Context) error {
+	req, err := c.newRequest(ctx, "GET", "/api/version", nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.do(req, nil); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
+}
+
+// Model is a model available on the Ollama server
+type Model struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// ListModels retrieves the models pulled on the Ollama server
+func (c *Client) ListModels(ctx context.Context) ([]Model, error) {
+	req, err := c.newRequest(ctx, "GET", "/api/tags", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	var resp struct {
+		Models []Model `json:"models"`
+	}
+	if err := c.do(req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
 
-	return req, nil
+	return resp.Models, nil
 }
 
-// do executes an HTTP request and decodes the JSON response
-func (c *Client) do(req *http.Request, v interface{}) error {
-	resp, err := c.httpClient.Do(req)
+// PullProgress is a status update streamed while a model is pulled
+type PullProgress struct {
+	Status    string `json:"status"` // e.g. "pulling manifest", "pulling <digest>", "success"
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// PullModel downloads a model, calling progress for each status update. Pulls can take far
+// longer than other requests, so only ctx bounds them.
+func (c *Client) PullModel(ctx context.Context, name string, progress func(PullProgress)) error {
+	body, err := json.Marshal(map[string]any{"model": name, "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, "POST", "/api/pull", bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
+
+	resp, err := httpx.Send(&http.Client{Transport: c.httpClient.Transport}, req)
+	if err != nil {
+		return fmt.Errorf("failed to pull %s: %w", name, err)
+	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
+	// The response is a stream of JSON objects, ending with "success" or an error
+	dec := json.NewDecoder(resp.Body)
+	var last PullProgress
+	for {
+		var p PullProgress
+		if err := dec.Decode(&p); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read pull progress: %w", err)
+		}
+		if p.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", name, p.Error)
+		}
+		if progress != nil {
+			progress(p)
 		}
-		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+		last = p
+	}
+	if last.Status != "success" {
+		return fmt.Errorf("pull of %s ended without success (last status: %q)", name, last.Status)
+	}
+
+	return nil
+}
+
+// ModelDetails describes a model's format and size
+type ModelDetails struct {
+	Format            string `json:"format"`
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+// ModelInfo is what Ollama reports about a pulled model
+type ModelInfo struct {
+	License    string       `json:"license"`
+	Modelfile  string       `json:"modelfile"`
+	Parameters string       `json:"parameters"`
+	Template   string       `json:"template"`
+	Details    ModelDetails `json:"details"`
+	ModifiedAt time.Time    `json:"modified_at"`
+}
+
+// ShowModel retrieves the details of a pulled model
+func (c *Client) ShowModel(ctx context.Context, name string) (*ModelInfo, error) {
+	body, err := json.Marshal(map[string]string{"model": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, "POST", "/api/show", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
 	}
 
-	if v != nil {
-		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+	var info ModelInfo
+	if err := c.do(req, &info); err != nil {
+		return nil, fmt.Errorf("failed to show model %s: %w", name, err)
+	}
+
+	return &info, nil
+}
+
+// EnsureModel pulls the configured model when the server doesn't have it yet, and reports
+// whether it was pulled
+func (c *Client) EnsureModel(ctx context.Context, progress func(PullProgress)) (bool, error) {
+	models, err := c.ListModels(ctx)
+	if err != nil {
+		return false, err
+	}
+	if HasModel(models, c.model) {
+		return false, nil
+	}
+	if err := c.PullModel(ctx, c.model, progress); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// HasModel reports whether name is among models, treating a missing tag as ":latest"
+func HasModel(models []Model, name string) bool {
+	if !strings.Contains(name, ":") {
+		name += ":latest"
+	}
+	for _, m := range models {
+		if m.Name == name {
+			return true
 		}
 	}
+	return false
+}
 
-	return nil
+// newRequest creates a new HTTP request
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
+
+	return req, nil
+}
+
+// do executes an HTTP request and decodes the JSON response
+func (c *Client) do(req *http.Request, v interface{}) error {
+	return httpx.Do(c.httpClient, req, v)
 }
@@ -0,0 +1,173 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestChatWithJSONRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int // status of each attempt; the last one repeats
+		retries   int
+		wantErr   bool
+		wantCalls int
+	}{
+		{"succeeds first time", []int{http.StatusOK}, 2, false, 1},
+		{"retries transient failure", []int{http.StatusServiceUnavailable, http.StatusOK}, 2, false, 2},
+		{"gives up after retries", []int{http.StatusInternalServerError}, 2, true, 3},
+		{"no retry on client error", []int{http.StatusBadRequest}, 2, true, 1},
+		{"retries disabled", []int{http.StatusTooManyRequests}, 0, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[min(calls, len(tt.statuses)-1)]
+				calls++
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					w.Write([]byte(`{"model": "m", "message": {"role": "assistant", "content": "{}"}, "done": true}`))
+				}
+			}))
+			defer server.Close()
+
+			client := New(&config.OllamaConfig{URL: server.URL, Model: "m", Retries: tt.retries})
+
+			_, err := client.ChatWithJSON(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChatWithJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWarmUp(t *testing.T) {
+	tests := []struct {
+		keepAlive string
+		want      any // keep_alive as decoded from the request body
+	}{
+		{"", nil},
+		{"30m", "30m"},
+		{"-1", float64(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keepAlive, func(t *testing.T) {
+			var got map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/generate" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				w.Write([]byte(`{"model": "m", "response": "", "done": true}`))
+			}))
+			defer server.Close()
+
+			client := New(&config.OllamaConfig{URL: server.URL, Model: "m", KeepAlive: tt.keepAlive})
+			if err := client.WarmUp(context.Background()); err != nil {
+				t.Fatalf("WarmUp() error = %v", err)
+			}
+
+			if got["model"] != "m" {
+				t.Errorf("model = %v, want m", got["model"])
+			}
+			if got["keep_alive"] != tt.want {
+				t.Errorf("keep_alive = %v, want %v", got["keep_alive"], tt.want)
+			}
+		})
+	}
+}
+
+func TestPullModel(t *testing.T) {
+	tests := []struct {
+		name       string
+		stream     string
+		wantErr    string
+		wantStatus []string
+	}{
+		{
+			name: "success",
+			stream: `{"status": "pulling manifest"}
+{"status": "pulling abc", "digest": "abc", "total": 100, "completed": 50}
+{"status": "pulling abc", "digest": "abc", "total": 100, "completed": 100}
+{"status": "success"}
+`,
+			wantStatus: []string{"pulling manifest", "pulling abc", "pulling abc", "success"},
+		},
+		{
+			name:       "error in stream",
+			stream:     `{"status": "pulling manifest"}` + "\n" + `{"error": "pull model manifest: file does not exist"}` + "\n",
+			wantErr:    "file does not exist",
+			wantStatus: []string{"pulling manifest"},
+		},
+		{
+			name:       "stream cut short",
+			stream:     `{"status": "pulling manifest"}` + "\n",
+			wantErr:    "ended without success",
+			wantStatus: []string{"pulling manifest"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/pull" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.Write([]byte(tt.stream))
+			}))
+			defer server.Close()
+
+			var statuses []string
+			client := New(&config.OllamaConfig{URL: server.URL, Model: "m"})
+			err := client.PullModel(context.Background(), "m", func(p PullProgress) {
+				statuses = append(statuses, p.Status)
+			})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("PullModel() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("PullModel() error = %v, want %q", err, tt.wantErr)
+			}
+			if strings.Join(statuses, ",") != strings.Join(tt.wantStatus, ",") {
+				t.Errorf("statuses = %v, want %v", statuses, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestShowModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["model"] != "llama3.1:8b" {
+			t.Errorf("unexpected request %v (%v)", req, err)
+		}
+		w.Write([]byte(`{
+			"parameters": "num_ctx 8192",
+			"details": {"format": "gguf", "family": "llama", "parameter_size": "8.0B", "quantization_level": "Q4_0"}
+		}`))
+	}))
+	defer server.Close()
+
+	client := New(&config.OllamaConfig{URL: server.URL, Model: "m"})
+	info, err := client.ShowModel(context.Background(), "llama3.1:8b")
+	if err != nil {
+		t.Fatalf("ShowModel() error = %v", err)
+	}
+	if info.Details.Family != "llama" || info.Details.ParameterSize != "8.0B" {
+		t.Errorf("details = %+v", info.Details)
+	}
+}
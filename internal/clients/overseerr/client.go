@@ -0,0 +1,155 @@
+// Package overseerr provides a client for interacting with the Overseerr/Jellyseerr API.
+package overseerr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/upstream"
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// Client is an Overseerr API client. Jellyseerr exposes the same API and works unchanged.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// New creates a new Overseerr client
+func New(cfg *config.OverseerrConfig) *Client {
+	return &Client{
+		baseURL: cfg.URL,
+		apiKey:  cfg.APIKey,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: upstream.Transport("overseerr", nil),
+		},
+	}
+}
+
+// Request status values returned by Overseerr
+const (
+	RequestStatusPending  = 1
+	RequestStatusApproved = 2
+	RequestStatusDeclined = 3
+)
+
+// Media status values returned by Overseerr
+const (
+	MediaStatusUnknown            = 1
+	MediaStatusPending            = 2
+	MediaStatusProcessing         = 3
+	MediaStatusPartiallyAvailable = 4
+	MediaStatusAvailable          = 5
+)
+
+// Request represents a media request
+type Request struct {
+	ID        int64        `json:"id"`
+	Status    int          `json:"status"`
+	Type      string       `json:"type"` // movie, tv
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	Media     RequestMedia `json:"media"`
+}
+
+// RequestMedia holds the media a request refers to
+type RequestMedia struct {
+	ID        int64  `json:"id"`
+	MediaType string `json:"mediaType"`
+	TMDBID    int64  `json:"tmdbId"`
+	TVDBID    int64  `json:"tvdbId"`
+	Status    int    `json:"status"`
+}
+
+// IsAvailable reports whether the requested media has been downloaded
+func (r *Request) IsAvailable() bool {
+	return r.Media.Status == MediaStatusAvailable || r.Media.Status == MediaStatusPartiallyAvailable
+}
+
+// requestPage is a paged list of requests
+type requestPage struct {
+	PageInfo struct {
+		Pages   int `json:"pages"`
+		Page    int `json:"page"`
+		Results int `json:"results"`
+	} `json:"pageInfo"`
+	Results []Request `json:"results"`
+}
+
+// GetAvailableRequests retrieves up to limit (default 100) of the most recent requests whose
+// media is available or partially available
+func (c *Client) GetAvailableRequests(ctx context.Context, limit int) ([]Request, error) {
+	if limit == 0 {
+		limit = 100
+	}
+
+	path := fmt.Sprintf("/api/v1/request?take=%d&skip=0&filter=available&sort=added", limit)
+	req, err := c.newRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var page requestPage
+	if err := c.do(req, &page); err != nil {
+		return nil, fmt.Errorf("failed to get requests: %w", err)
+	}
+
+	// Keep only available media, whatever the server's filter matched, and at most limit requests
+	requests := make([]Request, 0, len(page.Results))
+	for _, r := range page.Results {
+		if r.IsAvailable() && len(requests) < limit {
+			requests = append(requests, r)
+		}
+	}
+	return requests, nil
+}
+
+// newRequest creates a new HTTP request with API key header
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// do executes an HTTP request and decodes the JSON response
+func (c *Client) do(req *http.Request, v interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package overseerr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestGetAvailableRequests(t *testing.T) {
+	var take string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v1/request" || q.Get("filter") != "available" || q.Get("sort") != "added" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		if r.Header.Get("X-Api-Key") != "test-key" {
+			t.Errorf("expected X-Api-Key header")
+		}
+		take = q.Get("take")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"pageInfo": {"pages": 1, "page": 1, "results": 4}, "results": [
+			{"id": 1, "status": 2, "type": "movie", "createdAt": "2025-05-30T10:00:00.000Z", "media": {"tmdbId": 949, "status": 5}},
+			{"id": 2, "status": 2, "type": "tv", "createdAt": "2025-05-29T10:00:00.000Z", "media": {"tvdbId": 81189, "status": 4}},
+			{"id": 3, "status": 2, "type": "movie", "createdAt": "2025-05-28T10:00:00.000Z", "media": {"tmdbId": 680, "status": 3}},
+			{"id": 4, "status": 1, "type": "movie", "createdAt": "2025-05-27T10:00:00.000Z", "media": {"tmdbId": 603, "status": 5}}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := New(&config.OverseerrConfig{URL: server.URL, APIKey: "test-key"})
+
+	requests, err := client.GetAvailableRequests(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if take != "100" {
+		t.Errorf("expected the default take=100, got %s", take)
+	}
+
+	// The processing request is left out; partially available media counts as available
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 available requests, got %d", len(requests))
+	}
+	if requests[0].Media.TMDBID != 949 || requests[1].Media.TVDBID != 81189 || requests[2].ID != 4 {
+		t.Errorf("unexpected requests %+v", requests)
+	}
+
+	// The limit is passed on and enforced
+	requests, err = client.GetAvailableRequests(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if take != "2" {
+		t.Errorf("expected take=2, got %s", take)
+	}
+	if len(requests) != 2 || requests[1].ID != 2 {
+		t.Errorf("expected the 2 most recent available requests, got %+v", requests)
+	}
+}
+
+func TestGetAvailableRequestsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Unauthorized"}`))
+	}))
+	defer server.Close()
+
+	client := New(&config.OverseerrConfig{URL: server.URL, APIKey: "bad"})
+
+	if _, err := client.GetAvailableRequests(context.Background(), 10); err == nil {
+		t.Error("expected error for unauthorized request")
+	}
+}
@@ -10,12 +10,18 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/geekxflood/program-director/internal/clients/httpx"
+	"github.com/geekxflood/program-director/internal/clients/upstream"
 	"github.com/geekxflood/program-director/internal/config"
 	"github.com/geekxflood/program-director/pkg/models"
 )
 
+// ErrNotFound is matched by the errors of requests for resources Radarr doesn't have
+var ErrNotFound = httpx.ErrNotFound
+
 // Client is a Radarr API client
 type Client struct {
+	name       string
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
@@ -23,33 +29,85 @@ type Client struct {
 
 // New creates a new Radarr client
 func New(cfg *config.RadarrConfig) *Client {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
 	return &Client{
-		baseURL: cfg.URL,
-		apiKey:  cfg.APIKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		name:       cfg.Name,
+		baseURL:    cfg.URL,
+		apiKey:     cfg.APIKey,
+		httpClient: upstream.Client("radarr:"+cfg.Name, timeout, cfg.Proxy, cfg.TLS),
 	}
 }
 
 // Movie represents a movie from Radarr API
 type Movie struct {
-	ID         int64      `json:"id"`
-	Title      string     `json:"title"`
-	Year       int        `json:"year"`
-	Overview   string     `json:"overview"`
-	Runtime    int        `json:"runtime"`
-	Genres     []string   `json:"genres"`
-	Status     string     `json:"status"`
-	Monitored  bool       `json:"monitored"`
-	Path       string     `json:"path"`
-	HasFile    bool       `json:"hasFile"`
-	SizeOnDisk int64      `json:"sizeOnDisk"`
-	IMDBID     string     `json:"imdbId"`
-	TMDBID     int64      `json:"tmdbId"`
-	Ratings    Ratings    `json:"ratings"`
-	MovieFile  *MovieFile `json:"movieFile,omitempty"`
-	Popularity float64    `json:"popularity"`
+	ID               int64       `json:"id"`
+	Title            string      `json:"title"`
+	Year             int         `json:"year"`
+	Overview         string      `json:"overview"`
+	Runtime          int         `json:"runtime"`
+	Genres           []string    `json:"genres"`
+	Status           string      `json:"status"`
+	Monitored        bool        `json:"monitored"`
+	Path             string      `json:"path"`
+	HasFile          bool        `json:"hasFile"`
+	SizeOnDisk       int64       `json:"sizeOnDisk"`
+	IMDBID           string      `json:"imdbId"`
+	TMDBID           int64       `json:"tmdbId"`
+	Ratings          Ratings     `json:"ratings"`
+	MovieFile        *MovieFile  `json:"movieFile,omitempty"`
+	Popularity       float64     `json:"popularity"`
+	Tags             []int64     `json:"tags"`
+	Collection       *Collection `json:"collection,omitempty"`
+	Certification    string      `json:"certification"`
+	OriginalLanguage Language    `json:"originalLanguage"`
+	Added            time.Time   `json:"added"`
+	Images           []Image     `json:"images"`
+}
+
+// Collection is the TMDB collection (franchise) a movie belongs to
+type Collection struct {
+	Title  string `json:"title"`
+	Name   string `json:"name"` // used instead of title by Radarr v3
+	TMDBID int64  `json:"tmdbId"`
+}
+
+// DisplayTitle returns the collection title across Radarr versions
+func (c *Collection) DisplayTitle() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return c.Name
+}
+
+// Image is a piece of artwork, such as a poster or fanart
+type Image struct {
+	CoverType string `json:"coverType"` // poster, fanart, banner, ...
+	URL       string `json:"url"`       // path on the Radarr server
+	RemoteURL string `json:"remoteUrl"` // upstream URL on TMDB
+}
+
+// ImageURL returns the URL of the first image of coverType, preferring its remote URL, or ""
+func ImageURL(images []Image, coverType string) string {
+	for _, img := range images {
+		if img.CoverType != coverType {
+			continue
+		}
+		if img.RemoteURL != "" {
+			return img.RemoteURL
+		}
+		return img.URL
+	}
+	return ""
+}
+
+// Language is a language as reported by Radarr
+type Language struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
 }
 
 // Ratings holds rating information
@@ -67,10 +125,11 @@ type Rating struct {
 
 // MovieFile holds movie file information
 type MovieFile struct {
-	ID      int64   `json:"id"`
-	Path    string  `json:"path"`
-	Size    int64   `json:"size"`
-	Quality Quality `json:"quality"`
+	ID        int64     `json:"id"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Quality   Quality   `json:"quality"`
+	DateAdded time.Time `json:"dateAdded"`
 }
 
 // Quality holds quality information
@@ -85,24 +144,126 @@ type QualityInfo struct {
 	Resolution int    `json:"resolution"`
 }
 
+// Name returns the configured instance name
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Tag is a Radarr tag definition
+type Tag struct {
+	ID    int64  `json:"id"`
+	Label string `json:"label"`
+}
+
+// GetTags retrieves all tag definitions
+func (c *Client) GetTags(ctx context.Context) ([]Tag, error) {
+	req, err := c.newRequest(ctx, "GET", "/api/v3/tag", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []Tag
+	if err := c.do(req, &tags); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// MoviePageSize is how many movies EachMoviePage hands over at once
+const MoviePageSize = 500
+
 // GetMovies retrieves all movies from Radarr
 func (c *Client) GetMovies(ctx context.Context) ([]Movie, error) {
-	req, err := c.newRequest(ctx, "GET", "/api/v3/movie", nil)
+	var movies []Movie
+	err := c.EachMoviePage(ctx, MoviePageSize, func(page []Movie) error {
+		movies = append(movies, page...)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return movies, nil
+}
 
-	var movies []Movie
-	if err := c.do(req, &movies); err != nil {
-		return nil, fmt.Errorf("failed to get movies: %w", err)
+// EachMoviePage retrieves all movies from Radarr, calling fn with pages of up to size movies.
+// Radarr can't page its movie list, so the response is decoded one movie at a time, keeping
+// only the fields of Movie and leaving local cover URLs out; memory holds a page rather than
+// the whole library. An error from fn stops the fetch and is returned as is.
+func (c *Client) EachMoviePage(ctx context.Context, size int, fn func([]Movie) error) error {
+	if size <= 0 {
+		size = MoviePageSize
 	}
 
-	return movies, nil
+	req, err := c.newRequest(ctx, "GET", "/api/v3/movie?excludeLocalCovers=true", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return fmt.Errorf("failed to get movies: %w", err)
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return fmt.Errorf("failed to get movies: failed to decode response: expected a JSON array")
+	}
+
+	page := make([]Movie, 0, size)
+	for dec.More() {
+		var m Movie
+		if err := dec.Decode(&m); err != nil {
+			return fmt.Errorf("failed to get movies: failed to decode response: %w", err)
+		}
+		page = append(page, m)
+		if len(page) < size {
+			continue
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		page = make([]Movie, 0, size)
+	}
+	if len(page) > 0 {
+		return fn(page)
+	}
+	return nil
+}
+
+// GetMovie retrieves a single movie by its Radarr ID
+func (c *Client) GetMovie(ctx context.Context, id int64) (*Movie, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("/api/v3/movie/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Movie
+	if err := c.do(req, &m); err != nil {
+		return nil, fmt.Errorf("failed to get movie %d: %w", id, err)
+	}
+
+	return &m, nil
+}
+
+// HealthCheck verifies that Radarr is reachable and the API key is accepted
+func (c *Client) HealthCheck(ctx context.Context) error {
+	req, err := c.newRequest(ctx, "GET", "/api/v3/system/status", nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.do(req, nil); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
 }
 
 // ToMedia converts a Radarr movie to a Media model
 func (m *Movie) ToMedia() *models.Media {
-	return &models.Media{
+	media := &models.Media{
 		ExternalID: m.ID,
 		Source:     models.MediaSourceRadarr,
 		MediaType:  models.MediaTypeMovie,
@@ -121,7 +282,26 @@ func (m *Movie) ToMedia() *models.Media {
 		SizeOnDisk: m.SizeOnDisk,
 		Status:     m.Status,
 		Monitored:  m.Monitored,
+
+		Certification:    m.Certification,
+		OriginalLanguage: models.LanguageCode(m.OriginalLanguage.Name),
+
+		PosterURL: ImageURL(m.Images, "poster"),
+		FanartURL: ImageURL(m.Images, "fanart"),
 	}
+	if m.Collection != nil {
+		media.CollectionTMDBID = m.Collection.TMDBID
+	}
+	if m.MovieFile != nil {
+		media.Resolution = m.MovieFile.Quality.Quality.Resolution
+		media.Quality = m.MovieFile.Quality.Quality.Name
+	}
+	if m.MovieFile != nil && !m.MovieFile.DateAdded.IsZero() {
+		media.AddedAt = &m.MovieFile.DateAdded
+	} else if !m.Added.IsZero() {
+		media.AddedAt = &m.Added
+	}
+	return media
 }
 
 // newRequest creates a new HTTP request with API key header
@@ -144,25 +324,11 @@ func (c *Client) newRequest(ctx context.Context, method, path string, body io.Re
 
 // do executes an HTTP request and decodes the JSON response
 func (c *Client) do(req *http.Request, v interface{}) error {
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
-		}
-		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	if v != nil {
-		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
+	return httpx.Do(c.httpClient, req, v)
+}
 
-	return nil
+// send executes an HTTP request, turning error statuses into errors. The caller closes the
+// body of the response it returns.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	return httpx.Send(c.httpClient, req)
 }
@@ -0,0 +1,97 @@
+package radarr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestEachMoviePage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/movie" || r.URL.Query().Get("excludeLocalCovers") != "true" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		if r.Header.Get("X-Api-Key") != "test-key" {
+			t.Errorf("expected X-Api-Key header")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": 1, "title": "Alien", "year": 1979, "tags": [2], "alternateTitles": [{"title": "Alien - Le huitième passager"}]},
+			{"id": 2, "title": "Aliens", "year": 1986, "collection": {"title": "Alien Collection", "tmdbId": 8091}},
+			{"id": 3, "title": "Heat", "year": 1995, "images": [{"coverType": "poster", "remoteUrl": "https://image.tmdb.org/heat.jpg"}]}
+		]`))
+	}))
+	defer server.Close()
+
+	client := New(&config.RadarrConfig{URL: server.URL, APIKey: "test-key"})
+
+	var sizes []int
+	var titles []string
+	err := client.EachMoviePage(context.Background(), 2, func(page []Movie) error {
+		sizes = append(sizes, len(page))
+		for _, m := range page {
+			titles = append(titles, m.Title)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(sizes) != 2 || sizes[0] != 2 || sizes[1] != 1 {
+		t.Errorf("expected pages of 2 and 1 movies, got %v", sizes)
+	}
+	if len(titles) != 3 || titles[0] != "Alien" || titles[2] != "Heat" {
+		t.Errorf("unexpected titles %v", titles)
+	}
+
+	movies, err := client.GetMovies(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(movies) != 3 || movies[1].Collection.TMDBID != 8091 || movies[2].ToMedia().PosterURL != "https://image.tmdb.org/heat.jpg" {
+		t.Errorf("unexpected movies %+v", movies)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = client.EachMoviePage(context.Background(), 1, func([]Movie) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("expected the callback error after one page, got %v after %d calls", err, calls)
+	}
+}
+
+func TestEachMoviePageErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "api error", status: http.StatusUnauthorized, body: `{"message": "Unauthorized"}`},
+		{name: "not an array", status: http.StatusOK, body: `{"id": 1}`},
+		{name: "truncated", status: http.StatusOK, body: `[{"id": 1, "title": "Alien"}, {"id": 2, "ti`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := New(&config.RadarrConfig{URL: server.URL, APIKey: "test-key"})
+			if _, err := client.GetMovies(context.Background()); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package sonarr
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,12 +10,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/geekxflood/program-director/internal/clients/httpx"
+	"github.com/geekxflood/program-director/internal/clients/upstream"
 	"github.com/geekxflood/program-director/internal/config"
 	"github.com/geekxflood/program-director/pkg/models"
 )
 
+// ErrNotFound is matched by the errors of requests for resources Sonarr doesn't have
+var ErrNotFound = httpx.ErrNotFound
+
 // Client is a Sonarr API client
 type Client struct {
+	name       string
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
@@ -24,31 +29,67 @@ type Client struct {
 
 // New creates a new Sonarr client
 func New(cfg *config.SonarrConfig) *Client {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
 	return &Client{
-		baseURL: cfg.URL,
-		apiKey:  cfg.APIKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		name:       cfg.Name,
+		baseURL:    cfg.URL,
+		apiKey:     cfg.APIKey,
+		httpClient: upstream.Client("sonarr:"+cfg.Name, timeout, cfg.Proxy, cfg.TLS),
 	}
 }
 
 // Series represents a series from Sonarr API
 type Series struct {
-	ID         int64    `json:"id"`
-	Title      string   `json:"title"`
-	Year       int      `json:"year"`
-	Overview   string   `json:"overview"`
-	Runtime    int      `json:"runtime"`
-	Genres     []string `json:"genres"`
-	Status     string   `json:"status"`
-	Monitored  bool     `json:"monitored"`
-	Path       string   `json:"path"`
-	SeriesType string   `json:"seriesType"` // standard, anime, daily
-	TVDBID     int64    `json:"tvdbId"`
-	IMDBID     string   `json:"imdbId"`
-	Ratings    Ratings  `json:"ratings"`
-	Statistics Stats    `json:"statistics"`
+	ID               int64     `json:"id"`
+	Title            string    `json:"title"`
+	Year             int       `json:"year"`
+	Overview         string    `json:"overview"`
+	Runtime          int       `json:"runtime"`
+	Genres           []string  `json:"genres"`
+	Status           string    `json:"status"`
+	Monitored        bool      `json:"monitored"`
+	Path             string    `json:"path"`
+	SeriesType       string    `json:"seriesType"` // standard, anime, daily
+	TVDBID           int64     `json:"tvdbId"`
+	IMDBID           string    `json:"imdbId"`
+	Ratings          Ratings   `json:"ratings"`
+	Statistics       Stats     `json:"statistics"`
+	Tags             []int64   `json:"tags"`
+	Certification    string    `json:"certification"`
+	OriginalLanguage Language  `json:"originalLanguage"`
+	Added            time.Time `json:"added"`
+	Images           []Image   `json:"images"`
+}
+
+// Image is a piece of artwork, such as a poster or fanart
+type Image struct {
+	CoverType string `json:"coverType"` // poster, fanart, banner, ...
+	URL       string `json:"url"`       // path on the Sonarr server
+	RemoteURL string `json:"remoteUrl"` // upstream URL on TheTVDB
+}
+
+// ImageURL returns the URL of the first image of coverType, preferring its remote URL, or ""
+func ImageURL(images []Image, coverType string) string {
+	for _, img := range images {
+		if img.CoverType != coverType {
+			continue
+		}
+		if img.RemoteURL != "" {
+			return img.RemoteURL
+		}
+		return img.URL
+	}
+	return ""
+}
+
+// Language is a language as reported by Sonarr
+type Language struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
 }
 
 // Ratings holds rating information
@@ -67,6 +108,32 @@ type Stats struct {
 	PercentOfEpisodes float64 `json:"percentOfEpisodes"`
 }
 
+// Name returns the configured instance name
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Tag is a Sonarr tag definition
+type Tag struct {
+	ID    int64  `json:"id"`
+	Label string `json:"label"`
+}
+
+// GetTags retrieves all tag definitions
+func (c *Client) GetTags(ctx context.Context) ([]Tag, error) {
+	req, err := c.newRequest(ctx, "GET", "/api/v3/tag", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []Tag
+	if err := c.do(req, &tags); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return tags, nil
+}
+
 // GetSeries retrieves all series from Sonarr
 func (c *Client) GetSeries(ctx context.Context) ([]Series, error) {
 	req, err := c.newRequest(ctx, "GET", "/api/v3/series", nil)
@@ -82,15 +149,44 @@ func (c *Client) GetSeries(ctx context.Context) ([]Series, error) {
 	return series, nil
 }
 
+// GetSeriesByID retrieves a single series by its Sonarr ID
+func (c *Client) GetSeriesByID(ctx context.Context, id int64) (*Series, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("/api/v3/series/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Series
+	if err := c.do(req, &s); err != nil {
+		return nil, fmt.Errorf("failed to get series %d: %w", id, err)
+	}
+
+	return &s, nil
+}
+
+// HealthCheck verifies that Sonarr is reachable and the API key is accepted
+func (c *Client) HealthCheck(ctx context.Context) error {
+	req, err := c.newRequest(ctx, "GET", "/api/v3/system/status", nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.do(req, nil); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
+}
+
 // ToMedia converts a Sonarr series to a Media model
 func (s *Series) ToMedia() *models.Media {
 	// Determine media type based on series type
 	mediaType := models.MediaTypeSeries
-	if s.SeriesType == "anime" || isAnime(s.Genres) {
+	if s.IsAnime() {
 		mediaType = models.MediaTypeAnime
 	}
 
-	return &models.Media{
+	media := &models.Media{
 		ExternalID: s.ID,
 		Source:     models.MediaSourceSonarr,
 		MediaType:  mediaType,
@@ -108,7 +204,22 @@ func (s *Series) ToMedia() *models.Media {
 		SizeOnDisk: s.Statistics.SizeOnDisk,
 		Status:     s.Status,
 		Monitored:  s.Monitored,
+
+		Certification:    s.Certification,
+		OriginalLanguage: models.LanguageCode(s.OriginalLanguage.Name),
+
+		PosterURL: ImageURL(s.Images, "poster"),
+		FanartURL: ImageURL(s.Images, "fanart"),
 	}
+	if !s.Added.IsZero() {
+		media.AddedAt = &s.Added
+	}
+	return media
+}
+
+// IsAnime guesses whether the series is anime from its series type and genres
+func (s *Series) IsAnime() bool {
+	return s.SeriesType == "anime" || isAnime(s.Genres)
 }
 
 // isAnime checks if the genres indicate anime content
@@ -153,25 +264,5 @@ func (c *Client) newRequest(ctx context.Context, method, path string, body io.Re
 
 // do executes an HTTP request and decodes the JSON response
 func (c *Client) do(req *http.Request, v interface{}) error {
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
-		}
-		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	if v != nil {
-		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
-
-	return nil
+	return httpx.Do(c.httpClient, req, v)
 }
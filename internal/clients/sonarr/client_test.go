@@ -0,0 +1,27 @@
+package sonarr
+
+import "testing"
+
+func TestSeriesToMediaArtwork(t *testing.T) {
+	series := &Series{
+		ID:    1,
+		Title: "Twin Peaks",
+		Images: []Image{
+			{CoverType: "banner", URL: "/MediaCover/1/banner.jpg"},
+			{CoverType: "poster", URL: "/MediaCover/1/poster.jpg", RemoteURL: "https://artworks.thetvdb.com/twin-peaks.jpg"},
+			{CoverType: "fanart", URL: "/MediaCover/1/fanart.jpg"},
+		},
+	}
+
+	media := series.ToMedia()
+	if media.PosterURL != "https://artworks.thetvdb.com/twin-peaks.jpg" {
+		t.Errorf("expected the remote poster URL, got %q", media.PosterURL)
+	}
+	if media.FanartURL != "/MediaCover/1/fanart.jpg" {
+		t.Errorf("expected the local fanart path without a remote URL, got %q", media.FanartURL)
+	}
+
+	if media := (&Series{Title: "Twin Peaks"}).ToMedia(); media.PosterURL != "" || media.FanartURL != "" {
+		t.Errorf("expected no artwork without images, got %q and %q", media.PosterURL, media.FanartURL)
+	}
+}
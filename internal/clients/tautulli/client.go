@@ -0,0 +1,163 @@
+// Package tautulli provides a client for interacting with the Tautulli API.
+package tautulli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/upstream"
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// Client is a Tautulli API client
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// New creates a new Tautulli client
+func New(cfg *config.TautulliConfig) *Client {
+	return &Client{
+		baseURL: cfg.URL,
+		apiKey:  cfg.APIKey,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: upstream.Transport("tautulli", nil),
+		},
+	}
+}
+
+// HistoryEntry is a single playback session from Tautulli's history
+type HistoryEntry struct {
+	ID               int64   `json:"id"`
+	Date             int64   `json:"date"` // unix seconds
+	User             string  `json:"user"`
+	MediaType        string  `json:"media_type"` // movie, episode, track
+	Title            string  `json:"title"`
+	GrandparentTitle string  `json:"grandparent_title"` // show title for episodes
+	Year             flexInt `json:"year"`
+	GUID             string  `json:"guid"`
+	WatchedStatus    float64 `json:"watched_status"` // 0, 0.5 (partial), or 1
+}
+
+// WatchedAt returns the time the session started
+func (h *HistoryEntry) WatchedAt() time.Time {
+	return time.Unix(h.Date, 0)
+}
+
+// flexInt decodes integers Tautulli sometimes returns as strings or empty values
+type flexInt int
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*f = flexInt(n)
+	return nil
+}
+
+// imdbGUIDPattern extracts IMDB IDs from legacy Plex agent GUIDs (com.plexapp.agents.imdb://tt0113277?lang=en)
+var imdbGUIDPattern = regexp.MustCompile(`imdb://(tt\d+)`)
+
+// IMDBID returns the IMDB ID embedded in the entry's GUID, if any
+func (h *HistoryEntry) IMDBID() string {
+	if m := imdbGUIDPattern.FindStringSubmatch(h.GUID); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// apiResponse wraps every Tautulli API response
+type apiResponse struct {
+	Response struct {
+		Result  string          `json:"result"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	} `json:"response"`
+}
+
+// GetHistory retrieves playback history since the given time, newest first.
+// start and length page through the results.
+func (c *Client) GetHistory(ctx context.Context, after time.Time, start, length int) ([]HistoryEntry, error) {
+	params := url.Values{}
+	params.Set("start", strconv.Itoa(start))
+	params.Set("length", strconv.Itoa(length))
+	params.Set("order_column", "date")
+	params.Set("order_dir", "desc")
+	if !after.IsZero() {
+		params.Set("after", after.Format("2006-01-02"))
+	}
+
+	var data struct {
+		Data []HistoryEntry `json:"data"`
+	}
+	if err := c.call(ctx, "get_history", params, &data); err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+
+	return data.Data, nil
+}
+
+// call invokes a Tautulli API command and decodes its data payload
+func (c *Client) call(ctx context.Context, cmd string, params url.Values, v interface{}) error {
+	u, err := url.Parse(c.baseURL + "/api/v2")
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	params.Set("apikey", c.apiKey)
+	params.Set("cmd", cmd)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if apiResp.Response.Result != "success" {
+		return fmt.Errorf("API error: %s", apiResp.Response.Message)
+	}
+
+	if v != nil {
+		if err := json.Unmarshal(apiResp.Response.Data, v); err != nil {
+			return fmt.Errorf("failed to decode data: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package tautulli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestGetHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v2" || q.Get("cmd") != "get_history" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		if q.Get("apikey") != "test-key" {
+			t.Errorf("expected apikey parameter")
+		}
+		if q.Get("after") != "2025-01-02" {
+			t.Errorf("expected after=2025-01-02, got %s", q.Get("after"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"response": {"result": "success", "message": null, "data": {"data": [
+			{"id": 12, "date": 1735900000, "user": "alex", "media_type": "movie", "title": "Heat", "year": 1995,
+			 "guid": "com.plexapp.agents.imdb://tt0113277?lang=en", "watched_status": 1},
+			{"id": 13, "date": 1735910000, "user": "sam", "media_type": "episode", "title": "Pilot",
+			 "grandparent_title": "Breaking Bad", "year": "", "guid": "plex://episode/5d9c", "watched_status": 0.5}
+		]}}}`))
+	}))
+	defer server.Close()
+
+	client := New(&config.TautulliConfig{URL: server.URL, APIKey: "test-key"})
+
+	entries, err := client.GetHistory(context.Background(), time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), 0, 100)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].IMDBID() != "tt0113277" || entries[0].Year != 1995 {
+		t.Errorf("unexpected first entry %+v", entries[0])
+	}
+
+	if entries[1].IMDBID() != "" || entries[1].Year != 0 || entries[1].GrandparentTitle != "Breaking Bad" {
+		t.Errorf("unexpected second entry %+v", entries[1])
+	}
+}
+
+func TestGetHistoryAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"response": {"result": "error", "message": "Invalid apikey", "data": {}}}`))
+	}))
+	defer server.Close()
+
+	client := New(&config.TautulliConfig{URL: server.URL, APIKey: "bad"})
+
+	if _, err := client.GetHistory(context.Background(), time.Time{}, 0, 100); err == nil {
+		t.Error("expected error for unsuccessful API result")
+	}
+}
@@ -0,0 +1,261 @@
+// Package tmdb provides a client for interacting with The Movie Database (TMDB) API.
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/upstream"
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+const (
+	baseURL       = "https://api.themoviedb.org/3"
+	defaultRegion = "US"
+)
+
+// Client is a TMDB API client
+type Client struct {
+	baseURL    string
+	apiKey     string
+	region     string
+	httpClient *http.Client
+}
+
+// New creates a new TMDB client
+func New(cfg *config.TMDBConfig) *Client {
+	region := strings.ToUpper(cfg.Region)
+	if region == "" {
+		region = defaultRegion
+	}
+
+	return &Client{
+		baseURL: baseURL,
+		apiKey:  cfg.APIKey,
+		region:  region,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: upstream.Transport("tmdb", nil),
+		},
+	}
+}
+
+// Details holds the enrichment data TMDB provides for a title
+type Details struct {
+	OriginalLanguage string
+	Countries        []string // ISO 3166-1 origin countries
+	Keywords         []string
+	Certification    string // for the configured region, empty if unrated
+}
+
+// keyword is a TMDB keyword
+type keyword struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// movieResponse is the movie details payload with keywords and release dates appended
+type movieResponse struct {
+	OriginalLanguage    string   `json:"original_language"`
+	OriginCountry       []string `json:"origin_country"`
+	ProductionCountries []struct {
+		Code string `json:"iso_3166_1"`
+	} `json:"production_countries"`
+	Keywords struct {
+		Keywords []keyword `json:"keywords"`
+	} `json:"keywords"`
+	ReleaseDates struct {
+		Results []struct {
+			Region       string `json:"iso_3166_1"`
+			ReleaseDates []struct {
+				Certification string `json:"certification"`
+				Type          int    `json:"type"`
+			} `json:"release_dates"`
+		} `json:"results"`
+	} `json:"release_dates"`
+}
+
+// tvResponse is the TV details payload with keywords and content ratings appended
+type tvResponse struct {
+	OriginalLanguage string   `json:"original_language"`
+	OriginCountry    []string `json:"origin_country"`
+	Keywords         struct {
+		Results []keyword `json:"results"`
+	} `json:"keywords"`
+	ContentRatings struct {
+		Results []struct {
+			Region string `json:"iso_3166_1"`
+			Rating string `json:"rating"`
+		} `json:"results"`
+	} `json:"content_ratings"`
+}
+
+// releaseTypeTheatrical is TMDB's release type for theatrical releases
+const releaseTypeTheatrical = 3
+
+// GetMovieDetails retrieves keywords, certification, and original language for a movie
+func (c *Client) GetMovieDetails(ctx context.Context, tmdbID int64) (*Details, error) {
+	path := fmt.Sprintf("/movie/%d?append_to_response=keywords,release_dates", tmdbID)
+	req, err := c.newRequest(ctx, "GET", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp movieResponse
+	if err := c.do(req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get movie %d: %w", tmdbID, err)
+	}
+
+	details := &Details{
+		OriginalLanguage: resp.OriginalLanguage,
+		Countries:        resp.OriginCountry,
+		Keywords:         keywordNames(resp.Keywords.Keywords),
+	}
+
+	// Older movie records only list production countries
+	if len(details.Countries) == 0 {
+		for _, c := range resp.ProductionCountries {
+			details.Countries = append(details.Countries, c.Code)
+		}
+	}
+
+	for _, r := range resp.ReleaseDates.Results {
+		if r.Region != c.region {
+			continue
+		}
+		// Prefer the theatrical certification, fall back to any other release
+		for _, rd := range r.ReleaseDates {
+			if rd.Certification == "" {
+				continue
+			}
+			if rd.Type == releaseTypeTheatrical {
+				details.Certification = rd.Certification
+				break
+			}
+			if details.Certification == "" {
+				details.Certification = rd.Certification
+			}
+		}
+	}
+
+	return details, nil
+}
+
+// GetTVDetails retrieves keywords, content rating, and original language for a TV show
+func (c *Client) GetTVDetails(ctx context.Context, tmdbID int64) (*Details, error) {
+	path := fmt.Sprintf("/tv/%d?append_to_response=keywords,content_ratings", tmdbID)
+	req, err := c.newRequest(ctx, "GET", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp tvResponse
+	if err := c.do(req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get tv show %d: %w", tmdbID, err)
+	}
+
+	details := &Details{
+		OriginalLanguage: resp.OriginalLanguage,
+		Countries:        resp.OriginCountry,
+		Keywords:         keywordNames(resp.Keywords.Results),
+	}
+
+	for _, r := range resp.ContentRatings.Results {
+		if r.Region == c.region {
+			details.Certification = r.Rating
+			break
+		}
+	}
+
+	return details, nil
+}
+
+// FindTVByTVDB resolves a TVDB series ID to a TMDB TV ID. It returns 0 when TMDB has no match.
+func (c *Client) FindTVByTVDB(ctx context.Context, tvdbID int64) (int64, error) {
+	path := fmt.Sprintf("/find/%d?external_source=tvdb_id", tvdbID)
+	req, err := c.newRequest(ctx, "GET", path)
+	if err != nil {
+		return 0, err
+	}
+
+	var resp struct {
+		TVResults []struct {
+			ID int64 `json:"id"`
+		} `json:"tv_results"`
+	}
+	if err := c.do(req, &resp); err != nil {
+		return 0, fmt.Errorf("failed to find tvdb %d: %w", tvdbID, err)
+	}
+
+	if len(resp.TVResults) == 0 {
+		return 0, nil
+	}
+	return resp.TVResults[0].ID, nil
+}
+
+func keywordNames(keywords []keyword) []string {
+	names := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		names = append(names, k.Name)
+	}
+	return names
+}
+
+// newRequest creates a new HTTP request authenticated with the API key.
+// v4 read access tokens (JWTs) are sent as a bearer token, v3 keys as a query parameter.
+func (c *Client) newRequest(ctx context.Context, method, path string) (*http.Request, error) {
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	bearer := strings.Count(c.apiKey, ".") == 2
+	if !bearer {
+		q := u.Query()
+		q.Set("api_key", c.apiKey)
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if bearer {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	return req, nil
+}
+
+// do executes an HTTP request and decodes the JSON response
+func (c *Client) do(req *http.Request, v interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package tmdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestGetMovieDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/movie/949" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("api_key") != "test-key" {
+			t.Errorf("expected api_key query parameter")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{
+			"original_language": "en",
+			"production_countries": [{"iso_3166_1": "US", "name": "United States of America"}],
+			"keywords": {"keywords": [{"id": 1, "name": "heist"}, {"id": 2, "name": "los angeles"}]},
+			"release_dates": {"results": [
+				{"iso_3166_1": "DE", "release_dates": [{"certification": "16", "type": 3}]},
+				{"iso_3166_1": "US", "release_dates": [
+					{"certification": "", "type": 1},
+					{"certification": "NR", "type": 4},
+					{"certification": "R", "type": 3}
+				]}
+			]}
+		}`))
+	}))
+	defer server.Close()
+
+	client := New(&config.TMDBConfig{APIKey: "test-key"})
+	client.baseURL = server.URL
+
+	details, err := client.GetMovieDetails(context.Background(), 949)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if details.OriginalLanguage != "en" {
+		t.Errorf("expected original language en, got %s", details.OriginalLanguage)
+	}
+	if details.Certification != "R" {
+		t.Errorf("expected theatrical certification R, got %s", details.Certification)
+	}
+	if len(details.Countries) != 1 || details.Countries[0] != "US" {
+		t.Errorf("expected production country fallback US, got %v", details.Countries)
+	}
+	if len(details.Keywords) != 2 || details.Keywords[0] != "heist" {
+		t.Errorf("unexpected keywords %v", details.Keywords)
+	}
+}
+
+func TestGetTVDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/find/81189":
+			w.Write([]byte(`{"tv_results": [{"id": 1396}]}`))
+		case "/tv/1396":
+			if r.Header.Get("Authorization") != "Bearer aaa.bbb.ccc" {
+				t.Errorf("expected bearer token, got %q", r.Header.Get("Authorization"))
+			}
+			w.Write([]byte(`{
+				"original_language": "en",
+				"keywords": {"results": [{"id": 1, "name": "drug trade"}]},
+				"content_ratings": {"results": [{"iso_3166_1": "GB", "rating": "18"}, {"iso_3166_1": "US", "rating": "TV-MA"}]}
+			}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := New(&config.TMDBConfig{APIKey: "aaa.bbb.ccc"})
+	client.baseURL = server.URL
+
+	tvID, err := client.FindTVByTVDB(context.Background(), 81189)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tvID != 1396 {
+		t.Fatalf("expected tmdb id 1396, got %d", tvID)
+	}
+
+	details, err := client.GetTVDetails(context.Background(), tvID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if details.Certification != "TV-MA" {
+		t.Errorf("expected certification TV-MA, got %s", details.Certification)
+	}
+}
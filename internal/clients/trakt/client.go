@@ -6,8 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/geekxflood/program-director/internal/clients/upstream"
 	"github.com/geekxflood/program-director/internal/config"
 )
 
@@ -30,7 +33,8 @@ func New(cfg *config.TraktConfig) *Client {
 		baseURL:  baseURL,
 		clientID: cfg.ClientID,
 		httpClient: &http.Client{
-			Timeout: defaultTimeout,
+			Timeout:   defaultTimeout,
+			Transport: upstream.Transport("trakt", nil),
 		},
 	}
 }
@@ -131,10 +135,10 @@ type PopularShow struct {
 
 // SearchResult represents a search result
 type SearchResult struct {
-	Type  string `json:"type"`
+	Type  string  `json:"type"`
 	Score float64 `json:"score"`
-	Movie *Movie `json:"movie,omitempty"`
-	Show  *Show  `json:"show,omitempty"`
+	Movie *Movie  `json:"movie,omitempty"`
+	Show  *Show   `json:"show,omitempty"`
 }
 
 // GetTrendingMovies retrieves currently trending movies
@@ -201,3 +205,54 @@ func (c *Client) Search(ctx context.Context, query string, limit int) ([]SearchR
 	}
 	return results, nil
 }
+
+// ListItem represents an entry in a Trakt list
+type ListItem struct {
+	Rank     int    `json:"rank"`
+	ID       int64  `json:"id"`
+	ListedAt string `json:"listed_at"`
+	Type     string `json:"type"` // movie, show
+	Movie    *Movie `json:"movie,omitempty"`
+	Show     *Show  `json:"show,omitempty"`
+}
+
+// GetListItems retrieves the movies and shows of a user's public list
+func (c *Client) GetListItems(ctx context.Context, user, slug string) ([]ListItem, error) {
+	var items []ListItem
+	path := fmt.Sprintf("/users/%s/lists/%s/items/movie,show", url.PathEscape(user), url.PathEscape(slug))
+	if err := c.doRequest(ctx, http.MethodGet, path, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ParseListRef splits a list reference into user and slug. It accepts
+// "user/slug", "users/user/lists/slug", and full https://trakt.tv URLs.
+func ParseListRef(ref string) (user, slug string, err error) {
+	ref = strings.TrimSpace(ref)
+	ref = strings.TrimPrefix(ref, "https://")
+	ref = strings.TrimPrefix(ref, "http://")
+	ref = strings.TrimPrefix(ref, "trakt.tv/")
+	ref = strings.TrimPrefix(ref, "users/")
+	ref = strings.Trim(ref, "/")
+
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		ref = ref[:i]
+	}
+
+	parts := strings.Split(ref, "/")
+	switch {
+	case len(parts) == 2:
+		user, slug = parts[0], parts[1]
+	case len(parts) == 3 && parts[1] == "lists":
+		user, slug = parts[0], parts[2]
+	default:
+		return "", "", fmt.Errorf("invalid trakt list reference %q (expected user/list-slug)", ref)
+	}
+
+	if user == "" || slug == "" {
+		return "", "", fmt.Errorf("invalid trakt list reference %q (expected user/list-slug)", ref)
+	}
+
+	return user, slug, nil
+}
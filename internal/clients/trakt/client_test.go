@@ -133,3 +133,66 @@ func TestDoRequestError(t *testing.T) {
 		t.Error("expected error for 500 response, got nil")
 	}
 }
+
+func TestGetListItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/someone/lists/best-heist-movies/items/movie,show" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[
+			{"rank": 1, "type": "movie", "movie": {"title": "Heat", "year": 1995, "ids": {"trakt": 1, "imdb": "tt0113277", "tmdb": 949}}},
+			{"rank": 2, "type": "show", "show": {"title": "Lupin", "year": 2021, "ids": {"trakt": 2, "tvdb": 366529}}}
+		]`))
+	}))
+	defer server.Close()
+
+	client := New(&config.TraktConfig{ClientID: "test-key"})
+	client.baseURL = server.URL
+
+	items, err := client.GetListItems(context.Background(), "someone", "best-heist-movies")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	if items[0].Movie == nil || items[0].Movie.IDs.TMDB != 949 {
+		t.Errorf("expected first item to be movie with tmdb 949, got %+v", items[0])
+	}
+
+	if items[1].Show == nil || items[1].Show.IDs.TVDB != 366529 {
+		t.Errorf("expected second item to be show with tvdb 366529, got %+v", items[1])
+	}
+}
+
+func TestParseListRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		wantUser string
+		wantSlug string
+		wantErr  bool
+	}{
+		{ref: "someone/best-heist-movies", wantUser: "someone", wantSlug: "best-heist-movies"},
+		{ref: "https://trakt.tv/users/someone/lists/best-heist-movies", wantUser: "someone", wantSlug: "best-heist-movies"},
+		{ref: "https://trakt.tv/users/someone/lists/best-heist-movies?sort=rank,asc", wantUser: "someone", wantSlug: "best-heist-movies"},
+		{ref: "best-heist-movies", wantErr: true},
+		{ref: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			user, slug, err := ParseListRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseListRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if user != tt.wantUser || slug != tt.wantSlug {
+				t.Errorf("ParseListRef() = %s, %s, want %s, %s", user, slug, tt.wantUser, tt.wantSlug)
+			}
+		})
+	}
+}
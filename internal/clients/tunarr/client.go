@@ -9,24 +9,36 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
+	"github.com/geekxflood/program-director/internal/clients/httpx"
+	"github.com/geekxflood/program-director/internal/clients/upstream"
 	"github.com/geekxflood/program-director/internal/config"
 )
 
 // Client is a Tunarr API client
 type Client struct {
 	baseURL    string
+	publicURL  string
 	httpClient *http.Client
 }
 
 // New creates a new Tunarr client
 func New(cfg *config.TunarrConfig) *Client {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	publicURL := cfg.PublicURL
+	if publicURL == "" {
+		publicURL = cfg.URL
+	}
 	return &Client{
-		baseURL: cfg.URL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:    cfg.URL,
+		publicURL:  strings.TrimSuffix(publicURL, "/"),
+		httpClient: upstream.Client("tunarr", timeout, cfg.Proxy, cfg.TLS),
 	}
 }
 
@@ -39,6 +51,7 @@ type Channel struct {
 	GroupTitle     string      `json:"groupTitle"`
 	ProgramCount   int         `json:"programCount"`
 	Duration       int64       `json:"duration"`
+	StartTime      int64       `json:"startTime"` // Unix milliseconds the lineup loops from
 	StreamerSource string      `json:"steamerSource"`
 }
 
@@ -49,6 +62,9 @@ type ChannelIcon struct {
 	Height int    `json:"height"`
 }
 
+// APIError is returned when Tunarr answers with a non-2xx status
+type APIError = httpx.StatusError
+
 // Program represents a program in a channel lineup
 type Program struct {
 	ID          string `json:"id,omitempty"`
@@ -67,6 +83,7 @@ type Program struct {
 	Summary string `json:"summary,omitempty"`
 	Rating  string `json:"rating,omitempty"`
 	Year    int    `json:"year,omitempty"`
+	Icon    string `json:"icon,omitempty"` // Poster URL shown in the guide
 }
 
 // Programming represents the programming lineup for a channel
@@ -104,6 +121,21 @@ type PlexMedia struct {
 	ContentRating string `json:"contentRating"`
 }
 
+// GetChannels retrieves all channels
+func (c *Client) GetChannels(ctx context.Context) ([]Channel, error) {
+	req, err := c.newRequest(ctx, "GET", "/api/channels", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var channels []Channel
+	if err := c.do(req, &channels); err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+
+	return channels, nil
+}
+
 // GetChannel retrieves a single channel by ID
 func (c *Client) GetChannel(ctx context.Context, id string) (*Channel, error) {
 	req, err := c.newRequest(ctx, "GET", "/api/channels/"+id, nil)
@@ -119,6 +151,35 @@ func (c *Client) GetChannel(ctx context.Context, id string) (*Channel, error) {
 	return &channel, nil
 }
 
+// StreamURL returns the MPEG-TS stream URL of a channel, as players reach Tunarr
+func (c *Client) StreamURL(channel Channel) string {
+	return fmt.Sprintf("%s/stream/channels/%s.ts", c.publicURL, url.PathEscape(channel.ID))
+}
+
+// IconURL returns the icon URL of a channel, as players reach Tunarr, or "" without an icon
+func (c *Client) IconURL(channel Channel) string {
+	path := channel.Icon.Path
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return c.publicURL + "/" + strings.TrimPrefix(path, "/")
+}
+
+// GetProgramming retrieves the current programming lineup for a channel
+func (c *Client) GetProgramming(ctx context.Context, channelID string) (*Programming, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("/api/channels/%s/programming", channelID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var programming Programming
+	if err := c.do(req, &programming); err != nil {
+		return nil, fmt.Errorf("failed to get programming for channel %s: %w", channelID, err)
+	}
+
+	return &programming, nil
+}
+
 // SetProgramming sets the programming for a channel
 func (c *Client) SetProgramming(ctx context.Context, channelID string, programming *Programming) error {
 	body, err := json.Marshal(programming)
@@ -153,6 +214,20 @@ func (c *Client) GetMediaSources(ctx context.Context) ([]MediaSource, error) {
 	return sources, nil
 }
 
+// HealthCheck verifies that Tunarr is reachable
+func (c *Client) HealthCheck(ctx context.Context) error {
+	req, err := c.newRequest(ctx, "GET", "/api/version", nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.do(req, nil); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
+}
+
 // newRequest creates a new HTTP request
 func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
 	u, err := url.Parse(c.baseURL + path)
@@ -172,25 +247,5 @@ func (c *Client) newRequest(ctx context.Context, method, path string, body io.Re
 
 // do executes an HTTP request and decodes the JSON response
 func (c *Client) do(req *http.Request, v interface{}) error {
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("API error: status %d, failed to read body: %w", resp.StatusCode, err)
-		}
-		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	if v != nil {
-		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
-
-	return nil
+	return httpx.Do(c.httpClient, req, v)
 }
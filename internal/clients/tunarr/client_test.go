@@ -0,0 +1,80 @@
+package tunarr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestGetProgramming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/channels/ch1/programming" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{
+			"type": "manual",
+			"programs": [
+				{"type": "content", "duration": 5400000, "title": "Alien", "year": 1979}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	client := New(&config.TunarrConfig{URL: server.URL})
+
+	programming, err := client.GetProgramming(context.Background(), "ch1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if programming.Type != "manual" {
+		t.Errorf("expected type manual, got %s", programming.Type)
+	}
+
+	if len(programming.Programs) != 1 || programming.Programs[0].Title != "Alien" {
+		t.Errorf("unexpected programs: %+v", programming.Programs)
+	}
+}
+
+func TestGetChannels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/channels" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": "ch1", "number": 1, "name": "Sci-Fi", "programCount": 12},
+			{"id": "ch2", "number": 2, "name": "Horror", "programCount": 0}
+		]`))
+	}))
+	defer server.Close()
+
+	client := New(&config.TunarrConfig{URL: server.URL})
+
+	channels, err := client.GetChannels(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(channels) != 2 || channels[0].ID != "ch1" || channels[0].ProgramCount != 12 || channels[1].Number != 2 {
+		t.Errorf("unexpected channels: %+v", channels)
+	}
+}
+
+func TestGetProgrammingError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := New(&config.TunarrConfig{URL: server.URL})
+
+	if _, err := client.GetProgramming(context.Background(), "missing"); err == nil {
+		t.Error("expected error for 404 response, got nil")
+	}
+}
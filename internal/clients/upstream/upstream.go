@@ -0,0 +1,360 @@
+// Package upstream wraps the HTTP transport of the Radarr, Sonarr, Tunarr, Ollama, and other
+// upstream clients with retries of transient failures and a circuit breaker per upstream.
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/httpx"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/metrics"
+)
+
+// ErrCircuitOpen is returned, without contacting the upstream, while its circuit is open. It
+// matches httpx.ErrUpstreamUnavailable.
+var ErrCircuitOpen = fmt.Errorf("circuit open: %w", httpx.ErrUpstreamUnavailable)
+
+// Circuit states reported by State
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open" // the cooldown is over; the next request probes the upstream
+)
+
+// settings are the retry and breaker settings shared by every transport
+type settings struct {
+	retries         int
+	retryDelay      time.Duration
+	maxRetryDelay   time.Duration
+	breakerFailures int
+	breakerCooldown time.Duration
+	logger          *slog.Logger
+}
+
+// current holds the settings of Configure; until it is called requests are neither retried
+// nor broken
+var current atomic.Pointer[settings]
+
+// Configure applies cfg to every transport, existing or not. It may be called again on reload.
+func Configure(cfg *config.UpstreamConfig, logger *slog.Logger) {
+	current.Store(&settings{
+		retries:         cfg.Retries,
+		retryDelay:      time.Duration(cfg.RetryDelay) * time.Second,
+		maxRetryDelay:   time.Duration(cfg.MaxRetryDelay) * time.Second,
+		breakerFailures: cfg.BreakerFailures,
+		breakerCooldown: time.Duration(cfg.BreakerCooldown) * time.Second,
+		logger:          logger,
+	})
+}
+
+func load() settings {
+	if s := current.Load(); s != nil {
+		return *s
+	}
+	return settings{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+// outcome is how a request counts for the breaker of its upstream
+type outcome int
+
+const (
+	succeeded outcome = iota
+	failed            // network error or 5xx answer
+	abandoned         // canceled by the caller, which says nothing about the upstream
+)
+
+// breaker tracks the consecutive failures of an upstream and whether its circuit is open
+type breaker struct {
+	name string
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // zero while closed
+	probing  bool      // a half-open probe is in flight
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = make(map[string]*breaker)
+)
+
+// breakerFor returns the breaker of the upstream called name, shared by all its transports
+func breakerFor(name string) *breaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	b, ok := breakers[name]
+	if !ok {
+		b = &breaker{name: name}
+		breakers[name] = b
+	}
+	return b
+}
+
+// allow reports whether a request may go out, letting a single probe through once the
+// cooldown of an open circuit is over
+func (b *breaker) allow(s settings, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if s.breakerFailures <= 0 || b.openedAt.IsZero() {
+		return true
+	}
+	if b.probing || now.Sub(b.openedAt) < s.breakerCooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record counts the outcome of a request, opening the circuit after too many failures in a
+// row or a failed probe and closing it on any success
+func (b *breaker) record(s settings, o outcome, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasOpen := !b.openedAt.IsZero()
+	b.probing = false
+	switch o {
+	case succeeded:
+		b.failures = 0
+		b.openedAt = time.Time{}
+		if wasOpen {
+			s.logger.Info("upstream circuit closed", "upstream", b.name)
+			metrics.UpstreamCircuitOpen.WithLabelValues(b.name).Set(0)
+		}
+	case failed:
+		b.failures++
+		if s.breakerFailures > 0 && (wasOpen || b.failures >= s.breakerFailures) {
+			b.openedAt = now
+			if !wasOpen {
+				s.logger.Warn("upstream circuit opened", "upstream", b.name, "failures", b.failures, "cooldown", s.breakerCooldown)
+				metrics.UpstreamCircuitOpen.WithLabelValues(b.name).Set(1)
+			}
+		}
+	}
+}
+
+// state returns the circuit state at now
+func (b *breaker) state(s settings, now time.Time) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.openedAt.IsZero():
+		return StateClosed
+	case now.Sub(b.openedAt) < s.breakerCooldown:
+		return StateOpen
+	default:
+		return StateHalfOpen
+	}
+}
+
+// State returns the circuit state of the upstream called name, or "" when no client of it
+// was created
+func State(name string) string {
+	breakersMu.Lock()
+	b, ok := breakers[name]
+	breakersMu.Unlock()
+	if !ok {
+		return ""
+	}
+	return b.state(load(), time.Now())
+}
+
+// transport retries transient failures of an upstream and fails fast while its circuit is open
+type transport struct {
+	base    http.RoundTripper
+	breaker *breaker
+}
+
+// Transport wraps base, or http.DefaultTransport when nil, for the upstream called name,
+// such as "tunarr" or "radarr:4k", instrumenting each attempt with httpx. Transports of the
+// same name share a circuit breaker.
+func Transport(name string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: httpx.Instrument(name, base), breaker: breakerFor(name)}
+}
+
+// Client returns an HTTP client for the upstream called name that gives up on requests after
+// timeout. Requests go through proxy, or the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY proxy when
+// it is empty, with the TLS options of tlsCfg. A CA file that can no longer be read fails
+// every request, so it shows in health checks.
+func Client(name string, timeout time.Duration, proxy string, tlsCfg config.TLSConfig) *http.Client {
+	base, err := baseTransport(proxy, tlsCfg)
+	if err != nil {
+		base = failingTransport{err: err}
+	}
+	return &http.Client{Timeout: timeout, Transport: Transport(name, base)}
+}
+
+// baseTransport returns a transport with the proxy and TLS options of an upstream, or nil
+// for http.DefaultTransport when both are left to their defaults
+func baseTransport(proxy string, tlsCfg config.TLSConfig) (http.RoundTripper, error) {
+	clientTLS, err := tlsCfg.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if proxy == "" && clientTLS == nil {
+		return nil, nil
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = clientTLS
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, errors.New("invalid proxy URL")
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	return t, nil
+}
+
+// failingTransport fails every request with err
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
+
+// RoundTrip sends req, retrying idempotent requests after transient failures with jittered
+// exponential backoff, or a Retry-After answer within the maximum delay
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s := load()
+	ctx := req.Context()
+	name := t.breaker.name
+
+	if !t.breaker.allow(s, time.Now()) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("%s: %w", name, ErrCircuitOpen)
+	}
+
+	retries := s.retries
+	if !replayable(req) {
+		retries = 0
+	}
+	delay := s.retryDelay
+
+	for attempt := 0; ; attempt++ {
+		out := req
+		if attempt > 0 {
+			out = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					t.breaker.record(s, abandoned, time.Now())
+					return nil, err
+				}
+				out.Body = body
+			}
+		}
+
+		resp, err := t.base.RoundTrip(out)
+		if attempt >= retries || !transient(ctx, resp, err) {
+			t.breaker.record(s, result(ctx, resp, err), time.Now())
+			return resp, err
+		}
+
+		wait := backoff(delay, s.maxRetryDelay, resp)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		s.logger.DebugContext(ctx, "retrying upstream request",
+			"upstream", name, "method", req.Method, "attempt", attempt+1, "wait", wait, "error", failure(resp, err))
+		metrics.UpstreamRetries.WithLabelValues(name).Inc()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			t.breaker.record(s, abandoned, time.Now())
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+		if s.maxRetryDelay > 0 && delay > s.maxRetryDelay {
+			delay = s.maxRetryDelay
+		}
+	}
+}
+
+// replayable reports whether req is idempotent and can be sent again with its body
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// transient reports whether a request may succeed if sent again: network errors other than
+// the caller's cancellation, and 429, 502, 503, and 504 answers
+func transient(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// result classifies the final answer of a request for the breaker
+func result(ctx context.Context, resp *http.Response, err error) outcome {
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return abandoned
+	case err != nil, resp.StatusCode >= 500:
+		return failed
+	default:
+		return succeeded
+	}
+}
+
+// backoff returns the wait before the next attempt: the Retry-After of resp when set and
+// within maxDelay, or else delay with up to half of it taken off at random
+func backoff(delay, maxDelay time.Duration, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			if wait := time.Duration(seconds) * time.Second; maxDelay <= 0 || wait <= maxDelay {
+				return wait
+			}
+		}
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay - rand.N(delay/2+1)
+}
+
+// failure describes why an attempt is retried
+func failure(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "status " + strconv.Itoa(resp.StatusCode)
+}
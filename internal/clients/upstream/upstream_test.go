@@ -0,0 +1,214 @@
+package upstream
+
+import (
+	"encoding/pem"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// use applies s to every transport for the duration of the test
+func use(t *testing.T, s settings) {
+	t.Helper()
+	s.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	prev := current.Swap(&s)
+	t.Cleanup(func() { current.Store(prev) })
+}
+
+// flaky answers status to the first failures requests and 200 afterwards
+func flaky(status, failures int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if int(calls.Add(1)) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write(body)
+	}))
+	return server, &calls
+}
+
+func TestTransportRetries(t *testing.T) {
+	use(t, settings{retries: 2, retryDelay: time.Millisecond, maxRetryDelay: 5 * time.Millisecond})
+
+	tests := []struct {
+		name      string
+		method    string
+		status    int
+		failures  int
+		wantCode  int
+		wantCalls int32
+	}{
+		{name: "recovers", method: http.MethodGet, status: http.StatusServiceUnavailable, failures: 2, wantCode: http.StatusOK, wantCalls: 3},
+		{name: "gives up", method: http.MethodGet, status: http.StatusBadGateway, failures: 5, wantCode: http.StatusBadGateway, wantCalls: 3},
+		{name: "replays put body", method: http.MethodPut, status: http.StatusTooManyRequests, failures: 1, wantCode: http.StatusOK, wantCalls: 2},
+		{name: "post not retried", method: http.MethodPost, status: http.StatusServiceUnavailable, failures: 1, wantCode: http.StatusServiceUnavailable, wantCalls: 1},
+		{name: "client error not retried", method: http.MethodGet, status: http.StatusNotFound, failures: 1, wantCode: http.StatusNotFound, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := flaky(tt.status, tt.failures)
+			defer server.Close()
+			client := &http.Client{Transport: Transport("retries "+tt.name, nil)}
+
+			req, _ := http.NewRequest(tt.method, server.URL, strings.NewReader("payload"))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode || calls.Load() != tt.wantCalls {
+				t.Errorf("got status %d after %d calls, want %d after %d", resp.StatusCode, calls.Load(), tt.wantCode, tt.wantCalls)
+			}
+			if resp.StatusCode == http.StatusOK && string(body) != "payload" {
+				t.Errorf("expected the body to be replayed, got %q", body)
+			}
+		})
+	}
+}
+
+func TestTransportBreaker(t *testing.T) {
+	use(t, settings{breakerFailures: 2, breakerCooldown: 50 * time.Millisecond})
+
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: Transport("breaker", nil)}
+
+	get := func() error {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("expected the failing upstream to answer, got %v", err)
+		}
+	}
+	if State("breaker") != StateOpen {
+		t.Fatalf("expected an open circuit, got %q", State("breaker"))
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) || calls.Load() != 2 {
+		t.Fatalf("expected to fail fast without a call, got %v after %d calls", err, calls.Load())
+	}
+
+	// A failed probe after the cooldown opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	if State("breaker") != StateHalfOpen {
+		t.Fatalf("expected a half-open circuit, got %q", State("breaker"))
+	}
+	if err := get(); err != nil || calls.Load() != 3 {
+		t.Fatalf("expected a probe, got %v after %d calls", err, calls.Load())
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the circuit to reopen, got %v", err)
+	}
+
+	// A successful probe closes it
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	if err := get(); err != nil {
+		t.Fatalf("expected a probe, got %v", err)
+	}
+	if State("breaker") != StateClosed {
+		t.Errorf("expected a closed circuit, got %q", State("breaker"))
+	}
+	if State("unknown") != "" {
+		t.Errorf("expected no state for an upstream without a transport")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	retryAfter := func(v string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": {v}}}
+	}
+
+	for i := 0; i < 20; i++ {
+		if wait := backoff(4*time.Second, 0, nil); wait < 2*time.Second || wait > 4*time.Second {
+			t.Fatalf("jittered wait %s outside [2s, 4s]", wait)
+		}
+	}
+	if wait := backoff(time.Second, 30*time.Second, retryAfter("7")); wait != 7*time.Second {
+		t.Errorf("expected Retry-After to apply, got %s", wait)
+	}
+	if wait := backoff(time.Second, 5*time.Second, retryAfter("60")); wait > time.Second {
+		t.Errorf("expected a Retry-After beyond the maximum to be ignored, got %s", wait)
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tls     config.TLSConfig
+		wantErr bool
+	}{
+		{name: "self-signed rejected", wantErr: true},
+		{name: "ca file", tls: config.TLSConfig{CAFile: caFile}},
+		{name: "skip verify", tls: config.TLSConfig{InsecureSkipVerify: true}},
+		{name: "missing ca file", tls: config.TLSConfig{CAFile: caFile + ".missing"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := Client("tls "+tt.name, 5*time.Second, "", tt.tls)
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientProxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.Host)
+	}))
+	defer proxy.Close()
+
+	client := Client("proxy", 5*time.Second, proxy.URL, config.TLSConfig{})
+	resp, err := client.Get("http://radarr.internal:7878/api/v3/system/status")
+	if err != nil {
+		t.Fatalf("expected the request to go through the proxy, got %v", err)
+	}
+	resp.Body.Close()
+
+	if host, _ := proxied.Load().(string); host != "radarr.internal:7878" {
+		t.Errorf("expected the proxy to receive the request for radarr.internal:7878, got %q", host)
+	}
+}
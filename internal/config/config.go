@@ -2,27 +2,59 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	"github.com/spf13/viper"
+
+	"github.com/geekxflood/program-director/pkg/models"
 )
 
 // Config holds all application configuration
 type Config struct {
-	Debug    bool           `mapstructure:"debug"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Radarr   RadarrConfig   `mapstructure:"radarr"`
-	Sonarr   SonarrConfig   `mapstructure:"sonarr"`
-	Tunarr   TunarrConfig   `mapstructure:"tunarr"`
-	Trakt    TraktConfig    `mapstructure:"trakt"`
-	Ollama   OllamaConfig   `mapstructure:"ollama"`
-	Cooldown CooldownConfig `mapstructure:"cooldown"`
-	Server   ServerConfig   `mapstructure:"server"`
-	Themes   []ThemeConfig  `mapstructure:"themes"`
+	Debug      bool             `mapstructure:"debug"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Radarr     []RadarrConfig   `mapstructure:"radarr"`
+	Sonarr     []SonarrConfig   `mapstructure:"sonarr"`
+	Tunarr     TunarrConfig     `mapstructure:"tunarr"`
+	Trakt      TraktConfig      `mapstructure:"trakt"`
+	Overseerr  OverseerrConfig  `mapstructure:"overseerr"`
+	TMDB       TMDBConfig       `mapstructure:"tmdb"`
+	Tautulli   TautulliConfig   `mapstructure:"tautulli"`
+	Ollama     OllamaConfig     `mapstructure:"ollama"`
+	LLM        LLMConfig        `mapstructure:"llm"`
+	Cooldown   CooldownConfig   `mapstructure:"cooldown"`
+	Generation GenerationConfig `mapstructure:"generation"`
+	Upstream   UpstreamConfig   `mapstructure:"upstream"`
+	Sync       SyncConfig       `mapstructure:"sync"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Notify     NotifyConfig     `mapstructure:"notifications"`
+	Alerts     AlertsConfig     `mapstructure:"alerts"`
+	History    HistoryConfig    `mapstructure:"history"`
+	Logging    LoggingConfig    `mapstructure:"logging"`
+	Lists      []ListConfig     `mapstructure:"lists"`
+	Themes     []ThemeConfig    `mapstructure:"themes"`
+
+	// ThemesDir holds more theme files, merged after Themes; relative to the config file
+	ThemesDir string `mapstructure:"themes_dir"`
+
+	// File is the config file that was read, empty when only defaults and env vars apply
+	File string `mapstructure:"-"`
+
+	themeSources []themeSource // Where each theme was defined, for error paths
 }
 
 // DatabaseConfig configures the database connection
@@ -30,6 +62,13 @@ type DatabaseConfig struct {
 	Driver   string         `mapstructure:"driver"` // postgres or sqlite
 	Postgres PostgresConfig `mapstructure:"postgres"`
 	SQLite   SQLiteConfig   `mapstructure:"sqlite"`
+
+	// QueryTimeout cancels statements running longer, in seconds (0 disables it).
+	// Migrations, maintenance, and exports are exempt.
+	QueryTimeout int `mapstructure:"query_timeout"`
+	// SlowQueryMS logs statements taking at least this many milliseconds, with their
+	// arguments redacted (0 disables it)
+	SlowQueryMS int `mapstructure:"slow_query_ms"`
 }
 
 // PostgresConfig holds PostgreSQL connection settings
@@ -40,34 +79,129 @@ type PostgresConfig struct {
 	User     string `mapstructure:"user"`
 	Password string `mapstructure:"password"`
 	SSLMode  string `mapstructure:"sslmode"`
+
+	PasswordFile string `mapstructure:"password_file"` // Read into Password when it is empty
+
+	// Connection pool; 0 max_open_conns is unlimited and 0 conn_max_lifetime (seconds) keeps
+	// connections open indefinitely
+	MaxOpenConns    int `mapstructure:"max_open_conns"`
+	MaxIdleConns    int `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime int `mapstructure:"conn_max_lifetime"`
+
+	// StatementTimeout aborts statements running longer, in seconds (0 disables it)
+	StatementTimeout int `mapstructure:"statement_timeout"`
 }
 
 // SQLiteConfig holds SQLite settings
 type SQLiteConfig struct {
 	Path string `mapstructure:"path"`
+
+	// MaintenanceSchedule is a cron expression for checkpointing the WAL and running ANALYZE
+	// in serve mode; empty disables it
+	MaintenanceSchedule string `mapstructure:"maintenance_schedule"`
+	// Vacuum also rebuilds the database file during scheduled maintenance
+	Vacuum bool `mapstructure:"vacuum"`
 }
 
-// RadarrConfig holds Radarr API settings
+// DefaultInstance names a Radarr/Sonarr instance configured without a name
+const DefaultInstance = "default"
+
+// RadarrConfig holds settings for one Radarr instance
 type RadarrConfig struct {
-	URL    string `mapstructure:"url"`
-	APIKey string `mapstructure:"api_key"`
+	Name       string    `mapstructure:"name"` // Instance name stored on synced media, e.g. 4k
+	URL        string    `mapstructure:"url"`
+	APIKey     string    `mapstructure:"api_key"`
+	APIKeyFile string    `mapstructure:"api_key_file"` // Read into APIKey when it is empty
+	Timeout    int       `mapstructure:"timeout"`      // Seconds per request, 0 for 30
+	Proxy      string    `mapstructure:"proxy"`        // Proxy URL; empty uses HTTP(S)_PROXY
+	TLS        TLSConfig `mapstructure:"tls"`
 }
 
-// SonarrConfig holds Sonarr API settings
+// SonarrConfig holds settings for one Sonarr instance
 type SonarrConfig struct {
-	URL    string `mapstructure:"url"`
-	APIKey string `mapstructure:"api_key"`
+	Name       string    `mapstructure:"name"` // Instance name stored on synced media, e.g. 4k
+	URL        string    `mapstructure:"url"`
+	APIKey     string    `mapstructure:"api_key"`
+	APIKeyFile string    `mapstructure:"api_key_file"` // Read into APIKey when it is empty
+	Timeout    int       `mapstructure:"timeout"`      // Seconds per request, 0 for 30
+	Proxy      string    `mapstructure:"proxy"`        // Proxy URL; empty uses HTTP(S)_PROXY
+	TLS        TLSConfig `mapstructure:"tls"`
 }
 
 // TunarrConfig holds Tunarr API settings
 type TunarrConfig struct {
-	URL string `mapstructure:"url"`
+	URL     string    `mapstructure:"url"`
+	Timeout int       `mapstructure:"timeout"` // Seconds per request, 0 for 30
+	Proxy   string    `mapstructure:"proxy"`   // Proxy URL; empty uses HTTP(S)_PROXY
+	TLS     TLSConfig `mapstructure:"tls"`
+
+	// PublicURL is Tunarr as players reach it, used for the stream URLs of
+	// /api/v1/channels.m3u when URL is only reachable in-cluster; defaults to URL
+	PublicURL string `mapstructure:"public_url"`
+}
+
+// TLSConfig holds the TLS options of an upstream, such as one behind a reverse proxy with a
+// self-signed certificate
+type TLSConfig struct {
+	// CAFile is a PEM bundle of certificates trusted on top of the system ones
+	CAFile string `mapstructure:"ca_file"`
+	// InsecureSkipVerify accepts any certificate; prefer CAFile
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+}
+
+// ClientConfig returns the TLS client config of t, or nil when t changes nothing
+func (t TLSConfig) ClientConfig() (*tls.Config, error) {
+	if t == (TLSConfig{}) {
+		return nil, nil
+	}
+
+	clientTLS := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates in CA file %s", t.CAFile)
+		}
+		clientTLS.RootCAs = pool
+	}
+	return clientTLS, nil
 }
 
 // TraktConfig holds Trakt.tv API settings
 type TraktConfig struct {
-	ClientID     string `mapstructure:"client_id"`
-	ClientSecret string `mapstructure:"client_secret"`
+	ClientID         string `mapstructure:"client_id"`
+	ClientSecret     string `mapstructure:"client_secret"`
+	ClientSecretFile string `mapstructure:"client_secret_file"` // Read into ClientSecret when it is empty
+}
+
+// OverseerrConfig holds Overseerr/Jellyseerr API settings
+type OverseerrConfig struct {
+	URL        string `mapstructure:"url"`
+	APIKey     string `mapstructure:"api_key"`
+	APIKeyFile string `mapstructure:"api_key_file"` // Read into APIKey when it is empty
+}
+
+// TMDBConfig holds TMDB API settings used to enrich synced media
+type TMDBConfig struct {
+	APIKey     string `mapstructure:"api_key"`      // v3 API key or v4 read access token
+	APIKeyFile string `mapstructure:"api_key_file"` // Read into APIKey when it is empty
+	Region     string `mapstructure:"region"`       // ISO 3166-1 country used for certifications
+}
+
+// TautulliConfig holds Tautulli API settings used to import watch history
+type TautulliConfig struct {
+	URL        string `mapstructure:"url"`
+	APIKey     string `mapstructure:"api_key"`
+	APIKeyFile string `mapstructure:"api_key_file"` // Read into APIKey when it is empty
 }
 
 // OllamaConfig holds Ollama LLM settings
@@ -76,6 +210,50 @@ type OllamaConfig struct {
 	Model       string  `mapstructure:"model"`
 	Temperature float64 `mapstructure:"temperature"`
 	NumCtx      int     `mapstructure:"num_ctx"`
+
+	// MaxConcurrent caps simultaneous requests to this Ollama server, 0 for no limit
+	MaxConcurrent int `mapstructure:"max_concurrent"`
+
+	Proxy string    `mapstructure:"proxy"` // Proxy URL; empty uses HTTP(S)_PROXY
+	TLS   TLSConfig `mapstructure:"tls"`
+
+	// Timeout bounds each request in seconds. Chats that time out or fail transiently are
+	// retried Retries times, waiting RetryDelay seconds before the first retry and doubling
+	// the wait after each one.
+	Timeout    int `mapstructure:"timeout"`
+	Retries    int `mapstructure:"retries"`
+	RetryDelay int `mapstructure:"retry_delay"`
+
+	// KeepAlive is how long Ollama keeps the model loaded after a request, e.g. "30m", or
+	// -1 to keep it loaded; empty uses the server's default of 5 minutes
+	KeepAlive string `mapstructure:"keep_alive"`
+	// WarmUp loads the model when serve starts, so the first generation doesn't wait for it
+	WarmUp bool `mapstructure:"warm_up"`
+	// AutoPull pulls the model when serve starts and the server doesn't have it
+	AutoPull bool `mapstructure:"auto_pull"`
+}
+
+// LLMConfig controls how the LLM is used for scoring and ordering
+type LLMConfig struct {
+	// Enabled turns the LLM steps on; without them generation uses genre/keyword scores only
+	Enabled bool `mapstructure:"enabled"`
+	// FallbackModels are tried in order on the same Ollama server when ollama.model fails,
+	// before falling back to genre/keyword scores
+	FallbackModels []string `mapstructure:"fallback_models"`
+	// Prompts replace the built-in refinement prompts for every theme without its own
+	Prompts PromptConfig `mapstructure:"prompts"`
+	// Traces records the exact prompts and raw responses of generations
+	Traces LLMTracesConfig `mapstructure:"traces"`
+}
+
+// LLMTracesConfig holds the settings of recording LLM prompts and responses
+type LLMTracesConfig struct {
+	// Enabled stores the prompt and response of every Ollama call of a generation in
+	// llm_traces. Prompts include candidate titles, so traces grow quickly.
+	Enabled bool `mapstructure:"enabled"`
+	// RetentionDays deletes traces older than this many days after each generation; 0 keeps
+	// them forever
+	RetentionDays int `mapstructure:"retention_days"`
 }
 
 // CooldownConfig holds media cooldown settings
@@ -83,6 +261,105 @@ type CooldownConfig struct {
 	MovieDays  int `mapstructure:"movie_days"`
 	SeriesDays int `mapstructure:"series_days"`
 	AnimeDays  int `mapstructure:"anime_days"`
+
+	// Household watch history (requires tautulli)
+	WatchedDays    int     `mapstructure:"watched_days"`    // Window in which watched titles are avoided, 0 disables
+	WatchedPenalty float64 `mapstructure:"watched_penalty"` // 0 excludes watched titles, >0 subtracts from their score
+}
+
+// GenerationConfig holds playlist generation settings
+type GenerationConfig struct {
+	// Concurrency is how many themes GenerateAll works on at once. Themes sharing a channel
+	// always run one after another.
+	Concurrency int `mapstructure:"concurrency"`
+
+	// Retries of a theme after a transient Tunarr/Ollama failure, waiting RetryDelay seconds
+	// before the first retry and doubling the wait after each one
+	Retries    int `mapstructure:"retries"`
+	RetryDelay int `mapstructure:"retry_delay"`
+}
+
+// UpstreamConfig holds the retries and circuit breaker shared by the HTTP clients of Radarr,
+// Sonarr, Tunarr, Ollama, and the other upstreams
+type UpstreamConfig struct {
+	// Retries of idempotent requests after a network error or a 429, 502, 503, or 504
+	// answer, waiting about RetryDelay seconds before the first retry and doubling the wait
+	// after each one, up to MaxRetryDelay seconds
+	Retries       int `mapstructure:"retries"`
+	RetryDelay    int `mapstructure:"retry_delay"`
+	MaxRetryDelay int `mapstructure:"max_retry_delay"`
+
+	// BreakerFailures consecutive failed requests to an upstream open its circuit: requests
+	// fail at once for BreakerCooldown seconds, then one is let through to probe it. 0
+	// disables the breaker.
+	BreakerFailures int `mapstructure:"breaker_failures"`
+	BreakerCooldown int `mapstructure:"breaker_cooldown"`
+}
+
+// SyncConfig holds scheduled library sync settings for serve mode
+type SyncConfig struct {
+	// Schedule is a cron expression for syncing from Radarr/Sonarr, then configured lists and
+	// Tautulli watch history; empty disables scheduled sync
+	Schedule string `mapstructure:"schedule"`
+	// Cleanup removes media no longer in Radarr/Sonarr after each scheduled sync
+	Cleanup bool `mapstructure:"cleanup"`
+
+	// AnimeDetection decides which Sonarr series are stored as anime: "heuristic" (default;
+	// Sonarr's anime series type or anime genres), "series_type" (only Sonarr's series type),
+	// or "mapping" (also TVDB IDs found in the AniDB/AniList ID mapping at AnimeMappingURL)
+	AnimeDetection  string `mapstructure:"anime_detection"`
+	AnimeMappingURL string `mapstructure:"anime_mapping_url"` // Fribb/anime-lists when empty
+}
+
+// HistoryConfig holds play history retention settings
+type HistoryConfig struct {
+	// RetentionDays prunes play history older than this many days; 0 keeps it forever
+	RetentionDays int `mapstructure:"retention_days"`
+	// PruneSchedule is a cron expression for pruning in serve mode
+	PruneSchedule string `mapstructure:"prune_schedule"`
+}
+
+// LoggingConfig holds log format, level, and destination settings
+type LoggingConfig struct {
+	Format string `mapstructure:"format"` // text or json; --json forces json
+	Level  string `mapstructure:"level"`  // debug, info, warn, or error; --debug forces debug
+
+	// File also writes logs to this path, rotated once it reaches MaxSizeMB megabytes (0
+	// never rotates) keeping MaxBackups old files
+	File       string `mapstructure:"file"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+
+	// Levels overrides Level for the components of LogComponents, such as ollama: debug to
+	// see LLM prompts and responses without debug logs from the rest
+	Levels map[string]string `mapstructure:"levels"`
+}
+
+// LogComponents are the components whose level logging.levels may set
+var LogComponents = []string{
+	"database", "sync", "similarity", "ollama", "generation", "cooldown", "scheduler", "server", "notify", "upstream",
+}
+
+// SlogLevel returns the slog level of Level, info when it is empty
+func (l LoggingConfig) SlogLevel() (slog.Level, error) {
+	return parseLevel(l.Level)
+}
+
+// ComponentLevel returns the slog level of component, from Levels or else Level
+func (l LoggingConfig) ComponentLevel(component string) (slog.Level, error) {
+	if level, ok := l.Levels[component]; ok {
+		return parseLevel(level)
+	}
+	return l.SlogLevel()
+}
+
+func parseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	err := level.UnmarshalText([]byte(s))
+	return level, err
 }
 
 // ServerConfig holds HTTP server settings
@@ -91,6 +368,103 @@ type ServerConfig struct {
 	EnableScheduler bool `mapstructure:"enable_scheduler"`
 	MetricsEnabled  bool `mapstructure:"metrics_enabled"`
 	ShutdownTimeout int  `mapstructure:"shutdown_timeout"`
+
+	// APIKeys are static keys accepted by /api/v1 routes. The API stays open while neither
+	// these nor keys created with `apikey create` exist.
+	APIKeys []string `mapstructure:"api_keys"`
+
+	// RateLimit caps /api/v1 requests per client IP per minute, with bursts of up to
+	// RateBurst requests; 0 disables rate limiting
+	RateLimit int `mapstructure:"rate_limit"`
+	RateBurst int `mapstructure:"rate_burst"`
+
+	// ReadOnly answers 403 to every /api/v1 request but GET and HEAD, disabling syncs,
+	// generations, and webhooks while the dashboard, metrics, and reads stay available
+	ReadOnly bool `mapstructure:"read_only"`
+
+	// CompressMinSize is the size in bytes from which responses are compressed with gzip or
+	// deflate for clients that accept it; 0 disables compression
+	CompressMinSize int `mapstructure:"compress_min_size"`
+
+	// Webhooks verifies the requests of each /api/v1/webhooks/{source} route
+	Webhooks WebhooksConfig `mapstructure:"webhooks"`
+}
+
+// WebhooksConfig holds the verification of each webhook source
+type WebhooksConfig struct {
+	Radarr WebhookConfig `mapstructure:"radarr"`
+	Sonarr WebhookConfig `mapstructure:"sonarr"`
+	Tunarr WebhookConfig `mapstructure:"tunarr"`
+}
+
+// WebhookSources are the services with a /api/v1/webhooks/{source} route
+var WebhookSources = []string{"radarr", "sonarr", "tunarr"}
+
+// Source returns the settings of the named webhook source
+func (c *WebhooksConfig) Source(name string) (WebhookConfig, bool) {
+	switch name {
+	case "radarr":
+		return c.Radarr, true
+	case "sonarr":
+		return c.Sonarr, true
+	case "tunarr":
+		return c.Tunarr, true
+	}
+	return WebhookConfig{}, false
+}
+
+// WebhookConfig verifies a webhook source's requests with a shared secret or an HMAC
+// signature of the body. A source with a secret is authenticated by it instead of an API key.
+type WebhookConfig struct {
+	Secret     string `mapstructure:"secret"`
+	SecretFile string `mapstructure:"secret_file"`
+	// Verify is "secret" (the secret is sent as the X-Webhook-Secret header, the secret query
+	// parameter, or the basic auth password) or "hmac" (X-Webhook-Signature: sha256=<hex>)
+	Verify string `mapstructure:"verify"`
+}
+
+// NotifyConfig holds the targets that receive summaries after generation and sync runs
+type NotifyConfig struct {
+	Targets []NotifyTarget `mapstructure:"targets"`
+
+	// LowCandidates flags generated themes with fewer items than this; 0 flags themes that
+	// could not fill their max_items
+	LowCandidates int `mapstructure:"low_candidates"`
+}
+
+// NotifyTarget is one place summaries are posted to
+type NotifyTarget struct {
+	Name string `mapstructure:"name"` // Shown in logs, defaults to the type
+	Type string `mapstructure:"type"` // discord, slack, webhook, ntfy, or gotify
+	URL  string `mapstructure:"url"`  // Webhook URL, ntfy topic URL, or Gotify server URL
+
+	// Token is the ntfy access token, Gotify application token, or a webhook's bearer token
+	Token     string `mapstructure:"token"`
+	TokenFile string `mapstructure:"token_file"`
+
+	// Events limits the target to "generation" or "sync" runs, or "alert"s; empty means all
+	Events []string `mapstructure:"events"`
+	// OnlyFailures sends run summaries only when a theme or sync failed; alerts are always sent
+	OnlyFailures bool `mapstructure:"only_failures"`
+}
+
+// AlertsConfig controls alerts on failures that repeat, sent to the notification targets
+// and listed by /api/v1/status
+type AlertsConfig struct {
+	// ThemeFailures alerts when this many runs of a theme failed in a row (0 disables)
+	ThemeFailures int `mapstructure:"theme_failures"`
+	// UpstreamFailures alerts when this many health checks of an upstream such as Tunarr
+	// failed in a row (0 disables)
+	UpstreamFailures int `mapstructure:"upstream_failures"`
+	// CheckInterval is how often serve checks the upstreams, in seconds
+	CheckInterval int `mapstructure:"check_interval"`
+}
+
+// ListConfig defines a static title list imported by the list sync
+type ListConfig struct {
+	Name   string `mapstructure:"name"`
+	Source string `mapstructure:"source"` // mdblist or imdb
+	URL    string `mapstructure:"url"`    // List URL; IMDb exports may also be a local CSV path
 }
 
 // ThemeConfig defines a playlist theme
@@ -105,8 +479,96 @@ type ThemeConfig struct {
 	MinRating   float64  `mapstructure:"min_rating"`
 	MaxItems    int      `mapstructure:"max_items"`
 	Duration    int      `mapstructure:"duration"` // Target duration in minutes
+
+	// MinResolution picks only files of at least this many lines, e.g. 2160 for UHD.
+	// Media of unknown resolution (including series) is skipped.
+	MinResolution int `mapstructure:"min_resolution"`
+
+	// Content rating bounds such as "PG" or "TV-14"; unrated media is skipped when either is set
+	MinContentRating string `mapstructure:"min_content_rating"`
+	MaxContentRating string `mapstructure:"max_content_rating"`
+
+	// Release year bounds, and decades such as "1980s"; decades are combined with the bounds
+	MinYear int      `mapstructure:"min_year"`
+	MaxYear int      `mapstructure:"max_year"`
+	Decades []string `mapstructure:"decades"`
+
+	// Runtime bounds in minutes (episode runtime for series); unknown runtimes fail min_runtime
+	MinRuntime int `mapstructure:"min_runtime"`
+	MaxRuntime int `mapstructure:"max_runtime"`
+
+	// Selection picks candidates by "top" score (default) or "weighted_random", which samples
+	// in proportion to score^(1/selection_temperature) for variety between runs
+	Selection            string  `mapstructure:"selection"`
+	SelectionTemperature float64 `mapstructure:"selection_temperature"`
+
+	// PreferNewDays boosts media imported into Radarr/Sonarr within this many days
+	PreferNewDays int `mapstructure:"prefer_new_days"`
+
+	// Pinned titles or media IDs that always lead the playlist, regardless of score or cooldown
+	Pinned []string `mapstructure:"pinned"`
+
+	// Content kept off the channel; genres and titles are matched case-insensitively
+	ExcludeGenres   []string `mapstructure:"exclude_genres"`
+	ExcludeTitles   []string `mapstructure:"exclude_titles"`
+	ExcludeMediaIDs []int64  `mapstructure:"exclude_media_ids"`
+
+	// Original language (ISO 639-1, e.g. "ko") and origin country (ISO 3166-1, e.g. "KR") filters
+	Languages []string `mapstructure:"languages"`
+	Countries []string `mapstructure:"countries"`
+
+	// Watershed keeps mature titles out of the channel's quiet hours
+	Watershed *WatershedConfig `mapstructure:"watershed"`
+
+	// Request-driven programming (requires overseerr)
+	IncludeRequested bool `mapstructure:"include_requested"`
+	RequestedDays    int  `mapstructure:"requested_days"` // Only consider requests from the last N days
+
+	// TraktList restricts candidates to a public Trakt list ("user/list-slug" or URL)
+	TraktList string `mapstructure:"trakt_list"`
+
+	// Imported lists (by name) that candidates must appear on, or must not appear on
+	IncludeLists []string `mapstructure:"include_lists"`
+	ExcludeLists []string `mapstructure:"exclude_lists"`
+
+	// Radarr/Sonarr tags; candidates need at least one of Tags and none of ExcludeTags
+	Tags        []string `mapstructure:"tags"`
+	ExcludeTags []string `mapstructure:"exclude_tags"`
+
+	// Collections keeps Radarr collections together in release order: "group" pulls in the
+	// rest of a picked collection, "prioritize" also ranks collection members higher
+	Collections string `mapstructure:"collections"`
+
+	// OrderBy is the lineup play order: "score" (default), "random", "chronological",
+	// "release" (franchises in release order), "narrative" (ordered by the LLM), or
+	// "double_feature" (paired into double features by the LLM)
+	OrderBy string `mapstructure:"order_by"`
+
+	// DaysOfWeek limits scheduled and all-theme generation to these days, e.g. ["saturday"].
+	// Empty means every day.
+	DaysOfWeek []string `mapstructure:"days_of_week"`
+
+	// SkipUnchanged leaves the channel alone when the proposed lineup matches the last applied
+	// one, or when less than MinChange (fraction 0-1) of it differs from the current lineup
+	SkipUnchanged bool    `mapstructure:"skip_unchanged"`
+	MinChange     float64 `mapstructure:"min_change"`
+
+	// Prompts replace llm.prompts, or the built-in prompts, for this theme's LLM refinement.
+	// Relative files are resolved against the file defining the theme.
+	Prompts PromptConfig `mapstructure:"prompts"`
 }
 
+// WatershedConfig defines quiet hours during which only family-safe titles may air.
+// Times are "HH:MM" in local time; a window may wrap past midnight.
+type WatershedConfig struct {
+	Start            string `mapstructure:"start"`              // e.g. "06:00"
+	End              string `mapstructure:"end"`                // e.g. "21:00"
+	MaxContentRating string `mapstructure:"max_content_rating"` // Highest rating allowed during quiet hours, default PG
+}
+
+// DefaultWatershedRating is the highest content rating allowed during quiet hours by default
+const DefaultWatershedRating = "PG"
+
 // Load reads configuration from file and environment variables
 func Load(configFile string) (*Config, error) {
 	v := viper.New()
@@ -132,12 +594,13 @@ func Load(configFile string) (*Config, error) {
 	}
 
 	// Read config file
+	// A missing config file is okay, we'll use defaults and env vars; a file that cannot be
+	// read or parsed is not, so a half-saved file is never loaded as an empty config
 	if err := v.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
-		if errors.As(err, &configFileNotFoundError) {
+		if !errors.As(err, &configFileNotFoundError) && !errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
-		// Config file not found is okay, we'll use defaults and env vars
 	}
 
 	// Environment variable overrides
@@ -145,16 +608,54 @@ func Load(configFile string) (*Config, error) {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
-	// Map specific environment variables
+	// Map specific environment variables, and their _FILE variants
 	bindEnvVars(v)
+	if err := applyEnvFiles(v); err != nil {
+		return nil, err
+	}
 
+	// Unknown keys and type mismatches are collected with the validation errors
+	ve := &ValidationError{}
 	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("error unmarshaling config: %w", err)
+	if err := v.Unmarshal(&cfg, strictDecoding); err != nil {
+		fieldErrs, ok := decodeErrors(err)
+		if !ok {
+			return nil, fmt.Errorf("error unmarshaling config: %w", err)
+		}
+		ve.Errors = append(ve.Errors, fieldErrs...)
+	}
+
+	cfg.File = v.ConfigFileUsed()
+
+	// Radarr/Sonarr instances are lists, so their env vars are applied by hand
+	radarr, err := applyInstanceEnv(toInstances(cfg.Radarr), "RADARR", defaultRadarrURL)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Radarr = fromInstances[RadarrConfig](radarr)
+	sonarr, err := applyInstanceEnv(toInstances(cfg.Sonarr), "SONARR", defaultSonarrURL)
+	if err != nil {
+		return nil, err
 	}
+	cfg.Sonarr = fromInstances[SonarrConfig](sonarr)
+
+	if err := cfg.readSecretFiles(); err != nil {
+		return nil, err
+	}
+
+	if dir := cfg.ThemesPath(); dir != "" {
+		if err := loadThemesDir(&cfg, dir, ve); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.resolvePromptPaths()
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
+		ve.merge(err)
+	}
+	if err := ve.err(); err != nil {
 		return nil, fmt.Errorf("config validation error: %w", err)
 	}
 
@@ -165,132 +666,658 @@ func Load(configFile string) (*Config, error) {
 func setDefaults(v *viper.Viper) {
 	// Database defaults
 	v.SetDefault("database.driver", "sqlite")
+	v.SetDefault("database.query_timeout", 30)
+	v.SetDefault("database.slow_query_ms", 1000)
 	v.SetDefault("database.postgres.host", "localhost")
 	v.SetDefault("database.postgres.port", 5432)
 	v.SetDefault("database.postgres.database", "program_director")
 	v.SetDefault("database.postgres.sslmode", "disable")
+	v.SetDefault("database.postgres.max_open_conns", 25)
+	v.SetDefault("database.postgres.max_idle_conns", 5)
+	v.SetDefault("database.postgres.conn_max_lifetime", 0)
+	v.SetDefault("database.postgres.statement_timeout", 0)
+	v.SetDefault("database.sqlite.maintenance_schedule", "30 4 * * 0")
+	v.SetDefault("database.sqlite.vacuum", false)
 	v.SetDefault("database.sqlite.path", "./data/program-director.db")
 
-	// Radarr defaults
-	v.SetDefault("radarr.url", "http://radarr:7878")
-
-	// Sonarr defaults
-	v.SetDefault("sonarr.url", "http://sonarr:8989")
+	// Radarr and Sonarr are lists of instances, see applyInstanceEnv for their defaults
 
 	// Tunarr defaults
 	v.SetDefault("tunarr.url", "http://tunarr:8000")
 
 	// Trakt defaults (optional, no defaults needed)
 
+	// Overseerr defaults (optional, no defaults needed)
+
+	// TMDB defaults (optional, enrichment is skipped without an API key)
+	v.SetDefault("tmdb.region", "US")
+
 	// Ollama defaults
 	v.SetDefault("ollama.url", "http://ollama:11434")
 	v.SetDefault("ollama.model", "dolphin-llama3:8b")
 	v.SetDefault("ollama.temperature", 0.7)
 	v.SetDefault("ollama.num_ctx", 8192)
+	v.SetDefault("ollama.max_concurrent", 1)
+	v.SetDefault("ollama.timeout", 300)
+	v.SetDefault("ollama.retries", 2)
+	v.SetDefault("ollama.retry_delay", 2)
+	v.SetDefault("ollama.keep_alive", "")
+	v.SetDefault("ollama.warm_up", true)
+	v.SetDefault("ollama.auto_pull", false)
+
+	// LLM defaults
+	v.SetDefault("llm.enabled", true)
+	v.SetDefault("llm.fallback_models", []string{})
+	v.SetDefault("llm.prompts.system", "")
+	v.SetDefault("llm.prompts.user", "")
+	v.SetDefault("llm.traces.enabled", false)
+	v.SetDefault("llm.traces.retention_days", 7)
 
 	// Cooldown defaults
 	v.SetDefault("cooldown.movie_days", 30)
 	v.SetDefault("cooldown.series_days", 14)
 	v.SetDefault("cooldown.anime_days", 14)
+	v.SetDefault("cooldown.watched_days", 60)
+	v.SetDefault("cooldown.watched_penalty", 0)
+
+	// Generation defaults
+	v.SetDefault("generation.concurrency", 1)
+	v.SetDefault("generation.retries", 2)
+	v.SetDefault("generation.retry_delay", 10)
+	v.SetDefault("upstream.retries", 2)
+	v.SetDefault("upstream.retry_delay", 1)
+	v.SetDefault("upstream.max_retry_delay", 30)
+	v.SetDefault("upstream.breaker_failures", 5)
+	v.SetDefault("upstream.breaker_cooldown", 30)
+
+	// Sync defaults
+	v.SetDefault("sync.schedule", "")
+	v.SetDefault("sync.cleanup", false)
+	v.SetDefault("sync.anime_detection", "heuristic")
+	v.SetDefault("sync.anime_mapping_url", "")
 
 	// Server defaults
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.enable_scheduler", false)
 	v.SetDefault("server.metrics_enabled", true)
 	v.SetDefault("server.shutdown_timeout", 30)
+	v.SetDefault("server.rate_limit", 120)
+	v.SetDefault("server.rate_burst", 30)
+	v.SetDefault("server.read_only", false)
+	v.SetDefault("server.compress_min_size", 1024)
+	for _, source := range WebhookSources {
+		v.SetDefault("server.webhooks."+source+".secret", "")
+		v.SetDefault("server.webhooks."+source+".secret_file", "")
+		v.SetDefault("server.webhooks."+source+".verify", "secret")
+	}
+
+	// Notification defaults
+	v.SetDefault("notifications.targets", []NotifyTarget{})
+	v.SetDefault("notifications.low_candidates", 0)
+
+	// Alert defaults
+	v.SetDefault("alerts.theme_failures", 3)
+	v.SetDefault("alerts.upstream_failures", 3)
+	v.SetDefault("alerts.check_interval", 300)
+
+	// History defaults
+	v.SetDefault("history.retention_days", 0)
+	v.SetDefault("history.prune_schedule", "0 4 * * *")
+
+	// Logging defaults
+	v.SetDefault("logging.format", "text")
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.file", "")
+	v.SetDefault("logging.max_size_mb", 100)
+	v.SetDefault("logging.max_backups", 3)
+}
+
+// Default URLs for an instance configured only through environment variables
+const (
+	defaultRadarrURL = "http://radarr:7878"
+	defaultSonarrURL = "http://sonarr:8989"
+)
+
+// instance holds the fields shared by RadarrConfig and SonarrConfig
+type instance struct {
+	Name       string
+	URL        string
+	APIKey     string
+	APIKeyFile string
+	Timeout    int
+	Proxy      string
+	TLS        TLSConfig
+}
+
+// toInstances converts Radarr/Sonarr instance configs to their shared form
+func toInstances[T RadarrConfig | SonarrConfig](configs []T) []instance {
+	out := make([]instance, len(configs))
+	for i, c := range configs {
+		out[i] = instance(c)
+	}
+	return out
+}
+
+// fromInstances converts instances back to Radarr/Sonarr instance configs
+func fromInstances[T RadarrConfig | SonarrConfig](instances []instance) []T {
+	out := make([]T, len(instances))
+	for i, inst := range instances {
+		out[i] = T(inst)
+	}
+	return out
+}
+
+// applyInstanceEnv applies the <PREFIX>_URL and <PREFIX>_API_KEY (or <PREFIX>_API_KEY_FILE)
+// environment variables to the first instance, creating it when none is configured, and
+// names an unnamed single instance DefaultInstance
+func applyInstanceEnv(instances []instance, prefix, defaultURL string) ([]instance, error) {
+	envURL := os.Getenv(prefix + "_URL")
+	envKey, err := getenvOrFile(prefix + "_API_KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(instances) == 0 {
+		if envURL == "" && envKey == "" {
+			return instances, nil
+		}
+		instances = append(instances, instance{URL: defaultURL})
+	}
+
+	if envURL != "" {
+		instances[0].URL = envURL
+	}
+	if envKey != "" {
+		instances[0].APIKey = envKey
+	}
+	if len(instances) == 1 && instances[0].Name == "" {
+		instances[0].Name = DefaultInstance
+	}
+
+	return instances, nil
 }
 
 // bindEnvVars maps environment variables to config keys
 func bindEnvVars(v *viper.Viper) {
-	// Direct environment variable mappings
-	bindings := []struct {
-		key string
-		env string
-	}{
-		{"radarr.api_key", "RADARR_API_KEY"},
-		{"sonarr.api_key", "SONARR_API_KEY"},
-		{"radarr.url", "RADARR_URL"},
-		{"sonarr.url", "SONARR_URL"},
-		{"tunarr.url", "TUNARR_URL"},
-		{"trakt.client_id", "TRAKT_CLIENT_ID"},
-		{"trakt.client_secret", "TRAKT_CLIENT_SECRET"},
-		{"ollama.url", "OLLAMA_URL"},
-		{"ollama.model", "OLLAMA_MODEL"},
-		{"database.driver", "DB_DRIVER"},
-		{"database.postgres.host", "POSTGRES_HOST"},
-		{"database.postgres.port", "POSTGRES_PORT"},
-		{"database.postgres.database", "POSTGRES_DATABASE"},
-		{"database.postgres.user", "POSTGRES_USER"},
-		{"database.postgres.password", "POSTGRES_PASSWORD"},
-	}
-
-	for _, b := range bindings {
+	for _, b := range envBindings {
 		if err := v.BindEnv(b.key, b.env); err != nil {
 			panic(fmt.Sprintf("failed to bind env var %s: %v", b.env, err))
 		}
 	}
 }
 
-// Validate checks if the configuration is valid
+// envBindings are the direct environment variable mappings
+var envBindings = []struct {
+	key string
+	env string
+}{
+	{"server.api_keys", "API_KEYS"},
+	{"themes_dir", "THEMES_DIR"},
+	{"tunarr.url", "TUNARR_URL"},
+	{"tunarr.public_url", "TUNARR_PUBLIC_URL"},
+	{"trakt.client_id", "TRAKT_CLIENT_ID"},
+	{"trakt.client_secret", "TRAKT_CLIENT_SECRET"},
+	{"overseerr.url", "OVERSEERR_URL"},
+	{"overseerr.api_key", "OVERSEERR_API_KEY"},
+	{"tmdb.api_key", "TMDB_API_KEY"},
+	{"tautulli.url", "TAUTULLI_URL"},
+	{"tautulli.api_key", "TAUTULLI_API_KEY"},
+	{"ollama.url", "OLLAMA_URL"},
+	{"ollama.model", "OLLAMA_MODEL"},
+	{"database.driver", "DB_DRIVER"},
+	{"database.postgres.host", "POSTGRES_HOST"},
+	{"database.postgres.port", "POSTGRES_PORT"},
+	{"database.postgres.database", "POSTGRES_DATABASE"},
+	{"database.postgres.user", "POSTGRES_USER"},
+	{"database.postgres.password", "POSTGRES_PASSWORD"},
+	{"logging.format", "LOG_FORMAT"},
+	{"logging.level", "LOG_LEVEL"},
+	{"logging.file", "LOG_FILE"},
+}
+
+// Validate checks if the configuration is valid. Every problem is reported, each with the
+// YAML path of its setting, in a *ValidationError.
 func (c *Config) Validate() error {
+	ve := &ValidationError{}
+
 	// Validate database config
 	switch c.Database.Driver {
 	case "postgres":
 		if c.Database.Postgres.Host == "" {
-			return errors.New("postgres host is required")
+			ve.add("database.postgres.host", "postgres host is required")
+		}
+		pg := c.Database.Postgres
+		if pg.MaxOpenConns < 0 || pg.MaxIdleConns < 0 || pg.ConnMaxLifetime < 0 || pg.StatementTimeout < 0 {
+			ve.add("database.postgres", "postgres max_open_conns, max_idle_conns, conn_max_lifetime, and statement_timeout must not be negative")
 		}
 	case "sqlite":
 		// SQLite path can be empty (use default)
+		if s := c.Database.SQLite.MaintenanceSchedule; s != "" {
+			if _, err := cron.ParseStandard(s); err != nil {
+				ve.add("database.sqlite.maintenance_schedule", "invalid sqlite maintenance_schedule %q: %v", s, err)
+			}
+		}
 	default:
-		return fmt.Errorf("invalid database driver: %s (must be postgres or sqlite)", c.Database.Driver)
+		ve.add("database.driver", "invalid database driver: %s (must be postgres or sqlite)", c.Database.Driver)
 	}
-
-	// Validate Radarr config
-	if c.Radarr.URL == "" {
-		return errors.New("radarr URL is required")
+	if c.Database.QueryTimeout < 0 {
+		ve.add("database.query_timeout", "database query_timeout must not be negative")
 	}
-	if c.Radarr.APIKey == "" {
-		return errors.New("radarr API key is required")
+	if c.Database.SlowQueryMS < 0 {
+		ve.add("database.slow_query_ms", "database slow_query_ms must not be negative")
 	}
 
-	// Validate Sonarr config
-	if c.Sonarr.URL == "" {
-		return errors.New("sonarr URL is required")
-	}
-	if c.Sonarr.APIKey == "" {
-		return errors.New("sonarr API key is required")
-	}
+	// Validate Radarr and Sonarr instances
+	validateInstances(ve, "radarr", toInstances(c.Radarr))
+	validateInstances(ve, "sonarr", toInstances(c.Sonarr))
 
 	// Validate Tunarr config
 	if c.Tunarr.URL == "" {
-		return errors.New("tunarr URL is required")
+		ve.add("tunarr.url", "tunarr URL is required")
+	}
+	if c.Tunarr.Timeout < 0 {
+		ve.add("tunarr.timeout", "tunarr timeout must not be negative")
 	}
+	validateProxy(ve, "tunarr.proxy", "tunarr", c.Tunarr.Proxy)
+	validateTLS(ve, "tunarr.tls", "tunarr", c.Tunarr.TLS)
 
 	// Validate Ollama config
 	if c.Ollama.URL == "" {
-		return errors.New("ollama URL is required")
+		ve.add("ollama.url", "ollama URL is required")
 	}
 	if c.Ollama.Model == "" {
-		return errors.New("ollama model is required")
+		ve.add("ollama.model", "ollama model is required")
+	}
+	if c.Ollama.MaxConcurrent < 0 {
+		ve.add("ollama.max_concurrent", "ollama max_concurrent must not be negative")
+	}
+	validateProxy(ve, "ollama.proxy", "ollama", c.Ollama.Proxy)
+	validateTLS(ve, "ollama.tls", "ollama", c.Ollama.TLS)
+	if c.Ollama.Timeout < 0 || c.Ollama.Retries < 0 || c.Ollama.RetryDelay < 0 {
+		ve.add("ollama.retries", "ollama timeout, retries, and retry_delay must not be negative")
+	}
+	if c.Ollama.KeepAlive != "" {
+		if _, err := strconv.Atoi(c.Ollama.KeepAlive); err != nil {
+			if _, err := time.ParseDuration(c.Ollama.KeepAlive); err != nil {
+				ve.add("ollama.keep_alive", "invalid ollama keep_alive %q (e.g. 30m, or -1 to keep the model loaded)", c.Ollama.KeepAlive)
+			}
+		}
+	}
+
+	c.LLM.Prompts.validate(ve, "llm.prompts")
+	if c.LLM.Traces.RetentionDays < 0 {
+		ve.add("llm.traces.retention_days", "llm traces retention_days must not be negative")
+	}
+
+	if c.Cooldown.WatchedPenalty < 0 {
+		ve.add("cooldown.watched_penalty", "cooldown watched_penalty must not be negative")
+	}
+
+	if c.Server.RateLimit < 0 || c.Server.RateBurst < 0 {
+		ve.add("server.rate_limit", "server rate_limit and rate_burst must not be negative")
+	}
+	if c.Server.CompressMinSize < 0 {
+		ve.add("server.compress_min_size", "server compress_min_size must not be negative")
+	}
+	for _, source := range WebhookSources {
+		switch wh, _ := c.Server.Webhooks.Source(source); wh.Verify {
+		case "", "secret", "hmac":
+		default:
+			ve.add("server.webhooks."+source+".verify", "invalid webhook verify %q (must be secret or hmac)", wh.Verify)
+		}
+	}
+
+	if c.Generation.Concurrency < 0 {
+		ve.add("generation.concurrency", "generation concurrency must not be negative")
+	}
+	if c.Generation.Retries < 0 || c.Generation.RetryDelay < 0 {
+		ve.add("generation.retries", "generation retries and retry_delay must not be negative")
+	}
+	if u := c.Upstream; u.Retries < 0 || u.RetryDelay < 0 || u.MaxRetryDelay < 0 || u.BreakerFailures < 0 || u.BreakerCooldown < 0 {
+		ve.add("upstream", "upstream retries, delays, breaker_failures, and breaker_cooldown must not be negative")
+	}
+
+	if c.Sync.Schedule != "" {
+		if _, err := cron.ParseStandard(c.Sync.Schedule); err != nil {
+			ve.add("sync.schedule", "invalid sync schedule %q: %v", c.Sync.Schedule, err)
+		}
+	}
+	switch c.Sync.AnimeDetection {
+	case "", "heuristic", "series_type", "mapping":
+	default:
+		ve.add("sync.anime_detection", "invalid sync anime_detection %q (must be heuristic, series_type, or mapping)", c.Sync.AnimeDetection)
+	}
+
+	// Validate notification targets
+	for i, target := range c.Notify.Targets {
+		path := fmt.Sprintf("notifications.targets[%d]", i)
+		switch target.Type {
+		case "discord", "slack", "webhook", "ntfy", "gotify":
+		default:
+			ve.add(path+".type", "invalid notification type %q (must be discord, slack, webhook, ntfy, or gotify)", target.Type)
+		}
+		if target.URL == "" {
+			ve.add(path+".url", "notification url is required")
+		}
+		if target.Type == "gotify" && target.Token == "" {
+			ve.add(path+".token", "gotify needs an application token")
+		}
+		for j, event := range target.Events {
+			if event != "generation" && event != "sync" && event != "alert" {
+				ve.add(fmt.Sprintf("%s.events[%d]", path, j), "invalid notification event %q (must be generation, sync, or alert)", event)
+			}
+		}
+	}
+	if c.Notify.LowCandidates < 0 {
+		ve.add("notifications.low_candidates", "notifications low_candidates must not be negative")
+	}
+	if c.Alerts.ThemeFailures < 0 || c.Alerts.UpstreamFailures < 0 {
+		ve.add("alerts.theme_failures", "alerts theme_failures and upstream_failures must not be negative")
+	}
+	if c.Alerts.CheckInterval < 0 {
+		ve.add("alerts.check_interval", "alerts check_interval must not be negative")
+	}
+	if c.History.RetentionDays < 0 {
+		ve.add("history.retention_days", "history retention_days must not be negative")
+	}
+	if c.History.RetentionDays > 0 {
+		if _, err := cron.ParseStandard(c.History.PruneSchedule); err != nil {
+			ve.add("history.prune_schedule", "invalid history prune_schedule %q: %v", c.History.PruneSchedule, err)
+		}
+	}
+	switch c.Logging.Format {
+	case "", "text", "json":
+	default:
+		ve.add("logging.format", "invalid logging format %q (must be text or json)", c.Logging.Format)
+	}
+	if _, err := c.Logging.SlogLevel(); err != nil {
+		ve.add("logging.level", "invalid logging level %q (must be debug, info, warn, or error)", c.Logging.Level)
+	}
+	for _, component := range slices.Sorted(maps.Keys(c.Logging.Levels)) {
+		path := "logging.levels." + component
+		if !slices.Contains(LogComponents, component) {
+			ve.add(path, "unknown logging component %q (must be one of %s)", component, strings.Join(LogComponents, ", "))
+		} else if _, err := c.Logging.ComponentLevel(component); err != nil {
+			ve.add(path, "invalid logging level %q for %s (must be debug, info, warn, or error)", c.Logging.Levels[component], component)
+		}
+	}
+	if c.Logging.MaxSizeMB < 0 || c.Logging.MaxBackups < 0 {
+		ve.add("logging.max_size_mb", "logging max_size_mb and max_backups must not be negative")
+	}
+
+	// Validate lists
+	listNames := make(map[string]bool, len(c.Lists))
+	for i, list := range c.Lists {
+		path := fmt.Sprintf("lists[%d]", i)
+		if list.Name == "" {
+			ve.add(path+".name", "list %d: name is required", i)
+		} else if listNames[list.Name] {
+			ve.add(path+".name", "list %s: duplicate name", list.Name)
+		}
+		listNames[list.Name] = true
+		if list.Source != "mdblist" && list.Source != "imdb" {
+			ve.add(path+".source", "list %s: invalid source %q (must be mdblist or imdb)", list.Name, list.Source)
+		}
+		if list.URL == "" {
+			ve.add(path+".url", "list %s: url is required", list.Name)
+		}
 	}
 
 	// Validate themes
 	for i, theme := range c.Themes {
+		field := func(name string) string { return c.themePath(i, name) }
 		if theme.Name == "" {
-			return fmt.Errorf("theme %d: name is required", i)
+			ve.add(field("name"), "theme %d: name is required", i)
 		}
 		if theme.ChannelID == "" {
-			return fmt.Errorf("theme %s: channel_id is required", theme.Name)
+			ve.add(field("channel_id"), "theme %s: channel_id is required", theme.Name)
+		}
+		if theme.Schedule != "" {
+			if _, err := cron.ParseStandard(theme.Schedule); err != nil {
+				ve.add(field("schedule"), "theme %s: invalid schedule %q: %v", theme.Name, theme.Schedule, err)
+			}
+		}
+		if theme.IncludeRequested && c.Overseerr.URL == "" {
+			ve.add(field("include_requested"), "theme %s: include_requested requires overseerr url", theme.Name)
 		}
+		if theme.TraktList != "" && c.Trakt.ClientID == "" {
+			ve.add(field("trakt_list"), "theme %s: trakt_list requires trakt client_id", theme.Name)
+		}
+		if err := validateContentRatings(theme.MinContentRating, theme.MaxContentRating); err != nil {
+			ve.add(field("min_content_rating"), "theme %s: %v", theme.Name, err)
+		}
+		if theme.MinYear > 0 && theme.MaxYear > 0 && theme.MinYear > theme.MaxYear {
+			ve.add(field("min_year"), "theme %s: min_year %d is after max_year %d", theme.Name, theme.MinYear, theme.MaxYear)
+		}
+		if theme.PreferNewDays < 0 {
+			ve.add(field("prefer_new_days"), "theme %s: prefer_new_days must not be negative", theme.Name)
+		}
+		if theme.MinRuntime < 0 || theme.MaxRuntime < 0 {
+			ve.add(field("min_runtime"), "theme %s: min_runtime and max_runtime must not be negative", theme.Name)
+		}
+		if theme.MinRuntime > 0 && theme.MaxRuntime > 0 && theme.MinRuntime > theme.MaxRuntime {
+			ve.add(field("min_runtime"), "theme %s: min_runtime %d is after max_runtime %d", theme.Name, theme.MinRuntime, theme.MaxRuntime)
+		}
+		for j, d := range theme.Decades {
+			start, ok := ParseDecade(d)
+			if !ok {
+				ve.add(field(fmt.Sprintf("decades[%d]", j)), "theme %s: invalid decade %q (e.g. 1980s)", theme.Name, d)
+				continue
+			}
+			if (theme.MinYear > 0 && start+9 < theme.MinYear) || (theme.MaxYear > 0 && start > theme.MaxYear) {
+				ve.add(field(fmt.Sprintf("decades[%d]", j)), "theme %s: decade %s is outside min_year/max_year", theme.Name, d)
+			}
+		}
+		if theme.Watershed != nil {
+			if err := theme.Watershed.validate(); err != nil {
+				ve.add(field("watershed"), "theme %s: watershed %v", theme.Name, err)
+			}
+		}
+		for j, d := range theme.DaysOfWeek {
+			if _, ok := ParseWeekday(d); !ok {
+				ve.add(field(fmt.Sprintf("days_of_week[%d]", j)), "theme %s: invalid day of week %q (e.g. saturday or sat)", theme.Name, d)
+			}
+		}
+		if theme.MinChange < 0 || theme.MinChange > 1 {
+			ve.add(field("min_change"), "theme %s: min_change must be between 0 and 1", theme.Name)
+		}
+		switch theme.Selection {
+		case "", "top", "weighted_random":
+		default:
+			ve.add(field("selection"), "theme %s: invalid selection %q (must be top or weighted_random)", theme.Name, theme.Selection)
+		}
+		if theme.SelectionTemperature < 0 {
+			ve.add(field("selection_temperature"), "theme %s: selection_temperature must not be negative", theme.Name)
+		}
+		switch theme.OrderBy {
+		case "", "score", "random", "chronological", "release", "narrative", "double_feature":
+		default:
+			ve.add(field("order_by"), "theme %s: invalid order_by %q (must be score, random, chronological, release, narrative, or double_feature)", theme.Name, theme.OrderBy)
+		}
+		switch theme.Collections {
+		case "", "group", "prioritize":
+		default:
+			ve.add(field("collections"), "theme %s: invalid collections %q (must be group or prioritize)", theme.Name, theme.Collections)
+		}
+		for j, name := range theme.IncludeLists {
+			if !listNames[name] {
+				ve.add(field(fmt.Sprintf("include_lists[%d]", j)), "theme %s: unknown list %q", theme.Name, name)
+			}
+		}
+		for j, name := range theme.ExcludeLists {
+			if !listNames[name] {
+				ve.add(field(fmt.Sprintf("exclude_lists[%d]", j)), "theme %s: unknown list %q", theme.Name, name)
+			}
+		}
+		theme.Prompts.validate(ve, field("prompts"))
 	}
 
+	return ve.err()
+}
+
+// themePath returns the YAML path of a setting of the theme at index i of Themes, prefixed
+// with the file of themes loaded from themes_dir
+func (c *Config) themePath(i int, field string) string {
+	if i < len(c.themeSources) && c.themeSources[i].file != "" {
+		src := c.themeSources[i]
+		return src.file + ": " + joinPath(src.path, field)
+	}
+	return joinPath(fmt.Sprintf("themes[%d]", i), field)
+}
+
+// validateInstances checks that at least one instance is configured and that each
+// has a unique name, a URL, and an API key
+func validateInstances(ve *ValidationError, kind string, instances []instance) {
+	if len(instances) == 0 {
+		ve.add(kind, "%s URL is required", kind)
+		return
+	}
+
+	names := make(map[string]bool, len(instances))
+	for i, inst := range instances {
+		path := fmt.Sprintf("%s[%d]", kind, i)
+		if inst.Name == "" {
+			ve.add(path+".name", "%s instance %d: name is required", kind, i)
+		} else if names[inst.Name] {
+			ve.add(path+".name", "%s instance %s: duplicate name", kind, inst.Name)
+		}
+		names[inst.Name] = true
+
+		prefix := kind
+		if len(instances) > 1 {
+			prefix = fmt.Sprintf("%s instance %s:", kind, inst.Name)
+		}
+		if inst.URL == "" {
+			ve.add(path+".url", "%s URL is required", prefix)
+		}
+		if inst.APIKey == "" {
+			ve.add(path+".api_key", "%s API key is required", prefix)
+		}
+		if inst.Timeout < 0 {
+			ve.add(path+".timeout", "%s timeout must not be negative", prefix)
+		}
+		validateProxy(ve, path+".proxy", prefix, inst.Proxy)
+		validateTLS(ve, path+".tls", prefix, inst.TLS)
+	}
+}
+
+// validateProxy checks the proxy URL of an upstream
+func validateProxy(ve *ValidationError, path, upstream, proxy string) {
+	if proxy == "" {
+		return
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		ve.add(path, "%s proxy must be a URL such as http://proxy:3128", upstream)
+		return
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		ve.add(path, "%s proxy scheme %q is not supported (must be http, https, or socks5)", upstream, u.Scheme)
+	}
+}
+
+// validateTLS checks that the CA file of an upstream holds certificates
+func validateTLS(ve *ValidationError, path, upstream string, t TLSConfig) {
+	if _, err := t.ClientConfig(); err != nil {
+		ve.add(path+".ca_file", "%s %v", upstream, err)
+	}
+}
+
+// ParseWeekday parses a day name such as "Saturday" or "sat", ignoring case
+func ParseWeekday(day string) (time.Weekday, bool) {
+	day = strings.ToLower(strings.TrimSpace(day))
+	if len(day) < 3 {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if name := strings.ToLower(d.String()); strings.HasPrefix(name, day) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// RunsOn reports whether the theme's days_of_week include the given day
+func (t *ThemeConfig) RunsOn(day time.Weekday) bool {
+	if len(t.DaysOfWeek) == 0 {
+		return true
+	}
+	for _, d := range t.DaysOfWeek {
+		if wd, ok := ParseWeekday(d); ok && wd == day {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDecade returns the first year of a decade written like "1980s"
+func ParseDecade(decade string) (int, bool) {
+	d := strings.TrimSuffix(strings.TrimSpace(decade), "s")
+	if len(d) != 4 {
+		return 0, false
+	}
+	year, err := strconv.Atoi(d)
+	if err != nil || year%10 != 0 {
+		return 0, false
+	}
+	return year, true
+}
+
+// validateContentRatings checks that content rating bounds are known certifications in order
+func validateContentRatings(minRating, maxRating string) error {
+	var minLevel, maxLevel int
+	if minRating != "" {
+		level, ok := models.CertificationLevel(minRating)
+		if !ok {
+			return fmt.Errorf("unknown min_content_rating %q", minRating)
+		}
+		minLevel = level
+	}
+	if maxRating != "" {
+		level, ok := models.CertificationLevel(maxRating)
+		if !ok {
+			return fmt.Errorf("unknown max_content_rating %q", maxRating)
+		}
+		maxLevel = level
+	}
+	if minLevel > 0 && maxLevel > 0 && minLevel > maxLevel {
+		return fmt.Errorf("min_content_rating %s is above max_content_rating %s", minRating, maxRating)
+	}
+	return nil
+}
+
+// validate checks the watershed window and rating
+func (w *WatershedConfig) validate() error {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return fmt.Errorf("start %q must be HH:MM", w.Start)
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return fmt.Errorf("end %q must be HH:MM", w.End)
+	}
+	if start.Equal(end) {
+		return errors.New("start and end must differ")
+	}
+	if w.MaxContentRating != "" {
+		if _, ok := models.CertificationLevel(w.MaxContentRating); !ok {
+			return fmt.Errorf("unknown max_content_rating %q", w.MaxContentRating)
+		}
+	}
 	return nil
 }
 
 // DSN returns the database connection string for PostgreSQL
 func (c *PostgresConfig) DSN() string {
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Host, c.Port, c.User, c.Password, c.Database, c.SSLMode,
 	)
+	if c.StatementTimeout > 0 {
+		// Sent as a run-time parameter, in milliseconds, on every connection
+		dsn += fmt.Sprintf(" statement_timeout=%d", c.StatementTimeout*1000)
+	}
+	return dsn
 }
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidate(t *testing.T) {
@@ -21,13 +25,11 @@ func TestValidate(t *testing.T) {
 						Path: "./test.db",
 					},
 				},
-				Radarr: RadarrConfig{
-					URL:    "http://localhost:7878",
-					APIKey: "test-key",
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
 				},
-				Sonarr: SonarrConfig{
-					URL:    "http://localhost:8989",
-					APIKey: "test-key",
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
 				},
 				Tunarr: TunarrConfig{
 					URL: "http://localhost:8000",
@@ -45,13 +47,11 @@ func TestValidate(t *testing.T) {
 				Database: DatabaseConfig{
 					Driver: "invalid",
 				},
-				Radarr: RadarrConfig{
-					URL:    "http://localhost:7878",
-					APIKey: "test-key",
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
 				},
-				Sonarr: SonarrConfig{
-					URL:    "http://localhost:8989",
-					APIKey: "test-key",
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
 				},
 				Tunarr: TunarrConfig{
 					URL: "http://localhost:8000",
@@ -70,13 +70,11 @@ func TestValidate(t *testing.T) {
 				Database: DatabaseConfig{
 					Driver: "sqlite",
 				},
-				Radarr: RadarrConfig{
-					URL:    "http://localhost:7878",
-					APIKey: "",
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: ""},
 				},
-				Sonarr: SonarrConfig{
-					URL:    "http://localhost:8989",
-					APIKey: "test-key",
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
 				},
 				Tunarr: TunarrConfig{
 					URL: "http://localhost:8000",
@@ -95,13 +93,11 @@ func TestValidate(t *testing.T) {
 				Database: DatabaseConfig{
 					Driver: "sqlite",
 				},
-				Radarr: RadarrConfig{
-					URL:    "http://localhost:7878",
-					APIKey: "test-key",
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
 				},
-				Sonarr: SonarrConfig{
-					URL:    "http://localhost:8989",
-					APIKey: "",
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: ""},
 				},
 				Tunarr: TunarrConfig{
 					URL: "http://localhost:8000",
@@ -114,19 +110,72 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "sonarr API key is required",
 		},
+		{
+			name: "duplicate radarr instance names",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+				},
+				Radarr: []RadarrConfig{
+					{Name: "hd", URL: "http://localhost:7878", APIKey: "test-key"},
+					{Name: "hd", URL: "http://localhost:7879", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+			},
+			wantErr: true,
+			errMsg:  "radarr instance hd: duplicate name",
+		},
+		{
+			name: "inverted content ratings",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+				Themes: []ThemeConfig{
+					{
+						Name:             "kids",
+						ChannelID:        "kids-channel",
+						MinContentRating: "R",
+						MaxContentRating: "PG",
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "min_content_rating R is above max_content_rating PG",
+		},
 		{
 			name: "missing theme channel id",
 			config: Config{
 				Database: DatabaseConfig{
 					Driver: "sqlite",
 				},
-				Radarr: RadarrConfig{
-					URL:    "http://localhost:7878",
-					APIKey: "test-key",
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
 				},
-				Sonarr: SonarrConfig{
-					URL:    "http://localhost:8989",
-					APIKey: "test-key",
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
 				},
 				Tunarr: TunarrConfig{
 					URL: "http://localhost:8000",
@@ -145,6 +194,234 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "channel_id is required",
 		},
+		{
+			name: "invalid sync schedule",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+				Sync: SyncConfig{
+					Schedule: "every night",
+				},
+			},
+			wantErr: true,
+			errMsg:  "invalid sync schedule",
+		},
+		{
+			name: "invalid anime detection",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+				Sync: SyncConfig{
+					AnimeDetection: "anilist",
+				},
+			},
+			wantErr: true,
+			errMsg:  "invalid sync anime_detection",
+		},
+		{
+			name: "invalid webhook verify",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+				Server: ServerConfig{
+					Webhooks: WebhooksConfig{
+						Sonarr: WebhookConfig{Secret: "s3cret", Verify: "jwt"},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "server.webhooks.sonarr.verify",
+		},
+		{
+			name: "negative postgres pool size",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver:   "postgres",
+					Postgres: PostgresConfig{Host: "localhost", MaxOpenConns: -1},
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+			},
+			wantErr: true,
+			errMsg:  "max_open_conns",
+		},
+		{
+			name: "invalid sqlite maintenance schedule",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+					SQLite: SQLiteConfig{MaintenanceSchedule: "weekly"},
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+			},
+			wantErr: true,
+			errMsg:  "database.sqlite.maintenance_schedule",
+		},
+		{
+			name: "unreadable radarr CA file",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "https://radarr.lan", APIKey: "test-key", TLS: TLSConfig{CAFile: "/nonexistent/ca.pem"}},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+			},
+			wantErr: true,
+			errMsg:  "radarr[0].tls.ca_file",
+		},
+		{
+			name: "unsupported tunarr proxy",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL:   "http://localhost:8000",
+					Proxy: "ftp://proxy:21",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+			},
+			wantErr: true,
+			errMsg:  "tunarr.proxy",
+		},
+		{
+			name: "invalid logging level",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+				Logging: LoggingConfig{
+					Format: "json",
+					Level:  "verbose",
+				},
+			},
+			wantErr: true,
+			errMsg:  "invalid logging level",
+		},
+		{
+			name: "unknown logging component",
+			config: Config{
+				Database: DatabaseConfig{
+					Driver: "sqlite",
+				},
+				Radarr: []RadarrConfig{
+					{Name: "default", URL: "http://localhost:7878", APIKey: "test-key"},
+				},
+				Sonarr: []SonarrConfig{
+					{Name: "default", URL: "http://localhost:8989", APIKey: "test-key"},
+				},
+				Tunarr: TunarrConfig{
+					URL: "http://localhost:8000",
+				},
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "test-model",
+				},
+				Logging: LoggingConfig{
+					Levels: map[string]string{"ollama": "debug", "webhooks": "warn"},
+				},
+			},
+			wantErr: true,
+			errMsg:  "logging.levels.webhooks",
+		},
 	}
 
 	for _, tt := range tests {
@@ -179,6 +456,11 @@ func TestPostgresConfigDSN(t *testing.T) {
 	if got != want {
 		t.Errorf("DSN() = %v, want %v", got, want)
 	}
+
+	cfg.StatementTimeout = 30
+	if got, want := cfg.DSN(), want+" statement_timeout=30000"; got != want {
+		t.Errorf("DSN() with statement_timeout = %v, want %v", got, want)
+	}
 }
 
 func TestLoadConfigWithDefaults(t *testing.T) {
@@ -207,11 +489,277 @@ func TestLoadConfigWithDefaults(t *testing.T) {
 	if cfg.Ollama.Temperature != 0.7 {
 		t.Errorf("Default ollama temperature = %v, want 0.7", cfg.Ollama.Temperature)
 	}
-	if cfg.Radarr.APIKey != "test-radarr-key" {
-		t.Errorf("Radarr API key = %v, want test-radarr-key", cfg.Radarr.APIKey)
+	if cfg.LLM.Traces.RetentionDays != 7 {
+		t.Errorf("Default llm traces retention = %v, want 7", cfg.LLM.Traces.RetentionDays)
+	}
+	if cfg.Radarr[0].APIKey != "test-radarr-key" {
+		t.Errorf("Radarr API key = %v, want test-radarr-key", cfg.Radarr[0].APIKey)
 	}
-	if cfg.Sonarr.APIKey != "test-sonarr-key" {
-		t.Errorf("Sonarr API key = %v, want test-sonarr-key", cfg.Sonarr.APIKey)
+	if cfg.Sonarr[0].APIKey != "test-sonarr-key" {
+		t.Errorf("Sonarr API key = %v, want test-sonarr-key", cfg.Sonarr[0].APIKey)
+	}
+}
+
+func TestLoadMultipleInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+radarr:
+  - name: hd
+    url: http://radarr:7878
+    api_key: hd-key
+  - name: 4k
+    url: http://radarr4k:7878
+    api_key: 4k-key
+sonarr:
+  url: http://sonarr:8989
+  api_key: sonarr-key
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(cfg.Radarr) != 2 || cfg.Radarr[0].Name != "hd" || cfg.Radarr[1].Name != "4k" {
+		t.Fatalf("Radarr instances = %+v, want hd and 4k", cfg.Radarr)
+	}
+	if cfg.Radarr[1].APIKey != "4k-key" {
+		t.Errorf("Radarr 4k API key = %v, want 4k-key", cfg.Radarr[1].APIKey)
+	}
+
+	// A single map is still accepted and named after the default instance
+	if len(cfg.Sonarr) != 1 || cfg.Sonarr[0].Name != DefaultInstance {
+		t.Fatalf("Sonarr instances = %+v, want one default instance", cfg.Sonarr)
+	}
+	if cfg.Sonarr[0].APIKey != "sonarr-key" {
+		t.Errorf("Sonarr API key = %v, want sonarr-key", cfg.Sonarr[0].APIKey)
+	}
+}
+
+func TestLoadThemesDir(t *testing.T) {
+	base := `
+radarr:
+  url: http://radarr:7878
+  api_key: radarr-key
+sonarr:
+  url: http://sonarr:8989
+  api_key: sonarr-key
+themes_dir: themes.d
+themes:
+  - name: inline
+    channel_id: ch0
+`
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "merged in file order",
+			files: map[string]string{
+				"b-horror.yaml": "name: horror\nchannel_id: ch2\n",
+				"a-scifi.yml":   "themes:\n  - name: sci-fi\n    channel_id: ch1\n  - name: space\n    channel_id: ch3\n",
+				"notes.txt":     "not a theme",
+				".swap.yaml":    "name: hidden",
+			},
+			want: []string{"inline", "sci-fi", "space", "horror"},
+		},
+		{
+			name:    "duplicate name",
+			files:   map[string]string{"dup.yaml": "name: inline\nchannel_id: ch1\n"},
+			wantErr: "already defined in the config file",
+		},
+		{
+			name:    "invalid theme",
+			files:   map[string]string{"bad.yaml": "name: broken\n"},
+			wantErr: "theme broken: channel_id is required",
+		},
+		{
+			name:    "unknown key in list",
+			files:   map[string]string{"list.yaml": "themes:\n  - name: x\n    channel_id: c\n    colour: red\n"},
+			wantErr: "list.yaml: themes[0].colour: unknown key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(dir, "themes.d"), 0o700); err != nil {
+				t.Fatalf("failed to create themes.d: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(base), 0o600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			for name, data := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, "themes.d", name), []byte(data), 0o600); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+			}
+
+			cfg, err := Load(filepath.Join(dir, "config.yaml"))
+			if tt.wantErr != "" {
+				if err == nil || !contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			var got []string
+			for _, theme := range cfg.Themes {
+				got = append(got, theme.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("themes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := func(name, value string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(value+"\n"), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	radarrKey := secret("radarr", "radarr-secret")
+	tmdbKey := secret("tmdb", "tmdb-secret")
+	sonarrKey := secret("sonarr", "sonarr-secret")
+	pgPassword := secret("postgres", "pg-secret")
+
+	path := filepath.Join(dir, "config.yaml")
+	data := `
+radarr:
+  url: http://radarr:7878
+  api_key_file: ` + radarrKey + `
+sonarr:
+  url: http://sonarr:8989
+tmdb:
+  api_key: inline
+  api_key_file: ` + tmdbKey + `
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name: "files",
+			env:  map[string]string{"SONARR_API_KEY_FILE": sonarrKey, "POSTGRES_PASSWORD_FILE": pgPassword},
+		},
+		{
+			name:    "env and file",
+			env:     map[string]string{"SONARR_API_KEY_FILE": sonarrKey, "SONARR_API_KEY": "env"},
+			wantErr: "both SONARR_API_KEY and SONARR_API_KEY_FILE are set",
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{"SONARR_API_KEY_FILE": filepath.Join(dir, "missing")},
+			wantErr: "failed to read secret file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if cfg.Radarr[0].APIKey != "radarr-secret" {
+				t.Errorf("Radarr API key = %q, want radarr-secret", cfg.Radarr[0].APIKey)
+			}
+			if cfg.Sonarr[0].APIKey != "sonarr-secret" {
+				t.Errorf("Sonarr API key = %q, want sonarr-secret", cfg.Sonarr[0].APIKey)
+			}
+			if cfg.Database.Postgres.Password != "pg-secret" {
+				t.Errorf("Postgres password = %q, want pg-secret", cfg.Database.Postgres.Password)
+			}
+			// A secret set directly wins over its file
+			if cfg.TMDB.APIKey != "inline" {
+				t.Errorf("TMDB API key = %q, want inline", cfg.TMDB.APIKey)
+			}
+		})
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+database:
+  driver: mysql
+radarr:
+  url: http://radarr:7878
+  api_key: radarr-key
+  apikey: typo
+sonarr:
+  url: http://sonarr:8989
+cooldown:
+  movie_days: soon
+themes:
+  - name: sci-fi
+    channel_id: ch1
+    colour: red
+  - name: noir
+    channel_id: ch2
+    min_year: 1960
+    max_year: 1940
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	_, err := Load(path)
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Load() error = %v, want a *ValidationError", err)
+	}
+
+	want := []string{
+		"radarr[0].apikey",
+		"cooldown.movie_days",
+		"themes[0].colour",
+		"database.driver",
+		"sonarr[0].api_key",
+		"themes[1].min_year",
+	}
+	got := make(map[string]string, len(ve.Errors))
+	for _, fe := range ve.Errors {
+		got[fe.Path] = fe.Message
+	}
+	for _, path := range want {
+		if _, ok := got[path]; !ok {
+			t.Errorf("no error for %s in %v", path, ve.Errors)
+		}
+	}
+	if len(ve.Errors) != len(want) {
+		t.Errorf("got %d errors, want %d: %v", len(ve.Errors), len(want), ve.Errors)
+	}
+	if got["themes[0].colour"] != "unknown key" {
+		t.Errorf("themes[0].colour = %q, want unknown key", got["themes[0].colour"])
 	}
 }
 
@@ -229,3 +777,86 @@ func hasSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestThemeRunsOn(t *testing.T) {
+	weekend := ThemeConfig{DaysOfWeek: []string{"Saturday", "sun"}}
+	everyDay := ThemeConfig{}
+
+	tests := []struct {
+		day  time.Weekday
+		want bool
+	}{
+		{day: time.Saturday, want: true},
+		{day: time.Sunday, want: true},
+		{day: time.Monday, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := weekend.RunsOn(tt.day); got != tt.want {
+			t.Errorf("RunsOn(%s) = %v, want %v", tt.day, got, tt.want)
+		}
+		if !everyDay.RunsOn(tt.day) {
+			t.Errorf("theme without days_of_week should run on %s", tt.day)
+		}
+	}
+
+	if _, ok := ParseWeekday("sa"); ok {
+		t.Error("expected ambiguous day abbreviation to be rejected")
+	}
+}
+
+func TestLoadPromptPaths(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": `
+radarr:
+  url: http://radarr:7878
+  api_key: radarr-key
+sonarr:
+  url: http://sonarr:8989
+  api_key: sonarr-key
+llm:
+  prompts:
+    system: prompts/system.tmpl
+themes_dir: themes.d
+themes:
+  - name: inline
+    channel_id: ch0
+`,
+		"prompts/system.tmpl":   "{{.ResponseFormat}}",
+		"themes.d/anime.yaml":   "name: anime\nchannel_id: ch1\nprompts:\n  user: anime.tmpl\n",
+		"themes.d/anime.tmpl":   "{{.CandidateList}}",
+		"themes.d/missing.yaml": "name: missing\nchannel_id: ch2\nprompts:\n  user: nope.tmpl\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := Load(filepath.Join(dir, "config.yaml"))
+	if err == nil || !contains(err.Error(), "missing.yaml: prompts.user: cannot read prompt template") {
+		t.Fatalf("Load() error = %v, want an unreadable prompts.user in missing.yaml", err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "themes.d/missing.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := filepath.Join(dir, "prompts/system.tmpl"); cfg.LLM.Prompts.System != want {
+		t.Errorf("llm.prompts.system = %q, want %q", cfg.LLM.Prompts.System, want)
+	}
+	if want := filepath.Join(dir, "themes.d/anime.tmpl"); cfg.Themes[1].Prompts.User != want {
+		t.Errorf("anime prompts.user = %q, want %q", cfg.Themes[1].Prompts.User, want)
+	}
+	if got := cfg.Themes[1].Prompts.Or(cfg.LLM.Prompts); got.System != cfg.LLM.Prompts.System {
+		t.Errorf("Or() system = %q, want the global template", got.System)
+	}
+}
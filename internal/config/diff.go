@@ -0,0 +1,72 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Diff describes what changed between two configs
+type Diff struct {
+	Sections      []string            `json:"sections,omitempty"` // Changed top-level sections other than themes, e.g. "cooldown"
+	ThemesAdded   []string            `json:"themes_added,omitempty"`
+	ThemesRemoved []string            `json:"themes_removed,omitempty"`
+	ThemesChanged map[string][]string `json:"themes_changed,omitempty"` // Theme name to its changed settings
+}
+
+// Empty reports whether the configs are the same
+func (d Diff) Empty() bool {
+	return len(d.Sections) == 0 && len(d.ThemesAdded) == 0 && len(d.ThemesRemoved) == 0 && len(d.ThemesChanged) == 0
+}
+
+// DiffConfigs compares two configs section by section and theme by theme, matching themes by name
+func DiffConfigs(old, updated *Config) Diff {
+	var d Diff
+	for _, field := range changedFields(*old, *updated) {
+		if field != "themes" {
+			d.Sections = append(d.Sections, field)
+		}
+	}
+
+	oldThemes := make(map[string]ThemeConfig, len(old.Themes))
+	for _, t := range old.Themes {
+		oldThemes[t.Name] = t
+	}
+	for _, t := range updated.Themes {
+		prev, ok := oldThemes[t.Name]
+		delete(oldThemes, t.Name)
+		if !ok {
+			d.ThemesAdded = append(d.ThemesAdded, t.Name)
+			continue
+		}
+		if fields := changedFields(prev, t); len(fields) > 0 {
+			if d.ThemesChanged == nil {
+				d.ThemesChanged = make(map[string][]string)
+			}
+			d.ThemesChanged[t.Name] = fields
+		}
+	}
+	for _, t := range old.Themes {
+		if _, ok := oldThemes[t.Name]; ok {
+			d.ThemesRemoved = append(d.ThemesRemoved, t.Name)
+		}
+	}
+
+	return d
+}
+
+// changedFields returns the mapstructure names of the fields that differ between two structs
+// of the same type. Fields without a name, such as Config.File, are ignored.
+func changedFields(a, b any) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	var fields []string
+	for i := 0; i < va.NumField(); i++ {
+		name, _, _ := strings.Cut(va.Type().Field(i).Tag.Get("mapstructure"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	base := Config{
+		Cooldown: CooldownConfig{MovieDays: 30},
+		Themes: []ThemeConfig{
+			{Name: "sci-fi", ChannelID: "ch1", MaxItems: 10},
+			{Name: "horror", ChannelID: "ch2"},
+		},
+		File: "a.yaml",
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   Diff
+	}{
+		{"unchanged", func(c *Config) { c.File = "b.yaml" }, Diff{}},
+		{"section", func(c *Config) { c.Cooldown.MovieDays = 14 }, Diff{Sections: []string{"cooldown"}}},
+		{"theme added", func(c *Config) {
+			c.Themes = append(c.Themes, ThemeConfig{Name: "anime"})
+		}, Diff{ThemesAdded: []string{"anime"}}},
+		{"theme removed", func(c *Config) { c.Themes = c.Themes[:1] }, Diff{ThemesRemoved: []string{"horror"}}},
+		{"theme changed", func(c *Config) {
+			c.Themes[0].MaxItems = 12
+			c.Themes[0].Genres = []string{"Sci-Fi"}
+		}, Diff{ThemesChanged: map[string][]string{"sci-fi": {"genres", "max_items"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := base
+			updated.Themes = append([]ThemeConfig(nil), base.Themes...)
+			tt.modify(&updated)
+
+			got := DiffConfigs(&base, &updated)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffConfigs() = %+v, want %+v", got, tt.want)
+			}
+			if got.Empty() != tt.want.Empty() {
+				t.Errorf("Empty() = %v, want %v", got.Empty(), tt.want.Empty())
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// PromptConfig names Go template files that replace the built-in LLM refinement prompts.
+// An empty file keeps the built-in prompt.
+type PromptConfig struct {
+	System string `mapstructure:"system"` // System prompt template file
+	User   string `mapstructure:"user"`   // User prompt template file
+}
+
+// Or returns p with its empty files taken from fallback, e.g. a theme's prompts over llm.prompts
+func (p PromptConfig) Or(fallback PromptConfig) PromptConfig {
+	if p.System == "" {
+		p.System = fallback.System
+	}
+	if p.User == "" {
+		p.User = fallback.User
+	}
+	return p
+}
+
+// resolve makes relative files relative to dir
+func (p *PromptConfig) resolve(dir string) {
+	for _, file := range []*string{&p.System, &p.User} {
+		if *file != "" && !filepath.IsAbs(*file) {
+			*file = filepath.Join(dir, *file)
+		}
+	}
+}
+
+// validate checks that the prompt files can be read; template errors surface when they are used
+func (p PromptConfig) validate(ve *ValidationError, path string) {
+	for _, f := range []struct{ field, file string }{{"system", p.System}, {"user", p.User}} {
+		if f.file == "" {
+			continue
+		}
+		if _, err := os.ReadFile(f.file); err != nil {
+			ve.add(joinPath(path, f.field), "cannot read prompt template: %v", err)
+		}
+	}
+}
+
+// resolvePromptPaths resolves relative prompt files against the directory of the file that
+// names them: the config file, or a theme file in themes_dir
+func (c *Config) resolvePromptPaths() {
+	dir := "."
+	if c.File != "" {
+		dir = filepath.Dir(c.File)
+	}
+	c.LLM.Prompts.resolve(dir)
+	for i := range c.Themes {
+		themeDir := dir
+		if i < len(c.themeSources) && c.themeSources[i].file != "" {
+			themeDir = filepath.Dir(c.themeSources[i].file)
+		}
+		c.Themes[i].Prompts.resolve(themeDir)
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce waits for editors to finish writing before the file is read
+const reloadDebounce = 500 * time.Millisecond
+
+// ApplyFunc hands a reloaded config to a running component. It is called again with the
+// arguments swapped to roll the component back when a later ApplyFunc fails.
+type ApplyFunc func(old, updated *Config) error
+
+// Reloader re-reads the config file on demand, on SIGHUP, or when the file changes, and
+// applies valid configs to the registered components. Invalid configs are rejected whole.
+type Reloader struct {
+	mu      sync.Mutex
+	path    string
+	current *Config
+	apply   []ApplyFunc
+	load    func(path string) (*Config, error)
+	logger  *slog.Logger
+
+	themesDir string // themes_dir being watched, only used by Watch
+}
+
+// NewReloader creates a Reloader starting from current, which was loaded from path
+func NewReloader(path string, current *Config, logger *slog.Logger) *Reloader {
+	return &Reloader{
+		path:    path,
+		current: current,
+		load:    Load,
+		logger:  logger,
+	}
+}
+
+// OnReload registers fn; functions are applied in registration order
+func (r *Reloader) OnReload(fn ApplyFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apply = append(r.apply, fn)
+}
+
+// Current returns the config in effect
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload reads and validates the config file, then applies it. When a component fails to
+// apply it, the components already updated are rolled back and the old config stays in
+// effect. It returns the previous and the new config.
+func (r *Reloader) Reload() (old, updated *Config, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated, err = r.load(r.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	old = r.current
+
+	for i, fn := range r.apply {
+		if err := fn(old, updated); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if rbErr := r.apply[j](updated, old); rbErr != nil {
+					r.logger.Error("failed to roll back config", "error", rbErr)
+				}
+			}
+			return nil, nil, fmt.Errorf("failed to apply config: %w", err)
+		}
+	}
+
+	r.current = updated
+	return old, updated, nil
+}
+
+// Watch reloads on SIGHUP and, when the config came from a file, whenever that file or a
+// file in themes_dir changes, until ctx is canceled. Failed reloads are logged and the old
+// config is kept.
+func (r *Reloader) Watch(ctx context.Context) error {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if r.path != "" {
+		// Watch the directory, since editors and ConfigMap updates replace the file
+		if err := watcher.Add(filepath.Dir(r.path)); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", r.path, err)
+		}
+	}
+	r.watchThemesDir(watcher)
+
+	r.logger.Info("watching config for changes", "file", r.path, "themes_dir", r.themesDir)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sighup:
+			r.reload("sighup")
+			r.watchThemesDir(watcher)
+		case event := <-watcher.Events:
+			if r.isConfigEvent(event) {
+				debounce = time.After(reloadDebounce)
+			}
+		case <-debounce:
+			debounce = nil
+			r.reload("file change")
+			r.watchThemesDir(watcher)
+		case err := <-watcher.Errors:
+			if err != nil && !errors.Is(err, fsnotify.ErrEventOverflow) {
+				r.logger.Warn("config watcher error", "error", err)
+			}
+		}
+	}
+}
+
+// watchThemesDir moves the watch to the current config's themes_dir when it changed
+func (r *Reloader) watchThemesDir(watcher *fsnotify.Watcher) {
+	dir := r.Current().ThemesPath()
+	if dir == r.themesDir {
+		return
+	}
+	if r.themesDir != "" && r.themesDir != filepath.Dir(r.path) {
+		_ = watcher.Remove(r.themesDir)
+	}
+	r.themesDir = dir
+	if dir == "" {
+		return
+	}
+	if err := watcher.Add(dir); err != nil {
+		r.logger.Warn("failed to watch themes_dir, changes need a reload", "dir", dir, "error", err)
+	}
+}
+
+// isConfigEvent reports whether event may have changed the config file's or a theme file's contents
+func (r *Reloader) isConfigEvent(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	// Kubernetes swaps ConfigMap contents through a ..data symlink
+	name := filepath.Base(event.Name)
+	if filepath.Clean(event.Name) == filepath.Clean(r.path) || name == "..data" {
+		return true
+	}
+	return r.themesDir != "" && filepath.Dir(event.Name) == r.themesDir && isThemeFile(name)
+}
+
+// reload reloads and logs the outcome
+func (r *Reloader) reload(trigger string) {
+	old, updated, err := r.Reload()
+	if err != nil {
+		r.logger.Error("config reload failed, keeping current config", "trigger", trigger, "error", err)
+		return
+	}
+
+	diff := DiffConfigs(old, updated)
+	r.logger.Info("config reloaded",
+		"trigger", trigger,
+		"themes", len(updated.Themes),
+		"sections_changed", diff.Sections,
+		"themes_added", diff.ThemesAdded,
+		"themes_removed", diff.ThemesRemoved,
+		"themes_changed", len(diff.ThemesChanged),
+	)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	initial := &Config{Themes: []ThemeConfig{{Name: "old"}}}
+	next := &Config{Themes: []ThemeConfig{{Name: "new"}}}
+
+	tests := []struct {
+		name      string
+		loadErr   error
+		failAt    int // index of the ApplyFunc that fails, -1 for none
+		wantErr   bool
+		wantTheme string
+		wantApply []string // final config seen by each component
+	}{
+		{"applied", nil, -1, false, "new", []string{"new", "new"}},
+		{"invalid config", errors.New("theme 0: name is required"), -1, true, "old", []string{"old", "old"}},
+		{"rolled back", nil, 1, true, "old", []string{"old", "old"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReloader("config.yaml", initial, logger)
+			r.load = func(string) (*Config, error) {
+				if tt.loadErr != nil {
+					return nil, tt.loadErr
+				}
+				return next, nil
+			}
+
+			applied := []string{"old", "old"}
+			for i := range applied {
+				r.OnReload(func(_, updated *Config) error {
+					if i == tt.failAt && updated == next {
+						return errors.New("apply failed")
+					}
+					applied[i] = updated.Themes[0].Name
+					return nil
+				})
+			}
+
+			_, _, err := r.Reload()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := r.Current().Themes[0].Name; got != tt.wantTheme {
+				t.Errorf("Current() theme = %q, want %q", got, tt.wantTheme)
+			}
+			for i, want := range tt.wantApply {
+				if applied[i] != want {
+					t.Errorf("component %d has %q, want %q", i, applied[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestReloadKeepsConfigOnMalformedFile(t *testing.T) {
+	// Env vars alone would make a valid config with no themes
+	t.Setenv("RADARR_URL", "http://radarr:7878")
+	t.Setenv("RADARR_API_KEY", "radarr-key")
+	t.Setenv("SONARR_URL", "http://sonarr:8989")
+	t.Setenv("SONARR_API_KEY", "sonarr-key")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("themes:\n  - name: noir\n    channel_id: ch1\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	initial, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	r := NewReloader(path, initial, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	applied := false
+	r.OnReload(func(_, _ *Config) error {
+		applied = true
+		return nil
+	})
+
+	// A half-saved file
+	if err := os.WriteFile(path, []byte("themes:\n  - name: noir\n    channel_id: [ch1\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, _, err := r.Reload(); err == nil || !strings.Contains(err.Error(), "error reading config file") {
+		t.Fatalf("Reload() error = %v, want the parse error", err)
+	}
+	if applied {
+		t.Error("malformed config was applied")
+	}
+	if themes := r.Current().Themes; len(themes) != 1 || themes[0].Name != "noir" {
+		t.Errorf("Current() themes = %+v, want noir still in effect", themes)
+	}
+}
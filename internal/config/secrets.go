@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// readSecretFile reads a secret mounted as a file, such as a Docker or Kubernetes secret,
+// without its trailing newline
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+	return secret, nil
+}
+
+// getenvOrFile returns the environment variable name, or the contents of the file named by
+// name_FILE. Setting both is an error.
+func getenvOrFile(name string) (string, error) {
+	value := os.Getenv(name)
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return value, nil
+	}
+	if value != "" {
+		return "", fmt.Errorf("both %s and %s_FILE are set", name, name)
+	}
+	return readSecretFile(path)
+}
+
+// applyEnvFiles sets each bound key whose environment variable has a _FILE variant set,
+// e.g. POSTGRES_PASSWORD_FILE for database.postgres.password
+func applyEnvFiles(v *viper.Viper) error {
+	for _, b := range envBindings {
+		if os.Getenv(b.env+"_FILE") == "" {
+			continue
+		}
+		value, err := getenvOrFile(b.env)
+		if err != nil {
+			return err
+		}
+		v.Set(b.key, value)
+	}
+	return nil
+}
+
+// secretFile is a secret setting and the *_file setting it is read from
+type secretFile struct {
+	key   string
+	value *string
+	file  string
+}
+
+// readSecretFiles fills secrets left empty from their *_file settings, so a secret set in
+// YAML or the environment takes precedence over its file
+func (c *Config) readSecretFiles() error {
+	secrets := []secretFile{
+		{"database.postgres.password_file", &c.Database.Postgres.Password, c.Database.Postgres.PasswordFile},
+		{"trakt.client_secret_file", &c.Trakt.ClientSecret, c.Trakt.ClientSecretFile},
+		{"overseerr.api_key_file", &c.Overseerr.APIKey, c.Overseerr.APIKeyFile},
+		{"tmdb.api_key_file", &c.TMDB.APIKey, c.TMDB.APIKeyFile},
+		{"tautulli.api_key_file", &c.Tautulli.APIKey, c.Tautulli.APIKeyFile},
+		{"server.webhooks.radarr.secret_file", &c.Server.Webhooks.Radarr.Secret, c.Server.Webhooks.Radarr.SecretFile},
+		{"server.webhooks.sonarr.secret_file", &c.Server.Webhooks.Sonarr.Secret, c.Server.Webhooks.Sonarr.SecretFile},
+		{"server.webhooks.tunarr.secret_file", &c.Server.Webhooks.Tunarr.Secret, c.Server.Webhooks.Tunarr.SecretFile},
+	}
+	for i := range c.Radarr {
+		key := fmt.Sprintf("radarr %s api_key_file", c.Radarr[i].Name)
+		secrets = append(secrets, secretFile{key, &c.Radarr[i].APIKey, c.Radarr[i].APIKeyFile})
+	}
+	for i := range c.Sonarr {
+		key := fmt.Sprintf("sonarr %s api_key_file", c.Sonarr[i].Name)
+		secrets = append(secrets, secretFile{key, &c.Sonarr[i].APIKey, c.Sonarr[i].APIKeyFile})
+	}
+
+	for i := range c.Notify.Targets {
+		key := fmt.Sprintf("notifications.targets[%d].token_file", i)
+		secrets = append(secrets, secretFile{key, &c.Notify.Targets[i].Token, c.Notify.Targets[i].TokenFile})
+	}
+
+	for _, s := range secrets {
+		if s.file == "" || *s.value != "" {
+			continue
+		}
+		value, err := readSecretFile(s.file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.key, err)
+		}
+		*s.value = value
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ThemesPath returns ThemesDir, resolved against the config file's directory when relative
+func (c *Config) ThemesPath() string {
+	if c.ThemesDir == "" {
+		return ""
+	}
+	if filepath.IsAbs(c.ThemesDir) || c.File == "" {
+		return filepath.Clean(c.ThemesDir)
+	}
+	return filepath.Join(filepath.Dir(c.File), c.ThemesDir)
+}
+
+// isThemeFile reports whether a file in themes_dir is loaded. Hidden files are skipped,
+// which also skips editor swap files and the ..data entries of Kubernetes ConfigMaps.
+func isThemeFile(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// themeSource is where a theme from themes_dir was defined
+type themeSource struct {
+	file string
+	path string // themes[i] within a file holding a list, empty for a single theme
+}
+
+// loadThemesDir appends the themes defined in dir to cfg.Themes, reading files in name
+// order. A file holds either a themes list, as in the main config, or a single theme.
+// Theme names must be unique across the config file and every theme file. Problems with
+// the themes are added to ve; only unreadable files are returned as errors.
+func loadThemesDir(cfg *Config, dir string, ve *ValidationError) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read themes_dir: %w", err)
+	}
+
+	sources := make(map[string]string, len(cfg.Themes))
+	for _, theme := range cfg.Themes {
+		sources[theme.Name] = "the config file"
+	}
+	cfg.themeSources = make([]themeSource, len(cfg.Themes))
+
+	var files []string
+	for _, entry := range entries {
+		if !isThemeFile(entry.Name()) {
+			continue
+		}
+		// Stat follows symlinks, which ConfigMap volumes use for every file
+		path := filepath.Join(dir, entry.Name())
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, path)
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		themes, list, err := readThemeFile(path, ve)
+		if err != nil {
+			return err
+		}
+		for j, theme := range themes {
+			src := themeSource{file: path}
+			if list {
+				src.path = fmt.Sprintf("themes[%d]", j)
+			}
+			if source, ok := sources[theme.Name]; ok && theme.Name != "" {
+				ve.add(src.file+": "+joinPath(src.path, "name"), "theme %s is already defined in %s", theme.Name, source)
+			}
+			sources[theme.Name] = path
+			cfg.themeSources = append(cfg.themeSources, src)
+		}
+		cfg.Themes = append(cfg.Themes, themes...)
+	}
+
+	return nil
+}
+
+// readThemeFile reads the themes defined in one file, and whether the file holds a list
+func readThemeFile(path string, ve *ValidationError) ([]ThemeConfig, bool, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, false, fmt.Errorf("error reading theme file: %w", err)
+	}
+
+	var themes []ThemeConfig
+	var err error
+	list := v.IsSet("themes")
+	if list {
+		err = v.UnmarshalKey("themes", &themes, strictDecoding)
+	} else {
+		var theme ThemeConfig
+		err = v.Unmarshal(&theme, strictDecoding)
+		themes = []ThemeConfig{theme}
+	}
+	if err != nil {
+		fieldErrs, ok := decodeErrors(err)
+		if !ok {
+			return nil, false, fmt.Errorf("error unmarshaling %s: %w", path, err)
+		}
+		for _, fe := range fieldErrs {
+			if list {
+				fe.Path = joinPath("themes", fe.Path)
+			}
+			ve.add(path+": "+fe.Path, "%s", fe.Message)
+		}
+	}
+	return themes, list, nil
+}
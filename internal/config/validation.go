@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// FieldError is a problem with one config setting
+type FieldError struct {
+	Path    string // YAML path such as themes[2].min_year, or a file for themes_dir themes
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError lists every problem found in a config, so they can all be fixed at once
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems:", len(e.Errors))
+	for _, fe := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(fe.Error())
+	}
+	return b.String()
+}
+
+// add records a problem at path
+func (e *ValidationError) add(path, format string, args ...any) {
+	e.Errors = append(e.Errors, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// merge records the problems in err, which is added whole when it is not a ValidationError
+func (e *ValidationError) merge(err error) {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		e.Errors = append(e.Errors, ve.Errors...)
+		return
+	}
+	e.Errors = append(e.Errors, FieldError{Message: err.Error()})
+}
+
+// err returns e, or nil when there are no problems
+func (e *ValidationError) err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// strictDecoding rejects keys that match no setting
+var strictDecoding = viper.DecoderConfigOption(func(dc *mapstructure.DecoderConfig) {
+	dc.ErrorUnused = true
+})
+
+// decodeErrors converts the errors from decoding settings, such as unknown keys and type
+// mismatches, to field errors. It returns false for any other error.
+func decodeErrors(err error) ([]FieldError, bool) {
+	var me *mapstructure.Error
+	if !errors.As(err, &me) {
+		return nil, false
+	}
+
+	var out []FieldError
+	for _, msg := range me.Errors {
+		// Messages quote the path first, e.g. "'themes[0]' has invalid keys: colour"
+		var path, rest string
+		if _, after, ok := strings.Cut(msg, "'"); ok {
+			path, rest, _ = strings.Cut(after, "'")
+		}
+
+		if keys, ok := strings.CutPrefix(rest, " has invalid keys: "); ok {
+			for _, key := range strings.Split(keys, ", ") {
+				out = append(out, FieldError{Path: joinPath(path, key), Message: "unknown key"})
+			}
+			continue
+		}
+		// Drop the quoted path, e.g. "cannot parse 'cooldown.movie_days' as int"
+		message := strings.Replace(msg, " '"+path+"'", "", 1)
+		if message == msg {
+			message = strings.TrimPrefix(msg, "'"+path+"' ")
+		}
+		out = append(out, FieldError{Path: path, Message: message})
+	}
+	return out, true
+}
+
+// joinPath joins YAML path elements, either of which may be empty
+func joinPath(parent, child string) string {
+	switch {
+	case parent == "":
+		return child
+	case child == "":
+		return parent
+	case strings.HasPrefix(child, "["):
+		return parent + child
+	default:
+		return parent + "." + child
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"io/fs"
 	"log/slog"
+	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/geekxflood/program-director/internal/config"
 )
@@ -23,12 +25,18 @@ type DB interface {
 	Close() error
 	Ping(ctx context.Context) error
 
+	// Driver returns the driver name ("postgres" or "sqlite"), for the few queries that differ
+	Driver() string
+
+	// Stats returns the connection pool statistics
+	Stats() sql.DBStats
+
 	// Transaction support
 	BeginTx(ctx context.Context) (Tx, error)
 
 	// Query operations
-	Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
-	QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Query(ctx context.Context, query string, args ...interface{}) (*Rows, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) *Row
 	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 
 	// Migration
@@ -39,18 +47,35 @@ type DB interface {
 type Tx interface {
 	Commit() error
 	Rollback() error
-	Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
-	QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Query(ctx context.Context, query string, args ...interface{}) (*Rows, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) *Row
 	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
 
 // New creates a new database connection based on configuration
 func New(ctx context.Context, cfg *config.DatabaseConfig, logger *slog.Logger) (DB, error) {
+	limits := &queryLimits{
+		timeout: time.Duration(cfg.QueryTimeout) * time.Second,
+		slow:    time.Duration(cfg.SlowQueryMS) * time.Millisecond,
+		driver:  cfg.Driver,
+		logger:  logger,
+	}
+
 	switch cfg.Driver {
 	case "postgres":
-		return NewPostgres(ctx, &cfg.Postgres, logger)
+		db, err := NewPostgres(ctx, &cfg.Postgres, logger)
+		if err != nil {
+			return nil, err
+		}
+		db.limits = limits
+		return db, nil
 	case "sqlite":
-		return NewSQLite(ctx, &cfg.SQLite, logger)
+		db, err := NewSQLite(ctx, &cfg.SQLite, logger)
+		if err != nil {
+			return nil, err
+		}
+		db.limits = limits
+		return db, nil
 	default:
 		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
 	}
@@ -65,6 +90,7 @@ func loadMigrations(driver string) ([]Migration, error) {
 	}
 
 	migrations := make([]Migration, 0, len(entries))
+	downs := make(map[int]string)
 
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -76,9 +102,22 @@ func loadMigrations(driver string) ([]Migration, error) {
 			continue
 		}
 
+		// Driver-specific migrations are named 020_name.sqlite.sql / 020_name.postgres.sql
+		base := strings.TrimSuffix(name, ".sql")
+		if ext := path.Ext(base); ext == ".sqlite" || ext == ".postgres" {
+			if ext != "."+driver {
+				continue
+			}
+			base = strings.TrimSuffix(base, ext)
+		}
+
+		// Down migrations reverting them are named 020_name.down.sql / 020_name.down.sqlite.sql
+		down := path.Ext(base) == ".down"
+		base = strings.TrimSuffix(base, ".down")
+
 		// Parse migration version and name
 		// Expected format: 001_create_media_table.sql
-		parts := strings.SplitN(strings.TrimSuffix(name, ".sql"), "_", 2)
+		parts := strings.SplitN(base, "_", 2)
 		if len(parts) != 2 {
 			continue
 		}
@@ -97,6 +136,11 @@ func loadMigrations(driver string) ([]Migration, error) {
 		sql := string(content)
 		sql = adaptSQL(sql, driver)
 
+		if down {
+			downs[version] = sql
+			continue
+		}
+
 		migrations = append(migrations, Migration{
 			Version: version,
 			Name:    parts[1],
@@ -109,6 +153,14 @@ func loadMigrations(driver string) ([]Migration, error) {
 		return migrations[i].Version < migrations[j].Version
 	})
 
+	for i := range migrations {
+		migrations[i].DownSQL = downs[migrations[i].Version]
+		delete(downs, migrations[i].Version)
+	}
+	for version := range downs {
+		return nil, fmt.Errorf("down migration %03d has no matching migration", version)
+	}
+
 	return migrations, nil
 }
 
@@ -117,6 +169,7 @@ type Migration struct {
 	Version int
 	Name    string
 	SQL     string
+	DownSQL string // Reverts SQL; empty when the migration cannot be reverted
 }
 
 // adaptSQL converts PostgreSQL-specific SQL to SQLite where needed
@@ -209,3 +262,150 @@ func recordMigration(ctx context.Context, db DB, m Migration) error {
 	)
 	return err
 }
+
+// PendingMigrations returns the migrations not yet applied to db, in version order
+func PendingMigrations(ctx context.Context, db DB) ([]Migration, error) {
+	applied, err := getAppliedMigrations(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	migrations, err := loadMigrations(db.Driver())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// MigrationState is a migration and whether it is applied to a database
+type MigrationState struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	Unknown   bool // Applied to the database but not part of this build, e.g. by a newer release
+}
+
+// MigrationStatus returns every migration with whether it is applied to db, in version order
+func MigrationStatus(ctx context.Context, db DB) ([]MigrationState, error) {
+	migrations, err := loadMigrations(db.Driver())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	rows, err := db.Query(ctx, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	states := make(map[int]*MigrationState, len(migrations))
+	for _, m := range migrations {
+		states[m.Version] = &MigrationState{Migration: m}
+	}
+	for rows.Next() {
+		var (
+			version   int
+			name      string
+			appliedAt sql.NullTime
+		)
+		if err := rows.Scan(&version, &name, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+		}
+		state, ok := states[version]
+		if !ok {
+			state = &MigrationState{Migration: Migration{Version: version, Name: name}, Unknown: true}
+			states[version] = state
+		}
+		state.Applied = true
+		state.AppliedAt = appliedAt.Time
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	result := make([]MigrationState, 0, len(states))
+	for _, state := range states {
+		result = append(result, *state)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+// RevertibleMigrations returns the applied migrations of db newer than version to, newest
+// first, failing if any of them cannot be reverted
+func RevertibleMigrations(ctx context.Context, db DB, to int) ([]Migration, error) {
+	if to < 0 {
+		return nil, fmt.Errorf("invalid target version %d", to)
+	}
+	states, err := MigrationStatus(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var revert []Migration
+	for i := len(states) - 1; i >= 0; i-- {
+		state := states[i]
+		if !state.Applied || state.Version <= to {
+			continue
+		}
+		if state.Unknown {
+			return nil, fmt.Errorf("migration %03d_%s is not part of this build; revert it with the release that applied it", state.Version, state.Name)
+		}
+		if state.DownSQL == "" {
+			return nil, fmt.Errorf("migration %03d_%s has no down migration", state.Version, state.Name)
+		}
+		revert = append(revert, state.Migration)
+	}
+	return revert, nil
+}
+
+// MigrateDown reverts the applied migrations of db newer than version to, newest first, each
+// in its own transaction, and returns the reverted migrations. Nothing is reverted when one
+// of them has no down migration.
+func MigrateDown(ctx context.Context, db DB, to int) ([]Migration, error) {
+	ctx = WithoutQueryTimeout(ctx)
+	revert, err := RevertibleMigrations(ctx, db, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range revert {
+		if err := revertMigration(ctx, db, m); err != nil {
+			return revert[:i], err
+		}
+	}
+	return revert, nil
+}
+
+// revertMigration runs the down migration of m and forgets that m was applied
+func revertMigration(ctx context.Context, db DB, m Migration) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if _, err := tx.Exec(ctx, m.DownSQL); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("failed to revert migration %d: %w (rollback error: %w)", m.Version, err, rbErr)
+		}
+		return fmt.Errorf("failed to revert migration %d: %w", m.Version, err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", m.Version); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("failed to unrecord migration %d: %w (rollback error: %w)", m.Version, err, rbErr)
+		}
+		return fmt.Errorf("failed to unrecord migration %d: %w", m.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit revert of migration %d: %w", m.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestLoadMigrationsDriverSpecific(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+		reject string
+	}{
+		{"sqlite", "fts5", "tsvector"},
+		{"postgres", "tsvector", "fts5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.driver)
+			if err != nil {
+				t.Fatalf("loadMigrations() error = %v", err)
+			}
+
+			seen := make(map[int]bool)
+			var search *Migration
+			for i, m := range migrations {
+				if seen[m.Version] {
+					t.Errorf("duplicate migration version %d", m.Version)
+				}
+				seen[m.Version] = true
+				if m.Name == "create_media_search_index" {
+					search = &migrations[i]
+				}
+			}
+
+			if search == nil {
+				t.Fatal("create_media_search_index migration not loaded")
+			}
+			if !strings.Contains(search.SQL, tt.want) || strings.Contains(search.SQL, tt.reject) {
+				t.Errorf("%s got the wrong search index migration:\n%s", tt.driver, search.SQL)
+			}
+		})
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := NewSQLite(ctx, &config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")}, logger)
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer db.Close()
+
+	if _, err := PendingMigrations(ctx, db); err == nil {
+		t.Error("PendingMigrations() on an uninitialized database: want error")
+	}
+
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	pending, err := PendingMigrations(ctx, db)
+	if err != nil {
+		t.Fatalf("PendingMigrations() error = %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("PendingMigrations() after Migrate = %d, want 0", len(pending))
+	}
+
+	if _, err := db.Exec(ctx, "DELETE FROM schema_migrations WHERE version = (SELECT MAX(version) FROM schema_migrations)"); err != nil {
+		t.Fatal(err)
+	}
+	pending, err = PendingMigrations(ctx, db)
+	if err != nil {
+		t.Fatalf("PendingMigrations() error = %v", err)
+	}
+	if len(pending) != 1 {
+		t.Errorf("PendingMigrations() = %d, want 1", len(pending))
+	}
+}
+
+func TestLoadMigrationsDown(t *testing.T) {
+	for _, driver := range []string{"sqlite", "postgres"} {
+		migrations, err := loadMigrations(driver)
+		if err != nil {
+			t.Fatalf("loadMigrations(%s) error = %v", driver, err)
+		}
+		for _, m := range migrations {
+			if m.DownSQL == "" {
+				t.Errorf("%s: migration %03d_%s has no down migration", driver, m.Version, m.Name)
+			}
+			if strings.Contains(m.SQL, "Revert") {
+				t.Errorf("%s: migration %03d_%s loaded a down migration as its SQL", driver, m.Version, m.Name)
+			}
+		}
+	}
+}
+
+func TestMigrateDown(t *testing.T) {
+	ctx := context.Background()
+	db := newMigratedSQLite(t, "test.db")
+
+	states, err := MigrationStatus(ctx, db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() error = %v", err)
+	}
+	latest := states[len(states)-1].Version
+	for _, s := range states {
+		if !s.Applied || s.AppliedAt.IsZero() || s.Unknown {
+			t.Errorf("migration %d after Migrate = %+v, want applied", s.Version, s)
+		}
+	}
+
+	if _, err := MigrateDown(ctx, db, -1); err == nil {
+		t.Error("MigrateDown(-1): want error")
+	}
+
+	reverted, err := MigrateDown(ctx, db, latest-3)
+	if err != nil {
+		t.Fatalf("MigrateDown() error = %v", err)
+	}
+	if len(reverted) != 3 || reverted[0].Version != latest {
+		t.Errorf("MigrateDown() reverted %+v, want the 3 newest, newest first", reverted)
+	}
+	pending, err := PendingMigrations(ctx, db)
+	if err != nil || len(pending) != 3 {
+		t.Errorf("PendingMigrations() after MigrateDown = %d (%v), want 3", len(pending), err)
+	}
+
+	// Every down migration runs, and the schema can be rebuilt afterwards
+	if _, err := MigrateDown(ctx, db, 0); err != nil {
+		t.Fatalf("MigrateDown(0) error = %v", err)
+	}
+	var tables int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')").Scan(&tables); err != nil || tables != 0 {
+		t.Errorf("tables left after MigrateDown(0) = %d (%v), want 0", tables, err)
+	}
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() after MigrateDown(0) error = %v", err)
+	}
+
+	// A migration applied by a newer release cannot be reverted by this build
+	if _, err := db.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, 'from_the_future')", latest+1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateDown(ctx, db, latest); err == nil || !strings.Contains(err.Error(), "not part of this build") {
+		t.Errorf("MigrateDown() past an unknown migration error = %v", err)
+	}
+}
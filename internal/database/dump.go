@@ -0,0 +1,407 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// DumpFormat identifies program-director catalog dumps
+const DumpFormat = "program-director-dump"
+
+// DumpVersion is the version of the dump layout written by Export
+const DumpVersion = 1
+
+// DumpHeader is the first line of a dump
+type DumpHeader struct {
+	Format        string    `json:"format"`
+	Version       int       `json:"version"`
+	Driver        string    `json:"driver"`         // Database the dump was exported from
+	SchemaVersion int       `json:"schema_version"` // Latest migration applied to it
+	ExportedAt    time.Time `json:"exported_at"`
+}
+
+// TableCount is the number of rows exported or imported for a table
+type TableCount struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+}
+
+// dumpRecord is one table row of a dump
+type dumpRecord struct {
+	Table string                     `json:"table"`
+	Row   map[string]json.RawMessage `json:"row"`
+}
+
+// columnKind is how a dumped column is scanned and written, so values survive moving
+// between SQLite and PostgreSQL
+type columnKind int
+
+const (
+	kindInt columnKind = iota
+	kindFloat
+	kindText
+	kindBool
+	kindTime
+	kindJSON // JSON text, dumped as the JSON value itself
+)
+
+type dumpColumn struct {
+	name string
+	kind columnKind
+}
+
+type dumpTable struct {
+	name    string
+	columns []dumpColumn
+}
+
+// dumpTables are the tables in a dump, parents before the tables referencing them. Data
+// that the next sync rebuilds (collections, lists, checkpoints) is left out, as are LLM usage
+// and traces, which only matter for recent runs.
+var dumpTables = []dumpTable{
+	{"media", []dumpColumn{
+		{"id", kindInt}, {"external_id", kindInt}, {"source", kindText}, {"source_instance", kindText},
+		{"media_type", kindText}, {"title", kindText}, {"year", kindInt}, {"overview", kindText},
+		{"runtime", kindInt}, {"genres", kindJSON}, {"keywords", kindJSON}, {"tags", kindJSON},
+		{"countries", kindJSON}, {"certification", kindText}, {"original_language", kindText},
+		{"imdb_rating", kindFloat}, {"tmdb_rating", kindFloat}, {"popularity", kindFloat},
+		{"imdb_id", kindText}, {"tmdb_id", kindInt}, {"tvdb_id", kindInt}, {"collection_tmdb_id", kindInt},
+		{"path", kindText}, {"has_file", kindBool}, {"size_on_disk", kindInt}, {"resolution", kindInt},
+		{"quality", kindText}, {"status", kindText}, {"monitored", kindBool},
+		{"poster_url", kindText}, {"fanart_url", kindText}, {"added_at", kindTime}, {"enriched_at", kindTime},
+		{"synced_at", kindTime}, {"created_at", kindTime}, {"updated_at", kindTime},
+	}},
+	{"play_history", []dumpColumn{
+		{"id", kindInt}, {"media_id", kindInt}, {"channel_id", kindText}, {"theme_name", kindText},
+		{"played_at", kindTime}, {"media_title", kindText}, {"media_type", kindText}, {"source", kindText},
+	}},
+	{"watch_history", []dumpColumn{
+		{"id", kindInt}, {"media_id", kindInt}, {"source", kindText}, {"external_id", kindText},
+		{"title", kindText}, {"media_type", kindText}, {"user_name", kindText}, {"watched_at", kindTime},
+		{"created_at", kindTime},
+	}},
+	{"media_cooldowns", []dumpColumn{
+		{"id", kindInt}, {"media_id", kindInt}, {"cooldown_days", kindInt}, {"last_played_at", kindTime},
+		{"can_replay_at", kindTime}, {"media_title", kindText}, {"media_type", kindText},
+	}},
+	{"media_blocklist", []dumpColumn{
+		{"id", kindInt}, {"media_id", kindInt}, {"imdb_id", kindText}, {"reason", kindText}, {"created_at", kindTime},
+	}},
+	{"channel_snapshots", []dumpColumn{
+		{"id", kindInt}, {"channel_id", kindText}, {"theme_name", kindText}, {"programming", kindJSON},
+		{"program_count", kindInt}, {"lineup_hash", kindText}, {"created_at", kindTime}, {"restored_at", kindTime},
+	}},
+	{"lineup_items", []dumpColumn{
+		{"id", kindInt}, {"channel_id", kindText}, {"theme_name", kindText}, {"position", kindInt},
+		{"media_id", kindInt}, {"title", kindText}, {"year", kindInt}, {"media_type", kindText},
+		{"runtime", kindInt}, {"airs_at", kindTime}, {"applied_at", kindTime},
+	}},
+	{"generations", []dumpColumn{
+		{"id", kindInt}, {"theme_name", kindText}, {"channel_id", kindText}, {"generated", kindBool},
+		{"dry_run", kindBool}, {"item_count", kindInt}, {"total_score", kindFloat}, {"duration_ms", kindInt},
+		{"error", kindText}, {"skip_reason", kindText}, {"attempts", kindInt}, {"degraded", kindText},
+		{"created_at", kindTime},
+	}},
+	{"audit_log", []dumpColumn{
+		{"id", kindInt}, {"actor_type", kindText}, {"actor", kindText}, {"action", kindText},
+		{"theme_name", kindText}, {"parameters", kindJSON}, {"request_id", kindText}, {"created_at", kindTime},
+	}},
+	{"api_keys", []dumpColumn{
+		{"id", kindInt}, {"name", kindText}, {"key_hash", kindText}, {"role", kindText},
+		{"created_at", kindTime}, {"last_used_at", kindTime},
+	}},
+}
+
+// Export writes a portable NDJSON dump of the catalog, play and watch history, cooldowns,
+// blocklist, channel snapshots and lineups, generation history, audit log, and API keys of db
+// to w: a DumpHeader line, then one line per row.
+func Export(ctx context.Context, db DB, w io.Writer) ([]TableCount, error) {
+	ctx = WithoutQueryTimeout(ctx)
+	schemaVersion, err := schemaVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := json.NewEncoder(w)
+	header := DumpHeader{
+		Format:        DumpFormat,
+		Version:       DumpVersion,
+		Driver:        db.Driver(),
+		SchemaVersion: schemaVersion,
+		ExportedAt:    time.Now().UTC(),
+	}
+	if err := enc.Encode(header); err != nil {
+		return nil, fmt.Errorf("failed to write dump header: %w", err)
+	}
+
+	counts := make([]TableCount, 0, len(dumpTables))
+	for _, table := range dumpTables {
+		count, err := exportTable(ctx, db, enc, table)
+		if err != nil {
+			return counts, fmt.Errorf("failed to export %s: %w", table.name, err)
+		}
+		counts = append(counts, TableCount{Table: table.name, Rows: count})
+	}
+	return counts, nil
+}
+
+// exportTable writes every row of table, in id order
+func exportTable(ctx context.Context, db DB, enc *json.Encoder, table dumpTable) (int64, error) {
+	names := make([]string, len(table.columns))
+	for i, col := range table.columns {
+		names[i] = col.name
+	}
+	rows, err := db.Query(ctx, fmt.Sprintf("SELECT %s FROM %s ORDER BY id", strings.Join(names, ", "), table.name))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var count int64
+	for rows.Next() {
+		values := make([]any, len(table.columns))
+		for i, col := range table.columns {
+			values[i] = col.kind.scanTarget()
+		}
+		if err := rows.Scan(values...); err != nil {
+			return count, err
+		}
+
+		record := dumpRecord{Table: table.name, Row: make(map[string]json.RawMessage, len(values))}
+		for i, col := range table.columns {
+			raw, err := col.kind.encode(values[i])
+			if err != nil {
+				return count, fmt.Errorf("column %s: %w", col.name, err)
+			}
+			record.Row[col.name] = raw
+		}
+		if err := enc.Encode(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// Import loads a dump written by Export into db, keeping row IDs so history and cooldowns
+// still point at their media. The dumped tables must be empty; all rows are inserted in
+// one transaction. Dumps of a newer schema than db's are rejected.
+func Import(ctx context.Context, db DB, r io.Reader) ([]TableCount, error) {
+	ctx = WithoutQueryTimeout(ctx)
+	dec := json.NewDecoder(r)
+	var header DumpHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to read dump header: %w", err)
+	}
+	if header.Format != DumpFormat {
+		return nil, fmt.Errorf("not a program-director dump (format %q)", header.Format)
+	}
+	if header.Version != DumpVersion {
+		return nil, fmt.Errorf("unsupported dump version %d (this build reads version %d)", header.Version, DumpVersion)
+	}
+	current, err := schemaVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if header.SchemaVersion > current {
+		return nil, fmt.Errorf("dump has schema version %d, newer than this database's %d; upgrade and migrate first", header.SchemaVersion, current)
+	}
+
+	tables := make(map[string]dumpTable, len(dumpTables))
+	for _, table := range dumpTables {
+		var count int64
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM "+table.name).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table.name, err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("database is not empty: %s has %d rows", table.name, count)
+		}
+		tables[table.name] = table
+	}
+
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	imported := make(map[string]int64, len(dumpTables))
+	for line := 2; ; line++ {
+		var record dumpRecord
+		if err := dec.Decode(&record); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read dump line %d: %w", line, err)
+		}
+		table, ok := tables[record.Table]
+		if !ok {
+			return nil, fmt.Errorf("dump line %d: unknown table %q", line, record.Table)
+		}
+		if err := importRow(ctx, tx, table, record.Row); err != nil {
+			return nil, fmt.Errorf("dump line %d: failed to import %s row: %w", line, table.name, err)
+		}
+		imported[table.name]++
+	}
+
+	if db.Driver() == "postgres" {
+		// Explicit IDs do not advance the sequences behind BIGSERIAL columns
+		for _, table := range dumpTables {
+			query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %[1]s", table.name)
+			if _, err := tx.Exec(ctx, query); err != nil {
+				return nil, fmt.Errorf("failed to reset %s id sequence: %w", table.name, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	counts := make([]TableCount, 0, len(dumpTables))
+	for _, table := range dumpTables {
+		counts = append(counts, TableCount{Table: table.name, Rows: imported[table.name]})
+	}
+	return counts, nil
+}
+
+// importRow inserts the columns present in row; absent columns take their defaults
+func importRow(ctx context.Context, tx Tx, table dumpTable, row map[string]json.RawMessage) error {
+	names := make([]string, 0, len(row))
+	args := make([]any, 0, len(row))
+	for _, col := range table.columns {
+		raw, ok := row[col.name]
+		if !ok {
+			continue
+		}
+		value, err := col.kind.decode(raw)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", col.name, err)
+		}
+		names = append(names, col.name)
+		args = append(args, value)
+	}
+	if len(names) != len(row) {
+		for name := range row {
+			if !table.hasColumn(name) {
+				return fmt.Errorf("unknown column %q", name)
+			}
+		}
+	}
+
+	marks := make([]string, len(names))
+	for i := range marks {
+		marks[i] = fmt.Sprintf("$%d", i+1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table.name, strings.Join(names, ", "), strings.Join(marks, ", "))
+	_, err := tx.Exec(ctx, query, args...)
+	return err
+}
+
+func (t dumpTable) hasColumn(name string) bool {
+	for _, col := range t.columns {
+		if col.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// scanTarget returns a nullable value to scan a column of this kind into
+func (k columnKind) scanTarget() any {
+	switch k {
+	case kindInt:
+		return new(sql.NullInt64)
+	case kindFloat:
+		return new(sql.NullFloat64)
+	case kindBool:
+		return new(sql.NullBool)
+	case kindTime:
+		return new(sql.NullTime)
+	default:
+		return new(sql.NullString)
+	}
+}
+
+// encode converts a scanned value to its dumped JSON, null for NULL. JSON columns holding
+// invalid JSON are dumped as null.
+func (k columnKind) encode(v any) (json.RawMessage, error) {
+	var value any
+	switch v := v.(type) {
+	case *sql.NullInt64:
+		if v.Valid {
+			value = v.Int64
+		}
+	case *sql.NullFloat64:
+		if v.Valid {
+			value = v.Float64
+		}
+	case *sql.NullBool:
+		if v.Valid {
+			value = v.Bool
+		}
+	case *sql.NullTime:
+		if v.Valid {
+			value = v.Time.UTC()
+		}
+	case *sql.NullString:
+		if !v.Valid {
+			break
+		}
+		if k == kindJSON {
+			if json.Valid([]byte(v.String)) {
+				return json.RawMessage(v.String), nil
+			}
+			break
+		}
+		value = v.String
+	}
+	return json.Marshal(value)
+}
+
+// decode converts a dumped JSON value to a query argument, nil for null
+func (k columnKind) decode(raw json.RawMessage) (any, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+	var err error
+	switch k {
+	case kindInt:
+		var v int64
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case kindFloat:
+		var v float64
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case kindBool:
+		var v bool
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case kindTime:
+		var v time.Time
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case kindJSON:
+		// Stored as text so SQLite keeps it queryable as JSON
+		return string(raw), nil
+	default:
+		var v string
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	}
+}
+
+// schemaVersion returns the latest migration applied to db
+func schemaVersion(ctx context.Context, db DB) (int, error) {
+	var version sql.NullInt64
+	if err := db.QueryRow(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
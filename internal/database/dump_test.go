@@ -0,0 +1,153 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// newMigratedSQLite returns a migrated SQLite database in a temporary directory
+func newMigratedSQLite(t *testing.T, name string) *SQLiteDB {
+	t.Helper()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := NewSQLite(ctx, &config.SQLiteConfig{Path: filepath.Join(t.TempDir(), name)}, logger)
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return db
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	source := newMigratedSQLite(t, "source.db")
+
+	playedAt := time.Date(2026, 3, 14, 20, 0, 0, 0, time.UTC)
+	seed := []struct {
+		query string
+		args  []any
+	}{
+		{`INSERT INTO media (id, external_id, source, media_type, title, year, genres, imdb_rating, has_file, monitored, enriched_at)
+			VALUES (7, 70, 'radarr', 'movie', 'Chinatown', 1974, '["Crime","Mystery"]', 8.1, true, false, NULL)`, nil},
+		{`INSERT INTO media (id, external_id, source, media_type, title, genres, has_file) VALUES (9, 90, 'sonarr', 'series', 'Twin Peaks', 'not json', false)`, nil},
+		{"INSERT INTO play_history (media_id, channel_id, theme_name, played_at, media_title, media_type, source) VALUES (7, 'ch-1', 'noir', $1, 'Chinatown', 'movie', 'plex')", []any{playedAt}},
+		{"INSERT INTO media_cooldowns (media_id, cooldown_days, last_played_at, can_replay_at, media_title, media_type) VALUES (7, 30, $1, $2, 'Chinatown', 'movie')", []any{playedAt, playedAt.AddDate(0, 0, 30)}},
+		{"INSERT INTO media_blocklist (imdb_id, reason) VALUES ('tt0071315', 'seen it')", nil},
+		{`INSERT INTO channel_snapshots (channel_id, theme_name, programming, program_count) VALUES ('ch-1', 'noir', '{"programs":[{"id":"a"}]}', 1)`, nil},
+		{"INSERT INTO lineup_items (channel_id, theme_name, position, media_id, title, year, media_type, runtime, airs_at, applied_at) VALUES ('ch-1', 'noir', 0, 7, 'Chinatown', 1974, 'movie', 130, $1, $2)", []any{playedAt, playedAt}},
+		{"INSERT INTO generations (theme_name, channel_id, generated, item_count, total_score, duration_ms, attempts, degraded) VALUES ('noir', 'ch-1', true, 1, 0.8, 1200, 2, 'ordering')", nil},
+		{`INSERT INTO audit_log (actor_type, actor, action, theme_name, parameters) VALUES ('api_key', 'ops', 'generate', 'noir', '{"dry_run":false}')`, nil},
+		{"INSERT INTO api_keys (name, key_hash, role, last_used_at) VALUES ('ops', 'abc123', 'operator', $1)", []any{playedAt}},
+	}
+	for _, s := range seed {
+		if _, err := source.Exec(ctx, s.query, s.args...); err != nil {
+			t.Fatalf("seed %q: %v", s.query, err)
+		}
+	}
+
+	var dump bytes.Buffer
+	exported, err := Export(ctx, source, &dump)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	want := map[string]int64{
+		"media": 2, "play_history": 1, "watch_history": 0, "media_cooldowns": 1, "media_blocklist": 1, "channel_snapshots": 1,
+		"lineup_items": 1, "generations": 1, "audit_log": 1, "api_keys": 1,
+	}
+	for _, c := range exported {
+		if c.Rows != want[c.Table] {
+			t.Errorf("exported %d %s rows, want %d", c.Rows, c.Table, want[c.Table])
+		}
+	}
+
+	target := newMigratedSQLite(t, "target.db")
+	imported, err := Import(ctx, target, bytes.NewReader(dump.Bytes()))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	for i, c := range imported {
+		if c != exported[i] {
+			t.Errorf("imported %+v, want %+v", c, exported[i])
+		}
+	}
+
+	// A second export matches the first apart from the header
+	var again bytes.Buffer
+	if _, err := Export(ctx, target, &again); err != nil {
+		t.Fatalf("Export() of the import error = %v", err)
+	}
+	body := func(b bytes.Buffer) string {
+		_, rest, _ := strings.Cut(b.String(), "\n")
+		return rest
+	}
+	if body(dump) != body(again) {
+		t.Errorf("round trip changed the dump:\n%s\nwant:\n%s", body(again), body(dump))
+	}
+	if !strings.Contains(dump.String(), `"genres":["Crime","Mystery"]`) || !strings.Contains(dump.String(), `"has_file":true`) {
+		t.Errorf("dump lost JSON or boolean values:\n%s", dump.String())
+	}
+
+	var role, keyHash string
+	if err := target.QueryRow(ctx, "SELECT role, key_hash FROM api_keys WHERE name = 'ops'").Scan(&role, &keyHash); err != nil || role != "operator" || keyHash != "abc123" {
+		t.Errorf("imported API key role %q, hash %q (%v), want operator and abc123", role, keyHash, err)
+	}
+
+	// Triggers keep derived tables in sync with imported media
+	var genres int
+	if err := target.QueryRow(ctx, "SELECT COUNT(*) FROM media_genres WHERE media_id = 7").Scan(&genres); err != nil || genres != 2 {
+		t.Errorf("media_genres for imported media = %d (%v), want 2", genres, err)
+	}
+
+	// New rows get IDs after the imported ones
+	var id int64
+	if err := target.QueryRow(ctx, "INSERT INTO media (external_id, source, media_type, title) VALUES (1, 'radarr', 'movie', 'Heat') RETURNING id").Scan(&id); err != nil || id != 10 {
+		t.Errorf("next media id = %d (%v), want 10", id, err)
+	}
+
+	if _, err := Import(ctx, target, bytes.NewReader(dump.Bytes())); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("Import() into a non-empty database error = %v, want not empty", err)
+	}
+}
+
+func TestImportRejectsInvalidDumps(t *testing.T) {
+	header := `{"format":"program-director-dump","version":1,"driver":"sqlite","schema_version":25,"exported_at":"2026-01-01T00:00:00Z"}` + "\n"
+
+	tests := []struct {
+		name string
+		dump string
+		want string
+	}{
+		{"empty", "", "header"},
+		{"other format", `{"format":"pg_dump","version":1}` + "\n", "not a program-director dump"},
+		{"newer version", `{"format":"program-director-dump","version":99}` + "\n", "unsupported dump version"},
+		{"newer schema", `{"format":"program-director-dump","version":1,"schema_version":999}` + "\n", "newer than this database"},
+		{"unknown table", header + `{"table":"users","row":{"id":1}}` + "\n", "unknown table"},
+		{"unknown column", header + `{"table":"media_blocklist","row":{"id":1,"color":"red"}}` + "\n", "unknown column"},
+		{"wrong type", header + `{"table":"media_blocklist","row":{"id":"one"}}` + "\n", "column id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newMigratedSQLite(t, "test.db")
+			_, err := Import(context.Background(), db, strings.NewReader(tt.dump))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Import() error = %v, want %q", err, tt.want)
+			}
+			var count int
+			if err := db.QueryRow(context.Background(), "SELECT COUNT(*) FROM media_blocklist").Scan(&count); err != nil || count != 0 {
+				t.Errorf("rows left after a failed import = %d (%v)", count, err)
+			}
+		})
+	}
+}
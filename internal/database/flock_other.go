@@ -0,0 +1,12 @@
+//go:build !unix
+
+package database
+
+import "os"
+
+// lockFile always succeeds where flock is not available, e.g. on Windows, so every instance
+// sharing the database file runs scheduled jobs, as without a leader lock. Run a single serve
+// instance per SQLite database there.
+func lockFile(_ *os.File) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// leaderLockKey is the PostgreSQL advisory lock key held by the instance running scheduled
+// jobs ("pdleader" in ASCII)
+const leaderLockKey int64 = 0x70646c6561646572
+
+// LeaderLock elects one of the serve instances sharing a database to run scheduled jobs
+type LeaderLock interface {
+	// TryAcquire takes the lock unless another process holds it, and reports whether this
+	// process holds it. Once taken, the lock is kept until Release or the process exits.
+	TryAcquire(ctx context.Context) (bool, error)
+	// Release gives up the lock so another instance can take it
+	Release() error
+}
+
+// Elector is implemented by databases that can elect a leader among the processes sharing
+// them
+type Elector interface {
+	LeaderLock() LeaderLock
+}
+
+// LeaderLock returns a lock backed by a session-level advisory lock. The lock holds one
+// connection of the pool for as long as it is held; when that connection is lost, so is the
+// lock, and the next TryAcquire competes for it again.
+func (p *PostgresDB) LeaderLock() LeaderLock {
+	return &advisoryLock{db: p.db, key: leaderLockKey}
+}
+
+// advisoryLock is a PostgreSQL advisory lock held on a dedicated connection
+type advisoryLock struct {
+	db  *sql.DB
+	key int64
+
+	mu   sync.Mutex
+	conn *sql.Conn // Non-nil while the lock is held
+}
+
+// TryAcquire implements LeaderLock
+func (l *advisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		// The session, and with it the lock, is gone
+		_ = l.conn.Close()
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection for leader lock: %w", err)
+	}
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return false, fmt.Errorf("failed to take leader lock: %w", err)
+	}
+	if !acquired {
+		return false, conn.Close()
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Release implements LeaderLock
+func (l *advisoryLock) Release() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	_, err := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", l.key)
+	if closeErr := l.conn.Close(); err == nil {
+		err = closeErr
+	}
+	l.conn = nil
+	if err != nil {
+		return fmt.Errorf("failed to release leader lock: %w", err)
+	}
+	return nil
+}
+
+// LeaderLock returns a lock backed by an exclusive flock on the database path with a .lock
+// suffix, shared by the instances using the database file from the same host or volume.
+// Where flock is not available every instance holds the lock.
+func (s *SQLiteDB) LeaderLock() LeaderLock {
+	return &fileLock{path: s.path + ".lock"}
+}
+
+// fileLock is an exclusive advisory lock on a file
+type fileLock struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File // Non-nil while the lock is held
+}
+
+// TryAcquire implements LeaderLock
+func (l *fileLock) TryAcquire(_ context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		return true, nil
+	}
+
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return false, fmt.Errorf("failed to open leader lock file: %w", err)
+	}
+	held, err := lockFile(file)
+	if err != nil || !held {
+		_ = file.Close()
+		if err != nil {
+			return false, fmt.Errorf("failed to take leader lock: %w", err)
+		}
+		return false, nil
+	}
+	l.file = file
+	return true, nil
+}
+
+// Release implements LeaderLock. The lock file is left in place, as removing it would race
+// with an instance opening it.
+func (l *fileLock) Release() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to release leader lock: %w", err)
+	}
+	return nil
+}
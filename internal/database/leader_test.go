@@ -0,0 +1,36 @@
+//go:build unix
+
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db.lock")
+	first := &fileLock{path: path}
+	second := &fileLock{path: path}
+
+	if held, err := first.TryAcquire(ctx); err != nil || !held {
+		t.Fatalf("first TryAcquire() = %v, %v, want true", held, err)
+	}
+	if held, err := first.TryAcquire(ctx); err != nil || !held {
+		t.Errorf("TryAcquire() by the holder = %v, %v, want true", held, err)
+	}
+	if held, err := second.TryAcquire(ctx); err != nil || held {
+		t.Errorf("second TryAcquire() while held = %v, %v, want false", held, err)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if held, err := second.TryAcquire(ctx); err != nil || !held {
+		t.Errorf("second TryAcquire() after release = %v, %v, want true", held, err)
+	}
+	if err := second.Release(); err != nil {
+		t.Errorf("Release() error = %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// maxLoggedQuery caps the length of a query in slow query logs
+const maxLoggedQuery = 500
+
+// noTimeoutKey marks contexts whose statements are exempt from the query timeout
+type noTimeoutKey struct{}
+
+// WithoutQueryTimeout exempts the statements run with ctx from the query timeout, for work
+// that is expected to take long, such as migrations and streaming exports
+func WithoutQueryTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTimeoutKey{}, true)
+}
+
+// queryLimits bounds statements with a timeout and logs the slow ones. A nil *queryLimits
+// applies no limits.
+type queryLimits struct {
+	timeout time.Duration // 0 disables the timeout
+	slow    time.Duration // 0 disables slow query logging
+	driver  string
+	logger  *slog.Logger
+}
+
+// start applies the timeout to ctx and returns a function to call when the statement
+// returns, which logs it when it was slow. Rows returned by Query and QueryRow are read after
+// the statement returns, so Rows and Row release the context instead; for them the logged
+// time is the time until the first row.
+func (l *queryLimits) start(ctx context.Context, query string, args []interface{}) (context.Context, context.CancelFunc, func(err error)) {
+	if l == nil {
+		return ctx, func() {}, func(error) {}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if l.timeout > 0 && ctx.Value(noTimeoutKey{}) == nil {
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+	}
+
+	begin := time.Now()
+	done := func(err error) {
+		elapsed := time.Since(begin)
+		if l.slow <= 0 || elapsed < l.slow {
+			return
+		}
+		attrs := []any{
+			"driver", l.driver,
+			"duration", elapsed,
+			"query", compactQuery(query),
+			"args", redactArgs(args),
+		}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		}
+		l.logger.WarnContext(ctx, "slow database query", attrs...)
+	}
+	return ctx, cancel, done
+}
+
+// Rows are the result of a Query. Closing them releases the statement's context, so callers
+// must close them even when they read every row.
+type Rows struct {
+	*sql.Rows
+	cancel context.CancelFunc
+}
+
+// newRows wraps the result of a query run with a context released by cancel
+func newRows(rows *sql.Rows, err error, cancel context.CancelFunc) (*Rows, error) {
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Rows{Rows: rows, cancel: cancel}, nil
+}
+
+// Close closes the rows and releases the statement's context
+func (r *Rows) Close() error {
+	defer r.cancel()
+	return r.Rows.Close()
+}
+
+// Row is the result of a QueryRow. Scanning it releases the statement's context.
+type Row struct {
+	*sql.Row
+	cancel context.CancelFunc
+}
+
+// Scan copies the row into dest and releases the statement's context
+func (r *Row) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return r.Row.Scan(dest...)
+}
+
+// compactQuery collapses the whitespace of a query and truncates it for logging
+func compactQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedQuery {
+		query = query[:maxLoggedQuery] + "..."
+	}
+	return query
+}
+
+// redactArgs describes query arguments by type only, so titles, paths, and keys stay out
+// of the logs
+func redactArgs(args []interface{}) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			redacted[i] = "NULL"
+		case string:
+			redacted[i] = fmt.Sprintf("string(%d)", len(v))
+		case []byte:
+			redacted[i] = fmt.Sprintf("bytes(%d)", len(v))
+		default:
+			redacted[i] = fmt.Sprintf("%T", arg)
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactArgs(t *testing.T) {
+	got := redactArgs([]interface{}{"The Thing", int64(7), nil, []byte("key"), time.Time{}})
+	want := []string{"string(9)", "int64", "NULL", "bytes(3)", "time.Time"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("redactArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestCompactQuery(t *testing.T) {
+	if got := compactQuery("\n\t\tSELECT id\n\t\tFROM media   WHERE 1=1\n"); got != "SELECT id FROM media WHERE 1=1" {
+		t.Errorf("compactQuery() = %q", got)
+	}
+	if got := compactQuery(strings.Repeat("x ", maxLoggedQuery)); len(got) != maxLoggedQuery+3 {
+		t.Errorf("compactQuery() of a long query = %d bytes, want %d", len(got), maxLoggedQuery+3)
+	}
+}
+
+func TestQueryLimitsStart(t *testing.T) {
+	var logs bytes.Buffer
+	limits := &queryLimits{
+		timeout: time.Minute,
+		slow:    10 * time.Millisecond,
+		driver:  "sqlite",
+		logger:  slog.New(slog.NewTextHandler(&logs, nil)),
+	}
+
+	tests := []struct {
+		name         string
+		limits       *queryLimits
+		ctx          context.Context
+		sleep        time.Duration
+		wantDeadline bool
+		wantLog      bool
+	}{
+		{"fast", limits, context.Background(), 0, true, false},
+		{"slow", limits, context.Background(), 20 * time.Millisecond, true, true},
+		{"exempt from the timeout", limits, WithoutQueryTimeout(context.Background()), 0, false, false},
+		{"no limits", nil, context.Background(), 20 * time.Millisecond, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			ctx, cancel, done := tt.limits.start(tt.ctx, "SELECT * FROM media WHERE title LIKE $1", []interface{}{"%secret%"})
+			defer cancel()
+			time.Sleep(tt.sleep)
+			done(nil)
+
+			if _, ok := ctx.Deadline(); ok != tt.wantDeadline {
+				t.Errorf("deadline set = %t, want %t", ok, tt.wantDeadline)
+			}
+			if logged := strings.Contains(logs.String(), "slow database query"); logged != tt.wantLog {
+				t.Errorf("slow query logged = %t, want %t: %s", logged, tt.wantLog, logs.String())
+			}
+			if strings.Contains(logs.String(), "secret") {
+				t.Errorf("slow query log leaked an argument: %s", logs.String())
+			}
+		})
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	ctx := context.Background()
+	db := newMigratedSQLite(t, "test.db")
+	db.limits = &queryLimits{timeout: 50 * time.Millisecond, driver: "sqlite", logger: db.logger}
+
+	const slowQuery = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT COUNT(*) FROM (SELECT x FROM c LIMIT 1000000000)"
+	var count int64
+	err := db.QueryRow(ctx, slowQuery).Scan(&count)
+	if err == nil {
+		t.Fatal("QueryRow() of a slow query: want timeout error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) && !strings.Contains(err.Error(), "interrupt") {
+		t.Errorf("QueryRow() error = %v, want deadline exceeded", err)
+	}
+
+	// The database is usable again afterwards
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM media").Scan(&count); err != nil {
+		t.Errorf("QueryRow() after a timeout error = %v", err)
+	}
+}
+
+func TestRowsReleaseContext(t *testing.T) {
+	db := newMigratedSQLite(t, "test.db")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sqlRows, err := db.db.QueryContext(ctx, "SELECT COUNT(*) FROM media")
+	rows, err := newRows(sqlRows, err, cancel)
+	if err != nil {
+		t.Fatalf("newRows() error = %v", err)
+	}
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+	}
+	if ctx.Err() != nil {
+		t.Fatal("context released before the rows are closed")
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("context not released when the rows are closed")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	if rows, err := newRows(nil, errors.New("boom"), cancel); rows != nil || err == nil {
+		t.Errorf("newRows() of a failed query = %v, %v, want nil rows and the error", rows, err)
+	}
+	if ctx.Err() == nil {
+		t.Error("context not released when the query fails")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	row := &Row{Row: db.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM media"), cancel: cancel}
+	if err := row.Scan(&count); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("context not released when the row is scanned")
+	}
+}
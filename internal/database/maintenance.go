@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Maintainer is implemented by databases that need periodic maintenance. PostgreSQL does
+// not: autovacuum keeps it analyzed and compact.
+type Maintainer interface {
+	Maintain(ctx context.Context, vacuum bool) (*MaintenanceResult, error)
+}
+
+// MaintenanceResult reports what a maintenance run did
+type MaintenanceResult struct {
+	WAL          bool          // Whether the database is in WAL mode and was checkpointed
+	Checkpointed int           // WAL frames written back to the database file
+	Vacuumed     bool          // Whether the database file was rebuilt
+	SizeBefore   int64         // Database file size in bytes
+	SizeAfter    int64         // Database file size in bytes
+	Duration     time.Duration // Time the run took
+}
+
+// Maintain checkpoints and truncates the WAL, runs ANALYZE so the query planner has fresh
+// statistics, and, when vacuum is set, rebuilds the database file to reclaim free pages
+func (s *SQLiteDB) Maintain(ctx context.Context, vacuum bool) (*MaintenanceResult, error) {
+	ctx = WithoutQueryTimeout(ctx)
+	start := time.Now()
+	result := &MaintenanceResult{}
+
+	size, err := s.size(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.SizeBefore = size
+
+	if _, err := s.Exec(ctx, "ANALYZE"); err != nil {
+		return nil, fmt.Errorf("failed to analyze: %w", err)
+	}
+
+	if vacuum {
+		if _, err := s.Exec(ctx, "VACUUM"); err != nil {
+			return nil, fmt.Errorf("failed to vacuum: %w", err)
+		}
+		result.Vacuumed = true
+	}
+
+	// Checkpoint last, so the pages written by ANALYZE and VACUUM are included
+	wal, frames, err := s.checkpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.WAL = wal
+	result.Checkpointed = frames
+
+	if result.SizeAfter, err = s.size(ctx); err != nil {
+		return nil, err
+	}
+	result.Duration = time.Since(start)
+
+	s.logger.InfoContext(ctx, "sqlite maintenance completed",
+		"wal", result.WAL,
+		"checkpointed_frames", result.Checkpointed,
+		"vacuumed", result.Vacuumed,
+		"size_before", result.SizeBefore,
+		"size_after", result.SizeAfter,
+		"duration", result.Duration,
+	)
+	return result, nil
+}
+
+// checkpoint writes the WAL back into the database file and truncates it, returning false
+// when the database is not in WAL mode
+func (s *SQLiteDB) checkpoint(ctx context.Context) (bool, int, error) {
+	var busy, logFrames, checkpointed int
+	if err := s.QueryRow(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &logFrames, &checkpointed); err != nil {
+		return false, 0, fmt.Errorf("failed to checkpoint the wal: %w", err)
+	}
+	if logFrames < 0 {
+		return false, 0, nil
+	}
+	if busy != 0 {
+		return true, checkpointed, errors.New("failed to checkpoint the wal: database busy")
+	}
+	return true, checkpointed, nil
+}
+
+// size returns the size of the database file in bytes
+func (s *SQLiteDB) size(ctx context.Context) (int64, error) {
+	var pages, pageSize int64
+	if err := s.QueryRow(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := s.QueryRow(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
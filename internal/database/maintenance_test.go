@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSQLiteMaintain(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		wal     bool
+		vacuum  bool
+		wantWAL bool
+	}{
+		{"rollback journal", false, false, false},
+		{"wal", true, false, true},
+		{"wal with vacuum", true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newMigratedSQLite(t, "test.db")
+			if tt.wal {
+				if _, err := db.Exec(ctx, "PRAGMA journal_mode=WAL"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Leave free pages behind for VACUUM to reclaim
+			overview := strings.Repeat("x", 4096)
+			for i := 0; i < 50; i++ {
+				if _, err := db.Exec(ctx, "INSERT INTO media (external_id, source, media_type, title, overview) VALUES ($1, 'radarr', 'movie', 'title', $2)", i, overview); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := db.Exec(ctx, "DELETE FROM media"); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := db.Maintain(ctx, tt.vacuum)
+			if err != nil {
+				t.Fatalf("Maintain() error = %v", err)
+			}
+			if result.WAL != tt.wantWAL || result.Vacuumed != tt.vacuum {
+				t.Errorf("Maintain() = %+v, want wal %t, vacuumed %t", result, tt.wantWAL, tt.vacuum)
+			}
+			if tt.vacuum && result.SizeAfter >= result.SizeBefore {
+				t.Errorf("VACUUM did not shrink the database: %d -> %d bytes", result.SizeBefore, result.SizeAfter)
+			}
+			if !tt.vacuum && result.SizeAfter < result.SizeBefore {
+				t.Errorf("database shrank without VACUUM: %d -> %d bytes", result.SizeBefore, result.SizeAfter)
+			}
+
+			var stats int
+			if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM sqlite_stat1").Scan(&stats); err != nil || stats == 0 {
+				t.Errorf("ANALYZE left %d sqlite_stat1 rows (%v)", stats, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/geekxflood/program-director/internal/metrics"
+)
+
+// observe counts a statement, and its failure, in the database metrics. QueryRow errors only
+// surface on Scan, so those statements are counted without an error.
+func observe(driver, operation string, err error) {
+	metrics.DBQueries.WithLabelValues(driver, operation).Inc()
+	if err != nil {
+		metrics.DBQueryErrors.WithLabelValues(driver, operation).Inc()
+	}
+}
+
+var (
+	poolMaxOpenDesc = prometheus.NewDesc("program_director_db_max_open_connections",
+		"Maximum number of open database connections, 0 for unlimited", []string{"driver"}, nil)
+	poolOpenDesc = prometheus.NewDesc("program_director_db_open_connections",
+		"Number of open database connections by state (in_use or idle)", []string{"driver", "state"}, nil)
+	poolWaitCountDesc = prometheus.NewDesc("program_director_db_wait_count_total",
+		"Total number of connections waited for", []string{"driver"}, nil)
+	poolWaitDurationDesc = prometheus.NewDesc("program_director_db_wait_duration_seconds_total",
+		"Total time spent waiting for a connection", []string{"driver"}, nil)
+	poolClosedDesc = prometheus.NewDesc("program_director_db_closed_connections_total",
+		"Total number of connections closed by reason (max_idle, max_idle_time, or max_lifetime)", []string{"driver", "reason"}, nil)
+)
+
+// statsCollector reports the connection pool statistics of a database at scrape time
+type statsCollector struct {
+	db DB
+}
+
+// NewStatsCollector returns a collector of the connection pool statistics of db, labeled
+// with its driver
+func NewStatsCollector(db DB) prometheus.Collector {
+	return statsCollector{db: db}
+}
+
+// Describe implements prometheus.Collector
+func (c statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolMaxOpenDesc
+	ch <- poolOpenDesc
+	ch <- poolWaitCountDesc
+	ch <- poolWaitDurationDesc
+	ch <- poolClosedDesc
+}
+
+// Collect implements prometheus.Collector
+func (c statsCollector) Collect(ch chan<- prometheus.Metric) {
+	driver := c.db.Driver()
+	stats := c.db.Stats()
+
+	ch <- prometheus.MustNewConstMetric(poolMaxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections), driver)
+	ch <- prometheus.MustNewConstMetric(poolOpenDesc, prometheus.GaugeValue, float64(stats.InUse), driver, "in_use")
+	ch <- prometheus.MustNewConstMetric(poolOpenDesc, prometheus.GaugeValue, float64(stats.Idle), driver, "idle")
+	ch <- prometheus.MustNewConstMetric(poolWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount), driver)
+	ch <- prometheus.MustNewConstMetric(poolWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds(), driver)
+	ch <- prometheus.MustNewConstMetric(poolClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed), driver, "max_idle")
+	ch <- prometheus.MustNewConstMetric(poolClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), driver, "max_idle_time")
+	ch <- prometheus.MustNewConstMetric(poolClosedDesc, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), driver, "max_lifetime")
+}
+
+// Instrument wraps db so the statements run through it, including in its transactions, are
+// observed in program_director_db_query_duration_seconds under the repository label. As with
+// the slow query log, Query and QueryRow are timed until the first row.
+func Instrument(db DB, repository string) DB {
+	return &instrumentedDB{DB: db, repository: repository}
+}
+
+// instrumentedDB observes the statements of one repository
+type instrumentedDB struct {
+	DB
+	repository string
+}
+
+// observeRepository records a statement of a repository that started at begin
+func observeRepository(repository, operation string, begin time.Time, err error) {
+	metrics.DBQueryDuration.WithLabelValues(repository, operation, metrics.Status(err)).Observe(time.Since(begin).Seconds())
+}
+
+// BeginTx starts a new transaction whose statements are observed too
+func (d *instrumentedDB) BeginTx(ctx context.Context) (Tx, error) {
+	tx, err := d.DB.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedTx{Tx: tx, repository: d.repository}, nil
+}
+
+// Query executes a query that returns rows
+func (d *instrumentedDB) Query(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	begin := time.Now()
+	rows, err := d.DB.Query(ctx, query, args...)
+	observeRepository(d.repository, "query", begin, err)
+	return rows, err
+}
+
+// QueryRow executes a query that returns a single row
+func (d *instrumentedDB) QueryRow(ctx context.Context, query string, args ...interface{}) *Row {
+	begin := time.Now()
+	row := d.DB.QueryRow(ctx, query, args...)
+	observeRepository(d.repository, "query_row", begin, row.Err())
+	return row
+}
+
+// Exec executes a query that doesn't return rows
+func (d *instrumentedDB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	begin := time.Now()
+	result, err := d.DB.Exec(ctx, query, args...)
+	observeRepository(d.repository, "exec", begin, err)
+	return result, err
+}
+
+// instrumentedTx observes the statements of a transaction of one repository
+type instrumentedTx struct {
+	Tx
+	repository string
+}
+
+// Query executes a query that returns rows
+func (t *instrumentedTx) Query(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	begin := time.Now()
+	rows, err := t.Tx.Query(ctx, query, args...)
+	observeRepository(t.repository, "query", begin, err)
+	return rows, err
+}
+
+// QueryRow executes a query that is expected to return at most one row
+func (t *instrumentedTx) QueryRow(ctx context.Context, query string, args ...interface{}) *Row {
+	begin := time.Now()
+	row := t.Tx.QueryRow(ctx, query, args...)
+	observeRepository(t.repository, "query_row", begin, row.Err())
+	return row
+}
+
+// Exec executes a query without returning any rows
+func (t *instrumentedTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	begin := time.Now()
+	result, err := t.Tx.Exec(ctx, query, args...)
+	observeRepository(t.repository, "exec", begin, err)
+	return result, err
+}
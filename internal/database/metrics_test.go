@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/geekxflood/program-director/internal/metrics"
+)
+
+func TestInstrument(t *testing.T) {
+	ctx := context.Background()
+	db := Instrument(newMigratedSQLite(t, "test.db"), "test_repo")
+
+	if _, err := db.Exec(ctx, "INSERT INTO media (external_id, source, media_type, title) VALUES (1, 'radarr', 'movie', 'title')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(ctx, "SELECT * FROM missing_table"); err == nil {
+		t.Fatal("Exec() on a missing table succeeded")
+	}
+
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM media").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// exec/ok, exec/error, and query_row/ok from the transaction
+	for _, labels := range [][]string{
+		{"test_repo", "exec", "ok"},
+		{"test_repo", "exec", "error"},
+		{"test_repo", "query_row", "ok"},
+	} {
+		var m dto.Metric
+		if err := metrics.DBQueryDuration.WithLabelValues(labels...).(prometheus.Metric).Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetHistogram().GetSampleCount(); got != 1 {
+			t.Errorf("statements observed for %v = %d, want 1", labels, got)
+		}
+	}
+}
+
+func TestStatsCollector(t *testing.T) {
+	db := newMigratedSQLite(t, "test.db")
+
+	// max open, in use, idle, wait count, wait duration, and three closed reasons
+	if got := testutil.CollectAndCount(NewStatsCollector(db)); got != 8 {
+		t.Errorf("collected %d metrics, want 8", got)
+	}
+	if got := testutil.CollectAndCount(NewStatsCollector(db), "program_director_db_max_open_connections"); got != 1 {
+		t.Errorf("collected %d max open connection metrics, want 1", got)
+	}
+}
@@ -0,0 +1,2 @@
+-- Revert 001: drop the media catalog
+DROP TABLE IF EXISTS media;
@@ -0,0 +1,2 @@
+-- Revert 002: drop play history
+DROP TABLE IF EXISTS play_history;
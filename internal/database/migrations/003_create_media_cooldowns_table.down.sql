@@ -0,0 +1,2 @@
+-- Revert 003: drop media cooldowns
+DROP TABLE IF EXISTS media_cooldowns;
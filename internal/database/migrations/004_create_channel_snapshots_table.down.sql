@@ -0,0 +1,2 @@
+-- Revert 004: drop channel snapshots
+DROP TABLE IF EXISTS channel_snapshots;
@@ -0,0 +1,16 @@
+-- Channel lineup snapshots taken before programming is overwritten
+CREATE TABLE IF NOT EXISTS channel_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    channel_id TEXT NOT NULL,
+    theme_name TEXT NOT NULL,
+
+    -- Tunarr programming payload as captured before apply
+    programming JSONB NOT NULL,
+    program_count INTEGER DEFAULT 0,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    restored_at TIMESTAMP
+);
+
+-- Index for finding the latest snapshot of a channel
+CREATE INDEX IF NOT EXISTS idx_channel_snapshots_channel_created ON channel_snapshots(channel_id, created_at DESC);
@@ -0,0 +1,3 @@
+-- Revert 005: drop imported lists
+DROP TABLE IF EXISTS list_items;
+DROP TABLE IF EXISTS lists;
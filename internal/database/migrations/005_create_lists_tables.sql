@@ -0,0 +1,33 @@
+-- Static title lists imported from mdblist or IMDb exports
+CREATE TABLE IF NOT EXISTS lists (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    source TEXT NOT NULL,
+    url TEXT NOT NULL,
+    item_count INTEGER DEFAULT 0,
+
+    synced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lists_name ON lists(name);
+
+-- Entries of an imported list, matched against media by external IDs
+CREATE TABLE IF NOT EXISTS list_items (
+    id BIGSERIAL PRIMARY KEY,
+    list_id BIGINT NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+    rank INTEGER DEFAULT 0,
+    title TEXT,
+    year INTEGER,
+    media_type TEXT,
+
+    -- External IDs
+    imdb_id TEXT,
+    tmdb_id BIGINT,
+    tvdb_id BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_list_items_list_id ON list_items(list_id);
+CREATE INDEX IF NOT EXISTS idx_list_items_imdb_id ON list_items(imdb_id);
+CREATE INDEX IF NOT EXISTS idx_list_items_tmdb_id ON list_items(tmdb_id);
+CREATE INDEX IF NOT EXISTS idx_list_items_tvdb_id ON list_items(tvdb_id);
@@ -0,0 +1,7 @@
+-- Revert 006: drop TMDB enrichment columns
+DROP INDEX IF EXISTS idx_media_enriched_at;
+
+ALTER TABLE media DROP COLUMN enriched_at;
+ALTER TABLE media DROP COLUMN original_language;
+ALTER TABLE media DROP COLUMN certification;
+ALTER TABLE media DROP COLUMN keywords;
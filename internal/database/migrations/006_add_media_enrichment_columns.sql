@@ -0,0 +1,7 @@
+-- TMDB enrichment data not provided by Radarr/Sonarr
+ALTER TABLE media ADD COLUMN keywords JSONB DEFAULT '[]';
+ALTER TABLE media ADD COLUMN certification TEXT DEFAULT '';
+ALTER TABLE media ADD COLUMN original_language TEXT DEFAULT '';
+ALTER TABLE media ADD COLUMN enriched_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_media_enriched_at ON media(enriched_at);
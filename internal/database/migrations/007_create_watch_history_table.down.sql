@@ -0,0 +1,2 @@
+-- Revert 007: drop watch history
+DROP TABLE IF EXISTS watch_history;
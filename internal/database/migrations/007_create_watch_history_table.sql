@@ -0,0 +1,18 @@
+-- What the household actually watched, imported from Tautulli
+CREATE TABLE IF NOT EXISTS watch_history (
+    id BIGSERIAL PRIMARY KEY,
+    media_id BIGINT REFERENCES media(id) ON DELETE SET NULL,
+    source TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+
+    title TEXT NOT NULL,
+    media_type TEXT,
+    user_name TEXT,
+    watched_at TIMESTAMP NOT NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_watch_history_unique ON watch_history(source, external_id);
+CREATE INDEX IF NOT EXISTS idx_watch_history_media_watched ON watch_history(media_id, watched_at DESC);
+CREATE INDEX IF NOT EXISTS idx_watch_history_watched_at ON watch_history(watched_at DESC);
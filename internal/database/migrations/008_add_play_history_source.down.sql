@@ -0,0 +1,4 @@
+-- Revert 008: drop the play history source
+DROP INDEX IF EXISTS idx_play_history_source;
+
+ALTER TABLE play_history DROP COLUMN source;
@@ -0,0 +1,4 @@
+-- Distinguish lineup applications from airings reported by Plex
+ALTER TABLE play_history ADD COLUMN source TEXT NOT NULL DEFAULT 'lineup';
+
+CREATE INDEX IF NOT EXISTS idx_play_history_source ON play_history(source);
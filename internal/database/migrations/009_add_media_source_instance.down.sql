@@ -0,0 +1,5 @@
+-- Revert 009: external IDs are unique per source again, which fails if several instances share one
+DROP INDEX IF EXISTS idx_media_unique_instance;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_unique ON media(external_id, source);
+
+ALTER TABLE media DROP COLUMN source_instance;
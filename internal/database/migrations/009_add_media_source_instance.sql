@@ -0,0 +1,6 @@
+-- Track which Radarr/Sonarr instance media was synced from
+ALTER TABLE media ADD COLUMN source_instance TEXT NOT NULL DEFAULT 'default';
+
+-- External IDs are only unique within an instance
+DROP INDEX IF EXISTS idx_media_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_unique_instance ON media(external_id, source, source_instance);
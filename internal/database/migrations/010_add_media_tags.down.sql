@@ -0,0 +1,2 @@
+-- Revert 010: drop media tags
+ALTER TABLE media DROP COLUMN tags;
@@ -0,0 +1,2 @@
+-- Radarr/Sonarr tag labels, used by theme tag filters
+ALTER TABLE media ADD COLUMN tags JSONB DEFAULT '[]';
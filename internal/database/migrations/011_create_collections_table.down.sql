@@ -0,0 +1,6 @@
+-- Revert 011: drop collections and collection membership
+DROP INDEX IF EXISTS idx_media_collection;
+
+ALTER TABLE media DROP COLUMN collection_tmdb_id;
+
+DROP TABLE IF EXISTS collections;
@@ -0,0 +1,16 @@
+-- Radarr (TMDB) collections such as franchises and trilogies
+CREATE TABLE IF NOT EXISTS collections (
+    id BIGSERIAL PRIMARY KEY,
+    tmdb_id BIGINT NOT NULL,
+    title TEXT NOT NULL,
+
+    synced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_tmdb_id ON collections(tmdb_id);
+
+-- Collection membership, 0 when a movie is not part of a collection
+ALTER TABLE media ADD COLUMN collection_tmdb_id BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_media_collection ON media(collection_tmdb_id);
@@ -0,0 +1,3 @@
+-- Revert 012: drop file quality
+ALTER TABLE media DROP COLUMN quality;
+ALTER TABLE media DROP COLUMN resolution;
@@ -0,0 +1,3 @@
+-- File quality from Radarr, e.g. resolution 2160 and quality "Bluray-2160p"
+ALTER TABLE media ADD COLUMN resolution INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE media ADD COLUMN quality TEXT NOT NULL DEFAULT '';
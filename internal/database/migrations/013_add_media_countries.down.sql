@@ -0,0 +1,2 @@
+-- Revert 013: drop origin countries
+ALTER TABLE media DROP COLUMN countries;
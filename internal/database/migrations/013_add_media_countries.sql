@@ -0,0 +1,2 @@
+-- Origin countries (ISO 3166-1) from TMDB, used by theme country filters
+ALTER TABLE media ADD COLUMN countries JSONB DEFAULT '[]';
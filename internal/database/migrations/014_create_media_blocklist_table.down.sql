@@ -0,0 +1,2 @@
+-- Revert 014: drop the blocklist
+DROP TABLE IF EXISTS media_blocklist;
@@ -0,0 +1,12 @@
+-- Media that is never scheduled on any channel, blocked by library media ID or IMDB ID
+CREATE TABLE IF NOT EXISTS media_blocklist (
+    id BIGSERIAL PRIMARY KEY,
+    media_id BIGINT,
+    imdb_id TEXT,
+    reason TEXT NOT NULL DEFAULT '',
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_blocklist_media_id ON media_blocklist(media_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_blocklist_imdb_id ON media_blocklist(imdb_id);
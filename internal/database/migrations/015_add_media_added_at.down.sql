@@ -0,0 +1,4 @@
+-- Revert 015: drop library import times
+DROP INDEX IF EXISTS idx_media_added_at;
+
+ALTER TABLE media DROP COLUMN added_at;
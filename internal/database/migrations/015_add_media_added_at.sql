@@ -0,0 +1,4 @@
+-- When media was imported into Radarr/Sonarr, used to highlight fresh library additions
+ALTER TABLE media ADD COLUMN added_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_media_added_at ON media(added_at);
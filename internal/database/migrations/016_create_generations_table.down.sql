@@ -0,0 +1,2 @@
+-- Revert 016: drop the generation log
+DROP TABLE IF EXISTS generations;
@@ -0,0 +1,19 @@
+-- Every playlist generation run, for auditing what ran and when
+CREATE TABLE IF NOT EXISTS generations (
+    id BIGSERIAL PRIMARY KEY,
+    theme_name TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+
+    generated BOOLEAN NOT NULL DEFAULT FALSE,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    total_score REAL NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    skip_reason TEXT NOT NULL DEFAULT '',
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_generations_theme_created ON generations(theme_name, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_generations_created_at ON generations(created_at DESC);
@@ -0,0 +1,2 @@
+-- Revert 017: drop snapshot lineup hashes
+ALTER TABLE channel_snapshots DROP COLUMN lineup_hash;
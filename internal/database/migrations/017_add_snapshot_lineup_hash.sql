@@ -0,0 +1,2 @@
+-- Hash of the lineup applied right after the snapshot was taken, used to skip unchanged regenerations
+ALTER TABLE channel_snapshots ADD COLUMN lineup_hash TEXT;
@@ -0,0 +1,2 @@
+-- Revert 018: drop generation attempts
+ALTER TABLE generations DROP COLUMN attempts;
@@ -0,0 +1,2 @@
+-- Number of attempts a generation run took, more than 1 when transient failures were retried
+ALTER TABLE generations ADD COLUMN attempts INTEGER DEFAULT 1;
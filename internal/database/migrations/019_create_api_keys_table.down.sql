@@ -0,0 +1,2 @@
+-- Revert 019: drop stored API keys
+DROP TABLE IF EXISTS api_keys;
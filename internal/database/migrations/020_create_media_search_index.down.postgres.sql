@@ -0,0 +1,4 @@
+-- Revert 020: drop the full-text search vector
+DROP INDEX IF EXISTS idx_media_search_vector;
+
+ALTER TABLE media DROP COLUMN IF EXISTS search_vector;
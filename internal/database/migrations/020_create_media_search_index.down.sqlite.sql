@@ -0,0 +1,6 @@
+-- Revert 020: drop the full-text index and its triggers
+DROP TRIGGER IF EXISTS media_fts_insert;
+DROP TRIGGER IF EXISTS media_fts_delete;
+DROP TRIGGER IF EXISTS media_fts_update;
+
+DROP TABLE IF EXISTS media_fts;
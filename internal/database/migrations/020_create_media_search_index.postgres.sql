@@ -0,0 +1,8 @@
+-- Full-text search vector over media titles (weighted higher) and overviews
+ALTER TABLE media ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english'::regconfig, COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector('english'::regconfig, COALESCE(overview, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_media_search_vector ON media USING GIN (search_vector);
@@ -0,0 +1,22 @@
+-- Full-text index over media titles and overviews, kept in sync with media by triggers
+CREATE VIRTUAL TABLE IF NOT EXISTS media_fts USING fts5(
+    title,
+    overview,
+    content='media',
+    content_rowid='id'
+);
+
+CREATE TRIGGER IF NOT EXISTS media_fts_insert AFTER INSERT ON media BEGIN
+    INSERT INTO media_fts(rowid, title, overview) VALUES (new.id, new.title, new.overview);
+END;
+
+CREATE TRIGGER IF NOT EXISTS media_fts_delete AFTER DELETE ON media BEGIN
+    INSERT INTO media_fts(media_fts, rowid, title, overview) VALUES ('delete', old.id, old.title, old.overview);
+END;
+
+CREATE TRIGGER IF NOT EXISTS media_fts_update AFTER UPDATE OF title, overview ON media BEGIN
+    INSERT INTO media_fts(media_fts, rowid, title, overview) VALUES ('delete', old.id, old.title, old.overview);
+    INSERT INTO media_fts(rowid, title, overview) VALUES (new.id, new.title, new.overview);
+END;
+
+INSERT INTO media_fts(media_fts) VALUES ('rebuild');
@@ -0,0 +1,5 @@
+-- Revert 021: drop the genre index and its trigger
+DROP TRIGGER IF EXISTS media_genres_sync ON media;
+DROP FUNCTION IF EXISTS sync_media_genres();
+
+DROP TABLE IF EXISTS media_genres;
@@ -0,0 +1,6 @@
+-- Revert 021: drop the genre index and its triggers
+DROP TRIGGER IF EXISTS media_genres_insert;
+DROP TRIGGER IF EXISTS media_genres_update;
+DROP TRIGGER IF EXISTS media_genres_delete;
+
+DROP TABLE IF EXISTS media_genres;
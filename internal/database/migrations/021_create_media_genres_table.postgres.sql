@@ -0,0 +1,37 @@
+-- Lowercased genres per media, for exact indexed genre matching; kept in sync with media.genres by a trigger
+CREATE TABLE IF NOT EXISTS media_genres (
+    media_id BIGINT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    genre TEXT NOT NULL,
+    PRIMARY KEY (media_id, genre)
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_genres_genre ON media_genres(genre, media_id);
+
+CREATE OR REPLACE FUNCTION sync_media_genres() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.genres IS NOT DISTINCT FROM OLD.genres THEN
+            RETURN NEW;
+        END IF;
+        DELETE FROM media_genres WHERE media_id = NEW.id;
+    END IF;
+
+    IF jsonb_typeof(NEW.genres) = 'array' THEN
+        INSERT INTO media_genres (media_id, genre)
+        SELECT NEW.id, LOWER(g) FROM jsonb_array_elements_text(NEW.genres) AS g
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS media_genres_sync ON media;
+CREATE TRIGGER media_genres_sync AFTER INSERT OR UPDATE OF genres ON media
+    FOR EACH ROW EXECUTE FUNCTION sync_media_genres();
+
+INSERT INTO media_genres (media_id, genre)
+SELECT media.id, LOWER(g)
+FROM media, jsonb_array_elements_text(media.genres) AS g
+WHERE jsonb_typeof(media.genres) = 'array'
+ON CONFLICT DO NOTHING;
+
@@ -0,0 +1,27 @@
+-- Lowercased genres per media, for exact indexed genre matching; kept in sync with media.genres by triggers
+CREATE TABLE IF NOT EXISTS media_genres (
+    media_id INTEGER NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    genre TEXT NOT NULL,
+    PRIMARY KEY (media_id, genre)
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_genres_genre ON media_genres(genre, media_id);
+
+CREATE TRIGGER IF NOT EXISTS media_genres_insert AFTER INSERT ON media BEGIN
+    INSERT OR IGNORE INTO media_genres (media_id, genre)
+    SELECT new.id, LOWER(value) FROM json_each(CASE WHEN json_valid(new.genres) THEN new.genres ELSE '[]' END);
+END;
+
+CREATE TRIGGER IF NOT EXISTS media_genres_update AFTER UPDATE OF genres ON media BEGIN
+    DELETE FROM media_genres WHERE media_id = old.id;
+    INSERT OR IGNORE INTO media_genres (media_id, genre)
+    SELECT new.id, LOWER(value) FROM json_each(CASE WHEN json_valid(new.genres) THEN new.genres ELSE '[]' END);
+END;
+
+CREATE TRIGGER IF NOT EXISTS media_genres_delete AFTER DELETE ON media BEGIN
+    DELETE FROM media_genres WHERE media_id = old.id;
+END;
+
+INSERT OR IGNORE INTO media_genres (media_id, genre)
+SELECT media.id, LOWER(g.value)
+FROM media, json_each(CASE WHEN json_valid(media.genres) THEN media.genres ELSE '[]' END) AS g;
@@ -0,0 +1,2 @@
+-- Revert 022: drop sync checkpoints
+DROP TABLE IF EXISTS sync_checkpoints;
@@ -0,0 +1,8 @@
+-- Progress of a running media sync per source, so an interrupted sync can resume
+CREATE TABLE IF NOT EXISTS sync_checkpoints (
+    source TEXT PRIMARY KEY,
+    started_at TIMESTAMP NOT NULL,
+    stored INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- Revert 023: drop artwork URLs
+ALTER TABLE media DROP COLUMN fanart_url;
+ALTER TABLE media DROP COLUMN poster_url;
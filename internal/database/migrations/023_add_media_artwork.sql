@@ -0,0 +1,3 @@
+-- Poster and fanart image URLs from Radarr/Sonarr, for dashboards and channel art
+ALTER TABLE media ADD COLUMN poster_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN fanart_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Revert 024: drop LLM usage
+DROP TABLE IF EXISTS llm_usage;
@@ -0,0 +1,20 @@
+-- Ollama calls made by playlist generations, for seeing how heavy each theme is
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id BIGSERIAL PRIMARY KEY,
+    generation_id BIGINT REFERENCES generations(id) ON DELETE SET NULL,
+    theme_name TEXT NOT NULL,
+    purpose TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    eval_tokens INTEGER NOT NULL DEFAULT 0,
+    total_duration_ms BIGINT NOT NULL DEFAULT 0,
+    load_duration_ms BIGINT NOT NULL DEFAULT 0,
+    prompt_eval_duration_ms BIGINT NOT NULL DEFAULT 0,
+    eval_duration_ms BIGINT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_usage_theme_created ON llm_usage(theme_name, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_generation_id ON llm_usage(generation_id);
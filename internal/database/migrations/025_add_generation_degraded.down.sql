@@ -0,0 +1,2 @@
+-- Revert 025: drop degraded generation steps
+ALTER TABLE generations DROP COLUMN degraded;
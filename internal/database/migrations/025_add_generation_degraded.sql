@@ -0,0 +1,2 @@
+-- LLM steps of a run that fell back to another model or to heuristics
+ALTER TABLE generations ADD COLUMN degraded TEXT NOT NULL DEFAULT '';
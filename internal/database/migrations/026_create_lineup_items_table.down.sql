@@ -0,0 +1,2 @@
+-- Revert 026: drop applied lineups
+DROP TABLE IF EXISTS lineup_items;
@@ -0,0 +1,17 @@
+-- The lineup last applied to each channel, with the estimated air time of every title
+CREATE TABLE IF NOT EXISTS lineup_items (
+    id BIGSERIAL PRIMARY KEY,
+    channel_id TEXT NOT NULL,
+    theme_name TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    media_id BIGINT REFERENCES media(id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    year INTEGER NOT NULL DEFAULT 0,
+    media_type TEXT NOT NULL,
+    runtime INTEGER NOT NULL DEFAULT 0,
+    airs_at TIMESTAMP NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lineup_items_channel_position ON lineup_items(channel_id, position);
+CREATE INDEX IF NOT EXISTS idx_lineup_items_airs_at ON lineup_items(airs_at);
@@ -0,0 +1,2 @@
+-- Revert 027: drop the automation pause
+DROP TABLE IF EXISTS automation_pause;
@@ -0,0 +1,7 @@
+-- Whether automated actions (scheduled jobs, webhook-triggered refreshes) are paused, e.g.
+-- during Tunarr maintenance; the single row exists only while paused
+CREATE TABLE IF NOT EXISTS automation_pause (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    reason TEXT NOT NULL DEFAULT '',
+    paused_at TIMESTAMP NOT NULL
+);
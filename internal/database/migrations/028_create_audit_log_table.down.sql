@@ -0,0 +1,2 @@
+-- Revert 028: drop the audit log
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,2 @@
+-- Revert 029: drop API key roles
+ALTER TABLE api_keys DROP COLUMN role;
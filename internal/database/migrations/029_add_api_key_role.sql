@@ -0,0 +1,2 @@
+-- What each API key may do; keys created before roles keep full access
+ALTER TABLE api_keys ADD COLUMN role TEXT NOT NULL DEFAULT 'admin';
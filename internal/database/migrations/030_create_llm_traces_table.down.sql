@@ -0,0 +1,2 @@
+-- Revert 030: drop LLM traces
+DROP TABLE IF EXISTS llm_traces;
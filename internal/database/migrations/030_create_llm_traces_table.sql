@@ -0,0 +1,18 @@
+-- Exact prompts and raw responses of Ollama calls made by playlist generations, kept when
+-- llm.traces is enabled to see why a title was picked
+CREATE TABLE IF NOT EXISTS llm_traces (
+    id BIGSERIAL PRIMARY KEY,
+    generation_id BIGINT REFERENCES generations(id) ON DELETE SET NULL,
+    theme_name TEXT NOT NULL,
+    purpose TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    messages JSONB,
+    response TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_traces_generation_id ON llm_traces(generation_id);
+CREATE INDEX IF NOT EXISTS idx_llm_traces_theme_created ON llm_traces(theme_name, created_at DESC);
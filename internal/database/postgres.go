@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
@@ -15,6 +16,7 @@ import (
 type PostgresDB struct {
 	db     *sql.DB
 	logger *slog.Logger
+	limits *queryLimits // Set by New; nil applies no limits
 }
 
 // NewPostgres creates a new PostgreSQL connection
@@ -27,8 +29,9 @@ func NewPostgres(ctx context.Context, cfg *config.PostgresConfig, logger *slog.L
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
 
 	// Test connection
 	if err := db.PingContext(ctx); err != nil {
@@ -41,6 +44,9 @@ func NewPostgres(ctx context.Context, cfg *config.PostgresConfig, logger *slog.L
 	logger.Info("connected to PostgreSQL",
 		"host", cfg.Host,
 		"database", cfg.Database,
+		"max_open_conns", cfg.MaxOpenConns,
+		"max_idle_conns", cfg.MaxIdleConns,
+		"statement_timeout", cfg.StatementTimeout,
 	)
 
 	return &PostgresDB{
@@ -59,32 +65,56 @@ func (p *PostgresDB) Ping(ctx context.Context) error {
 	return p.db.PingContext(ctx)
 }
 
+// Driver returns "postgres"
+func (p *PostgresDB) Driver() string {
+	return "postgres"
+}
+
+// Stats returns the connection pool statistics
+func (p *PostgresDB) Stats() sql.DBStats {
+	return p.db.Stats()
+}
+
 // BeginTx starts a new transaction
 func (p *PostgresDB) BeginTx(ctx context.Context) (Tx, error) {
 	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	return &PostgresTx{tx: tx}, nil
+	return &PostgresTx{tx: tx, limits: p.limits}, nil
 }
 
 // Query executes a query that returns rows
-func (p *PostgresDB) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	return p.db.QueryContext(ctx, query, args...)
+func (p *PostgresDB) Query(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	ctx, cancel, done := p.limits.start(ctx, query, args)
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	done(err)
+	observe("postgres", "query", err)
+	return newRows(rows, err, cancel)
 }
 
 // QueryRow executes a query that returns a single row
-func (p *PostgresDB) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	return p.db.QueryRowContext(ctx, query, args...)
+func (p *PostgresDB) QueryRow(ctx context.Context, query string, args ...interface{}) *Row {
+	ctx, cancel, done := p.limits.start(ctx, query, args)
+	row := p.db.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	observe("postgres", "query_row", nil)
+	return &Row{Row: row, cancel: cancel}
 }
 
 // Exec executes a query that doesn't return rows
 func (p *PostgresDB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	return p.db.ExecContext(ctx, query, args...)
+	ctx, cancel, done := p.limits.start(ctx, query, args)
+	defer cancel()
+	result, err := p.db.ExecContext(ctx, query, args...)
+	done(err)
+	observe("postgres", "exec", err)
+	return result, err
 }
 
 // Migrate runs all pending migrations
 func (p *PostgresDB) Migrate(ctx context.Context) error {
+	ctx = WithoutQueryTimeout(ctx)
 	p.logger.Info("running database migrations")
 
 	// Create migrations table
@@ -143,7 +173,8 @@ func (p *PostgresDB) Migrate(ctx context.Context) error {
 
 // PostgresTx wraps sql.Tx to implement Tx interface
 type PostgresTx struct {
-	tx *sql.Tx
+	tx     *sql.Tx
+	limits *queryLimits
 }
 
 // Commit commits the transaction
@@ -157,16 +188,29 @@ func (t *PostgresTx) Rollback() error {
 }
 
 // Query executes a query that returns rows
-func (t *PostgresTx) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	return t.tx.QueryContext(ctx, query, args...)
+func (t *PostgresTx) Query(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	ctx, cancel, done := t.limits.start(ctx, query, args)
+	rows, err := t.tx.QueryContext(ctx, query, args...)
+	done(err)
+	observe("postgres", "query", err)
+	return newRows(rows, err, cancel)
 }
 
 // QueryRow executes a query that is expected to return at most one row
-func (t *PostgresTx) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	return t.tx.QueryRowContext(ctx, query, args...)
+func (t *PostgresTx) QueryRow(ctx context.Context, query string, args ...interface{}) *Row {
+	ctx, cancel, done := t.limits.start(ctx, query, args)
+	row := t.tx.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	observe("postgres", "query_row", nil)
+	return &Row{Row: row, cancel: cancel}
 }
 
 // Exec executes a query without returning any rows
 func (t *PostgresTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	return t.tx.ExecContext(ctx, query, args...)
+	ctx, cancel, done := t.limits.start(ctx, query, args)
+	defer cancel()
+	result, err := t.tx.ExecContext(ctx, query, args...)
+	done(err)
+	observe("postgres", "exec", err)
+	return result, err
 }
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// APIKeyRepository handles hashed HTTP API key persistence
+type APIKeyRepository struct {
+	db database.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository
+func NewAPIKeyRepository(db database.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: database.Instrument(db, "api_key")}
+}
+
+// Create stores an API key hash
+func (r *APIKeyRepository) Create(ctx context.Context, k *models.APIKey) error {
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now()
+	}
+
+	return r.db.QueryRow(ctx,
+		"INSERT INTO api_keys (name, role, key_hash, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		k.Name, k.Role, k.KeyHash, k.CreatedAt,
+	).Scan(&k.ID)
+}
+
+// GetByHash returns the API key with the given hash, or sql.ErrNoRows
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var k models.APIKey
+	err := r.db.QueryRow(ctx,
+		"SELECT id, name, role, key_hash, created_at, last_used_at FROM api_keys WHERE key_hash = $1",
+		hash,
+	).Scan(&k.ID, &k.Name, &k.Role, &k.KeyHash, &k.CreatedAt, &k.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Count returns the number of stored API keys
+func (r *APIKeyRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM api_keys").Scan(&count)
+	return count, err
+}
+
+// Touch records that an API key was just used
+func (r *APIKeyRepository) Touch(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, "UPDATE api_keys SET last_used_at = $1 WHERE id = $2", time.Now(), id)
+	return err
+}
+
+// List returns all API keys, oldest first
+func (r *APIKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := r.db.Query(ctx,
+		"SELECT id, name, role, key_hash, created_at, last_used_at FROM api_keys ORDER BY created_at, id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var k models.APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Role, &k.KeyHash, &k.CreatedAt, &k.LastUsedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// Delete revokes an API key and returns the number of keys removed
+func (r *APIKeyRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM api_keys WHERE id = $1", id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
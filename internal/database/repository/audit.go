@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// AuditRepository handles the audit log of triggered generations
+type AuditRepository struct {
+	db database.DB
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db database.DB) *AuditRepository {
+	return &AuditRepository{db: database.Instrument(db, "audit")}
+}
+
+// Create inserts an audit entry
+func (r *AuditRepository) Create(ctx context.Context, e *models.AuditEntry) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO audit_log (actor_type, actor, action, theme_name, parameters, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		e.ActorType, e.Actor, e.Action, e.ThemeName, e.Parameters, e.RequestID, e.CreatedAt,
+	).Scan(&e.ID)
+}
+
+// List returns audit entries matching opts, newest first
+func (r *AuditRepository) List(ctx context.Context, opts ListAuditOptions) ([]models.AuditEntry, error) {
+	query := `
+		SELECT id, actor_type, actor, action, theme_name, parameters, request_id, created_at
+		FROM audit_log WHERE 1=1
+	`
+	args := make([]interface{}, 0)
+	argIndex := 1
+
+	for _, filter := range []struct {
+		column string
+		value  string
+	}{
+		{"actor_type", string(opts.ActorType)},
+		{"actor", opts.Actor},
+		{"action", opts.Action},
+		{"theme_name", opts.ThemeName},
+	} {
+		if filter.value != "" {
+			query += fmt.Sprintf(" AND %s = $%d", filter.column, argIndex)
+			args = append(args, filter.value)
+			argIndex++
+		}
+	}
+
+	if !opts.Since.IsZero() {
+		query += fmt.Sprintf(" AND created_at >= $%d", argIndex)
+		args = append(args, opts.Since)
+		argIndex++
+	}
+
+	if !opts.Until.IsZero() {
+		query += fmt.Sprintf(" AND created_at <= $%d", argIndex)
+		args = append(args, opts.Until)
+		argIndex++
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, opts.Limit)
+		argIndex++
+	}
+
+	if opts.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, opts.Offset)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		err := rows.Scan(
+			&e.ID, &e.ActorType, &e.Actor, &e.Action, &e.ThemeName, &e.Parameters, &e.RequestID, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// ListAuditOptions provides filtering options for List
+type ListAuditOptions struct {
+	ActorType models.AuditActorType
+	Actor     string
+	Action    string
+	ThemeName string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+	Offset    int
+}
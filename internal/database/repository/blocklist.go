@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// BlocklistRepository handles the global media blocklist
+type BlocklistRepository struct {
+	db database.DB
+}
+
+// NewBlocklistRepository creates a new BlocklistRepository
+func NewBlocklistRepository(db database.DB) *BlocklistRepository {
+	return &BlocklistRepository{db: database.Instrument(db, "blocklist")}
+}
+
+// Add blocks a media ID or IMDB ID. It reports whether a new entry was created.
+func (r *BlocklistRepository) Add(ctx context.Context, e *models.BlocklistEntry) (bool, error) {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO media_blocklist (media_id, imdb_id, reason, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(ctx, query, e.MediaID, e.IMDBID, e.Reason, e.CreatedAt).Scan(&e.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove unblocks a media ID or IMDB ID, whichever is set, and returns the number of entries removed
+func (r *BlocklistRepository) Remove(ctx context.Context, mediaID int64, imdbID string) (int64, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM media_blocklist WHERE media_id = $1 OR imdb_id = $2", mediaID, imdbID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// List returns all blocklist entries, newest first
+func (r *BlocklistRepository) List(ctx context.Context) ([]models.BlocklistEntry, error) {
+	rows, err := r.db.Query(ctx,
+		"SELECT id, media_id, imdb_id, reason, created_at FROM media_blocklist ORDER BY created_at DESC, id DESC",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []models.BlocklistEntry
+	for rows.Next() {
+		var e models.BlocklistEntry
+		if err := rows.Scan(&e.ID, &e.MediaID, &e.IMDBID, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// BlockedMediaIDs returns IDs of library media blocked directly or through their IMDB ID
+func (r *BlocklistRepository) BlockedMediaIDs(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id FROM media
+		WHERE id IN (SELECT media_id FROM media_blocklist WHERE media_id IS NOT NULL)
+		   OR imdb_id IN (SELECT imdb_id FROM media_blocklist WHERE imdb_id IS NOT NULL)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// SyncCheckpointRepository handles media sync checkpoints
+type SyncCheckpointRepository struct {
+	db database.DB
+}
+
+// NewSyncCheckpointRepository creates a new SyncCheckpointRepository
+func NewSyncCheckpointRepository(db database.DB) *SyncCheckpointRepository {
+	return &SyncCheckpointRepository{db: database.Instrument(db, "sync_checkpoint")}
+}
+
+// Get returns the checkpoint of a source's unfinished sync, or sql.ErrNoRows
+func (r *SyncCheckpointRepository) Get(ctx context.Context, source models.MediaSource) (*models.SyncCheckpoint, error) {
+	var cp models.SyncCheckpoint
+	err := r.db.QueryRow(ctx,
+		"SELECT source, started_at, stored, total, updated_at FROM sync_checkpoints WHERE source = $1",
+		source,
+	).Scan(&cp.Source, &cp.StartedAt, &cp.Stored, &cp.Total, &cp.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// Save creates or replaces a source's checkpoint
+func (r *SyncCheckpointRepository) Save(ctx context.Context, cp *models.SyncCheckpoint) error {
+	cp.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO sync_checkpoints (source, started_at, stored, total, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (source) DO UPDATE SET
+			started_at = EXCLUDED.started_at,
+			stored = EXCLUDED.stored,
+			total = EXCLUDED.total,
+			updated_at = EXCLUDED.updated_at
+	`, cp.Source, cp.StartedAt, cp.Stored, cp.Total, cp.UpdatedAt)
+	return err
+}
+
+// Delete removes a source's checkpoint once its sync has finished
+func (r *SyncCheckpointRepository) Delete(ctx context.Context, source models.MediaSource) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM sync_checkpoints WHERE source = $1", source)
+	return err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// CollectionRepository handles Radarr collection persistence
+type CollectionRepository struct {
+	db database.DB
+}
+
+// NewCollectionRepository creates a new CollectionRepository
+func NewCollectionRepository(db database.DB) *CollectionRepository {
+	return &CollectionRepository{db: database.Instrument(db, "collection")}
+}
+
+// Upsert creates or updates a collection by TMDB ID
+func (r *CollectionRepository) Upsert(ctx context.Context, c *models.Collection) error {
+	now := time.Now()
+	c.SyncedAt = now
+
+	query := `
+		INSERT INTO collections (tmdb_id, title, synced_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tmdb_id) DO UPDATE SET
+			title = EXCLUDED.title,
+			synced_at = EXCLUDED.synced_at
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRow(ctx, query, c.TMDBID, c.Title, c.SyncedAt, now).Scan(&c.ID, &c.CreatedAt)
+}
@@ -17,31 +17,61 @@ type CooldownRepository struct {
 
 // NewCooldownRepository creates a new CooldownRepository
 func NewCooldownRepository(db database.DB) *CooldownRepository {
-	return &CooldownRepository{db: db}
+	return &CooldownRepository{db: database.Instrument(db, "cooldown")}
 }
 
+const upsertCooldownQuery = `
+	INSERT INTO media_cooldowns (
+		media_id, cooldown_days, last_played_at, can_replay_at, media_title, media_type
+	) VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (media_id) DO UPDATE SET
+		cooldown_days = EXCLUDED.cooldown_days,
+		last_played_at = EXCLUDED.last_played_at,
+		can_replay_at = EXCLUDED.can_replay_at,
+		media_title = EXCLUDED.media_title,
+		media_type = EXCLUDED.media_type
+	RETURNING id
+`
+
 // Upsert creates or updates a cooldown record
 func (r *CooldownRepository) Upsert(ctx context.Context, c *models.MediaCooldown) error {
-	query := `
-		INSERT INTO media_cooldowns (
-			media_id, cooldown_days, last_played_at, can_replay_at, media_title, media_type
-		) VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (media_id) DO UPDATE SET
-			cooldown_days = EXCLUDED.cooldown_days,
-			last_played_at = EXCLUDED.last_played_at,
-			can_replay_at = EXCLUDED.can_replay_at,
-			media_title = EXCLUDED.media_title,
-			media_type = EXCLUDED.media_type
-		RETURNING id
-	`
-
-	err := r.db.QueryRow(ctx, query,
+	err := r.db.QueryRow(ctx, upsertCooldownQuery,
 		c.MediaID, c.CooldownDays, c.LastPlayedAt, c.CanReplayAt, c.MediaTitle, c.MediaType,
 	).Scan(&c.ID)
 
 	return err
 }
 
+// RecordPlays inserts plays into the play history and upserts the matching cooldowns in one
+// transaction, so either every play is recorded with its cooldown or none is
+func (r *CooldownRepository) RecordPlays(ctx context.Context, plays []models.PlayHistory, cooldowns []models.MediaCooldown) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i := range plays {
+		h := &plays[i]
+		if err := tx.QueryRow(ctx, insertPlayHistoryQuery,
+			h.MediaID, h.ChannelID, h.ThemeName, h.PlayedAt, h.MediaTitle, h.MediaType, h.Source,
+		).Scan(&h.ID); err != nil {
+			return fmt.Errorf("failed to record play of %q: %w", h.MediaTitle, err)
+		}
+	}
+
+	for i := range cooldowns {
+		c := &cooldowns[i]
+		if err := tx.QueryRow(ctx, upsertCooldownQuery,
+			c.MediaID, c.CooldownDays, c.LastPlayedAt, c.CanReplayAt, c.MediaTitle, c.MediaType,
+		).Scan(&c.ID); err != nil {
+			return fmt.Errorf("failed to set cooldown of %q: %w", c.MediaTitle, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // List retrieves cooldowns with optional filters
 func (r *CooldownRepository) List(ctx context.Context, opts ListCooldownOptions) ([]models.MediaCooldown, error) {
 	query := `
@@ -103,6 +133,21 @@ func (r *CooldownRepository) List(ctx context.Context, opts ListCooldownOptions)
 	return cooldowns, rows.Err()
 }
 
+// GetByMediaID returns the cooldown record of a media item, or sql.ErrNoRows when it has none
+func (r *CooldownRepository) GetByMediaID(ctx context.Context, mediaID int64) (*models.MediaCooldown, error) {
+	var c models.MediaCooldown
+	err := r.db.QueryRow(ctx, `
+		SELECT id, media_id, cooldown_days, last_played_at, can_replay_at, media_title, media_type
+		FROM media_cooldowns WHERE media_id = $1
+	`, mediaID).Scan(
+		&c.ID, &c.MediaID, &c.CooldownDays, &c.LastPlayedAt, &c.CanReplayAt, &c.MediaTitle, &c.MediaType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
 // GetActiveCooldownMediaIDs returns IDs of media currently on cooldown
 func (r *CooldownRepository) GetActiveCooldownMediaIDs(ctx context.Context) ([]int64, error) {
 	rows, err := r.db.Query(ctx,
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// Generation statuses accepted by ListGenerationOptions.Status
+const (
+	GenerationStatusGenerated = "generated"
+	GenerationStatusFailed    = "failed"
+	GenerationStatusSkipped   = "skipped"
+)
+
+// GenerationRepository handles playlist generation run persistence
+type GenerationRepository struct {
+	db database.DB
+}
+
+// NewGenerationRepository creates a new GenerationRepository
+func NewGenerationRepository(db database.DB) *GenerationRepository {
+	return &GenerationRepository{db: database.Instrument(db, "generation")}
+}
+
+// Create inserts a generation run record
+func (r *GenerationRepository) Create(ctx context.Context, g *models.Generation) error {
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO generations (
+			theme_name, channel_id, generated, dry_run, item_count, total_score,
+			duration_ms, error, skip_reason, attempts, degraded, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		g.ThemeName, g.ChannelID, g.Generated, g.DryRun, g.ItemCount, g.TotalScore,
+		g.DurationMS, g.Error, g.SkipReason, max(g.Attempts, 1), g.Degraded, g.CreatedAt,
+	).Scan(&g.ID)
+}
+
+// List retrieves generation runs, newest first, with optional filters
+func (r *GenerationRepository) List(ctx context.Context, opts ListGenerationOptions) ([]models.Generation, error) {
+	query := `
+		SELECT id, theme_name, channel_id, generated, dry_run, item_count, total_score,
+			duration_ms, error, skip_reason, COALESCE(attempts, 1), degraded, created_at
+		FROM generations WHERE 1=1
+	`
+	args := make([]interface{}, 0)
+	argIndex := 1
+
+	if opts.ThemeName != "" {
+		query += fmt.Sprintf(" AND theme_name = $%d", argIndex)
+		args = append(args, opts.ThemeName)
+		argIndex++
+	}
+
+	if opts.ChannelID != "" {
+		query += fmt.Sprintf(" AND channel_id = $%d", argIndex)
+		args = append(args, opts.ChannelID)
+		argIndex++
+	}
+
+	switch opts.Status {
+	case GenerationStatusGenerated:
+		query += " AND generated = TRUE AND error = ''"
+	case GenerationStatusFailed:
+		query += " AND error <> ''"
+	case GenerationStatusSkipped:
+		query += " AND skip_reason <> ''"
+	}
+
+	if opts.DryRun != nil {
+		query += fmt.Sprintf(" AND dry_run = $%d", argIndex)
+		args = append(args, *opts.DryRun)
+		argIndex++
+	}
+
+	if !opts.Since.IsZero() {
+		query += fmt.Sprintf(" AND created_at >= $%d", argIndex)
+		args = append(args, opts.Since)
+		argIndex++
+	}
+
+	if !opts.Until.IsZero() {
+		query += fmt.Sprintf(" AND created_at <= $%d", argIndex)
+		args = append(args, opts.Until)
+		argIndex++
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, opts.Limit)
+		argIndex++
+	}
+
+	if opts.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, opts.Offset)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var generations []models.Generation
+	for rows.Next() {
+		var g models.Generation
+		err := rows.Scan(
+			&g.ID, &g.ThemeName, &g.ChannelID, &g.Generated, &g.DryRun, &g.ItemCount, &g.TotalScore,
+			&g.DurationMS, &g.Error, &g.SkipReason, &g.Attempts, &g.Degraded, &g.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		generations = append(generations, g)
+	}
+
+	return generations, rows.Err()
+}
+
+// ListGenerationOptions provides filtering options for List
+type ListGenerationOptions struct {
+	ThemeName string
+	ChannelID string
+	Status    string // generated, failed, or skipped; empty for all
+	DryRun    *bool
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+	Offset    int
+}
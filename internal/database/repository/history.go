@@ -16,24 +16,27 @@ type HistoryRepository struct {
 
 // NewHistoryRepository creates a new HistoryRepository
 func NewHistoryRepository(db database.DB) *HistoryRepository {
-	return &HistoryRepository{db: db}
+	return &HistoryRepository{db: database.Instrument(db, "history")}
 }
 
+const insertPlayHistoryQuery = `
+	INSERT INTO play_history (
+		media_id, channel_id, theme_name, played_at, media_title, media_type, source
+	) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id
+`
+
 // Create inserts a new play history record
 func (r *HistoryRepository) Create(ctx context.Context, h *models.PlayHistory) error {
 	if h.PlayedAt.IsZero() {
 		h.PlayedAt = time.Now()
 	}
+	if h.Source == "" {
+		h.Source = models.PlaySourceLineup
+	}
 
-	query := `
-		INSERT INTO play_history (
-			media_id, channel_id, theme_name, played_at, media_title, media_type
-		) VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id
-	`
-
-	err := r.db.QueryRow(ctx, query,
-		h.MediaID, h.ChannelID, h.ThemeName, h.PlayedAt, h.MediaTitle, h.MediaType,
+	err := r.db.QueryRow(ctx, insertPlayHistoryQuery,
+		h.MediaID, h.ChannelID, h.ThemeName, h.PlayedAt, h.MediaTitle, h.MediaType, h.Source,
 	).Scan(&h.ID)
 
 	return err
@@ -41,8 +44,22 @@ func (r *HistoryRepository) Create(ctx context.Context, h *models.PlayHistory) e
 
 // List retrieves play history with optional filters
 func (r *HistoryRepository) List(ctx context.Context, opts ListHistoryOptions) ([]models.PlayHistory, error) {
+	var history []models.PlayHistory
+	err := r.Each(ctx, opts, func(h models.PlayHistory) error {
+		history = append(history, h)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// Each calls fn for every play history entry matching opts, newest first, without loading
+// them all into memory. An error from fn stops the iteration and is returned.
+func (r *HistoryRepository) Each(ctx context.Context, opts ListHistoryOptions, fn func(models.PlayHistory) error) error {
 	query := `
-		SELECT id, media_id, channel_id, theme_name, played_at, media_title, media_type
+		SELECT id, media_id, channel_id, theme_name, played_at, media_title, media_type, source
 		FROM play_history WHERE 1=1
 	`
 	args := make([]interface{}, 0)
@@ -66,6 +83,12 @@ func (r *HistoryRepository) List(ctx context.Context, opts ListHistoryOptions) (
 		argIndex++
 	}
 
+	if opts.Source != "" {
+		query += fmt.Sprintf(" AND source = $%d", argIndex)
+		args = append(args, opts.Source)
+		argIndex++
+	}
+
 	if !opts.Since.IsZero() {
 		query += fmt.Sprintf(" AND played_at >= $%d", argIndex)
 		args = append(args, opts.Since)
@@ -94,23 +117,24 @@ func (r *HistoryRepository) List(ctx context.Context, opts ListHistoryOptions) (
 
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer func() { _ = rows.Close() }()
 
-	var history []models.PlayHistory
 	for rows.Next() {
 		var h models.PlayHistory
 		err := rows.Scan(
-			&h.ID, &h.MediaID, &h.ChannelID, &h.ThemeName, &h.PlayedAt, &h.MediaTitle, &h.MediaType,
+			&h.ID, &h.MediaID, &h.ChannelID, &h.ThemeName, &h.PlayedAt, &h.MediaTitle, &h.MediaType, &h.Source,
 		)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := fn(h); err != nil {
+			return err
 		}
-		history = append(history, h)
 	}
 
-	return history, rows.Err()
+	return rows.Err()
 }
 
 // Count returns the total number of play history records
@@ -137,10 +161,21 @@ func (r *HistoryRepository) Count(ctx context.Context, opts ListHistoryOptions)
 		argIndex++
 	}
 
+	if opts.Source != "" {
+		query += fmt.Sprintf(" AND source = $%d", argIndex)
+		args = append(args, opts.Source)
+		argIndex++
+	}
+
 	if !opts.Since.IsZero() {
 		query += fmt.Sprintf(" AND played_at >= $%d", argIndex)
 		args = append(args, opts.Since)
-		// argIndex++ not needed as it's the last use
+		argIndex++
+	}
+
+	if !opts.Until.IsZero() {
+		query += fmt.Sprintf(" AND played_at <= $%d", argIndex)
+		args = append(args, opts.Until)
 	}
 
 	var count int64
@@ -148,11 +183,21 @@ func (r *HistoryRepository) Count(ctx context.Context, opts ListHistoryOptions)
 	return count, err
 }
 
-// ListHistoryOptions provides filtering options for List
+// DeleteOlderThan removes play history recorded before the given time
+func (r *HistoryRepository) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM play_history WHERE played_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListHistoryOptions provides filtering options for List and Each
 type ListHistoryOptions struct {
 	MediaID   int64
 	ChannelID string
 	ThemeName string
+	Source    models.PlaySource
 	Since     time.Time
 	Until     time.Time
 	Limit     int
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// LineupRepository handles the lineups last applied to channels
+type LineupRepository struct {
+	db database.DB
+}
+
+// NewLineupRepository creates a new LineupRepository
+func NewLineupRepository(db database.DB) *LineupRepository {
+	return &LineupRepository{db: database.Instrument(db, "lineup")}
+}
+
+// Replace stores items as the lineup of a channel, replacing the one applied before
+func (r *LineupRepository) Replace(ctx context.Context, channelID string, items []models.LineupItem) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(ctx, "DELETE FROM lineup_items WHERE channel_id = $1", channelID); err != nil {
+		return fmt.Errorf("failed to clear lineup: %w", err)
+	}
+
+	for i := range items {
+		item := &items[i]
+		item.ChannelID = channelID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO lineup_items (
+				channel_id, theme_name, position, media_id, title, year, media_type, runtime, airs_at, applied_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id
+		`, item.ChannelID, item.ThemeName, item.Position, item.MediaID, item.Title, item.Year,
+			item.MediaType, item.Runtime, item.AirsAt, item.AppliedAt,
+		).Scan(&item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert lineup item %q: %w", item.Title, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// List returns the stored lineup of a channel, or of every channel when channelID is empty,
+// by channel and play order
+func (r *LineupRepository) List(ctx context.Context, channelID string) ([]models.LineupItem, error) {
+	query := `
+		SELECT id, channel_id, theme_name, position, media_id, title, year, media_type, runtime,
+			airs_at, applied_at
+		FROM lineup_items
+	`
+	var args []interface{}
+	if channelID != "" {
+		query += " WHERE channel_id = $1"
+		args = append(args, channelID)
+	}
+	query += " ORDER BY channel_id, position"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []models.LineupItem
+	for rows.Next() {
+		var item models.LineupItem
+		if err := rows.Scan(
+			&item.ID, &item.ChannelID, &item.ThemeName, &item.Position, &item.MediaID, &item.Title,
+			&item.Year, &item.MediaType, &item.Runtime, &item.AirsAt, &item.AppliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// FindByMedia returns the lineup item of a media item in the lineups currently applied to
+// channels, from the most recently applied lineup when several channels have it. It returns
+// sql.ErrNoRows when no channel's lineup has the media.
+func (r *LineupRepository) FindByMedia(ctx context.Context, mediaID int64) (*models.LineupItem, error) {
+	var item models.LineupItem
+	err := r.db.QueryRow(ctx, `
+		SELECT id, channel_id, theme_name, position, media_id, title, year, media_type, runtime,
+			airs_at, applied_at
+		FROM lineup_items
+		WHERE media_id = $1
+		ORDER BY applied_at DESC, position
+		LIMIT 1
+	`, mediaID).Scan(
+		&item.ID, &item.ChannelID, &item.ThemeName, &item.Position, &item.MediaID, &item.Title,
+		&item.Year, &item.MediaType, &item.Runtime, &item.AirsAt, &item.AppliedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// ListRepository handles imported title list persistence
+type ListRepository struct {
+	db database.DB
+}
+
+// NewListRepository creates a new ListRepository
+func NewListRepository(db database.DB) *ListRepository {
+	return &ListRepository{db: database.Instrument(db, "list")}
+}
+
+// Replace stores a list by name and replaces all of its items
+func (r *ListRepository) Replace(ctx context.Context, l *models.List, items []models.ListItem) error {
+	now := time.Now()
+	l.SyncedAt = now
+	l.ItemCount = len(items)
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO lists (name, source, url, item_count, synced_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (name) DO UPDATE SET
+			source = EXCLUDED.source,
+			url = EXCLUDED.url,
+			item_count = EXCLUDED.item_count,
+			synced_at = EXCLUDED.synced_at
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRow(ctx, query,
+		l.Name, l.Source, l.URL, l.ItemCount, l.SyncedAt, now,
+	).Scan(&l.ID, &l.CreatedAt); err != nil {
+		return fmt.Errorf("failed to upsert list: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM list_items WHERE list_id = $1", l.ID); err != nil {
+		return fmt.Errorf("failed to clear list items: %w", err)
+	}
+
+	for _, item := range items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO list_items (list_id, rank, title, year, media_type, imdb_id, tmdb_id, tvdb_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, l.ID, item.Rank, item.Title, item.Year, item.MediaType, item.IMDBID, item.TMDBID, item.TVDBID)
+		if err != nil {
+			return fmt.Errorf("failed to insert list item %q: %w", item.Title, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// List retrieves all imported lists ordered by name
+func (r *ListRepository) List(ctx context.Context) ([]models.List, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, source, url, item_count, synced_at, created_at
+		FROM lists
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var lists []models.List
+	for rows.Next() {
+		var l models.List
+		if err := rows.Scan(&l.ID, &l.Name, &l.Source, &l.URL, &l.ItemCount, &l.SyncedAt, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+
+	return lists, rows.Err()
+}
+
+// MediaIDs returns the IDs of media that appear on any of the named lists.
+// Entries are matched by IMDB ID, by TMDB ID for movies, or by TVDB ID.
+func (r *ListRepository) MediaIDs(ctx context.Context, names []string) ([]int64, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	argIndex := 1
+	query := `
+		SELECT DISTINCT m.id
+		FROM media m
+		JOIN list_items li ON
+			(li.imdb_id <> '' AND li.imdb_id = m.imdb_id)
+			OR (li.tmdb_id > 0 AND li.tmdb_id = m.tmdb_id AND li.media_type = 'movie' AND m.media_type = 'movie')
+			OR (li.tvdb_id > 0 AND li.tvdb_id = m.tvdb_id)
+		JOIN lists l ON l.id = li.list_id
+		WHERE l.name IN (` + placeholders(len(names), &argIndex) + ")"
+
+	args := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		args = append(args, name)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// LLMTraceRepository handles the prompts and responses of Ollama calls recorded by playlist
+// generations
+type LLMTraceRepository struct {
+	db database.DB
+}
+
+// NewLLMTraceRepository creates a new LLMTraceRepository
+func NewLLMTraceRepository(db database.DB) *LLMTraceRepository {
+	return &LLMTraceRepository{db: database.Instrument(db, "llm_traces")}
+}
+
+// Create inserts a trace
+func (r *LLMTraceRepository) Create(ctx context.Context, t *models.LLMTrace) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO llm_traces (
+			generation_id, theme_name, purpose, model, messages, response, error, duration_ms, request_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		t.GenerationID, t.ThemeName, t.Purpose, t.Model, t.Messages, t.Response, t.Error,
+		t.DurationMS, t.RequestID, t.CreatedAt,
+	).Scan(&t.ID)
+}
+
+// List returns traces matching opts, in the order the calls were made
+func (r *LLMTraceRepository) List(ctx context.Context, opts ListLLMTraceOptions) ([]models.LLMTrace, error) {
+	query := `
+		SELECT id, generation_id, theme_name, purpose, model, messages, response, error,
+			duration_ms, request_id, created_at
+		FROM llm_traces WHERE 1=1
+	`
+	args := make([]interface{}, 0)
+	argIndex := 1
+
+	if opts.GenerationID != 0 {
+		query += fmt.Sprintf(" AND generation_id = $%d", argIndex)
+		args = append(args, opts.GenerationID)
+		argIndex++
+	}
+
+	if opts.ThemeName != "" {
+		query += fmt.Sprintf(" AND theme_name = $%d", argIndex)
+		args = append(args, opts.ThemeName)
+		argIndex++
+	}
+
+	query += " ORDER BY created_at, id"
+
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var traces []models.LLMTrace
+	for rows.Next() {
+		var t models.LLMTrace
+		err := rows.Scan(
+			&t.ID, &t.GenerationID, &t.ThemeName, &t.Purpose, &t.Model, &t.Messages, &t.Response, &t.Error,
+			&t.DurationMS, &t.RequestID, &t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, t)
+	}
+
+	return traces, rows.Err()
+}
+
+// DeleteOlderThan removes traces recorded before the given time
+func (r *LLMTraceRepository) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM llm_traces WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListLLMTraceOptions provides filtering options for List
+type ListLLMTraceOptions struct {
+	GenerationID int64
+	ThemeName    string
+	Limit        int
+}
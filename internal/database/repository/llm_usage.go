@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// LLMUsageRepository handles Ollama usage records of playlist generations
+type LLMUsageRepository struct {
+	db database.DB
+}
+
+// NewLLMUsageRepository creates a new LLMUsageRepository
+func NewLLMUsageRepository(db database.DB) *LLMUsageRepository {
+	return &LLMUsageRepository{db: database.Instrument(db, "llm_usage")}
+}
+
+// Create inserts a usage record
+func (r *LLMUsageRepository) Create(ctx context.Context, u *models.LLMUsage) error {
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO llm_usage (
+			generation_id, theme_name, purpose, model, prompt_tokens, eval_tokens,
+			total_duration_ms, load_duration_ms, prompt_eval_duration_ms, eval_duration_ms, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		u.GenerationID, u.ThemeName, u.Purpose, u.Model, u.PromptTokens, u.EvalTokens,
+		u.TotalDurationMS, u.LoadDurationMS, u.PromptEvalDurationMS, u.EvalDurationMS, u.CreatedAt,
+	).Scan(&u.ID)
+}
+
+// Totals sums usage per theme, heaviest themes (by total tokens) first
+func (r *LLMUsageRepository) Totals(ctx context.Context, opts LLMUsageOptions) ([]models.LLMUsageTotals, error) {
+	query := `
+		SELECT theme_name, COUNT(DISTINCT generation_id), COUNT(*),
+			COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(eval_tokens), 0),
+			COALESCE(SUM(total_duration_ms), 0), COALESCE(SUM(eval_duration_ms), 0)
+		FROM llm_usage WHERE 1=1
+	`
+	args := make([]interface{}, 0)
+	argIndex := 1
+
+	if opts.ThemeName != "" {
+		query += fmt.Sprintf(" AND theme_name = $%d", argIndex)
+		args = append(args, opts.ThemeName)
+		argIndex++
+	}
+
+	if !opts.Since.IsZero() {
+		query += fmt.Sprintf(" AND created_at >= $%d", argIndex)
+		args = append(args, opts.Since)
+		argIndex++
+	}
+
+	if !opts.Until.IsZero() {
+		query += fmt.Sprintf(" AND created_at <= $%d", argIndex)
+		args = append(args, opts.Until)
+	}
+
+	query += `
+		GROUP BY theme_name
+		ORDER BY COALESCE(SUM(prompt_tokens), 0) + COALESCE(SUM(eval_tokens), 0) DESC, theme_name
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var totals []models.LLMUsageTotals
+	for rows.Next() {
+		var t models.LLMUsageTotals
+		err := rows.Scan(
+			&t.ThemeName, &t.Generations, &t.Calls,
+			&t.PromptTokens, &t.EvalTokens, &t.TotalDurationMS, &t.EvalDurationMS,
+		)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, rows.Err()
+}
+
+// LLMUsageOptions provides filtering options for Totals
+type LLMUsageOptions struct {
+	ThemeName string
+	Since     time.Time
+	Until     time.Time
+}
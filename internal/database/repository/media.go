@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/geekxflood/program-director/internal/config"
 	"github.com/geekxflood/program-director/internal/database"
 	"github.com/geekxflood/program-director/pkg/models"
 )
@@ -17,82 +19,324 @@ type MediaRepository struct {
 
 // NewMediaRepository creates a new MediaRepository
 func NewMediaRepository(db database.DB) *MediaRepository {
-	return &MediaRepository{db: db}
+	return &MediaRepository{db: database.Instrument(db, "media")}
 }
 
-// Upsert creates or updates a media record based on external_id and source
+// Upsert creates or updates a media record based on external_id, source, and source_instance.
+// A stored certification and original language are kept, so TMDB values win over the arr ones.
 func (r *MediaRepository) Upsert(ctx context.Context, m *models.Media) error {
+	args, err := mediaUpsertArgs(m, time.Now())
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO media (" + mediaUpsertColumns + ") VALUES " + upsertRowPlaceholders(1) +
+		mediaUpsertConflict + " RETURNING id, created_at"
+
+	return r.db.QueryRow(ctx, query, args...).Scan(&m.ID, &m.CreatedAt)
+}
+
+// mediaBulkBatchSize keeps multi-row inserts well under the drivers' bind parameter limits
+const mediaBulkBatchSize = 500
+
+// BulkUpsert creates or updates many media records in one transaction with multi-row inserts,
+// resolving conflicts the same way as Upsert. Each record's ID and CreatedAt are filled in, and
+// the number of newly created records is returned. Nothing is stored if any batch fails.
+func (r *MediaRepository) BulkUpsert(ctx context.Context, media []*models.Media) (int, error) {
+	if len(media) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	now := time.Now()
-	m.UpdatedAt = now
-	m.SyncedAt = now
-
-	query := `
-		INSERT INTO media (
-			external_id, source, media_type, title, year, overview, runtime,
-			genres, imdb_rating, tmdb_rating, popularity,
-			imdb_id, tmdb_id, tvdb_id, path, has_file, size_on_disk,
-			status, monitored, synced_at, created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7,
-			$8, $9, $10, $11,
-			$12, $13, $14, $15, $16, $17,
-			$18, $19, $20, $21, $22
+	created := 0
+	batch := make([]*models.Media, 0, mediaBulkBatchSize)
+	keys := make(map[mediaKey]bool, mediaBulkBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := upsertMediaBatch(ctx, tx, batch, now)
+		if err != nil {
+			return err
+		}
+		created += n
+		batch = batch[:0]
+		clear(keys)
+		return nil
+	}
+
+	for _, m := range media {
+		if m.SourceInstance == "" {
+			m.SourceInstance = config.DefaultInstance
+		}
+		// A row can only be upserted once per statement, so a repeated key starts a new batch
+		key := mediaKeyOf(m)
+		if keys[key] || len(batch) == mediaBulkBatchSize {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+		batch = append(batch, m)
+		keys[key] = true
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit media upsert: %w", err)
+	}
+	return created, nil
+}
+
+// upsertMediaBatch upserts a batch of media with distinct keys in a single statement and returns
+// how many of them did not exist before
+func upsertMediaBatch(ctx context.Context, tx database.Tx, batch []*models.Media, now time.Time) (int, error) {
+	existing, err := existingMediaKeys(ctx, tx, batch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up existing media: %w", err)
+	}
+
+	args := make([]interface{}, 0, len(batch)*mediaUpsertColumnCount)
+	rows := make([]string, 0, len(batch))
+	for i, m := range batch {
+		rowArgs, err := mediaUpsertArgs(m, now)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, rowArgs...)
+		rows = append(rows, upsertRowPlaceholders(i*mediaUpsertColumnCount+1))
+	}
+
+	query := "INSERT INTO media (" + mediaUpsertColumns + ") VALUES " + strings.Join(rows, ", ") +
+		mediaUpsertConflict + " RETURNING id, created_at, external_id, source, source_instance"
+
+	result, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert media batch: %w", err)
+	}
+	defer func() { _ = result.Close() }()
+
+	byKey := make(map[mediaKey]*models.Media, len(batch))
+	for _, m := range batch {
+		byKey[mediaKeyOf(m)] = m
+	}
+
+	for result.Next() {
+		var (
+			id        int64
+			createdAt time.Time
+			key       mediaKey
 		)
-		ON CONFLICT (external_id, source) DO UPDATE SET
-			media_type = EXCLUDED.media_type,
-			title = EXCLUDED.title,
-			year = EXCLUDED.year,
-			overview = EXCLUDED.overview,
-			runtime = EXCLUDED.runtime,
-			genres = EXCLUDED.genres,
-			imdb_rating = EXCLUDED.imdb_rating,
-			tmdb_rating = EXCLUDED.tmdb_rating,
-			popularity = EXCLUDED.popularity,
-			imdb_id = EXCLUDED.imdb_id,
-			tmdb_id = EXCLUDED.tmdb_id,
-			tvdb_id = EXCLUDED.tvdb_id,
-			path = EXCLUDED.path,
-			has_file = EXCLUDED.has_file,
-			size_on_disk = EXCLUDED.size_on_disk,
-			status = EXCLUDED.status,
-			monitored = EXCLUDED.monitored,
-			synced_at = EXCLUDED.synced_at,
-			updated_at = EXCLUDED.updated_at
-		RETURNING id, created_at
-	`
+		if err := result.Scan(&id, &createdAt, &key.externalID, &key.source, &key.instance); err != nil {
+			return 0, fmt.Errorf("failed to scan upserted media: %w", err)
+		}
+		if m, ok := byKey[key]; ok {
+			m.ID = id
+			m.CreatedAt = createdAt
+		}
+	}
+	if err := result.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read upserted media: %w", err)
+	}
+
+	return len(batch) - existing, nil
+}
+
+// existingMediaKeys counts how many media in the batch are already stored
+func existingMediaKeys(ctx context.Context, tx database.Tx, batch []*models.Media) (int, error) {
+	argIndex := 1
+	args := make([]interface{}, 0, len(batch))
+	for _, m := range batch {
+		args = append(args, m.ExternalID)
+	}
+	query := "SELECT external_id, source, source_instance FROM media WHERE external_id IN (" +
+		placeholders(len(batch), &argIndex) + ")"
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	wanted := make(map[mediaKey]bool, len(batch))
+	for _, m := range batch {
+		wanted[mediaKeyOf(m)] = true
+	}
+
+	count := 0
+	for rows.Next() {
+		var key mediaKey
+		if err := rows.Scan(&key.externalID, &key.source, &key.instance); err != nil {
+			return 0, err
+		}
+		if wanted[key] {
+			count++
+		}
+	}
+	return count, rows.Err()
+}
+
+// mediaKey is the unique key media upserts conflict on
+type mediaKey struct {
+	externalID int64
+	source     models.MediaSource
+	instance   string
+}
+
+func mediaKeyOf(m *models.Media) mediaKey {
+	return mediaKey{externalID: m.ExternalID, source: m.Source, instance: m.SourceInstance}
+}
+
+// mediaUpsertColumns are the columns written by Upsert and BulkUpsert, in mediaUpsertArgs order
+const mediaUpsertColumns = `external_id, source, media_type, title, year, overview, runtime,
+	genres, imdb_rating, tmdb_rating, popularity,
+	imdb_id, tmdb_id, tvdb_id, path, has_file, size_on_disk,
+	status, monitored, synced_at, created_at, updated_at, source_instance, tags,
+	collection_tmdb_id, resolution, quality, certification, original_language, added_at,
+	poster_url, fanart_url`
+
+// mediaUpsertColumnCount is the number of columns in mediaUpsertColumns
+const mediaUpsertColumnCount = 32
+
+// mediaUpsertConflict updates an existing record on a key conflict. A stored certification and
+// original language are kept, so TMDB values win over the arr ones.
+const mediaUpsertConflict = `
+	ON CONFLICT (external_id, source, source_instance) DO UPDATE SET
+		media_type = EXCLUDED.media_type,
+		title = EXCLUDED.title,
+		year = EXCLUDED.year,
+		overview = EXCLUDED.overview,
+		runtime = EXCLUDED.runtime,
+		genres = EXCLUDED.genres,
+		imdb_rating = EXCLUDED.imdb_rating,
+		tmdb_rating = EXCLUDED.tmdb_rating,
+		popularity = EXCLUDED.popularity,
+		imdb_id = EXCLUDED.imdb_id,
+		tmdb_id = EXCLUDED.tmdb_id,
+		tvdb_id = EXCLUDED.tvdb_id,
+		path = EXCLUDED.path,
+		has_file = EXCLUDED.has_file,
+		size_on_disk = EXCLUDED.size_on_disk,
+		status = EXCLUDED.status,
+		monitored = EXCLUDED.monitored,
+		synced_at = EXCLUDED.synced_at,
+		updated_at = EXCLUDED.updated_at,
+		tags = EXCLUDED.tags,
+		collection_tmdb_id = EXCLUDED.collection_tmdb_id,
+		resolution = EXCLUDED.resolution,
+		quality = EXCLUDED.quality,
+		added_at = EXCLUDED.added_at,
+		poster_url = EXCLUDED.poster_url,
+		fanart_url = EXCLUDED.fanart_url,
+		certification = COALESCE(NULLIF(media.certification, ''), EXCLUDED.certification),
+		original_language = COALESCE(NULLIF(media.original_language, ''), EXCLUDED.original_language)`
+
+// mediaUpsertArgs stamps m as updated at now and returns its values for mediaUpsertColumns. The
+// caller's SyncedAt is kept, so a resumed sync stores the time it started; it defaults to now.
+func mediaUpsertArgs(m *models.Media, now time.Time) ([]interface{}, error) {
+	m.UpdatedAt = now
+	if m.SyncedAt.IsZero() {
+		m.SyncedAt = now
+	}
 
 	genresValue, err := m.Genres.Value()
 	if err != nil {
-		return fmt.Errorf("failed to marshal genres: %w", err)
+		return nil, fmt.Errorf("failed to marshal genres: %w", err)
 	}
 
-	err = r.db.QueryRow(ctx, query,
+	tags := m.Tags
+	if tags == nil {
+		tags = models.StringSlice{}
+	}
+	tagsValue, err := tags.Value()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	if m.SourceInstance == "" {
+		m.SourceInstance = config.DefaultInstance
+	}
+
+	return []interface{}{
 		m.ExternalID, m.Source, m.MediaType, m.Title, m.Year, m.Overview, m.Runtime,
 		genresValue, m.IMDBRating, m.TMDBRating, m.Popularity,
 		m.IMDBID, m.TMDBID, m.TVDBID, m.Path, m.HasFile, m.SizeOnDisk,
-		m.Status, m.Monitored, m.SyncedAt, now, now,
-	).Scan(&m.ID, &m.CreatedAt)
+		m.Status, m.Monitored, m.SyncedAt, now, now, m.SourceInstance, tagsValue,
+		m.CollectionTMDBID, m.Resolution, m.Quality, m.Certification, m.OriginalLanguage, m.AddedAt,
+		m.PosterURL, m.FanartURL,
+	}, nil
+}
 
-	return err
+// upsertRowPlaceholders returns the placeholders for one row of mediaUpsertColumns, numbered from first
+func upsertRowPlaceholders(first int) string {
+	argIndex := first
+	return "(" + placeholders(mediaUpsertColumnCount, &argIndex) + ")"
 }
 
-// GetByExternalID retrieves a media record by external ID and source
-func (r *MediaRepository) GetByExternalID(ctx context.Context, externalID int64, source models.MediaSource) (*models.Media, error) {
-	query := `
-		SELECT id, external_id, source, media_type, title, year, overview, runtime,
-			genres, imdb_rating, tmdb_rating, popularity,
-			imdb_id, tmdb_id, tvdb_id, path, has_file, size_on_disk,
-			status, monitored, synced_at, created_at, updated_at
-		FROM media WHERE external_id = $1 AND source = $2
-	`
+// GetByID retrieves a media record by ID
+func (r *MediaRepository) GetByID(ctx context.Context, id int64) (*models.Media, error) {
+	query := "SELECT " + mediaColumns + " FROM media WHERE id = $1"
 
-	var m models.Media
-	err := r.db.QueryRow(ctx, query, externalID, source).Scan(
-		&m.ID, &m.ExternalID, &m.Source, &m.MediaType, &m.Title, &m.Year, &m.Overview, &m.Runtime,
-		&m.Genres, &m.IMDBRating, &m.TMDBRating, &m.Popularity,
-		&m.IMDBID, &m.TMDBID, &m.TVDBID, &m.Path, &m.HasFile, &m.SizeOnDisk,
-		&m.Status, &m.Monitored, &m.SyncedAt, &m.CreatedAt, &m.UpdatedAt,
-	)
+	m, err := scanMedia(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetByExternalID retrieves a media record by external ID, source, and source instance
+func (r *MediaRepository) GetByExternalID(ctx context.Context, externalID int64, source models.MediaSource, instance string) (*models.Media, error) {
+	query := "SELECT " + mediaColumns + " FROM media WHERE external_id = $1 AND source = $2 AND source_instance = $3"
+
+	m, err := scanMedia(r.db.QueryRow(ctx, query, externalID, source, instance))
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// FindByTitle retrieves media by case-insensitive title among the given media types.
+// When year is non-zero it must match as well.
+func (r *MediaRepository) FindByTitle(ctx context.Context, title string, year int, mediaTypes []models.MediaType) (*models.Media, error) {
+	query := "SELECT " + mediaColumns + " FROM media WHERE LOWER(title) = LOWER($1)"
+	args := []interface{}{title}
+	argIndex := 2
+
+	if year > 0 {
+		query += fmt.Sprintf(" AND year = $%d", argIndex)
+		args = append(args, year)
+		argIndex++
+	}
+
+	if len(mediaTypes) > 0 {
+		query += " AND media_type IN (" + placeholders(len(mediaTypes), &argIndex) + ")"
+		for _, mt := range mediaTypes {
+			args = append(args, mt)
+		}
+	}
+
+	query += " ORDER BY has_file DESC, id LIMIT 1"
+
+	m, err := scanMedia(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// FindByIMDBID retrieves media by IMDB ID
+func (r *MediaRepository) FindByIMDBID(ctx context.Context, imdbID string) (*models.Media, error) {
+	query := "SELECT " + mediaColumns + " FROM media WHERE imdb_id = $1 ORDER BY has_file DESC, id LIMIT 1"
+
+	m, err := scanMedia(r.db.QueryRow(ctx, query, imdbID))
 	if err != nil {
 		return nil, err
 	}
@@ -101,13 +345,7 @@ func (r *MediaRepository) GetByExternalID(ctx context.Context, externalID int64,
 
 // List retrieves media with optional filters
 func (r *MediaRepository) List(ctx context.Context, opts ListMediaOptions) ([]models.Media, error) {
-	query := `
-		SELECT id, external_id, source, media_type, title, year, overview, runtime,
-			genres, imdb_rating, tmdb_rating, popularity,
-			imdb_id, tmdb_id, tvdb_id, path, has_file, size_on_disk,
-			status, monitored, synced_at, created_at, updated_at
-		FROM media WHERE 1=1
-	`
+	query := "SELECT " + mediaColumns + " FROM media WHERE 1=1"
 	args := make([]interface{}, 0)
 	argIndex := 1
 
@@ -135,6 +373,12 @@ func (r *MediaRepository) List(ctx context.Context, opts ListMediaOptions) ([]mo
 		argIndex++
 	}
 
+	if !opts.SyncedSince.IsZero() {
+		query += fmt.Sprintf(" AND synced_at >= $%d", argIndex)
+		args = append(args, opts.SyncedSince)
+		argIndex++
+	}
+
 	// Order by
 	if opts.OrderBy != "" {
 		query += " ORDER BY " + opts.OrderBy
@@ -161,50 +405,95 @@ func (r *MediaRepository) List(ctx context.Context, opts ListMediaOptions) ([]mo
 	}
 	defer func() { _ = rows.Close() }()
 
-	var media []models.Media
-	for rows.Next() {
-		var m models.Media
-		err := rows.Scan(
-			&m.ID, &m.ExternalID, &m.Source, &m.MediaType, &m.Title, &m.Year, &m.Overview, &m.Runtime,
-			&m.Genres, &m.IMDBRating, &m.TMDBRating, &m.Popularity,
-			&m.IMDBID, &m.TMDBID, &m.TVDBID, &m.Path, &m.HasFile, &m.SizeOnDisk,
-			&m.Status, &m.Monitored, &m.SyncedAt, &m.CreatedAt, &m.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		media = append(media, m)
-	}
-
-	return media, rows.Err()
+	return scanMediaRows(rows)
 }
 
-// ListByGenres retrieves media that has any of the specified genres
-func (r *MediaRepository) ListByGenres(ctx context.Context, genres []string, mediaType models.MediaType, excludeIDs []int64) ([]models.Media, error) {
-	// Build genre condition
-	genreConditions := ""
+// Search finds media whose title or overview matches every word of the query (as a prefix), using
+// the FTS5 index on SQLite and the search_vector column on Postgres. Best matches come first, with
+// title matches weighted above overview matches.
+func (r *MediaRepository) Search(ctx context.Context, q string, opts SearchMediaOptions) ([]models.Media, error) {
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		return []models.Media{}, nil
+	}
+
+	var query string
 	args := make([]interface{}, 0)
 	argIndex := 1
 
-	var genreConditionsSb247 strings.Builder
-	for i, genre := range genres {
-		if i > 0 {
-			genreConditionsSb247.WriteString(" OR ")
+	if r.db.Driver() == "postgres" {
+		prefixes := make([]string, len(terms))
+		for i, term := range terms {
+			prefixes[i] = term + ":*"
+		}
+		query = "SELECT " + mediaColumns + fmt.Sprintf(" FROM media, to_tsquery('english', $%d) AS search_query", argIndex) +
+			" WHERE search_vector @@ search_query"
+		args = append(args, strings.Join(prefixes, " & "))
+		argIndex++
+	} else {
+		prefixes := make([]string, len(terms))
+		for i, term := range terms {
+			prefixes[i] = `"` + term + `"*`
 		}
-		genreConditionsSb247.WriteString(fmt.Sprintf("genres LIKE $%d", argIndex))
-		args = append(args, "%"+genre+"%")
+		query = "SELECT " + mediaColumns + " FROM media" +
+			fmt.Sprintf(" JOIN (SELECT rowid, bm25(media_fts, 10.0, 1.0) AS search_rank FROM media_fts WHERE media_fts MATCH $%d) AS fts", argIndex) +
+			" ON fts.rowid = media.id WHERE 1=1"
+		args = append(args, strings.Join(prefixes, " "))
 		argIndex++
 	}
-	genreConditions += genreConditionsSb247.String()
 
-	query := fmt.Sprintf(`
-		SELECT id, external_id, source, media_type, title, year, overview, runtime,
-			genres, imdb_rating, tmdb_rating, popularity,
-			imdb_id, tmdb_id, tvdb_id, path, has_file, size_on_disk,
-			status, monitored, synced_at, created_at, updated_at
-		FROM media
-		WHERE has_file = true AND (%s)
-	`, genreConditions)
+	if opts.MediaType != "" {
+		query += fmt.Sprintf(" AND media_type = $%d", argIndex)
+		args = append(args, opts.MediaType)
+		argIndex++
+	}
+
+	if r.db.Driver() == "postgres" {
+		query += " ORDER BY ts_rank(search_vector, search_query) DESC, title"
+	} else {
+		// bm25 scores are negative, best match first
+		query += " ORDER BY fts.search_rank, title"
+	}
+
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanMediaRows(rows)
+}
+
+// searchTerms splits a search query into lowercase words of letters and digits, dropping
+// punctuation so it can't be read as full-text query syntax
+func searchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ListByGenres retrieves media that has any of the specified genres (matched exactly, ignoring
+// case, through media_genres), released within any of the given year ranges when ranges are set
+func (r *MediaRepository) ListByGenres(ctx context.Context, genres []string, mediaType models.MediaType, years []YearRange, excludeIDs []int64) ([]models.Media, error) {
+	if len(genres) == 0 {
+		return []models.Media{}, nil
+	}
+
+	args := make([]interface{}, 0, len(genres))
+	argIndex := 1
+
+	genrePlaceholders := placeholders(len(genres), &argIndex)
+	for _, genre := range genres {
+		args = append(args, strings.ToLower(genre))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM media WHERE has_file = true AND id IN (SELECT media_id FROM media_genres WHERE genre IN (%s))",
+		mediaColumns, genrePlaceholders)
 
 	if mediaType != "" {
 		query += fmt.Sprintf(" AND media_type = $%d", argIndex)
@@ -212,6 +501,28 @@ func (r *MediaRepository) ListByGenres(ctx context.Context, genres []string, med
 		argIndex++
 	}
 
+	if len(years) > 0 {
+		var yearConditions []string
+		for _, yr := range years {
+			var bounds []string
+			if yr.Min > 0 {
+				bounds = append(bounds, fmt.Sprintf("year >= $%d", argIndex))
+				args = append(args, yr.Min)
+				argIndex++
+			}
+			if yr.Max > 0 {
+				bounds = append(bounds, fmt.Sprintf("year <= $%d", argIndex))
+				args = append(args, yr.Max)
+				argIndex++
+			}
+			if len(bounds) == 0 {
+				bounds = append(bounds, "1=1")
+			}
+			yearConditions = append(yearConditions, "("+strings.Join(bounds, " AND ")+")")
+		}
+		query += " AND (" + strings.Join(yearConditions, " OR ") + ")"
+	}
+
 	// Exclude specific IDs (e.g., already on cooldown)
 	if len(excludeIDs) > 0 {
 		query += " AND id NOT IN ("
@@ -236,22 +547,154 @@ func (r *MediaRepository) ListByGenres(ctx context.Context, genres []string, med
 	}
 	defer func() { _ = rows.Close() }()
 
-	var media []models.Media
-	for rows.Next() {
-		var m models.Media
-		err := rows.Scan(
-			&m.ID, &m.ExternalID, &m.Source, &m.MediaType, &m.Title, &m.Year, &m.Overview, &m.Runtime,
-			&m.Genres, &m.IMDBRating, &m.TMDBRating, &m.Popularity,
-			&m.IMDBID, &m.TMDBID, &m.TVDBID, &m.Path, &m.HasFile, &m.SizeOnDisk,
-			&m.Status, &m.Monitored, &m.SyncedAt, &m.CreatedAt, &m.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+	return scanMediaRows(rows)
+}
+
+// ListByCollections retrieves available media belonging to any of the given TMDB collections
+func (r *MediaRepository) ListByCollections(ctx context.Context, collectionTMDBIDs []int64, excludeIDs []int64) ([]models.Media, error) {
+	if len(collectionTMDBIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(collectionTMDBIDs)+len(excludeIDs))
+	argIndex := 1
+
+	query := "SELECT " + mediaColumns + " FROM media WHERE has_file = true AND collection_tmdb_id IN (" +
+		placeholders(len(collectionTMDBIDs), &argIndex) + ")"
+	for _, id := range collectionTMDBIDs {
+		args = append(args, id)
+	}
+
+	if len(excludeIDs) > 0 {
+		query += " AND id NOT IN (" + placeholders(len(excludeIDs), &argIndex) + ")"
+		for _, id := range excludeIDs {
+			args = append(args, id)
 		}
-		media = append(media, m)
 	}
 
-	return media, rows.Err()
+	query += " ORDER BY year, title"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanMediaRows(rows)
+}
+
+// ListByProviderIDs retrieves available media matching any of the given external provider IDs
+func (r *MediaRepository) ListByProviderIDs(ctx context.Context, ids ProviderIDs, excludeIDs []int64) ([]models.Media, error) {
+	if ids.Empty() {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(ids.TMDB)+len(ids.TVDB)+len(ids.IMDB)+len(excludeIDs))
+	argIndex := 1
+
+	var conditions []string
+	if len(ids.TMDB) > 0 {
+		conditions = append(conditions, "(tmdb_id IN ("+placeholders(len(ids.TMDB), &argIndex)+") AND media_type = 'movie')")
+		for _, id := range ids.TMDB {
+			args = append(args, id)
+		}
+	}
+	if len(ids.TVDB) > 0 {
+		conditions = append(conditions, "tvdb_id IN ("+placeholders(len(ids.TVDB), &argIndex)+")")
+		for _, id := range ids.TVDB {
+			args = append(args, id)
+		}
+	}
+	if len(ids.IMDB) > 0 {
+		conditions = append(conditions, "imdb_id IN ("+placeholders(len(ids.IMDB), &argIndex)+")")
+		for _, id := range ids.IMDB {
+			args = append(args, id)
+		}
+	}
+
+	query := "SELECT " + mediaColumns + " FROM media WHERE has_file = true AND (" + strings.Join(conditions, " OR ") + ")"
+
+	if len(excludeIDs) > 0 {
+		query += " AND id NOT IN (" + placeholders(len(excludeIDs), &argIndex) + ")"
+		for _, id := range excludeIDs {
+			args = append(args, id)
+		}
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanMediaRows(rows)
+}
+
+// placeholders returns n comma-separated $N placeholders starting at *argIndex and advances it
+func placeholders(n int, argIndex *int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(fmt.Sprintf("$%d", *argIndex))
+		*argIndex++
+	}
+	return sb.String()
+}
+
+// ListForEnrichment retrieves media from a source that was never enriched or was enriched before staleBefore
+func (r *MediaRepository) ListForEnrichment(ctx context.Context, source models.MediaSource, staleBefore time.Time) ([]models.Media, error) {
+	query := "SELECT " + mediaColumns + ` FROM media
+		WHERE source = $1 AND (enriched_at IS NULL OR enriched_at < $2)
+		ORDER BY id`
+
+	rows, err := r.db.Query(ctx, query, source, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanMediaRows(rows)
+}
+
+// UpdateEnrichment stores TMDB enrichment data for a media record
+func (r *MediaRepository) UpdateEnrichment(ctx context.Context, m *models.Media) error {
+	keywords := m.Keywords
+	if keywords == nil {
+		keywords = models.StringSlice{}
+	}
+	keywordsValue, err := keywords.Value()
+	if err != nil {
+		return fmt.Errorf("failed to marshal keywords: %w", err)
+	}
+
+	countries := m.Countries
+	if countries == nil {
+		countries = models.StringSlice{}
+	}
+	countriesValue, err := countries.Value()
+	if err != nil {
+		return fmt.Errorf("failed to marshal countries: %w", err)
+	}
+
+	now := time.Now()
+	_, err = r.db.Exec(ctx, `
+		UPDATE media SET keywords = $1, certification = $2, original_language = $3, countries = $4, enriched_at = $5
+		WHERE id = $6
+	`, keywordsValue, m.Certification, m.OriginalLanguage, countriesValue, now, m.ID)
+	if err != nil {
+		return err
+	}
+
+	m.EnrichedAt = &now
+	return nil
+}
+
+// UpdateMediaType changes the media type of a media record
+func (r *MediaRepository) UpdateMediaType(ctx context.Context, id int64, mediaType models.MediaType) error {
+	_, err := r.db.Exec(ctx, "UPDATE media SET media_type = $1, updated_at = $2 WHERE id = $3", mediaType, time.Now(), id)
+	return err
 }
 
 // Count returns the total number of media records
@@ -282,6 +725,67 @@ func (r *MediaRepository) Count(ctx context.Context, opts ListMediaOptions) (int
 	return count, err
 }
 
+// CountByGenre returns the number of available media of the given types per lowercase genre
+func (r *MediaRepository) CountByGenre(ctx context.Context, mediaTypes []models.MediaType) (map[string]int64, error) {
+	query := "SELECT g.genre, COUNT(*) FROM media_genres g JOIN media m ON m.id = g.media_id WHERE m.has_file = true"
+	args := make([]interface{}, 0, len(mediaTypes))
+	argIndex := 1
+
+	if len(mediaTypes) > 0 {
+		query += fmt.Sprintf(" AND m.media_type IN (%s)", placeholders(len(mediaTypes), &argIndex))
+		for _, mt := range mediaTypes {
+			args = append(args, mt)
+		}
+	}
+	query += " GROUP BY g.genre"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var genre string
+		var count int64
+		if err := rows.Scan(&genre, &count); err != nil {
+			return nil, err
+		}
+		counts[genre] = count
+	}
+	return counts, rows.Err()
+}
+
+// ListUnplayed returns available media of the given types (all when empty) that has never
+// aired on a channel, best rated first. A limit of 0 returns all of it.
+func (r *MediaRepository) ListUnplayed(ctx context.Context, mediaTypes []models.MediaType, limit int) ([]models.Media, error) {
+	query := "SELECT " + mediaColumns + " FROM media WHERE has_file = true" +
+		" AND NOT EXISTS (SELECT 1 FROM play_history h WHERE h.media_id = media.id)"
+	args := make([]interface{}, 0, len(mediaTypes)+1)
+	argIndex := 1
+
+	if len(mediaTypes) > 0 {
+		query += fmt.Sprintf(" AND media_type IN (%s)", placeholders(len(mediaTypes), &argIndex))
+		for _, mt := range mediaTypes {
+			args = append(args, mt)
+		}
+	}
+	query += " ORDER BY imdb_rating DESC, title"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanMediaRows(rows)
+}
+
 // DeleteStale removes media that hasn't been synced since the given time
 func (r *MediaRepository) DeleteStale(ctx context.Context, source models.MediaSource, beforeTime time.Time) (int64, error) {
 	result, err := r.db.Exec(ctx,
@@ -294,6 +798,92 @@ func (r *MediaRepository) DeleteStale(ctx context.Context, source models.MediaSo
 	return result.RowsAffected()
 }
 
+// AdoptDefaultInstance moves media of a source stored under the default instance, as all media
+// was before instances were named, onto instance, so the next sync updates those rows instead of
+// storing every title again. Rows whose key instance already has are left alone. It returns the
+// number of rows moved.
+func (r *MediaRepository) AdoptDefaultInstance(ctx context.Context, source models.MediaSource, instance string) (int64, error) {
+	if instance == "" || instance == config.DefaultInstance {
+		return 0, nil
+	}
+	result, err := r.db.Exec(ctx, `
+		UPDATE media SET source_instance = $1
+		WHERE source = $2 AND source_instance = $3
+		AND NOT EXISTS (
+			SELECT 1 FROM media named
+			WHERE named.external_id = media.external_id AND named.source = media.source AND named.source_instance = $4
+		)`,
+		instance, source, config.DefaultInstance, instance,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// mediaColumns is the column list matching scanMedia
+const mediaColumns = `id, external_id, source, media_type, title, year, overview, runtime,
+	genres, imdb_rating, tmdb_rating, popularity,
+	imdb_id, tmdb_id, tvdb_id, path, has_file, size_on_disk,
+	status, monitored, synced_at, created_at, updated_at,
+	keywords, certification, original_language, enriched_at, source_instance, tags, collection_tmdb_id,
+	resolution, quality, countries, added_at, poster_url, fanart_url`
+
+// rowScanner is implemented by *database.Row and *database.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMedia scans a row selected with mediaColumns
+func scanMedia(row rowScanner) (models.Media, error) {
+	var m models.Media
+	err := row.Scan(
+		&m.ID, &m.ExternalID, &m.Source, &m.MediaType, &m.Title, &m.Year, &m.Overview, &m.Runtime,
+		&m.Genres, &m.IMDBRating, &m.TMDBRating, &m.Popularity,
+		&m.IMDBID, &m.TMDBID, &m.TVDBID, &m.Path, &m.HasFile, &m.SizeOnDisk,
+		&m.Status, &m.Monitored, &m.SyncedAt, &m.CreatedAt, &m.UpdatedAt,
+		&m.Keywords, &m.Certification, &m.OriginalLanguage, &m.EnrichedAt, &m.SourceInstance, &m.Tags, &m.CollectionTMDBID,
+		&m.Resolution, &m.Quality, &m.Countries, &m.AddedAt, &m.PosterURL, &m.FanartURL,
+	)
+	return m, err
+}
+
+// scanMediaRows scans all rows selected with mediaColumns
+func scanMediaRows(rows *database.Rows) ([]models.Media, error) {
+	var media []models.Media
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, rows.Err()
+}
+
+// ProviderIDs groups external provider identifiers used to match media
+type ProviderIDs struct {
+	TMDB []int64 // Movies only: TMDB numbers movies and series separately
+	TVDB []int64
+	IMDB []string
+}
+
+// Empty reports whether no provider IDs are set
+func (p ProviderIDs) Empty() bool {
+	return len(p.TMDB) == 0 && len(p.TVDB) == 0 && len(p.IMDB) == 0
+}
+
+// YearRange bounds release years, inclusive; 0 leaves a side unbounded
+type YearRange struct {
+	Min int
+	Max int
+}
+
+// Contains reports whether a year is within the range
+func (y YearRange) Contains(year int) bool {
+	return (y.Min == 0 || year >= y.Min) && (y.Max == 0 || year <= y.Max)
+}
+
 // ListMediaOptions provides filtering options for List
 type ListMediaOptions struct {
 	Source    models.MediaSource
@@ -303,4 +893,13 @@ type ListMediaOptions struct {
 	OrderBy   string
 	Limit     int
 	Offset    int
+
+	// SyncedSince keeps media synced at or after this time
+	SyncedSince time.Time
+}
+
+// SearchMediaOptions provides filtering options for Search
+type SearchMediaOptions struct {
+	MediaType models.MediaType
+	Limit     int
 }
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// newMigratedSQLite returns a migrated SQLite database in a temporary directory
+func newMigratedSQLite(t *testing.T) database.DB {
+	t.Helper()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := database.NewSQLite(ctx, &config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")}, logger)
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return db
+}
+
+func movie(externalID int64, title string, genres ...string) *models.Media {
+	return &models.Media{
+		ExternalID:     externalID,
+		Source:         models.MediaSourceRadarr,
+		SourceInstance: "hd",
+		MediaType:      models.MediaTypeMovie,
+		Title:          title,
+		Genres:         genres,
+		HasFile:        true,
+	}
+}
+
+func countMedia(t *testing.T, db database.DB) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(context.Background(), "SELECT COUNT(*) FROM media").Scan(&count); err != nil {
+		t.Fatalf("failed to count media: %v", err)
+	}
+	return count
+}
+
+func TestBulkUpsertCounts(t *testing.T) {
+	ctx := context.Background()
+	db := newMigratedSQLite(t)
+	repo := NewMediaRepository(db)
+
+	syncedAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	first := []*models.Media{movie(1, "Heat"), movie(2, "Alien"), movie(3, "Ran")}
+	for _, m := range first {
+		m.SyncedAt = syncedAt
+	}
+	created, err := repo.BulkUpsert(ctx, first)
+	if err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+	if created != 3 {
+		t.Errorf("BulkUpsert() created %d, want 3", created)
+	}
+	for _, m := range first {
+		if m.ID == 0 || m.CreatedAt.IsZero() {
+			t.Errorf("%s was not given an ID and creation time: %+v", m.Title, m)
+		}
+	}
+
+	stored, err := repo.GetByID(ctx, first[0].ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if !stored.SyncedAt.Equal(syncedAt) {
+		t.Errorf("synced_at = %v, want the caller's %v", stored.SyncedAt, syncedAt)
+	}
+
+	// Two updates and one new title
+	second := []*models.Media{movie(1, "Heat (Director's Cut)"), movie(2, "Alien"), movie(4, "Tampopo")}
+	created, err = repo.BulkUpsert(ctx, second)
+	if err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+	if created != 1 {
+		t.Errorf("BulkUpsert() created %d, want 1", created)
+	}
+	if second[0].ID != first[0].ID || second[1].ID != first[1].ID {
+		t.Errorf("updated media got new IDs %d and %d, want %d and %d", second[0].ID, second[1].ID, first[0].ID, first[1].ID)
+	}
+
+	stored, err = repo.GetByID(ctx, first[0].ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.Title != "Heat (Director's Cut)" {
+		t.Errorf("title = %q, want the updated one", stored.Title)
+	}
+	if stored.SyncedAt.Equal(syncedAt) || stored.SyncedAt.IsZero() {
+		t.Errorf("synced_at = %v, want it to default to now", stored.SyncedAt)
+	}
+	if n := countMedia(t, db); n != 4 {
+		t.Errorf("stored %d media, want 4", n)
+	}
+}
+
+func TestBulkUpsertDuplicateKeys(t *testing.T) {
+	ctx := context.Background()
+	db := newMigratedSQLite(t)
+	repo := NewMediaRepository(db)
+
+	// A repeated key cannot be upserted twice in one statement, so it starts a new batch
+	media := []*models.Media{movie(1, "Heat"), movie(2, "Alien"), movie(1, "Heat (1995)")}
+	created, err := repo.BulkUpsert(ctx, media)
+	if err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+	if created != 2 {
+		t.Errorf("BulkUpsert() created %d, want 2", created)
+	}
+	if media[0].ID != media[2].ID {
+		t.Errorf("repeated key got IDs %d and %d, want the same", media[0].ID, media[2].ID)
+	}
+
+	stored, err := repo.GetByID(ctx, media[0].ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.Title != "Heat (1995)" {
+		t.Errorf("title = %q, want the last one upserted", stored.Title)
+	}
+	if n := countMedia(t, db); n != 2 {
+		t.Errorf("stored %d media, want 2", n)
+	}
+}
+
+func TestBulkUpsertRollsBackFailedBatch(t *testing.T) {
+	ctx := context.Background()
+	db := newMigratedSQLite(t)
+	repo := NewMediaRepository(db)
+
+	if _, err := db.Exec(ctx, `CREATE TRIGGER reject_media BEFORE INSERT ON media WHEN NEW.title = 'Rejected'
+		BEGIN SELECT RAISE(ABORT, 'rejected'); END`); err != nil {
+		t.Fatalf("failed to create trigger: %v", err)
+	}
+
+	// The repeated key flushes the first two titles before the failing batch
+	media := []*models.Media{movie(1, "Heat"), movie(2, "Alien"), movie(1, "Heat"), movie(3, "Rejected")}
+	if _, err := repo.BulkUpsert(ctx, media); err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Fatalf("BulkUpsert() error = %v, want the rejected batch", err)
+	}
+	if n := countMedia(t, db); n != 0 {
+		t.Errorf("stored %d media after a failed batch, want 0", n)
+	}
+}
+
+func TestListByGenresMatchesWholeGenres(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMediaRepository(newMigratedSQLite(t))
+
+	if _, err := repo.BulkUpsert(ctx, []*models.Media{
+		movie(1, "Heat", "Action", "Crime"),
+		movie(2, "Spirited Away", "Live Action", "Animation"),
+	}); err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+
+	media, err := repo.ListByGenres(ctx, []string{"action"}, models.MediaTypeMovie, nil, nil)
+	if err != nil {
+		t.Fatalf("ListByGenres() error = %v", err)
+	}
+	if len(media) != 1 || media[0].Title != "Heat" {
+		t.Errorf("ListByGenres(action) = %v, want only Heat", titles(media))
+	}
+}
+
+func TestListByGenresFollowsGenreUpdates(t *testing.T) {
+	ctx := context.Background()
+	db := newMigratedSQLite(t)
+	repo := NewMediaRepository(db)
+
+	heat := movie(1, "Heat", "Crime")
+	if _, err := repo.BulkUpsert(ctx, []*models.Media{heat}); err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+
+	// Re-syncing with new genres replaces the title's media_genres rows through the triggers
+	if _, err := repo.BulkUpsert(ctx, []*models.Media{movie(1, "Heat", "Thriller", "Drama")}); err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+
+	for genre, want := range map[string]int{"crime": 0, "thriller": 1, "drama": 1} {
+		media, err := repo.ListByGenres(ctx, []string{genre}, "", nil, nil)
+		if err != nil {
+			t.Fatalf("ListByGenres() error = %v", err)
+		}
+		if len(media) != want {
+			t.Errorf("ListByGenres(%s) = %v, want %d titles", genre, titles(media), want)
+		}
+	}
+
+	var rows int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM media_genres WHERE media_id = $1", heat.ID).Scan(&rows); err != nil || rows != 2 {
+		t.Errorf("media_genres rows = %d (%v), want 2", rows, err)
+	}
+}
+
+func TestListByProviderIDsMatchesTMDBMoviesOnly(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMediaRepository(newMigratedSQLite(t))
+
+	heat := movie(1, "Heat", "Crime")
+	heat.TMDBID = 949
+	// A series sharing the movie's TMDB number
+	series := &models.Media{
+		ExternalID: 2,
+		Source:     models.MediaSourceSonarr,
+		MediaType:  models.MediaTypeSeries,
+		Title:      "Twin Peaks",
+		TMDBID:     949,
+		TVDBID:     70533,
+		HasFile:    true,
+	}
+	if _, err := repo.BulkUpsert(ctx, []*models.Media{heat, series}); err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ids  ProviderIDs
+		want []string
+	}{
+		{"tmdb", ProviderIDs{TMDB: []int64{949}}, []string{"Heat"}},
+		{"tvdb", ProviderIDs{TVDB: []int64{70533}}, []string{"Twin Peaks"}},
+		{"tmdb or tvdb", ProviderIDs{TMDB: []int64{949}, TVDB: []int64{70533}}, []string{"Heat", "Twin Peaks"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			media, err := repo.ListByProviderIDs(ctx, tt.ids, nil)
+			if err != nil {
+				t.Fatalf("ListByProviderIDs() error = %v", err)
+			}
+			got := titles(media)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ListByProviderIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func titles(media []models.Media) []string {
+	out := make([]string, len(media))
+	for i := range media {
+		out[i] = media[i].Title
+	}
+	return out
+}
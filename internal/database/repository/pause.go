@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// PauseRepository handles pausing automated actions
+type PauseRepository struct {
+	db database.DB
+}
+
+// NewPauseRepository creates a new PauseRepository
+func NewPauseRepository(db database.DB) *PauseRepository {
+	return &PauseRepository{db: database.Instrument(db, "pause")}
+}
+
+// Get returns the pause in effect, or nil while automated actions run
+func (r *PauseRepository) Get(ctx context.Context) (*models.AutomationPause, error) {
+	var p models.AutomationPause
+	err := r.db.QueryRow(ctx, "SELECT reason, paused_at FROM automation_pause WHERE id = 1").Scan(&p.Reason, &p.PausedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Pause pauses automated actions, or updates the reason of the pause in effect
+func (r *PauseRepository) Pause(ctx context.Context, reason string) (*models.AutomationPause, error) {
+	p := models.AutomationPause{Reason: reason, PausedAt: time.Now()}
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO automation_pause (id, reason, paused_at)
+		VALUES (1, $1, $2)
+		ON CONFLICT (id) DO UPDATE SET reason = EXCLUDED.reason
+		RETURNING paused_at
+	`, p.Reason, p.PausedAt).Scan(&p.PausedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Resume resumes automated actions, reporting whether they were paused
+func (r *PauseRepository) Resume(ctx context.Context) (bool, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM automation_pause WHERE id = 1")
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// SnapshotRepository handles channel lineup snapshot persistence
+type SnapshotRepository struct {
+	db database.DB
+}
+
+// NewSnapshotRepository creates a new SnapshotRepository
+func NewSnapshotRepository(db database.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: database.Instrument(db, "snapshot")}
+}
+
+// Create inserts a new channel snapshot
+func (r *SnapshotRepository) Create(ctx context.Context, s *models.ChannelSnapshot) error {
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO channel_snapshots (
+			channel_id, theme_name, programming, program_count, lineup_hash, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		s.ChannelID, s.ThemeName, string(s.Programming), s.ProgramCount, s.LineupHash, s.CreatedAt,
+	).Scan(&s.ID)
+}
+
+// GetLatestUnrestored returns the most recent snapshot for a channel that has not been restored yet
+func (r *SnapshotRepository) GetLatestUnrestored(ctx context.Context, channelID string) (*models.ChannelSnapshot, error) {
+	query := `
+		SELECT id, channel_id, theme_name, programming, program_count, created_at, restored_at,
+			COALESCE(lineup_hash, '')
+		FROM channel_snapshots
+		WHERE channel_id = $1 AND restored_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	var s models.ChannelSnapshot
+	err := r.db.QueryRow(ctx, query, channelID).Scan(
+		&s.ID, &s.ChannelID, &s.ThemeName, &s.Programming, &s.ProgramCount, &s.CreatedAt, &s.RestoredAt, &s.LineupHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MarkRestored flags a snapshot as restored so it is not restored twice
+func (r *SnapshotRepository) MarkRestored(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE channel_snapshots SET restored_at = $1 WHERE id = $2",
+		time.Now(), id,
+	)
+	return err
+}
+
+// List retrieves snapshots with optional filters, newest first
+func (r *SnapshotRepository) List(ctx context.Context, opts ListSnapshotOptions) ([]models.ChannelSnapshot, error) {
+	query := `
+		SELECT id, channel_id, theme_name, programming, program_count, created_at, restored_at,
+			COALESCE(lineup_hash, '')
+		FROM channel_snapshots WHERE 1=1
+	`
+	args := make([]interface{}, 0)
+	argIndex := 1
+
+	if opts.ChannelID != "" {
+		query += fmt.Sprintf(" AND channel_id = $%d", argIndex)
+		args = append(args, opts.ChannelID)
+		argIndex++
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var snapshots []models.ChannelSnapshot
+	for rows.Next() {
+		var s models.ChannelSnapshot
+		if err := rows.Scan(
+			&s.ID, &s.ChannelID, &s.ThemeName, &s.Programming, &s.ProgramCount, &s.CreatedAt, &s.RestoredAt, &s.LineupHash,
+		); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// ListSnapshotOptions provides filtering options for List
+type ListSnapshotOptions struct {
+	ChannelID string
+	Limit     int
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// WatchHistoryRepository handles household watch history persistence
+type WatchHistoryRepository struct {
+	db database.DB
+}
+
+// NewWatchHistoryRepository creates a new WatchHistoryRepository
+func NewWatchHistoryRepository(db database.DB) *WatchHistoryRepository {
+	return &WatchHistoryRepository{db: database.Instrument(db, "watch_history")}
+}
+
+// CreateIfMissing inserts a watch record unless one with the same source and external ID exists.
+// It reports whether a new record was created.
+func (r *WatchHistoryRepository) CreateIfMissing(ctx context.Context, w *models.WatchHistory) (bool, error) {
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO watch_history (
+			media_id, source, external_id, title, media_type, user_name, watched_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (source, external_id) DO NOTHING
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		w.MediaID, w.Source, w.ExternalID, w.Title, w.MediaType, w.UserName, w.WatchedAt, w.CreatedAt,
+	).Scan(&w.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LatestWatchedAt returns the most recent watch time recorded for a source, or the zero time
+func (r *WatchHistoryRepository) LatestWatchedAt(ctx context.Context, source string) (time.Time, error) {
+	var latest time.Time
+	err := r.db.QueryRow(ctx,
+		"SELECT watched_at FROM watch_history WHERE source = $1 ORDER BY watched_at DESC LIMIT 1",
+		source,
+	).Scan(&latest)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return latest, err
+}
+
+// RecentMediaIDs returns IDs of library media watched since the given time
+func (r *WatchHistoryRepository) RecentMediaIDs(ctx context.Context, since time.Time) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT media_id FROM watch_history
+		WHERE media_id IS NOT NULL AND watched_at >= $1
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
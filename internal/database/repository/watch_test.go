@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestWatchHistoryCreateIfMissing(t *testing.T) {
+	ctx := context.Background()
+	db := newMigratedSQLite(t)
+	repo := NewWatchHistoryRepository(db)
+
+	watchedAt := time.Date(2026, 4, 2, 21, 0, 0, 0, time.UTC)
+	first := &models.WatchHistory{Source: "tautulli", ExternalID: "11", Title: "Heat", MediaType: models.MediaTypeMovie, UserName: "alex", WatchedAt: watchedAt}
+	created, err := repo.CreateIfMissing(ctx, first)
+	if err != nil {
+		t.Fatalf("CreateIfMissing() error = %v", err)
+	}
+	if !created || first.ID == 0 {
+		t.Fatalf("CreateIfMissing() = %v with ID %d, want a new record", created, first.ID)
+	}
+
+	// The same session imported again is left alone
+	again := &models.WatchHistory{Source: "tautulli", ExternalID: "11", Title: "Heat (renamed)", MediaType: models.MediaTypeMovie, WatchedAt: watchedAt.Add(time.Hour)}
+	created, err = repo.CreateIfMissing(ctx, again)
+	if err != nil {
+		t.Fatalf("CreateIfMissing() of a duplicate error = %v", err)
+	}
+	if created || again.ID != 0 {
+		t.Errorf("CreateIfMissing() of a duplicate = %v with ID %d, want nothing created", created, again.ID)
+	}
+
+	// The external ID is only unique per source
+	other := &models.WatchHistory{Source: "plex", ExternalID: "11", Title: "Heat", MediaType: models.MediaTypeMovie, WatchedAt: watchedAt}
+	if created, err := repo.CreateIfMissing(ctx, other); err != nil || !created {
+		t.Errorf("CreateIfMissing() from another source = %v, %v, want created", created, err)
+	}
+
+	latest, err := repo.LatestWatchedAt(ctx, "tautulli")
+	if err != nil {
+		t.Fatalf("LatestWatchedAt() error = %v", err)
+	}
+	if !latest.Equal(watchedAt) {
+		t.Errorf("LatestWatchedAt() = %v, want the first import's %v", latest, watchedAt)
+	}
+}
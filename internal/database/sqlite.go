@@ -16,7 +16,9 @@ import (
 // SQLiteDB implements DB interface for SQLite
 type SQLiteDB struct {
 	db     *sql.DB
+	path   string
 	logger *slog.Logger
+	limits *queryLimits // Set by New; nil applies no limits
 }
 
 // NewSQLite creates a new SQLite connection
@@ -57,6 +59,7 @@ func NewSQLite(ctx context.Context, cfg *config.SQLiteConfig, logger *slog.Logge
 
 	return &SQLiteDB{
 		db:     db,
+		path:   dbPath,
 		logger: logger,
 	}, nil
 }
@@ -71,36 +74,60 @@ func (s *SQLiteDB) Ping(ctx context.Context) error {
 	return s.db.PingContext(ctx)
 }
 
+// Driver returns "sqlite"
+func (s *SQLiteDB) Driver() string {
+	return "sqlite"
+}
+
+// Stats returns the connection pool statistics
+func (s *SQLiteDB) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
 // BeginTx starts a new transaction
 func (s *SQLiteDB) BeginTx(ctx context.Context) (Tx, error) {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	return &SQLiteTx{tx: tx}, nil
+	return &SQLiteTx{tx: tx, limits: s.limits}, nil
 }
 
 // Query executes a query that returns rows
-func (s *SQLiteDB) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+func (s *SQLiteDB) Query(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
 	// Convert $1, $2 style placeholders to ? for SQLite
 	query = convertPlaceholders(query)
-	return s.db.QueryContext(ctx, query, args...)
+	ctx, cancel, done := s.limits.start(ctx, query, args)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	done(err)
+	observe("sqlite", "query", err)
+	return newRows(rows, err, cancel)
 }
 
 // QueryRow executes a query that returns a single row
-func (s *SQLiteDB) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+func (s *SQLiteDB) QueryRow(ctx context.Context, query string, args ...interface{}) *Row {
 	query = convertPlaceholders(query)
-	return s.db.QueryRowContext(ctx, query, args...)
+	ctx, cancel, done := s.limits.start(ctx, query, args)
+	row := s.db.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	observe("sqlite", "query_row", nil)
+	return &Row{Row: row, cancel: cancel}
 }
 
 // Exec executes a query that doesn't return rows
 func (s *SQLiteDB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	query = convertPlaceholders(query)
-	return s.db.ExecContext(ctx, query, args...)
+	ctx, cancel, done := s.limits.start(ctx, query, args)
+	defer cancel()
+	result, err := s.db.ExecContext(ctx, query, args...)
+	done(err)
+	observe("sqlite", "exec", err)
+	return result, err
 }
 
 // Migrate runs all pending migrations
 func (s *SQLiteDB) Migrate(ctx context.Context) error {
+	ctx = WithoutQueryTimeout(ctx)
 	s.logger.Info("running database migrations")
 
 	// Create migrations table
@@ -161,7 +188,8 @@ func (s *SQLiteDB) Migrate(ctx context.Context) error {
 
 // SQLiteTx wraps sql.Tx to implement Tx interface
 type SQLiteTx struct {
-	tx *sql.Tx
+	tx     *sql.Tx
+	limits *queryLimits
 }
 
 // Commit commits the transaction
@@ -175,21 +203,34 @@ func (t *SQLiteTx) Rollback() error {
 }
 
 // Query executes a query that returns rows
-func (t *SQLiteTx) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+func (t *SQLiteTx) Query(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
 	query = convertPlaceholders(query)
-	return t.tx.QueryContext(ctx, query, args...)
+	ctx, cancel, done := t.limits.start(ctx, query, args)
+	rows, err := t.tx.QueryContext(ctx, query, args...)
+	done(err)
+	observe("sqlite", "query", err)
+	return newRows(rows, err, cancel)
 }
 
 // QueryRow executes a query that is expected to return at most one row
-func (t *SQLiteTx) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+func (t *SQLiteTx) QueryRow(ctx context.Context, query string, args ...interface{}) *Row {
 	query = convertPlaceholders(query)
-	return t.tx.QueryRowContext(ctx, query, args...)
+	ctx, cancel, done := t.limits.start(ctx, query, args)
+	row := t.tx.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	observe("sqlite", "query_row", nil)
+	return &Row{Row: row, cancel: cancel}
 }
 
 // Exec executes a query without returning any rows
 func (t *SQLiteTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	query = convertPlaceholders(query)
-	return t.tx.ExecContext(ctx, query, args...)
+	ctx, cancel, done := t.limits.start(ctx, query, args)
+	defer cancel()
+	result, err := t.tx.ExecContext(ctx, query, args...)
+	done(err)
+	observe("sqlite", "exec", err)
+	return result, err
 }
 
 // convertPlaceholders converts PostgreSQL-style $1, $2 placeholders to SQLite ? placeholders
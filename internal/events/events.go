@@ -0,0 +1,71 @@
+// Package events fans out what happens in program-director, such as generation results and
+// scheduler runs, to live subscribers like the /api/v1/ws endpoint.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	TypeSyncProgress = "sync.progress" // Progress of the running media sync
+	TypeGeneration   = "generation"    // A theme generation finished
+	TypeSchedulerRun = "scheduler.run" // A scheduled or on-demand run of a theme finished
+	TypeCooldown     = "cooldown"      // A title was played and its cooldown set
+)
+
+// Event is something that happened, with type-specific data
+type Event struct {
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// Bus delivers published events to every subscriber. A nil Bus drops them, so publishers
+// need no checks.
+type Bus struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus() *Bus {
+	return &Bus{subs: make(map[chan Event]struct{})}
+}
+
+// Publish sends an event of type typ to the subscribers. Slow subscribers miss events rather
+// than blocking the publisher.
+func (b *Bus) Publish(typ string, data interface{}) {
+	if b == nil {
+		return
+	}
+	event := Event{Type: typ, Time: time.Now(), Data: data}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of events and a function that ends the subscription
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 256)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
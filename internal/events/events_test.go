@@ -0,0 +1,31 @@
+package events
+
+import "testing"
+
+func TestBus(t *testing.T) {
+	var nilBus *Bus
+	nilBus.Publish(TypeGeneration, nil)
+
+	bus := NewBus()
+	events, unsubscribe := bus.Subscribe()
+
+	bus.Publish(TypeGeneration, map[string]string{"theme": "sci-fi"})
+	event := <-events
+	if event.Type != TypeGeneration || event.Time.IsZero() {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	// A full subscriber misses events instead of blocking
+	for i := 0; i < cap(events)+10; i++ {
+		bus.Publish(TypeCooldown, i)
+	}
+	if len(events) != cap(events) {
+		t.Errorf("expected %d buffered events, got %d", cap(events), len(events))
+	}
+
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(TypeCooldown, nil)
+	for range events {
+	}
+}
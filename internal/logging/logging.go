@@ -0,0 +1,91 @@
+// Package logging provides request-scoped logging helpers shared by the server and services.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random 16 character hex request ID
+func NewRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContextHandler adds the request ID from the record's context to every record logged
+// with a *Context method (InfoContext, DebugContext, ...)
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps h so records carry their request ID
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+// Handle adds the request_id attribute when ctx carries one
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps the wrapper around handlers derived with attributes
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the wrapper around handlers derived with a group
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// LevelHandler drops records below its own minimum level, so a component can log at a
+// different level than the handler it shares with the rest of the application
+type LevelHandler struct {
+	level   slog.Leveler
+	handler slog.Handler
+}
+
+// NewLevelHandler wraps h so only records at level or above reach it. h must accept them:
+// its own level is the lowest of every component's.
+func NewLevelHandler(level slog.Leveler, h slog.Handler) *LevelHandler {
+	return &LevelHandler{level: level, handler: h}
+}
+
+// Enabled reports whether level is at or above the handler's minimum level
+func (h *LevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.handler.Enabled(ctx, level)
+}
+
+// Handle passes r to the wrapped handler
+func (h *LevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps the minimum level of handlers derived with attributes
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LevelHandler{level: h.level, handler: h.handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the minimum level of handlers derived with a group
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	return &LevelHandler{level: h.level, handler: h.handler.WithGroup(name)}
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewTextHandler(&buf, nil))).With("app", "test")
+
+	ctx := WithRequestID(context.Background(), "abc123")
+	logger.InfoContext(ctx, "with id")
+	logger.Info("without id")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	if !strings.Contains(lines[0], "request_id=abc123") || !strings.Contains(lines[0], "app=test") {
+		t.Errorf("first line = %q, want request_id and app attributes", lines[0])
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("second line = %q, want no request_id", lines[1])
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	a, b := NewRequestID(), NewRequestID()
+	if len(a) != 16 || a == b {
+		t.Errorf("NewRequestID() = %q, %q; want distinct 16 character IDs", a, b)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	quiet := slog.New(NewLevelHandler(slog.LevelWarn, base)).With("component", "sync")
+	verbose := slog.New(NewLevelHandler(slog.LevelDebug, base)).With("component", "ollama")
+
+	quiet.Info("dropped")
+	quiet.Warn("kept warning")
+	verbose.Debug("kept debug")
+
+	out := buf.String()
+	if strings.Contains(out, "dropped") {
+		t.Errorf("expected the info record of a warn logger to be dropped, got %q", out)
+	}
+	if !strings.Contains(out, "kept warning") || !strings.Contains(out, "kept debug") {
+		t.Errorf("expected the warning and the debug record, got %q", out)
+	}
+}
@@ -0,0 +1,143 @@
+// Package metrics defines the Prometheus collectors exported on /metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name
+const namespace = "program_director"
+
+// Registry holds the process-wide collectors, including the Go runtime and process ones
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var factory = promauto.With(Registry)
+
+var (
+	// GenerationDuration observes playlist generation runs by theme and status
+	// (generated, skipped, or failed), including retries
+	GenerationDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "generation_duration_seconds",
+		Help:      "Duration of playlist generation runs by theme and status.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"theme", "status"})
+
+	// SyncDuration observes Radarr/Sonarr syncs by source and status (ok or error)
+	SyncDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Duration of media syncs by source and status.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 900, 1800},
+	}, []string{"source", "status"})
+
+	// SyncItems counts synced titles by source and result (created, updated, deleted, errors)
+	SyncItems = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_items_total",
+		Help:      "Titles processed by media syncs by source and result.",
+	}, []string{"source", "result"})
+
+	// LLMRequestDuration observes Ollama requests by model and status (ok or error)
+	LLMRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "Latency of Ollama requests by model and status.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 20, 40, 80, 160, 300},
+	}, []string{"model", "status"})
+
+	// LLMTokens counts Ollama tokens by model and kind (prompt or eval)
+	LLMTokens = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "Ollama tokens processed by model and kind.",
+	}, []string{"model", "kind"})
+
+	// UpstreamRequestDuration observes requests to upstreams by upstream name, method, and
+	// status code, or "error" when no answer came back; each retry is a request
+	UpstreamRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Latency of upstream requests by upstream, method, and status.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+	}, []string{"upstream", "method", "status"})
+
+	// UpstreamRetries counts retried requests to Radarr, Sonarr, Tunarr, Ollama, and other
+	// upstreams by upstream name
+	UpstreamRetries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_retries_total",
+		Help:      "Retried upstream requests by upstream.",
+	}, []string{"upstream"})
+
+	// UpstreamCircuitOpen is 1 while the circuit breaker of an upstream is open, 0 otherwise
+	UpstreamCircuitOpen = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "upstream_circuit_open",
+		Help:      "Whether the circuit breaker of an upstream is open.",
+	}, []string{"upstream"})
+
+	// HTTPRequests counts API requests by method, route pattern, and status code
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route, and status code.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPRequestDuration observes API request latency by method and route pattern
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// DBQueries counts database statements by driver and operation (query, query_row, exec)
+	DBQueries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_queries_total",
+		Help:      "Database statements by driver and operation.",
+	}, []string{"driver", "operation"})
+
+	// DBQueryErrors counts failed database statements by driver and operation
+	DBQueryErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Failed database statements by driver and operation.",
+	}, []string{"driver", "operation"})
+
+	// DBQueryDuration observes database statements by repository, operation (query,
+	// query_row, exec), and status (ok or error)
+	DBQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of database statements by repository, operation, and status.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"repository", "operation", "status"})
+)
+
+// Handler serves Registry together with extra gatherers, such as per-server collectors
+func Handler(extra ...prometheus.Gatherer) http.Handler {
+	gatherers := append(prometheus.Gatherers{Registry}, extra...)
+	return promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+}
+
+// Status returns "error" for a non-nil error and "ok" otherwise
+func Status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,180 @@
+// Package notify posts summaries of generation and sync runs to chat webhooks, generic HTTP
+// endpoints, and push services such as ntfy and Gotify.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// sendTimeout bounds each post, so a slow target cannot hold up a run
+const sendTimeout = 10 * time.Second
+
+// Events a target can subscribe to
+const (
+	EventGeneration = "generation"
+	EventSync       = "sync"
+	EventAlert      = "alert"
+)
+
+// Message is a run summary, rendered for each target type
+type Message struct {
+	Event  string `json:"event"` // EventGeneration, EventSync, or EventAlert
+	Title  string `json:"title"`
+	Body   string `json:"body"`   // One line per theme or media kind
+	Failed bool   `json:"failed"` // A theme or sync failed, or an alert fired
+	Data   any    `json:"data"`   // Structured details for webhook targets
+}
+
+// Notifier sends messages to the configured targets. A nil Notifier sends nothing.
+type Notifier struct {
+	config atomic.Pointer[config.NotifyConfig]
+	client *http.Client
+	logger *slog.Logger
+}
+
+// New creates a Notifier for cfg
+func New(cfg *config.NotifyConfig, logger *slog.Logger) *Notifier {
+	n := &Notifier{
+		client: &http.Client{Timeout: sendTimeout},
+		logger: logger,
+	}
+	n.config.Store(cfg)
+	return n
+}
+
+// SetConfig swaps in reloaded notification settings
+func (n *Notifier) SetConfig(cfg *config.NotifyConfig) {
+	n.config.Store(cfg)
+}
+
+// Send posts msg to every target subscribed to its event. Failures are logged, as they
+// must not fail the run being reported.
+func (n *Notifier) Send(ctx context.Context, msg Message) {
+	if n == nil {
+		return
+	}
+	// The run's own context may be about to end, e.g. an API request
+	ctx = context.WithoutCancel(ctx)
+
+	for _, target := range n.config.Load().Targets {
+		if len(target.Events) > 0 && !slices.Contains(target.Events, msg.Event) {
+			continue
+		}
+		if target.OnlyFailures && !msg.Failed && msg.Event != EventAlert {
+			continue
+		}
+
+		name := target.Name
+		if name == "" {
+			name = target.Type
+		}
+		if err := n.send(ctx, target, msg); err != nil {
+			n.logger.WarnContext(ctx, "failed to send notification", "target", name, "event", msg.Event, "error", err)
+			continue
+		}
+		n.logger.DebugContext(ctx, "notification sent", "target", name, "event", msg.Event)
+	}
+}
+
+// send posts msg to one target
+func (n *Notifier) send(ctx context.Context, target config.NotifyTarget, msg Message) error {
+	req, err := newRequest(ctx, target, msg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Embed colors for Discord
+const (
+	discordGreen = 0x2ecc71
+	discordRed   = 0xe74c3c
+)
+
+// newRequest builds the request for target's service
+func newRequest(ctx context.Context, target config.NotifyTarget, msg Message) (*http.Request, error) {
+	var payload any
+	url := target.URL
+	switch target.Type {
+	case "discord":
+		color := discordGreen
+		if msg.Failed {
+			color = discordRed
+		}
+		payload = map[string]any{
+			"username": "Program Director",
+			"embeds": []map[string]any{{
+				"title":       msg.Title,
+				"description": msg.Body,
+				"color":       color,
+			}},
+		}
+	case "slack":
+		payload = map[string]any{"text": "*" + msg.Title + "*\n" + msg.Body}
+	case "gotify":
+		priority := 5
+		if msg.Failed {
+			priority = 8
+		}
+		url = strings.TrimSuffix(url, "/") + "/message"
+		payload = map[string]any{"title": msg.Title, "message": msg.Body, "priority": priority}
+	case "ntfy":
+		// ntfy takes the body as plain text and the rest as headers
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(msg.Body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Title", msg.Title)
+		req.Header.Set("Tags", "tv")
+		if msg.Failed {
+			req.Header.Set("Priority", "high")
+			req.Header.Set("Tags", "tv,warning")
+		}
+		if target.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+target.Token)
+		}
+		return req, nil
+	default:
+		payload = msg
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case target.Type == "gotify":
+		req.Header.Set("X-Gotify-Key", target.Token)
+	case target.Token != "":
+		req.Header.Set("Authorization", "Bearer "+target.Token)
+	}
+	return req, nil
+}
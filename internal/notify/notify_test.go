@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/internal/services/playlist"
+)
+
+func TestSend(t *testing.T) {
+	msg := Message{Event: EventSync, Title: "Library sync failed", Body: "movies: failed: timeout", Failed: true}
+
+	tests := []struct {
+		name   string
+		target config.NotifyTarget
+		msg    Message
+		check  func(t *testing.T, r *http.Request, body string)
+	}{
+		{
+			name:   "discord",
+			target: config.NotifyTarget{Type: "discord"},
+			msg:    msg,
+			check: func(t *testing.T, r *http.Request, body string) {
+				if !strings.Contains(body, `"title":"Library sync failed"`) || !strings.Contains(body, `"color":15158332`) {
+					t.Errorf("body = %s", body)
+				}
+			},
+		},
+		{
+			name:   "slack",
+			target: config.NotifyTarget{Type: "slack"},
+			msg:    msg,
+			check: func(t *testing.T, r *http.Request, body string) {
+				if body != `{"text":"*Library sync failed*\nmovies: failed: timeout"}` {
+					t.Errorf("body = %s", body)
+				}
+			},
+		},
+		{
+			name:   "ntfy",
+			target: config.NotifyTarget{Type: "ntfy", Token: "tk"},
+			msg:    msg,
+			check: func(t *testing.T, r *http.Request, body string) {
+				if body != msg.Body || r.Header.Get("Title") != msg.Title || r.Header.Get("Priority") != "high" || r.Header.Get("Authorization") != "Bearer tk" {
+					t.Errorf("body = %q, headers = %v", body, r.Header)
+				}
+			},
+		},
+		{
+			name:   "gotify",
+			target: config.NotifyTarget{Type: "gotify", Token: "app"},
+			msg:    msg,
+			check: func(t *testing.T, r *http.Request, body string) {
+				if r.URL.Path != "/message" || r.Header.Get("X-Gotify-Key") != "app" || !strings.Contains(body, `"priority":8`) {
+					t.Errorf("path = %s, body = %s, headers = %v", r.URL.Path, body, r.Header)
+				}
+			},
+		},
+		{
+			name:   "webhook",
+			target: config.NotifyTarget{Type: "webhook"},
+			msg:    Message{Event: EventSync, Title: "t", Data: []SyncOutcome{{Kind: "movies", Created: 2}}},
+			check: func(t *testing.T, r *http.Request, body string) {
+				if !strings.Contains(body, `"event":"sync"`) || !strings.Contains(body, `"data":[{"kind":"movies","created":2`) {
+					t.Errorf("body = %s", body)
+				}
+			},
+		},
+		{
+			name:   "other event",
+			target: config.NotifyTarget{Type: "webhook", Events: []string{EventGeneration}},
+			msg:    msg,
+		},
+		{
+			name:   "only failures",
+			target: config.NotifyTarget{Type: "webhook", OnlyFailures: true},
+			msg:    Message{Event: EventSync, Title: "Library sync complete"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				body, _ := io.ReadAll(r.Body)
+				if tt.check != nil {
+					tt.check(t, r, string(body))
+				}
+			}))
+			defer server.Close()
+
+			tt.target.URL = server.URL
+			n := New(&config.NotifyConfig{Targets: []config.NotifyTarget{tt.target}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			n.Send(context.Background(), tt.msg)
+
+			want := 1
+			if tt.check == nil {
+				want = 0
+			}
+			if calls != want {
+				t.Errorf("calls = %d, want %d", calls, want)
+			}
+		})
+	}
+}
+
+func TestGenerationMessage(t *testing.T) {
+	summary := &playlist.GenerationSummary{
+		Results: []playlist.GenerationResult{
+			{ThemeName: "sci-fi", ItemCount: 10, Attempts: 1},
+			{ThemeName: "noir", ItemCount: 2, Attempts: 1},
+			{ThemeName: "anime", Error: errors.New("tunarr unavailable"), Attempts: 3},
+			{ThemeName: "docs", SkipReason: "no candidates found", Attempts: 1},
+		},
+		Generated: []string{"sci-fi", "noir"},
+		Failed:    []string{"anime"},
+		Skipped:   []string{"docs"},
+	}
+	themes := []config.ThemeConfig{{Name: "sci-fi", MaxItems: 10}, {Name: "noir", MaxItems: 10}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name      string
+		threshold int
+		wantLow   string
+	}{
+		{"below max_items", 0, "Low candidate counts: noir (2), docs (0)"},
+		{"below threshold", 12, "Low candidate counts: sci-fi (10), noir (2), docs (0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := New(&config.NotifyConfig{LowCandidates: tt.threshold}, logger)
+			msg := n.GenerationMessage(summary, themes)
+
+			if msg.Title != "Playlists: 2 generated (12 items), 1 failed, 1 skipped" || !msg.Failed {
+				t.Errorf("title = %q, failed = %v", msg.Title, msg.Failed)
+			}
+			lines := strings.Split(msg.Body, "\n")
+			want := []string{
+				"sci-fi: 10 items scheduled",
+				"noir: 2 items scheduled",
+				"anime: failed after 3 attempts: tunarr unavailable",
+				"docs: skipped (no candidates found)",
+				tt.wantLow,
+			}
+			if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+				t.Errorf("body =\n%s\nwant\n%s", msg.Body, strings.Join(want, "\n"))
+			}
+		})
+	}
+}
+
+func TestAirings(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 11, 2, h, m, 0, 0, time.Local) }
+	titles := []ScheduledTitle{
+		{Title: "Alien", AirsAt: at(21, 35)},
+		{Title: "Aliens", AirsAt: at(23, 32)},
+		{Title: "Alien 3", AirsAt: at(1, 50)},
+		{Title: "Prometheus", AirsAt: at(3, 45)},
+		{Title: "Covenant", AirsAt: at(5, 50)},
+	}
+
+	if got, want := airings(titles[:1]), "Alien airs ~21:35"; got != want {
+		t.Errorf("airings() = %q, want %q", got, want)
+	}
+	if got, want := airings(titles), "Alien airs ~21:35, Aliens airs ~23:32, Alien 3 airs ~01:50, 2 more"; got != want {
+		t.Errorf("airings() = %q, want %q", got, want)
+	}
+	if got := airings(nil); got != "" {
+		t.Errorf("airings(nil) = %q, want empty", got)
+	}
+}
+
+func TestSyncMessage(t *testing.T) {
+	msg := SyncMessage([]SyncRun{
+		{Kind: "movies", Result: &media.SyncResult{Created: 3, Updated: 10, Errors: 1}},
+		{Kind: "series", Err: errors.New("sonarr unreachable")},
+	})
+
+	want := "movies: 3 added, 10 updated, 0 removed, 1 errors\nseries: failed: sonarr unreachable"
+	if msg.Title != "Library sync failed" || !msg.Failed || msg.Body != want {
+		t.Errorf("SyncMessage() = %+v", msg)
+	}
+}
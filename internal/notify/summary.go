@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/internal/services/playlist"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// ThemeOutcome is one theme of a generation summary, as sent to webhook targets
+type ThemeOutcome struct {
+	Theme     string `json:"theme"`
+	ChannelID string `json:"channel_id"`
+	Status    string `json:"status"` // generated, failed, or skipped
+	Items     int    `json:"items"`
+	Attempts  int    `json:"attempts,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Skipped   string `json:"skipped,omitempty"`
+	Degraded  string `json:"degraded,omitempty"`
+	Low       bool   `json:"low_candidates,omitempty"` // Fewer items than notifications.low_candidates or max_items
+
+	Lineup []ScheduledTitle `json:"lineup,omitempty"` // Applied titles in play order
+}
+
+// ScheduledTitle is a title of an applied lineup with its estimated air time
+type ScheduledTitle struct {
+	Title  string    `json:"title"`
+	Year   int       `json:"year,omitempty"`
+	AirsAt time.Time `json:"airs_at"`
+}
+
+// maxAiringsListed caps how many titles the text of a generation summary lists per theme
+const maxAiringsListed = 3
+
+// GenerationMessage summarizes a generation run: items scheduled per theme, failures, and
+// themes with low candidate counts. themes supplies each theme's max_items when the
+// low_candidates threshold is 0.
+func (n *Notifier) GenerationMessage(summary *playlist.GenerationSummary, themes []config.ThemeConfig) Message {
+	threshold := 0
+	if n != nil {
+		threshold = n.config.Load().LowCandidates
+	}
+	maxItems := make(map[string]int, len(themes))
+	for _, t := range themes {
+		maxItems[t.Name] = t.MaxItems
+	}
+
+	outcomes := make([]ThemeOutcome, 0, len(summary.Results))
+	var lines, low []string
+	var items int
+	for _, r := range summary.Results {
+		o := ThemeOutcome{
+			Theme:     r.ThemeName,
+			ChannelID: r.ChannelID,
+			Status:    "generated",
+			Items:     r.ItemCount,
+			Attempts:  r.Attempts,
+			Skipped:   r.SkipReason,
+			Degraded:  r.Degraded,
+		}
+		switch {
+		case r.Error != nil:
+			o.Status = "failed"
+			o.Error = r.Error.Error()
+			lines = append(lines, fmt.Sprintf("%s: failed after %d attempts: %s", r.ThemeName, max(r.Attempts, 1), o.Error))
+		case r.SkipReason != "":
+			o.Status = "skipped"
+			lines = append(lines, fmt.Sprintf("%s: skipped (%s)", r.ThemeName, r.SkipReason))
+		default:
+			items += r.ItemCount
+			o.Lineup = scheduledTitles(r.Playlist)
+			line := fmt.Sprintf("%s: %d items scheduled", r.ThemeName, r.ItemCount)
+			if next := airings(o.Lineup); next != "" {
+				line += " (" + next + ")"
+			}
+			lines = append(lines, line)
+		}
+
+		limit := threshold
+		if limit == 0 {
+			limit = maxItems[r.ThemeName]
+		}
+		if r.Error == nil && (r.SkipReason == "no candidates found" || (o.Status == "generated" && r.ItemCount < limit)) {
+			o.Low = true
+			low = append(low, fmt.Sprintf("%s (%d)", r.ThemeName, r.ItemCount))
+		}
+		outcomes = append(outcomes, o)
+	}
+	if len(low) > 0 {
+		lines = append(lines, "Low candidate counts: "+strings.Join(low, ", "))
+	}
+
+	title := fmt.Sprintf("Playlists: %d generated (%d items), %d failed, %d skipped",
+		len(summary.Generated), items, len(summary.Failed), len(summary.Skipped))
+	return Message{
+		Event:  EventGeneration,
+		Title:  title,
+		Body:   strings.Join(lines, "\n"),
+		Failed: len(summary.Failed) > 0,
+		Data:   outcomes,
+	}
+}
+
+// scheduledTitles lists the titles of a playlist that have an estimated air time
+func scheduledTitles(p *models.Playlist) []ScheduledTitle {
+	if p == nil {
+		return nil
+	}
+	var titles []ScheduledTitle
+	for _, item := range p.Items {
+		if item.AirsAt != nil {
+			titles = append(titles, ScheduledTitle{Title: item.Title, Year: item.Year, AirsAt: *item.AirsAt})
+		}
+	}
+	return titles
+}
+
+// airings describes when the first titles of a lineup air, e.g. "Alien airs ~21:35"
+func airings(titles []ScheduledTitle) string {
+	parts := make([]string, 0, maxAiringsListed+1)
+	for i, t := range titles {
+		if i == maxAiringsListed {
+			parts = append(parts, fmt.Sprintf("%d more", len(titles)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s airs ~%s", t.Title, t.AirsAt.Local().Format("15:04")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SyncRun is the outcome of syncing one kind of media
+type SyncRun struct {
+	Kind   string            // movies or series
+	Result *media.SyncResult // nil when the sync failed
+	Err    error
+}
+
+// SyncOutcome is one kind of media of a sync summary, as sent to webhook targets
+type SyncOutcome struct {
+	Kind    string `json:"kind"`
+	Created int    `json:"created"`
+	Updated int    `json:"updated"`
+	Deleted int    `json:"deleted"`
+	Errors  int    `json:"errors"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SyncMessage summarizes a library sync
+func SyncMessage(runs []SyncRun) Message {
+	outcomes := make([]SyncOutcome, 0, len(runs))
+	var lines []string
+	failed := false
+	for _, run := range runs {
+		o := SyncOutcome{Kind: run.Kind}
+		if run.Err != nil {
+			failed = true
+			o.Error = run.Err.Error()
+			lines = append(lines, fmt.Sprintf("%s: failed: %s", run.Kind, o.Error))
+			outcomes = append(outcomes, o)
+			continue
+		}
+		o.Created, o.Updated, o.Deleted, o.Errors = run.Result.Created, run.Result.Updated, run.Result.Deleted, run.Result.Errors
+		line := fmt.Sprintf("%s: %d added, %d updated, %d removed", run.Kind, o.Created, o.Updated, o.Deleted)
+		if o.Errors > 0 {
+			line += fmt.Sprintf(", %d errors", o.Errors)
+		}
+		lines = append(lines, line)
+		outcomes = append(outcomes, o)
+	}
+
+	title := "Library sync complete"
+	if failed {
+		title = "Library sync failed"
+	}
+	return Message{
+		Event:  EventSync,
+		Title:  title,
+		Body:   strings.Join(lines, "\n"),
+		Failed: failed,
+		Data:   outcomes,
+	}
+}
+
+// NotifyGeneration sends the summary of a generation run
+func (n *Notifier) NotifyGeneration(ctx context.Context, summary *playlist.GenerationSummary, themes []config.ThemeConfig) {
+	if n == nil {
+		return
+	}
+	n.Send(ctx, n.GenerationMessage(summary, themes))
+}
+
+// NotifySync sends the summary of a library sync
+func (n *Notifier) NotifySync(ctx context.Context, runs []SyncRun) {
+	n.Send(ctx, SyncMessage(runs))
+}
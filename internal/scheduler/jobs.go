@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/events"
+	"github.com/geekxflood/program-director/internal/logging"
+	"github.com/geekxflood/program-director/internal/services/playlist"
+)
+
+var (
+	// ErrUnknownTheme is returned for a theme that is not configured
+	ErrUnknownTheme = errors.New("theme not found")
+	// ErrJobRunning is returned when running a theme whose generation is already running
+	ErrJobRunning = errors.New("theme generation already running")
+)
+
+// Results of the last run of a theme
+const (
+	ResultGenerated = "generated"
+	ResultSkipped   = "skipped"
+	ResultFailed    = "failed"
+)
+
+// ThemeJob describes the scheduled generation of a theme
+type ThemeJob struct {
+	Theme      string     `json:"theme"`
+	ChannelID  string     `json:"channel_id"`
+	Schedule   string     `json:"schedule"`           // Empty when generation is not scheduled
+	NextRun    *time.Time `json:"next_run,omitempty"` // Unset while paused or unscheduled
+	LastRun    *time.Time `json:"last_run,omitempty"`
+	LastResult string     `json:"last_result,omitempty"` // generated, skipped, or failed
+	LastError  string     `json:"last_error,omitempty"`  // Error or skip reason of the last run
+	Paused     bool       `json:"paused"`
+	Running    bool       `json:"running"`
+}
+
+// RunEvent is published to the event bus when a scheduled or on-demand run of a theme ends
+type RunEvent struct {
+	Theme     string    `json:"theme"`
+	Result    string    `json:"result"`           // generated, skipped, or failed
+	Detail    string    `json:"detail,omitempty"` // Error or skip reason
+	StartedAt time.Time `json:"started_at"`
+}
+
+// themeState is what the scheduler tracks of a theme across runs, by theme name
+type themeState struct {
+	paused  bool
+	running bool
+	lastRun time.Time
+	result  string
+	detail  string
+}
+
+// state returns the tracked state of a theme, creating it. s.mu must be held.
+func (s *Scheduler) state(name string) *themeState {
+	st, ok := s.states[name]
+	if !ok {
+		st = &themeState{}
+		s.states[name] = st
+	}
+	return st
+}
+
+// findTheme returns the configured theme named name. s.mu must be held.
+func (s *Scheduler) findTheme(name string) (config.ThemeConfig, bool) {
+	for _, theme := range s.themes {
+		if theme.Name == name {
+			return theme, true
+		}
+	}
+	return config.ThemeConfig{}, false
+}
+
+// Jobs lists the scheduled generation of each configured theme, in config order
+func (s *Scheduler) Jobs() []ThemeJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var globalNext time.Time
+	if s.generationEntry != 0 {
+		globalNext = s.cron.Entry(s.generationEntry).Next
+	}
+
+	jobs := make([]ThemeJob, 0, len(s.themes))
+	for _, theme := range s.themes {
+		st := s.state(theme.Name)
+		schedule, next := s.generationSchedule, globalNext
+		if id, ok := s.themeEntries[theme.Name]; ok {
+			schedule, next = theme.Schedule, s.cron.Entry(id).Next
+		}
+		job := ThemeJob{
+			Theme:      theme.Name,
+			ChannelID:  theme.ChannelID,
+			Schedule:   schedule,
+			LastResult: st.result,
+			LastError:  st.detail,
+			Paused:     st.paused,
+			Running:    st.running,
+		}
+		if !next.IsZero() && !st.paused {
+			job.NextRun = &next
+		}
+		if !st.lastRun.IsZero() {
+			lastRun := st.lastRun
+			job.LastRun = &lastRun
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// Pause leaves a theme out of scheduled generation until Resume or a restart
+func (s *Scheduler) Pause(name string) error {
+	return s.setPaused(name, true)
+}
+
+// Resume puts a paused theme back into scheduled generation
+func (s *Scheduler) Resume(name string) error {
+	return s.setPaused(name, false)
+}
+
+func (s *Scheduler) setPaused(name string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.findTheme(name); !ok {
+		return ErrUnknownTheme
+	}
+	s.state(name).paused = paused
+	s.logger.Info("theme scheduling changed", "theme", name, "paused", paused)
+	return nil
+}
+
+// RunNow starts generating a theme on this instance in the background, paused or not, and
+// records the outcome as its last run
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	theme, ok := s.findTheme(name)
+	if !ok {
+		s.mu.Unlock()
+		return ErrUnknownTheme
+	}
+	st := s.state(name)
+	if st.running {
+		s.mu.Unlock()
+		return ErrJobRunning
+	}
+	st.running = true
+	s.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+
+		start := time.Now()
+		s.logger.InfoContext(ctx, "on-demand generation started", "theme", name)
+		result := s.generator.Generate(ctx, &theme, false)
+		s.finish([]string{name}, []playlist.GenerationResult{result}, start)
+		s.logger.InfoContext(ctx, "on-demand generation complete", "theme", name, "duration", time.Since(start))
+		s.alerts.RecordGenerations(ctx, []playlist.GenerationResult{result})
+	}()
+	return nil
+}
+
+// claimDue returns the themes a scheduled generation of name runs, or of every theme without
+// its own schedule when name is empty, leaving out paused themes and ones already running, and
+// marks them running
+func (s *Scheduler) claimDue(name string) []config.ThemeConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []config.ThemeConfig
+	for _, theme := range s.themes {
+		if name != "" && theme.Name != name {
+			continue
+		}
+		if _, own := s.themeEntries[theme.Name]; name == "" && own {
+			continue
+		}
+		st := s.state(theme.Name)
+		if st.paused || st.running {
+			continue
+		}
+		st.running = true
+		due = append(due, theme)
+	}
+	return due
+}
+
+// finish records the results of a run that started at start and clears the running flag of
+// names, the themes it claimed
+func (s *Scheduler) finish(names []string, results []playlist.GenerationResult, start time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range names {
+		s.state(name).running = false
+	}
+	for _, result := range results {
+		st := s.state(result.ThemeName)
+		st.lastRun = start
+		switch {
+		case result.Error != nil:
+			st.result, st.detail = ResultFailed, result.Error.Error()
+		case result.SkipReason != "":
+			st.result, st.detail = ResultSkipped, result.SkipReason
+		default:
+			st.result, st.detail = ResultGenerated, ""
+		}
+		s.events.Publish(events.TypeSchedulerRun, RunEvent{
+			Theme:     result.ThemeName,
+			Result:    st.result,
+			Detail:    st.detail,
+			StartedAt: start,
+		})
+	}
+}
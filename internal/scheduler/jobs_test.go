@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/services/playlist"
+)
+
+func TestThemeJobs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	themes := []config.ThemeConfig{{Name: "sci-fi", ChannelID: "c1"}, {Name: "noir", ChannelID: "c2"}}
+
+	sched, err := NewScheduler(&Config{}, nil, themes, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	id, err := sched.cron.AddFunc("0 20 * * *", func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched.generationEntry, sched.generationSchedule = id, "0 20 * * *"
+	sched.cron.Start()
+	defer sched.cron.Stop()
+
+	if err := sched.Pause("noir"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sched.Pause("missing"); !errors.Is(err, ErrUnknownTheme) {
+		t.Errorf("expected ErrUnknownTheme, got %v", err)
+	}
+
+	due := sched.claimDue("")
+	if len(due) != 1 || due[0].Name != "sci-fi" {
+		t.Fatalf("expected only sci-fi due, got %+v", due)
+	}
+	if err := sched.RunNow("sci-fi"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("expected ErrJobRunning while sci-fi runs, got %v", err)
+	}
+
+	start := time.Now()
+	sched.finish([]string{"sci-fi"}, []playlist.GenerationResult{
+		{ThemeName: "sci-fi", Error: errors.New("tunarr unavailable")},
+	}, start)
+
+	jobs := sched.Jobs()
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	scifi, noir := jobs[0], jobs[1]
+	if scifi.Schedule != "0 20 * * *" || scifi.NextRun == nil || scifi.Running {
+		t.Errorf("unexpected sci-fi job %+v", scifi)
+	}
+	if scifi.LastRun == nil || !scifi.LastRun.Equal(start) || scifi.LastResult != ResultFailed || scifi.LastError != "tunarr unavailable" {
+		t.Errorf("unexpected sci-fi last run %+v", scifi)
+	}
+	if !noir.Paused || noir.NextRun != nil || noir.LastRun != nil {
+		t.Errorf("unexpected paused noir job %+v", noir)
+	}
+
+	if err := sched.Resume("noir"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if due := sched.claimDue(""); len(due) != 2 {
+		t.Errorf("expected both themes due after resuming, got %+v", due)
+	}
+}
+
+func TestThemeJobsWithOwnSchedule(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	themes := []config.ThemeConfig{
+		{Name: "sci-fi", ChannelID: "c1"},
+		{Name: "horror", ChannelID: "c2", Schedule: "0 21 * * 5-6"},
+	}
+
+	sched, err := NewScheduler(&Config{}, nil, themes, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sched.scheduleGeneration("0 20 * * *", false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	sched.cron.Start()
+	defer sched.cron.Stop()
+
+	jobs := sched.Jobs()
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	scifi, horror := jobs[0], jobs[1]
+	if scifi.Schedule != "0 20 * * *" {
+		t.Errorf("expected sci-fi on the global schedule, got %q", scifi.Schedule)
+	}
+	if horror.Schedule != "0 21 * * 5-6" {
+		t.Errorf("expected horror on its own schedule, got %q", horror.Schedule)
+	}
+	if horror.NextRun == nil || horror.NextRun.Hour() != 21 || (horror.NextRun.Weekday() != time.Friday && horror.NextRun.Weekday() != time.Saturday) {
+		t.Errorf("expected horror to run next on a Friday or Saturday at 21:00, got %v", horror.NextRun)
+	}
+
+	// The global run leaves out themes with their own schedule
+	if due := sched.claimDue(""); len(due) != 1 || due[0].Name != "sci-fi" {
+		t.Errorf("expected only sci-fi due on the global schedule, got %+v", due)
+	}
+	if due := sched.claimDue("horror"); len(due) != 1 || due[0].Name != "horror" {
+		t.Errorf("expected horror due on its own schedule, got %+v", due)
+	}
+
+	// Reloaded themes are rescheduled
+	sched.SetThemes([]config.ThemeConfig{{Name: "sci-fi", ChannelID: "c1", Schedule: "30 18 * * *"}})
+	if jobs := sched.Jobs(); len(jobs) != 1 || jobs[0].Schedule != "30 18 * * *" {
+		t.Errorf("expected the reloaded sci-fi schedule, got %+v", jobs)
+	}
+	if n := len(sched.cron.Entries()); n != 2 {
+		t.Errorf("expected the global and sci-fi entries after reload, got %d", n)
+	}
+}
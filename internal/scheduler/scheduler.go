@@ -5,20 +5,54 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
 
+	"github.com/geekxflood/program-director/internal/alerts"
 	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/events"
+	"github.com/geekxflood/program-director/internal/logging"
+	"github.com/geekxflood/program-director/internal/notify"
+	"github.com/geekxflood/program-director/internal/services/lists"
+	"github.com/geekxflood/program-director/internal/services/media"
 	"github.com/geekxflood/program-director/internal/services/playlist"
+	"github.com/geekxflood/program-director/internal/services/watched"
+	"github.com/geekxflood/program-director/pkg/models"
 )
 
 // Scheduler handles automated playlist generation on a cron schedule
 type Scheduler struct {
-	cron      *cron.Cron
-	generator *playlist.Generator
-	themes    []config.ThemeConfig
-	logger    *slog.Logger
+	cron       *cron.Cron
+	cronLogger cron.Logger
+	generator  *playlist.Generator
+	notifier   *notify.Notifier
+	alerts     *alerts.Monitor
+	leader     database.LeaderLock         // nil runs every job on this instance
+	pause      *repository.PauseRepository // nil never pauses jobs
+	audit      *repository.AuditRepository // nil records no audit entries
+	events     *events.Bus
+	logger     *slog.Logger
+
+	listSync    *lists.SyncService // nil syncs no lists
+	listConfigs []config.ListConfig
+	watchedSync *watched.SyncService // nil syncs no watch history
+
+	mu         sync.Mutex
+	leading    bool // Whether the leader lock was held at the last check
+	themes     []config.ThemeConfig
+	syncEntry  cron.EntryID // 0 while no sync is scheduled
+	pruneEntry cron.EntryID // 0 while no history pruning is scheduled
+
+	generationEntry    cron.EntryID // 0 while no generation is scheduled
+	generationSchedule string
+	generationDryRun   bool
+	themeEntries       map[string]cron.EntryID // Themes with their own schedule, by name
+	states             map[string]*themeState
 }
 
 // Config holds scheduler configuration
@@ -54,34 +88,184 @@ func NewScheduler(
 	)
 
 	return &Scheduler{
-		cron:      c,
-		generator: generator,
-		themes:    themes,
-		logger:    logger,
+		cron:         c,
+		cronLogger:   cronLogger,
+		generator:    generator,
+		themes:       themes,
+		logger:       logger,
+		themeEntries: make(map[string]cron.EntryID),
+		states:       make(map[string]*themeState),
 	}, nil
 }
 
-// Start starts the scheduler
+// SetThemes swaps the themes used by scheduled generation, starting with the next run, and
+// reschedules themes with their own schedule
+func (s *Scheduler) SetThemes(themes []config.ThemeConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.themes = themes
+	if s.generationEntry != 0 {
+		s.scheduleThemes()
+	}
+}
+
+// SetNotifier sets where summaries of scheduled runs are sent
+func (s *Scheduler) SetNotifier(n *notify.Notifier) {
+	s.notifier = n
+}
+
+// SetAlerts sets the monitor scheduled generations are reported to
+func (s *Scheduler) SetAlerts(m *alerts.Monitor) {
+	s.alerts = m
+}
+
+// SetLeaderLock makes scheduled jobs run only on the instance holding lock, so a single one
+// of several replicas sharing a database runs them. The lock is taken when a job is due and
+// kept until Stop.
+func (s *Scheduler) SetLeaderLock(lock database.LeaderLock) {
+	s.leader = lock
+}
+
+// SetPause makes scheduled jobs skip their runs while automated actions are paused
+func (s *Scheduler) SetPause(pause *repository.PauseRepository) {
+	s.pause = pause
+}
+
+// SetAudit sets where scheduled generations are recorded, attributed to this host
+func (s *Scheduler) SetAudit(audit *repository.AuditRepository) {
+	s.audit = audit
+}
+
+// SetEvents sets the bus finished runs of themes are published to
+func (s *Scheduler) SetEvents(bus *events.Bus) {
+	s.events = bus
+}
+
+// SetListSync sets the service scheduled syncs import the configured lists with
+func (s *Scheduler) SetListSync(syncService *lists.SyncService, cfgs []config.ListConfig) {
+	s.listSync = syncService
+	s.listConfigs = cfgs
+}
+
+// SetWatchedSync sets the service scheduled syncs import Tautulli watch history with
+func (s *Scheduler) SetWatchedSync(syncService *watched.SyncService) {
+	s.watchedSync = syncService
+}
+
+// ScheduleSync adds a job that syncs movies and series from Radarr/Sonarr on a cron schedule,
+// removing stale media when cleanup is set, then lists and watch history when their services
+// are set. A run is skipped while the previous one is still going.
+// It replaces a previously scheduled sync, and an empty schedule only removes it.
+func (s *Scheduler) ScheduleSync(schedule string, cleanup bool, syncService *media.SyncService) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if schedule == "" {
+		if s.syncEntry != 0 {
+			s.cron.Remove(s.syncEntry)
+			s.syncEntry = 0
+			s.logger.Info("unscheduled media sync")
+		}
+		return nil
+	}
+
+	job := cron.NewChain(cron.SkipIfStillRunning(s.cronLogger)).Then(cron.FuncJob(func() {
+		runCtx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		defer cancel()
+		runCtx = logging.WithRequestID(runCtx, logging.NewRequestID())
+		if !s.runs(runCtx, "sync") {
+			return
+		}
+		s.runSync(runCtx, syncService, cleanup)
+	}))
+
+	id, err := s.cron.AddJob(schedule, job)
+	if err != nil {
+		return fmt.Errorf("failed to add sync job: %w", err)
+	}
+	if s.syncEntry != 0 {
+		s.cron.Remove(s.syncEntry)
+	}
+	s.syncEntry = id
+
+	s.logger.Info("scheduled media sync", "schedule", schedule, "cleanup", cleanup)
+	return nil
+}
+
+// SchedulePrune adds a job that deletes play history older than cfg.RetentionDays on
+// cfg.PruneSchedule. It replaces a previously scheduled prune, and a retention of 0 only
+// removes it.
+func (s *Scheduler) SchedulePrune(cfg config.HistoryConfig, historyRepo *repository.HistoryRepository) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cfg.RetentionDays <= 0 {
+		if s.pruneEntry != 0 {
+			s.cron.Remove(s.pruneEntry)
+			s.pruneEntry = 0
+			s.logger.Info("unscheduled history pruning")
+		}
+		return nil
+	}
+
+	job := cron.NewChain(cron.SkipIfStillRunning(s.cronLogger)).Then(cron.FuncJob(func() {
+		runCtx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		runCtx = logging.WithRequestID(runCtx, logging.NewRequestID())
+		if !s.runs(runCtx, "prune") {
+			return
+		}
+		s.runPrune(runCtx, historyRepo, cfg.RetentionDays)
+	}))
+
+	id, err := s.cron.AddJob(cfg.PruneSchedule, job)
+	if err != nil {
+		return fmt.Errorf("failed to add prune job: %w", err)
+	}
+	if s.pruneEntry != 0 {
+		s.cron.Remove(s.pruneEntry)
+	}
+	s.pruneEntry = id
+
+	s.logger.Info("scheduled history pruning", "schedule", cfg.PruneSchedule, "retention_days", cfg.RetentionDays)
+	return nil
+}
+
+// ScheduleMaintenance adds a job that runs database maintenance on schedule, rebuilding the
+// database file as well when vacuum is set
+func (s *Scheduler) ScheduleMaintenance(schedule string, vacuum bool, db database.Maintainer) error {
+	job := cron.NewChain(cron.SkipIfStillRunning(s.cronLogger)).Then(cron.FuncJob(func() {
+		runCtx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		runCtx = logging.WithRequestID(runCtx, logging.NewRequestID())
+		if !s.runs(runCtx, "maintenance") {
+			return
+		}
+		if _, err := db.Maintain(runCtx, vacuum); err != nil {
+			s.logger.ErrorContext(runCtx, "database maintenance failed", "error", err)
+		}
+	}))
+
+	if _, err := s.cron.AddJob(schedule, job); err != nil {
+		return fmt.Errorf("failed to add maintenance job: %w", err)
+	}
+	s.logger.Info("scheduled database maintenance", "schedule", schedule, "vacuum", vacuum)
+	return nil
+}
+
+// Start starts the scheduler. Playlist generation is scheduled unless schedule is empty.
 func (s *Scheduler) Start(ctx context.Context, schedule string, dryRun bool) error {
 	s.logger.Info("starting scheduler",
 		"schedule", schedule,
-		"themes", len(s.themes),
+		"themes", len(s.currentThemes()),
 		"dry_run", dryRun,
 	)
 
 	// Add generation job
-	_, err := s.cron.AddFunc(schedule, func() {
-		// Create a new context with timeout for each run.
-		// Note: We use context.Background() here instead of the parent context because:
-		// 1. Each cron job execution should have its own independent context
-		// 2. The parent context (ctx) is tied to the scheduler's lifecycle, not individual runs
-		// 3. We want each run to have a fresh 30-minute timeout regardless of when it starts
-		runCtx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-		defer cancel()
-		s.runGeneration(runCtx, dryRun)
-	})
-	if err != nil {
-		return fmt.Errorf("failed to add cron job: %w", err)
+	if schedule != "" {
+		if err := s.scheduleGeneration(schedule, dryRun); err != nil {
+			return err
+		}
 	}
 
 	// Start cron scheduler
@@ -96,58 +280,287 @@ func (s *Scheduler) Start(ctx context.Context, schedule string, dryRun bool) err
 	return s.Stop()
 }
 
-// Stop stops the scheduler
+// scheduleGeneration adds the generation job for themes without their own schedule on
+// schedule, and a job for each theme with one
+func (s *Scheduler) scheduleGeneration(schedule string, dryRun bool) error {
+	id, err := s.cron.AddFunc(schedule, func() { s.runScheduledGeneration(dryRun, "") })
+	if err != nil {
+		return fmt.Errorf("failed to add cron job: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generationEntry, s.generationSchedule, s.generationDryRun = id, schedule, dryRun
+	s.scheduleThemes()
+	return nil
+}
+
+// scheduleThemes replaces the jobs of themes with their own schedule. s.mu must be held.
+func (s *Scheduler) scheduleThemes() {
+	for name, id := range s.themeEntries {
+		s.cron.Remove(id)
+		delete(s.themeEntries, name)
+	}
+	dryRun := s.generationDryRun
+	for _, theme := range s.themes {
+		if theme.Schedule == "" {
+			continue
+		}
+		name := theme.Name
+		id, err := s.cron.AddFunc(theme.Schedule, func() { s.runScheduledGeneration(dryRun, name) })
+		if err != nil {
+			// Config validation rejects invalid schedules, so this only guards programmatic themes
+			s.logger.Error("failed to schedule theme", "theme", name, "schedule", theme.Schedule, "error", err)
+			continue
+		}
+		s.themeEntries[name] = id
+	}
+}
+
+// runScheduledGeneration runs a scheduled generation of theme, or of every theme without its
+// own schedule when theme is empty
+func (s *Scheduler) runScheduledGeneration(dryRun bool, theme string) {
+	// Create a new context with timeout for each run.
+	// Note: We use context.Background() here instead of the scheduler's context because:
+	// 1. Each cron job execution should have its own independent context
+	// 2. The scheduler's context is tied to its lifecycle, not individual runs
+	// 3. We want each run to have a fresh 30-minute timeout regardless of when it starts
+	runCtx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	// Tag the run's logs like an API request so it can be traced the same way
+	runCtx = logging.WithRequestID(runCtx, logging.NewRequestID())
+	if !s.runs(runCtx, "generation") {
+		return
+	}
+	s.runGeneration(runCtx, dryRun, theme)
+}
+
+// Stop stops the scheduler, waiting for running jobs, and gives up the leader lock
 func (s *Scheduler) Stop() error {
 	ctx := s.cron.Stop()
 	<-ctx.Done()
+	if s.leader != nil {
+		if err := s.leader.Release(); err != nil {
+			return err
+		}
+	}
 	s.logger.Info("scheduler stopped")
 	return nil
 }
 
-// runGeneration executes playlist generation for all themes
-func (s *Scheduler) runGeneration(ctx context.Context, dryRun bool) {
+// runs reports whether a due job runs: on the leader, unless automated actions are paused. A
+// job is skipped when the pause cannot be checked, to stay off channels under maintenance.
+func (s *Scheduler) runs(ctx context.Context, job string) bool {
+	if !s.leads(ctx, job) {
+		return false
+	}
+	if s.pause == nil {
+		return true
+	}
+
+	pause, err := s.pause.Get(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to check automation pause, skipping scheduled job", "job", job, "error", err)
+		return false
+	}
+	if pause != nil {
+		s.logger.InfoContext(ctx, "skipping scheduled job, automation is paused", "job", job, "reason", pause.Reason, "since", pause.PausedAt)
+		return false
+	}
+	return true
+}
+
+// leads reports whether this instance runs a due job, taking the leader lock when it is
+// free. A job is skipped when the lock cannot be checked, as another instance may run it.
+func (s *Scheduler) leads(ctx context.Context, job string) bool {
+	if s.leader == nil {
+		return true
+	}
+
+	held, err := s.leader.TryAcquire(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to check leader lock, skipping scheduled job", "job", job, "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	changed := held != s.leading
+	s.leading = held
+	s.mu.Unlock()
+
+	switch {
+	case changed && held:
+		s.logger.InfoContext(ctx, "took leader lock, scheduled jobs run on this instance")
+	case changed:
+		s.logger.InfoContext(ctx, "lost leader lock, scheduled jobs run on another instance")
+	}
+	if !held {
+		s.logger.DebugContext(ctx, "skipping scheduled job, another instance holds the leader lock", "job", job)
+	}
+	return held
+}
+
+// runGeneration executes playlist generation for theme, or for all themes without their own
+// schedule when theme is empty, leaving out paused themes
+func (s *Scheduler) runGeneration(ctx context.Context, dryRun bool, theme string) {
 	start := time.Now()
+	themes := s.claimDue(theme)
+	names := make([]string, len(themes))
+	for i, theme := range themes {
+		names[i] = theme.Name
+	}
 
-	s.logger.Info("scheduled generation started",
-		"themes", len(s.themes),
+	if len(themes) == 0 {
+		s.logger.InfoContext(ctx, "scheduled generation skipped, all themes paused or running")
+		return
+	}
+	s.logger.InfoContext(ctx, "scheduled generation started",
+		"themes", len(themes),
 		"dry_run", dryRun,
 	)
+	s.recordAudit(ctx, names, dryRun)
 
-	results, err := s.generator.GenerateAll(ctx, s.themes, dryRun)
+	summary, err := s.generator.GenerateAll(ctx, themes, dryRun, false)
+	s.finish(names, summary.Results, start)
 	if err != nil {
-		s.logger.Error("generation failed", "error", err)
+		s.logger.ErrorContext(ctx, "generation failed", "error", err)
 		return
 	}
 
 	// Log results
-	var successCount, failCount int
-	for _, result := range results {
+	for _, result := range summary.Results {
 		if result.Error != nil {
-			failCount++
-			s.logger.Error("theme generation failed",
+			s.logger.ErrorContext(ctx, "theme generation failed",
 				"theme", result.ThemeName,
+				"attempts", result.Attempts,
 				"error", result.Error,
 			)
-		} else if result.Generated {
-			successCount++
-			s.logger.Info("theme generation succeeded",
+		} else if result.SkipReason != "" {
+			s.logger.InfoContext(ctx, "theme generation skipped",
 				"theme", result.ThemeName,
-				"items", result.ItemCount,
-				"duration", result.Duration,
+				"reason", result.SkipReason,
 			)
 		} else {
-			s.logger.Warn("theme generation skipped",
+			s.logger.InfoContext(ctx, "theme generation succeeded",
 				"theme", result.ThemeName,
+				"items", result.ItemCount,
+				"attempts", result.Attempts,
+				"duration", result.Duration,
 			)
 		}
 	}
 
-	s.logger.Info("scheduled generation complete",
-		"total", len(results),
-		"success", successCount,
-		"failed", failCount,
+	s.logger.InfoContext(ctx, "scheduled generation complete",
+		"total", len(summary.Results),
+		"success", len(summary.Generated),
+		"failed", len(summary.Failed),
+		"skipped", len(summary.Skipped),
+		"retried", len(summary.Retried),
 		"duration", time.Since(start),
 	)
+
+	if !dryRun {
+		s.notifier.NotifyGeneration(ctx, summary, themes)
+		s.alerts.RecordGenerations(ctx, summary.Results)
+	}
+}
+
+// recordAudit records a scheduled generation of themes in the audit log
+func (s *Scheduler) recordAudit(ctx context.Context, themes []string, dryRun bool) {
+	if s.audit == nil {
+		return
+	}
+	host, _ := os.Hostname()
+	entry := &models.AuditEntry{
+		ActorType:  models.AuditActorScheduler,
+		Actor:      host,
+		Action:     models.AuditActionGenerateAll,
+		Parameters: models.AuditParameters{"dry_run": dryRun, "themes": themes},
+		RequestID:  logging.RequestID(ctx),
+	}
+	if err := s.audit.Create(ctx, entry); err != nil {
+		s.logger.WarnContext(ctx, "failed to record audit entry", "error", err)
+	}
+}
+
+// currentThemes returns the themes in effect
+func (s *Scheduler) currentThemes() []config.ThemeConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.themes
+}
+
+// runSync syncs movies and then series, then lists and watch history, logging the outcome of each.
+// The notification covers movies and series only.
+func (s *Scheduler) runSync(ctx context.Context, syncService *media.SyncService, cleanup bool) {
+	start := time.Now()
+	s.logger.InfoContext(ctx, "scheduled sync started", "cleanup", cleanup)
+
+	failed := false
+	var runs []notify.SyncRun
+	for _, run := range []struct {
+		kind string
+		sync func(context.Context, media.SyncOptions) (*media.SyncResult, error)
+	}{
+		{"movies", syncService.SyncMovies},
+		{"series", syncService.SyncSeries},
+	} {
+		result, err := run.sync(ctx, media.SyncOptions{Cleanup: cleanup})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "scheduled sync failed", "kind", run.kind, "error", err)
+			failed = true
+			runs = append(runs, notify.SyncRun{Kind: run.kind, Err: err})
+			continue
+		}
+		s.logger.InfoContext(ctx, "scheduled sync result",
+			"kind", run.kind,
+			"created", result.Created,
+			"updated", result.Updated,
+			"deleted", result.Deleted,
+			"errors", result.Errors,
+		)
+		runs = append(runs, notify.SyncRun{Kind: run.kind, Result: result})
+	}
+
+	// Lists and watch history match against the media just synced
+	if s.listSync != nil && len(s.listConfigs) > 0 {
+		for _, result := range s.listSync.SyncAll(ctx, s.listConfigs) {
+			if result.Error != nil {
+				failed = true
+				continue // Logged by SyncAll
+			}
+			s.logger.InfoContext(ctx, "scheduled list sync result", "list", result.Name, "items", result.Items)
+		}
+	}
+	if s.watchedSync != nil {
+		result, err := s.watchedSync.Sync(ctx)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "scheduled sync failed", "kind", "watched", "error", err)
+			failed = true
+		} else {
+			s.logger.InfoContext(ctx, "scheduled sync result",
+				"kind", "watched",
+				"fetched", result.Fetched,
+				"created", result.Created,
+				"unmatched", result.Unmatched,
+				"errors", result.Errors,
+			)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "scheduled sync complete", "failed", failed, "duration", time.Since(start))
+	s.notifier.NotifySync(ctx, runs)
+}
+
+// runPrune deletes play history older than retentionDays
+func (s *Scheduler) runPrune(ctx context.Context, historyRepo *repository.HistoryRepository, retentionDays int) {
+	before := time.Now().AddDate(0, 0, -retentionDays)
+	deleted, err := historyRepo.DeleteOlderThan(ctx, before)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "history pruning failed", "error", err)
+		return
+	}
+	s.logger.InfoContext(ctx, "pruned play history", "deleted", deleted, "before", before.Format(time.DateOnly))
 }
 
 // GetNextRun returns the next scheduled run time
@@ -2,12 +2,22 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/geekxflood/program-director/internal/clients/tautulli"
 	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/internal/services/watched"
 )
 
 // mockGenerator is a mock implementation of the playlist generator
@@ -150,3 +160,251 @@ func TestSchedulerStartStop(t *testing.T) {
 
 	// Test should complete without hanging
 }
+
+func TestScheduleSync(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	sched, err := NewScheduler(&Config{}, nil, nil, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := sched.ScheduleSync("not a schedule", false, nil); err == nil {
+		t.Error("expected error for invalid sync schedule")
+	}
+
+	if err := sched.ScheduleSync("0 3 * * *", true, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n := len(sched.cron.Entries()); n != 1 {
+		t.Errorf("expected 1 cron entry, got %d", n)
+	}
+}
+
+func TestScheduleSyncReplaces(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	sched, err := NewScheduler(&Config{}, nil, nil, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := sched.ScheduleSync("0 3 * * *", false, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sched.ScheduleSync("0 4 * * *", false, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n := len(sched.cron.Entries()); n != 1 {
+		t.Errorf("expected rescheduling to keep 1 cron entry, got %d", n)
+	}
+
+	// An invalid schedule keeps the current one
+	if err := sched.ScheduleSync("not a schedule", false, nil); err == nil {
+		t.Error("expected error for invalid sync schedule")
+	}
+	if n := len(sched.cron.Entries()); n != 1 {
+		t.Errorf("expected 1 cron entry after a failed reschedule, got %d", n)
+	}
+
+	if err := sched.ScheduleSync("", false, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n := len(sched.cron.Entries()); n != 0 {
+		t.Errorf("expected no cron entries after unscheduling, got %d", n)
+	}
+}
+
+func TestSchedulePrune(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	sched, err := NewScheduler(&Config{}, nil, nil, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := sched.SchedulePrune(config.HistoryConfig{RetentionDays: 30, PruneSchedule: "not a schedule"}, nil); err == nil {
+		t.Error("expected error for invalid prune schedule")
+	}
+
+	if err := sched.SchedulePrune(config.HistoryConfig{RetentionDays: 30, PruneSchedule: "0 4 * * *"}, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sched.SchedulePrune(config.HistoryConfig{RetentionDays: 90, PruneSchedule: "0 5 * * *"}, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n := len(sched.cron.Entries()); n != 1 {
+		t.Errorf("expected rescheduling to keep 1 cron entry, got %d", n)
+	}
+
+	// No retention only unschedules, whatever the schedule
+	if err := sched.SchedulePrune(config.HistoryConfig{PruneSchedule: "not a schedule"}, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n := len(sched.cron.Entries()); n != 0 {
+		t.Errorf("expected no cron entries after unscheduling, got %d", n)
+	}
+}
+
+func TestScheduleMaintenance(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	sched, err := NewScheduler(&Config{}, nil, nil, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := sched.ScheduleMaintenance("not a schedule", false, nil); err == nil {
+		t.Error("expected error for invalid maintenance schedule")
+	}
+	if err := sched.ScheduleMaintenance("30 4 * * 0", true, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n := len(sched.cron.Entries()); n != 1 {
+		t.Errorf("expected 1 cron entry, got %d", n)
+	}
+}
+
+func TestSetThemes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	sched, err := NewScheduler(&Config{}, nil, []config.ThemeConfig{{Name: "old"}}, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sched.SetThemes([]config.ThemeConfig{{Name: "new"}, {Name: "other"}})
+	if themes := sched.currentThemes(); len(themes) != 2 || themes[0].Name != "new" {
+		t.Errorf("unexpected themes after SetThemes: %+v", themes)
+	}
+}
+
+// fakeLeaderLock is a LeaderLock whose availability the test controls
+type fakeLeaderLock struct {
+	free     bool
+	err      error
+	released bool
+}
+
+func (l *fakeLeaderLock) TryAcquire(context.Context) (bool, error) {
+	return l.free, l.err
+}
+
+func (l *fakeLeaderLock) Release() error {
+	l.released = true
+	return nil
+}
+
+func TestLeads(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	sched, err := NewScheduler(&Config{}, nil, nil, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !sched.leads(ctx, "sync") {
+		t.Error("expected jobs to run without a leader lock")
+	}
+
+	lock := &fakeLeaderLock{}
+	sched.SetLeaderLock(lock)
+	if sched.leads(ctx, "sync") {
+		t.Error("expected jobs to be skipped while another instance holds the lock")
+	}
+
+	lock.free = true
+	if !sched.leads(ctx, "sync") {
+		t.Error("expected jobs to run once the lock is taken")
+	}
+
+	lock.err = errors.New("connection refused")
+	if sched.leads(ctx, "sync") {
+		t.Error("expected jobs to be skipped when the lock cannot be checked")
+	}
+
+	if err := sched.Stop(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !lock.released {
+		t.Error("expected Stop to release the leader lock")
+	}
+}
+
+func TestRunsWhilePaused(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db, err := database.NewSQLite(ctx, &config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")}, logger)
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	pause := repository.NewPauseRepository(db)
+
+	sched, err := NewScheduler(&Config{}, nil, nil, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	sched.SetPause(pause)
+	if !sched.runs(ctx, "sync") {
+		t.Error("expected jobs to run while not paused")
+	}
+
+	if _, err := pause.Pause(ctx, "tunarr upgrade"); err != nil {
+		t.Fatal(err)
+	}
+	if sched.runs(ctx, "sync") {
+		t.Error("expected jobs to be skipped while paused")
+	}
+
+	if resumed, err := pause.Resume(ctx); err != nil || !resumed {
+		t.Fatalf("Resume() = %v, %v", resumed, err)
+	}
+	if !sched.runs(ctx, "sync") {
+		t.Error("expected jobs to run once resumed")
+	}
+}
+
+func TestRunSyncImportsWatchHistory(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db, err := database.NewSQLite(ctx, &config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")}, logger)
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	mediaRepo := repository.NewMediaRepository(db)
+	watchRepo := repository.NewWatchHistoryRepository(db)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"response": {"result": "success", "data": {"data": [
+			{"id": 12, "date": 1735900000, "user": "alex", "media_type": "movie", "title": "Heat", "year": 1995, "watched_status": 1}
+		]}}}`))
+	}))
+	defer server.Close()
+
+	sched, err := NewScheduler(&Config{}, nil, nil, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	sched.SetWatchedSync(watched.NewSyncService(tautulli.New(&config.TautulliConfig{URL: server.URL}), mediaRepo, watchRepo, logger))
+
+	syncService := media.NewSyncService(nil, nil, nil, nil, mediaRepo, repository.NewCollectionRepository(db), repository.NewSyncCheckpointRepository(db), logger)
+	sched.runSync(ctx, syncService, false)
+
+	latest, err := watchRepo.LatestWatchedAt(ctx, watched.SourceTautulli)
+	if err != nil {
+		t.Fatalf("LatestWatchedAt() error = %v", err)
+	}
+	if !latest.Equal(time.Unix(1735900000, 0)) {
+		t.Errorf("latest watch = %v, want the Tautulli session imported by the scheduled sync", latest)
+	}
+}
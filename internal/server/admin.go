@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// handleAdminReload re-reads the config file and applies theme, cooldown, and schedule
+// changes, answering with what changed. It requires an API key even though other /api/v1
+// routes are open while no key is configured.
+func (s *Server) handleAdminReload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	configured, err := s.keysConfigured(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "API key check failed", "error", err)
+		writeError(w, http.StatusInternalServerError, errors.New("authentication unavailable"), "")
+		return
+	}
+	if !configured {
+		writeError(w, http.StatusForbidden, errors.New("forbidden"), "admin endpoints require an API key to be configured")
+		return
+	}
+
+	if s.reloader == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("config reload is not available"), "")
+		return
+	}
+
+	old, updated, err := s.reloader.Reload()
+	if err != nil {
+		s.logger.WarnContext(ctx, "config reload failed, keeping current config", "trigger", "api", "error", err)
+		writeError(w, http.StatusUnprocessableEntity, err, "the current configuration was kept")
+		return
+	}
+
+	diff := config.DiffConfigs(old, updated)
+	s.logger.InfoContext(ctx, "config reloaded",
+		"trigger", "api",
+		"themes", len(updated.Themes),
+		"sections_changed", diff.Sections,
+		"themes_added", diff.ThemesAdded,
+		"themes_removed", diff.ThemesRemoved,
+		"themes_changed", len(diff.ThemesChanged),
+	)
+
+	message := "configuration reloaded"
+	if diff.Empty() {
+		message = "configuration unchanged"
+	}
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data:    diff,
+		Message: message,
+	})
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestHandleAdminReload(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	valid := `
+radarr:
+  url: http://radarr:7878
+  api_key: radarr-key
+sonarr:
+  url: http://sonarr:8989
+  api_key: sonarr-key
+server:
+  api_keys: [secret]
+themes:
+  - name: sci-fi
+    channel_id: ch1
+`
+	invalid := valid + "  - name: broken\n"
+
+	tests := []struct {
+		name     string
+		method   string
+		keys     []string
+		file     string // config file contents; empty for no reloader
+		want     int
+		wantAdds []string
+	}{
+		{name: "wrong method", method: http.MethodGet, keys: []string{"secret"}, want: http.StatusMethodNotAllowed},
+		{name: "no keys configured", method: http.MethodPost, file: valid, want: http.StatusForbidden},
+		{name: "reload unavailable", method: http.MethodPost, keys: []string{"secret"}, want: http.StatusServiceUnavailable},
+		{name: "invalid config kept", method: http.MethodPost, keys: []string{"secret"}, file: invalid, want: http.StatusUnprocessableEntity},
+		{name: "reloaded", method: http.MethodPost, keys: []string{"secret"}, file: valid, want: http.StatusOK, wantAdds: []string{"sci-fi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Server: config.ServerConfig{APIKeys: tt.keys}}
+			serverCfg := &Config{}
+			if tt.file != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+				serverCfg.Reloader = config.NewReloader(path, cfg, logger)
+			}
+			s := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+			recorder := httptest.NewRecorder()
+			routes(s).ServeHTTP(recorder, httptest.NewRequest(tt.method, "/api/v1/admin/reload", nil))
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data config.Diff `json:"data"`
+			}
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(body.Data.ThemesAdded) != len(tt.wantAdds) || body.Data.ThemesAdded[0] != tt.wantAdds[0] {
+				t.Errorf("themes_added = %v, want %v", body.Data.ThemesAdded, tt.wantAdds)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/logging"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// defaultAuditLimit caps how many audit entries are returned when no limit is given
+const defaultAuditLimit = 100
+
+// audit records that a request triggered action, attributed to the API key it was authorized
+// with and the client address. A failure is logged without failing the request.
+func (s *Server) audit(r *http.Request, action, theme string, params models.AuditParameters) {
+	if s.auditRepo == nil {
+		return
+	}
+	if params == nil {
+		params = models.AuditParameters{}
+	}
+	params["client_ip"] = clientIP(r)
+
+	ctx := r.Context()
+	entry := &models.AuditEntry{
+		ActorType:  models.AuditActorAPI,
+		Actor:      requestKeyName(ctx),
+		Action:     action,
+		ThemeName:  theme,
+		Parameters: params,
+		RequestID:  logging.RequestID(ctx),
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		s.logger.WarnContext(ctx, "failed to record audit entry", "action", action, "error", err)
+	}
+}
+
+// handleAudit lists who or what triggered generations, newest first. Supported filters are
+// actor_type (cli, api, scheduler), actor, action, theme, since and until (RFC 3339), limit,
+// and offset.
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if s.auditRepo == nil {
+		writeError(w, http.StatusNotFound, errors.New("audit log is not available"), "")
+		return
+	}
+
+	opts, err := parseAuditOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err, "invalid query parameters")
+		return
+	}
+
+	entries, err := s.auditRepo.List(r.Context(), opts)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to list audit log", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query audit log")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"entries": entries,
+			"count":   len(entries),
+		},
+	})
+}
+
+// parseAuditOptions reads audit log filters from the query string
+func parseAuditOptions(r *http.Request) (repository.ListAuditOptions, error) {
+	query := r.URL.Query()
+	opts := repository.ListAuditOptions{
+		ActorType: models.AuditActorType(query.Get("actor_type")),
+		Actor:     query.Get("actor"),
+		Action:    query.Get("action"),
+		ThemeName: query.Get("theme"),
+		Limit:     defaultAuditLimit,
+	}
+
+	switch opts.ActorType {
+	case "", models.AuditActorCLI, models.AuditActorAPI, models.AuditActorScheduler:
+	default:
+		return opts, fmt.Errorf("invalid actor_type %q (must be cli, api, or scheduler)", opts.ActorType)
+	}
+
+	for name, dst := range map[string]*time.Time{"since": &opts.Since, "until": &opts.Until} {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dst = t
+		}
+	}
+
+	for name, dst := range map[string]*int{"limit": &opts.Limit, "offset": &opts.Offset} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return opts, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*dst = n
+		}
+	}
+
+	return opts, nil
+}
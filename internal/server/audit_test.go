@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestParseAuditOptions(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v1/audit?actor_type=api&actor=home-assistant&action=generate&theme=noir&since=2025-06-01T00:00:00Z&limit=10", nil)
+
+	opts, err := parseAuditOptions(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if opts.ActorType != models.AuditActorAPI || opts.Actor != "home-assistant" || opts.Action != "generate" ||
+		opts.ThemeName != "noir" || opts.Limit != 10 {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if !opts.Since.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected since %v", opts.Since)
+	}
+
+	defaults, err := parseAuditOptions(httptest.NewRequest(http.MethodGet, "/api/v1/audit", nil))
+	if err != nil || defaults.Limit != defaultAuditLimit {
+		t.Errorf("expected default limit %d, got %d (%v)", defaultAuditLimit, defaults.Limit, err)
+	}
+
+	for _, query := range []string{"actor_type=robot", "until=tomorrow", "offset=-5"} {
+		if _, err := parseAuditOptions(httptest.NewRequest(http.MethodGet, "/api/v1/audit?"+query, nil)); err == nil {
+			t.Errorf("expected error for %s", query)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// requireAPIKey guards /api/v1 routes with the configured static keys and the keys stored in
+// the database, and checks the key's role allows the route. Other routes, such as /health,
+// are always open, and webhook routes with a secret are verified by their handlers instead.
+func (s *Server) requireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/v1/") || s.webhookSecured(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, ok, err := s.authorized(r.Context(), requestAPIKey(r))
+		if err != nil {
+			s.logger.ErrorContext(r.Context(), "API key check failed", "error", err)
+			writeError(w, http.StatusInternalServerError, errors.New("authentication unavailable"), "")
+			return
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="program-director"`)
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"), "a valid API key is required")
+			return
+		}
+		if required := requiredRole(r); !key.Role.Allows(required) {
+			s.logger.WarnContext(r.Context(), "API key role denied", "key", key.Name, "role", key.Role, "required", required)
+			writeError(w, http.StatusForbidden, errors.New("forbidden"), fmt.Sprintf("this route requires the %s role", required))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+	})
+}
+
+// requiredRole returns the role a request needs: admin for /api/v1/admin routes, read-only
+// for reads, and operator for anything else, such as generating or syncing
+func requiredRole(r *http.Request) models.APIKeyRole {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/v1/admin/"):
+		return models.APIKeyRoleAdmin
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		return models.APIKeyRoleReadOnly
+	default:
+		return models.APIKeyRoleOperator
+	}
+}
+
+// apiKeyContextKey is the context key of the API key a request was authorized with
+type apiKeyContextKey struct{}
+
+// requestKeyName returns the name of the API key a request was authorized with: the stored
+// key's name, or "static #N" for the Nth key of server.api_keys. It is empty while access is
+// open.
+func requestKeyName(ctx context.Context) string {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*models.APIKey)
+	if key == nil {
+		return ""
+	}
+	return key.Name
+}
+
+// authorized reports whether key grants access, and the key it matched. Keys of
+// server.api_keys are admins. Access is open, as an unnamed admin, while no key is
+// configured.
+func (s *Server) authorized(ctx context.Context, key string) (*models.APIKey, bool, error) {
+	if key != "" {
+		for i, k := range s.cfg().Server.APIKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+				return &models.APIKey{Name: fmt.Sprintf("static #%d", i+1), Role: models.APIKeyRoleAdmin}, true, nil
+			}
+		}
+
+		if s.apiKeyRepo != nil {
+			stored, err := s.apiKeyRepo.GetByHash(ctx, models.HashAPIKey(key))
+			switch {
+			case err == nil:
+				if err := s.apiKeyRepo.Touch(ctx, stored.ID); err != nil {
+					s.logger.WarnContext(ctx, "failed to record API key use", "key", stored.Name, "error", err)
+				}
+				return stored, true, nil
+			case !errors.Is(err, sql.ErrNoRows):
+				return nil, false, err
+			}
+		}
+	}
+
+	configured, err := s.keysConfigured(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return &models.APIKey{Role: models.APIKeyRoleAdmin}, !configured, nil
+}
+
+// keysConfigured reports whether any API key exists; until one does, /api/v1 routes are open
+func (s *Server) keysConfigured(ctx context.Context) (bool, error) {
+	if len(s.cfg().Server.APIKeys) > 0 {
+		return true, nil
+	}
+	if s.apiKeyRepo == nil {
+		return false, nil
+	}
+	count, err := s.apiKeyRepo.Count(ctx)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// requestAPIKey reads the key from an "Authorization: Bearer" or X-API-Key header, or the
+// api_key query parameter for clients such as Plex webhooks that cannot set headers
+func requestAPIKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	var keyName string
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyName = requestKeyName(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name    string
+		keys    []string
+		path    string
+		headers map[string]string
+		want    int
+		keyName string
+	}{
+		{name: "open without keys", path: "/api/v1/themes", want: http.StatusOK},
+		{name: "missing key", keys: []string{"secret"}, path: "/api/v1/themes", want: http.StatusUnauthorized},
+		{name: "wrong key", keys: []string{"secret"}, path: "/api/v1/themes", headers: map[string]string{"X-API-Key": "nope"}, want: http.StatusUnauthorized},
+		{name: "bearer token", keys: []string{"other", "secret"}, path: "/api/v1/themes", headers: map[string]string{"Authorization": "Bearer secret"}, want: http.StatusOK, keyName: "static #2"},
+		{name: "api key header", keys: []string{"secret"}, path: "/api/v1/generate", headers: map[string]string{"X-API-Key": "secret"}, want: http.StatusOK, keyName: "static #1"},
+		{name: "query parameter", keys: []string{"secret"}, path: "/api/v1/webhooks/plex?api_key=secret", want: http.StatusOK, keyName: "static #1"},
+		{name: "basic auth rejected", keys: []string{"secret"}, path: "/api/v1/themes", headers: map[string]string{"Authorization": "Basic secret"}, want: http.StatusUnauthorized},
+		{name: "health bypassed", keys: []string{"secret"}, path: "/health", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Server: config.ServerConfig{APIKeys: tt.keys}}
+			s := NewServer(cfg, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			recorder := httptest.NewRecorder()
+			keyName = ""
+
+			s.requireAPIKey(ok).ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+			if keyName != tt.keyName {
+				t.Errorf("key name = %q, want %q", keyName, tt.keyName)
+			}
+			if tt.want == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestRequiredRole(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   models.APIKeyRole
+	}{
+		{http.MethodGet, "/api/v1/history", models.APIKeyRoleReadOnly},
+		{http.MethodGet, "/api/v1/guide.xml", models.APIKeyRoleReadOnly},
+		{http.MethodPost, "/api/v1/generate/noir", models.APIKeyRoleOperator},
+		{http.MethodDelete, "/api/v1/blocklist", models.APIKeyRoleOperator},
+		{http.MethodPost, "/api/v1/webhooks/plex", models.APIKeyRoleOperator},
+		{http.MethodPost, "/api/v1/admin/reload", models.APIKeyRoleAdmin},
+	}
+
+	for _, tt := range tests {
+		if got := requiredRole(httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+			t.Errorf("requiredRole(%s %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
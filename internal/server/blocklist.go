@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// blocklistRequest is the body of POST /api/v1/blocklist
+type blocklistRequest struct {
+	MediaID int64  `json:"media_id"`
+	IMDBID  string `json:"imdb_id"`
+	Reason  string `json:"reason"`
+}
+
+// handleListBlocklist lists blocked media
+func (s *Server) handleListBlocklist(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.blocklistRepo.List(r.Context())
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to list blocklist", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query blocklist")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"blocklist": entries,
+			"count":     len(entries),
+		},
+	})
+}
+
+// handleAddBlocklist blocks the media of a blocklistRequest
+func (s *Server) handleAddBlocklist(w http.ResponseWriter, r *http.Request) {
+	var req blocklistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err, "invalid JSON payload")
+		return
+	}
+
+	entry, err := models.NewBlocklistEntry(req.MediaID, req.IMDBID, req.Reason)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err, "")
+		return
+	}
+
+	created, err := s.blocklistRepo.Add(r.Context(), entry)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to add blocklist entry", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to add blocklist entry")
+		return
+	}
+	if !created {
+		writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "already blocked"})
+		return
+	}
+
+	s.logger.InfoContext(r.Context(), "media blocked via API", "media_id", req.MediaID, "imdb_id", req.IMDBID)
+	writeJSON(w, http.StatusCreated, successResponse{Success: true, Data: entry, Message: "media blocked"})
+}
+
+// handleRemoveBlocklist unblocks the media of ?media_id= or ?imdb_id=
+func (s *Server) handleRemoveBlocklist(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var mediaID int64
+	if v := query.Get("media_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err, "invalid media_id")
+			return
+		}
+		mediaID = id
+	}
+	imdbID := query.Get("imdb_id")
+
+	// Validates that exactly one identifier was given
+	if _, err := models.NewBlocklistEntry(mediaID, imdbID, ""); err != nil {
+		writeError(w, http.StatusBadRequest, err, "")
+		return
+	}
+
+	removed, err := s.blocklistRepo.Remove(r.Context(), mediaID, imdbID)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to remove blocklist entry", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to remove blocklist entry")
+		return
+	}
+	if removed == 0 {
+		writeError(w, http.StatusNotFound, errors.New("not blocked"), "")
+		return
+	}
+
+	s.logger.InfoContext(r.Context(), "media unblocked via API", "media_id", mediaID, "imdb_id", imdbID)
+	writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "media unblocked"})
+}
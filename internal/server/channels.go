@@ -0,0 +1,134 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// m3uChannel is one entry of the channels playlist
+type m3uChannel struct {
+	ID     string
+	Number int
+	Name   string
+	Logo   string
+	Group  string
+	URL    string
+}
+
+// handleChannelsM3U serves an M3U playlist of the Tunarr streams of the channels targeted by
+// configured themes, with tvg attributes matching GET /api/v1/guide.xml
+func (s *Server) handleChannelsM3U(w http.ResponseWriter, r *http.Request) {
+	if s.tunarrClient == nil {
+		writeError(w, http.StatusNotFound, errors.New("tunarr is not configured"), "")
+		return
+	}
+
+	ctx := r.Context()
+	channels, err := s.tunarrClient.GetChannels(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get tunarr channels", "error", err)
+		writeError(w, errorStatus(err), err, "failed to get channels from tunarr")
+		return
+	}
+
+	entries, missing := managedChannels(s.cfg().Themes, channels, s.tunarrClient)
+	for _, id := range missing {
+		s.logger.WarnContext(ctx, "theme channel not found in tunarr", "channel_id", id)
+	}
+
+	w.Header().Set("Content-Type", "audio/x-mpegurl; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="channels.m3u"`)
+	w.WriteHeader(http.StatusOK)
+	_ = writeM3U(w, entries, guideURL(r))
+}
+
+// managedChannels returns the Tunarr channels targeted by themes, by channel number, and the
+// theme channel IDs Tunarr doesn't have
+func managedChannels(themes []config.ThemeConfig, channels []tunarr.Channel, client *tunarr.Client) ([]m3uChannel, []string) {
+	byID := make(map[string]tunarr.Channel, len(channels))
+	for _, ch := range channels {
+		byID[ch.ID] = ch
+	}
+
+	var entries []m3uChannel
+	var missing []string
+	seen := make(map[string]bool)
+	for _, theme := range themes {
+		if seen[theme.ChannelID] {
+			continue
+		}
+		seen[theme.ChannelID] = true
+
+		ch, ok := byID[theme.ChannelID]
+		if !ok {
+			missing = append(missing, theme.ChannelID)
+			continue
+		}
+		name := ch.Name
+		if name == "" {
+			name = theme.Name
+		}
+		entries = append(entries, m3uChannel{
+			ID:     ch.ID,
+			Number: ch.Number,
+			Name:   name,
+			Logo:   client.IconURL(ch),
+			Group:  ch.GroupTitle,
+			URL:    client.StreamURL(ch),
+		})
+	}
+
+	slices.SortStableFunc(entries, func(a, b m3uChannel) int { return a.Number - b.Number })
+	return entries, missing
+}
+
+// guideURL returns the absolute URL of the XMLTV guide as the client reached this server,
+// keeping the API key of a request authenticated with ?api_key=
+func guideURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	u := url.URL{Scheme: scheme, Host: r.Host, Path: "/api/v1/guide.xml"}
+	if key := r.URL.Query().Get("api_key"); key != "" {
+		u.RawQuery = url.Values{"api_key": {key}}.Encode()
+	}
+	return u.String()
+}
+
+// writeM3U writes an extended M3U playlist of channels, pointing players at the guide
+func writeM3U(w io.Writer, channels []m3uChannel, guide string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#EXTM3U url-tvg=\"%s\" x-tvg-url=\"%s\"\n", guide, guide)
+	for _, ch := range channels {
+		b.WriteString("#EXTINF:-1")
+		for _, attr := range [][2]string{
+			{"tvg-id", ch.ID},
+			{"tvg-chno", strconv.Itoa(ch.Number)},
+			{"tvg-name", ch.Name},
+			{"tvg-logo", ch.Logo},
+			{"group-title", ch.Group},
+		} {
+			if attr[1] != "" {
+				fmt.Fprintf(&b, ` %s="%s"`, attr[0], m3uAttr(attr[1]))
+			}
+		}
+		fmt.Fprintf(&b, ",%s\n%s\n", m3uAttr(ch.Name), ch.URL)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// m3uAttr makes a value safe inside a quoted M3U attribute or the title of an entry
+func m3uAttr(v string) string {
+	return strings.NewReplacer(`"`, "'", "\n", " ", "\r", " ").Replace(v)
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestManagedChannelsM3U(t *testing.T) {
+	client := tunarr.New(&config.TunarrConfig{URL: "http://tunarr:8000", PublicURL: "https://tv.example.com/"})
+	themes := []config.ThemeConfig{
+		{Name: "sci-fi", ChannelID: "c2"},
+		{Name: "sci-fi-weekend", ChannelID: "c2"},
+		{Name: "noir", ChannelID: "c1"},
+		{Name: "gone", ChannelID: "c9"},
+	}
+	channels := []tunarr.Channel{
+		{ID: "c1", Number: 1, Icon: tunarr.ChannelIcon{Path: "/images/noir.png"}},
+		{ID: "c2", Number: 2, Name: `Sci-Fi "Classics"`, GroupTitle: "Movies"},
+		{ID: "c3", Number: 3, Name: "Unmanaged"},
+	}
+
+	entries, missing := managedChannels(themes, channels, client)
+	if len(missing) != 1 || missing[0] != "c9" {
+		t.Errorf("expected c9 missing, got %v", missing)
+	}
+	if len(entries) != 2 || entries[0].ID != "c1" || entries[1].ID != "c2" {
+		t.Fatalf("expected c1 and c2 by number, got %+v", entries)
+	}
+	if entries[0].Name != "noir" {
+		t.Errorf("expected unnamed channel named after its theme, got %q", entries[0].Name)
+	}
+
+	var b strings.Builder
+	req := httptest.NewRequest("GET", "http://pd.local:8080/api/v1/channels.m3u?api_key=k", nil)
+	if err := writeM3U(&b, entries, guideURL(req)); err != nil {
+		t.Fatal(err)
+	}
+	want := `#EXTM3U url-tvg="http://pd.local:8080/api/v1/guide.xml?api_key=k" x-tvg-url="http://pd.local:8080/api/v1/guide.xml?api_key=k"
+#EXTINF:-1 tvg-id="c1" tvg-chno="1" tvg-name="noir" tvg-logo="https://tv.example.com/images/noir.png",noir
+https://tv.example.com/stream/channels/c1.ts
+#EXTINF:-1 tvg-id="c2" tvg-chno="2" tvg-name="Sci-Fi 'Classics'" group-title="Movies",Sci-Fi 'Classics'
+https://tv.example.com/stream/channels/c2.ts
+`
+	if b.String() != want {
+		t.Errorf("unexpected playlist:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Compressed writers are reused across responses, as each allocates large buffers
+var (
+	gzipWriters  = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	flateWriters = sync.Pool{New: func() any {
+		w, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+		return w
+	}}
+)
+
+// compress compresses responses of at least server.compress_min_size bytes with gzip or
+// deflate, as negotiated with Accept-Encoding. Responses that set their own Content-Encoding,
+// like /metrics, and event streams are left alone. It is a no-op when the size is 0.
+func (s *Server) compress(next http.Handler) http.Handler {
+	minSize := s.cfg().Server.CompressMinSize
+	if minSize <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: minSize}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptedEncoding returns the encoding to compress with for an Accept-Encoding header,
+// gzip over deflate, or "" when the client accepts neither
+func acceptedEncoding(header string) string {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+	accepts := func(encoding string) bool {
+		if q, ok := weights[encoding]; ok {
+			return q > 0
+		}
+		return weights["*"] > 0
+	}
+
+	switch {
+	case accepts("gzip"):
+		return "gzip"
+	case accepts("deflate"):
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressWriter buffers the start of a response until it reaches the minimum size, then
+// compresses the rest; smaller responses are written as they are when the handler returns
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status  int
+	buf     []byte
+	started bool
+	enc     io.WriteCloser // nil when the response is not compressed
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.started || w.status != 0 {
+		return
+	}
+	w.status = status
+	// Informational and bodiless responses have nothing to compress
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		w.start(false)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.started {
+		if w.enc != nil {
+			return w.enc.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.flushBuffer(w.compressible()); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends what was written so far, uncompressed when the minimum size was not reached
+func (w *compressWriter) Flush() {
+	if !w.started {
+		if err := w.flushBuffer(false); err != nil {
+			return
+		}
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// compressible reports whether the response may be compressed, from its headers
+func (w *compressWriter) compressible() bool {
+	h := w.Header()
+	return h.Get("Content-Encoding") == "" &&
+		!strings.HasPrefix(h.Get("Content-Type"), "text/event-stream")
+}
+
+// flushBuffer starts the response, compressed or not, and writes the buffered start of it
+func (w *compressWriter) flushBuffer(compressed bool) error {
+	w.start(compressed)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// start writes the headers of the response, set up for compression when compressed
+func (w *compressWriter) start(compressed bool) {
+	w.started = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if compressed {
+		h := w.Header()
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		switch w.encoding {
+		case "gzip":
+			gz := gzipWriters.Get().(*gzip.Writer)
+			gz.Reset(w.ResponseWriter)
+			w.enc = gz
+		default:
+			fw := flateWriters.Get().(*flate.Writer)
+			fw.Reset(w.ResponseWriter)
+			w.enc = fw
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// close finishes the response once the handler returned, writing a response that stayed
+// under the minimum size as it is
+func (w *compressWriter) close() {
+	if !w.started {
+		if w.status == 0 && len(w.buf) == 0 {
+			// The handler wrote nothing; let the server answer its implicit 200
+			return
+		}
+		_ = w.flushBuffer(false)
+		return
+	}
+	if w.enc == nil {
+		return
+	}
+	_ = w.enc.Close()
+	switch enc := w.enc.(type) {
+	case *gzip.Writer:
+		gzipWriters.Put(enc)
+	case *flate.Writer:
+		flateWriters.Put(enc)
+	}
+	w.enc = nil
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip, deflate, br", "gzip"},
+		{"deflate", "deflate"},
+		{"gzip;q=0, deflate;q=0.5", "deflate"},
+		{"*", "gzip"},
+		{"br, identity", ""},
+	}
+
+	for _, tt := range tests {
+		if got := acceptedEncoding(tt.header); got != tt.want {
+			t.Errorf("acceptedEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{Server: config.ServerConfig{CompressMinSize: 100}}
+	s := NewServer(cfg, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	large := strings.Repeat(`{"title":"Alien"}`, 50)
+	handler := s.compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("encoded") == "true" {
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		body := large
+		if r.URL.Query().Get("small") == "true" {
+			body = `{"ok":true}`
+		}
+		// Written in pieces, so the threshold is crossed mid-response
+		for len(body) > 0 {
+			n := min(len(body), 40)
+			io.WriteString(w, body[:n])
+			body = body[n:]
+		}
+	}))
+
+	tests := []struct {
+		name     string
+		target   string
+		accept   string
+		encoding string
+	}{
+		{name: "gzip", target: "/", accept: "gzip, deflate", encoding: "gzip"},
+		{name: "deflate", target: "/", accept: "deflate", encoding: "deflate"},
+		{name: "not accepted", target: "/", accept: "br"},
+		{name: "below threshold", target: "/?small=true", accept: "gzip"},
+		{name: "already encoded", target: "/?encoded=true", accept: "gzip", encoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != http.StatusCreated {
+				t.Errorf("status = %d, want 201", recorder.Code)
+			}
+			if got := recorder.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.encoding)
+			}
+			if recorder.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("expected Vary: Accept-Encoding")
+			}
+
+			var body io.Reader = recorder.Body
+			switch {
+			case tt.name == "already encoded":
+				return
+			case tt.encoding == "gzip":
+				gz, err := gzip.NewReader(body)
+				if err != nil {
+					t.Fatalf("invalid gzip body: %v", err)
+				}
+				body = gz
+			case tt.encoding == "deflate":
+				body = flate.NewReader(body)
+			}
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("failed to read body: %v", err)
+			}
+			want := large
+			if strings.Contains(tt.target, "small") {
+				want = `{"ok":true}`
+			}
+			if string(got) != want {
+				t.Errorf("body = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/clients/httpx"
+	"github.com/geekxflood/program-director/internal/scheduler"
+	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/internal/services/playlist"
+)
+
+// errorStatus returns the status answering a failure caused by err:
+//   - 404 for a missing row, snapshot, or theme
+//   - 400 for an unknown Radarr or Sonarr instance
+//   - 409 when the channel or theme is busy with another generation
+//   - 503 when shutting down, or when an upstream such as Radarr or Tunarr is unreachable,
+//     failing, or has its circuit open
+//   - 502 when an upstream rejected our API key or answered otherwise unexpectedly
+//   - 504 when an upstream timed out
+//   - 500 for everything else
+func errorStatus(err error) int {
+	var statusErr *httpx.StatusError
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, playlist.ErrNoSnapshot), errors.Is(err, scheduler.ErrUnknownTheme):
+		return http.StatusNotFound
+	case errors.Is(err, media.ErrUnknownInstance), errors.Is(err, playlist.ErrUnknownStrategy), errors.Is(err, playlist.ErrLLMUnavailable):
+		return http.StatusBadRequest
+	case errors.Is(err, playlist.ErrChannelBusy), errors.Is(err, scheduler.ErrJobRunning):
+		return http.StatusConflict
+	case errors.Is(err, playlist.ErrShuttingDown), errors.Is(err, httpx.ErrUpstreamUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.As(err, &statusErr):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorCodes are the codes of error responses by status
+var errorCodes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusConflict:              "conflict",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+	http.StatusTooManyRequests:       "rate_limited",
+	http.StatusInternalServerError:   "internal",
+	http.StatusBadGateway:            "bad_gateway",
+	http.StatusServiceUnavailable:    "unavailable",
+	http.StatusGatewayTimeout:        "gateway_timeout",
+}
+
+// errorCode returns the machine-readable code of an error response with status, such as
+// "not_found" for 404
+func errorCode(status int) string {
+	if code, ok := errorCodes[status]; ok {
+		return code
+	}
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
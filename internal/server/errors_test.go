@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/clients/httpx"
+	"github.com/geekxflood/program-director/internal/clients/upstream"
+	"github.com/geekxflood/program-director/internal/scheduler"
+	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/internal/services/playlist"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"upstream 503", fmt.Errorf("radarr instance hd: %w", &httpx.StatusError{StatusCode: 503}), http.StatusServiceUnavailable},
+		{"circuit open", fmt.Errorf("tunarr: %w", upstream.ErrCircuitOpen), http.StatusServiceUnavailable},
+		{"rejected api key", &httpx.StatusError{StatusCode: 401}, http.StatusBadGateway},
+		{"upstream 404", &httpx.StatusError{StatusCode: 404}, http.StatusBadGateway},
+		{"timeout", fmt.Errorf("failed to get movies: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{"missing row", fmt.Errorf("get media: %w", sql.ErrNoRows), http.StatusNotFound},
+		{"no snapshot", playlist.ErrNoSnapshot, http.StatusNotFound},
+		{"unknown theme", scheduler.ErrUnknownTheme, http.StatusNotFound},
+		{"unknown instance", fmt.Errorf("radarr instance %q: %w", "4k", media.ErrUnknownInstance), http.StatusBadRequest},
+		{"unknown strategy", fmt.Errorf("%w %q", playlist.ErrUnknownStrategy, "random"), http.StatusBadRequest},
+		{"channel busy", playlist.ErrChannelBusy, http.StatusConflict},
+		{"job running", scheduler.ErrJobRunning, http.StatusConflict},
+		{"shutting down", playlist.ErrShuttingDown, http.StatusServiceUnavailable},
+		{"database", errors.New("disk I/O error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorStatus(tt.err); got != tt.want {
+				t.Errorf("errorStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// defaultGenerationsLimit caps how many runs are returned when no limit is given
+const defaultGenerationsLimit = 100
+
+// handleGenerations lists recorded generation runs, newest first. Supported filters are
+// theme, channel_id, status (generated, failed, skipped), dry_run, since and until
+// (RFC 3339), limit, and offset.
+func (s *Server) handleGenerations(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseGenerationOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err, "invalid query parameters")
+		return
+	}
+
+	generations, err := s.generationRepo.List(r.Context(), opts)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to list generations", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query generations")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"generations": generations,
+			"count":       len(generations),
+		},
+	})
+}
+
+// handleGenerationTraces lists the prompts and raw responses of the Ollama calls of a
+// generation run, in the order they were made. Runs are only traced with llm.traces enabled.
+func (s *Server) handleGenerationTraces(w http.ResponseWriter, r *http.Request) {
+	if s.traceRepo == nil {
+		writeError(w, http.StatusNotFound, errors.New("llm traces are not available"), "")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, errors.New("invalid generation id"), "")
+		return
+	}
+
+	traces, err := s.traceRepo.List(r.Context(), repository.ListLLMTraceOptions{GenerationID: id})
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to list llm traces", "generation_id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query llm traces")
+		return
+	}
+	if traces == nil {
+		traces = []models.LLMTrace{}
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"generation_id": id,
+			"traces":        traces,
+			"count":         len(traces),
+		},
+	})
+}
+
+// parseGenerationOptions reads generation filters from the query string
+func parseGenerationOptions(r *http.Request) (repository.ListGenerationOptions, error) {
+	query := r.URL.Query()
+	opts := repository.ListGenerationOptions{
+		ThemeName: query.Get("theme"),
+		ChannelID: query.Get("channel_id"),
+		Status:    query.Get("status"),
+		Limit:     defaultGenerationsLimit,
+	}
+
+	switch opts.Status {
+	case "", repository.GenerationStatusGenerated, repository.GenerationStatusFailed, repository.GenerationStatusSkipped:
+	default:
+		return opts, fmt.Errorf("invalid status %q (must be generated, failed, or skipped)", opts.Status)
+	}
+
+	if v := query.Get("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid dry_run: %w", err)
+		}
+		opts.DryRun = &dryRun
+	}
+
+	for name, dst := range map[string]*time.Time{"since": &opts.Since, "until": &opts.Until} {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dst = t
+		}
+	}
+
+	for name, dst := range map[string]*int{"limit": &opts.Limit, "offset": &opts.Offset} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return opts, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*dst = n
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseGenerationOptions(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v1/generations?theme=sci-fi&status=failed&dry_run=false&since=2025-06-01T00:00:00Z&limit=10", nil)
+
+	opts, err := parseGenerationOptions(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if opts.ThemeName != "sci-fi" || opts.Status != "failed" || opts.Limit != 10 {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if opts.DryRun == nil || *opts.DryRun {
+		t.Errorf("expected dry_run filter false, got %v", opts.DryRun)
+	}
+	if !opts.Since.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected since %v", opts.Since)
+	}
+
+	defaults, err := parseGenerationOptions(httptest.NewRequest(http.MethodGet, "/api/v1/generations", nil))
+	if err != nil || defaults.Limit != defaultGenerationsLimit {
+		t.Errorf("expected default limit %d, got %d (%v)", defaultGenerationsLimit, defaults.Limit, err)
+	}
+
+	for _, query := range []string{"status=broken", "since=yesterday", "limit=-1", "dry_run=maybe"} {
+		if _, err := parseGenerationOptions(httptest.NewRequest(http.MethodGet, "/api/v1/generations?"+query, nil)); err == nil {
+			t.Errorf("expected error for %s", query)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// xmltvTimeLayout is the XMLTV date format, with the zone offset
+const xmltvTimeLayout = "20060102150405 -0700"
+
+// xmltvGuide is the root of an XMLTV document
+type xmltvGuide struct {
+	XMLName       xml.Name         `xml:"tv"`
+	GeneratorName string           `xml:"generator-info-name,attr"`
+	Channels      []xmltvChannel   `xml:"channel"`
+	Programmes    []xmltvProgramme `xml:"programme"`
+}
+
+type xmltvChannel struct {
+	ID          string `xml:"id,attr"`
+	DisplayName string `xml:"display-name"`
+}
+
+type xmltvProgramme struct {
+	Start      string       `xml:"start,attr"`
+	Stop       string       `xml:"stop,attr"`
+	Channel    string       `xml:"channel,attr"`
+	Title      string       `xml:"title"`
+	Desc       string       `xml:"desc,omitempty"`
+	Date       string       `xml:"date,omitempty"`
+	Categories []string     `xml:"category"`
+	Length     *xmltvLength `xml:"length"`
+	Rating     *xmltvRating `xml:"rating"`
+}
+
+type xmltvLength struct {
+	Units string `xml:"units,attr"`
+	Value int    `xml:",chardata"`
+}
+
+type xmltvRating struct {
+	Value string `xml:"value"`
+}
+
+// handleGuide serves the lineups last applied to channels as an XMLTV guide, one programme
+// per title at its estimated air time, for clients that don't read Tunarr's guide.
+// channel_id limits it to one channel.
+func (s *Server) handleGuide(w http.ResponseWriter, r *http.Request) {
+	if s.lineupRepo == nil {
+		writeError(w, http.StatusNotFound, errors.New("lineups are not stored"), "")
+		return
+	}
+
+	ctx := r.Context()
+	items, err := s.lineupRepo.List(ctx, r.URL.Query().Get("channel_id"))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list lineups", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query lineups")
+		return
+	}
+
+	// Details come from the library; titles removed since the lineup was applied go without
+	media := make(map[int64]*models.Media)
+	for _, item := range items {
+		if item.MediaID == nil {
+			continue
+		}
+		if _, ok := media[*item.MediaID]; ok {
+			continue
+		}
+		m, err := s.mediaRepo.GetByID(ctx, *item.MediaID)
+		if err != nil {
+			s.logger.DebugContext(ctx, "guide entry without media details", "media_id", *item.MediaID, "error", err)
+		}
+		media[*item.MediaID] = m
+	}
+
+	out, err := xml.MarshalIndent(buildGuide(items, channelNames(s.cfg()), media), "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err, "failed to encode guide")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(out)
+}
+
+// channelNames maps the channel IDs of the configured themes to their theme names
+func channelNames(cfg *config.Config) map[string]string {
+	names := make(map[string]string, len(cfg.Themes))
+	for _, theme := range cfg.Themes {
+		if _, ok := names[theme.ChannelID]; !ok {
+			names[theme.ChannelID] = theme.Name
+		}
+	}
+	return names
+}
+
+// buildGuide turns lineups, ordered by channel, into an XMLTV guide with the programmes of
+// each channel by air time. Channels are named after their theme, or their ID when no
+// configured theme targets them anymore.
+func buildGuide(items []models.LineupItem, names map[string]string, media map[int64]*models.Media) xmltvGuide {
+	guide := xmltvGuide{GeneratorName: "program-director"}
+
+	// Titles that already aired when the lineup was applied come back after the last one
+	items = slices.Clone(items)
+	slices.SortStableFunc(items, func(a, b models.LineupItem) int {
+		if a.ChannelID != b.ChannelID {
+			return strings.Compare(a.ChannelID, b.ChannelID)
+		}
+		return a.AirsAt.Compare(b.AirsAt)
+	})
+
+	for i, item := range items {
+		if i == 0 || item.ChannelID != items[i-1].ChannelID {
+			name := names[item.ChannelID]
+			if name == "" {
+				name = item.ChannelID
+			}
+			guide.Channels = append(guide.Channels, xmltvChannel{ID: item.ChannelID, DisplayName: name})
+		}
+
+		programme := xmltvProgramme{
+			Start:   item.AirsAt.Format(xmltvTimeLayout),
+			Stop:    item.AirsAt.Add(time.Duration(item.Runtime) * time.Minute).Format(xmltvTimeLayout),
+			Channel: item.ChannelID,
+			Title:   item.Title,
+		}
+		if item.Year > 0 {
+			programme.Date = strconv.Itoa(item.Year)
+		}
+		if item.Runtime > 0 {
+			programme.Length = &xmltvLength{Units: "minutes", Value: item.Runtime}
+		}
+		if item.MediaID != nil {
+			if m := media[*item.MediaID]; m != nil {
+				programme.Desc = m.Overview
+				programme.Categories = m.Genres
+				if m.Certification != "" {
+					programme.Rating = &xmltvRating{Value: m.Certification}
+				}
+			}
+		}
+		guide.Programmes = append(guide.Programmes, programme)
+	}
+
+	return guide
+}
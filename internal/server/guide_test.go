@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestBuildGuide(t *testing.T) {
+	at := func(hour, min int) time.Time { return time.Date(2025, 6, 1, hour, min, 0, 0, time.UTC) }
+	alienID, heatID := int64(1), int64(2)
+	items := []models.LineupItem{
+		{ChannelID: "c1", Position: 1, MediaID: &alienID, Title: "Alien", Year: 1979, Runtime: 117, AirsAt: at(22, 0)},
+		{ChannelID: "c1", Position: 2, Title: "Removed", Runtime: 90, AirsAt: at(20, 0)},
+		{ChannelID: "c2", Position: 1, MediaID: &heatID, Title: "Heat", Year: 1995, Runtime: 170, AirsAt: at(21, 0)},
+	}
+	media := map[int64]*models.Media{
+		alienID: {Overview: "In space", Genres: models.StringSlice{"Horror", "Science Fiction"}, Certification: "R"},
+		heatID:  nil,
+	}
+
+	guide := buildGuide(items, map[string]string{"c1": "sci-fi"}, media)
+
+	if len(guide.Channels) != 2 || guide.Channels[0].DisplayName != "sci-fi" || guide.Channels[1].DisplayName != "c2" {
+		t.Fatalf("unexpected channels %+v", guide.Channels)
+	}
+	if len(guide.Programmes) != 3 {
+		t.Fatalf("expected 3 programmes, got %d", len(guide.Programmes))
+	}
+
+	// By air time within a channel
+	removed, alien := guide.Programmes[0], guide.Programmes[1]
+	if removed.Title != "Removed" || removed.Desc != "" || removed.Date != "" {
+		t.Errorf("unexpected programme without media %+v", removed)
+	}
+	if alien.Start != "20250601220000 +0000" || alien.Stop != "20250601235700 +0000" {
+		t.Errorf("unexpected Alien times %s to %s", alien.Start, alien.Stop)
+	}
+	if alien.Desc != "In space" || alien.Date != "1979" || len(alien.Categories) != 2 || alien.Rating.Value != "R" {
+		t.Errorf("unexpected Alien details %+v", alien)
+	}
+
+	out, err := xml.Marshal(guide)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<tv generator-info-name="program-director">`,
+		`<channel id="c1"><display-name>sci-fi</display-name></channel>`,
+		`<programme start="20250601210000 +0000" stop="20250601235000 +0000" channel="c2"><title>Heat</title><date>1995</date><length units="minutes">170</length></programme>`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("guide misses %s:\n%s", want, out)
+		}
+	}
+}
@@ -2,22 +2,30 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/geekxflood/program-director/internal/config"
 	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/notify"
+	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/internal/services/playlist"
 	"github.com/geekxflood/program-director/pkg/models"
 )
 
 // Response helpers
+
+// errorResponse is the body of every error answer: the error, a code derived from the status
+// (see errorCode), and an optional message. Data carries what is known of a failed operation,
+// such as the result of a failed generation.
 type errorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
+	Error   string      `json:"error"`
+	Code    string      `json:"code"`
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
 }
 
 type successResponse struct {
@@ -40,17 +48,13 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 func writeError(w http.ResponseWriter, status int, err error, message string) {
 	writeJSON(w, status, errorResponse{
 		Error:   err.Error(),
+		Code:    errorCode(status),
 		Message: message,
 	})
 }
 
 // Health check handler
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
-	}
-
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"status":    "healthy",
 		"timestamp": time.Now().Format(time.RFC3339),
@@ -60,11 +64,6 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // Ready check handler
 func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
-	}
-
 	// Check database connectivity
 	ctx := r.Context()
 	_, err := s.mediaRepo.Count(ctx, repository.ListMediaOptions{Limit: 1})
@@ -84,75 +83,11 @@ func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
 
 // Metrics handler (Prometheus format)
 func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
-	}
-
-	ctx := r.Context()
-
-	// Get counts
-	hasFile := true
-	movieCount, err := s.mediaRepo.Count(ctx, repository.ListMediaOptions{
-		MediaType: models.MediaTypeMovie,
-		HasFile:   &hasFile,
-	})
-	if err != nil {
-		movieCount = 0
-	}
-	seriesCount, err := s.mediaRepo.Count(ctx, repository.ListMediaOptions{
-		MediaType: models.MediaTypeSeries,
-		HasFile:   &hasFile,
-	})
-	if err != nil {
-		seriesCount = 0
-	}
-	animeCount, err := s.mediaRepo.Count(ctx, repository.ListMediaOptions{
-		MediaType: models.MediaTypeAnime,
-		HasFile:   &hasFile,
-	})
-	if err != nil {
-		animeCount = 0
-	}
-	historyCount, err := s.historyRepo.Count(ctx, repository.ListHistoryOptions{})
-	if err != nil {
-		historyCount = 0
-	}
-	cooldownCount, err := s.cooldownRepo.CountActive(ctx)
-	if err != nil {
-		cooldownCount = 0
-	}
-
-	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
-	fmt.Fprintf(w, "# HELP program_director_media_total Total number of media items by type\n")
-	fmt.Fprintf(w, "# TYPE program_director_media_total gauge\n")
-	fmt.Fprintf(w, "program_director_media_total{type=\"movie\"} %d\n", movieCount)
-	fmt.Fprintf(w, "program_director_media_total{type=\"series\"} %d\n", seriesCount)
-	fmt.Fprintf(w, "program_director_media_total{type=\"anime\"} %d\n", animeCount)
-	fmt.Fprintf(w, "\n")
-
-	fmt.Fprintf(w, "# HELP program_director_history_plays_total Total number of plays recorded\n")
-	fmt.Fprintf(w, "# TYPE program_director_history_plays_total counter\n")
-	fmt.Fprintf(w, "program_director_history_plays_total %d\n", historyCount)
-	fmt.Fprintf(w, "\n")
-
-	fmt.Fprintf(w, "# HELP program_director_cooldowns_active Number of media items on cooldown\n")
-	fmt.Fprintf(w, "# TYPE program_director_cooldowns_active gauge\n")
-	fmt.Fprintf(w, "program_director_cooldowns_active %d\n", cooldownCount)
-	fmt.Fprintf(w, "\n")
-
-	fmt.Fprintf(w, "# HELP program_director_themes_configured Number of configured themes\n")
-	fmt.Fprintf(w, "# TYPE program_director_themes_configured gauge\n")
-	fmt.Fprintf(w, "program_director_themes_configured %d\n", len(s.config.Themes))
+	s.metricsHandler.ServeHTTP(w, r)
 }
 
 // Media list handler
 func (s *Server) handleMediaList(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
-	}
-
 	ctx := r.Context()
 
 	// Parse query parameters
@@ -170,7 +105,7 @@ func (s *Server) handleMediaList(w http.ResponseWriter, r *http.Request) {
 
 	media, err := s.mediaRepo.List(ctx, opts)
 	if err != nil {
-		s.logger.Error("failed to list media", "error", err)
+		s.logger.ErrorContext(r.Context(), "failed to list media", "error", err)
 		writeError(w, http.StatusInternalServerError, err, "failed to query media")
 		return
 	}
@@ -186,100 +121,126 @@ func (s *Server) handleMediaList(w http.ResponseWriter, r *http.Request) {
 
 // Media sync handler
 func (s *Server) handleMediaSync(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
-	}
-
 	ctx := r.Context()
-	cleanup := r.URL.Query().Get("cleanup") == "true"
+	opts := media.SyncOptions{
+		Cleanup: r.URL.Query().Get("cleanup") == "true",
+		DryRun:  r.URL.Query().Get("dry_run") == "true",
+	}
 
-	s.logger.Info("media sync triggered via API", "cleanup", cleanup)
+	s.logger.InfoContext(r.Context(), "media sync triggered via API", "cleanup", opts.Cleanup, "dry_run", opts.DryRun)
 
 	// Sync movies
-	movieResult, err := s.syncService.SyncMovies(ctx, cleanup)
+	movieResult, err := s.syncService.SyncMovies(ctx, opts)
 	if err != nil {
-		s.logger.Error("movie sync failed", "error", err)
-		writeError(w, http.StatusInternalServerError, err, "movie sync failed")
+		s.logger.ErrorContext(r.Context(), "movie sync failed", "error", err)
+		s.notifySync(ctx, opts, notify.SyncRun{Kind: "movies", Err: err})
+		writeError(w, errorStatus(err), err, "movie sync failed")
 		return
 	}
 
 	// Sync series
-	seriesResult, err := s.syncService.SyncSeries(ctx, cleanup)
+	seriesResult, err := s.syncService.SyncSeries(ctx, opts)
 	if err != nil {
-		s.logger.Error("series sync failed", "error", err)
-		writeError(w, http.StatusInternalServerError, err, "series sync failed")
+		s.logger.ErrorContext(r.Context(), "series sync failed", "error", err)
+		s.notifySync(ctx, opts, notify.SyncRun{Kind: "movies", Result: movieResult}, notify.SyncRun{Kind: "series", Err: err})
+		writeError(w, errorStatus(err), err, "series sync failed")
 		return
 	}
+	s.notifySync(ctx, opts, notify.SyncRun{Kind: "movies", Result: movieResult}, notify.SyncRun{Kind: "series", Result: seriesResult})
 
 	writeJSON(w, http.StatusOK, successResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"movies": map[string]interface{}{
-				"created": movieResult.Created,
-				"updated": movieResult.Updated,
-				"deleted": movieResult.Deleted,
-				"errors":  movieResult.Errors,
-			},
-			"series": map[string]interface{}{
-				"created": seriesResult.Created,
-				"updated": seriesResult.Updated,
-				"deleted": seriesResult.Deleted,
-				"errors":  seriesResult.Errors,
-			},
+			"movies":  syncResultData(movieResult),
+			"series":  syncResultData(seriesResult),
+			"dry_run": opts.DryRun,
 		},
 		Message: "sync completed successfully",
 	})
 }
 
-// Themes list handler
-func (s *Server) handleThemesList(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
+// notifySync sends the summary of a sync run, unless it was a dry run
+func (s *Server) notifySync(ctx context.Context, opts media.SyncOptions, runs ...notify.SyncRun) {
+	if !opts.DryRun {
+		s.notifier.NotifySync(ctx, runs)
+	}
+}
+
+// syncResultData is the API view of a sync result, with the affected titles on dry runs
+func syncResultData(result *media.SyncResult) map[string]interface{} {
+	data := map[string]interface{}{
+		"created": result.Created,
+		"updated": result.Updated,
+		"deleted": result.Deleted,
+		"errors":  result.Errors,
 	}
+	if result.Changes != nil {
+		data["changes"] = result.Changes
+	}
+	return data
+}
 
+// Themes list handler
+func (s *Server) handleThemesList(w http.ResponseWriter, r *http.Request) {
+	themes := s.cfg().Themes
 	writeJSON(w, http.StatusOK, successResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"themes": s.config.Themes,
-			"count":  len(s.config.Themes),
+			"themes": themes,
+			"count":  len(themes),
 		},
 	})
 }
 
 // Generate all playlists handler
 func (s *Server) handleGenerateAll(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
-	}
-
-	ctx := r.Context()
+	// Shutdown drains generations instead of canceling them with the request
+	ctx := context.WithoutCancel(r.Context())
 	dryRun := r.URL.Query().Get("dry_run") == "true"
+	force := r.URL.Query().Get("force") == "true"
 
-	s.logger.Info("generating all playlists via API", "dry_run", dryRun)
+	s.logger.InfoContext(r.Context(), "generating all playlists via API", "dry_run", dryRun, "force", force)
+	s.audit(r, models.AuditActionGenerateAll, "", models.AuditParameters{"dry_run": dryRun, "force": force})
 
-	results, err := s.playlistGenerator.GenerateAll(ctx, s.config.Themes, dryRun)
+	themes := s.cfg().Themes
+	summary, err := s.playlistGenerator.GenerateAll(ctx, themes, dryRun, force)
+	if errors.Is(err, playlist.ErrShuttingDown) {
+		writeError(w, http.StatusServiceUnavailable, err, "server is shutting down")
+		return
+	}
 	if err != nil {
-		s.logger.Error("playlist generation failed", "error", err)
-		writeError(w, http.StatusInternalServerError, err, "generation failed")
+		s.logger.ErrorContext(r.Context(), "playlist generation failed", "error", err)
+		writeError(w, errorStatus(err), err, "generation failed")
 		return
 	}
+	if !dryRun {
+		s.notifier.NotifyGeneration(ctx, summary, themes)
+		s.alerts.RecordGenerations(ctx, summary.Results)
+	}
 
 	// Convert results to JSON-friendly format
-	resultData := make([]map[string]interface{}, 0, len(results))
-	for _, result := range results {
+	resultData := make([]map[string]interface{}, 0, len(summary.Results))
+	for _, result := range summary.Results {
 		data := map[string]interface{}{
 			"theme":      result.ThemeName,
 			"channel_id": result.ChannelID,
 			"generated":  result.Generated,
 			"item_count": result.ItemCount,
+			"attempts":   result.Attempts,
 			"duration":   result.Duration.String(),
 		}
 		if result.Error != nil {
 			data["error"] = result.Error.Error()
 		}
+		if result.SkipReason != "" {
+			data["skipped"] = result.SkipReason
+		}
+		if result.Degraded != "" {
+			data["degraded"] = result.Degraded
+		}
+		if result.Diff != nil {
+			data["diff"] = result.Diff
+		}
 		resultData = append(resultData, data)
 	}
 
@@ -287,7 +248,13 @@ func (s *Server) handleGenerateAll(w http.ResponseWriter, r *http.Request) {
 		Success: true,
 		Data: map[string]interface{}{
 			"results": resultData,
-			"count":   len(results),
+			"count":   len(summary.Results),
+			"summary": map[string]interface{}{
+				"generated": summary.Generated,
+				"retried":   summary.Retried,
+				"failed":    summary.Failed,
+				"skipped":   summary.Skipped,
+			},
 		},
 		Message: "playlist generation completed",
 	})
@@ -295,51 +262,64 @@ func (s *Server) handleGenerateAll(w http.ResponseWriter, r *http.Request) {
 
 // Generate specific theme handler
 func (s *Server) handleGenerateTheme(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
-	}
-
-	// Extract theme name from path
-	themeName := strings.TrimPrefix(r.URL.Path, "/api/v1/generate/")
-	if themeName == "" {
-		writeError(w, http.StatusBadRequest, errors.New("theme name required"), "")
-		return
-	}
-
-	// Find theme
-	var themeConfig *config.ThemeConfig
-	for i := range s.config.Themes {
-		if s.config.Themes[i].Name == themeName {
-			themeConfig = &s.config.Themes[i]
-			break
-		}
-	}
-
+	themeName := r.PathValue("theme")
+	themeConfig := s.findTheme(themeName)
 	if themeConfig == nil {
 		writeError(w, http.StatusNotFound, errors.New("theme not found"), "")
 		return
 	}
 
-	ctx := r.Context()
+	// Shutdown drains generations instead of canceling them with the request
+	ctx := context.WithoutCancel(r.Context())
 	dryRun := r.URL.Query().Get("dry_run") == "true"
 
-	s.logger.Info("generating playlist via API",
+	s.logger.InfoContext(r.Context(), "generating playlist via API",
 		"theme", themeName,
 		"dry_run", dryRun,
 	)
+	s.audit(r, models.AuditActionGenerate, themeName, models.AuditParameters{"dry_run": dryRun})
 
 	result := s.playlistGenerator.Generate(ctx, themeConfig, dryRun)
+	if errors.Is(result.Error, playlist.ErrChannelBusy) {
+		writeError(w, http.StatusConflict, result.Error, "a generation is already running for this channel")
+		return
+	}
+	if errors.Is(result.Error, playlist.ErrShuttingDown) {
+		writeError(w, http.StatusServiceUnavailable, result.Error, "server is shutting down")
+		return
+	}
+	if !dryRun {
+		s.alerts.RecordGenerations(ctx, []playlist.GenerationResult{result})
+	}
 
 	data := map[string]interface{}{
 		"theme":      result.ThemeName,
 		"channel_id": result.ChannelID,
 		"generated":  result.Generated,
 		"item_count": result.ItemCount,
+		"attempts":   result.Attempts,
 		"duration":   result.Duration.String(),
 	}
+	if result.SkipReason != "" {
+		data["skipped"] = result.SkipReason
+	}
+	if result.Degraded != "" {
+		data["degraded"] = result.Degraded
+	}
+	if result.Diff != nil {
+		data["diff"] = result.Diff
+	}
+
 	if result.Error != nil {
-		data["error"] = result.Error.Error()
+		s.logger.ErrorContext(r.Context(), "playlist generation failed", "theme", themeName, "error", result.Error)
+		status := errorStatus(result.Error)
+		writeJSON(w, status, errorResponse{
+			Error:   result.Error.Error(),
+			Code:    errorCode(status),
+			Message: "playlist generation failed",
+			Data:    data,
+		})
+		return
 	}
 
 	writeJSON(w, http.StatusOK, successResponse{
@@ -349,20 +329,68 @@ func (s *Server) handleGenerateTheme(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// History handler
-func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
+// Undo handler restores the lineup a theme's channel had before the last apply
+func (s *Server) handleUndo(w http.ResponseWriter, r *http.Request) {
+	themeName := r.PathValue("theme")
+	themeConfig := s.findTheme(themeName)
+	if themeConfig == nil {
+		writeError(w, http.StatusNotFound, errors.New("theme not found"), "")
 		return
 	}
 
+	s.logger.InfoContext(r.Context(), "restoring previous lineup via API",
+		"theme", themeName,
+		"channel_id", themeConfig.ChannelID,
+	)
+
+	snapshot, err := s.playlistGenerator.Undo(r.Context(), themeConfig.ChannelID)
+	if err != nil {
+		if errors.Is(err, playlist.ErrNoSnapshot) {
+			writeError(w, http.StatusNotFound, err, "nothing to undo")
+			return
+		}
+		if errors.Is(err, playlist.ErrChannelBusy) {
+			writeError(w, http.StatusConflict, err, "a generation is writing this channel")
+			return
+		}
+		s.logger.ErrorContext(r.Context(), "undo failed", "theme", themeName, "error", err)
+		writeError(w, errorStatus(err), err, "undo failed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"theme":         themeName,
+			"channel_id":    snapshot.ChannelID,
+			"snapshot_id":   snapshot.ID,
+			"program_count": snapshot.ProgramCount,
+			"snapshot_at":   snapshot.CreatedAt.Format(time.RFC3339),
+		},
+		Message: "previous lineup restored",
+	})
+}
+
+// findTheme returns the configured theme with the given name, or nil
+func (s *Server) findTheme(name string) *config.ThemeConfig {
+	themes := s.cfg().Themes
+	for i := range themes {
+		if themes[i].Name == name {
+			return &themes[i]
+		}
+	}
+	return nil
+}
+
+// History handler
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	history, err := s.historyRepo.List(ctx, repository.ListHistoryOptions{
 		Limit: 100,
 	})
 	if err != nil {
-		s.logger.Error("failed to list history", "error", err)
+		s.logger.ErrorContext(r.Context(), "failed to list history", "error", err)
 		writeError(w, http.StatusInternalServerError, err, "failed to query history")
 		return
 	}
@@ -378,11 +406,6 @@ func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 
 // Cooldowns handler
 func (s *Server) handleCooldowns(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
-	}
-
 	ctx := r.Context()
 
 	cooldowns, err := s.cooldownRepo.List(ctx, repository.ListCooldownOptions{
@@ -390,7 +413,7 @@ func (s *Server) handleCooldowns(w http.ResponseWriter, r *http.Request) {
 		Limit:      100,
 	})
 	if err != nil {
-		s.logger.Error("failed to list cooldowns", "error", err)
+		s.logger.ErrorContext(r.Context(), "failed to list cooldowns", "error", err)
 		writeError(w, http.StatusInternalServerError, err, "failed to query cooldowns")
 		return
 	}
@@ -403,28 +426,3 @@ func (s *Server) handleCooldowns(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 }
-
-// Webhooks handler
-func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
-		return
-	}
-
-	// Parse webhook payload
-	var payload map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, http.StatusBadRequest, err, "invalid JSON payload")
-		return
-	}
-
-	s.logger.Info("webhook received", "payload", payload)
-
-	// TODO: Implement webhook processing logic
-	// For now, just acknowledge receipt
-
-	writeJSON(w, http.StatusOK, successResponse{
-		Success: true,
-		Message: "webhook received",
-	})
-}
@@ -2,10 +2,12 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/geekxflood/program-director/internal/config"
@@ -36,12 +38,40 @@ func TestWriteJSON(t *testing.T) {
 	}
 }
 
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		status   int
+		wantCode string
+	}{
+		{http.StatusNotFound, "not_found"},
+		{http.StatusConflict, "conflict"},
+		{http.StatusTooManyRequests, "rate_limited"},
+		{http.StatusTeapot, "i'm_a_teapot"},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		writeError(recorder, tt.status, errors.New("theme not found"), "details")
+
+		var result errorResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if recorder.Code != tt.status || result.Code != tt.wantCode {
+			t.Errorf("got status %d with code %q, want %d with %q", recorder.Code, result.Code, tt.status, tt.wantCode)
+		}
+		if result.Error != "theme not found" || result.Message != "details" {
+			t.Errorf("unexpected envelope %+v", result)
+		}
+	}
+}
+
 func TestHandleHealth(t *testing.T) {
 	cfg := &config.Config{}
 	serverCfg := &Config{Port: 8080, MetricsEnabled: true}
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	server := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, logger)
+	server := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	recorder := httptest.NewRecorder()
@@ -75,12 +105,12 @@ func TestHandleHealthMethodNotAllowed(t *testing.T) {
 	serverCfg := &Config{Port: 8080, MetricsEnabled: true}
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	server := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, logger)
+	server := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	req := httptest.NewRequest(http.MethodPost, "/health", nil)
 	recorder := httptest.NewRecorder()
 
-	server.handleHealth(recorder, req)
+	routes(server).ServeHTTP(recorder, req)
 
 	if recorder.Code != http.StatusMethodNotAllowed {
 		t.Errorf("expected status 405, got %d", recorder.Code)
@@ -97,7 +127,7 @@ func TestHandleThemesList(t *testing.T) {
 	serverCfg := &Config{Port: 8080, MetricsEnabled: true}
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	server := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, logger)
+	server := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/themes", nil)
 	recorder := httptest.NewRecorder()
@@ -140,13 +170,13 @@ func TestServerNew(t *testing.T) {
 	serverCfg := &Config{Port: 8080, MetricsEnabled: true}
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	server := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, logger)
+	server := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	if server == nil {
 		t.Fatal("expected non-nil server")
 	}
 
-	if server.config != cfg {
+	if server.cfg() != cfg {
 		t.Error("expected config to be set")
 	}
 
@@ -163,3 +193,49 @@ func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
 		(s[:len(substr)] == substr || contains(s[1:], substr)))
 }
+
+func TestHandleUndoThemeNotFound(t *testing.T) {
+	cfg := &config.Config{
+		Themes: []config.ThemeConfig{
+			{Name: "theme1", ChannelID: "ch1"},
+		},
+	}
+	serverCfg := &Config{Port: 8080, MetricsEnabled: true}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	server := NewServer(cfg, serverCfg, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/undo/missing", nil)
+	recorder := httptest.NewRecorder()
+
+	routes(server).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", recorder.Code)
+	}
+}
+
+func TestHandleBlocklistRequiresOneID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	server := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+	}{
+		{name: "add without id", req: httptest.NewRequest(http.MethodPost, "/api/v1/blocklist", strings.NewReader(`{"reason":"x"}`))},
+		{name: "add with both ids", req: httptest.NewRequest(http.MethodPost, "/api/v1/blocklist", strings.NewReader(`{"media_id":1,"imdb_id":"tt1"}`))},
+		{name: "remove without id", req: httptest.NewRequest(http.MethodDelete, "/api/v1/blocklist", nil)},
+		{name: "remove invalid media id", req: httptest.NewRequest(http.MethodDelete, "/api/v1/blocklist?media_id=abc", nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			routes(server).ServeHTTP(recorder, tt.req)
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", recorder.Code)
+			}
+		})
+	}
+}
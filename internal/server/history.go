@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/history"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// handleHistoryExport streams the play history as CSV (the default) or a JSON array,
+// newest first. Filters: format, channel_id, theme, source (lineup or plex), and since and
+// until as RFC 3339 times.
+func (s *Server) handleHistoryExport(w http.ResponseWriter, r *http.Request) {
+	format, opts, err := parseHistoryExportOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err, "invalid query parameters")
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="play-history.%s"`, format))
+	w.WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure can only cut the export short
+	count, err := history.Export(r.Context(), s.historyRepo, w, format, opts)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "history export interrupted", "written", count, "error", err)
+		return
+	}
+	s.logger.DebugContext(r.Context(), "exported play history", "format", format, "entries", count)
+}
+
+// parseHistoryExportOptions reads the export format and history filters from the query string
+func parseHistoryExportOptions(r *http.Request) (history.Format, repository.ListHistoryOptions, error) {
+	query := r.URL.Query()
+	opts := repository.ListHistoryOptions{
+		ChannelID: query.Get("channel_id"),
+		ThemeName: query.Get("theme"),
+		Source:    models.PlaySource(query.Get("source")),
+	}
+
+	format, err := history.ParseFormat(query.Get("format"))
+	if err != nil {
+		return "", opts, err
+	}
+
+	switch opts.Source {
+	case "", models.PlaySourceLineup, models.PlaySourcePlex:
+	default:
+		return "", opts, fmt.Errorf("invalid source %q (must be lineup or plex)", opts.Source)
+	}
+
+	for name, dst := range map[string]*time.Time{"since": &opts.Since, "until": &opts.Until} {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return "", opts, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dst = t
+		}
+	}
+
+	return format, opts, nil
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/services/history"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestParseHistoryExportOptions(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v1/history/export?format=json&channel_id=ch-1&theme=noir&source=plex&since=2026-01-01T00:00:00Z", nil)
+
+	format, opts, err := parseHistoryExportOptions(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if format != history.FormatJSON {
+		t.Errorf("format = %q, want json", format)
+	}
+	if opts.ChannelID != "ch-1" || opts.ThemeName != "noir" || opts.Source != models.PlaySourcePlex {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if !opts.Since.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || !opts.Until.IsZero() {
+		t.Errorf("unexpected range %v - %v", opts.Since, opts.Until)
+	}
+
+	format, _, err = parseHistoryExportOptions(httptest.NewRequest(http.MethodGet, "/api/v1/history/export", nil))
+	if err != nil || format != history.FormatCSV {
+		t.Errorf("expected default format csv, got %q (%v)", format, err)
+	}
+
+	for _, query := range []string{"format=xml", "source=radio", "since=yesterday", "until=2026-13-01"} {
+		if _, _, err := parseHistoryExportOptions(httptest.NewRequest(http.MethodGet, "/api/v1/history/export?"+query, nil)); err == nil {
+			t.Errorf("expected error for %s", query)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+)
+
+// handleLineups lists the lineups last applied to channels, with the estimated air time of
+// each title, by channel and play order. channel_id limits it to one channel.
+func (s *Server) handleLineups(w http.ResponseWriter, r *http.Request) {
+	if s.lineupRepo == nil {
+		writeError(w, http.StatusNotFound, errors.New("lineups are not stored"), "")
+		return
+	}
+
+	items, err := s.lineupRepo.List(r.Context(), r.URL.Query().Get("channel_id"))
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to list lineups", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query lineups")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"items": items,
+			"count": len(items),
+		},
+	})
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/radarr"
+	"github.com/geekxflood/program-director/internal/clients/sonarr"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// mediaDetailHistoryLimit is how many recent plays the media detail includes by default
+const mediaDetailHistoryLimit = 20
+
+// mediaSearchLimit is how many results a media search returns by default
+const mediaSearchLimit = 50
+
+// mediaDetail is a media record with its scheduling state
+type mediaDetail struct {
+	Media         *models.Media         `json:"media"`
+	OnCooldown    bool                  `json:"on_cooldown"`
+	Cooldown      *models.MediaCooldown `json:"cooldown,omitempty"`
+	PlayCount     int64                 `json:"play_count"`
+	RecentHistory []models.PlayHistory  `json:"recent_history"`
+}
+
+// handleMediaDetail returns one media record with its cooldown state, play count, and most
+// recent plays (up to ?history_limit=, default 20)
+func (s *Server) handleMediaDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, errors.New("invalid media id"), "")
+		return
+	}
+
+	historyLimit := mediaDetailHistoryLimit
+	if v := r.URL.Query().Get("history_limit"); v != "" {
+		historyLimit, err = strconv.Atoi(v)
+		if err != nil || historyLimit < 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid history_limit"), "")
+			return
+		}
+	}
+
+	ctx := r.Context()
+
+	media, err := s.mediaRepo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, errors.New("media not found"), "")
+		return
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get media", "media_id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query media")
+		return
+	}
+
+	detail := mediaDetail{Media: media, RecentHistory: []models.PlayHistory{}}
+
+	cooldown, err := s.cooldownRepo.GetByMediaID(ctx, id)
+	switch {
+	case err == nil:
+		detail.Cooldown = cooldown
+		detail.OnCooldown = cooldown.CanReplayAt.After(time.Now())
+	case !errors.Is(err, sql.ErrNoRows):
+		s.logger.ErrorContext(ctx, "failed to get cooldown", "media_id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query cooldown")
+		return
+	}
+
+	historyOpts := repository.ListHistoryOptions{MediaID: id}
+	if detail.PlayCount, err = s.historyRepo.Count(ctx, historyOpts); err != nil {
+		s.logger.ErrorContext(ctx, "failed to count plays", "media_id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query history")
+		return
+	}
+
+	if historyLimit > 0 {
+		historyOpts.Limit = historyLimit
+		history, err := s.historyRepo.List(ctx, historyOpts)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to list plays", "media_id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, err, "failed to query history")
+			return
+		}
+		if history != nil {
+			detail.RecentHistory = history
+		}
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data:    detail,
+	})
+}
+
+// handleMediaSearch searches media titles and overviews for ?q=, optionally filtered by ?type=
+// and capped by ?limit= (default 50)
+func (s *Server) handleMediaSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing q parameter"), "")
+		return
+	}
+
+	opts := repository.SearchMediaOptions{
+		MediaType: models.MediaType(query.Get("type")),
+		Limit:     mediaSearchLimit,
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"), "")
+			return
+		}
+		opts.Limit = limit
+	}
+
+	media, err := s.mediaRepo.Search(r.Context(), q, opts)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to search media", "query", q, "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to search media")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"query": q,
+			"media": media,
+			"count": len(media),
+		},
+	})
+}
+
+// handleMediaRefresh fetches one movie or series from Radarr/Sonarr by its external ID and
+// stores it, without a full sync. ?instance= picks the instance, defaulting to the first.
+func (s *Server) handleMediaRefresh(w http.ResponseWriter, r *http.Request) {
+	source := models.MediaSource(r.PathValue("source"))
+	if source != models.MediaSourceRadarr && source != models.MediaSourceSonarr {
+		writeError(w, http.StatusBadRequest, errors.New("source must be radarr or sonarr"), "")
+		return
+	}
+
+	externalID, err := strconv.ParseInt(r.PathValue("external_id"), 10, 64)
+	if err != nil || externalID <= 0 {
+		writeError(w, http.StatusBadRequest, errors.New("invalid external id"), "")
+		return
+	}
+
+	ctx := r.Context()
+	instance := r.URL.Query().Get("instance")
+
+	result, err := s.syncService.Refresh(ctx, source, instance, externalID)
+	switch {
+	case errors.Is(err, media.ErrUnknownInstance):
+		writeError(w, http.StatusBadRequest, err, "")
+		return
+	case errors.Is(err, radarr.ErrNotFound), errors.Is(err, sonarr.ErrNotFound):
+		writeError(w, http.StatusNotFound, errors.New("media not found in "+string(source)), "")
+		return
+	case err != nil:
+		s.logger.ErrorContext(ctx, "media refresh failed",
+			"source", source,
+			"external_id", externalID,
+			"error", err,
+		)
+		writeError(w, errorStatus(err), err, "media refresh failed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"media":    result.Media,
+			"created":  result.Created,
+			"enriched": result.Enriched,
+		},
+		Message: "media refreshed",
+	})
+}
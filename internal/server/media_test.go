@@ -0,0 +1,94 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestHandleMediaDetailBadRequest(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{"not a number", http.MethodGet, "/api/v1/media/abc", http.StatusBadRequest},
+		{"zero id", http.MethodGet, "/api/v1/media/0", http.StatusBadRequest},
+		{"nested path", http.MethodGet, "/api/v1/media/1/history", http.StatusNotFound},
+		{"bad history limit", http.MethodGet, "/api/v1/media/1?history_limit=-1", http.StatusBadRequest},
+		{"wrong method", http.MethodPost, "/api/v1/media/1", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			routes(s).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleMediaSearchBadRequest(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{"missing query", http.MethodGet, "/api/v1/media/search", http.StatusBadRequest},
+		{"blank query", http.MethodGet, "/api/v1/media/search?q=%20%20", http.StatusBadRequest},
+		{"bad limit", http.MethodGet, "/api/v1/media/search?q=alien&limit=0", http.StatusBadRequest},
+		{"wrong method", http.MethodPost, "/api/v1/media/search?q=alien", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			routes(s).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleMediaRefreshBadRequest(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{"unknown source", http.MethodPost, "/api/v1/media/plex/1/refresh", http.StatusBadRequest},
+		{"not a number", http.MethodPost, "/api/v1/media/radarr/abc/refresh", http.StatusBadRequest},
+		{"zero id", http.MethodPost, "/api/v1/media/sonarr/0/refresh", http.StatusBadRequest},
+		{"wrong method", http.MethodGet, "/api/v1/media/radarr/1/refresh", http.StatusMethodNotAllowed},
+		{"detail still routed", http.MethodGet, "/api/v1/media/abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			routes(s).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
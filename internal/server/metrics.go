@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/metrics"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// libraryScrapeTimeout bounds the database queries of one /metrics scrape
+const libraryScrapeTimeout = 5 * time.Second
+
+var (
+	mediaTotalDesc = prometheus.NewDesc("program_director_media_total",
+		"Total number of media items by type", []string{"type"}, nil)
+	historyPlaysDesc = prometheus.NewDesc("program_director_history_plays_total",
+		"Total number of plays recorded", nil, nil)
+	cooldownsActiveDesc = prometheus.NewDesc("program_director_cooldowns_active",
+		"Number of media items on cooldown", nil, nil)
+	themesConfiguredDesc = prometheus.NewDesc("program_director_themes_configured",
+		"Number of configured themes", nil, nil)
+)
+
+// libraryCollector reports library, history, and cooldown counts from the database at scrape
+// time. Counts that fail to load are left out of the scrape.
+type libraryCollector struct {
+	s *Server
+}
+
+// Describe implements prometheus.Collector
+func (c libraryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- mediaTotalDesc
+	ch <- historyPlaysDesc
+	ch <- cooldownsActiveDesc
+	ch <- themesConfiguredDesc
+}
+
+// Collect implements prometheus.Collector
+func (c libraryCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), libraryScrapeTimeout)
+	defer cancel()
+
+	hasFile := true
+	for _, mediaType := range []models.MediaType{models.MediaTypeMovie, models.MediaTypeSeries, models.MediaTypeAnime} {
+		count, err := c.s.mediaRepo.Count(ctx, repository.ListMediaOptions{MediaType: mediaType, HasFile: &hasFile})
+		if err != nil {
+			c.s.logger.WarnContext(ctx, "failed to count media for metrics", "type", mediaType, "error", err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(mediaTotalDesc, prometheus.GaugeValue, float64(count), string(mediaType))
+	}
+
+	if count, err := c.s.historyRepo.Count(ctx, repository.ListHistoryOptions{}); err != nil {
+		c.s.logger.WarnContext(ctx, "failed to count plays for metrics", "error", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(historyPlaysDesc, prometheus.CounterValue, float64(count))
+	}
+
+	if count, err := c.s.cooldownRepo.CountActive(ctx); err != nil {
+		c.s.logger.WarnContext(ctx, "failed to count cooldowns for metrics", "error", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(cooldownsActiveDesc, prometheus.GaugeValue, float64(count))
+	}
+
+	ch <- prometheus.MustNewConstMetric(themesConfiguredDesc, prometheus.GaugeValue, float64(len(c.s.cfg().Themes)))
+}
+
+// recordMetrics counts requests and observes their latency by method and the path of the mux
+// pattern they matched, such as /api/v1/media/{id}, so paths with IDs share one series
+func (s *Server) recordMetrics(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		route := "unmatched"
+		if _, pattern := mux.Handler(r); pattern != "" {
+			route = pattern
+			if _, path, ok := strings.Cut(pattern, " "); ok {
+				route = path
+			}
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		metrics.HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(rec.status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
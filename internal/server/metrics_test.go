@@ -0,0 +1,73 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/metrics"
+)
+
+func TestRecordMetricsRoute(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/media/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := s.recordMetrics(mux, mux)
+
+	tests := []struct {
+		target string
+		route  string
+		code   string
+	}{
+		{"/api/v1/media/41", "/api/v1/media/{id}", "418"},
+		{"/api/v1/media/42", "/api/v1/media/{id}", "418"},
+		{"/nowhere", "unmatched", "404"},
+	}
+	before := make(map[string]float64)
+	for _, tt := range tests {
+		before[tt.route] = requestCount(t, tt.route, tt.code)
+	}
+
+	for _, tt := range tests {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+	}
+
+	if got := requestCount(t, "/api/v1/media/{id}", "418") - before["/api/v1/media/{id}"]; got != 2 {
+		t.Errorf("requests for /api/v1/media/{id} = %v, want 2", got)
+	}
+	if got := requestCount(t, "unmatched", "404") - before["unmatched"]; got != 1 {
+		t.Errorf("unmatched requests = %v, want 1", got)
+	}
+}
+
+// requestCount reads program_director_http_requests_total for a GET route and status code
+func requestCount(t *testing.T, route, code string) float64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "program_director_http_requests_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["method"] == http.MethodGet && labels["route"] == route && labels["code"] == code {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/logging"
+)
+
+func TestLogRequests(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(logging.NewContextHandler(slog.NewTextHandler(&buf, nil)))
+	s := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	var seen string
+	handler := s.logRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "generated", incoming: ""},
+		{name: "propagated", incoming: "client-req.42", wantSame: true},
+		{name: "invalid replaced", incoming: "bad id\nwith newline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/generate", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestIDHeader, tt.incoming)
+			}
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, req)
+
+			id := recorder.Header().Get(requestIDHeader)
+			if id == "" || id != seen {
+				t.Fatalf("response ID %q, context ID %q; want equal and set", id, seen)
+			}
+			if (id == tt.incoming) != tt.wantSame {
+				t.Errorf("response ID = %q, incoming %q", id, tt.incoming)
+			}
+			line := buf.String()
+			for _, want := range []string{"request_id=" + id, "status=418", "method=POST", "path=/api/v1/generate"} {
+				if !strings.Contains(line, want) {
+					t.Errorf("access log %q missing %q", line, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// pauseRequest is the optional body of POST /api/v1/pause
+type pauseRequest struct {
+	Reason string `json:"reason"`
+}
+
+// pausingAvailable reports whether the pause of automated actions can be managed, writing a
+// 404 when it can't
+func (s *Server) pausingAvailable(w http.ResponseWriter) bool {
+	if s.pauseRepo == nil {
+		writeError(w, http.StatusNotFound, errors.New("pausing is not available"), "")
+		return false
+	}
+	return true
+}
+
+// handleGetPause shows the pause of automated actions: scheduled jobs and webhook-triggered
+// refreshes. Read endpoints and manual generation keep working while paused.
+func (s *Server) handleGetPause(w http.ResponseWriter, r *http.Request) {
+	if !s.pausingAvailable(w) {
+		return
+	}
+
+	ctx := r.Context()
+	pause, err := s.pauseRepo.Get(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get automation pause", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query pause")
+		return
+	}
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data:    map[string]interface{}{"paused": pause != nil, "pause": pause},
+	})
+}
+
+// handlePause pauses automated actions, with the optional reason of a pauseRequest
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	if !s.pausingAvailable(w) {
+		return
+	}
+
+	ctx := r.Context()
+	var req pauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err, "invalid JSON payload")
+		return
+	}
+	pause, err := s.pauseRepo.Pause(ctx, req.Reason)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to pause automation", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to pause")
+		return
+	}
+	s.logger.InfoContext(ctx, "automation paused via API", "reason", req.Reason)
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data:    map[string]interface{}{"paused": true, "pause": pause},
+		Message: "automation paused",
+	})
+}
+
+// handleResume lifts the pause of automated actions
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	if !s.pausingAvailable(w) {
+		return
+	}
+
+	ctx := r.Context()
+	resumed, err := s.pauseRepo.Resume(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to resume automation", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to resume")
+		return
+	}
+	message := "automation was not paused"
+	if resumed {
+		s.logger.InfoContext(ctx, "automation resumed via API")
+		message = "automation resumed"
+	}
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data:    map[string]interface{}{"paused": false},
+		Message: message,
+	})
+}
+
+// automationPaused reports whether automated actions are paused. When the pause cannot be
+// checked they are treated as paused.
+func (s *Server) automationPaused(r *http.Request) bool {
+	if s.pauseRepo == nil {
+		return false
+	}
+	pause, err := s.pauseRepo.Get(r.Context())
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to check automation pause", "error", err)
+		return true
+	}
+	return pause != nil
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database/repository"
+)
+
+func TestPauseRoutes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{}
+	s := NewServer(cfg, &Config{Pause: repository.NewPauseRepository(newTestDB(t))}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	handler := routes(s)
+
+	// Steps run in order against the same pause
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		want    int
+		message string
+	}{
+		{"not paused", http.MethodGet, "", http.StatusOK, `"paused":false`},
+		{"resume while running", http.MethodDelete, "", http.StatusOK, "automation was not paused"},
+		{"invalid payload", http.MethodPost, `not json`, http.StatusBadRequest, ""},
+		{"pause without reason", http.MethodPost, "", http.StatusOK, "automation paused"},
+		{"pause with reason", http.MethodPost, `{"reason": "maintenance"}`, http.StatusOK, `"paused":true`},
+		{"paused", http.MethodGet, "", http.StatusOK, `"reason":"maintenance"`},
+		{"resume", http.MethodDelete, "", http.StatusOK, "automation resumed"},
+		{"resumed", http.MethodGet, "", http.StatusOK, `"paused":false`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, "/api/v1/pause", strings.NewReader(tt.body)))
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+			if tt.message != "" && !strings.Contains(recorder.Body.String(), tt.message) {
+				t.Errorf("body = %s, want %q", recorder.Body, tt.message)
+			}
+		})
+	}
+}
+
+func TestPauseRoutesUnavailable(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	handler := routes(s)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, "/api/v1/pause", nil))
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("%s status = %d, want %d", method, recorder.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestAutomationPaused(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db := newTestDB(t)
+	pauseRepo := repository.NewPauseRepository(db)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if s := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger); s.automationPaused(req) {
+		t.Error("automationPaused() = true without a pause repository, want false")
+	}
+
+	s := NewServer(&config.Config{}, &Config{Pause: pauseRepo}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+	if s.automationPaused(req) {
+		t.Error("automationPaused() = true before pausing, want false")
+	}
+	if _, err := pauseRepo.Pause(ctx, "maintenance"); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if !s.automationPaused(req) {
+		t.Error("automationPaused() = false while paused, want true")
+	}
+
+	// A pause that cannot be checked holds automated actions back
+	if _, err := db.Exec(ctx, "DROP TABLE automation_pause"); err != nil {
+		t.Fatalf("failed to drop automation_pause: %v", err)
+	}
+	if !s.automationPaused(req) {
+		t.Error("automationPaused() = false when the pause cannot be checked, want true")
+	}
+}
+
+func TestArrWebhookSkippedWhilePaused(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pauseRepo := repository.NewPauseRepository(newTestDB(t))
+	if _, err := pauseRepo.Pause(ctx, "maintenance"); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	cfg := &config.Config{}
+	cfg.Server.Webhooks.Radarr = config.WebhookConfig{Secret: "s3cret", Verify: "secret"}
+	cfg.Server.Webhooks.Sonarr = config.WebhookConfig{Secret: "s3cret", Verify: "secret"}
+	// Without a sync service a refresh that went through would panic
+	s := NewServer(cfg, &Config{Pause: pauseRepo}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	handler := routes(s)
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+	}{
+		{"radarr download", "/api/v1/webhooks/radarr?secret=s3cret", `{"eventType": "Download", "movie": {"id": 1, "title": "Heat"}}`},
+		{"sonarr download", "/api/v1/webhooks/sonarr?secret=s3cret", `{"eventType": "Download", "series": {"id": 2, "title": "Twin Peaks"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+			}
+			if !strings.Contains(recorder.Body.String(), "automation is paused") {
+				t.Errorf("body = %s, want the refresh skipped", recorder.Body)
+			}
+		})
+	}
+}
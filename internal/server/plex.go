@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// plexEventScrobble is sent when Plex considers an item watched (about 90% played)
+const plexEventScrobble = "media.scrobble"
+
+// plexWebhookMaxMemory bounds the multipart form Plex posts (payload plus optional thumbnail)
+const plexWebhookMaxMemory = 1 << 20
+
+// plexWebhook is the JSON payload of a Plex webhook
+type plexWebhook struct {
+	Event   string `json:"event"`
+	Account struct {
+		Title string `json:"title"`
+	} `json:"Account"`
+	Player struct {
+		Title string `json:"title"`
+	} `json:"Player"`
+	Metadata plexMetadata `json:"Metadata"`
+}
+
+// plexMetadata describes the item a Plex webhook refers to
+type plexMetadata struct {
+	Type             string `json:"type"` // movie, episode
+	Title            string `json:"title"`
+	GrandparentTitle string `json:"grandparentTitle"`
+	Year             int    `json:"year"`
+	GUIDs            []struct {
+		ID string `json:"id"` // imdb://tt0113277, tmdb://949, tvdb://81189
+	} `json:"Guid"`
+}
+
+// providerIDs extracts external IDs from the metadata GUIDs
+func (m *plexMetadata) providerIDs() repository.ProviderIDs {
+	var ids repository.ProviderIDs
+	for _, g := range m.GUIDs {
+		scheme, value, ok := strings.Cut(g.ID, "://")
+		if !ok {
+			continue
+		}
+		switch scheme {
+		case "imdb":
+			ids.IMDB = append(ids.IMDB, value)
+		case "tmdb":
+			if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+				ids.TMDB = append(ids.TMDB, id)
+			}
+		case "tvdb":
+			if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+				ids.TVDB = append(ids.TVDB, id)
+			}
+		}
+	}
+	return ids
+}
+
+// Plex webhook handler
+func (s *Server) handlePlexWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := parsePlexWebhook(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err, "invalid Plex webhook payload")
+		return
+	}
+
+	s.logger.DebugContext(r.Context(), "plex webhook received",
+		"event", payload.Event,
+		"type", payload.Metadata.Type,
+		"title", payload.Metadata.Title,
+		"player", payload.Player.Title,
+		"account", payload.Account.Title,
+	)
+
+	if payload.Event != plexEventScrobble {
+		writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "event ignored"})
+		return
+	}
+
+	ctx := r.Context()
+
+	media, err := s.matchPlexMedia(ctx, &payload.Metadata)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "media not in library"})
+			return
+		}
+		s.logger.ErrorContext(r.Context(), "failed to match plex media", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to match media")
+		return
+	}
+
+	// Only count airings of media in a lineup currently applied to one of our channels; library
+	// plays of titles that were scheduled in the past are not airings
+	if s.lineupRepo == nil {
+		writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "media not on a channel"})
+		return
+	}
+	lineup, err := s.lineupRepo.FindByMedia(ctx, media.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "media not on a channel"})
+			return
+		}
+		s.logger.ErrorContext(r.Context(), "failed to look up lineup", "media_id", media.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to look up lineup")
+		return
+	}
+
+	if err := s.cooldownManager.RecordAiring(ctx, media, lineup.ChannelID, lineup.ThemeName, time.Now()); err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to record airing", "media_id", media.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to record airing")
+		return
+	}
+
+	s.logger.InfoContext(r.Context(), "recorded airing from plex",
+		"title", media.Title,
+		"channel_id", lineup.ChannelID,
+		"theme", lineup.ThemeName,
+	)
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Message: "airing recorded",
+		Data: map[string]interface{}{
+			"media_id":   media.ID,
+			"channel_id": lineup.ChannelID,
+			"theme":      lineup.ThemeName,
+		},
+	})
+}
+
+// parsePlexWebhook reads the payload from Plex's multipart form, or from a raw JSON body
+func parsePlexWebhook(r *http.Request) (*plexWebhook, error) {
+	var payload plexWebhook
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		return &payload, nil
+	}
+
+	if err := r.ParseMultipartForm(plexWebhookMaxMemory); err != nil {
+		return nil, err
+	}
+	raw := r.FormValue("payload")
+	if raw == "" {
+		return nil, errors.New("missing payload field")
+	}
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// matchPlexMedia finds the library media a Plex item refers to
+func (s *Server) matchPlexMedia(ctx context.Context, meta *plexMetadata) (*models.Media, error) {
+	// Episode GUIDs identify the episode, so series are matched by show title
+	if meta.Type == "episode" {
+		return s.mediaRepo.FindByTitle(ctx, meta.GrandparentTitle, 0,
+			[]models.MediaType{models.MediaTypeSeries, models.MediaTypeAnime})
+	}
+
+	if ids := meta.providerIDs(); !ids.Empty() {
+		media, err := s.mediaRepo.ListByProviderIDs(ctx, ids, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(media) > 0 {
+			return &media[0], nil
+		}
+	}
+
+	return s.mediaRepo.FindByTitle(ctx, meta.Title, meta.Year, []models.MediaType{models.MediaTypeMovie})
+}
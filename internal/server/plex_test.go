@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/cooldown"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// newTestDB returns a migrated SQLite database in a temporary directory
+func newTestDB(t *testing.T) database.DB {
+	t.Helper()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := database.New(ctx, &config.DatabaseConfig{Driver: "sqlite", SQLite: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")}}, logger)
+	if err != nil {
+		t.Fatalf("database.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return db
+}
+
+func newPlexWebhookRequest(t *testing.T, payload string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("payload", payload); err != nil {
+		t.Fatalf("failed to write payload: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("failed to close form: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/plex", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestHandlePlexWebhookIgnoresOtherEvents(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	server := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	req := newPlexWebhookRequest(t, `{"event": "media.pause", "Metadata": {"type": "movie", "title": "Heat"}}`)
+	recorder := httptest.NewRecorder()
+
+	server.handlePlexWebhook(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	var result successResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Message != "event ignored" {
+		t.Errorf("expected event to be ignored, got %q", result.Message)
+	}
+}
+
+func TestHandlePlexWebhookInvalidPayload(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	server := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	req := newPlexWebhookRequest(t, `not json`)
+	recorder := httptest.NewRecorder()
+
+	server.handlePlexWebhook(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", recorder.Code)
+	}
+}
+
+func TestPlexMetadataProviderIDs(t *testing.T) {
+	var meta plexMetadata
+	err := json.Unmarshal([]byte(`{"type": "movie", "Guid": [
+		{"id": "imdb://tt0113277"}, {"id": "tmdb://949"}, {"id": "tvdb://abc"}, {"id": "plex://movie/5d77"}
+	]}`), &meta)
+	if err != nil {
+		t.Fatalf("failed to decode metadata: %v", err)
+	}
+
+	ids := meta.providerIDs()
+	if len(ids.IMDB) != 1 || ids.IMDB[0] != "tt0113277" {
+		t.Errorf("unexpected IMDB IDs %v", ids.IMDB)
+	}
+	if len(ids.TMDB) != 1 || ids.TMDB[0] != 949 {
+		t.Errorf("unexpected TMDB IDs %v", ids.TMDB)
+	}
+	if len(ids.TVDB) != 0 {
+		t.Errorf("expected invalid TVDB ID to be skipped, got %v", ids.TVDB)
+	}
+}
+
+func TestHandlePlexWebhookRecordsOnlyChannelAirings(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db := newTestDB(t)
+
+	mediaRepo := repository.NewMediaRepository(db)
+	historyRepo := repository.NewHistoryRepository(db)
+	cooldownRepo := repository.NewCooldownRepository(db)
+	lineupRepo := repository.NewLineupRepository(db)
+	manager := cooldown.NewManager(cooldownRepo, historyRepo, nil, &config.CooldownConfig{MovieDays: 30}, logger)
+
+	heat := &models.Media{ExternalID: 1, Source: models.MediaSourceRadarr, MediaType: models.MediaTypeMovie, Title: "Heat", Year: 1995, TMDBID: 949}
+	alien := &models.Media{ExternalID: 2, Source: models.MediaSourceRadarr, MediaType: models.MediaTypeMovie, Title: "Alien", Year: 1979, TMDBID: 348}
+	for _, m := range []*models.Media{heat, alien} {
+		if err := mediaRepo.Upsert(ctx, m); err != nil {
+			t.Fatalf("Upsert() error = %v", err)
+		}
+	}
+
+	// Alien was scheduled months ago, but only Heat is in a lineup applied now
+	if err := historyRepo.Create(ctx, &models.PlayHistory{
+		MediaID: alien.ID, ChannelID: "ch-old", ThemeName: "sci-fi", PlayedAt: time.Now().AddDate(0, -6, 0),
+		Source: models.PlaySourceLineup, MediaTitle: alien.Title, MediaType: alien.MediaType,
+	}); err != nil {
+		t.Fatalf("failed to seed history: %v", err)
+	}
+	now := time.Now()
+	if err := lineupRepo.Replace(ctx, "ch-1", []models.LineupItem{{
+		ThemeName: "crime", Position: 1, MediaID: &heat.ID, Title: heat.Title, Year: heat.Year,
+		MediaType: heat.MediaType, AirsAt: now, AppliedAt: now,
+	}}); err != nil {
+		t.Fatalf("failed to seed lineup: %v", err)
+	}
+
+	server := NewServer(&config.Config{}, &Config{Lineups: lineupRepo}, mediaRepo, historyRepo, cooldownRepo, nil, nil, nil, nil, nil, nil, manager, logger)
+
+	tests := []struct {
+		name      string
+		tmdbID    string
+		title     string
+		wantMsg   string
+		wantPlays int
+	}{
+		{"library play of a title scheduled in the past", "348", "Alien", "media not on a channel", 0},
+		{"airing of a title in the current lineup", "949", "Heat", "airing recorded", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newPlexWebhookRequest(t, `{"event": "media.scrobble", "Metadata": {"type": "movie", "title": "`+tt.title+`", "Guid": [{"id": "tmdb://`+tt.tmdbID+`"}]}}`)
+			recorder := httptest.NewRecorder()
+
+			server.handlePlexWebhook(recorder, req)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			var result successResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if result.Message != tt.wantMsg {
+				t.Errorf("expected message %q, got %q", tt.wantMsg, result.Message)
+			}
+
+			plays, err := historyRepo.List(ctx, repository.ListHistoryOptions{Source: models.PlaySourcePlex, Limit: 10})
+			if err != nil {
+				t.Fatalf("failed to list plex plays: %v", err)
+			}
+			if len(plays) != tt.wantPlays {
+				t.Errorf("expected %d plex plays, got %d", tt.wantPlays, len(plays))
+			}
+		})
+	}
+}
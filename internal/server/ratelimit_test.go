@@ -0,0 +1,44 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(60, 3) // one token per second, bursts of three
+	l.now = func() time.Time { return now }
+
+	for i := range 3 {
+		if ok, _ := l.allow("10.0.0.1"); !ok {
+			t.Fatalf("request %d within burst was limited", i+1)
+		}
+	}
+
+	ok, wait := l.allow("10.0.0.1")
+	if ok {
+		t.Fatal("request beyond burst was allowed")
+	}
+	if wait <= 0 || wait > time.Second {
+		t.Errorf("wait = %v, want up to 1s", wait)
+	}
+
+	if ok, _ := l.allow("10.0.0.2"); !ok {
+		t.Error("other client was limited")
+	}
+
+	now = now.Add(1500 * time.Millisecond)
+	if ok, _ := l.allow("10.0.0.1"); !ok {
+		t.Error("request after refill was limited")
+	}
+	if ok, _ := l.allow("10.0.0.1"); ok {
+		t.Error("refill granted more than elapsed time allows")
+	}
+
+	now = now.Add(time.Hour)
+	l.allow("10.0.0.3")
+	if _, ok := l.buckets["10.0.0.1"]; ok {
+		t.Error("idle bucket was not pruned")
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// rejectWrites answers 403 to /api/v1 requests that change anything while server.read_only
+// is set, leaving GET and HEAD requests, the dashboard, and metrics alone. The setting is
+// read per request, so a config reload toggles it.
+func (s *Server) rejectWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.cfg().Server.ReadOnly || !strings.HasPrefix(r.URL.Path, "/api/v1/") ||
+			r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		s.logger.InfoContext(r.Context(), "request rejected, server is read-only", "method", r.Method, "path", r.URL.Path)
+		writeError(w, http.StatusForbidden, errors.New("server is read-only"), "")
+	})
+}
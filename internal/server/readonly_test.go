@@ -0,0 +1,46 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestRejectWrites(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name     string
+		readOnly bool
+		method   string
+		path     string
+		want     int
+	}{
+		{name: "writes allowed", method: http.MethodPost, path: "/api/v1/generate", want: http.StatusOK},
+		{name: "generate rejected", readOnly: true, method: http.MethodPost, path: "/api/v1/generate", want: http.StatusForbidden},
+		{name: "webhook rejected", readOnly: true, method: http.MethodPost, path: "/api/v1/webhooks/radarr", want: http.StatusForbidden},
+		{name: "delete rejected", readOnly: true, method: http.MethodDelete, path: "/api/v1/pause", want: http.StatusForbidden},
+		{name: "reads allowed", readOnly: true, method: http.MethodGet, path: "/api/v1/themes", want: http.StatusOK},
+		{name: "metrics allowed", readOnly: true, method: http.MethodGet, path: "/metrics", want: http.StatusOK},
+		{name: "outside api allowed", readOnly: true, method: http.MethodPost, path: "/dashboard", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Server: config.ServerConfig{ReadOnly: tt.readOnly}}
+			s := NewServer(cfg, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+			recorder := httptest.NewRecorder()
+
+			s.rejectWrites(ok).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+)
+
+// routeErrors serves requests through mux, answering the ones no route matches with a JSON
+// error instead of the mux's plain text: 405 with the Allow header when routes of the path
+// accept other methods, and 404 otherwise
+func routeErrors(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		// The mux answers 405 and sets Allow itself; only its status and headers are kept
+		probe := &headerRecorder{header: make(http.Header)}
+		h.ServeHTTP(probe, r)
+		if probe.status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", probe.header.Get("Allow"))
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"), "")
+			return
+		}
+		writeError(w, http.StatusNotFound, errors.New("not found"), "")
+	})
+}
+
+// headerRecorder keeps the headers and status of a response, discarding its body
+type headerRecorder struct {
+	header http.Header
+	status int
+}
+
+func (r *headerRecorder) Header() http.Header { return r.header }
+
+func (r *headerRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *headerRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
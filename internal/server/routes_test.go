@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// routes returns the routes of s without the middleware of Start
+func routes(s *Server) http.Handler {
+	mux := http.NewServeMux()
+	s.registerHandlers(mux)
+	return routeErrors(mux)
+}
+
+func TestRouteErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(&config.Config{}, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	tests := []struct {
+		name      string
+		method    string
+		target    string
+		want      int
+		wantAllow string
+	}{
+		{"wrong method", http.MethodPut, "/api/v1/pause", http.StatusMethodNotAllowed, "DELETE, GET, HEAD, POST"},
+		{"get only", http.MethodPost, "/api/v1/media/7", http.StatusMethodNotAllowed, "GET, HEAD"},
+		{"unknown path", http.MethodGet, "/api/v1/nowhere", http.StatusNotFound, ""},
+		{"missing path value", http.MethodPost, "/api/v1/generate/", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			routes(s).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+
+			var body errorResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON error, got %v", err)
+			}
+			if recorder.Code != tt.want || body.Code != errorCode(tt.want) {
+				t.Errorf("got status %d with code %q, want %d", recorder.Code, body.Code, tt.want)
+			}
+			if got := recorder.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/geekxflood/program-director/internal/scheduler"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// SetScheduler exposes the scheduled generation of themes on /api/v1/scheduler. It must be
+// called before Start; without it those routes answer 404.
+func (s *Server) SetScheduler(sched *scheduler.Scheduler) {
+	s.scheduler = sched
+}
+
+// handleScheduler lists the scheduled generation of each theme: its schedule, next and last
+// run, last result, and whether it is paused or running
+func (s *Server) handleScheduler(w http.ResponseWriter, r *http.Request) {
+	if s.scheduler == nil {
+		writeError(w, http.StatusNotFound, errors.New("scheduler is not running"), "")
+		return
+	}
+
+	jobs := s.scheduler.Jobs()
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"jobs":              jobs,
+			"count":             len(jobs),
+			"automation_paused": s.automationPaused(r),
+		},
+	})
+}
+
+// handleSchedulerAction runs, pauses, or resumes the scheduled generation of a theme, from
+// POST /api/v1/scheduler/{theme}/{run|pause|resume}. Runs start in the background.
+func (s *Server) handleSchedulerAction(w http.ResponseWriter, r *http.Request) {
+	name, action := r.PathValue("theme"), r.PathValue("action")
+	if s.scheduler == nil {
+		writeError(w, http.StatusNotFound, errors.New("scheduler is not running"), "")
+		return
+	}
+
+	var err error
+	status, message := http.StatusOK, ""
+	switch action {
+	case "run":
+		err = s.scheduler.RunNow(name)
+		if err == nil {
+			s.audit(r, models.AuditActionGenerate, name, models.AuditParameters{"via": "scheduler"})
+		}
+		status, message = http.StatusAccepted, "generation started"
+	case "pause":
+		err = s.scheduler.Pause(name)
+		message = "theme paused"
+	case "resume":
+		err = s.scheduler.Resume(name)
+		message = "theme resumed"
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"), "action must be run, pause, or resume")
+		return
+	}
+
+	if err != nil {
+		writeError(w, errorStatus(err), err, "")
+		return
+	}
+
+	s.logger.InfoContext(r.Context(), "scheduler action via API", "theme", name, "action", action)
+	writeJSON(w, status, successResponse{
+		Success: true,
+		Data:    map[string]interface{}{"theme": name, "action": action},
+		Message: message,
+	})
+}
@@ -6,10 +6,20 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/geekxflood/program-director/internal/alerts"
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
 	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
 	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/events"
+	"github.com/geekxflood/program-director/internal/metrics"
+	"github.com/geekxflood/program-director/internal/notify"
+	"github.com/geekxflood/program-director/internal/scheduler"
 	"github.com/geekxflood/program-director/internal/services/cooldown"
 	"github.com/geekxflood/program-director/internal/services/media"
 	"github.com/geekxflood/program-director/internal/services/playlist"
@@ -17,22 +27,68 @@ import (
 
 // Server represents the HTTP server
 type Server struct {
-	config            *config.Config
+	config            atomic.Pointer[config.Config]
 	logger            *slog.Logger
 	httpServer        *http.Server
 	mediaRepo         *repository.MediaRepository
 	historyRepo       *repository.HistoryRepository
 	cooldownRepo      *repository.CooldownRepository
+	blocklistRepo     *repository.BlocklistRepository
+	generationRepo    *repository.GenerationRepository
+	apiKeyRepo        *repository.APIKeyRepository
+	llmUsageRepo      *repository.LLMUsageRepository
+	lineupRepo        *repository.LineupRepository
+	tunarrClient      *tunarr.Client
+	scheduler         *scheduler.Scheduler
+	pauseRepo         *repository.PauseRepository
+	auditRepo         *repository.AuditRepository
+	traceRepo         *repository.LLMTraceRepository
+	events            *events.Bus
 	syncService       *media.SyncService
 	playlistGenerator *playlist.Generator
 	cooldownManager   *cooldown.Manager
 	metricsEnabled    bool
+	metricsHandler    http.Handler
+	shutdownTimeout   time.Duration
+	upstreamChecks    []Upstream
+	upstreams         upstreamTracker
+	reloader          *config.Reloader
+	notifier          *notify.Notifier
+	alerts            *alerts.Monitor
 }
 
 // Config holds server configuration
 type Config struct {
 	Port           int
 	MetricsEnabled bool
+	Upstreams      []Upstream       // Dependencies checked by /api/v1/status
+	Reloader       *config.Reloader // Backs POST /api/v1/admin/reload; nil disables it
+	Notifier       *notify.Notifier // Sent summaries of generations and syncs run via the API
+	Alerts         *alerts.Monitor  // Tracks failure streaks; nil disables upstream polling and alerts
+	DB             database.DB      // Connection pool statistics exported on /metrics; nil leaves them out
+
+	// Lineups backs GET /api/v1/lineups and tells which channel a Plex scrobble aired on; nil
+	// disables both
+	Lineups *repository.LineupRepository
+
+	// Tunarr backs GET /api/v1/channels.m3u; nil disables it
+	Tunarr *tunarr.Client
+
+	// Pause backs /api/v1/pause and holds back webhook-triggered refreshes while automation is
+	// paused; nil disables both
+	Pause *repository.PauseRepository
+
+	// Audit records who triggered generations and backs GET /api/v1/audit; nil disables both
+	Audit *repository.AuditRepository
+
+	// Traces backs GET /api/v1/generations/{id}/traces; nil disables it
+	Traces *repository.LLMTraceRepository
+
+	// Events are pushed to GET /api/v1/ws clients; nil disables it
+	Events *events.Bus
+
+	// ShutdownTimeout bounds waiting for running generations on shutdown (default 30s)
+	ShutdownTimeout time.Duration
 }
 
 // NewServer creates a new HTTP server instance
@@ -42,22 +98,65 @@ func NewServer(
 	mediaRepo *repository.MediaRepository,
 	historyRepo *repository.HistoryRepository,
 	cooldownRepo *repository.CooldownRepository,
+	blocklistRepo *repository.BlocklistRepository,
+	generationRepo *repository.GenerationRepository,
+	apiKeyRepo *repository.APIKeyRepository,
+	llmUsageRepo *repository.LLMUsageRepository,
 	syncService *media.SyncService,
 	playlistGenerator *playlist.Generator,
 	cooldownManager *cooldown.Manager,
 	logger *slog.Logger,
 ) *Server {
-	return &Server{
-		config:            cfg,
+	s := &Server{
 		logger:            logger,
 		mediaRepo:         mediaRepo,
 		historyRepo:       historyRepo,
 		cooldownRepo:      cooldownRepo,
+		blocklistRepo:     blocklistRepo,
+		generationRepo:    generationRepo,
+		apiKeyRepo:        apiKeyRepo,
+		llmUsageRepo:      llmUsageRepo,
 		syncService:       syncService,
 		playlistGenerator: playlistGenerator,
 		cooldownManager:   cooldownManager,
 		metricsEnabled:    serverCfg.MetricsEnabled,
+		shutdownTimeout:   serverCfg.ShutdownTimeout,
+		lineupRepo:        serverCfg.Lineups,
+		tunarrClient:      serverCfg.Tunarr,
+		pauseRepo:         serverCfg.Pause,
+		auditRepo:         serverCfg.Audit,
+		traceRepo:         serverCfg.Traces,
+		events:            serverCfg.Events,
+		upstreamChecks:    serverCfg.Upstreams,
+		reloader:          serverCfg.Reloader,
+		notifier:          serverCfg.Notifier,
+		alerts:            serverCfg.Alerts,
+	}
+	s.config.Store(cfg)
+	if s.shutdownTimeout <= 0 {
+		s.shutdownTimeout = 30 * time.Second
 	}
+
+	if s.metricsEnabled {
+		library := prometheus.NewRegistry()
+		library.MustRegister(libraryCollector{s: s})
+		if serverCfg.DB != nil {
+			library.MustRegister(database.NewStatsCollector(serverCfg.DB))
+		}
+		s.metricsHandler = metrics.Handler(library)
+	}
+	return s
+}
+
+// cfg returns the config in effect
+func (s *Server) cfg() *config.Config {
+	return s.config.Load()
+}
+
+// SetConfig swaps in a reloaded config. Themes, API keys, and read-only mode apply to the
+// next request; rate limits and other startup settings need a restart.
+func (s *Server) SetConfig(cfg *config.Config) {
+	s.config.Store(cfg)
 }
 
 // Start starts the HTTP server
@@ -71,7 +170,7 @@ func (s *Server) Start(ctx context.Context, port int) error {
 
 	s.httpServer = &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      s.logRequests(s.recordMetrics(mux, s.compress(s.rateLimit(s.rejectWrites(s.requireAPIKey(routeErrors(mux))))))),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -80,6 +179,10 @@ func (s *Server) Start(ctx context.Context, port int) error {
 
 	s.logger.Info("HTTP server starting", "address", addr)
 
+	if s.alerts != nil {
+		go s.watchUpstreams(ctx)
+	}
+
 	// Start server in goroutine
 	errChan := make(chan error, 1)
 	go func() {
@@ -94,6 +197,7 @@ func (s *Server) Start(ctx context.Context, port int) error {
 		return fmt.Errorf("server error: %w", err)
 	case <-ctx.Done():
 		s.logger.Info("shutting down HTTP server")
+		s.drainGenerations()
 		// Use a timeout context derived from a fresh background context for shutdown.
 		// Note: We use context.Background() here instead of the parent context because:
 		// 1. The parent context (ctx) is already canceled/done at this point
@@ -105,6 +209,18 @@ func (s *Server) Start(ctx context.Context, port int) error {
 	}
 }
 
+// drainGenerations waits up to the shutdown timeout for running generations, from the API
+// and the scheduler alike, before the HTTP server stops
+func (s *Server) drainGenerations() {
+	if s.playlistGenerator == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	// Drain logs generations it had to cancel
+	_ = s.playlistGenerator.Drain(ctx)
+}
+
 // Shutdown gracefully shuts down the HTTP server
 func (s *Server) Shutdown(ctx context.Context) error {
 	if s.httpServer == nil {
@@ -123,24 +239,57 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-// registerHandlers registers all HTTP handlers
+// registerHandlers registers all HTTP handlers by method and path. GET routes also answer
+// HEAD; see routeErrors for requests no route matches.
 func (s *Server) registerHandlers(mux *http.ServeMux) {
 	// Health check
-	mux.HandleFunc("/health", s.handleHealth)
-	mux.HandleFunc("/ready", s.handleReady)
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /ready", s.handleReady)
 
 	// Metrics
 	if s.metricsEnabled {
-		mux.HandleFunc("/metrics", s.handleMetrics)
+		mux.HandleFunc("GET /metrics", s.handleMetrics)
 	}
 
 	// API v1 routes
-	mux.HandleFunc("/api/v1/media", s.handleMediaList)
-	mux.HandleFunc("/api/v1/media/sync", s.handleMediaSync)
-	mux.HandleFunc("/api/v1/themes", s.handleThemesList)
-	mux.HandleFunc("/api/v1/generate", s.handleGenerateAll)
-	mux.HandleFunc("/api/v1/generate/", s.handleGenerateTheme)
-	mux.HandleFunc("/api/v1/history", s.handleHistory)
-	mux.HandleFunc("/api/v1/cooldowns", s.handleCooldowns)
-	mux.HandleFunc("/api/v1/webhooks", s.handleWebhooks)
+	mux.HandleFunc("GET /api/v1/media", s.handleMediaList)
+	mux.HandleFunc("GET /api/v1/media/{id}", s.handleMediaDetail)
+	mux.HandleFunc("GET /api/v1/media/search", s.handleMediaSearch)
+	mux.HandleFunc("POST /api/v1/media/{source}/{external_id}/refresh", s.handleMediaRefresh)
+	mux.HandleFunc("POST /api/v1/media/sync", s.handleMediaSync)
+	mux.HandleFunc("GET /api/v1/media/sync/status", s.handleSyncStatus)
+	mux.HandleFunc("GET /api/v1/media/sync/events", s.handleSyncEvents)
+	mux.HandleFunc("GET /api/v1/ws", s.handleWebSocket)
+	mux.HandleFunc("GET /api/v1/themes", s.handleThemesList)
+	mux.HandleFunc("GET /api/v1/themes/suggestions", s.handleThemeSuggestions)
+	mux.HandleFunc("GET /api/v1/themes/{name}/candidates", s.handleThemeCandidates)
+	mux.HandleFunc("GET /api/v1/themes/{name}/compare", s.handleThemeCompare)
+	mux.HandleFunc("POST /api/v1/generate", s.handleGenerateAll)
+	mux.HandleFunc("POST /api/v1/generate/{theme}", s.handleGenerateTheme)
+	mux.HandleFunc("POST /api/v1/undo/{theme}", s.handleUndo)
+	mux.HandleFunc("GET /api/v1/history", s.handleHistory)
+	mux.HandleFunc("GET /api/v1/history/export", s.handleHistoryExport)
+	mux.HandleFunc("GET /api/v1/cooldowns", s.handleCooldowns)
+	mux.HandleFunc("GET /api/v1/blocklist", s.handleListBlocklist)
+	mux.HandleFunc("POST /api/v1/blocklist", s.handleAddBlocklist)
+	mux.HandleFunc("DELETE /api/v1/blocklist", s.handleRemoveBlocklist)
+	mux.HandleFunc("GET /api/v1/generations", s.handleGenerations)
+	mux.HandleFunc("GET /api/v1/generations/{id}/traces", s.handleGenerationTraces)
+	mux.HandleFunc("GET /api/v1/lineups", s.handleLineups)
+	mux.HandleFunc("GET /api/v1/guide.xml", s.handleGuide)
+	mux.HandleFunc("GET /api/v1/channels.m3u", s.handleChannelsM3U)
+	mux.HandleFunc("GET /api/v1/scheduler", s.handleScheduler)
+	mux.HandleFunc("POST /api/v1/scheduler/{theme}/{action}", s.handleSchedulerAction)
+	mux.HandleFunc("GET /api/v1/pause", s.handleGetPause)
+	mux.HandleFunc("POST /api/v1/pause", s.handlePause)
+	mux.HandleFunc("DELETE /api/v1/pause", s.handleResume)
+	mux.HandleFunc("GET /api/v1/audit", s.handleAudit)
+	mux.HandleFunc("GET /api/v1/stats/llm", s.handleLLMStats)
+	mux.HandleFunc("GET /api/v1/reports/utilization", s.handleUtilizationReport)
+	mux.HandleFunc("GET /api/v1/status", s.handleStatus)
+	mux.HandleFunc("POST /api/v1/admin/reload", s.handleAdminReload)
+	mux.HandleFunc("POST /api/v1/webhooks/radarr", s.handleRadarrWebhook)
+	mux.HandleFunc("POST /api/v1/webhooks/sonarr", s.handleSonarrWebhook)
+	mux.HandleFunc("POST /api/v1/webhooks/tunarr", s.handleTunarrWebhook)
+	mux.HandleFunc("POST /api/v1/webhooks/plex", s.handlePlexWebhook)
 }
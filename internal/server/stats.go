@@ -0,0 +1,103 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/media"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// defaultUtilizationTitles is how many never-scheduled titles are listed per genre by default
+const defaultUtilizationTitles = 10
+
+// handleLLMStats returns Ollama usage totals per theme and overall. Filters: theme, and since
+// and until as RFC 3339 times.
+func (s *Server) handleLLMStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := repository.LLMUsageOptions{ThemeName: query.Get("theme")}
+	for name, dst := range map[string]*time.Time{"since": &opts.Since, "until": &opts.Until} {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid %s: %w", name, err), "invalid query parameters")
+				return
+			}
+			*dst = t
+		}
+	}
+
+	themes, err := s.llmUsageRepo.Totals(r.Context(), opts)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to total llm usage", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to query llm usage")
+		return
+	}
+	if themes == nil {
+		themes = []models.LLMUsageTotals{}
+	}
+
+	var total models.LLMUsageTotals
+	for _, t := range themes {
+		total.Add(t)
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"themes": themes,
+			"total": map[string]interface{}{
+				"generations":       total.Generations,
+				"calls":             total.Calls,
+				"prompt_tokens":     total.PromptTokens,
+				"eval_tokens":       total.EvalTokens,
+				"total_duration_ms": total.TotalDurationMS,
+				"eval_duration_ms":  total.EvalDurationMS,
+			},
+		},
+	})
+}
+
+// handleUtilizationReport lists media with files that has never been scheduled, grouped by
+// genre. Filters: media_type (comma separated), and titles, the titles listed per genre
+// (0 for all).
+func (s *Server) handleUtilizationReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var mediaTypes []models.MediaType
+	if v := query.Get("media_type"); v != "" {
+		for _, mt := range strings.Split(v, ",") {
+			switch t := models.MediaType(strings.TrimSpace(mt)); t {
+			case models.MediaTypeMovie, models.MediaTypeSeries, models.MediaTypeAnime:
+				mediaTypes = append(mediaTypes, t)
+			default:
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid media_type %q", mt), "invalid query parameters")
+				return
+			}
+		}
+	}
+	titles := defaultUtilizationTitles
+	if v := query.Get("titles"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid titles %q", v), "invalid query parameters")
+			return
+		}
+		titles = n
+	}
+
+	report, err := media.Utilization(r.Context(), s.mediaRepo, mediaTypes, titles)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to build utilization report", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to build utilization report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data:    report,
+	})
+}
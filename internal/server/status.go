@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/upstream"
+)
+
+// upstreamTimeout bounds each dependency check made by /api/v1/status
+const upstreamTimeout = 5 * time.Second
+
+// Upstream is an external dependency reported by /api/v1/status
+type Upstream struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// UpstreamStatus is the result of checking one upstream
+type UpstreamStatus struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"` // ok, error
+	LatencyMS   int64      `json:"latency_ms"`
+	Error       string     `json:"error,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Circuit     string     `json:"circuit,omitempty"` // closed, open, or half-open
+}
+
+// upstreamTracker remembers when each upstream last answered successfully
+type upstreamTracker struct {
+	mu          sync.Mutex
+	lastSuccess map[string]time.Time
+}
+
+// check runs every upstream concurrently, each bounded by upstreamTimeout
+func (t *upstreamTracker) check(ctx context.Context, upstreams []Upstream) []UpstreamStatus {
+	results := make([]UpstreamStatus, len(upstreams))
+
+	var wg sync.WaitGroup
+	for i, u := range upstreams {
+		wg.Add(1)
+		go func(i int, u Upstream) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := u.Check(checkCtx)
+			results[i] = UpstreamStatus{
+				Name:      u.Name,
+				Status:    "ok",
+				LatencyMS: time.Since(start).Milliseconds(),
+				Circuit:   upstream.State(u.Name),
+			}
+			if err != nil {
+				results[i].Status = "error"
+				results[i].Error = err.Error()
+			}
+		}(i, u)
+	}
+	wg.Wait()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.lastSuccess == nil {
+		t.lastSuccess = make(map[string]time.Time)
+	}
+	for i := range results {
+		if results[i].Status == "ok" {
+			t.lastSuccess[results[i].Name] = time.Now()
+		}
+		if last, ok := t.lastSuccess[results[i].Name]; ok {
+			results[i].LastSuccess = &last
+		}
+	}
+
+	return results
+}
+
+// handleStatus checks every configured upstream and reports per-dependency health
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	results := s.upstreams.check(ctx, s.upstreamChecks)
+
+	active, err := s.alerts.Alerts(ctx, s.cfg().Themes)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list alerts", "error", err)
+		writeError(w, http.StatusInternalServerError, err, "failed to list alerts")
+		return
+	}
+
+	status, code := "ok", http.StatusOK
+	for _, res := range results {
+		if res.Status != "ok" {
+			s.logger.WarnContext(ctx, "upstream check failed", "upstream", res.Name, "error", res.Error)
+			status, code = "degraded", http.StatusServiceUnavailable
+		}
+	}
+
+	writeJSON(w, code, map[string]interface{}{
+		"status":    status,
+		"upstreams": results,
+		"alerts":    active,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// watchUpstreams checks the upstreams every alerts.check_interval seconds and records the
+// outcomes, so an outage alerts even when nobody polls /api/v1/status. An interval of 0
+// pauses the checks until a reload sets one.
+func (s *Server) watchUpstreams(ctx context.Context) {
+	for {
+		interval := time.Duration(s.cfg().Alerts.CheckInterval) * time.Second
+		wait := interval
+		if wait == 0 {
+			wait = time.Minute
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if interval == 0 {
+			continue
+		}
+
+		for _, res := range s.upstreams.check(ctx, s.upstreamChecks) {
+			var err error
+			if res.Status != "ok" {
+				err = errors.New(res.Error)
+			}
+			s.alerts.RecordUpstream(ctx, res.Name, err)
+		}
+	}
+}
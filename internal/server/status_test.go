@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/alerts"
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestHandleStatus(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name      string
+		upstreams []Upstream
+		want      int
+		status    string
+	}{
+		{"none configured", nil, http.StatusOK, "ok"},
+		{"all healthy", []Upstream{{"tunarr", ok}, {"ollama", ok}}, http.StatusOK, "ok"},
+		{"one down", []Upstream{{"tunarr", ok}, {"ollama", down}}, http.StatusServiceUnavailable, "degraded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&config.Config{}, &Config{Upstreams: tt.upstreams}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+			recorder := httptest.NewRecorder()
+			s.handleStatus(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+			if recorder.Code != tt.want {
+				t.Fatalf("status code = %d, want %d", recorder.Code, tt.want)
+			}
+
+			var body struct {
+				Status    string           `json:"status"`
+				Upstreams []UpstreamStatus `json:"upstreams"`
+			}
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Status != tt.status {
+				t.Errorf("status = %q, want %q", body.Status, tt.status)
+			}
+			if len(body.Upstreams) != len(tt.upstreams) {
+				t.Fatalf("got %d upstreams, want %d", len(body.Upstreams), len(tt.upstreams))
+			}
+			for i, u := range body.Upstreams {
+				if u.Name != tt.upstreams[i].Name {
+					t.Errorf("upstream %d = %q, want %q", i, u.Name, tt.upstreams[i].Name)
+				}
+				if (u.Status == "ok") != (u.LastSuccess != nil) {
+					t.Errorf("%s: status %q with last_success %v", u.Name, u.Status, u.LastSuccess)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleStatusAlerts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	monitor := alerts.New(&config.AlertsConfig{UpstreamFailures: 1}, nil, nil, logger)
+	monitor.RecordUpstream(context.Background(), "tunarr", errors.New("connection refused"))
+
+	s := NewServer(&config.Config{}, &Config{Alerts: monitor}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+	recorder := httptest.NewRecorder()
+	s.handleStatus(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+
+	var body struct {
+		Alerts []alerts.Alert `json:"alerts"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Alerts) != 1 || body.Alerts[0].Kind != alerts.KindUpstream || body.Alerts[0].Name != "tunarr" {
+		t.Errorf("alerts = %+v, want tunarr upstream alert", body.Alerts)
+	}
+}
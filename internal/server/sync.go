@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// syncEventsHeartbeat is how often an idle progress stream sends a keep-alive comment
+const syncEventsHeartbeat = 15 * time.Second
+
+// handleSyncStatus returns the running or most recent media sync's progress
+func (s *Server) handleSyncStatus(w http.ResponseWriter, r *http.Request) {
+	progress := s.syncService.Progress()
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"running":  progress != nil && progress.Running(),
+			"progress": progress,
+		},
+	})
+}
+
+// handleSyncEvents streams media sync progress as server-sent "progress" events, starting with
+// the current state, until the client disconnects
+func (s *Server) handleSyncEvents(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// The stream outlives the server's write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		writeError(w, http.StatusInternalServerError, err, "failed to start event stream")
+		return
+	}
+
+	events, unsubscribe := s.syncService.SubscribeProgress()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: progress\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if progress := s.syncService.Progress(); progress != nil {
+		if err := send(progress); err != nil {
+			return
+		}
+	} else if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(syncEventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case progress, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := send(progress); err != nil {
+				s.logger.DebugContext(r.Context(), "sync event stream closed", "error", err)
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
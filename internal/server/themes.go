@@ -0,0 +1,151 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/services/playlist"
+)
+
+// previewMaxLimit caps ?limit= on candidate previews
+const previewMaxLimit = 500
+
+// Default and maximum ?count= on theme suggestions
+const (
+	suggestDefaultCount = 5
+	suggestMaxCount     = 20
+)
+
+// handleThemeCandidates ranks a theme's candidates without touching Tunarr or cooldowns.
+// ?with_llm=true lets the LLM refine the ranking; ?limit= overrides the theme's max_items.
+func (s *Server) handleThemeCandidates(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	query := r.URL.Query()
+	withLLM := query.Get("with_llm") == "true"
+
+	var limit int
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > previewMaxLimit {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"), "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	theme := s.findTheme(name)
+	if theme == nil {
+		writeError(w, http.StatusNotFound, errors.New("theme not found"), "")
+		return
+	}
+
+	ctx := r.Context()
+	candidates, err := s.playlistGenerator.Preview(ctx, theme, withLLM, limit)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "candidate preview failed", "theme", name, "error", err)
+		writeError(w, errorStatus(err), err, "failed to preview candidates")
+		return
+	}
+
+	var totalRuntime int
+	for _, c := range candidates {
+		totalRuntime += c.Runtime
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"theme":         theme.Name,
+			"channel_id":    theme.ChannelID,
+			"with_llm":      withLLM,
+			"candidates":    candidates,
+			"count":         len(candidates),
+			"total_runtime": totalRuntime,
+		},
+	})
+}
+
+// handleThemeCompare ranks a theme's candidates with several scoring strategies side by side,
+// with the overlap of each pair of rankings. ?strategy= is repeated or comma separated
+// (default heuristic,llm); ?limit= overrides the theme's max_items.
+func (s *Server) handleThemeCompare(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	query := r.URL.Query()
+
+	var strategies []string
+	for _, v := range query["strategy"] {
+		for _, strategy := range strings.Split(v, ",") {
+			if strategy = strings.TrimSpace(strategy); strategy != "" && !slices.Contains(strategies, strategy) {
+				strategies = append(strategies, strategy)
+			}
+		}
+	}
+	if len(strategies) == 0 {
+		strategies = []string{playlist.StrategyHeuristic, playlist.StrategyLLM}
+	}
+
+	var limit int
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > previewMaxLimit {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"), "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	theme := s.findTheme(name)
+	if theme == nil {
+		writeError(w, http.StatusNotFound, errors.New("theme not found"), "")
+		return
+	}
+
+	ctx := r.Context()
+	comparison, err := s.playlistGenerator.Compare(ctx, theme, strategies, limit)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "strategy comparison failed", "theme", name, "error", err)
+		writeError(w, errorStatus(err), err, "failed to compare strategies")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{Success: true, Data: comparison})
+}
+
+// handleThemeSuggestions asks the LLM for new themes based on the library's genres, the
+// genres no theme covers, and titles that have never aired. ?count= sets how many (default 5).
+func (s *Server) handleThemeSuggestions(w http.ResponseWriter, r *http.Request) {
+	count := suggestDefaultCount
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > suggestMaxCount {
+			writeError(w, http.StatusBadRequest, errors.New("invalid count"), "count must be between 1 and 20")
+			return
+		}
+		count = n
+	}
+
+	cfg := s.cfg()
+	if !cfg.LLM.Enabled {
+		writeError(w, http.StatusServiceUnavailable, errors.New("llm disabled"), "theme suggestions need the LLM (llm.enabled)")
+		return
+	}
+
+	ctx := r.Context()
+	suggestions, err := s.playlistGenerator.SuggestThemes(ctx, cfg.Themes, count)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "theme suggestions failed", "error", err)
+		writeError(w, errorStatus(err), err, "failed to suggest themes")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"suggestions": suggestions,
+			"count":       len(suggestions),
+		},
+	})
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestThemeRoutes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{Themes: []config.ThemeConfig{{Name: "sci-fi", ChannelID: "1"}}}
+	s := NewServer(cfg, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{"no action", http.MethodGet, "/api/v1/themes/sci-fi", http.StatusNotFound},
+		{"unknown action", http.MethodGet, "/api/v1/themes/sci-fi/lineup", http.StatusNotFound},
+		{"unknown theme", http.MethodGet, "/api/v1/themes/horror/candidates", http.StatusNotFound},
+		{"bad limit", http.MethodGet, "/api/v1/themes/sci-fi/candidates?limit=0", http.StatusBadRequest},
+		{"limit too large", http.MethodGet, "/api/v1/themes/sci-fi/candidates?limit=501", http.StatusBadRequest},
+		{"wrong method", http.MethodPost, "/api/v1/themes/sci-fi/candidates", http.StatusMethodNotAllowed},
+		{"suggestions bad count", http.MethodGet, "/api/v1/themes/suggestions?count=21", http.StatusBadRequest},
+		{"suggestions wrong method", http.MethodPost, "/api/v1/themes/suggestions", http.StatusMethodNotAllowed},
+		{"suggestions without llm", http.MethodGet, "/api/v1/themes/suggestions", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			routes(s).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
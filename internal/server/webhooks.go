@@ -0,0 +1,237 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/clients/radarr"
+	"github.com/geekxflood/program-director/internal/clients/sonarr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// webhookMaxBody bounds the webhook payloads that are read for verification
+const webhookMaxBody = 1 << 20
+
+// Headers carrying a webhook's shared secret or HMAC signature
+const (
+	webhookSecretHeader    = "X-Webhook-Secret"
+	webhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// errWebhookUnverified is returned for webhook requests that fail verification
+var errWebhookUnverified = errors.New("webhook verification failed")
+
+// arrWebhook is the JSON payload of a Radarr or Sonarr webhook
+type arrWebhook struct {
+	EventType    string `json:"eventType"`
+	InstanceName string `json:"instanceName"`
+	Movie        *struct {
+		ID    int64  `json:"id"`
+		Title string `json:"title"`
+	} `json:"movie"`
+	Series *struct {
+		ID    int64  `json:"id"`
+		Title string `json:"title"`
+	} `json:"series"`
+}
+
+// Radarr and Sonarr events that change a title's files or metadata, and so refresh it
+var arrRefreshEvents = map[string]bool{
+	"Download":          true, // Imported or upgraded
+	"Rename":            true,
+	"MovieAdded":        true,
+	"MovieFileDelete":   true,
+	"SeriesAdd":         true,
+	"EpisodeFileDelete": true,
+}
+
+// webhookSource returns the source a /api/v1/webhooks/{source} path refers to
+func webhookSource(path string) (string, bool) {
+	source, ok := strings.CutPrefix(path, "/api/v1/webhooks/")
+	if !ok {
+		return "", false
+	}
+	for _, s := range config.WebhookSources {
+		if s == source {
+			return source, true
+		}
+	}
+	return "", false
+}
+
+// webhookSecured reports whether path is a webhook route with a secret, which then
+// authenticates it instead of an API key
+func (s *Server) webhookSecured(path string) bool {
+	source, ok := webhookSource(path)
+	if !ok {
+		return false
+	}
+	wh, _ := s.cfg().Server.Webhooks.Source(source)
+	return wh.Secret != ""
+}
+
+// verifyWebhook reads the request body and checks it against the source's secret. Sources
+// without a secret are not verified.
+func verifyWebhook(r *http.Request, wh config.WebhookConfig) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, webhookMaxBody))
+	if err != nil {
+		return nil, err
+	}
+	if wh.Secret == "" {
+		return body, nil
+	}
+
+	if wh.Verify == "hmac" {
+		signature := strings.TrimPrefix(r.Header.Get(webhookSignatureHeader), "sha256=")
+		got, err := hex.DecodeString(signature)
+		if err != nil || signature == "" {
+			return nil, errWebhookUnverified
+		}
+		mac := hmac.New(sha256.New, []byte(wh.Secret))
+		mac.Write(body)
+		if !hmac.Equal(got, mac.Sum(nil)) {
+			return nil, errWebhookUnverified
+		}
+		return body, nil
+	}
+
+	secret := r.Header.Get(webhookSecretHeader)
+	if secret == "" {
+		secret = r.URL.Query().Get("secret")
+	}
+	if secret == "" {
+		_, secret, _ = r.BasicAuth()
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(wh.Secret)) != 1 {
+		return nil, errWebhookUnverified
+	}
+	return body, nil
+}
+
+// readWebhook verifies a webhook request for source and returns its body, writing the error
+// response when it fails
+func (s *Server) readWebhook(w http.ResponseWriter, r *http.Request, source string) ([]byte, bool) {
+	wh, _ := s.cfg().Server.Webhooks.Source(source)
+	body, err := verifyWebhook(r, wh)
+	switch {
+	case errors.Is(err, errWebhookUnverified):
+		s.logger.WarnContext(r.Context(), "rejected unverified webhook", "source", source, "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusUnauthorized, err, "")
+		return nil, false
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err, "failed to read webhook payload")
+		return nil, false
+	}
+	return body, true
+}
+
+// handleRadarrWebhook refreshes the movie a Radarr event refers to
+func (s *Server) handleRadarrWebhook(w http.ResponseWriter, r *http.Request) {
+	s.handleArrWebhook(w, r, models.MediaSourceRadarr)
+}
+
+// handleSonarrWebhook refreshes the series a Sonarr event refers to
+func (s *Server) handleSonarrWebhook(w http.ResponseWriter, r *http.Request) {
+	s.handleArrWebhook(w, r, models.MediaSourceSonarr)
+}
+
+// handleArrWebhook refreshes the title of a Radarr or Sonarr import, rename, addition, or
+// file deletion, without a full sync. ?instance= names the configured instance that sent it,
+// defaulting to the first.
+func (s *Server) handleArrWebhook(w http.ResponseWriter, r *http.Request, source models.MediaSource) {
+	body, ok := s.readWebhook(w, r, string(source))
+	if !ok {
+		return
+	}
+
+	var payload arrWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err, "invalid JSON payload")
+		return
+	}
+
+	ctx := r.Context()
+	var externalID int64
+	var title string
+	switch {
+	case source == models.MediaSourceRadarr && payload.Movie != nil:
+		externalID, title = payload.Movie.ID, payload.Movie.Title
+	case source == models.MediaSourceSonarr && payload.Series != nil:
+		externalID, title = payload.Series.ID, payload.Series.Title
+	}
+
+	s.logger.DebugContext(ctx, "webhook received",
+		"source", source,
+		"event", payload.EventType,
+		"instance", payload.InstanceName,
+		"title", title,
+	)
+
+	if payload.EventType == "Test" {
+		writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "test received"})
+		return
+	}
+	if !arrRefreshEvents[payload.EventType] || externalID == 0 {
+		writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "event ignored"})
+		return
+	}
+	// The next sync picks the change up once automation resumes
+	if s.automationPaused(r) {
+		s.logger.InfoContext(ctx, "webhook refresh skipped, automation is paused", "source", source, "title", title)
+		writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "event ignored, automation is paused"})
+		return
+	}
+
+	result, err := s.syncService.Refresh(ctx, source, r.URL.Query().Get("instance"), externalID)
+	switch {
+	case errors.Is(err, radarr.ErrNotFound), errors.Is(err, sonarr.ErrNotFound):
+		writeJSON(w, http.StatusOK, successResponse{Success: true, Message: "media not found in " + string(source)})
+		return
+	case err != nil:
+		s.logger.ErrorContext(ctx, "webhook refresh failed",
+			"source", source,
+			"event", payload.EventType,
+			"external_id", externalID,
+			"error", err,
+		)
+		writeError(w, errorStatus(err), err, "media refresh failed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Message: "media refreshed",
+		Data: map[string]interface{}{
+			"title":   result.Media.Title,
+			"created": result.Created,
+		},
+	})
+}
+
+// handleTunarrWebhook acknowledges and logs Tunarr events
+func (s *Server) handleTunarrWebhook(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.readWebhook(w, r, "tunarr")
+	if !ok {
+		return
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err, "invalid JSON payload")
+		return
+	}
+	s.logger.InfoContext(r.Context(), "webhook received", "source", "tunarr", "payload", payload)
+
+	writeJSON(w, http.StatusOK, successResponse{
+		Success: true,
+		Message: "webhook received",
+	})
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	const body = `{"eventType": "Test"}`
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(body))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	shared := config.WebhookConfig{Secret: "s3cret", Verify: "secret"}
+	signed := config.WebhookConfig{Secret: "s3cret", Verify: "hmac"}
+
+	tests := []struct {
+		name    string
+		wh      config.WebhookConfig
+		target  string
+		headers map[string]string
+		basic   string
+		wantErr bool
+	}{
+		{"no secret configured", config.WebhookConfig{}, "/", nil, "", false},
+		{"secret header", shared, "/", map[string]string{webhookSecretHeader: "s3cret"}, "", false},
+		{"secret query", shared, "/?secret=s3cret", nil, "", false},
+		{"basic auth password", shared, "/", nil, "s3cret", false},
+		{"wrong secret", shared, "/", map[string]string{webhookSecretHeader: "guess"}, "", true},
+		{"missing secret", shared, "/", nil, "", true},
+		{"valid signature", signed, "/", map[string]string{webhookSignatureHeader: signature}, "", false},
+		{"signature without prefix", signed, "/", map[string]string{webhookSignatureHeader: strings.TrimPrefix(signature, "sha256=")}, "", false},
+		{"bad signature", signed, "/", map[string]string{webhookSignatureHeader: "sha256=00ff"}, "", true},
+		{"secret instead of signature", signed, "/?secret=s3cret", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if tt.basic != "" {
+				req.SetBasicAuth("radarr", tt.basic)
+			}
+
+			got, err := verifyWebhook(req, tt.wh)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && string(got) != body {
+				t.Errorf("verifyWebhook() body = %q, want %q", got, body)
+			}
+		})
+	}
+}
+
+func TestArrWebhookRoutes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{}
+	cfg.Server.APIKeys = []string{"api-key"}
+	cfg.Server.Webhooks.Radarr = config.WebhookConfig{Secret: "s3cret", Verify: "secret"}
+	s := NewServer(cfg, &Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	handler := s.requireAPIKey(routes(s))
+
+	tests := []struct {
+		name    string
+		target  string
+		body    string
+		want    int
+		message string
+	}{
+		{"radarr secret replaces the API key", "/api/v1/webhooks/radarr?secret=s3cret", `{"eventType": "Test"}`, http.StatusOK, "test received"},
+		{"radarr wrong secret", "/api/v1/webhooks/radarr?secret=guess", `{"eventType": "Test"}`, http.StatusUnauthorized, ""},
+		{"radarr ignored event", "/api/v1/webhooks/radarr?secret=s3cret", `{"eventType": "Grab", "movie": {"id": 1}}`, http.StatusOK, "event ignored"},
+		{"radarr invalid payload", "/api/v1/webhooks/radarr?secret=s3cret", `not json`, http.StatusBadRequest, ""},
+		{"sonarr without secret needs the API key", "/api/v1/webhooks/sonarr", `{"eventType": "Test"}`, http.StatusUnauthorized, ""},
+		{"sonarr with API key", "/api/v1/webhooks/sonarr?api_key=api-key", `{"eventType": "Test"}`, http.StatusOK, "test received"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+			if tt.message != "" && !strings.Contains(recorder.Body.String(), tt.message) {
+				t.Errorf("body = %s, want message %q", recorder.Body, tt.message)
+			}
+		})
+	}
+}
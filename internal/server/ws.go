@@ -0,0 +1,275 @@
+package server
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/events"
+	"github.com/geekxflood/program-director/internal/services/media"
+)
+
+const (
+	// wsGUID is appended to the client key to compute Sec-WebSocket-Accept (RFC 6455)
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// wsPingInterval is how often an idle connection is pinged to keep proxies from closing it
+	wsPingInterval = 30 * time.Second
+	// wsWriteTimeout bounds writing a frame to a client
+	wsWriteTimeout = 10 * time.Second
+	// wsMaxMessage caps what a client may send; the endpoint only pushes
+	wsMaxMessage = 4 << 10
+)
+
+// WebSocket opcodes
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+	wsPing  = 0x9
+	wsPong  = 0xA
+)
+
+// wsFrame is a frame to write to a client
+type wsFrame struct {
+	opcode  byte
+	payload []byte
+}
+
+// handleWebSocket upgrades to a WebSocket that pushes events as JSON text messages of
+// {"type", "time", "data"}: sync progress, generation results, scheduler runs, and cooldowns
+// set by recorded plays. It starts with the current sync progress, if any.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if s.events == nil {
+		writeError(w, http.StatusNotFound, errors.New("live events are not available"), "")
+		return
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		writeError(w, http.StatusBadRequest, errors.New("websocket upgrade required"), "")
+		return
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		writeError(w, http.StatusUpgradeRequired, errors.New("unsupported websocket version"), "")
+		return
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing Sec-WebSocket-Key"), "")
+		return
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err, "failed to upgrade connection")
+		return
+	}
+	defer conn.Close()
+	// The connection outlives the server's read and write timeouts
+	_ = conn.SetDeadline(time.Time{})
+
+	ctx := r.Context()
+	if _, err := rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAccept(key) + "\r\n\r\n"); err != nil {
+		return
+	}
+	if err := rw.Flush(); err != nil {
+		return
+	}
+	s.logger.DebugContext(ctx, "websocket connected", "remote_addr", r.RemoteAddr)
+
+	bus, unsubscribe := s.events.Subscribe()
+	defer unsubscribe()
+	var progress <-chan media.Progress
+	if s.syncService != nil {
+		ch, unsubscribeProgress := s.syncService.SubscribeProgress()
+		defer unsubscribeProgress()
+		progress = ch
+	}
+
+	replies := make(chan wsFrame, 4)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		wsRead(rw.Reader, replies)
+	}()
+
+	send := func(f wsFrame) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := wsWrite(rw.Writer, f); err != nil {
+			return err
+		}
+		return rw.Flush()
+	}
+	sendEvent := func(event events.Event) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return send(wsFrame{opcode: wsText, payload: data})
+	}
+
+	if s.syncService != nil {
+		if p := s.syncService.Progress(); p != nil {
+			if err := sendEvent(events.Event{Type: events.TypeSyncProgress, Time: p.UpdatedAt, Data: p}); err != nil {
+				return
+			}
+		}
+	}
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			_ = send(wsFrame{opcode: wsClose, payload: wsCloseCode(1001)})
+			return
+		case <-closed:
+			return
+		case f := <-replies:
+			err = send(f)
+			if f.opcode == wsClose {
+				return
+			}
+		case event, ok := <-bus:
+			if !ok {
+				return
+			}
+			err = sendEvent(event)
+		case p, ok := <-progress:
+			if !ok {
+				progress = nil
+				continue
+			}
+			err = sendEvent(events.Event{Type: events.TypeSyncProgress, Time: p.UpdatedAt, Data: p})
+		case <-ping.C:
+			err = send(wsFrame{opcode: wsPing})
+		}
+		if err != nil {
+			s.logger.DebugContext(ctx, "websocket closed", "error", err)
+			return
+		}
+	}
+}
+
+// headerHasToken reports whether a comma-separated header contains token, ignoring case
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wsAccept returns the Sec-WebSocket-Accept of a client key
+func wsAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// wsCloseCode returns the payload of a close frame with a status code
+func wsCloseCode(code uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, code)
+}
+
+// wsWrite writes an unfragmented, unmasked frame, as servers send them
+func wsWrite(w io.Writer, f wsFrame) error {
+	header := []byte{0x80 | f.opcode}
+	switch n := len(f.payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(f.payload)
+	return err
+}
+
+// wsRead reads client frames until the connection fails or closes, queueing the pong of each
+// ping and the reply to a close. Data messages are discarded.
+func wsRead(r *bufio.Reader, replies chan<- wsFrame) {
+	for {
+		opcode, payload, err := wsReadFrame(r)
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case wsPing:
+			select {
+			case replies <- wsFrame{opcode: wsPong, payload: payload}:
+			default:
+			}
+		case wsClose:
+			if len(payload) >= 2 {
+				payload = payload[:2]
+			}
+			select {
+			case replies <- wsFrame{opcode: wsClose, payload: payload}:
+			default:
+			}
+			return
+		}
+	}
+}
+
+// wsReadFrame reads and unmasks one client frame
+func wsReadFrame(r *bufio.Reader) (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	if head[1]&0x80 == 0 {
+		return 0, nil, errors.New("unmasked client frame")
+	}
+
+	size := uint64(head[1] & 0x7F)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if size > wsMaxMessage {
+		return 0, nil, errors.New("client frame too large")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/events"
+)
+
+func TestWebSocket(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	bus := events.NewBus()
+	s := NewServer(&config.Config{}, &Config{Events: bus}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+	server := httptest.NewServer(routes(s))
+	defer server.Close()
+
+	recorder := httptest.NewRecorder()
+	routes(s).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/ws", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without an upgrade, got %d", recorder.Code)
+	}
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	io.WriteString(conn, "GET /api/v1/ws HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+key+"\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected handshake: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	// The subscription starts with the upgrade; publish until the event arrives
+	go func() {
+		for i := 0; i < 50; i++ {
+			bus.Publish(events.TypeGeneration, map[string]string{"theme": "sci-fi"})
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	opcode, payload := readServerFrame(t, r)
+	var event events.Event
+	if err := json.Unmarshal(payload, &event); err != nil || opcode != wsText {
+		t.Fatalf("expected a JSON text message, got opcode %d: %s", opcode, payload)
+	}
+	if event.Type != events.TypeGeneration {
+		t.Errorf("event type = %q, want %q", event.Type, events.TypeGeneration)
+	}
+
+	// A masked close frame is answered with a close frame
+	mask := []byte{1, 2, 3, 4}
+	code := wsCloseCode(1000)
+	frame := []byte{0x80 | wsClose, 0x80 | byte(len(code))}
+	frame = append(frame, mask...)
+	for i, b := range code {
+		frame = append(frame, b^mask[i%4])
+	}
+	conn.Write(frame)
+	for {
+		opcode, payload = readServerFrame(t, r)
+		if opcode == wsClose {
+			break
+		}
+	}
+	if binary.BigEndian.Uint16(payload) != 1000 {
+		t.Errorf("close code = %d, want 1000", binary.BigEndian.Uint16(payload))
+	}
+}
+
+// readServerFrame reads an unmasked frame sent by the server
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatalf("failed to read frame: %v", err)
+	}
+	size := int(head[1] & 0x7F)
+	switch size {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		size = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		size = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+	return head[0] & 0x0F, payload
+}
@@ -4,10 +4,12 @@ package cooldown
 import (
 	"context"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/geekxflood/program-director/internal/config"
 	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/events"
 	"github.com/geekxflood/program-director/pkg/models"
 )
 
@@ -15,68 +17,127 @@ import (
 type Manager struct {
 	cooldownRepo *repository.CooldownRepository
 	historyRepo  *repository.HistoryRepository
-	config       *config.CooldownConfig
+	watchRepo    *repository.WatchHistoryRepository
+	config       atomic.Pointer[config.CooldownConfig]
+	events       *events.Bus
 	logger       *slog.Logger
 }
 
+// PlayEvent is published to the event bus when a play is recorded and its title's cooldown set
+type PlayEvent struct {
+	MediaID     int64             `json:"media_id"`
+	MediaTitle  string            `json:"media_title"`
+	MediaType   models.MediaType  `json:"media_type"`
+	ChannelID   string            `json:"channel_id"`
+	ThemeName   string            `json:"theme"`
+	Source      models.PlaySource `json:"source"`
+	CanReplayAt time.Time         `json:"can_replay_at"`
+}
+
 // NewManager creates a new cooldown Manager
 func NewManager(
 	cooldownRepo *repository.CooldownRepository,
 	historyRepo *repository.HistoryRepository,
+	watchRepo *repository.WatchHistoryRepository,
 	cfg *config.CooldownConfig,
 	logger *slog.Logger,
 ) *Manager {
-	return &Manager{
+	m := &Manager{
 		cooldownRepo: cooldownRepo,
 		historyRepo:  historyRepo,
-		config:       cfg,
+		watchRepo:    watchRepo,
 		logger:       logger,
 	}
+	m.config.Store(cfg)
+	return m
+}
+
+// SetConfig swaps in reloaded cooldown settings; they apply to plays recorded from now on
+func (m *Manager) SetConfig(cfg *config.CooldownConfig) {
+	m.config.Store(cfg)
+}
+
+// SetEvents sets the bus recorded plays are published to
+func (m *Manager) SetEvents(bus *events.Bus) {
+	m.events = bus
+}
+
+// RecordPlays records that media were applied to a channel lineup and sets their cooldowns,
+// all in one transaction: on error none of them is recorded
+func (m *Manager) RecordPlays(ctx context.Context, media []*models.Media, channelID, themeName string) error {
+	playedAt := time.Now()
+	plays := make([]models.PlayHistory, len(media))
+	cooldowns := make([]models.MediaCooldown, len(media))
+	for i, item := range media {
+		plays[i], cooldowns[i] = m.newPlay(item, channelID, themeName, models.PlaySourceLineup, playedAt)
+	}
+
+	if err := m.cooldownRepo.RecordPlays(ctx, plays, cooldowns); err != nil {
+		return err
+	}
+	for i := range plays {
+		m.recorded(ctx, &plays[i], &cooldowns[i])
+	}
+	return nil
 }
 
-// RecordPlay records that a media item was played and sets its cooldown
-func (m *Manager) RecordPlay(ctx context.Context, media *models.Media, channelID, themeName string) error {
-	now := time.Now()
+// RecordAiring records that a media item actually aired and restarts its cooldown from the airing time
+func (m *Manager) RecordAiring(ctx context.Context, media *models.Media, channelID, themeName string, airedAt time.Time) error {
+	history, cooldown := m.newPlay(media, channelID, themeName, models.PlaySourcePlex, airedAt)
+
+	if err := m.historyRepo.Create(ctx, &history); err != nil {
+		return err
+	}
+	if err := m.cooldownRepo.Upsert(ctx, &cooldown); err != nil {
+		return err
+	}
+	m.recorded(ctx, &history, &cooldown)
+	return nil
+}
 
-	// Create play history record
-	history := &models.PlayHistory{
+// newPlay builds the play history entry of a media item and the cooldown it starts
+func (m *Manager) newPlay(media *models.Media, channelID, themeName string, source models.PlaySource, playedAt time.Time) (models.PlayHistory, models.MediaCooldown) {
+	history := models.PlayHistory{
 		MediaID:    media.ID,
 		ChannelID:  channelID,
 		ThemeName:  themeName,
-		PlayedAt:   now,
+		PlayedAt:   playedAt,
+		Source:     source,
 		MediaTitle: media.Title,
 		MediaType:  media.MediaType,
 	}
 
-	if err := m.historyRepo.Create(ctx, history); err != nil {
-		return err
-	}
-
 	// Determine cooldown days based on media type
 	cooldownDays := m.getCooldownDays(media.MediaType)
-
-	// Create or update cooldown
-	cooldown := &models.MediaCooldown{
+	cooldown := models.MediaCooldown{
 		MediaID:      media.ID,
 		CooldownDays: cooldownDays,
-		LastPlayedAt: now,
-		CanReplayAt:  now.AddDate(0, 0, cooldownDays),
+		LastPlayedAt: playedAt,
+		CanReplayAt:  playedAt.AddDate(0, 0, cooldownDays),
 		MediaTitle:   media.Title,
 		MediaType:    media.MediaType,
 	}
+	return history, cooldown
+}
 
-	if err := m.cooldownRepo.Upsert(ctx, cooldown); err != nil {
-		return err
-	}
-
-	m.logger.Debug("recorded play and cooldown",
-		"media_id", media.ID,
-		"title", media.Title,
-		"cooldown_days", cooldownDays,
+// recorded logs and publishes a stored play and its cooldown
+func (m *Manager) recorded(ctx context.Context, history *models.PlayHistory, cooldown *models.MediaCooldown) {
+	m.logger.DebugContext(ctx, "recorded play and cooldown",
+		"media_id", history.MediaID,
+		"source", history.Source,
+		"title", history.MediaTitle,
+		"cooldown_days", cooldown.CooldownDays,
 		"can_replay_at", cooldown.CanReplayAt,
 	)
-
-	return nil
+	m.events.Publish(events.TypeCooldown, PlayEvent{
+		MediaID:     history.MediaID,
+		MediaTitle:  history.MediaTitle,
+		MediaType:   history.MediaType,
+		ChannelID:   history.ChannelID,
+		ThemeName:   history.ThemeName,
+		Source:      history.Source,
+		CanReplayAt: cooldown.CanReplayAt,
+	})
 }
 
 // GetActiveCooldownMediaIDs returns IDs of all media currently on cooldown
@@ -84,16 +145,31 @@ func (m *Manager) GetActiveCooldownMediaIDs(ctx context.Context) ([]int64, error
 	return m.cooldownRepo.GetActiveCooldownMediaIDs(ctx)
 }
 
+// GetRecentlyWatchedMediaIDs returns IDs of media the household watched within the configured window
+func (m *Manager) GetRecentlyWatchedMediaIDs(ctx context.Context) ([]int64, error) {
+	days := m.config.Load().WatchedDays
+	if m.watchRepo == nil || days <= 0 {
+		return nil, nil
+	}
+	return m.watchRepo.RecentMediaIDs(ctx, time.Now().AddDate(0, 0, -days))
+}
+
+// WatchedPenalty returns the score penalty for recently watched media; 0 means exclude them
+func (m *Manager) WatchedPenalty() float64 {
+	return m.config.Load().WatchedPenalty
+}
+
 // getCooldownDays returns the cooldown days for a media type
 func (m *Manager) getCooldownDays(mediaType models.MediaType) int {
+	cfg := m.config.Load()
 	switch mediaType {
 	case models.MediaTypeMovie:
-		return m.config.MovieDays
+		return cfg.MovieDays
 	case models.MediaTypeSeries:
-		return m.config.SeriesDays
+		return cfg.SeriesDays
 	case models.MediaTypeAnime:
-		return m.config.AnimeDays
+		return cfg.AnimeDays
 	default:
-		return m.config.MovieDays
+		return cfg.MovieDays
 	}
 }
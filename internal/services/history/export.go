@@ -0,0 +1,148 @@
+// Package history exports the recorded play history for external analysis
+package history
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// Format is a play history export format
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// csvHeader names the columns of a CSV export
+var csvHeader = []string{"id", "played_at", "channel_id", "theme_name", "media_id", "media_title", "media_type", "source"}
+
+// ParseFormat returns the export format with the given name, CSV when empty
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(name); f {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid format %q (must be csv or json)", name)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Export streams the play history matching opts to w, newest first, and returns the
+// number of entries written. JSON exports are a single array.
+func Export(ctx context.Context, repo *repository.HistoryRepository, w io.Writer, format Format, opts repository.ListHistoryOptions) (int, error) {
+	enc, err := newEncoder(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	// Large histories take a while to stream, especially to slow clients
+	ctx = database.WithoutQueryTimeout(ctx)
+
+	count := 0
+	err = repo.Each(ctx, opts, func(h models.PlayHistory) error {
+		count++
+		return enc.write(h)
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to export history: %w", err)
+	}
+	if err := enc.close(); err != nil {
+		return count, fmt.Errorf("failed to export history: %w", err)
+	}
+	return count, nil
+}
+
+// encoder writes play history entries in one export format
+type encoder interface {
+	write(h models.PlayHistory) error
+	close() error
+}
+
+// newEncoder returns an encoder for format writing to w, writing any header right away
+func newEncoder(w io.Writer, format Format) (encoder, error) {
+	switch format {
+	case FormatCSV:
+		enc := &csvEncoder{w: csv.NewWriter(w)}
+		if err := enc.w.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return enc, nil
+	case FormatJSON:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return nil, err
+		}
+		return &jsonEncoder{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// csvEncoder writes one row per entry under a header row
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) write(h models.PlayHistory) error {
+	return e.w.Write([]string{
+		strconv.FormatInt(h.ID, 10),
+		h.PlayedAt.UTC().Format(time.RFC3339),
+		h.ChannelID,
+		h.ThemeName,
+		strconv.FormatInt(h.MediaID, 10),
+		h.MediaTitle,
+		string(h.MediaType),
+		string(h.Source),
+	})
+}
+
+func (e *csvEncoder) close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonEncoder writes the entries as the elements of a JSON array
+type jsonEncoder struct {
+	w       io.Writer
+	written bool
+}
+
+func (e *jsonEncoder) write(h models.PlayHistory) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if !e.written {
+		sep = "\n"
+		e.written = true
+	}
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonEncoder) close() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
@@ -0,0 +1,103 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatCSV, false},
+		{"csv", FormatCSV, false},
+		{"json", FormatJSON, false},
+		{"xml", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	playedAt := time.Date(2026, 3, 14, 20, 0, 0, 0, time.UTC)
+	entries := []models.PlayHistory{
+		{ID: 2, MediaID: 7, ChannelID: "ch-1", ThemeName: "noir", PlayedAt: playedAt, MediaTitle: "Heat, Part 1", MediaType: models.MediaTypeMovie, Source: models.PlaySourcePlex},
+		{ID: 1, MediaID: 8, ChannelID: "ch-1", ThemeName: "noir", PlayedAt: playedAt.Add(-time.Hour), MediaTitle: "Chinatown", MediaType: models.MediaTypeMovie, Source: models.PlaySourceLineup},
+	}
+
+	tests := []struct {
+		name    string
+		format  Format
+		entries []models.PlayHistory
+	}{
+		{"csv", FormatCSV, entries},
+		{"csv empty", FormatCSV, nil},
+		{"json", FormatJSON, entries},
+		{"json empty", FormatJSON, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, err := newEncoder(&buf, tt.format)
+			if err != nil {
+				t.Fatalf("newEncoder() error = %v", err)
+			}
+			for _, h := range tt.entries {
+				if err := enc.write(h); err != nil {
+					t.Fatalf("write() error = %v", err)
+				}
+			}
+			if err := enc.close(); err != nil {
+				t.Fatalf("close() error = %v", err)
+			}
+
+			switch tt.format {
+			case FormatCSV:
+				records, err := csv.NewReader(&buf).ReadAll()
+				if err != nil {
+					t.Fatalf("invalid CSV: %v", err)
+				}
+				if len(records) != len(tt.entries)+1 {
+					t.Fatalf("got %d records, want header and %d rows", len(records), len(tt.entries))
+				}
+				for i, h := range tt.entries {
+					row := records[i+1]
+					if row[1] != h.PlayedAt.Format(time.RFC3339) || row[5] != h.MediaTitle || row[7] != string(h.Source) {
+						t.Errorf("row %d = %v, want %+v", i, row, h)
+					}
+				}
+			case FormatJSON:
+				var got []models.PlayHistory
+				if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+					t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+				}
+				if len(got) != len(tt.entries) {
+					t.Fatalf("got %d entries, want %d", len(got), len(tt.entries))
+				}
+				for i, h := range tt.entries {
+					if got[i].ID != h.ID || !got[i].PlayedAt.Equal(h.PlayedAt) || got[i].MediaTitle != h.MediaTitle {
+						t.Errorf("entry %d = %+v, want %+v", i, got[i], h)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package lists
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/upstream"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// fetchIMDbList reads an IMDb list CSV export from a URL or a local file path
+func fetchIMDbList(ctx context.Context, location string) ([]models.ListItem, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open IMDb export: %w", err)
+		}
+		defer f.Close()
+		return parseIMDbCSV(f)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second, Transport: upstream.Transport("imdb", nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download IMDb export: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to download IMDb export: status %d", resp.StatusCode)
+	}
+
+	return parseIMDbCSV(resp.Body)
+}
+
+// parseIMDbCSV parses the CSV produced by IMDb's list export.
+// Columns are located by header name since IMDb has reordered them over time.
+func parseIMDbCSV(r io.Reader) ([]models.ListItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IMDb export header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	constCol, ok := columns["const"]
+	if !ok {
+		return nil, errors.New("IMDb export is missing the Const column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []models.ListItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read IMDb export: %w", err)
+		}
+		if constCol >= len(record) || !strings.HasPrefix(record[constCol], "tt") {
+			continue
+		}
+
+		item := models.ListItem{
+			Title:     field(record, "title"),
+			IMDBID:    strings.TrimSpace(record[constCol]),
+			MediaType: imdbMediaType(field(record, "title type")),
+		}
+		item.Rank, _ = strconv.Atoi(field(record, "position"))
+		item.Year, _ = strconv.Atoi(field(record, "year"))
+		if item.Rank == 0 {
+			item.Rank = len(items) + 1
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// imdbMediaType maps an IMDb "Title Type" value to a media type
+func imdbMediaType(titleType string) models.MediaType {
+	switch strings.ToLower(titleType) {
+	case "tv series", "tvseries", "tv mini series", "tvminiseries":
+		return models.MediaTypeSeries
+	default:
+		return models.MediaTypeMovie
+	}
+}
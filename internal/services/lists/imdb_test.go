@@ -0,0 +1,40 @@
+package lists
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestParseIMDbCSV(t *testing.T) {
+	csvData := "\ufeffPosition,Const,Created,Modified,Description,Title,URL,Title Type,IMDb Rating,Runtime (mins),Year\n" +
+		"1,tt0133093,2024-01-01,2024-01-01,,The Matrix,https://www.imdb.com/title/tt0133093/,Movie,8.7,136,1999\n" +
+		"2,tt0903747,2024-01-01,2024-01-01,,\"Breaking Bad\",https://www.imdb.com/title/tt0903747/,TV Series,9.5,49,2008\n" +
+		"3,nm0000206,2024-01-01,2024-01-01,,Keanu Reeves,https://www.imdb.com/name/nm0000206/,,,,\n"
+
+	items, err := parseIMDbCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("parseIMDbCSV() error = %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	want := []models.ListItem{
+		{Rank: 1, Title: "The Matrix", Year: 1999, MediaType: models.MediaTypeMovie, IMDBID: "tt0133093"},
+		{Rank: 2, Title: "Breaking Bad", Year: 2008, MediaType: models.MediaTypeSeries, IMDBID: "tt0903747"},
+	}
+	for i, w := range want {
+		if items[i] != w {
+			t.Errorf("item %d = %+v, want %+v", i, items[i], w)
+		}
+	}
+}
+
+func TestParseIMDbCSVMissingConst(t *testing.T) {
+	if _, err := parseIMDbCSV(strings.NewReader("Title,Year\nThe Matrix,1999\n")); err == nil {
+		t.Error("expected error for export without Const column")
+	}
+}
@@ -0,0 +1,145 @@
+// Package lists provides import of static title lists used as theme filters.
+package lists
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/mdblist"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// SyncService imports configured mdblist and IMDb lists into the database
+type SyncService struct {
+	mdblist  *mdblist.Client
+	listRepo *repository.ListRepository
+	logger   *slog.Logger
+}
+
+// NewSyncService creates a new SyncService
+func NewSyncService(
+	mdblistClient *mdblist.Client,
+	listRepo *repository.ListRepository,
+	logger *slog.Logger,
+) *SyncService {
+	return &SyncService{
+		mdblist:  mdblistClient,
+		listRepo: listRepo,
+		logger:   logger,
+	}
+}
+
+// SyncResult contains the result of importing one list
+type SyncResult struct {
+	Name     string
+	Source   models.ListSource
+	Items    int
+	Error    error
+	Duration time.Duration
+}
+
+// SyncAll imports every configured list, continuing past individual failures
+func (s *SyncService) SyncAll(ctx context.Context, lists []config.ListConfig) []SyncResult {
+	results := make([]SyncResult, 0, len(lists))
+
+	for i := range lists {
+		select {
+		case <-ctx.Done():
+			return results
+		default:
+		}
+
+		result := s.Sync(ctx, &lists[i])
+		if result.Error != nil {
+			s.logger.Error("list sync failed",
+				"list", result.Name,
+				"error", result.Error,
+			)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// Sync imports a single list, replacing any previously imported items
+func (s *SyncService) Sync(ctx context.Context, cfg *config.ListConfig) SyncResult {
+	start := time.Now()
+	result := SyncResult{
+		Name:   cfg.Name,
+		Source: models.ListSource(cfg.Source),
+	}
+
+	s.logger.Info("syncing list", "list", cfg.Name, "source", cfg.Source, "url", cfg.URL)
+
+	items, err := s.fetch(ctx, cfg)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	list := &models.List{
+		Name:   cfg.Name,
+		Source: result.Source,
+		URL:    cfg.URL,
+	}
+	if err := s.listRepo.Replace(ctx, list, items); err != nil {
+		result.Error = fmt.Errorf("failed to store list: %w", err)
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	result.Items = len(items)
+	result.Duration = time.Since(start)
+
+	s.logger.Info("list sync complete",
+		"list", cfg.Name,
+		"items", result.Items,
+		"duration", result.Duration,
+	)
+
+	return result
+}
+
+// fetch retrieves list items from the configured source
+func (s *SyncService) fetch(ctx context.Context, cfg *config.ListConfig) ([]models.ListItem, error) {
+	switch models.ListSource(cfg.Source) {
+	case models.ListSourceMDBList:
+		entries, err := s.mdblist.GetList(ctx, cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch mdblist list: %w", err)
+		}
+		return fromMDBList(entries), nil
+	case models.ListSourceIMDb:
+		return fetchIMDbList(ctx, cfg.URL)
+	default:
+		return nil, fmt.Errorf("unsupported list source %q", cfg.Source)
+	}
+}
+
+// fromMDBList converts mdblist entries to list items
+func fromMDBList(entries []mdblist.Item) []models.ListItem {
+	items := make([]models.ListItem, 0, len(entries))
+	for _, e := range entries {
+		item := models.ListItem{
+			Rank:   e.Rank,
+			Title:  e.Title,
+			Year:   e.ReleaseYear,
+			IMDBID: e.IMDBID,
+			TVDBID: e.TVDBID,
+		}
+		if e.MediaType == "show" {
+			item.MediaType = models.MediaTypeSeries
+		} else {
+			item.MediaType = models.MediaTypeMovie
+			item.TMDBID = e.ID
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,153 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/animelists"
+	"github.com/geekxflood/program-director/internal/clients/sonarr"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// Anime detection modes for Sonarr series
+const (
+	// AnimeDetectionHeuristic treats Sonarr's anime series type or anime genres as anime
+	AnimeDetectionHeuristic = "heuristic"
+	// AnimeDetectionSeriesType trusts only Sonarr's series type
+	AnimeDetectionSeriesType = "series_type"
+	// AnimeDetectionMapping treats series whose TVDB ID is in an AniDB/AniList ID mapping,
+	// or whose Sonarr series type is anime, as anime
+	AnimeDetectionMapping = "mapping"
+)
+
+// animeMappingMaxAge is how long a fetched ID mapping is reused before it is fetched again
+const animeMappingMaxAge = 24 * time.Hour
+
+// AnimeDetector decides which Sonarr series are stored as anime. A nil detector uses the
+// heuristic.
+type AnimeDetector struct {
+	mode    string
+	mapping *animelists.Client
+
+	mu       sync.Mutex
+	tvdbIDs  map[int64]bool
+	loadedAt time.Time
+}
+
+// NewAnimeDetector creates a detector for mode; mapping is only used by AnimeDetectionMapping
+func NewAnimeDetector(mode string, mapping *animelists.Client) *AnimeDetector {
+	if mode == "" {
+		mode = AnimeDetectionHeuristic
+	}
+	return &AnimeDetector{mode: mode, mapping: mapping}
+}
+
+// prepare fetches the ID mapping when the mode needs it and the loaded one is stale. When the
+// fetch fails, the previously loaded mapping is kept.
+func (d *AnimeDetector) prepare(ctx context.Context) error {
+	if d == nil || d.mode != AnimeDetectionMapping || d.mapping == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.tvdbIDs != nil && time.Since(d.loadedAt) < animeMappingMaxAge {
+		return nil
+	}
+
+	entries, err := d.mapping.GetMapping(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch anime ID mapping: %w", err)
+	}
+	d.tvdbIDs = animelists.TVDBIDs(entries)
+	d.loadedAt = time.Now()
+	return nil
+}
+
+// mediaType returns the media type to store for a series. Without a loaded mapping, the
+// mapping mode falls back to Sonarr's series type.
+func (d *AnimeDetector) mediaType(show *sonarr.Series) models.MediaType {
+	anime := false
+	switch {
+	case d == nil || d.mode == AnimeDetectionHeuristic:
+		anime = show.IsAnime()
+	case d.mode == AnimeDetectionMapping:
+		d.mu.Lock()
+		anime = show.SeriesType == "anime" || d.tvdbIDs[show.TVDBID]
+		d.mu.Unlock()
+	default:
+		anime = show.SeriesType == "anime"
+	}
+
+	if anime {
+		return models.MediaTypeAnime
+	}
+	return models.MediaTypeSeries
+}
+
+// ReclassifyResult lists stored series whose media type changed
+type ReclassifyResult struct {
+	Checked int
+	Changes []string // "Title (Year) [instance]: series -> anime"
+}
+
+// ReclassifyAnime re-applies anime detection to stored series using their current Sonarr
+// data, without a full sync. With dryRun, changes are only reported.
+func (s *SyncService) ReclassifyAnime(ctx context.Context, dryRun bool) (*ReclassifyResult, error) {
+	if err := s.anime.prepare(ctx); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.mediaRepo.List(ctx, repository.ListMediaOptions{Source: models.MediaSourceSonarr})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list series: %w", err)
+	}
+	byKey := make(map[string]*models.Media, len(stored))
+	for i := range stored {
+		byKey[syncKey(&stored[i])] = &stored[i]
+	}
+
+	result := &ReclassifyResult{}
+	for _, client := range s.sonarr {
+		series, err := client.GetSeries(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("sonarr instance %s: %w", client.Name(), err)
+		}
+
+		for i := range series {
+			show := &series[i]
+			m, ok := byKey[syncKey(&models.Media{
+				Source:         models.MediaSourceSonarr,
+				SourceInstance: client.Name(),
+				ExternalID:     show.ID,
+			})]
+			if !ok {
+				continue
+			}
+			result.Checked++
+
+			mediaType := s.anime.mediaType(show)
+			if mediaType == m.MediaType {
+				continue
+			}
+			result.Changes = append(result.Changes, fmt.Sprintf("%s: %s -> %s", changeLabel(m), m.MediaType, mediaType))
+			if dryRun {
+				continue
+			}
+			if err := s.mediaRepo.UpdateMediaType(ctx, m.ID, mediaType); err != nil {
+				return nil, fmt.Errorf("failed to update %s: %w", m.Title, err)
+			}
+		}
+	}
+
+	s.logger.InfoContext(ctx, "anime reclassification complete",
+		"checked", result.Checked,
+		"changed", len(result.Changes),
+		"dry_run", dryRun,
+	)
+	return result, nil
+}
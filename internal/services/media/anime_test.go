@@ -0,0 +1,44 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/clients/sonarr"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestAnimeDetectorMediaType(t *testing.T) {
+	animeType := sonarr.Series{SeriesType: "anime", TVDBID: 1}
+	animeGenre := sonarr.Series{SeriesType: "standard", Genres: []string{"Anime", "Comedy"}, TVDBID: 2}
+	mapped := sonarr.Series{SeriesType: "standard", Genres: []string{"Animation"}, TVDBID: 3}
+	western := sonarr.Series{SeriesType: "standard", Genres: []string{"Animation", "Comedy"}, TVDBID: 4}
+
+	mapping := NewAnimeDetector(AnimeDetectionMapping, nil)
+	mapping.tvdbIDs = map[int64]bool{3: true}
+
+	tests := []struct {
+		name     string
+		detector *AnimeDetector
+		show     sonarr.Series
+		want     models.MediaType
+	}{
+		{"nil uses heuristic", nil, animeGenre, models.MediaTypeAnime},
+		{"heuristic series type", NewAnimeDetector("", nil), animeType, models.MediaTypeAnime},
+		{"heuristic genre", NewAnimeDetector(AnimeDetectionHeuristic, nil), animeGenre, models.MediaTypeAnime},
+		{"heuristic western animation", NewAnimeDetector(AnimeDetectionHeuristic, nil), western, models.MediaTypeSeries},
+		{"series type anime", NewAnimeDetector(AnimeDetectionSeriesType, nil), animeType, models.MediaTypeAnime},
+		{"series type ignores genres", NewAnimeDetector(AnimeDetectionSeriesType, nil), animeGenre, models.MediaTypeSeries},
+		{"mapping match", mapping, mapped, models.MediaTypeAnime},
+		{"mapping keeps series type", mapping, animeType, models.MediaTypeAnime},
+		{"mapping ignores genres", mapping, animeGenre, models.MediaTypeSeries},
+		{"mapping not loaded", NewAnimeDetector(AnimeDetectionMapping, nil), mapped, models.MediaTypeSeries},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.detector.mediaType(&tt.show); got != tt.want {
+				t.Errorf("mediaType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package media
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// syncBatchSize is how many titles are stored per transaction; each batch is a checkpoint
+const syncBatchSize = 500
+
+// checkpointMaxAge is how long an interrupted sync can be resumed before it starts over
+const checkpointMaxAge = 24 * time.Hour
+
+// resumeCheckpoint returns the sync time to use for a source and, when an interrupted sync is
+// being resumed, the keys of the titles it already stored. Stored titles keep a synced_at after
+// the original start, so cleanup still treats them as present.
+func (s *SyncService) resumeCheckpoint(ctx context.Context, source models.MediaSource, opts SyncOptions) (time.Time, map[string]bool) {
+	now := time.Now()
+	if opts.DryRun || s.checkpoints == nil {
+		return now, nil
+	}
+
+	cp, err := s.checkpoints.Get(ctx, source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return now, nil
+	}
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to read sync checkpoint", "source", source, "error", err)
+		return now, nil
+	}
+	if now.Sub(cp.UpdatedAt) > checkpointMaxAge {
+		s.logger.InfoContext(ctx, "discarding stale sync checkpoint", "source", source, "updated_at", cp.UpdatedAt)
+		return now, nil
+	}
+
+	media, err := s.mediaRepo.List(ctx, repository.ListMediaOptions{Source: source, SyncedSince: cp.StartedAt})
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to list media stored before interruption", "source", source, "error", err)
+		return now, nil
+	}
+
+	stored := make(map[string]bool, len(media))
+	for i := range media {
+		stored[syncKey(&media[i])] = true
+	}
+
+	s.logger.InfoContext(ctx, "resuming interrupted sync",
+		"source", source,
+		"started_at", cp.StartedAt,
+		"stored", len(stored),
+		"total", cp.Total,
+	)
+	return cp.StartedAt, stored
+}
+
+// storeAll creates or updates the synced media in batches, saving a checkpoint after each one so
+// a cancelled sync can resume, and counts the outcome. Titles in stored were already written by
+// the interrupted sync being resumed and are skipped.
+func (s *SyncService) storeAll(ctx context.Context, source models.MediaSource, syncTime time.Time, media []*models.Media, stored map[string]bool, result *SyncResult) error {
+	todo := media
+	if len(stored) > 0 {
+		todo = make([]*models.Media, 0, len(media))
+		for _, m := range media {
+			if !stored[syncKey(m)] {
+				todo = append(todo, m)
+			}
+		}
+		result.Resumed = len(media) - len(todo)
+		s.progress.resumed(result.Resumed)
+	}
+
+	cp := &models.SyncCheckpoint{Source: source, StartedAt: syncTime, Stored: result.Resumed, Total: len(media)}
+	s.saveCheckpoint(ctx, cp)
+	s.reportProgress(ctx, PhaseStoring, cp.Stored, cp.Total)
+
+	for start := 0; start < len(todo); start += syncBatchSize {
+		batch := todo[start:min(start+syncBatchSize, len(todo))]
+
+		created, err := s.mediaRepo.BulkUpsert(ctx, batch)
+		if err != nil {
+			return err
+		}
+		result.Created += created
+		result.Updated += len(batch) - created
+
+		cp.Stored += len(batch)
+		s.saveCheckpoint(ctx, cp)
+		s.reportProgress(ctx, PhaseStoring, cp.Stored, cp.Total)
+	}
+
+	return nil
+}
+
+// saveCheckpoint records a sync's progress; failures only lose the ability to resume
+func (s *SyncService) saveCheckpoint(ctx context.Context, cp *models.SyncCheckpoint) {
+	if s.checkpoints == nil {
+		return
+	}
+	if err := s.checkpoints.Save(ctx, cp); err != nil {
+		s.logger.WarnContext(ctx, "failed to save sync checkpoint", "source", cp.Source, "error", err)
+	}
+}
+
+// clearCheckpoint removes a source's checkpoint once all of its media is stored
+func (s *SyncService) clearCheckpoint(ctx context.Context, source models.MediaSource) {
+	if s.checkpoints == nil {
+		return
+	}
+	if err := s.checkpoints.Delete(ctx, source); err != nil {
+		s.logger.WarnContext(ctx, "failed to clear sync checkpoint", "source", source, "error", err)
+	}
+}
+
+// reportProgress records progress within a phase and logs it periodically
+func (s *SyncService) reportProgress(ctx context.Context, phase string, done, total int) {
+	p, log := s.progress.update(phase, done, total)
+	if !log {
+		return
+	}
+	s.logger.InfoContext(ctx, "sync progress",
+		"source", p.Source,
+		"phase", p.Phase,
+		"done", done,
+		"total", total,
+		"eta", time.Duration(p.ETASeconds*float64(time.Second)).Round(time.Second),
+	)
+}
+
+// finishProgress marks the current sync as done, failed, or canceled
+func (s *SyncService) finishProgress(ctx context.Context, err error) {
+	p := s.progress.finish(err)
+	if p.Phase == PhaseCanceled {
+		s.logger.WarnContext(ctx, "sync canceled", "source", p.Source, "done", p.Done, "total", p.Total)
+	}
+}
+
+// Progress returns the running or most recent sync's progress, or nil if none has run
+func (s *SyncService) Progress() *Progress {
+	return s.progress.snapshot()
+}
+
+// SubscribeProgress returns a channel of sync progress updates and a function that ends the
+// subscription
+func (s *SyncService) SubscribeProgress() (<-chan Progress, func()) {
+	return s.progress.subscribe()
+}
@@ -0,0 +1,104 @@
+package media
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// SyncChanges lists the titles a dry-run sync would create, change, or delete
+type SyncChanges struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Deleted []string `json:"deleted"`
+}
+
+// planChanges compares the media fetched from Radarr/Sonarr against what is stored for the same
+// source. Stored media that wasn't fetched would be deleted by cleanup.
+func planChanges(stored []models.Media, fetched []*models.Media, cleanup bool) *SyncChanges {
+	changes := &SyncChanges{Created: []string{}, Updated: []string{}, Deleted: []string{}}
+
+	byKey := make(map[string]*models.Media, len(stored))
+	for i := range stored {
+		byKey[syncKey(&stored[i])] = &stored[i]
+	}
+
+	seen := make(map[string]bool, len(fetched))
+	for _, m := range fetched {
+		key := syncKey(m)
+		seen[key] = true
+
+		existing, ok := byKey[key]
+		switch {
+		case !ok:
+			changes.Created = append(changes.Created, changeLabel(m))
+		case mediaChanged(existing, m):
+			changes.Updated = append(changes.Updated, changeLabel(m))
+		}
+	}
+
+	if cleanup {
+		for i := range stored {
+			if !seen[syncKey(&stored[i])] {
+				changes.Deleted = append(changes.Deleted, changeLabel(&stored[i]))
+			}
+		}
+	}
+
+	return changes
+}
+
+// mediaChanged reports whether a sync would change any Radarr/Sonarr-provided field
+func mediaChanged(stored, fetched *models.Media) bool {
+	return stored.Title != fetched.Title ||
+		stored.Year != fetched.Year ||
+		stored.Overview != fetched.Overview ||
+		stored.Runtime != fetched.Runtime ||
+		stored.MediaType != fetched.MediaType ||
+		stored.Path != fetched.Path ||
+		stored.HasFile != fetched.HasFile ||
+		stored.SizeOnDisk != fetched.SizeOnDisk ||
+		stored.Resolution != fetched.Resolution ||
+		stored.Quality != fetched.Quality ||
+		stored.Status != fetched.Status ||
+		stored.Monitored != fetched.Monitored ||
+		stored.IMDBID != fetched.IMDBID ||
+		stored.TMDBID != fetched.TMDBID ||
+		stored.TVDBID != fetched.TVDBID ||
+		stored.CollectionTMDBID != fetched.CollectionTMDBID ||
+		stored.PosterURL != fetched.PosterURL ||
+		stored.FanartURL != fetched.FanartURL ||
+		!slices.Equal(stored.Genres, fetched.Genres) ||
+		!slices.Equal(stored.Tags, fetched.Tags)
+}
+
+// adoptInstance moves stored media of the default instance onto instance, unless instance already
+// has the same key, the way MediaRepository.AdoptDefaultInstance does
+func adoptInstance(stored []models.Media, instance string) {
+	if instance == "" || instance == config.DefaultInstance {
+		return
+	}
+	named := make(map[int64]bool)
+	for i := range stored {
+		if stored[i].SourceInstance == instance {
+			named[stored[i].ExternalID] = true
+		}
+	}
+	for i := range stored {
+		if stored[i].SourceInstance == config.DefaultInstance && !named[stored[i].ExternalID] {
+			stored[i].SourceInstance = instance
+		}
+	}
+}
+
+// syncKey identifies a media record the way upserts do
+func syncKey(m *models.Media) string {
+	return fmt.Sprintf("%s/%s/%d", m.Source, m.SourceInstance, m.ExternalID)
+}
+
+// changeLabel names a title in a dry-run report
+func changeLabel(m *models.Media) string {
+	return fmt.Sprintf("%s (%d) [%s]", m.Title, m.Year, m.SourceInstance)
+}
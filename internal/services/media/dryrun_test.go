@@ -0,0 +1,74 @@
+package media
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestPlanChanges(t *testing.T) {
+	movie := func(id int64, title string, instance string) models.Media {
+		return models.Media{
+			ExternalID:     id,
+			Source:         models.MediaSourceRadarr,
+			SourceInstance: instance,
+			Title:          title,
+			Year:           2000,
+			HasFile:        true,
+			Genres:         models.StringSlice{"Drama"},
+		}
+	}
+
+	stored := []models.Media{
+		movie(1, "Kept", "hd"),
+		movie(2, "Changed", "hd"),
+		movie(3, "Removed", "hd"),
+		movie(1, "Other Instance", "4k"),
+	}
+
+	kept := movie(1, "Kept", "hd")
+	changed := movie(2, "Changed", "hd")
+	changed.HasFile = false
+	added := movie(4, "Added", "hd")
+	fetched := []*models.Media{&kept, &changed, &added}
+
+	tests := []struct {
+		name    string
+		cleanup bool
+		want    SyncChanges
+	}{
+		{
+			name: "without cleanup",
+			want: SyncChanges{
+				Created: []string{"Added (2000) [hd]"},
+				Updated: []string{"Changed (2000) [hd]"},
+				Deleted: []string{},
+			},
+		},
+		{
+			name:    "with cleanup",
+			cleanup: true,
+			want: SyncChanges{
+				Created: []string{"Added (2000) [hd]"},
+				Updated: []string{"Changed (2000) [hd]"},
+				Deleted: []string{"Removed (2000) [hd]", "Other Instance (2000) [4k]"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planChanges(stored, fetched, tt.cleanup)
+			if !slices.Equal(got.Created, tt.want.Created) {
+				t.Errorf("Created = %v, want %v", got.Created, tt.want.Created)
+			}
+			if !slices.Equal(got.Updated, tt.want.Updated) {
+				t.Errorf("Updated = %v, want %v", got.Updated, tt.want.Updated)
+			}
+			if !slices.Equal(got.Deleted, tt.want.Deleted) {
+				t.Errorf("Deleted = %v, want %v", got.Deleted, tt.want.Deleted)
+			}
+		})
+	}
+}
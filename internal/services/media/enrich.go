@@ -0,0 +1,98 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/tmdb"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// enrichmentMaxAge is how long TMDB data is kept before it is refreshed
+const enrichmentMaxAge = 30 * 24 * time.Hour
+
+// enrich fetches TMDB details for media of a source that is new or stale.
+// It returns the number of enriched items and the number of failures.
+func (s *SyncService) enrich(ctx context.Context, source models.MediaSource) (int, int) {
+	pending, err := s.mediaRepo.ListForEnrichment(ctx, source, time.Now().Add(-enrichmentMaxAge))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list media for enrichment", "source", source, "error", err)
+		return 0, 1
+	}
+
+	s.logger.InfoContext(ctx, "enriching media from TMDB", "source", source, "count", len(pending))
+	s.reportProgress(ctx, PhaseEnriching, 0, len(pending))
+
+	enriched, failed := 0, 0
+	for i := range pending {
+		select {
+		case <-ctx.Done():
+			return enriched, failed
+		default:
+		}
+		if i > 0 {
+			s.reportProgress(ctx, PhaseEnriching, i, len(pending))
+		}
+
+		if err := s.enrichOne(ctx, &pending[i]); err != nil {
+			s.logger.WarnContext(ctx, "failed to enrich media",
+				"title", pending[i].Title,
+				"error", err,
+			)
+			failed++
+			continue
+		}
+		enriched++
+	}
+	s.reportProgress(ctx, PhaseEnriching, len(pending), len(pending))
+
+	return enriched, failed
+}
+
+// enrichOne fetches and stores TMDB details for one media item
+func (s *SyncService) enrichOne(ctx context.Context, m *models.Media) error {
+	details, err := s.fetchDetails(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	if details != nil {
+		m.Keywords = models.StringSlice(details.Keywords)
+		m.Certification = details.Certification
+		m.Countries = models.StringSlice(details.Countries)
+		if details.OriginalLanguage != "" {
+			m.OriginalLanguage = details.OriginalLanguage
+		}
+	}
+
+	// Titles TMDB doesn't know are still marked so they aren't retried every sync
+	if err := s.mediaRepo.UpdateEnrichment(ctx, m); err != nil {
+		return fmt.Errorf("failed to store enrichment: %w", err)
+	}
+	return nil
+}
+
+// fetchDetails looks up TMDB details for a media item. It returns nil details when the item has no TMDB match.
+func (s *SyncService) fetchDetails(ctx context.Context, m *models.Media) (*tmdb.Details, error) {
+	if m.MediaType == models.MediaTypeMovie {
+		if m.TMDBID == 0 {
+			return nil, nil
+		}
+		return s.tmdb.GetMovieDetails(ctx, m.TMDBID)
+	}
+
+	tvID := m.TMDBID
+	if tvID == 0 && m.TVDBID > 0 {
+		id, err := s.tmdb.FindTVByTVDB(ctx, m.TVDBID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tmdb id: %w", err)
+		}
+		tvID = id
+	}
+	if tvID == 0 {
+		return nil, nil
+	}
+
+	return s.tmdb.GetTVDetails(ctx, tvID)
+}
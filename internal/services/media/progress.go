@@ -0,0 +1,175 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// Sync phases reported in Progress
+const (
+	PhaseFetching  = "fetching"
+	PhaseStoring   = "storing"
+	PhaseEnriching = "enriching"
+	PhaseDone      = "done"
+	PhaseFailed    = "failed"
+	PhaseCanceled  = "canceled"
+)
+
+// progressLogInterval is how often a running sync logs its progress
+const progressLogInterval = 10 * time.Second
+
+// Progress is a snapshot of the running, or most recent, sync of a source
+type Progress struct {
+	Source     models.MediaSource `json:"source"`
+	Phase      string             `json:"phase"`
+	Done       int                `json:"done"`
+	Total      int                `json:"total"`
+	Resumed    int                `json:"resumed,omitempty"` // items already stored by an interrupted sync
+	ETASeconds float64            `json:"eta_seconds,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	StartedAt  time.Time          `json:"started_at"` // when the sync started
+	PhaseAt    time.Time          `json:"phase_at"`   // when the current phase started
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// Running reports whether the sync is still in progress
+func (p Progress) Running() bool {
+	return p.Phase != PhaseDone && p.Phase != PhaseFailed && p.Phase != PhaseCanceled
+}
+
+// progressTracker keeps the latest progress and fans updates out to subscribers
+type progressTracker struct {
+	mu      sync.Mutex
+	current *Progress
+	lastLog time.Time
+	subs    map[chan Progress]struct{}
+	now     func() time.Time
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{subs: make(map[chan Progress]struct{}), now: time.Now}
+}
+
+// start begins tracking a new sync of a source
+func (t *progressTracker) start(source models.MediaSource) Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.current = &Progress{Source: source, Phase: PhaseFetching, StartedAt: now, PhaseAt: now, UpdatedAt: now}
+	t.lastLog = now
+	t.publish()
+	return *t.current
+}
+
+// update records progress within a phase, estimating the time left from the phase's rate so far.
+// It reports whether the update is worth logging: on a phase change, at the end of a phase, or
+// once progressLogInterval has passed since the last logged update.
+func (t *progressTracker) update(phase string, done, total int) (Progress, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current == nil {
+		return Progress{}, false
+	}
+
+	now := t.now()
+	p := t.current
+	changed := p.Phase != phase
+	if changed {
+		p.Phase = phase
+		p.PhaseAt = now
+	}
+	p.Done, p.Total, p.UpdatedAt = done, total, now
+
+	p.ETASeconds = 0
+	if elapsed := now.Sub(p.PhaseAt); done > 0 && total > done && elapsed > 0 {
+		p.ETASeconds = (elapsed.Seconds() / float64(done)) * float64(total-done)
+	}
+
+	log := changed || done == total || now.Sub(t.lastLog) >= progressLogInterval
+	if log {
+		t.lastLog = now
+	}
+	t.publish()
+	return *p, log
+}
+
+// resumed records how many items an interrupted sync had already stored
+func (t *progressTracker) resumed(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != nil {
+		t.current.Resumed = n
+	}
+}
+
+// finish ends tracking with done, failed, or canceled
+func (t *progressTracker) finish(err error) Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current == nil {
+		return Progress{}
+	}
+
+	p := t.current
+	p.ETASeconds = 0
+	p.UpdatedAt = t.now()
+	switch {
+	case err == nil:
+		p.Phase = PhaseDone
+	case errors.Is(err, context.Canceled):
+		p.Phase = PhaseCanceled
+		p.Error = err.Error()
+	default:
+		p.Phase = PhaseFailed
+		p.Error = err.Error()
+	}
+	t.publish()
+	return *p
+}
+
+// snapshot returns the latest progress, or nil before the first sync
+func (t *progressTracker) snapshot() *Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current == nil {
+		return nil
+	}
+	p := *t.current
+	return &p
+}
+
+// subscribe returns a channel of progress updates and a function that ends the subscription.
+// Slow subscribers miss intermediate updates rather than blocking the sync.
+func (t *progressTracker) subscribe() (<-chan Progress, func()) {
+	ch := make(chan Progress, 16)
+
+	t.mu.Lock()
+	t.subs[ch] = struct{}{}
+	t.mu.Unlock()
+
+	return ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := t.subs[ch]; ok {
+			delete(t.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish sends the current progress to subscribers; t.mu must be held
+func (t *progressTracker) publish() {
+	for ch := range t.subs {
+		select {
+		case ch <- *t.current:
+		default:
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestProgressTrackerUpdate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newProgressTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.start(models.MediaSourceRadarr)
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		phase   string
+		done    int
+		total   int
+		wantETA float64
+		wantLog bool
+	}{
+		{"phase change logs", 0, PhaseStoring, 0, 1000, 0, true},
+		{"quiet within interval", 2 * time.Second, PhaseStoring, 100, 1000, 18, false},
+		{"logs after interval", 8 * time.Second, PhaseStoring, 500, 1000, 10, true},
+		{"logs at end of phase", time.Second, PhaseStoring, 1000, 1000, 0, true},
+		{"new phase restarts the estimate", time.Second, PhaseEnriching, 0, 10, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			p, log := tracker.update(tt.phase, tt.done, tt.total)
+			if log != tt.wantLog {
+				t.Errorf("log = %v, want %v", log, tt.wantLog)
+			}
+			if p.ETASeconds != tt.wantETA {
+				t.Errorf("ETASeconds = %v, want %v", p.ETASeconds, tt.wantETA)
+			}
+			if p.Phase != tt.phase || p.Done != tt.done || p.Total != tt.total {
+				t.Errorf("progress = %s %d/%d, want %s %d/%d", p.Phase, p.Done, p.Total, tt.phase, tt.done, tt.total)
+			}
+		})
+	}
+}
+
+func TestProgressTrackerFinish(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		phase string
+	}{
+		{"success", nil, PhaseDone},
+		{"canceled", fmt.Errorf("failed to store movies: %w", context.Canceled), PhaseCanceled},
+		{"failed", errors.New("radarr instance hd: timeout"), PhaseFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newProgressTracker()
+			tracker.start(models.MediaSourceSonarr)
+
+			p := tracker.finish(tt.err)
+			if p.Phase != tt.phase {
+				t.Errorf("Phase = %q, want %q", p.Phase, tt.phase)
+			}
+			if p.Running() {
+				t.Error("finished sync reported as running")
+			}
+		})
+	}
+}
+
+func TestProgressTrackerSubscribe(t *testing.T) {
+	tracker := newProgressTracker()
+	if tracker.snapshot() != nil {
+		t.Fatal("snapshot before any sync should be nil")
+	}
+
+	events, unsubscribe := tracker.subscribe()
+	tracker.start(models.MediaSourceRadarr)
+	tracker.update(PhaseStoring, 1, 2)
+
+	if p := <-events; p.Phase != PhaseFetching {
+		t.Errorf("first event phase = %q, want %q", p.Phase, PhaseFetching)
+	}
+	if p := <-events; p.Phase != PhaseStoring || p.Done != 1 {
+		t.Errorf("second event = %s %d, want %s 1", p.Phase, p.Done, PhaseStoring)
+	}
+
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("channel still open after unsubscribe")
+	}
+	unsubscribe()
+
+	// Updates after unsubscribing must not block or panic
+	tracker.update(PhaseStoring, 2, 2)
+}
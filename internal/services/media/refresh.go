@@ -0,0 +1,132 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// ErrUnknownInstance is returned when a refresh names an instance that isn't configured
+var ErrUnknownInstance = errors.New("unknown instance")
+
+// RefreshResult describes a single refreshed title
+type RefreshResult struct {
+	Media    *models.Media
+	Created  bool // the title wasn't stored before
+	Enriched bool
+}
+
+// Refresh fetches one movie or series by its Radarr/Sonarr ID and stores it, without a
+// full sync. An empty instance means the first configured instance of the source.
+// A title the instance doesn't have returns an error wrapping the client's ErrNotFound.
+func (s *SyncService) Refresh(ctx context.Context, source models.MediaSource, instance string, externalID int64) (*RefreshResult, error) {
+	var (
+		media *models.Media
+		err   error
+	)
+	switch source {
+	case models.MediaSourceRadarr:
+		media, err = s.fetchMovie(ctx, instance, externalID)
+	case models.MediaSourceSonarr:
+		media, err = s.fetchSeries(ctx, instance, externalID)
+	default:
+		return nil, fmt.Errorf("unsupported source: %s", source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	media.SyncedAt = time.Now()
+	created, err := s.mediaRepo.BulkUpsert(ctx, []*models.Media{media})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+	result := &RefreshResult{Media: media, Created: created > 0}
+
+	if s.tmdb != nil {
+		if err := s.enrichOne(ctx, media); err != nil {
+			s.logger.WarnContext(ctx, "failed to enrich media", "title", media.Title, "error", err)
+		} else {
+			result.Enriched = true
+		}
+	}
+
+	s.logger.InfoContext(ctx, "media refreshed",
+		"source", source,
+		"instance", media.SourceInstance,
+		"external_id", externalID,
+		"title", media.Title,
+		"created", result.Created,
+	)
+	return result, nil
+}
+
+// fetchMovie loads one movie from a Radarr instance and records its collection
+func (s *SyncService) fetchMovie(ctx context.Context, instance string, id int64) (*models.Media, error) {
+	for _, client := range s.radarr {
+		if instance != "" && client.Name() != instance {
+			continue
+		}
+
+		movie, err := client.GetMovie(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("radarr instance %s: %w", client.Name(), err)
+		}
+		radarrTags, err := client.GetTags(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("radarr instance %s: %w", client.Name(), err)
+		}
+		tags := make(map[int64]string, len(radarrTags))
+		for _, t := range radarrTags {
+			tags[t.ID] = t.Label
+		}
+
+		media := movie.ToMedia()
+		media.SourceInstance = client.Name()
+		media.Tags = tagLabels(movie.Tags, tags)
+
+		if c := movie.Collection; c != nil && c.TMDBID > 0 {
+			if err := s.collectionRepo.Upsert(ctx, &models.Collection{TMDBID: c.TMDBID, Title: c.DisplayTitle()}); err != nil {
+				s.logger.ErrorContext(ctx, "failed to store collection", "title", c.DisplayTitle(), "error", err)
+			}
+		}
+		return media, nil
+	}
+	return nil, fmt.Errorf("radarr instance %q: %w", instance, ErrUnknownInstance)
+}
+
+// fetchSeries loads one series from a Sonarr instance
+func (s *SyncService) fetchSeries(ctx context.Context, instance string, id int64) (*models.Media, error) {
+	for _, client := range s.sonarr {
+		if instance != "" && client.Name() != instance {
+			continue
+		}
+
+		show, err := client.GetSeriesByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("sonarr instance %s: %w", client.Name(), err)
+		}
+		sonarrTags, err := client.GetTags(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("sonarr instance %s: %w", client.Name(), err)
+		}
+		tags := make(map[int64]string, len(sonarrTags))
+		for _, t := range sonarrTags {
+			tags[t.ID] = t.Label
+		}
+
+		if err := s.anime.prepare(ctx); err != nil {
+			s.logger.WarnContext(ctx, "anime detection falls back to Sonarr series types", "error", err)
+		}
+
+		media := show.ToMedia()
+		media.MediaType = s.anime.mediaType(show)
+		media.SourceInstance = client.Name()
+		media.Tags = tagLabels(show.Tags, tags)
+		return media, nil
+	}
+	return nil, fmt.Errorf("sonarr instance %q: %w", instance, ErrUnknownInstance)
+}
@@ -3,121 +3,208 @@ package media
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/geekxflood/program-director/internal/clients/radarr"
 	"github.com/geekxflood/program-director/internal/clients/sonarr"
+	"github.com/geekxflood/program-director/internal/clients/tmdb"
 	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/metrics"
 	"github.com/geekxflood/program-director/pkg/models"
 )
 
 // SyncService handles media synchronization from Radarr/Sonarr
 type SyncService struct {
-	radarr    *radarr.Client
-	sonarr    *sonarr.Client
-	mediaRepo *repository.MediaRepository
-	logger    *slog.Logger
+	radarr         []*radarr.Client
+	sonarr         []*sonarr.Client
+	tmdb           *tmdb.Client
+	anime          *AnimeDetector
+	mediaRepo      *repository.MediaRepository
+	collectionRepo *repository.CollectionRepository
+	checkpoints    *repository.SyncCheckpointRepository
+	progress       *progressTracker
+	logger         *slog.Logger
 }
 
-// NewSyncService creates a new SyncService
+// NewSyncService creates a new SyncService. Instances are synced in order, and a title
+// present in several instances is stored once, from the first instance that has it.
+// Progress is checkpointed to checkpointRepo so an interrupted sync can resume, and anime
+// decides which series are stored as anime.
 func NewSyncService(
-	radarrClient *radarr.Client,
-	sonarrClient *sonarr.Client,
+	radarrClients []*radarr.Client,
+	sonarrClients []*sonarr.Client,
+	tmdbClient *tmdb.Client,
+	anime *AnimeDetector,
 	mediaRepo *repository.MediaRepository,
+	collectionRepo *repository.CollectionRepository,
+	checkpointRepo *repository.SyncCheckpointRepository,
 	logger *slog.Logger,
 ) *SyncService {
 	return &SyncService{
-		radarr:    radarrClient,
-		sonarr:    sonarrClient,
-		mediaRepo: mediaRepo,
-		logger:    logger,
+		radarr:         radarrClients,
+		sonarr:         sonarrClients,
+		tmdb:           tmdbClient,
+		anime:          anime,
+		mediaRepo:      mediaRepo,
+		collectionRepo: collectionRepo,
+		checkpoints:    checkpointRepo,
+		progress:       newProgressTracker(),
+		logger:         logger,
 	}
 }
 
 // SyncResult contains the results of a sync operation
 type SyncResult struct {
-	Source   models.MediaSource
-	Created  int
-	Updated  int
-	Deleted  int
-	Errors   int
-	Duration time.Duration
+	Source     models.MediaSource
+	Created    int
+	Updated    int
+	Deleted    int
+	Duplicates int // titles skipped because an earlier instance already has them
+	Resumed    int // titles already stored by an interrupted sync that this one resumed
+	Enriched   int
+	Errors     int
+	Duration   time.Duration
+
+	// Changes lists affected titles on dry runs, where Updated counts only changed titles
+	Changes *SyncChanges
+}
+
+// SyncOptions controls a sync run
+type SyncOptions struct {
+	// Cleanup removes media that is no longer in any instance
+	Cleanup bool
+	// DryRun reports what would be created, updated, and deleted without writing anything
+	DryRun bool
+}
+
+// SyncMovies synchronizes movies from all Radarr instances
+func (s *SyncService) SyncMovies(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
+	start := time.Now()
+	s.progress.start(models.MediaSourceRadarr)
+	result, err := s.syncMovies(ctx, opts)
+	s.finishProgress(ctx, err)
+	observeSync(models.MediaSourceRadarr, opts, result, err, time.Since(start))
+	return result, err
 }
 
-// SyncMovies synchronizes movies from Radarr
-func (s *SyncService) SyncMovies(ctx context.Context, cleanup bool) (*SyncResult, error) {
+func (s *SyncService) syncMovies(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
 	start := time.Now()
 	result := &SyncResult{
 		Source: models.MediaSourceRadarr,
 	}
 
-	s.logger.Info("starting movie sync")
+	s.logger.InfoContext(ctx, "starting movie sync", "instances", len(s.radarr), "dry_run", opts.DryRun)
 
-	// Fetch all movies from Radarr
-	movies, err := s.radarr.GetMovies(ctx)
-	if err != nil {
-		return nil, err
-	}
+	syncTime, stored := s.resumeCheckpoint(ctx, models.MediaSourceRadarr, opts)
+	seen := make(map[string]bool)
+	collections := make(map[int64]bool)
+	var pending []*models.Media
+
+	for i, client := range s.radarr {
+		radarrTags, err := client.GetTags(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("radarr instance %s: %w", client.Name(), err)
+		}
+		tags := make(map[int64]string, len(radarrTags))
+		for _, t := range radarrTags {
+			tags[t.ID] = t.Label
+		}
 
-	s.logger.Info("fetched movies from Radarr", "count", len(movies))
+		// Turn movies into media a page at a time, so the full Radarr payloads of a large
+		// library are never held at once
+		fetched := 0
+		err = client.EachMoviePage(ctx, radarr.MoviePageSize, func(movies []radarr.Movie) error {
+			fetched += len(movies)
+			for _, movie := range movies {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
 
-	syncTime := time.Now()
+				media := movie.ToMedia()
+				media.SourceInstance = client.Name()
+				media.Tags = tagLabels(movie.Tags, tags)
+				media.SyncedAt = syncTime
 
-	for _, movie := range movies {
-		select {
-		case <-ctx.Done():
-			return result, ctx.Err()
-		default:
-		}
+				if key := dedupeKey(media); key != "" {
+					if seen[key] {
+						result.Duplicates++
+						continue
+					}
+					seen[key] = true
+				}
 
-		media := movie.ToMedia()
-		media.SyncedAt = syncTime
+				pending = append(pending, media)
 
-		// Check if exists
-		existing, err := s.mediaRepo.GetByExternalID(ctx, media.ExternalID, media.Source)
-		if err != nil {
-			// Doesn't exist, create
-			if err := s.mediaRepo.Upsert(ctx, media); err != nil {
-				s.logger.Error("failed to create movie",
-					"title", media.Title,
-					"error", err,
-				)
-				result.Errors++
-				continue
-			}
-			result.Created++
-		} else {
-			// Exists, update
-			media.ID = existing.ID
-			media.CreatedAt = existing.CreatedAt
-			if err := s.mediaRepo.Upsert(ctx, media); err != nil {
-				s.logger.Error("failed to update movie",
-					"title", media.Title,
-					"error", err,
-				)
-				result.Errors++
-				continue
+				// Record each collection once per sync
+				if c := movie.Collection; c != nil && c.TMDBID > 0 && !collections[c.TMDBID] && !opts.DryRun {
+					collections[c.TMDBID] = true
+					if err := s.collectionRepo.Upsert(ctx, &models.Collection{TMDBID: c.TMDBID, Title: c.DisplayTitle()}); err != nil {
+						s.logger.ErrorContext(ctx, "failed to store collection", "title", c.DisplayTitle(), "error", err)
+						result.Errors++
+					}
+				}
 			}
-			result.Updated++
+			return nil
+		})
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return result, ctxErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("radarr instance %s: %w", client.Name(), err)
 		}
+
+		s.logger.InfoContext(ctx, "fetched movies from Radarr", "instance", client.Name(), "count", fetched)
+		s.reportProgress(ctx, PhaseFetching, i+1, len(s.radarr))
 	}
 
-	// Cleanup stale entries
-	if cleanup {
+	firstInstance := ""
+	if len(s.radarr) > 0 {
+		firstInstance = s.radarr[0].Name()
+	}
+
+	if opts.DryRun {
+		if err := s.plan(ctx, models.MediaSourceRadarr, firstInstance, pending, opts.Cleanup, result); err != nil {
+			return nil, fmt.Errorf("failed to plan movie sync: %w", err)
+		}
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	if err := s.adoptDefaultInstance(ctx, models.MediaSourceRadarr, firstInstance); err != nil {
+		return result, err
+	}
+	if err := s.storeAll(ctx, models.MediaSourceRadarr, syncTime, pending, stored, result); err != nil {
+		return result, fmt.Errorf("failed to store movies: %w", err)
+	}
+
+	// Cleanup stale entries, including duplicates now owned by an earlier instance
+	if opts.Cleanup {
 		deleted, err := s.mediaRepo.DeleteStale(ctx, models.MediaSourceRadarr, syncTime.Add(-time.Minute))
 		if err != nil {
-			s.logger.Error("failed to cleanup stale movies", "error", err)
+			s.logger.ErrorContext(ctx, "failed to cleanup stale movies", "error", err)
 		} else {
 			result.Deleted = int(deleted)
 		}
 	}
+	s.clearCheckpoint(ctx, models.MediaSourceRadarr)
+
+	// Fill in keywords, certification, and language from TMDB
+	if s.tmdb != nil {
+		enriched, failed := s.enrich(ctx, models.MediaSourceRadarr)
+		result.Enriched = enriched
+		result.Errors += failed
+	}
 
 	result.Duration = time.Since(start)
-	s.logger.Info("movie sync complete",
+	s.logger.InfoContext(ctx, "movie sync complete",
 		"created", result.Created,
 		"updated", result.Updated,
 		"deleted", result.Deleted,
+		"duplicates", result.Duplicates,
+		"enriched", result.Enriched,
 		"errors", result.Errors,
 		"duration", result.Duration,
 	)
@@ -125,82 +212,203 @@ func (s *SyncService) SyncMovies(ctx context.Context, cleanup bool) (*SyncResult
 	return result, nil
 }
 
-// SyncSeries synchronizes series from Sonarr
-func (s *SyncService) SyncSeries(ctx context.Context, cleanup bool) (*SyncResult, error) {
+// SyncSeries synchronizes series from all Sonarr instances
+func (s *SyncService) SyncSeries(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
+	start := time.Now()
+	s.progress.start(models.MediaSourceSonarr)
+	result, err := s.syncSeries(ctx, opts)
+	s.finishProgress(ctx, err)
+	observeSync(models.MediaSourceSonarr, opts, result, err, time.Since(start))
+	return result, err
+}
+
+func (s *SyncService) syncSeries(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
 	start := time.Now()
 	result := &SyncResult{
 		Source: models.MediaSourceSonarr,
 	}
 
-	s.logger.Info("starting series sync")
-
-	// Fetch all series from Sonarr
-	series, err := s.sonarr.GetSeries(ctx)
-	if err != nil {
-		return nil, err
-	}
+	s.logger.InfoContext(ctx, "starting series sync", "instances", len(s.sonarr), "dry_run", opts.DryRun)
 
-	s.logger.Info("fetched series from Sonarr", "count", len(series))
+	syncTime, stored := s.resumeCheckpoint(ctx, models.MediaSourceSonarr, opts)
+	seen := make(map[string]bool)
+	var pending []*models.Media
 
-	syncTime := time.Now()
+	if err := s.anime.prepare(ctx); err != nil {
+		s.logger.WarnContext(ctx, "anime detection falls back to Sonarr series types", "error", err)
+	}
 
-	for _, show := range series {
-		select {
-		case <-ctx.Done():
-			return result, ctx.Err()
-		default:
+	for i, client := range s.sonarr {
+		// Fetch all series from this Sonarr instance
+		series, err := client.GetSeries(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("sonarr instance %s: %w", client.Name(), err)
 		}
 
-		media := show.ToMedia()
-		media.SyncedAt = syncTime
+		s.logger.InfoContext(ctx, "fetched series from Sonarr", "instance", client.Name(), "count", len(series))
+		s.reportProgress(ctx, PhaseFetching, i+1, len(s.sonarr))
 
-		// Check if exists
-		existing, err := s.mediaRepo.GetByExternalID(ctx, media.ExternalID, media.Source)
+		sonarrTags, err := client.GetTags(ctx)
 		if err != nil {
-			// Doesn't exist, create
-			if err := s.mediaRepo.Upsert(ctx, media); err != nil {
-				s.logger.Error("failed to create series",
-					"title", media.Title,
-					"error", err,
-				)
-				result.Errors++
-				continue
+			return nil, fmt.Errorf("sonarr instance %s: %w", client.Name(), err)
+		}
+		tags := make(map[int64]string, len(sonarrTags))
+		for _, t := range sonarrTags {
+			tags[t.ID] = t.Label
+		}
+
+		for _, show := range series {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			default:
 			}
-			result.Created++
-		} else {
-			// Exists, update
-			media.ID = existing.ID
-			media.CreatedAt = existing.CreatedAt
-			if err := s.mediaRepo.Upsert(ctx, media); err != nil {
-				s.logger.Error("failed to update series",
-					"title", media.Title,
-					"error", err,
-				)
-				result.Errors++
-				continue
+
+			media := show.ToMedia()
+			media.MediaType = s.anime.mediaType(&show)
+			media.SourceInstance = client.Name()
+			media.Tags = tagLabels(show.Tags, tags)
+			media.SyncedAt = syncTime
+
+			if key := dedupeKey(media); key != "" {
+				if seen[key] {
+					result.Duplicates++
+					continue
+				}
+				seen[key] = true
 			}
-			result.Updated++
+
+			pending = append(pending, media)
+		}
+	}
+
+	firstInstance := ""
+	if len(s.sonarr) > 0 {
+		firstInstance = s.sonarr[0].Name()
+	}
+
+	if opts.DryRun {
+		if err := s.plan(ctx, models.MediaSourceSonarr, firstInstance, pending, opts.Cleanup, result); err != nil {
+			return nil, fmt.Errorf("failed to plan series sync: %w", err)
 		}
+		result.Duration = time.Since(start)
+		return result, nil
 	}
 
-	// Cleanup stale entries
-	if cleanup {
+	if err := s.adoptDefaultInstance(ctx, models.MediaSourceSonarr, firstInstance); err != nil {
+		return result, err
+	}
+	if err := s.storeAll(ctx, models.MediaSourceSonarr, syncTime, pending, stored, result); err != nil {
+		return result, fmt.Errorf("failed to store series: %w", err)
+	}
+
+	// Cleanup stale entries, including duplicates now owned by an earlier instance
+	if opts.Cleanup {
 		deleted, err := s.mediaRepo.DeleteStale(ctx, models.MediaSourceSonarr, syncTime.Add(-time.Minute))
 		if err != nil {
-			s.logger.Error("failed to cleanup stale series", "error", err)
+			s.logger.ErrorContext(ctx, "failed to cleanup stale series", "error", err)
 		} else {
 			result.Deleted = int(deleted)
 		}
 	}
+	s.clearCheckpoint(ctx, models.MediaSourceSonarr)
+
+	// Fill in keywords, certification, and language from TMDB
+	if s.tmdb != nil {
+		enriched, failed := s.enrich(ctx, models.MediaSourceSonarr)
+		result.Enriched = enriched
+		result.Errors += failed
+	}
 
 	result.Duration = time.Since(start)
-	s.logger.Info("series sync complete",
+	s.logger.InfoContext(ctx, "series sync complete",
 		"created", result.Created,
 		"updated", result.Updated,
 		"deleted", result.Deleted,
+		"duplicates", result.Duplicates,
+		"enriched", result.Enriched,
 		"errors", result.Errors,
 		"duration", result.Duration,
 	)
 
 	return result, nil
 }
+
+// observeSync records a sync's duration and, unless it was a dry run, its item counts
+func observeSync(source models.MediaSource, opts SyncOptions, result *SyncResult, err error, elapsed time.Duration) {
+	metrics.SyncDuration.WithLabelValues(string(source), metrics.Status(err)).Observe(elapsed.Seconds())
+	if result == nil || opts.DryRun {
+		return
+	}
+	for label, n := range map[string]int{
+		"created": result.Created,
+		"updated": result.Updated,
+		"deleted": result.Deleted,
+		"errors":  result.Errors,
+	} {
+		metrics.SyncItems.WithLabelValues(string(source), label).Add(float64(n))
+	}
+}
+
+// adoptDefaultInstance moves media stored before instances were named onto the first configured
+// instance. Without it, upgrading stores every title again under a new ID, and cleanup then
+// deletes the old rows along with their play history and cooldowns.
+func (s *SyncService) adoptDefaultInstance(ctx context.Context, source models.MediaSource, instance string) error {
+	moved, err := s.mediaRepo.AdoptDefaultInstance(ctx, source, instance)
+	if err != nil {
+		return fmt.Errorf("failed to move %s media to instance %s: %w", source, instance, err)
+	}
+	if moved > 0 {
+		s.logger.InfoContext(ctx, "moved media synced before instances were named", "source", source, "instance", instance, "count", moved)
+	}
+	return nil
+}
+
+// plan fills in a dry run's result by comparing the fetched media against the stored media, as
+// stored after adoptDefaultInstance hands unnamed media to firstInstance
+func (s *SyncService) plan(ctx context.Context, source models.MediaSource, firstInstance string, media []*models.Media, cleanup bool, result *SyncResult) error {
+	stored, err := s.mediaRepo.List(ctx, repository.ListMediaOptions{Source: source})
+	if err != nil {
+		return err
+	}
+	adoptInstance(stored, firstInstance)
+
+	result.Changes = planChanges(stored, media, cleanup)
+	result.Created = len(result.Changes.Created)
+	result.Updated = len(result.Changes.Updated)
+	result.Deleted = len(result.Changes.Deleted)
+
+	s.logger.InfoContext(ctx, "sync dry run complete",
+		"source", source,
+		"would_create", result.Created,
+		"would_update", result.Updated,
+		"would_delete", result.Deleted,
+		"unchanged", len(media)-result.Created-result.Updated,
+	)
+	return nil
+}
+
+// dedupeKey identifies the same title across instances by its provider ID,
+// TMDB for movies and TVDB for series. It is empty when no ID is known.
+func dedupeKey(m *models.Media) string {
+	switch {
+	case m.Source == models.MediaSourceRadarr && m.TMDBID > 0:
+		return fmt.Sprintf("tmdb:%d", m.TMDBID)
+	case m.Source == models.MediaSourceSonarr && m.TVDBID > 0:
+		return fmt.Sprintf("tvdb:%d", m.TVDBID)
+	case m.IMDBID != "":
+		return "imdb:" + m.IMDBID
+	}
+	return ""
+}
+
+// tagLabels resolves tag IDs to their labels, skipping unknown IDs
+func tagLabels(ids []int64, labels map[int64]string) models.StringSlice {
+	out := make(models.StringSlice, 0, len(ids))
+	for _, id := range ids {
+		if label, ok := labels[id]; ok {
+			out = append(out, label)
+		}
+	}
+	return out
+}
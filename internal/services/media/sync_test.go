@@ -0,0 +1,127 @@
+package media
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/clients/radarr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// newTestDB returns a migrated SQLite database in a temporary directory
+func newTestDB(t *testing.T) database.DB {
+	t.Helper()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := database.New(ctx, &config.DatabaseConfig{Driver: "sqlite", SQLite: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")}}, logger)
+	if err != nil {
+		t.Fatalf("database.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return db
+}
+
+// newRadarrServer serves movies as the whole Radarr library, with no tags
+func newRadarrServer(t *testing.T, movies string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/movie":
+			_, _ = w.Write([]byte(movies))
+		case "/api/v3/tag":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSyncMoviesAdoptsMediaFromBeforeNamedInstances(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	// A catalog synced before instances were named, with play history on its only title
+	mediaRepo := repository.NewMediaRepository(db)
+	heat := &models.Media{ExternalID: 1, Source: models.MediaSourceRadarr, MediaType: models.MediaTypeMovie, Title: "Heat", Year: 1995, TMDBID: 949, HasFile: true}
+	if err := mediaRepo.Upsert(ctx, heat); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if _, err := db.Exec(ctx, "INSERT INTO play_history (media_id, channel_id, theme_name, media_title, media_type) VALUES ($1, 'ch-1', 'crime', 'Heat', 'movie')", heat.ID); err != nil {
+		t.Fatalf("failed to seed play history: %v", err)
+	}
+
+	server := newRadarrServer(t, `[{"id": 1, "title": "Heat", "year": 1995, "tmdbId": 949, "hasFile": true}]`)
+	client := radarr.New(&config.RadarrConfig{Name: "hd", URL: server.URL, APIKey: "key"})
+	svc := NewSyncService([]*radarr.Client{client}, nil, nil, nil, mediaRepo, repository.NewCollectionRepository(db), nil,
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	plan, err := svc.SyncMovies(ctx, SyncOptions{Cleanup: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("SyncMovies() dry run error = %v", err)
+	}
+	if plan.Created != 0 || plan.Deleted != 0 {
+		t.Errorf("dry run would create %d and delete %d movies, want 0 and 0", plan.Created, plan.Deleted)
+	}
+
+	result, err := svc.SyncMovies(ctx, SyncOptions{Cleanup: true})
+	if err != nil {
+		t.Fatalf("SyncMovies() error = %v", err)
+	}
+	if result.Created != 0 || result.Updated != 1 || result.Deleted != 0 {
+		t.Errorf("sync created %d, updated %d, deleted %d, want 0, 1, 0", result.Created, result.Updated, result.Deleted)
+	}
+
+	media, err := mediaRepo.GetByExternalID(ctx, 1, "radarr", "hd")
+	if err != nil {
+		t.Fatalf("GetByExternalID() error = %v", err)
+	}
+	if media.ID != heat.ID {
+		t.Errorf("media ID = %d, want the original %d", media.ID, heat.ID)
+	}
+
+	var plays int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM play_history WHERE media_id = $1", heat.ID).Scan(&plays); err != nil || plays != 1 {
+		t.Errorf("play history after sync = %d (%v), want 1", plays, err)
+	}
+}
+
+func TestSyncMoviesStoresArtwork(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	mediaRepo := repository.NewMediaRepository(db)
+
+	server := newRadarrServer(t, `[{"id": 1, "title": "Heat", "year": 1995, "tmdbId": 949, "hasFile": true, "images": [
+		{"coverType": "poster", "url": "/MediaCover/1/poster.jpg", "remoteUrl": "https://image.tmdb.org/heat.jpg"},
+		{"coverType": "fanart", "url": "/MediaCover/1/fanart.jpg"}
+	]}]`)
+	client := radarr.New(&config.RadarrConfig{Name: "hd", URL: server.URL, APIKey: "key"})
+	svc := NewSyncService([]*radarr.Client{client}, nil, nil, nil, mediaRepo, repository.NewCollectionRepository(db), nil,
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if _, err := svc.SyncMovies(ctx, SyncOptions{}); err != nil {
+		t.Fatalf("SyncMovies() error = %v", err)
+	}
+
+	media, err := mediaRepo.GetByExternalID(ctx, 1, "radarr", "hd")
+	if err != nil {
+		t.Fatalf("GetByExternalID() error = %v", err)
+	}
+	// The remote URL is preferred, falling back to the path on the Radarr server
+	if media.PosterURL != "https://image.tmdb.org/heat.jpg" || media.FanartURL != "/MediaCover/1/fanart.jpg" {
+		t.Errorf("artwork = %q, %q, want the remote poster and the local fanart", media.PosterURL, media.FanartURL)
+	}
+}
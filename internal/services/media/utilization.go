@@ -0,0 +1,125 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// noGenre groups never-scheduled media without genres
+const noGenre = "(none)"
+
+// UtilizationReport lists the media with files that has never been scheduled on a channel,
+// grouped by genre, to show which themes would put dead inventory to use
+type UtilizationReport struct {
+	Available   int64              `json:"available"`   // Media with files
+	Unscheduled int                `json:"unscheduled"` // Of which never scheduled
+	Genres      []GenreUtilization `json:"genres"`      // Most never-scheduled titles first
+}
+
+// GenreUtilization is one genre of a utilization report. Titles in several genres are
+// listed under each of them.
+type GenreUtilization struct {
+	Genre       string             `json:"genre"`
+	Available   int64              `json:"available"`
+	Unscheduled int                `json:"unscheduled"`
+	Titles      []UnscheduledTitle `json:"titles"` // Best rated first, up to the report's limit
+}
+
+// UnscheduledTitle is a never-scheduled title of a utilization report
+type UnscheduledTitle struct {
+	ID         int64            `json:"id"`
+	Title      string           `json:"title"`
+	Year       int              `json:"year"`
+	MediaType  models.MediaType `json:"media_type"`
+	IMDBRating float64          `json:"imdb_rating"`
+}
+
+// Utilization builds the utilization report for the given media types (all when empty),
+// listing up to titles never-scheduled titles per genre (all when 0)
+func Utilization(ctx context.Context, repo *repository.MediaRepository, mediaTypes []models.MediaType, titles int) (*UtilizationReport, error) {
+	hasFile := true
+	var available int64
+	if len(mediaTypes) == 0 {
+		count, err := repo.Count(ctx, repository.ListMediaOptions{HasFile: &hasFile})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count media: %w", err)
+		}
+		available = count
+	}
+	for _, mt := range mediaTypes {
+		count, err := repo.Count(ctx, repository.ListMediaOptions{MediaType: mt, HasFile: &hasFile})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count media: %w", err)
+		}
+		available += count
+	}
+
+	genreCounts, err := repo.CountByGenre(ctx, mediaTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count genres: %w", err)
+	}
+	unscheduled, err := repo.ListUnplayed(ctx, mediaTypes, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unscheduled media: %w", err)
+	}
+
+	report := buildUtilization(genreCounts, unscheduled, titles)
+	report.Available = available
+	return report, nil
+}
+
+// buildUtilization groups never-scheduled media, best rated first, by genre. genreCounts
+// holds the available media per lowercase genre.
+func buildUtilization(genreCounts map[string]int64, unscheduled []models.Media, titles int) *UtilizationReport {
+	byGenre := make(map[string]*GenreUtilization)
+	genre := func(name string) *GenreUtilization {
+		key := strings.ToLower(name)
+		g, ok := byGenre[key]
+		if !ok {
+			g = &GenreUtilization{Genre: name, Available: genreCounts[key], Titles: []UnscheduledTitle{}}
+			byGenre[key] = g
+		}
+		return g
+	}
+
+	for _, m := range unscheduled {
+		names := m.Genres
+		if len(names) == 0 {
+			names = []string{noGenre}
+		}
+		for _, name := range names {
+			g := genre(name)
+			g.Unscheduled++
+			if titles == 0 || len(g.Titles) < titles {
+				g.Titles = append(g.Titles, UnscheduledTitle{
+					ID:         m.ID,
+					Title:      m.Title,
+					Year:       m.Year,
+					MediaType:  m.MediaType,
+					IMDBRating: m.IMDBRating,
+				})
+			}
+		}
+	}
+
+	report := &UtilizationReport{
+		Unscheduled: len(unscheduled),
+		Genres:      make([]GenreUtilization, 0, len(byGenre)),
+	}
+	for _, g := range byGenre {
+		report.Genres = append(report.Genres, *g)
+	}
+	sort.Slice(report.Genres, func(i, j int) bool {
+		a, b := report.Genres[i], report.Genres[j]
+		if a.Unscheduled != b.Unscheduled {
+			return a.Unscheduled > b.Unscheduled
+		}
+		return a.Genre < b.Genre
+	})
+	return report
+}
@@ -0,0 +1,75 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestBuildUtilization(t *testing.T) {
+	media := func(id int64, title string, genres ...string) models.Media {
+		return models.Media{ID: id, Title: title, MediaType: models.MediaTypeMovie, Genres: genres}
+	}
+	// Best rated first, as ListUnplayed returns them
+	unscheduled := []models.Media{
+		media(1, "Alien", "Horror", "Science Fiction"),
+		media(2, "Solaris", "Science Fiction", "Drama"),
+		media(3, "Stalker", "Science Fiction"),
+		media(4, "Untagged"),
+	}
+	counts := map[string]int64{"horror": 5, "science fiction": 10, "drama": 2}
+
+	tests := []struct {
+		name   string
+		titles int
+		want   []GenreUtilization
+	}{
+		{
+			name:   "all titles",
+			titles: 0,
+			want: []GenreUtilization{
+				{Genre: "Science Fiction", Available: 10, Unscheduled: 3, Titles: []UnscheduledTitle{{ID: 1}, {ID: 2}, {ID: 3}}},
+				{Genre: "(none)", Available: 0, Unscheduled: 1, Titles: []UnscheduledTitle{{ID: 4}}},
+				{Genre: "Drama", Available: 2, Unscheduled: 1, Titles: []UnscheduledTitle{{ID: 2}}},
+				{Genre: "Horror", Available: 5, Unscheduled: 1, Titles: []UnscheduledTitle{{ID: 1}}},
+			},
+		},
+		{
+			name:   "limited titles",
+			titles: 1,
+			want: []GenreUtilization{
+				{Genre: "Science Fiction", Available: 10, Unscheduled: 3, Titles: []UnscheduledTitle{{ID: 1}}},
+				{Genre: "(none)", Available: 0, Unscheduled: 1, Titles: []UnscheduledTitle{{ID: 4}}},
+				{Genre: "Drama", Available: 2, Unscheduled: 1, Titles: []UnscheduledTitle{{ID: 2}}},
+				{Genre: "Horror", Available: 5, Unscheduled: 1, Titles: []UnscheduledTitle{{ID: 1}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := buildUtilization(counts, unscheduled, tt.titles)
+			if report.Unscheduled != len(unscheduled) {
+				t.Errorf("Unscheduled = %d, want %d", report.Unscheduled, len(unscheduled))
+			}
+			if len(report.Genres) != len(tt.want) {
+				t.Fatalf("got %d genres, want %d: %+v", len(report.Genres), len(tt.want), report.Genres)
+			}
+			for i, want := range tt.want {
+				got := report.Genres[i]
+				if got.Genre != want.Genre || got.Available != want.Available || got.Unscheduled != want.Unscheduled {
+					t.Errorf("genre %d = %s (%d available, %d unscheduled), want %s (%d, %d)",
+						i, got.Genre, got.Available, got.Unscheduled, want.Genre, want.Available, want.Unscheduled)
+				}
+				if len(got.Titles) != len(want.Titles) {
+					t.Fatalf("%s: got %d titles, want %d", got.Genre, len(got.Titles), len(want.Titles))
+				}
+				for j := range want.Titles {
+					if got.Titles[j].ID != want.Titles[j].ID {
+						t.Errorf("%s title %d = %d, want %d", got.Genre, j, got.Titles[j].ID, want.Titles[j].ID)
+					}
+				}
+			}
+		})
+	}
+}
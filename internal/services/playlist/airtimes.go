@@ -0,0 +1,52 @@
+package playlist
+
+import (
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// airTimes returns when each title of lineup next airs at or after from, in lineup order,
+// for a channel looping the lineup since start. A title airing at from keeps its current
+// airing. A zero start, or one after from, has the lineup start at from.
+func airTimes(lineup []slot, start, from time.Time) []time.Time {
+	var total time.Duration
+	for _, s := range lineup {
+		total += s.length()
+	}
+
+	cycle := from
+	if !start.IsZero() && !start.After(from) && total > 0 {
+		cycle = start.Add(from.Sub(start) / total * total)
+	}
+
+	times := make([]time.Time, 0, len(lineup))
+	offset := time.Duration(0)
+	for _, s := range lineup {
+		length := s.length()
+		if s.item != nil {
+			airs := cycle.Add(offset)
+			if !airs.Add(length).After(from) {
+				airs = airs.Add(total)
+			}
+			times = append(times, airs)
+		}
+		offset += length
+	}
+	return times
+}
+
+// length returns how long a slot airs for
+func (s slot) length() time.Duration {
+	if s.item == nil {
+		return s.flex
+	}
+	return runtime(&s.item.Media)
+}
+
+// setAirTimes sets the air times of the titles of a lineup, items being its titles in order
+func setAirTimes(items []models.MediaWithScore, lineup []slot, start, from time.Time) {
+	for i, airs := range airTimes(lineup, start, from) {
+		items[i].AirsAt = &airs
+	}
+}
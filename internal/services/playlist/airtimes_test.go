@@ -0,0 +1,63 @@
+package playlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestAirTimes(t *testing.T) {
+	items := []models.MediaWithScore{
+		{Media: models.Media{Title: "Alien", Runtime: 120}},
+		{Media: models.Media{Title: "Aliens", Runtime: 90}},
+	}
+	// Alien 0:00-2:00, flex 2:00-2:30, Aliens 2:30-4:00; the lineup loops every 4 hours
+	lineup := []slot{{item: &items[0]}, {flex: 30 * time.Minute}, {item: &items[1]}}
+	start := time.Date(2025, 11, 1, 20, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return time.Date(2025, 11, 2, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		start time.Time
+		from  time.Time
+		want  []time.Time
+	}{
+		{"lineup starts when applied", time.Time{}, at(21, 0), []time.Time{at(21, 0), at(23, 30)}},
+		{"channel start in the future", at(23, 0), at(21, 0), []time.Time{at(21, 0), at(23, 30)}},
+		// 5 loops since the channel start, the sixth starts at 16:00
+		{"mid loop", start, at(17, 0), []time.Time{at(16, 0), at(18, 30)}},
+		{"first title done this loop", start, at(18, 0), []time.Time{at(20, 0), at(18, 30)}},
+		{"at a loop boundary", start, at(20, 0), []time.Time{at(20, 0), at(22, 30)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := airTimes(lineup, tt.start, tt.from)
+			if len(got) != len(tt.want) {
+				t.Fatalf("airTimes() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("airTimes()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSetAirTimes(t *testing.T) {
+	items := []models.MediaWithScore{
+		{Media: models.Media{Title: "Alien", Runtime: 120}},
+		{Media: models.Media{Title: "Aliens", Runtime: 90}},
+	}
+	from := time.Date(2025, 11, 2, 21, 0, 0, 0, time.UTC)
+
+	setAirTimes(items, itemSlots(items), time.Time{}, from)
+	if items[0].AirsAt == nil || !items[0].AirsAt.Equal(from) {
+		t.Errorf("first title airs at %v, want %v", items[0].AirsAt, from)
+	}
+	if want := from.Add(2 * time.Hour); items[1].AirsAt == nil || !items[1].AirsAt.Equal(want) {
+		t.Errorf("second title airs at %v, want %v", items[1].AirsAt, want)
+	}
+}
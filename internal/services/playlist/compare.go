@@ -0,0 +1,138 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/services/similarity"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// Scoring strategies compared by Compare
+const (
+	StrategyHeuristic = "heuristic" // Genre/keyword scores only
+	StrategyLLM       = "llm"       // Genre/keyword scores refined by the LLM, as generation does
+)
+
+var (
+	// ErrUnknownStrategy is returned by Compare for a strategy other than heuristic or llm
+	ErrUnknownStrategy = errors.New("unknown scoring strategy")
+	// ErrLLMUnavailable is returned by Compare for the llm strategy while the LLM is disabled
+	ErrLLMUnavailable = errors.New("llm strategy requires llm.enabled")
+)
+
+// StrategyRanking is the ranking of a theme's candidates by one strategy
+type StrategyRanking struct {
+	Strategy     string                  `json:"strategy"`
+	Candidates   []models.MediaWithScore `json:"candidates"`
+	DurationMS   int64                   `json:"duration_ms"`
+	PromptTokens int                     `json:"prompt_tokens"`
+	EvalTokens   int                     `json:"eval_tokens"`
+	Degraded     []string                `json:"degraded,omitempty"` // LLM steps that fell back
+}
+
+// RankingOverlap measures how much two rankings agree
+type RankingOverlap struct {
+	A       string  `json:"a"`
+	B       string  `json:"b"`
+	Shared  int     `json:"shared"`  // Titles in both rankings
+	Jaccard float64 `json:"jaccard"` // Shared titles over titles in either ranking
+	// MeanRankShift is the average distance between the positions of the shared titles, 0
+	// when they are in the same order
+	MeanRankShift float64 `json:"mean_rank_shift"`
+}
+
+// Comparison ranks a theme's candidates with several strategies side by side
+type Comparison struct {
+	Theme      string            `json:"theme"`
+	Rankings   []StrategyRanking `json:"rankings"`
+	Overlap    []RankingOverlap  `json:"overlap"` // Every pair of rankings, in strategy order
+	ComparedAt time.Time         `json:"compared_at"`
+}
+
+// Compare ranks the theme's candidates with each strategy, as Preview does, and measures the
+// overlap of every pair of rankings. Nothing is written; limit overrides the theme's
+// max_items when positive.
+func (g *Generator) Compare(ctx context.Context, theme *config.ThemeConfig, strategies []string, limit int) (*Comparison, error) {
+	for _, strategy := range strategies {
+		switch strategy {
+		case StrategyHeuristic:
+		case StrategyLLM:
+			if !g.scorer.HasLLM() {
+				return nil, ErrLLMUnavailable
+			}
+		default:
+			return nil, fmt.Errorf("%w %q (must be heuristic or llm)", ErrUnknownStrategy, strategy)
+		}
+	}
+
+	opts := g.candidateOptions(ctx)
+	opts.Limit = limit
+
+	comparison := &Comparison{Theme: theme.Name, ComparedAt: time.Now()}
+	for _, strategy := range strategies {
+		opts.SkipLLM = strategy != StrategyLLM
+		usage := &similarity.UsageRecorder{}
+
+		start := time.Now()
+		candidates, err := g.scorer.FindCandidates(similarity.WithUsageRecorder(ctx, usage), theme, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s strategy failed: %w", strategy, err)
+		}
+
+		ranking := StrategyRanking{
+			Strategy:   strategy,
+			Candidates: candidates,
+			DurationMS: time.Since(start).Milliseconds(),
+			Degraded:   usage.Degraded(),
+		}
+		if ranking.Candidates == nil {
+			ranking.Candidates = []models.MediaWithScore{}
+		}
+		for _, u := range usage.Usage() {
+			ranking.PromptTokens += u.PromptTokens
+			ranking.EvalTokens += u.EvalTokens
+		}
+		comparison.Rankings = append(comparison.Rankings, ranking)
+	}
+
+	comparison.Overlap = []RankingOverlap{}
+	for i := range comparison.Rankings {
+		for j := i + 1; j < len(comparison.Rankings); j++ {
+			a, b := comparison.Rankings[i], comparison.Rankings[j]
+			overlap := compareRankings(a.Candidates, b.Candidates)
+			overlap.A, overlap.B = a.Strategy, b.Strategy
+			comparison.Overlap = append(comparison.Overlap, overlap)
+		}
+	}
+	return comparison, nil
+}
+
+// compareRankings measures the overlap of two rankings by media ID
+func compareRankings(a, b []models.MediaWithScore) RankingOverlap {
+	positions := make(map[int64]int, len(a))
+	for i, m := range a {
+		positions[m.ID] = i
+	}
+
+	var overlap RankingOverlap
+	var shift float64
+	for j, m := range b {
+		if i, ok := positions[m.ID]; ok {
+			overlap.Shared++
+			shift += math.Abs(float64(i - j))
+		}
+	}
+
+	if union := len(a) + len(b) - overlap.Shared; union > 0 {
+		overlap.Jaccard = float64(overlap.Shared) / float64(union)
+	}
+	if overlap.Shared > 0 {
+		overlap.MeanRankShift = shift / float64(overlap.Shared)
+	}
+	return overlap
+}
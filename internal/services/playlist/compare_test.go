@@ -0,0 +1,69 @@
+package playlist
+
+import (
+	"math"
+	"testing"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestCompareRankings(t *testing.T) {
+	ranked := func(ids ...int64) []models.MediaWithScore {
+		items := make([]models.MediaWithScore, len(ids))
+		for i, id := range ids {
+			items[i] = models.MediaWithScore{Media: models.Media{ID: id}}
+		}
+		return items
+	}
+
+	tests := []struct {
+		name        string
+		a, b        []models.MediaWithScore
+		wantShared  int
+		wantJaccard float64
+		wantShift   float64
+	}{
+		{name: "identical", a: ranked(1, 2, 3), b: ranked(1, 2, 3), wantShared: 3, wantJaccard: 1},
+		{name: "reordered", a: ranked(1, 2, 3), b: ranked(3, 2, 1), wantShared: 3, wantJaccard: 1, wantShift: 4.0 / 3},
+		{name: "partial", a: ranked(1, 2, 3, 4), b: ranked(2, 5, 1), wantShared: 2, wantJaccard: 2.0 / 5, wantShift: 1.5},
+		{name: "disjoint", a: ranked(1, 2), b: ranked(3, 4), wantJaccard: 0},
+		{name: "empty", wantJaccard: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareRankings(tt.a, tt.b)
+			if got.Shared != tt.wantShared ||
+				math.Abs(got.Jaccard-tt.wantJaccard) > 1e-9 ||
+				math.Abs(got.MeanRankShift-tt.wantShift) > 1e-9 {
+				t.Errorf("compareRankings() = %+v, want shared %d, jaccard %.3f, shift %.3f",
+					got, tt.wantShared, tt.wantJaccard, tt.wantShift)
+			}
+		})
+	}
+}
+
+func TestGoldenDrift(t *testing.T) {
+	lineup := func(ids ...int64) *Golden {
+		g := &Golden{Theme: "noir"}
+		for _, id := range ids {
+			g.Items = append(g.Items, GoldenItem{ID: id})
+		}
+		return g
+	}
+
+	drift := goldenDrift(lineup(1, 2, 3, 4), lineup(1, 2, 5, 4))
+	if math.Abs(drift.Drift-(1-3.0/5)) > 1e-9 {
+		t.Errorf("drift = %.3f, want %.3f", drift.Drift, 1-3.0/5)
+	}
+	if len(drift.Added) != 1 || drift.Added[0].ID != 5 || len(drift.Removed) != 1 || drift.Removed[0].ID != 3 {
+		t.Errorf("added %+v, removed %+v; want 5 added and 3 removed", drift.Added, drift.Removed)
+	}
+
+	if same := goldenDrift(lineup(1, 2), lineup(1, 2)); same.Drift != 0 || len(same.Added)+len(same.Removed) != 0 {
+		t.Errorf("expected no drift for the same lineup, got %+v", same)
+	}
+	if empty := goldenDrift(lineup(), lineup()); empty.Drift != 0 {
+		t.Errorf("expected no drift between empty lineups, got %.3f", empty.Drift)
+	}
+}
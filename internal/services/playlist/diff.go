@@ -0,0 +1,118 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// DiffEntry identifies a title in a lineup diff
+type DiffEntry struct {
+	Title string `json:"title"`
+	Year  int    `json:"year,omitempty"`
+}
+
+// LineupDiff compares a proposed playlist to the channel's current lineup
+type LineupDiff struct {
+	Added   []DiffEntry `json:"added"`
+	Removed []DiffEntry `json:"removed"`
+	Kept    []DiffEntry `json:"kept"`
+}
+
+// Changed reports whether applying the playlist would add or remove any title
+func (d *LineupDiff) Changed() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0
+}
+
+// ChangeRatio is the share of the lineup that would change, from 0 (identical) to 1 (all new)
+func (d *LineupDiff) ChangeRatio() float64 {
+	changed := max(len(d.Added), len(d.Removed))
+	if changed == 0 {
+		return 0
+	}
+	return float64(changed) / float64(changed+len(d.Kept))
+}
+
+// diffChannel fetches the channel's current programming and diffs the proposed items against it
+func (g *Generator) diffChannel(ctx context.Context, theme *config.ThemeConfig, items []models.MediaWithScore) *LineupDiff {
+	current, err := g.tunarr.GetProgramming(ctx, theme.ChannelID)
+	if err != nil {
+		g.logger.WarnContext(ctx, "failed to get current programming for diff",
+			"theme", theme.Name,
+			"channel_id", theme.ChannelID,
+			"error", err,
+		)
+		return nil
+	}
+
+	diff := diffLineup(current.Programs, items)
+	g.logger.InfoContext(ctx, "lineup diff",
+		"theme", theme.Name,
+		"added", len(diff.Added),
+		"removed", len(diff.Removed),
+		"kept", len(diff.Kept),
+	)
+	return diff
+}
+
+// diffLineup matches content programs to proposed items by Plex file path, falling back to
+// title and year. Flex and other non-content programs are ignored.
+func diffLineup(current []tunarr.Program, proposed []models.MediaWithScore) *LineupDiff {
+	diff := &LineupDiff{
+		Added:   []DiffEntry{},
+		Removed: []DiffEntry{},
+		Kept:    []DiffEntry{},
+	}
+
+	// Index unmatched content programs; a title scheduled twice is matched once per proposed item
+	byPath := make(map[string][]int)
+	byTitle := make(map[string][]int)
+	matched := make([]bool, len(current))
+	for i, p := range current {
+		if p.Type != "content" {
+			matched[i] = true
+			continue
+		}
+		if p.PlexFilePath != "" {
+			byPath[p.PlexFilePath] = append(byPath[p.PlexFilePath], i)
+		}
+		key := titleKey(p.Title, p.Year)
+		byTitle[key] = append(byTitle[key], i)
+	}
+
+	take := func(indexes []int) bool {
+		for _, i := range indexes {
+			if !matched[i] {
+				matched[i] = true
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, item := range proposed {
+		entry := DiffEntry{Title: item.Title, Year: item.Year}
+		if (item.Path != "" && take(byPath[item.Path])) || take(byTitle[titleKey(item.Title, item.Year)]) {
+			diff.Kept = append(diff.Kept, entry)
+		} else {
+			diff.Added = append(diff.Added, entry)
+		}
+	}
+
+	for i, p := range current {
+		if !matched[i] {
+			diff.Removed = append(diff.Removed, DiffEntry{Title: p.Title, Year: p.Year})
+		}
+	}
+
+	return diff
+}
+
+// titleKey normalizes a title and year for matching
+func titleKey(title string, year int) string {
+	return fmt.Sprintf("%s|%d", strings.ToLower(strings.TrimSpace(title)), year)
+}
@@ -0,0 +1,88 @@
+package playlist
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestDiffLineup(t *testing.T) {
+	content := func(title string, year int, path string) tunarr.Program {
+		return tunarr.Program{Type: "content", Title: title, Year: year, PlexFilePath: path}
+	}
+	item := func(title string, year int, path string) models.MediaWithScore {
+		return models.MediaWithScore{Media: models.Media{Title: title, Year: year, Path: path}}
+	}
+	titles := func(entries []DiffEntry) []string {
+		out := make([]string, 0, len(entries))
+		for _, e := range entries {
+			out = append(out, e.Title)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		current     []tunarr.Program
+		proposed    []models.MediaWithScore
+		wantAdded   []string
+		wantRemoved []string
+		wantKept    []string
+	}{
+		{
+			name:        "empty channel",
+			proposed:    []models.MediaWithScore{item("Alien", 1979, "/m/alien.mkv")},
+			wantAdded:   []string{"Alien"},
+			wantRemoved: []string{},
+			wantKept:    []string{},
+		},
+		{
+			name: "matched by path, flex ignored",
+			current: []tunarr.Program{
+				content("Alien (Director's Cut)", 1979, "/m/alien.mkv"),
+				{Type: "flex", Duration: 60000},
+				content("Heat", 1995, "/m/heat.mkv"),
+			},
+			proposed: []models.MediaWithScore{
+				item("Alien", 1979, "/m/alien.mkv"),
+				item("Aliens", 1986, "/m/aliens.mkv"),
+			},
+			wantAdded:   []string{"Aliens"},
+			wantRemoved: []string{"Heat"},
+			wantKept:    []string{"Alien"},
+		},
+		{
+			name:        "matched by title and year without path",
+			current:     []tunarr.Program{content("The Thing", 1982, ""), content("The Thing", 2011, "")},
+			proposed:    []models.MediaWithScore{item("the thing", 1982, "/m/thing.mkv")},
+			wantAdded:   []string{},
+			wantRemoved: []string{"The Thing"},
+			wantKept:    []string{"the thing"},
+		},
+		{
+			name:        "repeated program matched once",
+			current:     []tunarr.Program{content("Heat", 1995, "/m/heat.mkv"), content("Heat", 1995, "/m/heat.mkv")},
+			proposed:    []models.MediaWithScore{item("Heat", 1995, "/m/heat.mkv")},
+			wantAdded:   []string{},
+			wantRemoved: []string{"Heat"},
+			wantKept:    []string{"Heat"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffLineup(tt.current, tt.proposed)
+			if got := titles(diff.Added); !reflect.DeepEqual(got, tt.wantAdded) {
+				t.Errorf("added = %v, want %v", got, tt.wantAdded)
+			}
+			if got := titles(diff.Removed); !reflect.DeepEqual(got, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", got, tt.wantRemoved)
+			}
+			if got := titles(diff.Kept); !reflect.DeepEqual(got, tt.wantKept) {
+				t.Errorf("kept = %v, want %v", got, tt.wantKept)
+			}
+		})
+	}
+}
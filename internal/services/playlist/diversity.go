@@ -0,0 +1,96 @@
+package playlist
+
+import (
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// diversityWindow is how many of the best remaining titles are considered for each slot
+const diversityWindow = 5
+
+// Costs of placing a title right after a similar one
+const (
+	sameCollectionCost = 3
+	sameYearCost       = 1
+	sameGenreCost      = 1
+)
+
+// diversify reorders a score-sorted lineup so that titles from the same collection, release
+// year, or genre do not air back to back. Each slot takes the least similar of the best
+// remaining titles, so the order still follows score where it can. Genres the theme asks for
+// are ignored, as every title shares them. Collections the theme keeps together move as one block.
+func diversify(items []models.MediaWithScore, theme *config.ThemeConfig) []models.MediaWithScore {
+	themeGenres := make(map[string]bool, len(theme.Genres))
+	for _, g := range theme.Genres {
+		themeGenres[strings.ToLower(g)] = true
+	}
+
+	blocks := lineupBlocks(items, theme.Collections != "")
+
+	result := make([]models.MediaWithScore, 0, len(items))
+	for len(blocks) > 0 {
+		picked := 0
+		if len(result) > 0 {
+			prev := &result[len(result)-1]
+			best := -1
+			for i := 0; i < len(blocks) && i < diversityWindow; i++ {
+				if c := similarityCost(prev, &blocks[i][0], themeGenres); best < 0 || c < best {
+					best, picked = c, i
+				}
+			}
+		}
+
+		result = append(result, blocks[picked]...)
+		blocks = append(blocks[:picked], blocks[picked+1:]...)
+	}
+
+	return result
+}
+
+// lineupBlocks splits a lineup into the units diversify moves. With grouped collections,
+// consecutive members of a collection form one block.
+func lineupBlocks(items []models.MediaWithScore, groupCollections bool) [][]models.MediaWithScore {
+	blocks := make([][]models.MediaWithScore, 0, len(items))
+	for i, item := range items {
+		if n := len(blocks); groupCollections && n > 0 && item.CollectionTMDBID > 0 &&
+			items[i-1].CollectionTMDBID == item.CollectionTMDBID {
+			blocks[n-1] = append(blocks[n-1], item)
+			continue
+		}
+		blocks = append(blocks, []models.MediaWithScore{item})
+	}
+	return blocks
+}
+
+// similarityCost scores how alike two consecutive titles are; 0 means nothing in common
+func similarityCost(a, b *models.MediaWithScore, themeGenres map[string]bool) int {
+	cost := 0
+	if a.CollectionTMDBID > 0 && a.CollectionTMDBID == b.CollectionTMDBID {
+		cost += sameCollectionCost
+	}
+	if a.Year > 0 && a.Year == b.Year {
+		cost += sameYearCost
+	}
+	for _, g := range a.Genres {
+		if themeGenres[strings.ToLower(g)] {
+			continue
+		}
+		if containsGenre(b.Genres, g) {
+			cost += sameGenreCost
+			break
+		}
+	}
+	return cost
+}
+
+// containsGenre reports whether genres contains g, ignoring case
+func containsGenre(genres models.StringSlice, g string) bool {
+	for _, other := range genres {
+		if strings.EqualFold(other, g) {
+			return true
+		}
+	}
+	return false
+}
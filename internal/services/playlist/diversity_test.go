@@ -0,0 +1,72 @@
+package playlist
+
+import (
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestDiversify(t *testing.T) {
+	item := func(id int64, year int, collection int64, genres ...string) models.MediaWithScore {
+		return models.MediaWithScore{Media: models.Media{
+			ID: id, Year: year, CollectionTMDBID: collection, Genres: genres,
+		}}
+	}
+
+	tests := []struct {
+		name  string
+		theme config.ThemeConfig
+		items []models.MediaWithScore
+		want  []int64
+	}{
+		{
+			name:  "franchise spaced out",
+			theme: config.ThemeConfig{Genres: []string{"Science Fiction"}},
+			items: []models.MediaWithScore{
+				item(1, 1979, 8091, "Science Fiction", "Horror"),
+				item(2, 1986, 8091, "Science Fiction", "Action"),
+				item(3, 1992, 8091, "Science Fiction", "Thriller"),
+				item(4, 1982, 0, "Science Fiction", "Drama"),
+				item(5, 1997, 0, "Science Fiction", "Comedy"),
+			},
+			want: []int64{1, 4, 2, 5, 3},
+		},
+		{
+			name:  "theme genres ignored, same year spaced out",
+			theme: config.ThemeConfig{Genres: []string{"Horror"}},
+			items: []models.MediaWithScore{
+				item(1, 1980, 0, "Horror"),
+				item(2, 1980, 0, "Horror"),
+				item(3, 1981, 0, "Horror"),
+			},
+			want: []int64{1, 3, 2},
+		},
+		{
+			name:  "grouped collections move as a block",
+			theme: config.ThemeConfig{Collections: "group"},
+			items: []models.MediaWithScore{
+				item(1, 2001, 119, "Fantasy"),
+				item(2, 2002, 119, "Fantasy"),
+				item(3, 2003, 119, "Fantasy"),
+				item(4, 2003, 0, "Fantasy"),
+				item(5, 1995, 0, "Crime"),
+			},
+			want: []int64{1, 2, 3, 5, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diversify(tt.items, &tt.theme)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d items, want %d", len(got), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("item %d = %d, want %d", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+}
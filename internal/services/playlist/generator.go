@@ -6,10 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/geekxflood/program-director/internal/clients/tunarr"
 	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/events"
+	"github.com/geekxflood/program-director/internal/metrics"
 	"github.com/geekxflood/program-director/internal/services/cooldown"
 	"github.com/geekxflood/program-director/internal/services/similarity"
 	"github.com/geekxflood/program-director/pkg/models"
@@ -17,10 +22,22 @@ import (
 
 // Generator handles playlist generation and Tunarr integration
 type Generator struct {
-	tunarr   *tunarr.Client
-	scorer   *similarity.Scorer
-	cooldown *cooldown.Manager
-	logger   *slog.Logger
+	tunarr      *tunarr.Client
+	scorer      *similarity.Scorer
+	cooldown    *cooldown.Manager
+	snapshots   *repository.SnapshotRepository
+	generations *repository.GenerationRepository
+	llmUsage    *repository.LLMUsageRepository
+	lineups     *repository.LineupRepository
+	traces      *repository.LLMTraceRepository // nil unless llm.traces is enabled
+	traceDays   int                            // Retention of traces, 0 keeps them forever
+	concurrency int
+	retries     int
+	retryDelay  time.Duration
+	channels    *channelLocks
+	jobs        *jobTracker
+	events      *events.Bus
+	logger      *slog.Logger
 }
 
 // NewGenerator creates a new playlist Generator
@@ -28,16 +45,59 @@ func NewGenerator(
 	tunarrClient *tunarr.Client,
 	scorer *similarity.Scorer,
 	cooldownManager *cooldown.Manager,
+	snapshotRepo *repository.SnapshotRepository,
+	generationRepo *repository.GenerationRepository,
+	llmUsageRepo *repository.LLMUsageRepository,
+	lineupRepo *repository.LineupRepository,
+	cfg *config.GenerationConfig,
 	logger *slog.Logger,
 ) *Generator {
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
 	return &Generator{
-		tunarr:   tunarrClient,
-		scorer:   scorer,
-		cooldown: cooldownManager,
-		logger:   logger,
+		tunarr:      tunarrClient,
+		scorer:      scorer,
+		cooldown:    cooldownManager,
+		snapshots:   snapshotRepo,
+		generations: generationRepo,
+		llmUsage:    llmUsageRepo,
+		lineups:     lineupRepo,
+		concurrency: concurrency,
+		retries:     cfg.Retries,
+		retryDelay:  time.Duration(cfg.RetryDelay) * time.Second,
+		channels:    newChannelLocks(),
+		jobs:        newJobTracker(),
+		logger:      logger,
 	}
 }
 
+// SetEvents sets the bus finished generations are published to
+func (g *Generator) SetEvents(bus *events.Bus) {
+	g.events = bus
+}
+
+// SetTraces records the prompts and responses of each generation's Ollama calls to repo,
+// deleting those older than retentionDays (unless 0) after each generation
+func (g *Generator) SetTraces(repo *repository.LLMTraceRepository, retentionDays int) {
+	g.traces = repo
+	g.traceDays = retentionDays
+}
+
+// GenerationEvent is published to the event bus when a generation finishes
+type GenerationEvent struct {
+	Theme     string `json:"theme"`
+	ChannelID string `json:"channel_id"`
+	Status    string `json:"status"` // generated, skipped, or failed
+	DryRun    bool   `json:"dry_run"`
+	ItemCount int    `json:"item_count"`
+	Duration  string `json:"duration"`
+	Error     string `json:"error,omitempty"`
+	Skipped   string `json:"skipped,omitempty"`
+}
+
 // GenerationResult contains the results of a playlist generation
 type GenerationResult struct {
 	ThemeName  string
@@ -48,68 +108,333 @@ type GenerationResult struct {
 	Duration   time.Duration
 	Error      error
 	Playlist   *models.Playlist
+	SkipReason string // Set when the theme was not generated on purpose
+	Attempts   int    // Runs it took, more than 1 when transient failures were retried
+	Degraded   string // LLM steps that fell back to another model or to heuristics, "; " separated
+
+	// Diff against the channel's current lineup, set on dry runs
+	Diff *LineupDiff
 }
 
-// GenerateAll generates playlists for all themes
-func (g *Generator) GenerateAll(ctx context.Context, themes []config.ThemeConfig, dryRun bool) ([]GenerationResult, error) {
-	results := make([]GenerationResult, 0, len(themes))
+// GenerateAll generates playlists for all themes, up to the configured concurrency at once.
+// Themes sharing a channel run one after another. Themes whose days_of_week exclude today
+// are skipped unless force is set. Results are in theme order. Themes not started before Drain
+// are left out and ErrShuttingDown is returned.
+func (g *Generator) GenerateAll(ctx context.Context, themes []config.ThemeConfig, dryRun, force bool) (*GenerationSummary, error) {
+	today := time.Now().Weekday()
+
+	// Group themes by channel so two themes never write the same channel at once
+	var jobs [][]int
+	jobByChannel := make(map[string]int)
+	for i, theme := range themes {
+		j, ok := jobByChannel[theme.ChannelID]
+		if !ok {
+			j = len(jobs)
+			jobByChannel[theme.ChannelID] = j
+			jobs = append(jobs, nil)
+		}
+		jobs[j] = append(jobs[j], i)
+	}
 
-	for _, theme := range themes {
+	// Themes on different channels run in parallel, so they share the titles they pick
+	ctx = withReservations(ctx, newReservations())
+
+	queue := make(chan []int)
+	results := make([]GenerationResult, len(themes))
+	done := make([]bool, len(themes))
+
+	var wg sync.WaitGroup
+	for range min(g.concurrency, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				for _, i := range job {
+					if ctx.Err() != nil {
+						break
+					}
+					results[i] = g.generateIfScheduled(ctx, &themes[i], today, dryRun, force)
+					if errors.Is(results[i].Error, ErrShuttingDown) {
+						break
+					}
+					done[i] = true
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, job := range jobs {
 		select {
+		case queue <- job:
 		case <-ctx.Done():
-			return results, ctx.Err()
-		default:
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	// Themes not reached before cancellation or shutdown are left out
+	generated := make([]GenerationResult, 0, len(themes))
+	for i := range themes {
+		if done[i] {
+			generated = append(generated, results[i])
+		}
+	}
+	if len(generated) < len(themes) {
+		if err := ctx.Err(); err != nil {
+			return summarize(generated), err
 		}
+		return summarize(generated), ErrShuttingDown
+	}
+	return summarize(generated), nil
+}
 
-		result := g.Generate(ctx, &theme, dryRun)
-		results = append(results, result)
+// generateIfScheduled generates a theme, or records it as skipped when it is not scheduled on day
+func (g *Generator) generateIfScheduled(ctx context.Context, theme *config.ThemeConfig, day time.Weekday, dryRun, force bool) GenerationResult {
+	if !force && !theme.RunsOn(day) {
+		g.logger.InfoContext(ctx, "skipping theme not scheduled today",
+			"theme", theme.Name,
+			"days_of_week", theme.DaysOfWeek,
+		)
+		result := GenerationResult{
+			ThemeName:  theme.Name,
+			ChannelID:  theme.ChannelID,
+			SkipReason: "not scheduled on " + day.String(),
+		}
+		g.record(ctx, &result, dryRun)
+		return result
 	}
 
-	return results, nil
+	return g.Generate(ctx, theme, dryRun)
 }
 
-// Generate creates a playlist for a single theme
+// Generate creates a playlist for a single theme, retrying transient failures, and records the run.
+// Unless dryRun is set it fails with ErrChannelBusy, without recording a run, while another
+// generation or undo is writing the theme's channel. After Drain it fails with ErrShuttingDown.
 func (g *Generator) Generate(ctx context.Context, theme *config.ThemeConfig, dryRun bool) GenerationResult {
+	ctx, end, ok := g.jobs.start(ctx)
+	if !ok {
+		return GenerationResult{
+			ThemeName: theme.Name,
+			ChannelID: theme.ChannelID,
+			Error:     ErrShuttingDown,
+		}
+	}
+	defer end()
+
+	if !dryRun {
+		if !g.channels.tryLock(theme.ChannelID) {
+			g.logger.WarnContext(ctx, "channel busy, generation rejected", "theme", theme.Name, "channel_id", theme.ChannelID)
+			return GenerationResult{
+				ThemeName: theme.Name,
+				ChannelID: theme.ChannelID,
+				Error:     fmt.Errorf("channel %s: %w", theme.ChannelID, ErrChannelBusy),
+			}
+		}
+		defer g.channels.unlock(theme.ChannelID)
+	}
+
+	usage := &similarity.UsageRecorder{CaptureTraces: g.traces != nil}
+	result := g.generateWithRetry(similarity.WithUsageRecorder(ctx, usage), theme, dryRun)
+	result.Degraded = strings.Join(usage.Degraded(), "; ")
+	metrics.GenerationDuration.WithLabelValues(theme.Name, result.status()).Observe(result.Duration.Seconds())
+	generationID := g.record(ctx, &result, dryRun)
+	g.recordUsage(ctx, theme.Name, generationID, usage.Usage())
+	g.recordTraces(ctx, theme.Name, generationID, usage.Traces())
+	g.publish(&result, dryRun)
+	return result
+}
+
+// publish sends a finished generation to the event bus
+func (g *Generator) publish(result *GenerationResult, dryRun bool) {
+	event := GenerationEvent{
+		Theme:     result.ThemeName,
+		ChannelID: result.ChannelID,
+		Status:    result.status(),
+		DryRun:    dryRun,
+		ItemCount: result.ItemCount,
+		Duration:  result.Duration.String(),
+		Skipped:   result.SkipReason,
+	}
+	if result.Error != nil {
+		event.Error = result.Error.Error()
+	}
+	g.events.Publish(events.TypeGeneration, event)
+}
+
+// record stores a generation run and returns its ID, or nil when it wasn't stored. Failures
+// are logged, as they must not fail the run.
+func (g *Generator) record(ctx context.Context, result *GenerationResult, dryRun bool) *int64 {
+	if g.generations == nil {
+		return nil
+	}
+
+	run := &models.Generation{
+		ThemeName:  result.ThemeName,
+		ChannelID:  result.ChannelID,
+		Generated:  result.Generated,
+		DryRun:     dryRun,
+		ItemCount:  result.ItemCount,
+		TotalScore: result.TotalScore,
+		DurationMS: result.Duration.Milliseconds(),
+		SkipReason: result.SkipReason,
+		Attempts:   result.Attempts,
+		Degraded:   result.Degraded,
+	}
+	if result.Error != nil {
+		run.Error = result.Error.Error()
+	}
+
+	if err := g.generations.Create(ctx, run); err != nil {
+		g.logger.WarnContext(ctx, "failed to record generation", "theme", result.ThemeName, "error", err)
+		return nil
+	}
+	return &run.ID
+}
+
+// recordUsage stores the Ollama calls of a generation run. Failures are logged, as they must
+// not fail the run.
+func (g *Generator) recordUsage(ctx context.Context, theme string, generationID *int64, usage []models.LLMUsage) {
+	if g.llmUsage == nil {
+		return
+	}
+
+	for i := range usage {
+		u := &usage[i]
+		u.ThemeName = theme
+		u.GenerationID = generationID
+		if err := g.llmUsage.Create(ctx, u); err != nil {
+			g.logger.WarnContext(ctx, "failed to record llm usage", "theme", theme, "error", err)
+			return
+		}
+	}
+}
+
+// recordTraces stores the prompts and responses of a generation run and prunes expired
+// traces. Failures are logged, as they must not fail the run.
+func (g *Generator) recordTraces(ctx context.Context, theme string, generationID *int64, traces []models.LLMTrace) {
+	if g.traces == nil {
+		return
+	}
+
+	for i := range traces {
+		t := &traces[i]
+		t.ThemeName = theme
+		t.GenerationID = generationID
+		if err := g.traces.Create(ctx, t); err != nil {
+			g.logger.WarnContext(ctx, "failed to record llm trace", "theme", theme, "error", err)
+			return
+		}
+	}
+
+	if g.traceDays > 0 {
+		before := time.Now().AddDate(0, 0, -g.traceDays)
+		if deleted, err := g.traces.DeleteOlderThan(ctx, before); err != nil {
+			g.logger.WarnContext(ctx, "failed to prune llm traces", "error", err)
+		} else if deleted > 0 {
+			g.logger.DebugContext(ctx, "pruned llm traces", "deleted", deleted, "before", before)
+		}
+	}
+}
+
+// storeLineup stores the titles of a lineup applied to the theme's channel with their air
+// times. Failures are logged, as the lineup is already on air.
+func (g *Generator) storeLineup(ctx context.Context, theme *config.ThemeConfig, items []models.MediaWithScore, appliedAt time.Time) {
+	if g.lineups == nil {
+		return
+	}
+
+	lineup := make([]models.LineupItem, 0, len(items))
+	for i, item := range items {
+		mediaID := item.ID
+		entry := models.LineupItem{
+			ThemeName: theme.Name,
+			Position:  i + 1,
+			MediaID:   &mediaID,
+			Title:     item.Title,
+			Year:      item.Year,
+			MediaType: item.MediaType,
+			Runtime:   item.Runtime,
+			AppliedAt: appliedAt,
+		}
+		if item.AirsAt != nil {
+			entry.AirsAt = *item.AirsAt
+		}
+		lineup = append(lineup, entry)
+	}
+
+	if err := g.lineups.Replace(ctx, theme.ChannelID, lineup); err != nil {
+		g.logger.WarnContext(ctx, "failed to store lineup", "theme", theme.Name, "channel_id", theme.ChannelID, "error", err)
+	}
+}
+
+// generate creates a playlist for a single theme
+func (g *Generator) generate(ctx context.Context, theme *config.ThemeConfig, dryRun bool) GenerationResult {
 	start := time.Now()
 	result := GenerationResult{
 		ThemeName: theme.Name,
 		ChannelID: theme.ChannelID,
 	}
 
-	g.logger.Info("generating playlist",
+	g.logger.InfoContext(ctx, "generating playlist",
 		"theme", theme.Name,
 		"channel", theme.ChannelID,
 		"dry_run", dryRun,
 	)
 
-	// Get media on cooldown
-	excludeIDs, err := g.cooldown.GetActiveCooldownMediaIDs(ctx)
-	if err != nil {
-		g.logger.Warn("failed to get cooldown IDs", "error", err)
-		excludeIDs = nil
+	// Find matching candidates, leaving out titles other themes of the run already picked
+	opts := g.candidateOptions(ctx)
+	reserved := reservationsFrom(ctx)
+	if reserved != nil {
+		reserved.release(theme.Name)
+		opts.ExcludeIDs = append(opts.ExcludeIDs, reserved.taken(theme.Name)...)
 	}
-
-	g.logger.Debug("excluding media on cooldown", "count", len(excludeIDs))
-
-	// Find matching candidates
-	candidates, err := g.scorer.FindCandidates(ctx, theme, excludeIDs)
+	candidates, err := g.scorer.FindCandidates(ctx, theme, opts)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to find candidates: %w", err)
 		result.Duration = time.Since(start)
 		return result
 	}
 
+	// Another theme may have picked the same titles while these were being scored
+	if reserved != nil {
+		found := len(candidates)
+		candidates = reserved.claim(theme.Name, candidates)
+		if dropped := found - len(candidates); dropped > 0 {
+			g.logger.InfoContext(ctx, "dropped candidates picked by another theme", "theme", theme.Name, "count", dropped)
+		}
+	}
+
 	if len(candidates) == 0 {
-		g.logger.Warn("no candidates found for theme", "theme", theme.Name)
+		g.logger.WarnContext(ctx, "no candidates found for theme", "theme", theme.Name)
+		result.SkipReason = "no candidates found"
 		result.Duration = time.Since(start)
 		return result
 	}
 
-	g.logger.Info("found candidates",
+	g.logger.InfoContext(ctx, "found candidates",
 		"theme", theme.Name,
 		"count", len(candidates),
 	)
 
+	// Put the lineup in the theme's play order
+	candidates = g.order(ctx, theme, candidates)
+
+	// Daypart the lineup so mature titles stay out of the theme's quiet hours
+	lineup := itemSlots(candidates)
+	if theme.Watershed != nil {
+		lineup = daypart(candidates, theme.Watershed, time.Now())
+		candidates = slotItems(lineup)
+		g.logger.DebugContext(ctx, "applied watershed",
+			"theme", theme.Name,
+			"start", theme.Watershed.Start,
+			"end", theme.Watershed.End,
+			"slots", len(lineup),
+			"items", len(candidates),
+		)
+	}
+
 	// Build playlist
 	playlist := &models.Playlist{
 		ThemeName:   theme.Name,
@@ -133,7 +458,7 @@ func (g *Generator) Generate(ctx context.Context, theme *config.ThemeConfig, dry
 	result.TotalScore = totalScore
 
 	// Log playlist
-	g.logger.Info("playlist generated",
+	g.logger.InfoContext(ctx, "playlist generated",
 		"theme", theme.Name,
 		"items", len(candidates),
 		"total_score", fmt.Sprintf("%.2f", totalScore),
@@ -141,7 +466,7 @@ func (g *Generator) Generate(ctx context.Context, theme *config.ThemeConfig, dry
 	)
 
 	for i, c := range candidates {
-		g.logger.Debug("playlist item",
+		g.logger.DebugContext(ctx, "playlist item",
 			"position", i+1,
 			"title", c.Title,
 			"year", c.Year,
@@ -152,64 +477,147 @@ func (g *Generator) Generate(ctx context.Context, theme *config.ThemeConfig, dry
 
 	// Apply to Tunarr if not dry run
 	if !dryRun {
-		if err := g.applyToTunarr(ctx, theme.ChannelID, candidates); err != nil {
-			result.Error = fmt.Errorf("failed to apply to Tunarr: %w", err)
-		} else {
-			result.Generated = true
+		programs := buildPrograms(lineup)
+		if theme.SkipUnchanged {
+			if reason := g.unchangedReason(ctx, theme, programs, candidates); reason != "" {
+				g.logger.InfoContext(ctx, "skipping unchanged lineup", "theme", theme.Name, "reason", reason)
+				result.SkipReason = reason
+				result.Duration = time.Since(start)
+				return result
+			}
+		}
 
-			// Record plays and cooldowns
-			for _, c := range candidates {
-				if err := g.cooldown.RecordPlay(ctx, &c.Media, theme.ChannelID, theme.Name); err != nil {
-					g.logger.Warn("failed to record play",
-						"media_id", c.ID,
-						"title", c.Title,
-						"error", err,
-					)
-				}
+		if err := g.commit(ctx, theme, programs, lineup, candidates); err != nil {
+			result.Error = err
+			if reserved != nil {
+				reserved.release(theme.Name)
 			}
+		} else {
+			result.Generated = true
 		}
 	} else {
 		result.Generated = true // Mark as successful for dry run
+		setAirTimes(candidates, lineup, time.Time{}, time.Now())
+		result.Diff = g.diffChannel(ctx, theme, candidates)
 	}
 
 	result.Duration = time.Since(start)
 	return result
 }
 
-// applyToTunarr updates the Tunarr channel with the generated playlist
-func (g *Generator) applyToTunarr(ctx context.Context, channelID string, items []models.MediaWithScore) error {
-	// First, get channel info to verify it exists
-	channel, err := g.tunarr.GetChannel(ctx, channelID)
+// commit applies the lineup to Tunarr, records plays and cooldowns for its items in one
+// transaction, then sets their air times and stores the lineup. When the plays cannot be
+// recorded the channel's previous lineup is restored and an error returned, so a lineup is
+// never left on air without its cooldowns. Once started it is not interrupted by ctx being
+// canceled, bounded by commitTimeout instead.
+func (g *Generator) commit(ctx context.Context, theme *config.ThemeConfig, programs []tunarr.Program, lineup []slot, candidates []models.MediaWithScore) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("canceled before applying to Tunarr: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), commitTimeout)
+	defer cancel()
+
+	channelStart, err := g.applyToTunarr(ctx, theme, programs)
 	if err != nil {
-		return fmt.Errorf("failed to get channel %s: %w", channelID, err)
+		return fmt.Errorf("failed to apply to Tunarr: %w", err)
 	}
+	appliedAt := time.Now()
 
-	g.logger.Debug("updating Tunarr channel",
-		"channel_id", channelID,
-		"channel_name", channel.Name,
-	)
+	media := make([]*models.Media, len(candidates))
+	for i := range candidates {
+		media[i] = &candidates[i].Media
+	}
+	if err := g.cooldown.RecordPlays(ctx, media, theme.ChannelID, theme.Name); err != nil {
+		err = fmt.Errorf("failed to record plays: %w", err)
+		if _, restoreErr := g.restoreLatest(ctx, theme.ChannelID); restoreErr != nil {
+			g.logger.ErrorContext(ctx, "failed to restore channel after recording plays failed",
+				"theme", theme.Name,
+				"channel_id", theme.ChannelID,
+				"error", restoreErr,
+			)
+			return fmt.Errorf("%w; lineup left applied: %w", err, restoreErr)
+		}
+		return fmt.Errorf("%w; previous lineup restored", err)
+	}
 
-	// Get media sources to find the Plex source
-	sources, err := g.tunarr.GetMediaSources(ctx)
+	setAirTimes(candidates, lineup, channelStart, appliedAt)
+	g.storeLineup(ctx, theme, candidates, appliedAt)
+	return nil
+}
+
+// Preview ranks the theme's candidates as a generation would, without touching Tunarr or
+// recording plays. The LLM refines the ranking only when withLLM is set; limit overrides
+// the theme's max_items when positive.
+func (g *Generator) Preview(ctx context.Context, theme *config.ThemeConfig, withLLM bool, limit int) ([]models.MediaWithScore, error) {
+	opts := g.candidateOptions(ctx)
+	opts.SkipLLM = !withLLM
+	opts.Limit = limit
+
+	candidates, err := g.scorer.FindCandidates(ctx, theme, opts)
 	if err != nil {
-		return fmt.Errorf("failed to get media sources: %w", err)
+		return nil, fmt.Errorf("failed to find candidates: %w", err)
 	}
+	return candidates, nil
+}
 
-	var plexSourceID string
-	for _, source := range sources {
-		if source.Type == "plex" {
-			plexSourceID = source.ID
-			break
-		}
+// CheckTheme checks a theme's feasibility against the catalog and current cooldowns
+func (g *Generator) CheckTheme(ctx context.Context, theme *config.ThemeConfig) ([]similarity.ThemeWarning, error) {
+	return g.scorer.CheckTheme(ctx, theme, g.candidateOptions(ctx))
+}
+
+// SuggestThemes asks the LLM for up to count new themes suited to the library, avoiding the
+// existing themes' names and genres
+func (g *Generator) SuggestThemes(ctx context.Context, existing []config.ThemeConfig, count int) ([]similarity.ThemeSuggestion, error) {
+	return g.scorer.SuggestThemes(ctx, existing, count)
+}
+
+// candidateOptions gathers media to exclude or demote: titles on cooldown and titles the household recently watched
+func (g *Generator) candidateOptions(ctx context.Context) similarity.CandidateOptions {
+	var opts similarity.CandidateOptions
+
+	// Get media on cooldown
+	excludeIDs, err := g.cooldown.GetActiveCooldownMediaIDs(ctx)
+	if err != nil {
+		g.logger.WarnContext(ctx, "failed to get cooldown IDs", "error", err)
+		excludeIDs = nil
 	}
+	g.logger.DebugContext(ctx, "excluding media on cooldown", "count", len(excludeIDs))
+	opts.ExcludeIDs = excludeIDs
 
-	if plexSourceID == "" {
-		return errors.New("no Plex media source found in Tunarr")
+	watchedIDs, err := g.cooldown.GetRecentlyWatchedMediaIDs(ctx)
+	if err != nil {
+		g.logger.WarnContext(ctx, "failed to get recently watched IDs", "error", err)
+		return opts
+	}
+	if len(watchedIDs) == 0 {
+		return opts
+	}
+
+	if penalty := g.cooldown.WatchedPenalty(); penalty > 0 {
+		opts.Penalties = make(map[int64]float64, len(watchedIDs))
+		for _, id := range watchedIDs {
+			opts.Penalties[id] = penalty
+		}
+		g.logger.DebugContext(ctx, "penalizing recently watched media", "count", len(watchedIDs), "penalty", penalty)
+	} else {
+		opts.ExcludeIDs = append(opts.ExcludeIDs, watchedIDs...)
+		g.logger.DebugContext(ctx, "excluding recently watched media", "count", len(watchedIDs))
 	}
 
-	// Build programming lineup
-	programs := make([]tunarr.Program, 0, len(items))
-	for _, item := range items {
+	return opts
+}
+
+// buildPrograms converts a lineup into Tunarr programs
+func buildPrograms(lineup []slot) []tunarr.Program {
+	programs := make([]tunarr.Program, 0, len(lineup))
+	for _, s := range lineup {
+		// Flex fills time, e.g. the rest of a watershed window
+		if s.item == nil {
+			programs = append(programs, tunarr.Program{Type: "flex", Duration: s.flex.Milliseconds()})
+			continue
+		}
+		item := s.item
+
 		// Convert runtime to milliseconds
 		durationMs := int64(item.Runtime) * 60 * 1000
 
@@ -223,9 +631,46 @@ func (g *Generator) applyToTunarr(ctx context.Context, channelID string, items [
 			PlexFilePath: item.Path,
 			Title:        item.Title,
 			Year:         item.Year,
+			Icon:         item.PosterURL,
 		}
 		programs = append(programs, program)
 	}
+	return programs
+}
+
+// applyToTunarr updates the Tunarr channel with the generated programs and returns the
+// time the channel loops its lineup from, zero when Tunarr does not report it
+func (g *Generator) applyToTunarr(ctx context.Context, theme *config.ThemeConfig, programs []tunarr.Program) (time.Time, error) {
+	channelID := theme.ChannelID
+
+	// First, get channel info to verify it exists
+	channel, err := g.tunarr.GetChannel(ctx, channelID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get channel %s: %w", channelID, err)
+	}
+
+	g.logger.DebugContext(ctx, "updating Tunarr channel",
+		"channel_id", channelID,
+		"channel_name", channel.Name,
+	)
+
+	// Get media sources to find the Plex source
+	sources, err := g.tunarr.GetMediaSources(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get media sources: %w", err)
+	}
+
+	var plexSourceID string
+	for _, source := range sources {
+		if source.Type == "plex" {
+			plexSourceID = source.ID
+			break
+		}
+	}
+
+	if plexSourceID == "" {
+		return time.Time{}, errors.New("no Plex media source found in Tunarr")
+	}
 
 	// Create programming object
 	programming := &tunarr.Programming{
@@ -233,15 +678,23 @@ func (g *Generator) applyToTunarr(ctx context.Context, channelID string, items [
 		Programs: programs,
 	}
 
+	// Keep the current lineup so it can be restored with Undo
+	if err := g.snapshotChannel(ctx, theme, lineupHash(programs)); err != nil {
+		return time.Time{}, fmt.Errorf("failed to snapshot channel %s: %w", channelID, err)
+	}
+
 	// Apply to Tunarr
 	if err := g.tunarr.SetProgramming(ctx, channelID, programming); err != nil {
-		return err
+		return time.Time{}, err
 	}
 
-	g.logger.Info("Tunarr channel updated",
+	g.logger.InfoContext(ctx, "Tunarr channel updated",
 		"channel_id", channelID,
 		"programs", len(programs),
 	)
 
-	return nil
+	if channel.StartTime <= 0 {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(channel.StartTime), nil
 }
@@ -0,0 +1,228 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/cooldown"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestGenerateAllKeepsThemeOrder(t *testing.T) {
+	notToday := strings.ToLower(time.Now().Add(24 * time.Hour).Weekday().String())
+
+	themes := make([]config.ThemeConfig, 0, 8)
+	for i := range 8 {
+		themes = append(themes, config.ThemeConfig{
+			Name:       fmt.Sprintf("theme-%d", i),
+			ChannelID:  fmt.Sprintf("channel-%d", i%3),
+			DaysOfWeek: []string{notToday},
+		})
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, concurrency := range []int{0, 1, 4, 16} {
+		g := NewGenerator(nil, nil, nil, nil, nil, nil, nil, &config.GenerationConfig{Concurrency: concurrency}, logger)
+
+		summary, err := g.GenerateAll(context.Background(), themes, true, false)
+		if err != nil {
+			t.Fatalf("concurrency %d: GenerateAll() error = %v", concurrency, err)
+		}
+		results := summary.Results
+		if len(results) != len(themes) {
+			t.Fatalf("concurrency %d: got %d results, want %d", concurrency, len(results), len(themes))
+		}
+		for i, r := range results {
+			if r.ThemeName != themes[i].Name || r.SkipReason == "" {
+				t.Errorf("concurrency %d: result %d = %q (skip %q), want skipped %q",
+					concurrency, i, r.ThemeName, r.SkipReason, themes[i].Name)
+			}
+		}
+	}
+}
+
+func TestGenerateAllCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	g := NewGenerator(nil, nil, nil, nil, nil, nil, nil, &config.GenerationConfig{Concurrency: 2}, logger)
+
+	summary, err := g.GenerateAll(ctx, []config.ThemeConfig{{Name: "a", ChannelID: "1"}}, true, false)
+	if err == nil || len(summary.Results) != 0 {
+		t.Errorf("GenerateAll() = %d results, %v; want none and context error", len(summary.Results), err)
+	}
+}
+
+func TestBuildProgramsPassesPoster(t *testing.T) {
+	items := []models.MediaWithScore{
+		{Media: models.Media{Title: "Heat", Year: 1995, Runtime: 170, PosterURL: "https://image.tmdb.org/heat.jpg", FanartURL: "https://image.tmdb.org/heat-fanart.jpg"}},
+		{Media: models.Media{Title: "Ronin", Year: 1998, Runtime: 122}},
+	}
+
+	programs := buildPrograms(itemSlots(items))
+	if len(programs) != 2 {
+		t.Fatalf("buildPrograms() = %d programs, want 2", len(programs))
+	}
+	if programs[0].Icon != "https://image.tmdb.org/heat.jpg" {
+		t.Errorf("icon = %q, want Heat's poster", programs[0].Icon)
+	}
+	if programs[1].Icon != "" {
+		t.Errorf("icon = %q, want none for media without a poster", programs[1].Icon)
+	}
+}
+
+func TestRecordTracesPrunesExpired(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	traces := repository.NewLLMTraceRepository(newTestDB(t))
+
+	now := time.Now()
+	for _, age := range []int{10, 6} {
+		trace := &models.LLMTrace{ThemeName: "noir", Purpose: "refine", Model: "llama3", CreatedAt: now.AddDate(0, 0, -age)}
+		if err := traces.Create(ctx, trace); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	g := NewGenerator(nil, nil, nil, nil, nil, nil, nil, &config.GenerationConfig{}, logger)
+	g.SetTraces(traces, 7)
+	g.recordTraces(ctx, "noir", nil, []models.LLMTrace{{Purpose: "narrative_order", Model: "llama3"}})
+
+	kept, err := traces.List(ctx, repository.ListLLMTraceOptions{ThemeName: "noir"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var purposes []string
+	for _, trace := range kept {
+		purposes = append(purposes, trace.Purpose)
+		if trace.CreatedAt.Before(now.AddDate(0, 0, -7)) {
+			t.Errorf("trace from %v outlived the 7 day retention", trace.CreatedAt)
+		}
+	}
+	if len(kept) != 2 || purposes[1] != "narrative_order" {
+		t.Errorf("traces after generation = %v, want the 6 day old one and the new one", purposes)
+	}
+
+	// A retention of 0 keeps traces forever
+	old := &models.LLMTrace{ThemeName: "noir", Purpose: "refine", Model: "llama3", CreatedAt: now.AddDate(-1, 0, 0)}
+	if err := traces.Create(ctx, old); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	g.SetTraces(traces, 0)
+	g.recordTraces(ctx, "noir", nil, nil)
+	if kept, err := traces.List(ctx, repository.ListLLMTraceOptions{ThemeName: "noir"}); err != nil || len(kept) != 3 {
+		t.Errorf("traces with retention 0 = %d (%v), want 3", len(kept), err)
+	}
+}
+
+func TestCommitRestoresChannelWhenPlaysFail(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	theme := &config.ThemeConfig{Name: "sci-fi", ChannelID: "ch-1"}
+
+	for _, failing := range []bool{false, true} {
+		db := newTestDB(t)
+		mediaRepo := repository.NewMediaRepository(db)
+		media := []*models.Media{
+			{ExternalID: 1, Source: models.MediaSourceRadarr, MediaType: models.MediaTypeMovie, Title: "Solaris", Runtime: 167, HasFile: true},
+			{ExternalID: 2, Source: models.MediaSourceRadarr, MediaType: models.MediaTypeMovie, Title: "Stalker", Runtime: 162, HasFile: true},
+		}
+		if _, err := mediaRepo.BulkUpsert(ctx, media); err != nil {
+			t.Fatalf("BulkUpsert() error = %v", err)
+		}
+		if failing {
+			if _, err := db.Exec(ctx, `CREATE TRIGGER reject_cooldown BEFORE INSERT ON media_cooldowns WHEN NEW.media_title = 'Stalker'
+				BEGIN SELECT RAISE(ABORT, 'cooldowns unavailable'); END`); err != nil {
+				t.Fatalf("failed to create trigger: %v", err)
+			}
+		}
+
+		fake, client := newFakeTunarr(t, "ch-1", tunarr.Program{Type: "content", Title: "Alien", Duration: 7000000})
+		cooldowns := cooldown.NewManager(repository.NewCooldownRepository(db), repository.NewHistoryRepository(db), nil, &config.CooldownConfig{}, logger)
+		g := NewGenerator(client, nil, cooldowns, repository.NewSnapshotRepository(db), nil, nil, repository.NewLineupRepository(db), &config.GenerationConfig{}, logger)
+
+		candidates := []models.MediaWithScore{{Media: *media[0]}, {Media: *media[1]}}
+		lineup := itemSlots(candidates)
+		err := g.commit(ctx, theme, buildPrograms(lineup), lineup, candidates)
+
+		var plays, lineupItems int
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM play_history").Scan(&plays); err != nil {
+			t.Fatalf("failed to count plays: %v", err)
+		}
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM lineup_items").Scan(&lineupItems); err != nil {
+			t.Fatalf("failed to count lineup items: %v", err)
+		}
+
+		if !failing {
+			if err != nil {
+				t.Fatalf("commit() error = %v", err)
+			}
+			if got := fake.titles(); len(got) != 2 || plays != 2 || lineupItems != 2 {
+				t.Errorf("commit() left channel %v with %d plays and %d lineup items, want the lineup with 2 of each", got, plays, lineupItems)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), "previous lineup restored") {
+			t.Fatalf("commit() error = %v, want the failed plays with the lineup restored", err)
+		}
+		if got := fake.titles(); len(got) != 1 || got[0] != "Alien" {
+			t.Errorf("channel programs = %v, want the previous [Alien]", got)
+		}
+		if plays != 0 || lineupItems != 0 {
+			t.Errorf("failed commit left %d plays and %d lineup items, want none", plays, lineupItems)
+		}
+	}
+}
+
+func TestCandidateOptionsWatchedPenalty(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db := newTestDB(t)
+
+	heat := &models.Media{ExternalID: 1, Source: models.MediaSourceRadarr, MediaType: models.MediaTypeMovie, Title: "Heat", HasFile: true}
+	if _, err := repository.NewMediaRepository(db).BulkUpsert(ctx, []*models.Media{heat}); err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+	watchRepo := repository.NewWatchHistoryRepository(db)
+	w := &models.WatchHistory{MediaID: &heat.ID, Source: "tautulli", ExternalID: "11", Title: "Heat", MediaType: models.MediaTypeMovie, WatchedAt: time.Now().Add(-48 * time.Hour)}
+	if _, err := watchRepo.CreateIfMissing(ctx, w); err != nil {
+		t.Fatalf("CreateIfMissing() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		cfg         config.CooldownConfig
+		wantExclude bool
+		wantPenalty float64
+	}{
+		{"excluded without a penalty", config.CooldownConfig{WatchedDays: 7}, true, 0},
+		{"penalized", config.CooldownConfig{WatchedDays: 7, WatchedPenalty: 0.3}, false, 0.3},
+		{"outside the window", config.CooldownConfig{WatchedDays: 1, WatchedPenalty: 0.3}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cooldowns := cooldown.NewManager(repository.NewCooldownRepository(db), repository.NewHistoryRepository(db), watchRepo, &tt.cfg, logger)
+			g := NewGenerator(nil, nil, cooldowns, nil, nil, nil, nil, &config.GenerationConfig{}, logger)
+
+			opts := g.candidateOptions(ctx)
+			if excluded := slices.Contains(opts.ExcludeIDs, heat.ID); excluded != tt.wantExclude {
+				t.Errorf("excluded = %v, want %v (options %+v)", excluded, tt.wantExclude, opts)
+			}
+			if got := opts.Penalties[heat.ID]; got != tt.wantPenalty {
+				t.Errorf("penalty = %v, want %v", got, tt.wantPenalty)
+			}
+		})
+	}
+}
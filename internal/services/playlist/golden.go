@@ -0,0 +1,113 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/services/similarity"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// Golden is the lineup selected for a theme with a fixed seed, saved so later selections
+// can be checked for drift after config, library, or model changes
+type Golden struct {
+	Theme     string       `json:"theme"`
+	Seed      uint64       `json:"seed"`
+	WithLLM   bool         `json:"with_llm"`
+	Limit     int          `json:"limit,omitempty"` // Overrides the theme's max_items when positive
+	CreatedAt time.Time    `json:"created_at"`
+	Items     []GoldenItem `json:"items"`
+}
+
+// GoldenItem is a title of a golden lineup
+type GoldenItem struct {
+	ID    int64   `json:"id"`
+	Title string  `json:"title"`
+	Year  int     `json:"year,omitempty"`
+	Score float64 `json:"score"`
+}
+
+// GoldenDrift compares a new selection to a golden lineup
+type GoldenDrift struct {
+	Theme   string         `json:"theme"`
+	Overlap RankingOverlap `json:"overlap"`
+	// Drift is the share of titles in either lineup that are not in both, from 0 (same
+	// titles) to 1 (no title in common)
+	Drift   float64      `json:"drift"`
+	Added   []GoldenItem `json:"added"`   // Selected now but not in the golden lineup
+	Removed []GoldenItem `json:"removed"` // In the golden lineup but no longer selected
+}
+
+// SelectGolden selects the theme's lineup with seed, ignoring cooldowns and watch history so
+// that only config, library, and model changes move it. Nothing is written.
+func (g *Generator) SelectGolden(ctx context.Context, theme *config.ThemeConfig, seed uint64, withLLM bool, limit int) (*Golden, error) {
+	opts := similarity.CandidateOptions{SkipLLM: !withLLM, Limit: limit, Seed: seed}
+	candidates, err := g.scorer.FindCandidates(ctx, theme, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find candidates: %w", err)
+	}
+
+	golden := &Golden{
+		Theme:     theme.Name,
+		Seed:      seed,
+		WithLLM:   withLLM,
+		Limit:     limit,
+		CreatedAt: time.Now(),
+		Items:     make([]GoldenItem, len(candidates)),
+	}
+	for i, c := range candidates {
+		golden.Items[i] = GoldenItem{ID: c.ID, Title: c.Title, Year: c.Year, Score: c.Score}
+	}
+	return golden, nil
+}
+
+// CheckGolden selects the theme's lineup again as golden was selected and measures how far
+// it drifted
+func (g *Generator) CheckGolden(ctx context.Context, theme *config.ThemeConfig, golden *Golden) (*GoldenDrift, error) {
+	current, err := g.SelectGolden(ctx, theme, golden.Seed, golden.WithLLM, golden.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return goldenDrift(golden, current), nil
+}
+
+// goldenDrift measures the drift of current from golden
+func goldenDrift(golden, current *Golden) *GoldenDrift {
+	drift := &GoldenDrift{
+		Theme:   golden.Theme,
+		Overlap: compareRankings(rankedItems(golden.Items), rankedItems(current.Items)),
+		Added:   missingItems(current.Items, golden.Items),
+		Removed: missingItems(golden.Items, current.Items),
+	}
+	drift.Overlap.A, drift.Overlap.B = "golden", "current"
+	if len(golden.Items) > 0 || len(current.Items) > 0 {
+		drift.Drift = 1 - drift.Overlap.Jaccard
+	}
+	return drift
+}
+
+// rankedItems converts golden items to a ranking for compareRankings
+func rankedItems(items []GoldenItem) []models.MediaWithScore {
+	ranked := make([]models.MediaWithScore, len(items))
+	for i, item := range items {
+		ranked[i] = models.MediaWithScore{Media: models.Media{ID: item.ID}}
+	}
+	return ranked
+}
+
+// missingItems returns the items of a that are not in b, in order
+func missingItems(a, b []GoldenItem) []GoldenItem {
+	in := make(map[int64]bool, len(b))
+	for _, item := range b {
+		in[item.ID] = true
+	}
+	missing := []GoldenItem{}
+	for _, item := range a {
+		if !in[item.ID] {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,93 @@
+package playlist
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/internal/services/similarity"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestCheckGoldenDetectsDrift(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db := newTestDB(t)
+	mediaRepo := repository.NewMediaRepository(db)
+
+	western := func(externalID int64, title string, rating float64) *models.Media {
+		return &models.Media{
+			ExternalID: externalID,
+			Source:     models.MediaSourceRadarr,
+			MediaType:  models.MediaTypeMovie,
+			Title:      title,
+			Genres:     []string{"Western"},
+			IMDBRating: rating,
+			Runtime:    120,
+			HasFile:    true,
+		}
+	}
+	library := []*models.Media{
+		western(1, "Unforgiven", 8.2),
+		western(2, "Rio Bravo", 8.0),
+		western(3, "The Searchers", 7.8),
+		western(4, "Shane", 7.6),
+	}
+	if _, err := mediaRepo.BulkUpsert(ctx, library); err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+
+	scorer := similarity.NewScorer(mediaRepo, nil, nil, nil, nil, nil, logger)
+	g := NewGenerator(nil, scorer, nil, nil, nil, nil, nil, &config.GenerationConfig{}, logger)
+	theme := &config.ThemeConfig{Name: "westerns", Genres: []string{"Western"}, MaxItems: 3}
+
+	// Save the golden lineup as the snapshot command does
+	selected, err := g.SelectGolden(ctx, theme, 42, false, 0)
+	if err != nil {
+		t.Fatalf("SelectGolden() error = %v", err)
+	}
+	if len(selected.Items) == 0 {
+		t.Fatal("SelectGolden() selected no titles")
+	}
+	data, err := json.Marshal(selected)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var golden Golden
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	drift, err := g.CheckGolden(ctx, theme, &golden)
+	if err != nil {
+		t.Fatalf("CheckGolden() error = %v", err)
+	}
+	if drift.Drift != 0 || len(drift.Added)+len(drift.Removed) != 0 {
+		t.Errorf("drift of an unchanged library = %+v, want none", drift)
+	}
+
+	// A golden title leaving the library shows up as removed
+	gone := golden.Items[0]
+	if _, err := db.Exec(ctx, "UPDATE media SET has_file = false WHERE id = $1", gone.ID); err != nil {
+		t.Fatalf("failed to remove %s: %v", gone.Title, err)
+	}
+
+	drift, err = g.CheckGolden(ctx, theme, &golden)
+	if err != nil {
+		t.Fatalf("CheckGolden() error = %v", err)
+	}
+	if drift.Drift <= 0 {
+		t.Errorf("drift = %.3f after %s left the library, want above 0", drift.Drift, gone.Title)
+	}
+	removed := false
+	for _, item := range drift.Removed {
+		removed = removed || item.ID == gone.ID
+	}
+	if !removed {
+		t.Errorf("removed = %+v, want %s", drift.Removed, gone.Title)
+	}
+}
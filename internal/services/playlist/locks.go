@@ -0,0 +1,40 @@
+package playlist
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrChannelBusy is returned when another generation or undo is already writing the channel
+var ErrChannelBusy = errors.New("channel is busy with another generation")
+
+// channelLocks guards channels against concurrent writes, so two runs cannot both apply
+// programming or record cooldowns for the same channel
+type channelLocks struct {
+	mu   sync.Mutex
+	busy map[string]bool
+}
+
+func newChannelLocks() *channelLocks {
+	return &channelLocks{busy: make(map[string]bool)}
+}
+
+// tryLock marks the channel busy, reporting false when it already was
+func (l *channelLocks) tryLock(channelID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.busy[channelID] {
+		return false
+	}
+	l.busy[channelID] = true
+	return true
+}
+
+// unlock releases a channel taken with tryLock
+func (l *channelLocks) unlock(channelID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.busy, channelID)
+}
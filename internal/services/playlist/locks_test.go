@@ -0,0 +1,31 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestGenerateRejectsBusyChannel(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	g := NewGenerator(nil, nil, nil, nil, nil, nil, nil, &config.GenerationConfig{}, logger)
+	theme := &config.ThemeConfig{Name: "sci-fi", ChannelID: "1"}
+
+	if !g.channels.tryLock("1") {
+		t.Fatal("tryLock() on a free channel = false")
+	}
+
+	result := g.Generate(context.Background(), theme, false)
+	if !errors.Is(result.Error, ErrChannelBusy) {
+		t.Errorf("Generate() error = %v, want ErrChannelBusy", result.Error)
+	}
+
+	g.channels.unlock("1")
+	if !g.channels.tryLock("1") {
+		t.Error("tryLock() after unlock = false")
+	}
+}
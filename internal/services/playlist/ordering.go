@@ -0,0 +1,135 @@
+package playlist
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/services/similarity"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// orderStrategy puts a score-sorted lineup, without pinned titles, in play order
+type orderStrategy func(ctx context.Context, g *Generator, theme *config.ThemeConfig, items []models.MediaWithScore) []models.MediaWithScore
+
+// orderStrategies maps ThemeConfig.OrderBy values to strategies
+var orderStrategies = map[string]orderStrategy{
+	"":               orderByScore,
+	"score":          orderByScore,
+	"random":         orderRandomly,
+	"chronological":  orderChronologically,
+	"release":        orderByRelease,
+	"narrative":      orderByNarrative,
+	"double_feature": orderByDoubleFeature,
+}
+
+// order arranges a lineup with the theme's order_by strategy. Pinned titles stay at the top.
+func (g *Generator) order(ctx context.Context, theme *config.ThemeConfig, items []models.MediaWithScore) []models.MediaWithScore {
+	pinned := 0
+	for pinned < len(items) && items[pinned].MatchReason == similarity.PinnedReason {
+		pinned++
+	}
+
+	strategy, ok := orderStrategies[theme.OrderBy]
+	if !ok {
+		strategy = orderByScore
+	}
+
+	ordered := strategy(ctx, g, theme, items[pinned:])
+	return append(items[:pinned:pinned], ordered...)
+}
+
+// orderByScore keeps score order, spacing out similar titles
+func orderByScore(_ context.Context, _ *Generator, theme *config.ThemeConfig, items []models.MediaWithScore) []models.MediaWithScore {
+	return diversify(items, theme)
+}
+
+// orderRandomly shuffles the lineup
+func orderRandomly(_ context.Context, _ *Generator, _ *config.ThemeConfig, items []models.MediaWithScore) []models.MediaWithScore {
+	rand.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+	return items
+}
+
+// orderChronologically sorts the lineup by release year; titles of unknown year go last
+func orderChronologically(_ context.Context, _ *Generator, _ *config.ThemeConfig, items []models.MediaWithScore) []models.MediaWithScore {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Year == 0 || items[j].Year == 0 {
+			return items[j].Year == 0 && items[i].Year != 0
+		}
+		return items[i].Year < items[j].Year
+	})
+	return items
+}
+
+// orderByRelease keeps score order but plays the members of each collection in release
+// order, reusing the positions the collection already holds
+func orderByRelease(_ context.Context, _ *Generator, _ *config.ThemeConfig, items []models.MediaWithScore) []models.MediaWithScore {
+	positions := make(map[int64][]int)
+	for i, item := range items {
+		if id := item.CollectionTMDBID; id > 0 {
+			positions[id] = append(positions[id], i)
+		}
+	}
+
+	result := append([]models.MediaWithScore(nil), items...)
+	for _, idx := range positions {
+		members := make([]models.MediaWithScore, 0, len(idx))
+		for _, i := range idx {
+			members = append(members, items[i])
+		}
+		sort.SliceStable(members, func(a, b int) bool {
+			return members[a].Year < members[b].Year
+		})
+		for n, i := range idx {
+			result[i] = members[n]
+		}
+	}
+	return result
+}
+
+// orderByNarrative lets the LLM arrange the lineup, falling back to score order
+func orderByNarrative(ctx context.Context, g *Generator, theme *config.ThemeConfig, items []models.MediaWithScore) []models.MediaWithScore {
+	if len(items) < 2 {
+		return items
+	}
+
+	if !g.scorer.HasLLM() {
+		return orderByScore(ctx, g, theme, items)
+	}
+
+	ordered, err := g.scorer.NarrativeOrder(ctx, theme, items)
+	if err != nil {
+		g.logger.WarnContext(ctx, "LLM narrative ordering failed, using score order",
+			"theme", theme.Name,
+			"error", err,
+		)
+		similarity.RecordDegradation(ctx, "narrative_order", "score order")
+		return orderByScore(ctx, g, theme, items)
+	}
+	return ordered
+}
+
+// orderByDoubleFeature lets the LLM pair the lineup into double features, falling back to score order
+func orderByDoubleFeature(ctx context.Context, g *Generator, theme *config.ThemeConfig, items []models.MediaWithScore) []models.MediaWithScore {
+	if len(items) < 2 {
+		return items
+	}
+
+	if !g.scorer.HasLLM() {
+		return orderByScore(ctx, g, theme, items)
+	}
+
+	paired, err := g.scorer.DoubleFeatures(ctx, theme, items)
+	if err != nil {
+		g.logger.WarnContext(ctx, "LLM double feature pairing failed, using score order",
+			"theme", theme.Name,
+			"error", err,
+		)
+		similarity.RecordDegradation(ctx, "double_feature", "score order")
+		return orderByScore(ctx, g, theme, items)
+	}
+	return paired
+}
@@ -0,0 +1,54 @@
+package playlist
+
+import (
+	"context"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/services/similarity"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestOrder(t *testing.T) {
+	item := func(id int64, year int, collection int64) models.MediaWithScore {
+		return models.MediaWithScore{Media: models.Media{ID: id, Year: year, CollectionTMDBID: collection}}
+	}
+	pinned := models.MediaWithScore{Media: models.Media{ID: 9, Year: 2020}, MatchReason: similarity.PinnedReason}
+
+	tests := []struct {
+		orderBy string
+		items   []models.MediaWithScore
+		want    []int64
+	}{
+		{
+			orderBy: "chronological",
+			items:   []models.MediaWithScore{pinned, item(1, 1999, 0), item(2, 0, 0), item(3, 1985, 0)},
+			want:    []int64{9, 3, 1, 2},
+		},
+		{
+			orderBy: "release",
+			items:   []models.MediaWithScore{item(3, 2003, 119), item(10, 1995, 0), item(1, 2001, 119), item(2, 2002, 119)},
+			want:    []int64{1, 10, 2, 3},
+		},
+		{
+			orderBy: "score",
+			items:   []models.MediaWithScore{pinned, item(1, 1990, 0), item(2, 1990, 0), item(3, 1975, 0)},
+			want:    []int64{9, 1, 3, 2},
+		},
+	}
+
+	g := &Generator{}
+	for _, tt := range tests {
+		t.Run(tt.orderBy, func(t *testing.T) {
+			got := g.order(context.Background(), &config.ThemeConfig{OrderBy: tt.orderBy}, tt.items)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d items, want %d", len(got), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("item %d = %d, want %d", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package playlist
+
+import (
+	"context"
+	"sync"
+
+	"github.com/geekxflood/program-director/internal/services/similarity"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// reservations tracks the media picked by each theme of one GenerateAll run, so themes
+// generated in parallel cannot schedule the same title on two channels
+type reservations struct {
+	mu    sync.Mutex
+	theme map[int64]string
+}
+
+type reservationsKey struct{}
+
+func newReservations() *reservations {
+	return &reservations{theme: make(map[int64]string)}
+}
+
+// withReservations returns a context whose generations share res
+func withReservations(ctx context.Context, res *reservations) context.Context {
+	return context.WithValue(ctx, reservationsKey{}, res)
+}
+
+// reservationsFrom returns the reservations shared through ctx, or nil
+func reservationsFrom(ctx context.Context) *reservations {
+	res, _ := ctx.Value(reservationsKey{}).(*reservations)
+	return res
+}
+
+// taken returns the media reserved by themes other than themeName
+func (r *reservations) taken(themeName string) []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]int64, 0, len(r.theme))
+	for id, owner := range r.theme {
+		if owner != themeName {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// claim reserves the candidates for themeName, dropping those another theme reserved since
+// they were found. Pinned titles are kept and not reserved, as they also bypass cooldowns.
+func (r *reservations) claim(themeName string, candidates []models.MediaWithScore) []models.MediaWithScore {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	claimed := candidates[:0]
+	for _, c := range candidates {
+		if c.MatchReason != similarity.PinnedReason {
+			if owner, ok := r.theme[c.ID]; ok && owner != themeName {
+				continue
+			}
+			r.theme[c.ID] = themeName
+		}
+		claimed = append(claimed, c)
+	}
+	return claimed
+}
+
+// release drops the reservations of themeName, e.g. before it is retried
+func (r *reservations) release(themeName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, owner := range r.theme {
+		if owner == themeName {
+			delete(r.theme, id)
+		}
+	}
+}
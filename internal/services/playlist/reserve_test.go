@@ -0,0 +1,87 @@
+package playlist
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/services/similarity"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func scored(ids ...int64) []models.MediaWithScore {
+	items := make([]models.MediaWithScore, len(ids))
+	for i, id := range ids {
+		items[i] = models.MediaWithScore{Media: models.Media{ID: id}}
+	}
+	return items
+}
+
+func ids(items []models.MediaWithScore) []int64 {
+	out := make([]int64, len(items))
+	for i := range items {
+		out[i] = items[i].ID
+	}
+	return out
+}
+
+func TestReservationsClaim(t *testing.T) {
+	res := newReservations()
+
+	if got := ids(res.claim("noir", scored(1, 2, 3))); !slices.Equal(got, []int64{1, 2, 3}) {
+		t.Fatalf("claim(noir) = %v, want [1 2 3]", got)
+	}
+
+	// Titles picked by noir are left to it, except pinned ones
+	candidates := scored(2, 4, 3)
+	candidates[2].MatchReason = similarity.PinnedReason
+	if got := ids(res.claim("western", candidates)); !slices.Equal(got, []int64{4, 3}) {
+		t.Errorf("claim(western) = %v, want [4 3]", got)
+	}
+
+	taken := res.taken("western")
+	slices.Sort(taken)
+	if !slices.Equal(taken, []int64{1, 2, 3}) {
+		t.Errorf("taken(western) = %v, want [1 2 3]", taken)
+	}
+
+	// A retried theme gives up its titles first
+	res.release("noir")
+	if got := ids(res.claim("western", scored(1, 2))); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("claim(western) after release(noir) = %v, want [1 2]", got)
+	}
+	if taken := res.taken("noir"); len(taken) != 3 {
+		t.Errorf("taken(noir) = %v, want western's 3 titles", taken)
+	}
+}
+
+func TestReservationsConcurrentThemes(t *testing.T) {
+	res := newReservations()
+
+	// Every theme finds the same titles; each must end up with distinct ones
+	const themes = 8
+	claimed := make([][]int64, themes)
+	var wg sync.WaitGroup
+	for i := range themes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			claimed[i] = ids(res.claim(fmt.Sprintf("theme-%d", i), scored(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)))
+		}()
+	}
+	wg.Wait()
+
+	owner := make(map[int64]int)
+	for i, got := range claimed {
+		for _, id := range got {
+			if j, ok := owner[id]; ok {
+				t.Errorf("media %d scheduled by theme-%d and theme-%d", id, j, i)
+			}
+			owner[id] = i
+		}
+	}
+	if len(owner) != 10 {
+		t.Errorf("claimed %d titles, want all 10", len(owner))
+	}
+}
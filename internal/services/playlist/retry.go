@@ -0,0 +1,102 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// GenerationSummary sums up a GenerateAll run by outcome
+type GenerationSummary struct {
+	Results   []GenerationResult
+	Generated []string // Themes generated, including those that needed retries
+	Retried   []string // Themes that took more than one attempt, whatever the outcome
+	Failed    []string
+	Skipped   []string
+}
+
+// summarize sorts results into a GenerationSummary
+func summarize(results []GenerationResult) *GenerationSummary {
+	summary := &GenerationSummary{
+		Results:   results,
+		Generated: []string{},
+		Retried:   []string{},
+		Failed:    []string{},
+		Skipped:   []string{},
+	}
+	for _, r := range results {
+		if r.Attempts > 1 {
+			summary.Retried = append(summary.Retried, r.ThemeName)
+		}
+		switch r.status() {
+		case "failed":
+			summary.Failed = append(summary.Failed, r.ThemeName)
+		case "skipped":
+			summary.Skipped = append(summary.Skipped, r.ThemeName)
+		default:
+			summary.Generated = append(summary.Generated, r.ThemeName)
+		}
+	}
+	return summary
+}
+
+// status names the outcome of a run: "failed", "skipped", or "generated"
+func (r *GenerationResult) status() string {
+	switch {
+	case r.Error != nil:
+		return "failed"
+	case r.SkipReason != "":
+		return "skipped"
+	default:
+		return "generated"
+	}
+}
+
+// generateWithRetry runs generate, retrying with exponential backoff while it fails transiently
+func (g *Generator) generateWithRetry(ctx context.Context, theme *config.ThemeConfig, dryRun bool) GenerationResult {
+	start := time.Now()
+	delay := g.retryDelay
+
+	for attempt := 1; ; attempt++ {
+		result := g.generate(ctx, theme, dryRun)
+		result.Attempts = attempt
+		result.Duration = time.Since(start)
+
+		if result.Error == nil || attempt > g.retries || !transient(result.Error) {
+			return result
+		}
+
+		g.logger.WarnContext(ctx, "theme generation failed, retrying",
+			"theme", theme.Name,
+			"attempt", attempt,
+			"retry_in", delay,
+			"error", result.Error,
+		)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return result
+		}
+		delay *= 2
+	}
+}
+
+// transient reports whether err looks like a failure a retry may get past: network errors,
+// timeouts, and Tunarr/Ollama 429 or 5xx responses
+func transient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var temp interface{ Temporary() bool }
+	return errors.As(err, &temp) && temp.Temporary()
+}
@@ -0,0 +1,60 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/clients/ollama"
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+)
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"tunarr 503", fmt.Errorf("failed to apply: %w", &tunarr.APIError{StatusCode: 503}), true},
+		{"tunarr 429", &tunarr.APIError{StatusCode: 429}, true},
+		{"tunarr 404", fmt.Errorf("failed to get channel: %w", &tunarr.APIError{StatusCode: 404}), false},
+		{"ollama 500", &ollama.APIError{StatusCode: 500}, true},
+		{"connection refused", &url.Error{Op: "Get", URL: "http://tunarr", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"canceled", &url.Error{Op: "Get", URL: "http://tunarr", Err: context.Canceled}, false},
+		{"plain error", errors.New("no Plex media source found in Tunarr"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transient(tt.err); got != tt.want {
+				t.Errorf("transient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	summary := summarize([]GenerationResult{
+		{ThemeName: "ok", Generated: true, Attempts: 1},
+		{ThemeName: "flaky", Generated: true, Attempts: 2},
+		{ThemeName: "down", Error: errors.New("boom"), Attempts: 3},
+		{ThemeName: "weekday", SkipReason: "not scheduled on Monday"},
+	})
+
+	if want := []string{"ok", "flaky"}; !reflect.DeepEqual(summary.Generated, want) {
+		t.Errorf("Generated = %v, want %v", summary.Generated, want)
+	}
+	if want := []string{"flaky", "down"}; !reflect.DeepEqual(summary.Retried, want) {
+		t.Errorf("Retried = %v, want %v", summary.Retried, want)
+	}
+	if want := []string{"down"}; !reflect.DeepEqual(summary.Failed, want) {
+		t.Errorf("Failed = %v, want %v", summary.Failed, want)
+	}
+	if want := []string{"weekday"}; !reflect.DeepEqual(summary.Skipped, want) {
+		t.Errorf("Skipped = %v, want %v", summary.Skipped, want)
+	}
+}
@@ -0,0 +1,90 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrShuttingDown is returned for generations started after Drain
+var ErrShuttingDown = errors.New("shutting down, not starting new generations")
+
+// commitTimeout bounds applying a lineup to Tunarr and recording its plays, which run to
+// completion even when the generation is canceled meanwhile
+const commitTimeout = 2 * time.Minute
+
+// jobTracker counts running generations so shutdown can wait for them, and cancels the ones
+// still running when it gives up
+type jobTracker struct {
+	mu      sync.Mutex
+	closed  bool
+	running sync.WaitGroup
+
+	abort       context.Context
+	cancelAbort context.CancelFunc
+}
+
+func newJobTracker() *jobTracker {
+	abort, cancel := context.WithCancel(context.Background())
+	return &jobTracker{abort: abort, cancelAbort: cancel}
+}
+
+// start registers a generation, returning a context that is also canceled when a drain
+// times out and a function to call when it ends. It reports false once draining started.
+func (t *jobTracker) start(ctx context.Context) (context.Context, func(), bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ctx, func() {}, false
+	}
+	t.running.Add(1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(t.abort, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		t.running.Done()
+	}, true
+}
+
+// drain refuses new generations and waits for the running ones until ctx is done, then
+// cancels them and waits for them to return
+func (t *jobTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		t.cancelAbort()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Drain stops new generations from starting, with ErrShuttingDown, and waits for the
+// running ones until ctx is done. Generations still running then are canceled; one already
+// applying its lineup finishes applying and recording plays first, so cooldowns are never
+// recorded for a lineup that was not applied, nor skipped for one that was.
+func (g *Generator) Drain(ctx context.Context) error {
+	start := time.Now()
+	g.logger.InfoContext(ctx, "waiting for running generations")
+
+	if err := g.jobs.drain(ctx); err != nil {
+		g.logger.WarnContext(ctx, "canceled generations still running at shutdown", "waited", time.Since(start))
+		return err
+	}
+	g.logger.InfoContext(ctx, "running generations finished", "waited", time.Since(start))
+	return nil
+}
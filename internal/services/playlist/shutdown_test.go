@@ -0,0 +1,75 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestJobTrackerDrain(t *testing.T) {
+	jobs := newJobTracker()
+
+	_, end, ok := jobs.start(context.Background())
+	if !ok {
+		t.Fatal("start() before drain = false")
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		end()
+	}()
+
+	if err := jobs.drain(context.Background()); err != nil {
+		t.Errorf("drain() error = %v, want nil once the job ended", err)
+	}
+	if _, _, ok := jobs.start(context.Background()); ok {
+		t.Error("start() after drain = true")
+	}
+}
+
+func TestJobTrackerDrainTimeout(t *testing.T) {
+	jobs := newJobTracker()
+
+	jobCtx, end, ok := jobs.start(context.Background())
+	if !ok {
+		t.Fatal("start() before drain = false")
+	}
+	go func() {
+		<-jobCtx.Done()
+		end()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := jobs.drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("drain() error = %v, want DeadlineExceeded", err)
+	}
+	if jobCtx.Err() == nil {
+		t.Error("job context not canceled after the drain timed out")
+	}
+}
+
+func TestGenerateAfterDrain(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	g := NewGenerator(nil, nil, nil, nil, nil, nil, nil, &config.GenerationConfig{}, logger)
+
+	if err := g.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	theme := config.ThemeConfig{Name: "sci-fi", ChannelID: "1"}
+	if result := g.Generate(context.Background(), &theme, false); !errors.Is(result.Error, ErrShuttingDown) {
+		t.Errorf("Generate() error = %v, want ErrShuttingDown", result.Error)
+	}
+	summary, err := g.GenerateAll(context.Background(), []config.ThemeConfig{theme}, false, true)
+	if !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("GenerateAll() error = %v, want ErrShuttingDown", err)
+	}
+	if len(summary.Results) != 0 {
+		t.Errorf("GenerateAll() results = %d, want 0", len(summary.Results))
+	}
+}
@@ -0,0 +1,106 @@
+package playlist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// ErrNoSnapshot is returned by Undo when a channel has no lineup left to restore
+var ErrNoSnapshot = errors.New("no snapshot available for channel")
+
+// snapshotChannel persists the channel's current Tunarr lineup before it is overwritten by
+// the lineup with the given hash
+func (g *Generator) snapshotChannel(ctx context.Context, theme *config.ThemeConfig, lineupHash string) error {
+	if g.snapshots == nil {
+		return nil
+	}
+
+	current, err := g.tunarr.GetProgramming(ctx, theme.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("failed to marshal programming: %w", err)
+	}
+
+	snapshot := &models.ChannelSnapshot{
+		ChannelID:    theme.ChannelID,
+		ThemeName:    theme.Name,
+		Programming:  data,
+		ProgramCount: len(current.Programs),
+		LineupHash:   lineupHash,
+	}
+	if err := g.snapshots.Create(ctx, snapshot); err != nil {
+		return err
+	}
+
+	g.logger.DebugContext(ctx, "channel snapshot stored",
+		"channel_id", theme.ChannelID,
+		"snapshot_id", snapshot.ID,
+		"programs", snapshot.ProgramCount,
+	)
+
+	return nil
+}
+
+// Undo restores the most recent snapshot taken for a channel and marks it as restored.
+// It fails with ErrChannelBusy while a generation is writing the channel.
+func (g *Generator) Undo(ctx context.Context, channelID string) (*models.ChannelSnapshot, error) {
+	if g.snapshots == nil {
+		return nil, ErrNoSnapshot
+	}
+
+	if !g.channels.tryLock(channelID) {
+		return nil, ErrChannelBusy
+	}
+	defer g.channels.unlock(channelID)
+
+	return g.restoreLatest(ctx, channelID)
+}
+
+// restoreLatest restores the most recent snapshot of a channel and marks it as restored. The
+// caller holds the channel's lock.
+func (g *Generator) restoreLatest(ctx context.Context, channelID string) (*models.ChannelSnapshot, error) {
+	if g.snapshots == nil {
+		return nil, ErrNoSnapshot
+	}
+
+	snapshot, err := g.snapshots.GetLatestUnrestored(ctx, channelID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoSnapshot
+		}
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	var programming tunarr.Programming
+	if err := json.Unmarshal(snapshot.Programming, &programming); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %d: %w", snapshot.ID, err)
+	}
+
+	if err := g.tunarr.SetProgramming(ctx, channelID, &programming); err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot %d: %w", snapshot.ID, err)
+	}
+
+	if err := g.snapshots.MarkRestored(ctx, snapshot.ID); err != nil {
+		return nil, fmt.Errorf("failed to mark snapshot %d restored: %w", snapshot.ID, err)
+	}
+
+	g.logger.InfoContext(ctx, "channel lineup restored",
+		"channel_id", channelID,
+		"snapshot_id", snapshot.ID,
+		"programs", snapshot.ProgramCount,
+		"snapshot_at", snapshot.CreatedAt,
+	)
+
+	return snapshot, nil
+}
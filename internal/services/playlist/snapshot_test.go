@@ -0,0 +1,134 @@
+package playlist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/internal/database/repository"
+)
+
+// newTestDB returns a migrated SQLite database in a temporary directory
+func newTestDB(t *testing.T) database.DB {
+	t.Helper()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := database.New(ctx, &config.DatabaseConfig{Driver: "sqlite", SQLite: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")}}, logger)
+	if err != nil {
+		t.Fatalf("database.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return db
+}
+
+// fakeTunarr serves one channel with a Plex media source, keeping the programming posted to it
+type fakeTunarr struct {
+	mu          sync.Mutex
+	programming tunarr.Programming
+}
+
+func newFakeTunarr(t *testing.T, channelID string, programs ...tunarr.Program) (*fakeTunarr, *tunarr.Client) {
+	t.Helper()
+	fake := &fakeTunarr{programming: tunarr.Programming{Type: "manual", Programs: programs}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/channels/"+channelID, func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(tunarr.Channel{ID: channelID, Name: "Test Channel"})
+	})
+	mux.HandleFunc("GET /api/media-sources", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]tunarr.MediaSource{{ID: "plex-1", Type: "plex"}})
+	})
+	mux.HandleFunc("GET /api/channels/"+channelID+"/programming", func(w http.ResponseWriter, _ *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(fake.programming)
+	})
+	mux.HandleFunc("POST /api/channels/"+channelID+"/programming", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		if err := json.NewDecoder(r.Body).Decode(&fake.programming); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return fake, tunarr.New(&config.TunarrConfig{URL: server.URL})
+}
+
+func (f *fakeTunarr) titles() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	titles := make([]string, len(f.programming.Programs))
+	for i, p := range f.programming.Programs {
+		titles[i] = p.Title
+	}
+	return titles
+}
+
+func TestApplySnapshotsChannelAndUndoRestoresIt(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	snapshots := repository.NewSnapshotRepository(newTestDB(t))
+
+	fake, client := newFakeTunarr(t, "ch-1",
+		tunarr.Program{Type: "content", Title: "Alien", Duration: 7000000},
+		tunarr.Program{Type: "content", Title: "Aliens", Duration: 8000000},
+	)
+	g := NewGenerator(client, nil, nil, snapshots, nil, nil, nil, &config.GenerationConfig{}, logger)
+	theme := &config.ThemeConfig{Name: "sci-fi", ChannelID: "ch-1"}
+
+	programs := []tunarr.Program{{Type: "content", Title: "Solaris", Duration: 9000000}}
+	if _, err := g.applyToTunarr(ctx, theme, programs); err != nil {
+		t.Fatalf("applyToTunarr() error = %v", err)
+	}
+	if got := fake.titles(); len(got) != 1 || got[0] != "Solaris" {
+		t.Fatalf("channel programs after apply = %v, want [Solaris]", got)
+	}
+
+	snapshot, err := snapshots.GetLatestUnrestored(ctx, "ch-1")
+	if err != nil {
+		t.Fatalf("GetLatestUnrestored() error = %v", err)
+	}
+	if snapshot.ThemeName != "sci-fi" || snapshot.ProgramCount != 2 || snapshot.LineupHash != lineupHash(programs) {
+		t.Errorf("snapshot = %+v, want the 2 programs replaced by the sci-fi lineup", snapshot)
+	}
+
+	restored, err := g.Undo(ctx, "ch-1")
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if restored.ID != snapshot.ID {
+		t.Errorf("Undo() restored snapshot %d, want %d", restored.ID, snapshot.ID)
+	}
+	if got := fake.titles(); len(got) != 2 || got[0] != "Alien" || got[1] != "Aliens" {
+		t.Errorf("channel programs after undo = %v, want [Alien Aliens]", got)
+	}
+
+	stored, err := snapshots.List(ctx, repository.ListSnapshotOptions{ChannelID: "ch-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(stored) != 1 || stored[0].RestoredAt == nil {
+		t.Errorf("snapshots after undo = %+v, want the one snapshot marked restored", stored)
+	}
+
+	if _, err := g.Undo(ctx, "ch-1"); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("second Undo() error = %v, want ErrNoSnapshot", err)
+	}
+}
@@ -0,0 +1,57 @@
+package playlist
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// unchangedReason reports why applying programs would only churn the channel, or "" when
+// they should be applied. The lineup is unchanged when it hashes the same as the one applied
+// over the latest unrestored snapshot, or changes less than the theme's min_change.
+func (g *Generator) unchangedReason(ctx context.Context, theme *config.ThemeConfig, programs []tunarr.Program, items []models.MediaWithScore) string {
+	if g.snapshots != nil {
+		last, err := g.snapshots.GetLatestUnrestored(ctx, theme.ChannelID)
+		switch {
+		case err == nil && last.LineupHash == lineupHash(programs):
+			return "lineup unchanged since last apply"
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			g.logger.WarnContext(ctx, "failed to get last snapshot", "channel_id", theme.ChannelID, "error", err)
+		}
+	}
+
+	if theme.MinChange <= 0 {
+		return ""
+	}
+
+	current, err := g.tunarr.GetProgramming(ctx, theme.ChannelID)
+	if err != nil {
+		g.logger.WarnContext(ctx, "failed to get current programming", "channel_id", theme.ChannelID, "error", err)
+		return ""
+	}
+	if ratio := diffLineup(current.Programs, items).ChangeRatio(); ratio < theme.MinChange {
+		return fmt.Sprintf("only %.0f%% of lineup changed (min_change %.0f%%)", ratio*100, theme.MinChange*100)
+	}
+	return ""
+}
+
+// lineupHash fingerprints the order and content of programs. Flex durations are left out
+// since they depend on when the lineup is applied.
+func lineupHash(programs []tunarr.Program) string {
+	h := sha256.New()
+	for _, p := range programs {
+		if p.Type != "content" {
+			fmt.Fprintf(h, "%s\n", p.Type)
+			continue
+		}
+		fmt.Fprintf(h, "%s|%s|%s|%d\n", p.Type, p.PlexFilePath, p.Title, p.Year)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,47 @@
+package playlist
+
+import (
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/clients/tunarr"
+)
+
+func TestLineupHash(t *testing.T) {
+	alien := tunarr.Program{Type: "content", Title: "Alien", Year: 1979, PlexFilePath: "/m/alien.mkv", Duration: 7020000}
+	heat := tunarr.Program{Type: "content", Title: "Heat", Year: 1995, PlexFilePath: "/m/heat.mkv", Duration: 10200000}
+	flex := func(ms int64) tunarr.Program { return tunarr.Program{Type: "flex", Duration: ms} }
+
+	base := lineupHash([]tunarr.Program{alien, flex(60000), heat})
+
+	if got := lineupHash([]tunarr.Program{alien, flex(120000), heat}); got != base {
+		t.Error("flex duration should not change the hash")
+	}
+	if got := lineupHash([]tunarr.Program{heat, flex(60000), alien}); got == base {
+		t.Error("reordered lineup should change the hash")
+	}
+	if got := lineupHash([]tunarr.Program{alien, heat}); got == base {
+		t.Error("dropped flex should change the hash")
+	}
+}
+
+func TestChangeRatio(t *testing.T) {
+	entries := func(n int) []DiffEntry { return make([]DiffEntry, n) }
+
+	tests := []struct {
+		name string
+		diff LineupDiff
+		want float64
+	}{
+		{"identical", LineupDiff{Kept: entries(4)}, 0},
+		{"all new", LineupDiff{Added: entries(3), Removed: entries(3)}, 1},
+		{"one swapped", LineupDiff{Added: entries(1), Removed: entries(1), Kept: entries(3)}, 0.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.diff.ChangeRatio(); got != tt.want {
+				t.Errorf("ChangeRatio() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
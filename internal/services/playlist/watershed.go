@@ -0,0 +1,143 @@
+package playlist
+
+import (
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// day is the period a watershed repeats on
+const day = 24 * time.Hour
+
+// slot is one entry of a channel lineup: a title, or flex (filler) time when item is nil
+type slot struct {
+	item *models.MediaWithScore
+	flex time.Duration
+}
+
+// itemSlots builds a lineup that plays items in order
+func itemSlots(items []models.MediaWithScore) []slot {
+	slots := make([]slot, 0, len(items))
+	for i := range items {
+		slots = append(slots, slot{item: &items[i]})
+	}
+	return slots
+}
+
+// slotItems returns the titles of a lineup in play order
+func slotItems(slots []slot) []models.MediaWithScore {
+	items := make([]models.MediaWithScore, 0, len(slots))
+	for _, s := range slots {
+		if s.item != nil {
+			items = append(items, *s.item)
+		}
+	}
+	return items
+}
+
+// watershed is a parsed WatershedConfig
+type watershed struct {
+	start    time.Duration // offset of the quiet window from midnight
+	length   time.Duration
+	maxLevel int
+}
+
+// newWatershed parses a validated WatershedConfig
+func newWatershed(cfg *config.WatershedConfig) watershed {
+	start, _ := time.Parse("15:04", cfg.Start)
+	end, _ := time.Parse("15:04", cfg.End)
+
+	rating := cfg.MaxContentRating
+	if rating == "" {
+		rating = config.DefaultWatershedRating
+	}
+	maxLevel, _ := models.CertificationLevel(rating)
+
+	length := end.Sub(start)
+	if length < 0 {
+		length += day
+	}
+
+	return watershed{
+		start:    time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		length:   length,
+		maxLevel: maxLevel,
+	}
+}
+
+// window returns the quiet window containing t, or the next one after t
+func (w watershed) window(t time.Time) (time.Time, time.Time) {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for day := -1; ; day++ {
+		start := midnight.AddDate(0, 0, day).Add(w.start)
+		if end := start.Add(w.length); end.After(t) {
+			return start, end
+		}
+	}
+}
+
+// familySafe reports whether a title may air during quiet hours. Unrated titles may not.
+func (w watershed) familySafe(m *models.Media) bool {
+	level, ok := models.CertificationLevel(m.Certification)
+	return ok && level <= w.maxLevel
+}
+
+// fits reports whether a title starting at t may air without entering quiet hours
+func (w watershed) fits(m *models.Media, t time.Time) bool {
+	if w.familySafe(m) {
+		return true
+	}
+	start, _ := w.window(t)
+	return !start.Before(t.Add(runtime(m)))
+}
+
+// daypart orders items for a lineup starting at start so that titles rated above the
+// watershed never air during quiet hours. Each slot takes the best-scored remaining title
+// that fits; when only mature titles remain, flex time fills the rest of the quiet window.
+// Mature titles longer than the gap between quiet windows are dropped. The lineup is
+// padded to whole days so the watershed still holds when Tunarr loops it.
+func daypart(items []models.MediaWithScore, cfg *config.WatershedConfig, start time.Time) []slot {
+	w := newWatershed(cfg)
+	remaining := make([]*models.MediaWithScore, 0, len(items))
+	for i := range items {
+		if w.familySafe(&items[i].Media) || runtime(&items[i].Media) <= day-w.length {
+			remaining = append(remaining, &items[i])
+		}
+	}
+
+	slots := make([]slot, 0, len(items)+1)
+	clock := start
+	for len(remaining) > 0 {
+		picked := -1
+		for i, item := range remaining {
+			if w.fits(&item.Media, clock) {
+				picked = i
+				break
+			}
+		}
+
+		if picked < 0 {
+			_, end := w.window(clock)
+			slots = append(slots, slot{flex: end.Sub(clock)})
+			clock = end
+			continue
+		}
+
+		item := remaining[picked]
+		remaining = append(remaining[:picked], remaining[picked+1:]...)
+		slots = append(slots, slot{item: item})
+		clock = clock.Add(runtime(&item.Media))
+	}
+
+	if rem := clock.Sub(start) % day; rem > 0 {
+		slots = append(slots, slot{flex: day - rem})
+	}
+
+	return slots
+}
+
+// runtime returns a title's runtime as a duration
+func runtime(m *models.Media) time.Duration {
+	return time.Duration(m.Runtime) * time.Minute
+}
@@ -0,0 +1,62 @@
+package playlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestDaypart(t *testing.T) {
+	item := func(id int64, certification string, runtime int) models.MediaWithScore {
+		return models.MediaWithScore{Media: models.Media{ID: id, Certification: certification, Runtime: runtime}}
+	}
+
+	// Quiet hours 06:00-21:00, lineup applied at 19:00
+	cfg := &config.WatershedConfig{Start: "06:00", End: "21:00", MaxContentRating: "PG"}
+	start := time.Date(2025, 10, 31, 19, 0, 0, 0, time.UTC)
+
+	items := []models.MediaWithScore{
+		item(1, "R", 120),     // best scored, but would air 19:00-21:00
+		item(2, "PG", 90),     // 19:00-20:30
+		item(3, "TV-MA", 30),  // 20:30-21:00 overlaps quiet hours, waits
+		item(4, "G", 60),      // 20:30-21:30
+		item(5, "R", 600),     // mature and longer than the 9h night, dropped
+		item(6, "NC-17", 480), // 23:30-07:30 would run into quiet hours, waits for the next night
+	}
+
+	slots := daypart(items, cfg, start)
+
+	var got []int64
+	clock := start
+	for _, s := range slots {
+		if s.item == nil {
+			clock = clock.Add(s.flex)
+			got = append(got, 0)
+			continue
+		}
+		if s.item.Certification != "PG" && s.item.Certification != "G" {
+			if h := clock.Hour(); h >= 6 && h < 21 {
+				t.Errorf("item %d (%s) starts during quiet hours at %s", s.item.ID, s.item.Certification, clock.Format("15:04"))
+			}
+		}
+		got = append(got, s.item.ID)
+		clock = clock.Add(time.Duration(s.item.Runtime) * time.Minute)
+	}
+
+	// 19:00 PG, 20:30 G, 21:30 R, 23:30 TV-MA, 00:00 flex to 21:00, 21:00 NC-17, then pad to whole days
+	want := []int64{2, 4, 1, 3, 0, 6, 0}
+	if len(got) != len(want) {
+		t.Fatalf("lineup = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("lineup = %v, want %v", got, want)
+		}
+	}
+
+	if total := clock.Sub(start); total%day != 0 {
+		t.Errorf("lineup length %s is not a whole number of days", total)
+	}
+}
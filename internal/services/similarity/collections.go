@@ -0,0 +1,144 @@
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// Collection modes for ThemeConfig.Collections
+const (
+	collectionsGroup      = "group"
+	collectionsPrioritize = "prioritize"
+)
+
+// collectionBoost is added to the score of collection members when a theme prioritizes collections
+const collectionBoost = 0.3
+
+// boostCollections raises the score of candidates that belong to a collection
+func boostCollections(candidates []models.MediaWithScore) {
+	for i := range candidates {
+		if candidates[i].CollectionTMDBID > 0 {
+			candidates[i].Score += collectionBoost
+			candidates[i].MatchReason += " (collection)"
+		}
+	}
+}
+
+// groupCollections pulls in the remaining library members of each picked collection and
+// keeps every collection together in release order. Candidates must be sorted by score.
+func (s *Scorer) groupCollections(
+	ctx context.Context,
+	theme *config.ThemeConfig,
+	candidates []models.MediaWithScore,
+	excludeIDs []int64,
+	maxItems int,
+) ([]models.MediaWithScore, error) {
+	seen := make(map[int64]bool)
+	var collectionIDs []int64
+	for _, c := range candidates {
+		if id := c.CollectionTMDBID; id > 0 && !seen[id] {
+			seen[id] = true
+			collectionIDs = append(collectionIDs, id)
+		}
+	}
+	if len(collectionIDs) == 0 {
+		return candidates, nil
+	}
+
+	members, err := s.mediaRepo.ListByCollections(ctx, collectionIDs, excludeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Siblings still have to satisfy the theme's filters
+	siblings := members[:0]
+	for _, m := range members {
+		if passesFilters(&m, theme) {
+			siblings = append(siblings, m)
+		}
+	}
+
+	grouped := groupByCollection(candidates, siblings, maxItems)
+
+	s.logger.DebugContext(ctx, "grouped collections",
+		"theme", theme.Name,
+		"collections", len(collectionIDs),
+		"before", len(candidates),
+		"after", len(grouped),
+	)
+
+	return grouped, nil
+}
+
+// groupByCollection walks candidates in order and replaces the first member of each
+// collection with the whole collection sorted by release year. Siblings take the score
+// of the candidate that pulled them in. Collections that do not fit in maxItems are
+// skipped as a whole.
+func groupByCollection(candidates []models.MediaWithScore, siblings []models.Media, maxItems int) []models.MediaWithScore {
+	byCollection := make(map[int64][]models.Media)
+	for _, c := range candidates {
+		if c.CollectionTMDBID > 0 {
+			byCollection[c.CollectionTMDBID] = append(byCollection[c.CollectionTMDBID], c.Media)
+		}
+	}
+	for _, m := range siblings {
+		group := byCollection[m.CollectionTMDBID]
+		if !containsMedia(group, m.ID) {
+			byCollection[m.CollectionTMDBID] = append(group, m)
+		}
+	}
+
+	reasons := make(map[int64]string, len(candidates))
+	for _, c := range candidates {
+		reasons[c.ID] = c.MatchReason
+	}
+
+	result := make([]models.MediaWithScore, 0, maxItems)
+	done := make(map[int64]bool)
+	for _, c := range candidates {
+		if len(result) >= maxItems {
+			break
+		}
+
+		if c.CollectionTMDBID == 0 {
+			result = append(result, c)
+			continue
+		}
+		if done[c.CollectionTMDBID] {
+			continue
+		}
+		done[c.CollectionTMDBID] = true
+
+		group := byCollection[c.CollectionTMDBID]
+		if len(result)+len(group) > maxItems {
+			continue
+		}
+
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Year < group[j].Year
+		})
+		for _, m := range group {
+			reason, ok := reasons[m.ID]
+			if !ok {
+				reason = fmt.Sprintf("Same collection as %s", c.Title)
+			}
+			result = append(result, models.MediaWithScore{Media: m, Score: c.Score, MatchReason: reason})
+		}
+	}
+
+	return result
+}
+
+// containsMedia reports whether media with the given ID is in the slice
+func containsMedia(media []models.Media, id int64) bool {
+	for _, m := range media {
+		if m.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package similarity
+
+import (
+	"testing"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestGroupByCollection(t *testing.T) {
+	movie := func(id int64, title string, year int, collection int64) models.Media {
+		return models.Media{ID: id, Title: title, Year: year, CollectionTMDBID: collection}
+	}
+
+	candidates := []models.MediaWithScore{
+		{Media: movie(2, "The Two Towers", 2002, 119), Score: 0.9},
+		{Media: movie(10, "Heat", 1995, 0), Score: 0.8},
+		{Media: movie(3, "The Return of the King", 2003, 119), Score: 0.7},
+		{Media: movie(20, "Alien", 1979, 8091), Score: 0.6},
+		{Media: movie(11, "Ronin", 1998, 0), Score: 0.5},
+	}
+	siblings := []models.Media{
+		movie(1, "The Fellowship of the Ring", 2001, 119),
+		movie(2, "The Two Towers", 2002, 119),
+		movie(3, "The Return of the King", 2003, 119),
+		movie(21, "Aliens", 1986, 8091),
+		movie(22, "Alien 3", 1992, 8091),
+	}
+
+	got := groupByCollection(candidates, siblings, 5)
+
+	want := []int64{1, 2, 3, 10, 11} // Alien collection does not fit and is skipped whole
+	if len(got) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(got), len(want), got)
+	}
+	for i, id := range want {
+		if got[i].ID != id {
+			t.Errorf("item %d = %d (%s), want %d", i, got[i].ID, got[i].Title, id)
+		}
+	}
+
+	if got[0].Score != 0.9 {
+		t.Errorf("sibling score = %v, want score of the candidate that pulled it in", got[0].Score)
+	}
+	if got[0].MatchReason != "Same collection as The Two Towers" {
+		t.Errorf("sibling reason = %q", got[0].MatchReason)
+	}
+}
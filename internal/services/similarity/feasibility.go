@@ -0,0 +1,110 @@
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+// ThemeWarning is an actionable problem found when checking a theme against the catalog
+type ThemeWarning struct {
+	Check   string `json:"check"` // genres, min_rating, candidates, duration
+	Message string `json:"message"`
+}
+
+// CheckTheme checks a theme against the catalog: genres that match nothing, a min_rating that
+// excludes every genre match, and a target duration the candidates left after opts'
+// exclusions (such as cooldowns) cannot fill. The LLM is never called.
+func (s *Scorer) CheckTheme(ctx context.Context, theme *config.ThemeConfig, opts CandidateOptions) ([]ThemeWarning, error) {
+	var warnings []ThemeWarning
+
+	// Trakt list themes take their candidates from the list instead of genres
+	if theme.TraktList == "" {
+		genreWarnings, err := s.checkGenres(ctx, theme)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, genreWarnings...)
+	}
+
+	opts.SkipLLM = true
+	candidates, err := s.FindCandidates(ctx, theme, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find candidates: %w", err)
+	}
+
+	if len(candidates) == 0 {
+		warnings = append(warnings, ThemeWarning{
+			Check:   "candidates",
+			Message: "no candidates left after filters, blocklist, and cooldowns",
+		})
+		return warnings, nil
+	}
+
+	if theme.Duration > 0 {
+		var runtime int
+		for _, c := range candidates {
+			runtime += c.Runtime
+		}
+		if runtime < theme.Duration {
+			warnings = append(warnings, ThemeWarning{
+				Check: "duration",
+				Message: fmt.Sprintf("target duration %d min is unreachable: %d candidates add up to %d min after cooldowns (raise max_items or widen filters)",
+					theme.Duration, len(candidates), runtime),
+			})
+		}
+	}
+
+	return warnings, nil
+}
+
+// checkGenres reports genres without matches and a min_rating above every genre match
+func (s *Scorer) checkGenres(ctx context.Context, theme *config.ThemeConfig) ([]ThemeWarning, error) {
+	if len(theme.Genres) == 0 {
+		return []ThemeWarning{{Check: "genres", Message: "no genres configured, only pinned or requested titles can be picked"}}, nil
+	}
+
+	mediaTypes := resolveMediaTypes(theme)
+	counts, err := s.mediaRepo.CountByGenre(ctx, mediaTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count genres: %w", err)
+	}
+
+	var warnings []ThemeWarning
+	var matched int
+	for _, genre := range theme.Genres {
+		if counts[strings.ToLower(genre)] == 0 {
+			warnings = append(warnings, ThemeWarning{
+				Check:   "genres",
+				Message: fmt.Sprintf("genre %q matches no available media of the theme's types", genre),
+			})
+			continue
+		}
+		matched++
+	}
+	if matched == 0 || theme.MinRating <= 0 {
+		return warnings, nil
+	}
+
+	// Genre matches come highest rated first, so checking the top of each type is enough
+	var best float64
+	for _, mediaType := range mediaTypes {
+		media, err := s.mediaRepo.ListByGenres(ctx, theme.Genres, mediaType, yearRanges(theme), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list genre matches: %w", err)
+		}
+		if len(media) > 0 {
+			best = max(best, media[0].IMDBRating)
+		}
+	}
+	if best < theme.MinRating {
+		warnings = append(warnings, ThemeWarning{
+			Check:   "min_rating",
+			Message: fmt.Sprintf("min_rating %.1f excludes every genre match (highest rated is %.1f)", theme.MinRating, best),
+		})
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,118 @@
+package similarity
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// newCatalogScorer returns a scorer over a migrated SQLite database holding media
+func newCatalogScorer(t *testing.T, media ...*models.Media) *Scorer {
+	t.Helper()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := database.NewSQLite(ctx, &config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")}, logger)
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	mediaRepo := repository.NewMediaRepository(db)
+	if _, err := mediaRepo.BulkUpsert(ctx, media); err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+	return NewScorer(mediaRepo, nil, nil, nil, nil, nil, logger)
+}
+
+func western(externalID int64, title string, rating float64, runtime int) *models.Media {
+	return &models.Media{
+		ExternalID: externalID,
+		Source:     models.MediaSourceRadarr,
+		MediaType:  models.MediaTypeMovie,
+		Title:      title,
+		Genres:     []string{"Western"},
+		IMDBRating: rating,
+		Runtime:    runtime,
+		HasFile:    true,
+	}
+}
+
+func TestCheckTheme(t *testing.T) {
+	s := newCatalogScorer(t,
+		western(1, "Unforgiven", 8.2, 130),
+		western(2, "Rio Bravo", 8.0, 141),
+	)
+
+	tests := []struct {
+		name    string
+		theme   config.ThemeConfig
+		exclude []int64
+		want    []string // Checks warned about, in order
+	}{
+		{
+			name:  "feasible",
+			theme: config.ThemeConfig{Name: "westerns", Genres: []string{"Western"}, Duration: 240},
+		},
+		{
+			name:  "duration out of reach",
+			theme: config.ThemeConfig{Name: "westerns", Genres: []string{"Western"}, Duration: 600},
+			want:  []string{"duration"},
+		},
+		{
+			name:  "unknown genre",
+			theme: config.ThemeConfig{Name: "westerns", Genres: []string{"Western", "Noir"}},
+			want:  []string{"genres"},
+		},
+		{
+			name:  "min_rating above every match",
+			theme: config.ThemeConfig{Name: "westerns", Genres: []string{"Western"}, MinRating: 9},
+			want:  []string{"min_rating", "candidates"},
+		},
+		{
+			name:    "all on cooldown",
+			theme:   config.ThemeConfig{Name: "westerns", Genres: []string{"Western"}, Duration: 240},
+			exclude: []int64{1, 2},
+			want:    []string{"candidates"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := s.CheckTheme(context.Background(), &tt.theme, CandidateOptions{ExcludeIDs: tt.exclude})
+			if err != nil {
+				t.Fatalf("CheckTheme() error = %v", err)
+			}
+			checks := make([]string, len(warnings))
+			for i, w := range warnings {
+				checks[i] = w.Check
+			}
+			if strings.Join(checks, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("CheckTheme() warned about %v, want %v: %+v", checks, tt.want, warnings)
+			}
+		})
+	}
+}
+
+func TestCheckThemeDurationWarning(t *testing.T) {
+	s := newCatalogScorer(t, western(1, "Unforgiven", 8.2, 130), western(2, "Rio Bravo", 8.0, 141))
+	theme := &config.ThemeConfig{Name: "westerns", Genres: []string{"Western"}, Duration: 600}
+
+	warnings, err := s.CheckTheme(context.Background(), theme, CandidateOptions{})
+	if err != nil {
+		t.Fatalf("CheckTheme() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "600 min is unreachable: 2 candidates add up to 271 min") {
+		t.Errorf("CheckTheme() = %+v, want the 271 of 600 minutes reported", warnings)
+	}
+}
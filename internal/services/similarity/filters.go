@@ -0,0 +1,144 @@
+package similarity
+
+import (
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// passesFilters applies the theme's hard per-item filters. Unlike min_rating, these also
+// apply to titles pulled in by requests and collections.
+func passesFilters(m *models.Media, theme *config.ThemeConfig) bool {
+	if theme.MinResolution > 0 && m.Resolution < theme.MinResolution {
+		return false
+	}
+	if !matchesContentRating(m.Certification, theme) {
+		return false
+	}
+	if containsFold(theme.ExcludeTitles, m.Title) || anyContainsFold(theme.ExcludeGenres, m.Genres) {
+		return false
+	}
+	if theme.MinRuntime > 0 && m.Runtime < theme.MinRuntime {
+		return false
+	}
+	if theme.MaxRuntime > 0 && m.Runtime > theme.MaxRuntime {
+		return false
+	}
+	if years := yearRanges(theme); len(years) > 0 && !inYearRanges(m.Year, years) {
+		return false
+	}
+	if len(theme.Languages) > 0 && !containsFold(theme.Languages, m.OriginalLanguage) {
+		return false
+	}
+	if len(theme.Countries) > 0 && !anyContainsFold(theme.Countries, m.Countries) {
+		return false
+	}
+	return matchesTags(m.Tags, theme)
+}
+
+// matchesContentRating reports whether a certification is within the theme's content rating bounds.
+// Unrated or unknown certifications never match a bounded theme.
+func matchesContentRating(certification string, theme *config.ThemeConfig) bool {
+	if theme.MinContentRating == "" && theme.MaxContentRating == "" {
+		return true
+	}
+
+	level, ok := models.CertificationLevel(certification)
+	if !ok {
+		return false
+	}
+	if minLevel, ok := models.CertificationLevel(theme.MinContentRating); ok && level < minLevel {
+		return false
+	}
+	if maxLevel, ok := models.CertificationLevel(theme.MaxContentRating); ok && level > maxLevel {
+		return false
+	}
+	return true
+}
+
+// matchesTags reports whether media tags satisfy the theme's tags and exclude_tags.
+// Labels are compared case-insensitively.
+func matchesTags(tags models.StringSlice, theme *config.ThemeConfig) bool {
+	if len(theme.Tags) == 0 && len(theme.ExcludeTags) == 0 {
+		return true
+	}
+
+	has := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		has[strings.ToLower(t)] = true
+	}
+
+	for _, t := range theme.ExcludeTags {
+		if has[strings.ToLower(t)] {
+			return false
+		}
+	}
+
+	if len(theme.Tags) == 0 {
+		return true
+	}
+	for _, t := range theme.Tags {
+		if has[strings.ToLower(t)] {
+			return true
+		}
+	}
+	return false
+}
+
+// yearRanges combines a theme's decades with its min_year/max_year bounds.
+// Config validation ensures every decade overlaps the bounds.
+func yearRanges(theme *config.ThemeConfig) []repository.YearRange {
+	bounds := repository.YearRange{Min: theme.MinYear, Max: theme.MaxYear}
+	if len(theme.Decades) == 0 {
+		if bounds.Min == 0 && bounds.Max == 0 {
+			return nil
+		}
+		return []repository.YearRange{bounds}
+	}
+
+	ranges := make([]repository.YearRange, 0, len(theme.Decades))
+	for _, d := range theme.Decades {
+		start, ok := config.ParseDecade(d)
+		if !ok {
+			continue
+		}
+		r := repository.YearRange{Min: max(start, bounds.Min), Max: start + 9}
+		if bounds.Max > 0 {
+			r.Max = min(r.Max, bounds.Max)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// inYearRanges reports whether a year is within any of the ranges
+func inYearRanges(year int, ranges []repository.YearRange) bool {
+	for _, r := range ranges {
+		if r.Contains(year) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyContainsFold reports whether values contains any of candidates, ignoring case
+func anyContainsFold(values []string, candidates []string) bool {
+	for _, c := range candidates {
+		if containsFold(values, c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package similarity
+
+import (
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestMatchesTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    models.StringSlice
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{name: "no filters", tags: nil, want: true},
+		{name: "included tag", tags: models.StringSlice{"kids", "4k"}, include: []string{"kids"}, want: true},
+		{name: "case insensitive", tags: models.StringSlice{"Halloween"}, include: []string{"halloween"}, want: true},
+		{name: "missing included tag", tags: models.StringSlice{"4k"}, include: []string{"kids"}, want: false},
+		{name: "untagged with include", tags: nil, include: []string{"kids"}, want: false},
+		{name: "excluded tag", tags: models.StringSlice{"kids", "scary"}, include: []string{"kids"}, exclude: []string{"scary"}, want: false},
+		{name: "exclude only", tags: models.StringSlice{"4k"}, exclude: []string{"scary"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme := &config.ThemeConfig{Tags: tt.include, ExcludeTags: tt.exclude}
+			if got := matchesTags(tt.tags, theme); got != tt.want {
+				t.Errorf("matchesTags(%v) = %v, want %v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPassesFiltersResolution(t *testing.T) {
+	theme := &config.ThemeConfig{MinResolution: 2160}
+
+	if !passesFilters(&models.Media{Resolution: 2160}, theme) {
+		t.Error("expected 2160p media to pass min_resolution 2160")
+	}
+	if passesFilters(&models.Media{Resolution: 1080}, theme) {
+		t.Error("expected 1080p media to fail min_resolution 2160")
+	}
+	if passesFilters(&models.Media{}, theme) {
+		t.Error("expected media of unknown resolution to fail min_resolution")
+	}
+	if !passesFilters(&models.Media{}, &config.ThemeConfig{}) {
+		t.Error("expected media to pass without filters")
+	}
+}
+
+func TestPassesFiltersRuntime(t *testing.T) {
+	weeknight := &config.ThemeConfig{MinRuntime: 80, MaxRuntime: 150}
+
+	tests := []struct {
+		runtime int
+		want    bool
+	}{
+		{runtime: 0, want: false},
+		{runtime: 22, want: false},
+		{runtime: 80, want: true},
+		{runtime: 150, want: true},
+		{runtime: 210, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := passesFilters(&models.Media{Runtime: tt.runtime}, weeknight); got != tt.want {
+			t.Errorf("passesFilters(runtime %d) = %v, want %v", tt.runtime, got, tt.want)
+		}
+	}
+}
+
+func TestPassesFiltersExcludes(t *testing.T) {
+	theme := &config.ThemeConfig{
+		ExcludeGenres: []string{"horror"},
+		ExcludeTitles: []string{"The Room"},
+	}
+
+	tests := []struct {
+		name  string
+		media models.Media
+		want  bool
+	}{
+		{name: "allowed", media: models.Media{Title: "Heat", Genres: models.StringSlice{"Crime", "Thriller"}}, want: true},
+		{name: "excluded genre", media: models.Media{Title: "Alien", Genres: models.StringSlice{"Horror", "Science Fiction"}}, want: false},
+		{name: "excluded title", media: models.Media{Title: "the room", Genres: models.StringSlice{"Drama"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := passesFilters(&tt.media, theme); got != tt.want {
+				t.Errorf("passesFilters(%s) = %v, want %v", tt.media.Title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesContentRating(t *testing.T) {
+	kids := &config.ThemeConfig{MaxContentRating: "PG"}
+	adults := &config.ThemeConfig{MinContentRating: "R"}
+
+	tests := []struct {
+		name          string
+		certification string
+		theme         *config.ThemeConfig
+		want          bool
+	}{
+		{name: "unbounded theme", certification: "", theme: &config.ThemeConfig{}, want: true},
+		{name: "G on kids channel", certification: "G", theme: kids, want: true},
+		{name: "TV-PG on kids channel", certification: "TV-PG", theme: kids, want: true},
+		{name: "R on kids channel", certification: "R", theme: kids, want: false},
+		{name: "unrated on kids channel", certification: "NR", theme: kids, want: false},
+		{name: "TV-MA on adult channel", certification: "TV-MA", theme: adults, want: true},
+		{name: "PG-13 on adult channel", certification: "PG-13", theme: adults, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesContentRating(tt.certification, tt.theme); got != tt.want {
+				t.Errorf("matchesContentRating(%q) = %v, want %v", tt.certification, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPassesFiltersLanguageAndCountry(t *testing.T) {
+	kdrama := &config.ThemeConfig{Languages: []string{"ko"}, Countries: []string{"kr"}}
+
+	if !passesFilters(&models.Media{OriginalLanguage: "ko", Countries: models.StringSlice{"KR"}}, kdrama) {
+		t.Error("expected Korean media from KR to pass")
+	}
+	if passesFilters(&models.Media{OriginalLanguage: "ja", Countries: models.StringSlice{"KR"}}, kdrama) {
+		t.Error("expected Japanese-language media to fail")
+	}
+	if passesFilters(&models.Media{OriginalLanguage: "ko", Countries: models.StringSlice{"US"}}, kdrama) {
+		t.Error("expected media from another country to fail")
+	}
+	if passesFilters(&models.Media{}, kdrama) {
+		t.Error("expected media without language or country to fail")
+	}
+}
+
+func TestYearRanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		theme config.ThemeConfig
+		pass  []int
+		fail  []int
+	}{
+		{name: "no bounds", theme: config.ThemeConfig{}, pass: []int{1950, 2024}},
+		{name: "min and max", theme: config.ThemeConfig{MinYear: 1980, MaxYear: 1999}, pass: []int{1980, 1999}, fail: []int{1979, 2000}},
+		{name: "decades", theme: config.ThemeConfig{Decades: []string{"1980s", "1970s"}}, pass: []int{1970, 1985, 1989}, fail: []int{1990, 1969}},
+		{name: "decades with bounds", theme: config.ThemeConfig{Decades: []string{"1980s"}, MinYear: 1984}, pass: []int{1984, 1989}, fail: []int{1983, 1990}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, year := range tt.pass {
+				if !passesFilters(&models.Media{Year: year}, &tt.theme) {
+					t.Errorf("expected %d to pass", year)
+				}
+			}
+			for _, year := range tt.fail {
+				if passesFilters(&models.Media{Year: year}, &tt.theme) {
+					t.Errorf("expected %d to fail", year)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package similarity
+
+import (
+	"context"
+	"errors"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// applyListFilters keeps only candidates on the theme's include lists and drops those on its exclude lists
+func (s *Scorer) applyListFilters(ctx context.Context, theme *config.ThemeConfig, candidates []models.MediaWithScore) ([]models.MediaWithScore, error) {
+	if s.listRepo == nil {
+		return nil, errors.New("list repository not configured")
+	}
+
+	var include, exclude map[int64]bool
+	if len(theme.IncludeLists) > 0 {
+		ids, err := s.listRepo.MediaIDs(ctx, theme.IncludeLists)
+		if err != nil {
+			return nil, err
+		}
+		include = idSet(ids)
+	}
+	if len(theme.ExcludeLists) > 0 {
+		ids, err := s.listRepo.MediaIDs(ctx, theme.ExcludeLists)
+		if err != nil {
+			return nil, err
+		}
+		exclude = idSet(ids)
+	}
+
+	filtered := filterByIDSets(candidates, include, exclude)
+
+	s.logger.DebugContext(ctx, "list filter results",
+		"theme", theme.Name,
+		"include_lists", theme.IncludeLists,
+		"exclude_lists", theme.ExcludeLists,
+		"before", len(candidates),
+		"after", len(filtered),
+	)
+
+	return filtered, nil
+}
+
+// filterByIDSets keeps candidates present in include (when non-nil) and absent from exclude
+func filterByIDSets(candidates []models.MediaWithScore, include, exclude map[int64]bool) []models.MediaWithScore {
+	filtered := candidates[:0]
+	for _, c := range candidates {
+		if include != nil && !include[c.ID] {
+			continue
+		}
+		if exclude[c.ID] {
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+func idSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,168 @@
+package similarity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/geekxflood/program-director/internal/clients/ollama"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// NarrativeOrder asks the LLM to arrange a lineup so it tells a story across the evening,
+// e.g. building tension or following a theme's arc. Titles the LLM leaves out keep their
+// relative order at the end.
+func (s *Scorer) NarrativeOrder(ctx context.Context, theme *config.ThemeConfig, items []models.MediaWithScore) ([]models.MediaWithScore, error) {
+	if s.ollama == nil {
+		return nil, errors.New("no LLM configured")
+	}
+
+	systemPrompt := `You are a TV programming assistant that decides the running order of a themed channel.
+You must respond ONLY with valid JSON in this exact format:
+{"order": [3, 1, 2]}
+
+List every item index exactly once, in the order the items should air.
+Only output JSON, no other text.`
+
+	userPrompt := fmt.Sprintf(`Theme: %s
+Description: %s
+
+Lineup:
+%s
+Order the lineup so it flows as a narrative: pacing, mood, and how each title leads into the next. Output JSON only.`,
+		theme.Name,
+		theme.Description,
+		lineupSummary(items),
+	)
+
+	var result struct {
+		Order []int `json:"order"`
+	}
+	err := s.chat(ctx, "narrative_order", []ollama.ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM order: %w", err)
+	}
+
+	return applyOrder(items, result.Order), nil
+}
+
+// DoubleFeatures asks the LLM to pair a lineup into thematically linked double features,
+// such as body-swap comedies or director companion pieces. The lineup is returned pair by
+// pair with the link in each title's MatchReason; unpaired titles follow in their order.
+func (s *Scorer) DoubleFeatures(ctx context.Context, theme *config.ThemeConfig, items []models.MediaWithScore) ([]models.MediaWithScore, error) {
+	if s.ollama == nil {
+		return nil, errors.New("no LLM configured")
+	}
+
+	systemPrompt := `You are a TV programming assistant that builds double features for a themed channel.
+You must respond ONLY with valid JSON in this exact format:
+{
+  "pairs": [
+    {"first": 3, "second": 1, "reason": "body-swap comedies"},
+    {"first": 2, "second": 5, "reason": "director companion pieces"}
+  ]
+}
+
+Pair items that are linked by story, director, era, or idea, and play best back to back.
+Use each item index at most once. Only output JSON, no other text.`
+
+	userPrompt := fmt.Sprintf(`Theme: %s
+Description: %s
+
+Lineup:
+%s
+Pair the lineup into double features. Output JSON only.`,
+		theme.Name,
+		theme.Description,
+		lineupSummary(items),
+	)
+
+	var result struct {
+		Pairs []struct {
+			First  int    `json:"first"`
+			Second int    `json:"second"`
+			Reason string `json:"reason"`
+		} `json:"pairs"`
+	}
+	err := s.chat(ctx, "double_feature", []ollama.ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM pairs: %w", err)
+	}
+
+	pairs := make([]doubleFeature, 0, len(result.Pairs))
+	for _, p := range result.Pairs {
+		pairs = append(pairs, doubleFeature{first: p.First, second: p.Second, reason: p.Reason})
+	}
+	return applyPairs(items, pairs), nil
+}
+
+// doubleFeature is a pair of 1-based lineup indexes and what links them
+type doubleFeature struct {
+	first, second int
+	reason        string
+}
+
+// applyPairs orders items pair by pair, skipping pairs with invalid or already used indexes.
+// Unpaired items follow in their original order.
+func applyPairs(items []models.MediaWithScore, pairs []doubleFeature) []models.MediaWithScore {
+	used := make([]bool, len(items))
+	valid := func(idx int) bool { return idx >= 1 && idx <= len(items) && !used[idx-1] }
+
+	result := make([]models.MediaWithScore, 0, len(items))
+	for _, p := range pairs {
+		if p.first == p.second || !valid(p.first) || !valid(p.second) {
+			continue
+		}
+		for _, idx := range []int{p.first, p.second} {
+			used[idx-1] = true
+			item := items[idx-1]
+			if p.reason != "" {
+				item.MatchReason = "Double feature: " + p.reason
+			}
+			result = append(result, item)
+		}
+	}
+	for i, item := range items {
+		if !used[i] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// lineupSummary lists items as numbered lines for an LLM prompt
+func lineupSummary(items []models.MediaWithScore) string {
+	var lineup strings.Builder
+	for i, item := range items {
+		lineup.WriteString(fmt.Sprintf("%d. \"%s\" (%d) - Genres: %s\n",
+			i+1, item.Title, item.Year, strings.Join(item.Genres, ", ")))
+	}
+	return lineup.String()
+}
+
+// applyOrder arranges items by 1-based indexes, ignoring invalid and repeated ones.
+// Items that are not listed follow in their original order.
+func applyOrder(items []models.MediaWithScore, order []int) []models.MediaWithScore {
+	used := make([]bool, len(items))
+	result := make([]models.MediaWithScore, 0, len(items))
+	for _, idx := range order {
+		if i := idx - 1; i >= 0 && i < len(items) && !used[i] {
+			used[i] = true
+			result = append(result, items[i])
+		}
+	}
+	for i, item := range items {
+		if !used[i] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
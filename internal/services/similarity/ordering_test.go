@@ -0,0 +1,168 @@
+package similarity
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/clients/ollama"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestApplyOrder(t *testing.T) {
+	items := []models.MediaWithScore{
+		{Media: models.Media{ID: 1}},
+		{Media: models.Media{ID: 2}},
+		{Media: models.Media{ID: 3}},
+		{Media: models.Media{ID: 4}},
+	}
+
+	// Out-of-range and repeated indexes are ignored; item 2 was left out and goes last
+	got := applyOrder(items, []int{3, 0, 1, 3, 9, 4})
+
+	want := []int64{3, 1, 4, 2}
+	for i, id := range want {
+		if got[i].ID != id {
+			t.Errorf("item %d = %d, want %d", i, got[i].ID, id)
+		}
+	}
+}
+
+func TestApplyPairs(t *testing.T) {
+	items := []models.MediaWithScore{
+		{Media: models.Media{ID: 1}, MatchReason: "Genre match: 80%"},
+		{Media: models.Media{ID: 2}},
+		{Media: models.Media{ID: 3}},
+		{Media: models.Media{ID: 4}},
+		{Media: models.Media{ID: 5}},
+	}
+
+	got := applyPairs(items, []doubleFeature{
+		{first: 4, second: 1, reason: "body-swap comedies"},
+		{first: 2, second: 4, reason: "reuses 4, skipped"},
+		{first: 3, second: 3, reason: "same item, skipped"},
+		{first: 5, second: 2, reason: "director companion pieces"},
+	})
+
+	want := []int64{4, 1, 5, 2, 3}
+	for i, id := range want {
+		if got[i].ID != id {
+			t.Errorf("item %d = %d, want %d", i, got[i].ID, id)
+		}
+	}
+	if got[1].MatchReason != "Double feature: body-swap comedies" {
+		t.Errorf("paired reason = %q", got[1].MatchReason)
+	}
+	if items[0].MatchReason != "Genre match: 80%" {
+		t.Error("applyPairs modified its input")
+	}
+}
+
+func TestNarrativeOrderReprompts(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []string // content of each LLM answer
+		wantErr bool
+		want    []int64
+	}{
+		{"valid", []string{`{"order": [2, 1]}`}, false, []int64{2, 1}},
+		{"corrected after re-prompt", []string{`{"order": [2, 1`, `{"order": [2, 1]}`}, false, []int64{2, 1}},
+		{"malformed twice", []string{`not json`, `still not json`}, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []ollama.ChatRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req ollama.ChatRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				requests = append(requests, req)
+				answer := tt.answers[min(len(requests), len(tt.answers))-1]
+				json.NewEncoder(w).Encode(ollama.ChatResponse{Message: ollama.ChatMessage{Role: "assistant", Content: answer}})
+			}))
+			defer server.Close()
+
+			client := ollama.New(&config.OllamaConfig{URL: server.URL, Model: "m"})
+			s := NewScorer(nil, client, nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			items := []models.MediaWithScore{{Media: models.Media{ID: 1}}, {Media: models.Media{ID: 2}}}
+
+			got, err := s.NarrativeOrder(context.Background(), &config.ThemeConfig{Name: "noir"}, items)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NarrativeOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(requests) != len(tt.answers) {
+				t.Fatalf("requests = %d, want %d", len(requests), len(tt.answers))
+			}
+			if len(requests) > 1 {
+				// The re-prompt carries the bad answer and the parse error
+				msgs := requests[1].Messages
+				if len(msgs) != 4 || msgs[2].Content != tt.answers[0] || !strings.Contains(msgs[3].Content, "could not be parsed") {
+					t.Errorf("re-prompt messages = %+v", msgs)
+				}
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("item %d = %d, want %d", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestNarrativeOrderFallsBack(t *testing.T) {
+	var tried []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollama.ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		tried = append(tried, req.Model)
+		if req.Model != "fb" {
+			http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(ollama.ChatResponse{Message: ollama.ChatMessage{Role: "assistant", Content: `{"order": [2, 1]}`}})
+	}))
+	defer server.Close()
+
+	client := ollama.New(&config.OllamaConfig{URL: server.URL, Model: "m"})
+	s := NewScorer(nil, client, nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.SetFallbackModels([]string{"fb"})
+	rec := &UsageRecorder{CaptureTraces: true}
+	ctx := WithUsageRecorder(context.Background(), rec)
+	items := []models.MediaWithScore{{Media: models.Media{ID: 1}}, {Media: models.Media{ID: 2}}}
+
+	got, err := s.NarrativeOrder(ctx, &config.ThemeConfig{Name: "noir"}, items)
+	if err != nil {
+		t.Fatalf("NarrativeOrder() error = %v", err)
+	}
+	if strings.Join(tried, ",") != "m,fb" {
+		t.Errorf("models tried = %v, want [m fb]", tried)
+	}
+	if got[0].ID != 2 {
+		t.Errorf("first item = %d, want 2", got[0].ID)
+	}
+	if d := rec.Degraded(); len(d) != 1 || d[0] != "narrative_order: fallback model fb" {
+		t.Errorf("Degraded() = %v", d)
+	}
+
+	// Both calls are traced with their prompt, the failure, and the answer
+	traces := rec.Traces()
+	if len(traces) != 2 {
+		t.Fatalf("Traces() = %d traces, want 2", len(traces))
+	}
+	if traces[0].Model != "m" || traces[0].Error == "" || traces[0].Response != "" {
+		t.Errorf("failed call trace = %+v", traces[0])
+	}
+	if traces[1].Model != "fb" || traces[1].Response != `{"order": [2, 1]}` || len(traces[1].Messages) == 0 {
+		t.Errorf("fallback call trace = %+v", traces[1])
+	}
+}
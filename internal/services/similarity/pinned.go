@@ -0,0 +1,68 @@
+package similarity
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// PinnedReason is the MatchReason of pinned titles
+const PinnedReason = "pinned"
+
+// resolvePinned looks up a theme's pinned entries. Each entry is matched by title first and
+// then, when numeric, by media ID, so a title such as "1917" still resolves to the film.
+// Entries that match nothing are logged and skipped, as are blocked media IDs.
+func (s *Scorer) resolvePinned(ctx context.Context, theme *config.ThemeConfig, blocked []int64) ([]models.MediaWithScore, error) {
+	mediaTypes := resolveMediaTypes(theme)
+	pinned := make([]models.MediaWithScore, 0, len(theme.Pinned))
+	for _, entry := range theme.Pinned {
+		m, err := s.mediaRepo.FindByTitle(ctx, entry, 0, mediaTypes)
+		if errors.Is(err, sql.ErrNoRows) {
+			if id, convErr := strconv.ParseInt(entry, 10, 64); convErr == nil {
+				m, err = s.mediaRepo.GetByID(ctx, id)
+			}
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "pinned media not found", "theme", theme.Name, "entry", entry)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(blocked, m.ID) {
+			s.logger.WarnContext(ctx, "pinned media is blocklisted", "theme", theme.Name, "entry", entry, "media_id", m.ID)
+			continue
+		}
+
+		pinned = append(pinned, models.MediaWithScore{Media: *m, Score: 1.0, MatchReason: PinnedReason})
+	}
+	return pinned, nil
+}
+
+// pinFirst puts pinned titles at the top of the candidates, dropping any duplicates from
+// the rest. Pinned titles count toward maxItems but are never trimmed.
+func pinFirst(candidates, pinned []models.MediaWithScore, maxItems int) []models.MediaWithScore {
+	result := make([]models.MediaWithScore, 0, max(maxItems, len(pinned)))
+	seen := make(map[int64]bool, len(pinned))
+	for _, p := range pinned {
+		if !seen[p.ID] {
+			seen[p.ID] = true
+			result = append(result, p)
+		}
+	}
+
+	for _, c := range candidates {
+		if len(result) >= maxItems {
+			break
+		}
+		if !seen[c.ID] {
+			result = append(result, c)
+		}
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package similarity
+
+import (
+	"testing"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestPinFirst(t *testing.T) {
+	item := func(id int64) models.MediaWithScore {
+		return models.MediaWithScore{Media: models.Media{ID: id}}
+	}
+
+	tests := []struct {
+		name       string
+		candidates []models.MediaWithScore
+		pinned     []models.MediaWithScore
+		maxItems   int
+		want       []int64
+	}{
+		{
+			name:       "pinned lead and count toward max",
+			candidates: []models.MediaWithScore{item(1), item(2), item(3)},
+			pinned:     []models.MediaWithScore{item(9)},
+			maxItems:   3,
+			want:       []int64{9, 1, 2},
+		},
+		{
+			name:       "candidate duplicate dropped",
+			candidates: []models.MediaWithScore{item(1), item(9), item(2)},
+			pinned:     []models.MediaWithScore{item(9)},
+			maxItems:   3,
+			want:       []int64{9, 1, 2},
+		},
+		{
+			name:       "pinned never trimmed",
+			candidates: []models.MediaWithScore{item(1)},
+			pinned:     []models.MediaWithScore{item(7), item(8), item(9)},
+			maxItems:   2,
+			want:       []int64{7, 8, 9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pinFirst(tt.candidates, tt.pinned, tt.maxItems)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d items, want %d", len(got), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("item %d = %d, want %d", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+}
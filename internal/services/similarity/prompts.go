@@ -0,0 +1,125 @@
+package similarity
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// refineResponseFormat asks for the JSON answer refinWithLLM parses. Custom system prompts
+// should include it as {{.ResponseFormat}}.
+const refineResponseFormat = `You must respond ONLY with valid JSON in this exact format:
+{
+  "rankings": [
+    {"index": 1, "score": 0.95, "reason": "brief reason"},
+    {"index": 2, "score": 0.80, "reason": "brief reason"}
+  ]
+}
+
+Score each item from 0.0 to 1.0 based on how well it fits the theme.
+Include ALL items in your rankings.
+Only output JSON, no other text.`
+
+// Built-in refinement prompts, used when no template file is configured
+const (
+	defaultSystemPrompt = `You are a TV programming assistant that selects content for themed channels.
+{{.ResponseFormat}}`
+
+	defaultUserPrompt = `Theme: {{.Theme.Name}}
+Description: {{.Theme.Description}}
+Target genres: {{join .Theme.Genres ", "}}
+Keywords: {{join .Theme.Keywords ", "}}
+
+{{.CandidateList}}
+Rank ALL items by how well they fit this theme. Output JSON only.`
+)
+
+// PromptData is what refinement prompt templates are executed with
+type PromptData struct {
+	Theme          *config.ThemeConfig
+	Candidates     []PromptCandidate
+	CandidateList  string // Candidates formatted as in the built-in prompt
+	ResponseFormat string // The JSON answer format the response must follow
+}
+
+// PromptCandidate is a candidate as seen by prompt templates
+type PromptCandidate struct {
+	Index     int // 1-based number the LLM refers to in its rankings
+	Title     string
+	Year      int
+	MediaType string
+	Genres    []string
+	Rating    float64
+	Overview  string
+}
+
+// promptFuncs are the functions available to prompt templates
+var promptFuncs = template.FuncMap{
+	"join": strings.Join,
+	"truncate": func(n int, s string) string {
+		if len(s) <= n {
+			return s
+		}
+		return s[:n] + "..."
+	},
+}
+
+// newPromptData builds the template data for refining candidates for theme
+func newPromptData(theme *config.ThemeConfig, candidates []models.MediaWithScore) PromptData {
+	data := PromptData{
+		Theme:          theme,
+		Candidates:     make([]PromptCandidate, 0, len(candidates)),
+		ResponseFormat: refineResponseFormat,
+	}
+
+	var list strings.Builder
+	list.WriteString("Media candidates:\n")
+	for i, c := range candidates {
+		data.Candidates = append(data.Candidates, PromptCandidate{
+			Index:     i + 1,
+			Title:     c.Title,
+			Year:      c.Year,
+			MediaType: string(c.MediaType),
+			Genres:    c.Genres,
+			Rating:    c.IMDBRating,
+			Overview:  c.Overview,
+		})
+		list.WriteString(fmt.Sprintf("%d. \"%s\" (%d) - Genres: %s - Rating: %.1f\n",
+			i+1, c.Title, c.Year, strings.Join(c.Genres, ", "), c.IMDBRating))
+		if c.Overview != "" && len(c.Overview) > 200 {
+			list.WriteString(fmt.Sprintf("   %s...\n", c.Overview[:200]))
+		} else if c.Overview != "" {
+			list.WriteString(fmt.Sprintf("   %s\n", c.Overview))
+		}
+	}
+	data.CandidateList = list.String()
+
+	return data
+}
+
+// renderPrompt executes the template in file, or the built-in template when file is empty.
+// Files are read on every call so edits apply to the next generation.
+func renderPrompt(file, builtin string, data PromptData) (string, error) {
+	text, name := builtin, "built-in"
+	if file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		text, name = string(b), file
+	}
+
+	tmpl, err := template.New(name).Funcs(promptFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", name, err)
+	}
+	return out.String(), nil
+}
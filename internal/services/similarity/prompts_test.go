@@ -0,0 +1,90 @@
+package similarity
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	theme := &config.ThemeConfig{Name: "noir", Description: "Shadows", Genres: []string{"Crime", "Mystery"}, Keywords: []string{"detective"}}
+	candidates := []models.MediaWithScore{
+		{Media: models.Media{Title: "The Third Man", Year: 1949, Genres: models.StringSlice{"Thriller"}, IMDBRating: 8.1, Overview: strings.Repeat("x", 210)}},
+		{Media: models.Media{Title: "Laura", Year: 1944, MediaType: models.MediaTypeMovie}},
+	}
+	data := newPromptData(theme, candidates)
+
+	dir := t.TempDir()
+	write := func(name, text string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		builtin string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "built-in user prompt",
+			builtin: defaultUserPrompt,
+			want: "Theme: noir\nDescription: Shadows\nTarget genres: Crime, Mystery\nKeywords: detective\n\n" +
+				"Media candidates:\n" +
+				"1. \"The Third Man\" (1949) - Genres: Thriller - Rating: 8.1\n   " + strings.Repeat("x", 200) + "...\n" +
+				"2. \"Laura\" (1944) - Genres:  - Rating: 0.0\n" +
+				"\nRank ALL items by how well they fit this theme. Output JSON only.",
+		},
+		{
+			name:    "built-in system prompt includes the response format",
+			builtin: defaultSystemPrompt,
+			want:    "You are a TV programming assistant that selects content for themed channels.\n" + refineResponseFormat,
+		},
+		{
+			name: "custom template",
+			file: write("user.tmpl", `Chaîne {{.Theme.Name}}:{{range .Candidates}} {{.Index}}={{.Title}}/{{.MediaType}}/{{truncate 3 .Overview}}{{end}}`),
+			want: "Chaîne noir: 1=The Third Man//xxx... 2=Laura/movie/",
+		},
+		{
+			name:    "syntax error",
+			file:    write("bad.tmpl", `{{.Theme.Name`),
+			wantErr: "invalid prompt template",
+		},
+		{
+			name:    "unknown field",
+			file:    write("field.tmpl", `{{.Theme.Colour}}`),
+			wantErr: "failed to render prompt template",
+		},
+		{
+			name:    "missing file",
+			file:    filepath.Join(dir, "missing.tmpl"),
+			wantErr: "failed to read prompt template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderPrompt(tt.file, tt.builtin, data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("renderPrompt() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renderPrompt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderPrompt() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package similarity
+
+import (
+	"context"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/overseerr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+const (
+	// requestedBoost is added to the score of titles requested through Overseerr
+	requestedBoost = 0.5
+	// defaultRequestedDays is the request window used when a theme doesn't set one
+	defaultRequestedDays = 30
+	// requestedFetchLimit bounds how many recent requests are considered
+	requestedFetchLimit = 200
+)
+
+// applyRequested boosts candidates that were recently requested and downloaded,
+// and adds requested titles that didn't match the genre filter. The returned slice
+// is always usable; on error the input candidates are returned unchanged.
+func (s *Scorer) applyRequested(
+	ctx context.Context,
+	theme *config.ThemeConfig,
+	candidates []models.MediaWithScore,
+	excludeIDs []int64,
+) ([]models.MediaWithScore, error) {
+	if s.overseerr == nil {
+		return candidates, nil
+	}
+
+	requests, err := s.overseerr.GetAvailableRequests(ctx, requestedFetchLimit)
+	if err != nil {
+		return candidates, err
+	}
+
+	ids := requestedProviderIDs(requests, theme.RequestedDays, time.Now())
+	if ids.Empty() {
+		return candidates, nil
+	}
+
+	requested, err := s.mediaRepo.ListByProviderIDs(ctx, ids, excludeIDs)
+	if err != nil {
+		return candidates, err
+	}
+
+	index := make(map[int64]int, len(candidates))
+	for i, c := range candidates {
+		index[c.ID] = i
+	}
+
+	allowedTypes := make(map[models.MediaType]bool)
+	for _, mt := range resolveMediaTypes(theme) {
+		allowedTypes[mt] = true
+	}
+
+	var boosted, added int
+	for _, m := range requested {
+		if i, ok := index[m.ID]; ok {
+			candidates[i].Score += requestedBoost
+			candidates[i].MatchReason += " (requested)"
+			boosted++
+			continue
+		}
+		if !allowedTypes[m.MediaType] {
+			continue
+		}
+		// Scored like genre matches, so min_rating and keywords apply and scores compare
+		scored, ok := s.scoreMedia(m, theme)
+		if !ok {
+			continue
+		}
+		scored.Score += requestedBoost
+		scored.MatchReason = "Recently requested"
+		candidates = append(candidates, scored)
+		added++
+	}
+
+	s.logger.DebugContext(ctx, "applied requested titles",
+		"theme", theme.Name,
+		"requests", len(requests),
+		"boosted", boosted,
+		"added", added,
+	)
+
+	return candidates, nil
+}
+
+// requestedProviderIDs collects TMDB (movie) and TVDB (tv) IDs of available requests within the window
+func requestedProviderIDs(requests []overseerr.Request, days int, now time.Time) repository.ProviderIDs {
+	if days <= 0 {
+		days = defaultRequestedDays
+	}
+	cutoff := now.AddDate(0, 0, -days)
+
+	var ids repository.ProviderIDs
+
+	for _, r := range requests {
+		if !r.IsAvailable() || r.CreatedAt.Before(cutoff) {
+			continue
+		}
+		switch r.Type {
+		case "movie":
+			if r.Media.TMDBID > 0 {
+				ids.TMDB = append(ids.TMDB, r.Media.TMDBID)
+			}
+		case "tv":
+			if r.Media.TVDBID > 0 {
+				ids.TVDB = append(ids.TVDB, r.Media.TVDBID)
+			}
+		}
+	}
+
+	return ids
+}
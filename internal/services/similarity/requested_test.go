@@ -0,0 +1,80 @@
+package similarity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/overseerr"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestRequestedProviderIDs(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	requests := []overseerr.Request{
+		{Type: "movie", CreatedAt: now.AddDate(0, 0, -2), Media: overseerr.RequestMedia{TMDBID: 10, Status: overseerr.MediaStatusAvailable}},
+		{Type: "tv", CreatedAt: now.AddDate(0, 0, -5), Media: overseerr.RequestMedia{TVDBID: 20, Status: overseerr.MediaStatusPartiallyAvailable}},
+		{Type: "movie", CreatedAt: now.AddDate(0, 0, -1), Media: overseerr.RequestMedia{TMDBID: 11, Status: overseerr.MediaStatusProcessing}},
+		{Type: "movie", CreatedAt: now.AddDate(0, 0, -60), Media: overseerr.RequestMedia{TMDBID: 12, Status: overseerr.MediaStatusAvailable}},
+	}
+
+	ids := requestedProviderIDs(requests, 30, now)
+
+	if len(ids.TMDB) != 1 || ids.TMDB[0] != 10 {
+		t.Errorf("expected tmdb IDs [10], got %v", ids.TMDB)
+	}
+	if len(ids.TVDB) != 1 || ids.TVDB[0] != 20 {
+		t.Errorf("expected tvdb IDs [20], got %v", ids.TVDB)
+	}
+}
+
+func TestApplyRequestedScoresAddedTitles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		created := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+		w.Write([]byte(`{"results": [
+			{"id": 1, "type": "movie", "createdAt": "` + created + `", "media": {"tmdbId": 101, "status": 5}},
+			{"id": 2, "type": "movie", "createdAt": "` + created + `", "media": {"tmdbId": 102, "status": 5}}
+		]}`))
+	}))
+	defer server.Close()
+
+	drama := func(externalID, tmdbID int64, title string, rating float64) *models.Media {
+		return &models.Media{
+			ExternalID: externalID,
+			Source:     models.MediaSourceRadarr,
+			MediaType:  models.MediaTypeMovie,
+			Title:      title,
+			Genres:     []string{"Drama"},
+			IMDBRating: rating,
+			TMDBID:     tmdbID,
+			HasFile:    true,
+		}
+	}
+	s := newCatalogScorer(t, drama(1, 101, "The Assassination of Jesse James", 7.5), drama(2, 102, "The Ballad of Buster Scruggs", 6.4))
+	s.overseerr = overseerr.New(&config.OverseerrConfig{URL: server.URL})
+
+	theme := &config.ThemeConfig{Name: "westerns", Genres: []string{"Western"}, Keywords: []string{"Jesse James"}, MinRating: 7}
+	candidates, err := s.applyRequested(context.Background(), theme, nil, nil)
+	if err != nil {
+		t.Fatalf("applyRequested() error = %v", err)
+	}
+
+	// The title below min_rating is left out; the other is scored as a genre match would be
+	if len(candidates) != 1 || candidates[0].TMDBID != 101 {
+		t.Fatalf("applyRequested() = %+v, want only the title rated above min_rating", candidates)
+	}
+	scored, ok := s.scoreMedia(candidates[0].Media, theme)
+	if !ok {
+		t.Fatal("scoreMedia() rejected the added title")
+	}
+	if candidates[0].Score != scored.Score+requestedBoost {
+		t.Errorf("score = %.3f, want the heuristic score %.3f plus the requested boost", candidates[0].Score, scored.Score)
+	}
+	if candidates[0].MatchReason != "Recently requested" {
+		t.Errorf("match reason = %q", candidates[0].MatchReason)
+	}
+}
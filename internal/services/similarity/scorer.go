@@ -6,10 +6,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/geekxflood/program-director/internal/clients/ollama"
+	"github.com/geekxflood/program-director/internal/clients/overseerr"
+	"github.com/geekxflood/program-director/internal/clients/trakt"
 	"github.com/geekxflood/program-director/internal/config"
 	"github.com/geekxflood/program-director/internal/database/repository"
 	"github.com/geekxflood/program-director/pkg/models"
@@ -18,62 +23,196 @@ import (
 // Scorer handles content similarity scoring
 type Scorer struct {
 	mediaRepo *repository.MediaRepository
-	ollama    *ollama.Client
+	ollama    *ollama.Client      // nil when the LLM is disabled
+	fallbacks []*ollama.Client    // Tried in order when ollama fails
+	prompts   config.PromptConfig // llm.prompts, for themes without their own
+	overseerr *overseerr.Client
+	trakt     *trakt.Client
+	listRepo  *repository.ListRepository
+	blocklist *repository.BlocklistRepository
 	logger    *slog.Logger
+	llmLogger *slog.Logger // Ollama requests, prompts, and responses
 }
 
 // NewScorer creates a new Scorer
 func NewScorer(
 	mediaRepo *repository.MediaRepository,
 	ollamaClient *ollama.Client,
+	overseerrClient *overseerr.Client,
+	traktClient *trakt.Client,
+	listRepo *repository.ListRepository,
+	blocklistRepo *repository.BlocklistRepository,
 	logger *slog.Logger,
 ) *Scorer {
 	return &Scorer{
 		mediaRepo: mediaRepo,
 		ollama:    ollamaClient,
+		overseerr: overseerrClient,
+		trakt:     traktClient,
+		listRepo:  listRepo,
+		blocklist: blocklistRepo,
 		logger:    logger,
+		llmLogger: logger,
 	}
 }
 
+// SetLLMLogger logs Ollama requests, and at debug level their prompts and responses, to
+// logger instead of the scorer's own
+func (s *Scorer) SetLLMLogger(logger *slog.Logger) {
+	s.llmLogger = logger
+}
+
+// SetFallbackModels sets the models tried in order, on the primary model's server, when
+// the primary model fails
+func (s *Scorer) SetFallbackModels(models []string) {
+	s.fallbacks = nil
+	if s.ollama == nil {
+		return
+	}
+	for _, model := range models {
+		s.fallbacks = append(s.fallbacks, s.ollama.WithModel(model))
+	}
+}
+
+// SetPrompts sets the refinement prompt templates used by themes without their own
+func (s *Scorer) SetPrompts(prompts config.PromptConfig) {
+	s.prompts = prompts
+}
+
+// HasLLM reports whether the LLM steps are enabled
+func (s *Scorer) HasLLM() bool {
+	return s.ollama != nil
+}
+
+// CandidateOptions carries per-run state used when selecting candidates
+type CandidateOptions struct {
+	ExcludeIDs []int64           // Media that must not be picked (e.g. on cooldown)
+	Penalties  map[int64]float64 // Score penalties by media ID (e.g. recently watched)
+	SkipLLM    bool              // Rank by heuristic scores only
+	Limit      int               // Overrides the theme's max_items when positive
+	Seed       uint64            // Seeds weighted_random selection when non-zero, for repeatable picks
+}
+
 // FindCandidates finds media candidates matching a theme
-func (s *Scorer) FindCandidates(ctx context.Context, theme *config.ThemeConfig, excludeIDs []int64) ([]models.MediaWithScore, error) {
-	// Phase 1: Genre-based filtering
-	candidates, err := s.filterByGenre(ctx, theme, excludeIDs)
+func (s *Scorer) FindCandidates(ctx context.Context, theme *config.ThemeConfig, opts CandidateOptions) ([]models.MediaWithScore, error) {
+	excludeIDs := append(slices.Clone(opts.ExcludeIDs), theme.ExcludeMediaIDs...)
+
+	// Blocklisted media is never picked, not even when pinned
+	blocked, err := s.blockedMediaIDs(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("genre filter failed: %w", err)
+		return nil, fmt.Errorf("blocklist lookup failed: %w", err)
+	}
+	excludeIDs = append(excludeIDs, blocked...)
+
+	// Phase 1: Genre-based filtering, or the theme's Trakt list when one is set
+	var candidates []models.MediaWithScore
+	if theme.TraktList != "" {
+		candidates, err = s.filterByTraktList(ctx, theme, excludeIDs)
+		if err != nil {
+			return nil, fmt.Errorf("trakt list filter failed: %w", err)
+		}
+	} else {
+		candidates, err = s.filterByGenre(ctx, theme, excludeIDs)
+		if err != nil {
+			return nil, fmt.Errorf("genre filter failed: %w", err)
+		}
 	}
 
-	s.logger.Debug("genre filter results",
+	s.logger.DebugContext(ctx, "genre filter results",
 		"theme", theme.Name,
 		"candidates", len(candidates),
 	)
 
-	if len(candidates) == 0 {
+	// Boost or pull in titles the household requested
+	if theme.IncludeRequested {
+		candidates, err = s.applyRequested(ctx, theme, candidates, excludeIDs)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to apply requested titles", "theme", theme.Name, "error", err)
+		}
+	}
+
+	// Restrict to (or drop) titles on imported lists
+	if len(theme.IncludeLists) > 0 || len(theme.ExcludeLists) > 0 {
+		candidates, err = s.applyListFilters(ctx, theme, candidates)
+		if err != nil {
+			return nil, fmt.Errorf("list filter failed: %w", err)
+		}
+	}
+
+	if len(candidates) == 0 && len(theme.Pinned) == 0 {
 		return nil, nil
 	}
 
 	// Phase 2: LLM refinement on top candidates
-	if len(candidates) > 20 && s.ollama != nil {
+	if len(candidates) > 20 && s.ollama != nil && !opts.SkipLLM {
 		refined, err := s.refinWithLLM(ctx, theme, candidates[:minInt(50, len(candidates))])
 		if err != nil {
-			s.logger.Warn("LLM refinement failed, using genre scores",
+			s.logger.WarnContext(ctx, "LLM refinement failed, using genre scores",
 				"error", err,
 			)
+			RecordDegradation(ctx, "refine", "genre/keyword scores only")
 		} else {
 			candidates = refined
 		}
 	}
 
+	// Demote penalized titles such as those the household recently watched
+	if len(opts.Penalties) > 0 {
+		applyPenalties(candidates, opts.Penalties)
+	}
+
+	// Highlight fresh library additions
+	if theme.PreferNewDays > 0 {
+		boostNew(candidates, time.Now().AddDate(0, 0, -theme.PreferNewDays))
+	}
+
+	// Rank franchise collections first
+	if theme.Collections == collectionsPrioritize {
+		boostCollections(candidates)
+	}
+
 	// Sort by score descending
 	sort.Slice(candidates, func(i, j int) bool {
 		return candidates[i].Score > candidates[j].Score
 	})
 
+	// Sample by score instead of taking the top scores, for variety between runs
+	if theme.Selection == selectionWeightedRandom {
+		seed1, seed2 := rand.Uint64(), rand.Uint64()
+		if opts.Seed != 0 {
+			seed1, seed2 = opts.Seed, opts.Seed
+		}
+		weightedShuffle(candidates, theme.SelectionTemperature, rand.New(rand.NewPCG(seed1, seed2)))
+	}
+
 	// Limit results
 	maxItems := theme.MaxItems
 	if maxItems == 0 {
 		maxItems = 20
 	}
+	if opts.Limit > 0 {
+		maxItems = opts.Limit
+	}
+
+	// Keep collections together in release order
+	if theme.Collections == collectionsGroup || theme.Collections == collectionsPrioritize {
+		grouped, err := s.groupCollections(ctx, theme, candidates, excludeIDs, maxItems)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to group collections", "theme", theme.Name, "error", err)
+		} else {
+			candidates = grouped
+		}
+	}
+
+	// Pinned titles always lead, regardless of score or cooldown
+	if len(theme.Pinned) > 0 {
+		pinned, err := s.resolvePinned(ctx, theme, blocked)
+		if err != nil {
+			return nil, fmt.Errorf("pinned lookup failed: %w", err)
+		}
+		candidates = pinFirst(candidates, pinned, maxItems)
+	}
+
 	if len(candidates) > maxItems {
 		candidates = candidates[:maxItems]
 	}
@@ -81,64 +220,96 @@ func (s *Scorer) FindCandidates(ctx context.Context, theme *config.ThemeConfig,
 	return candidates, nil
 }
 
-// filterByGenre performs initial filtering based on genre matching
-func (s *Scorer) filterByGenre(ctx context.Context, theme *config.ThemeConfig, excludeIDs []int64) ([]models.MediaWithScore, error) {
-	var mediaTypes []models.MediaType
-
-	// Determine which media types to include
-	for _, mt := range theme.MediaTypes {
-		switch strings.ToLower(mt) {
-		case "movie", "movies":
-			mediaTypes = append(mediaTypes, models.MediaTypeMovie)
-		case "series", "shows", "tv":
-			mediaTypes = append(mediaTypes, models.MediaTypeSeries)
-		case "anime":
-			mediaTypes = append(mediaTypes, models.MediaTypeAnime)
-		}
+// blockedMediaIDs returns the IDs of globally blocklisted media
+func (s *Scorer) blockedMediaIDs(ctx context.Context) ([]int64, error) {
+	if s.blocklist == nil {
+		return nil, nil
 	}
+	return s.blocklist.BlockedMediaIDs(ctx)
+}
 
-	// If no specific types, include all
-	if len(mediaTypes) == 0 {
-		mediaTypes = []models.MediaType{models.MediaTypeMovie, models.MediaTypeSeries, models.MediaTypeAnime}
-	}
+// filterByGenre performs initial filtering based on genre matching
+func (s *Scorer) filterByGenre(ctx context.Context, theme *config.ThemeConfig, excludeIDs []int64) ([]models.MediaWithScore, error) {
+	mediaTypes := resolveMediaTypes(theme)
+	years := yearRanges(theme)
 
 	var candidates []models.MediaWithScore
 
 	for _, mediaType := range mediaTypes {
 		// Fetch media matching genres
-		media, err := s.mediaRepo.ListByGenres(ctx, theme.Genres, mediaType, excludeIDs)
+		media, err := s.mediaRepo.ListByGenres(ctx, theme.Genres, mediaType, years, excludeIDs)
 		if err != nil {
 			return nil, err
 		}
 
 		for _, m := range media {
-			// Skip if below minimum rating
-			if theme.MinRating > 0 && m.IMDBRating < theme.MinRating {
-				continue
+			if candidate, ok := s.scoreMedia(m, theme); ok {
+				candidates = append(candidates, candidate)
 			}
+		}
+	}
 
-			// Calculate genre score
-			score := s.calculateGenreScore(m.Genres, theme.Genres)
+	return candidates, nil
+}
 
-			// Add keyword bonus
-			if len(theme.Keywords) > 0 {
-				score += s.calculateKeywordScore(m.Title, m.Overview, theme.Keywords)
-			}
+// scoreMedia applies theme filters to a media item and computes its heuristic score.
+// It returns false when the item should not be a candidate.
+func (s *Scorer) scoreMedia(m models.Media, theme *config.ThemeConfig) (models.MediaWithScore, bool) {
+	// Skip if below minimum rating
+	if theme.MinRating > 0 && m.IMDBRating < theme.MinRating {
+		return models.MediaWithScore{}, false
+	}
 
-			// Add rating bonus
-			if m.IMDBRating > 0 {
-				score += m.IMDBRating / 20 // Small bonus for highly rated content
-			}
+	// Skip if failing tag or quality filters
+	if !passesFilters(&m, theme) {
+		return models.MediaWithScore{}, false
+	}
+
+	// Calculate genre score
+	score := s.calculateGenreScore(m.Genres, theme.Genres)
 
-			candidates = append(candidates, models.MediaWithScore{
-				Media:       m,
-				Score:       score,
-				MatchReason: fmt.Sprintf("Genre match: %.0f%%", score*100),
-			})
+	// Add keyword bonus, matching TMDB keywords as well as title and overview
+	if len(theme.Keywords) > 0 {
+		text := m.Overview
+		if len(m.Keywords) > 0 {
+			text += " " + strings.Join(m.Keywords, " ")
 		}
+		score += s.calculateKeywordScore(m.Title, text, theme.Keywords)
 	}
 
-	return candidates, nil
+	// Add rating bonus
+	if m.IMDBRating > 0 {
+		score += m.IMDBRating / 20 // Small bonus for highly rated content
+	}
+
+	return models.MediaWithScore{
+		Media:       m,
+		Score:       score,
+		MatchReason: fmt.Sprintf("Genre match: %.0f%%", score*100),
+	}, true
+}
+
+// resolveMediaTypes determines which media types a theme includes
+func resolveMediaTypes(theme *config.ThemeConfig) []models.MediaType {
+	var mediaTypes []models.MediaType
+
+	for _, mt := range theme.MediaTypes {
+		switch strings.ToLower(mt) {
+		case "movie", "movies":
+			mediaTypes = append(mediaTypes, models.MediaTypeMovie)
+		case "series", "shows", "tv":
+			mediaTypes = append(mediaTypes, models.MediaTypeSeries)
+		case "anime":
+			mediaTypes = append(mediaTypes, models.MediaTypeAnime)
+		}
+	}
+
+	// If no specific types, include all
+	if len(mediaTypes) == 0 {
+		mediaTypes = []models.MediaType{models.MediaTypeMovie, models.MediaTypeSeries, models.MediaTypeAnime}
+	}
+
+	return mediaTypes
 }
 
 // calculateGenreScore calculates how well media genres match theme genres
@@ -183,58 +354,22 @@ func (s *Scorer) calculateKeywordScore(title, overview string, keywords []string
 
 // refinWithLLM uses the LLM to refine and score candidates
 func (s *Scorer) refinWithLLM(ctx context.Context, theme *config.ThemeConfig, candidates []models.MediaWithScore) ([]models.MediaWithScore, error) {
-	// Build media summary for LLM
-	var mediaSummary strings.Builder
-	mediaSummary.WriteString("Media candidates:\n")
-	for i, c := range candidates {
-		mediaSummary.WriteString(fmt.Sprintf("%d. \"%s\" (%d) - Genres: %s - Rating: %.1f\n",
-			i+1, c.Title, c.Year, strings.Join(c.Genres, ", "), c.IMDBRating))
-		if c.Overview != "" && len(c.Overview) > 200 {
-			mediaSummary.WriteString(fmt.Sprintf("   %s...\n", c.Overview[:200]))
-		} else if c.Overview != "" {
-			mediaSummary.WriteString(fmt.Sprintf("   %s\n", c.Overview))
-		}
+	prompts := theme.Prompts.Or(s.prompts)
+	data := newPromptData(theme, candidates)
+	systemPrompt, err := renderPrompt(prompts.System, defaultSystemPrompt, data)
+	if err != nil {
+		return nil, err
+	}
+	userPrompt, err := renderPrompt(prompts.User, defaultUserPrompt, data)
+	if err != nil {
+		return nil, err
 	}
-
-	systemPrompt := `You are a TV programming assistant that selects content for themed channels.
-You must respond ONLY with valid JSON in this exact format:
-{
-  "rankings": [
-    {"index": 1, "score": 0.95, "reason": "brief reason"},
-    {"index": 2, "score": 0.80, "reason": "brief reason"}
-  ]
-}
-
-Score each item from 0.0 to 1.0 based on how well it fits the theme.
-Include ALL items in your rankings.
-Only output JSON, no other text.`
-
-	userPrompt := fmt.Sprintf(`Theme: %s
-Description: %s
-Target genres: %s
-Keywords: %s
-
-%s
-
-Rank ALL items by how well they fit this theme. Output JSON only.`,
-		theme.Name,
-		theme.Description,
-		strings.Join(theme.Genres, ", "),
-		strings.Join(theme.Keywords, ", "),
-		mediaSummary.String(),
-	)
 
 	messages := []ollama.ChatMessage{
 		{Role: "system", Content: systemPrompt},
 		{Role: "user", Content: userPrompt},
 	}
 
-	resp, err := s.ollama.ChatWithJSON(ctx, messages)
-	if err != nil {
-		return nil, err
-	}
-
-	// Parse LLM response
 	var result struct {
 		Rankings []struct {
 			Index  int     `json:"index"`
@@ -242,12 +377,7 @@ Rank ALL items by how well they fit this theme. Output JSON only.`,
 			Reason string  `json:"reason"`
 		} `json:"rankings"`
 	}
-
-	if err := json.Unmarshal([]byte(resp.Message.Content), &result); err != nil {
-		s.logger.Warn("failed to parse LLM response",
-			"error", err,
-			"response", resp.Message.Content,
-		)
+	if err := s.chat(ctx, "refine", messages, &result); err != nil {
 		return nil, err
 	}
 
@@ -266,9 +396,119 @@ Rank ALL items by how well they fit this theme. Output JSON only.`,
 	return candidates, nil
 }
 
+// newMediaBoost is added to the score of media imported within a theme's prefer_new_days
+const newMediaBoost = 0.2
+
+// boostNew raises the score of candidates imported (or first synced) since the given time
+func boostNew(candidates []models.MediaWithScore, since time.Time) {
+	for i := range candidates {
+		if candidates[i].AddedOrCreatedAt().After(since) {
+			candidates[i].Score += newMediaBoost
+			candidates[i].MatchReason += " (new)"
+		}
+	}
+}
+
+// applyPenalties subtracts per-media penalties from candidate scores
+func applyPenalties(candidates []models.MediaWithScore, penalties map[int64]float64) {
+	for i := range candidates {
+		if p, ok := penalties[candidates[i].ID]; ok {
+			candidates[i].Score -= p
+		}
+	}
+}
+
 func minInt(a, b int) int {
 	if a < b {
 		return a
 	}
 	return b
 }
+
+// chat sends messages to the LLM and decodes its JSON answer into v. A malformed answer is
+// sent back once with the parse error so the model can correct it.
+func (s *Scorer) chat(ctx context.Context, purpose string, messages []ollama.ChatMessage, v any) error {
+	resp, err := s.chatOnce(ctx, purpose, messages)
+	if err != nil {
+		return err
+	}
+	parseErr := json.Unmarshal([]byte(resp.Message.Content), v)
+	if parseErr == nil {
+		return nil
+	}
+
+	s.llmLogger.WarnContext(ctx, "malformed LLM response, re-prompting",
+		"purpose", purpose,
+		"error", parseErr,
+		"response", resp.Message.Content,
+	)
+	messages = append(messages[:len(messages):len(messages)],
+		ollama.ChatMessage{Role: "assistant", Content: resp.Message.Content},
+		ollama.ChatMessage{Role: "user", Content: fmt.Sprintf(
+			"Your response could not be parsed: %v. Respond again with only valid JSON in the requested format.", parseErr)},
+	)
+	resp, err = s.chatOnce(ctx, purpose, messages)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(resp.Message.Content), v); err != nil {
+		s.llmLogger.WarnContext(ctx, "failed to parse LLM response",
+			"purpose", purpose,
+			"error", err,
+			"response", resp.Message.Content,
+		)
+		return fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	return nil
+}
+
+// chatOnce sends messages to the LLM, trying the fallback models in order when the primary
+// model fails
+func (s *Scorer) chatOnce(ctx context.Context, purpose string, messages []ollama.ChatMessage) (*ollama.ChatResponse, error) {
+	resp, err := s.chatWith(ctx, s.ollama, purpose, messages)
+	for _, client := range s.fallbacks {
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+		RecordDegradation(ctx, purpose, "fallback model "+client.Model())
+		resp, err = s.chatWith(ctx, client, purpose, messages)
+	}
+	return resp, err
+}
+
+// chatWith sends messages to one model and logs the call with its latency, so a slow
+// generation can be traced down to its Ollama requests
+func (s *Scorer) chatWith(ctx context.Context, client *ollama.Client, purpose string, messages []ollama.ChatMessage) (*ollama.ChatResponse, error) {
+	s.llmLogger.DebugContext(ctx, "ollama prompt",
+		"purpose", purpose,
+		"model", client.Model(),
+		"messages", messages,
+	)
+	start := time.Now()
+	resp, err := client.ChatWithJSON(ctx, messages)
+	recordTrace(ctx, purpose, client.Model(), messages, resp, err, time.Since(start))
+	if err != nil {
+		s.llmLogger.WarnContext(ctx, "ollama request failed",
+			"purpose", purpose,
+			"model", client.Model(),
+			"duration", time.Since(start),
+			"error", err,
+		)
+		return nil, err
+	}
+
+	recordUsage(ctx, purpose, resp)
+
+	s.llmLogger.InfoContext(ctx, "ollama request",
+		"purpose", purpose,
+		"duration", time.Since(start),
+		"prompt_tokens", resp.PromptEvalCount,
+		"eval_tokens", resp.EvalCount,
+	)
+	s.llmLogger.DebugContext(ctx, "ollama response",
+		"purpose", purpose,
+		"model", client.Model(),
+		"response", resp.Message.Content,
+	)
+	return resp, nil
+}
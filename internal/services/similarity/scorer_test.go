@@ -0,0 +1,29 @@
+package similarity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestBoostNew(t *testing.T) {
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	fresh := since.Add(48 * time.Hour)
+	old := since.Add(-48 * time.Hour)
+
+	candidates := []models.MediaWithScore{
+		{Media: models.Media{ID: 1, AddedAt: &fresh, CreatedAt: old}, Score: 0.5},
+		{Media: models.Media{ID: 2, AddedAt: &old, CreatedAt: fresh}, Score: 0.5},
+		{Media: models.Media{ID: 3, CreatedAt: fresh}, Score: 0.5}, // unknown import date falls back to created_at
+	}
+
+	boostNew(candidates, since)
+
+	want := []float64{0.5 + newMediaBoost, 0.5, 0.5 + newMediaBoost}
+	for i, score := range want {
+		if candidates[i].Score != score {
+			t.Errorf("candidate %d score = %v, want %v", candidates[i].ID, candidates[i].Score, score)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package similarity
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// selectionWeightedRandom is the ThemeConfig.Selection mode that samples by score
+const selectionWeightedRandom = "weighted_random"
+
+// defaultSelectionTemperature samples candidates in proportion to their score
+const defaultSelectionTemperature = 1.0
+
+// minSelectionWeight keeps zero and negative scores selectable, if rarely
+const minSelectionWeight = 1e-3
+
+// weightedShuffle orders candidates by weighted random sampling without replacement, so
+// taking the first N samples N candidates. Each candidate weighs score^(1/temperature):
+// 1 samples in proportion to score, lower temperatures favour the best scores more strongly.
+func weightedShuffle(candidates []models.MediaWithScore, temperature float64, rng *rand.Rand) {
+	if temperature <= 0 {
+		temperature = defaultSelectionTemperature
+	}
+
+	// Efraimidis-Spirakis: sort by u^(1/w), compared in log space as log(u)/w
+	keys := make([]float64, len(candidates))
+	for i, c := range candidates {
+		weight := math.Pow(math.Max(c.Score, minSelectionWeight), 1/temperature)
+		keys[i] = math.Log(1-rng.Float64()) / weight
+	}
+
+	sort.Sort(byKey{candidates: candidates, keys: keys})
+}
+
+// byKey sorts candidates by descending key
+type byKey struct {
+	candidates []models.MediaWithScore
+	keys       []float64
+}
+
+func (b byKey) Len() int           { return len(b.candidates) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] > b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.candidates[i], b.candidates[j] = b.candidates[j], b.candidates[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
@@ -0,0 +1,43 @@
+package similarity
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+func TestWeightedShuffle(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	tests := []struct {
+		temperature float64
+		min, max    float64 // expected share of runs where the 0.9 candidate is sampled first
+	}{
+		{temperature: 1, min: 0.85, max: 0.95},  // 0.9 / (0.9 + 0.1)
+		{temperature: 0.5, min: 0.97, max: 1.0}, // 0.81 / (0.81 + 0.01)
+		{temperature: 10, min: 0.50, max: 0.62}, // nearly uniform
+	}
+
+	const runs = 4000
+	for _, tt := range tests {
+		first := 0
+		for range runs {
+			candidates := []models.MediaWithScore{
+				{Media: models.Media{ID: 1}, Score: 0.9},
+				{Media: models.Media{ID: 2}, Score: 0.1},
+			}
+			weightedShuffle(candidates, tt.temperature, rng)
+			if candidates[0].ID == 1 {
+				first++
+			}
+			if candidates[0].ID == candidates[1].ID {
+				t.Fatal("shuffle duplicated a candidate")
+			}
+		}
+
+		if share := float64(first) / runs; share < tt.min || share > tt.max {
+			t.Errorf("temperature %v: best candidate first in %.3f of runs, want %.2f-%.2f", tt.temperature, share, tt.min, tt.max)
+		}
+	}
+}
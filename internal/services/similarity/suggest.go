@@ -0,0 +1,212 @@
+package similarity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/geekxflood/program-director/internal/clients/ollama"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// Limits on what the library summary sent to the LLM includes
+const (
+	suggestTopGenres = 30
+	suggestUnplayed  = 40
+)
+
+// ThemeSuggestion is a theme the LLM proposes for the library. Its fields use the theme
+// config's keys, so it can be saved as a themes_dir file once a channel_id is added.
+type ThemeSuggestion struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	MediaTypes  []string `json:"media_types,omitempty"`
+	Genres      []string `json:"genres"`
+	Keywords    []string `json:"keywords,omitempty"`
+	Schedule    string   `json:"schedule,omitempty"`
+	Reason      string   `json:"reason"` // Why the theme fits the library
+	Titles      int64    `json:"titles"` // Available titles in the suggested genres
+}
+
+// SuggestThemes asks the LLM for up to count new themes, based on the library's genre
+// distribution, the genres existing themes leave uncovered, and titles that have never aired.
+// Suggestions reusing an existing theme's name or naming no genre in the library are dropped.
+func (s *Scorer) SuggestThemes(ctx context.Context, existing []config.ThemeConfig, count int) ([]ThemeSuggestion, error) {
+	if s.ollama == nil {
+		return nil, errors.New("no LLM configured")
+	}
+
+	genreCounts, err := s.mediaRepo.CountByGenre(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count genres: %w", err)
+	}
+	if len(genreCounts) == 0 {
+		return nil, errors.New("the library is empty; run a sync first")
+	}
+	unplayed, err := s.mediaRepo.ListUnplayed(ctx, nil, suggestUnplayed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unplayed media: %w", err)
+	}
+
+	systemPrompt := `You are a TV programming assistant that designs themed channels for a media library.
+You must respond ONLY with valid JSON in this exact format:
+{
+  "themes": [
+    {
+      "name": "kebab-case-name",
+      "description": "one sentence",
+      "media_types": ["movie"],
+      "genres": ["Genre"],
+      "keywords": ["keyword"],
+      "schedule": "0 20 * * 5",
+      "reason": "why this library suits the theme"
+    }
+  ]
+}
+
+media_types are movie, series, or anime. Genres must be taken from the library's genre list.
+schedule is a standard 5-field cron expression for when the channel is regenerated.
+Only output JSON, no other text.`
+
+	userPrompt := fmt.Sprintf(`%s
+%s
+%s
+Suggest %d new themes that put the library's uncovered genres and unplayed titles to use and differ from the existing themes. Output JSON only.`,
+		genreSummary(genreCounts, existing),
+		themesSummary(existing),
+		unplayedSummary(unplayed),
+		count,
+	)
+
+	var result struct {
+		Themes []ThemeSuggestion `json:"themes"`
+	}
+	err = s.chat(ctx, "suggest_themes", []ollama.ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM suggestions: %w", err)
+	}
+
+	suggestions := cleanSuggestions(result.Themes, genreCounts, existing)
+	if len(suggestions) > count {
+		suggestions = suggestions[:count]
+	}
+	return suggestions, nil
+}
+
+// cleanSuggestions drops suggestions that reuse a theme name or name no library genre, and
+// removes genres, media types, and schedules that would not validate
+func cleanSuggestions(suggestions []ThemeSuggestion, genreCounts map[string]int64, existing []config.ThemeConfig) []ThemeSuggestion {
+	names := make(map[string]bool, len(existing)+len(suggestions))
+	for _, t := range existing {
+		names[strings.ToLower(t.Name)] = true
+	}
+
+	out := make([]ThemeSuggestion, 0, len(suggestions))
+	for _, sg := range suggestions {
+		sg.Name = strings.TrimSpace(sg.Name)
+		if sg.Name == "" || names[strings.ToLower(sg.Name)] {
+			continue
+		}
+
+		genres := sg.Genres[:0]
+		var titles int64
+		for _, g := range sg.Genres {
+			if n, ok := genreCounts[strings.ToLower(g)]; ok {
+				genres = append(genres, g)
+				titles = max(titles, n)
+			}
+		}
+		if len(genres) == 0 {
+			continue
+		}
+		sg.Genres = genres
+		sg.Titles = titles
+
+		mediaTypes := sg.MediaTypes[:0]
+		for _, mt := range sg.MediaTypes {
+			switch models.MediaType(strings.ToLower(mt)) {
+			case models.MediaTypeMovie, models.MediaTypeSeries, models.MediaTypeAnime:
+				mediaTypes = append(mediaTypes, strings.ToLower(mt))
+			}
+		}
+		sg.MediaTypes = mediaTypes
+
+		if _, err := cron.ParseStandard(sg.Schedule); err != nil {
+			sg.Schedule = ""
+		}
+
+		names[strings.ToLower(sg.Name)] = true
+		out = append(out, sg)
+	}
+	return out
+}
+
+// genreSummary lists the library's largest genres with their title counts, marking those no
+// existing theme covers
+func genreSummary(genreCounts map[string]int64, existing []config.ThemeConfig) string {
+	covered := make(map[string]bool)
+	for _, t := range existing {
+		for _, g := range t.Genres {
+			covered[strings.ToLower(g)] = true
+		}
+	}
+
+	genres := make([]string, 0, len(genreCounts))
+	for g := range genreCounts {
+		genres = append(genres, g)
+	}
+	sort.Slice(genres, func(i, j int) bool {
+		if genreCounts[genres[i]] != genreCounts[genres[j]] {
+			return genreCounts[genres[i]] > genreCounts[genres[j]]
+		}
+		return genres[i] < genres[j]
+	})
+	if len(genres) > suggestTopGenres {
+		genres = genres[:suggestTopGenres]
+	}
+
+	var b strings.Builder
+	b.WriteString("Library genres (available titles):\n")
+	for _, g := range genres {
+		fmt.Fprintf(&b, "- %s: %d", g, genreCounts[g])
+		if !covered[g] {
+			b.WriteString(" (no theme)")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// themesSummary lists the existing themes and their genres
+func themesSummary(existing []config.ThemeConfig) string {
+	if len(existing) == 0 {
+		return "Existing themes: none\n"
+	}
+	var b strings.Builder
+	b.WriteString("Existing themes:\n")
+	for _, t := range existing {
+		fmt.Fprintf(&b, "- %s: %s\n", t.Name, strings.Join(t.Genres, ", "))
+	}
+	return b.String()
+}
+
+// unplayedSummary lists titles that have never aired
+func unplayedSummary(media []models.Media) string {
+	if len(media) == 0 {
+		return "Unplayed titles: none\n"
+	}
+	var b strings.Builder
+	b.WriteString("Unplayed titles:\n")
+	for _, m := range media {
+		fmt.Fprintf(&b, "- %s (%d) [%s] %s\n", m.Title, m.Year, m.MediaType, strings.Join(m.Genres, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,34 @@
+package similarity
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/geekxflood/program-director/internal/config"
+)
+
+func TestCleanSuggestions(t *testing.T) {
+	genreCounts := map[string]int64{"horror": 40, "comedy": 25, "documentary": 12}
+	existing := []config.ThemeConfig{{Name: "Horror-Night", Genres: []string{"Horror"}}}
+
+	got := cleanSuggestions([]ThemeSuggestion{
+		{Name: "horror-night", Genres: []string{"Comedy"}},
+		{Name: " docs ", Genres: []string{"Documentary", "Cooking"}, MediaTypes: []string{"Movie", "podcast"}, Schedule: "0 20 * * 0"},
+		{Name: "westerns", Genres: []string{"Western"}},
+		{Name: "scary-laughs", Genres: []string{"Horror", "Comedy"}, Schedule: "every friday"},
+		{Name: "Docs", Genres: []string{"Documentary"}},
+	}, genreCounts, existing)
+
+	want := []ThemeSuggestion{
+		{Name: "docs", Genres: []string{"Documentary"}, MediaTypes: []string{"movie"}, Schedule: "0 20 * * 0", Titles: 12},
+		{Name: "scary-laughs", Genres: []string{"Horror", "Comedy"}, Titles: 40},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("cleanSuggestions() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("suggestion %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
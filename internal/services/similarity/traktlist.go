@@ -0,0 +1,86 @@
+package similarity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/geekxflood/program-director/internal/clients/trakt"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// filterByTraktList restricts candidates to the local media that appears on the theme's Trakt list
+func (s *Scorer) filterByTraktList(ctx context.Context, theme *config.ThemeConfig, excludeIDs []int64) ([]models.MediaWithScore, error) {
+	if s.trakt == nil {
+		return nil, errors.New("trakt client not configured")
+	}
+
+	user, slug, err := trakt.ParseListRef(theme.TraktList)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.trakt.GetListItems(ctx, user, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trakt list %s/%s: %w", user, slug, err)
+	}
+
+	ids := traktListProviderIDs(items)
+	media, err := s.mediaRepo.ListByProviderIDs(ctx, ids, excludeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedTypes := make(map[models.MediaType]bool)
+	for _, mt := range resolveMediaTypes(theme) {
+		allowedTypes[mt] = true
+	}
+
+	candidates := make([]models.MediaWithScore, 0, len(media))
+	for _, m := range media {
+		if !allowedTypes[m.MediaType] {
+			continue
+		}
+		candidate, ok := s.scoreMedia(m, theme)
+		if !ok {
+			continue
+		}
+		candidate.MatchReason = fmt.Sprintf("On Trakt list %s/%s", user, slug)
+		candidates = append(candidates, candidate)
+	}
+
+	s.logger.DebugContext(ctx, "trakt list results",
+		"theme", theme.Name,
+		"list", user+"/"+slug,
+		"list_items", len(items),
+		"matched", len(candidates),
+	)
+
+	return candidates, nil
+}
+
+// traktListProviderIDs collects the IMDB, TMDB, and TVDB IDs of list entries
+func traktListProviderIDs(items []trakt.ListItem) repository.ProviderIDs {
+	var ids repository.ProviderIDs
+	for _, item := range items {
+		switch {
+		case item.Movie != nil:
+			if item.Movie.IDs.TMDB > 0 {
+				ids.TMDB = append(ids.TMDB, int64(item.Movie.IDs.TMDB))
+			}
+			if item.Movie.IDs.IMDB != "" {
+				ids.IMDB = append(ids.IMDB, item.Movie.IDs.IMDB)
+			}
+		case item.Show != nil:
+			if item.Show.IDs.TVDB > 0 {
+				ids.TVDB = append(ids.TVDB, int64(item.Show.IDs.TVDB))
+			}
+			if item.Show.IDs.IMDB != "" {
+				ids.IMDB = append(ids.IMDB, item.Show.IDs.IMDB)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,123 @@
+package similarity
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/ollama"
+	"github.com/geekxflood/program-director/internal/logging"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// UsageRecorder collects the Ollama calls made while generating one theme, and the LLM
+// steps that fell back to another model or to heuristics
+type UsageRecorder struct {
+	// CaptureTraces also collects the exact prompt and raw response of each call
+	CaptureTraces bool
+
+	mu       sync.Mutex
+	usage    []models.LLMUsage
+	traces   []models.LLMTrace
+	degraded []string
+}
+
+type usageRecorderKey struct{}
+
+// WithUsageRecorder returns a context whose Ollama calls are collected by rec
+func WithUsageRecorder(ctx context.Context, rec *UsageRecorder) context.Context {
+	return context.WithValue(ctx, usageRecorderKey{}, rec)
+}
+
+// Usage returns the calls collected so far
+func (r *UsageRecorder) Usage() []models.LLMUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.LLMUsage(nil), r.usage...)
+}
+
+// Traces returns the prompts and responses collected so far, when CaptureTraces is set
+func (r *UsageRecorder) Traces() []models.LLMTrace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.LLMTrace(nil), r.traces...)
+}
+
+// Degraded describes the fallbacks taken so far, e.g. "refine: fallback model llama3.2:3b",
+// in the order they happened
+func (r *UsageRecorder) Degraded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.degraded...)
+}
+
+// RecordDegradation notes on the context's recorder, if it has one, that an LLM step such
+// as "refine" fell back. Repeats of the same fallback are noted once.
+func RecordDegradation(ctx context.Context, step, fallback string) {
+	rec, ok := ctx.Value(usageRecorderKey{}).(*UsageRecorder)
+	if !ok {
+		return
+	}
+
+	entry := step + ": " + fallback
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if !slices.Contains(rec.degraded, entry) {
+		rec.degraded = append(rec.degraded, entry)
+	}
+}
+
+// recordUsage adds a completed call to the context's recorder, if it has one
+func recordUsage(ctx context.Context, purpose string, resp *ollama.ChatResponse) {
+	rec, ok := ctx.Value(usageRecorderKey{}).(*UsageRecorder)
+	if !ok {
+		return
+	}
+
+	u := models.LLMUsage{
+		Purpose:              purpose,
+		Model:                resp.Model,
+		PromptTokens:         resp.PromptEvalCount,
+		EvalTokens:           resp.EvalCount,
+		TotalDurationMS:      time.Duration(resp.TotalDuration).Milliseconds(),
+		LoadDurationMS:       time.Duration(resp.LoadDuration).Milliseconds(),
+		PromptEvalDurationMS: time.Duration(resp.PromptEvalDuration).Milliseconds(),
+		EvalDurationMS:       time.Duration(resp.EvalDuration).Milliseconds(),
+		CreatedAt:            time.Now(),
+	}
+
+	rec.mu.Lock()
+	rec.usage = append(rec.usage, u)
+	rec.mu.Unlock()
+}
+
+// recordTrace adds the prompt and the response or error of a call to model to the context's
+// recorder, if it has one that captures traces
+func recordTrace(ctx context.Context, purpose, model string, messages []ollama.ChatMessage, resp *ollama.ChatResponse, err error, duration time.Duration) {
+	rec, ok := ctx.Value(usageRecorderKey{}).(*UsageRecorder)
+	if !ok || !rec.CaptureTraces {
+		return
+	}
+
+	t := models.LLMTrace{
+		Purpose:    purpose,
+		Model:      model,
+		Messages:   make(models.LLMMessages, len(messages)),
+		DurationMS: duration.Milliseconds(),
+		RequestID:  logging.RequestID(ctx),
+		CreatedAt:  time.Now(),
+	}
+	for i, m := range messages {
+		t.Messages[i] = models.LLMMessage{Role: m.Role, Content: m.Content}
+	}
+	if err != nil {
+		t.Error = err.Error()
+	} else {
+		t.Response = resp.Message.Content
+	}
+
+	rec.mu.Lock()
+	rec.traces = append(rec.traces, t)
+	rec.mu.Unlock()
+}
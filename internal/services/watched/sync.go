@@ -0,0 +1,172 @@
+// Package watched imports household watch history so recently watched titles can be avoided.
+package watched
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/tautulli"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// SourceTautulli identifies watch history imported from Tautulli
+const SourceTautulli = "tautulli"
+
+const (
+	// pageSize is the number of history rows requested per Tautulli call
+	pageSize = 500
+	// initialLookback bounds the first import when no history is stored yet
+	initialLookback = 180 * 24 * time.Hour
+)
+
+// SyncService imports watch history from Tautulli
+type SyncService struct {
+	tautulli  *tautulli.Client
+	mediaRepo *repository.MediaRepository
+	watchRepo *repository.WatchHistoryRepository
+	logger    *slog.Logger
+}
+
+// NewSyncService creates a new SyncService
+func NewSyncService(
+	tautulliClient *tautulli.Client,
+	mediaRepo *repository.MediaRepository,
+	watchRepo *repository.WatchHistoryRepository,
+	logger *slog.Logger,
+) *SyncService {
+	return &SyncService{
+		tautulli:  tautulliClient,
+		mediaRepo: mediaRepo,
+		watchRepo: watchRepo,
+		logger:    logger,
+	}
+}
+
+// SyncResult contains the results of a watch history import
+type SyncResult struct {
+	Fetched   int
+	Created   int
+	Unmatched int
+	Errors    int
+	Duration  time.Duration
+}
+
+// Sync imports history newer than the latest stored entry
+func (s *SyncService) Sync(ctx context.Context) (*SyncResult, error) {
+	start := time.Now()
+	result := &SyncResult{}
+
+	latest, err := s.watchRepo.LatestWatchedAt(ctx, SourceTautulli)
+	if err != nil {
+		return nil, err
+	}
+	after := latest
+	if after.IsZero() {
+		after = time.Now().Add(-initialLookback)
+	}
+	// Tautulli filters by date, so step back a day to catch sessions from the same day
+	after = after.AddDate(0, 0, -1)
+
+	s.logger.Info("starting watch history sync", "after", after.Format("2006-01-02"))
+
+	for offset := 0; ; offset += pageSize {
+		entries, err := s.tautulli.GetHistory(ctx, after, offset, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		result.Fetched += len(entries)
+
+		for i := range entries {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			default:
+			}
+			s.record(ctx, &entries[i], result)
+		}
+
+		if len(entries) < pageSize {
+			break
+		}
+	}
+
+	result.Duration = time.Since(start)
+	s.logger.Info("watch history sync complete",
+		"fetched", result.Fetched,
+		"created", result.Created,
+		"unmatched", result.Unmatched,
+		"errors", result.Errors,
+		"duration", result.Duration,
+	)
+
+	return result, nil
+}
+
+// record stores a single history entry, matching it to library media when possible
+func (s *SyncService) record(ctx context.Context, entry *tautulli.HistoryEntry, result *SyncResult) {
+	// Skip in-progress sessions, music, and sessions that were barely started
+	if entry.ID == 0 || entry.WatchedStatus <= 0 {
+		return
+	}
+	if entry.MediaType != "movie" && entry.MediaType != "episode" {
+		return
+	}
+
+	w := &models.WatchHistory{
+		Source:     SourceTautulli,
+		ExternalID: strconv.FormatInt(entry.ID, 10),
+		Title:      entry.Title,
+		MediaType:  models.MediaTypeMovie,
+		UserName:   entry.User,
+		WatchedAt:  entry.WatchedAt(),
+	}
+	if entry.MediaType == "episode" {
+		w.Title = entry.GrandparentTitle
+		w.MediaType = models.MediaTypeSeries
+	}
+
+	media, err := s.match(ctx, entry)
+	switch {
+	case err == nil:
+		w.MediaID = &media.ID
+		w.MediaType = media.MediaType
+	case errors.Is(err, sql.ErrNoRows):
+		result.Unmatched++
+	default:
+		s.logger.Warn("failed to match watch history", "title", w.Title, "error", err)
+		result.Errors++
+		return
+	}
+
+	created, err := s.watchRepo.CreateIfMissing(ctx, w)
+	if err != nil {
+		s.logger.Error("failed to store watch history", "title", w.Title, "error", err)
+		result.Errors++
+		return
+	}
+	if created {
+		result.Created++
+	}
+}
+
+// match finds the library media a history entry refers to
+func (s *SyncService) match(ctx context.Context, entry *tautulli.HistoryEntry) (*models.Media, error) {
+	if entry.MediaType == "episode" {
+		return s.mediaRepo.FindByTitle(ctx, entry.GrandparentTitle, 0,
+			[]models.MediaType{models.MediaTypeSeries, models.MediaTypeAnime})
+	}
+
+	if imdbID := entry.IMDBID(); imdbID != "" {
+		media, err := s.mediaRepo.FindByIMDBID(ctx, imdbID)
+		if err == nil || !errors.Is(err, sql.ErrNoRows) {
+			return media, err
+		}
+	}
+
+	return s.mediaRepo.FindByTitle(ctx, entry.Title, int(entry.Year), []models.MediaType{models.MediaTypeMovie})
+}
@@ -0,0 +1,104 @@
+package watched
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geekxflood/program-director/internal/clients/tautulli"
+	"github.com/geekxflood/program-director/internal/config"
+	"github.com/geekxflood/program-director/internal/database"
+	"github.com/geekxflood/program-director/internal/database/repository"
+	"github.com/geekxflood/program-director/pkg/models"
+)
+
+// newTestDB returns a migrated SQLite database in a temporary directory
+func newTestDB(t *testing.T) database.DB {
+	t.Helper()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := database.NewSQLite(ctx, &config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")}, logger)
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return db
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	mediaRepo := repository.NewMediaRepository(db)
+	watchRepo := repository.NewWatchHistoryRepository(db)
+
+	library := []*models.Media{
+		{ExternalID: 1, Source: models.MediaSourceRadarr, MediaType: models.MediaTypeMovie, Title: "Heat", Year: 1995, IMDBID: "tt0113277", HasFile: true},
+		{ExternalID: 2, Source: models.MediaSourceRadarr, MediaType: models.MediaTypeMovie, Title: "Ronin", Year: 1998, HasFile: true},
+		{ExternalID: 3, Source: models.MediaSourceSonarr, MediaType: models.MediaTypeSeries, Title: "Breaking Bad", HasFile: true},
+	}
+	if _, err := mediaRepo.BulkUpsert(ctx, library); err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var afters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		afters = append(afters, r.URL.Query().Get("after"))
+		mu.Unlock()
+		w.Write([]byte(`{"response": {"result": "success", "data": {"data": [
+			{"id": 11, "date": 1735900000, "user": "alex", "media_type": "movie", "title": "Heat (Director's Cut)", "year": 1995,
+			 "guid": "com.plexapp.agents.imdb://tt0113277?lang=en", "watched_status": 1},
+			{"id": 12, "date": 1735910000, "user": "sam", "media_type": "movie", "title": "Ronin", "year": 1998, "watched_status": 0.75},
+			{"id": 13, "date": 1735920000, "user": "sam", "media_type": "episode", "title": "Pilot",
+			 "grandparent_title": "Breaking Bad", "watched_status": 1},
+			{"id": 14, "date": 1735930000, "user": "alex", "media_type": "movie", "title": "Tampopo", "year": 1985, "watched_status": 1},
+			{"id": 15, "date": 1735940000, "user": "alex", "media_type": "movie", "title": "Heat", "year": 1995, "watched_status": 0},
+			{"id": 16, "date": 1735950000, "user": "alex", "media_type": "track", "title": "Heat Wave", "watched_status": 1}
+		]}}}`))
+	}))
+	defer server.Close()
+
+	svc := NewSyncService(tautulli.New(&config.TautulliConfig{URL: server.URL}), mediaRepo, watchRepo,
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	result, err := svc.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	// The unstarted session and the music track are skipped; Tampopo is not in the library
+	if result.Fetched != 6 || result.Created != 4 || result.Unmatched != 1 || result.Errors != 0 {
+		t.Errorf("Sync() = %+v, want 6 fetched, 4 created, 1 unmatched", result)
+	}
+
+	ids, err := watchRepo.RecentMediaIDs(ctx, time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("RecentMediaIDs() error = %v", err)
+	}
+	if len(ids) != 3 {
+		t.Errorf("watched media = %v, want Heat, Ronin, and Breaking Bad", ids)
+	}
+
+	// A second import only asks for history from the day before the latest session and
+	// skips what is already stored
+	result, err = svc.Sync(ctx)
+	if err != nil {
+		t.Fatalf("second Sync() error = %v", err)
+	}
+	if result.Created != 0 {
+		t.Errorf("second Sync() created %d, want 0", result.Created)
+	}
+	latest := time.Unix(1735930000, 0).AddDate(0, 0, -1).Format("2006-01-02")
+	if len(afters) != 2 || afters[1] != latest {
+		t.Errorf("Tautulli queried after %v, want %s on the second import", afters, latest)
+	}
+}
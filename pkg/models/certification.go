@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Certification levels from most to least family-friendly, shared by movie and TV ratings
+const (
+	CertificationAllAges  = iota + 1 // G, TV-Y, TV-G
+	CertificationChildren            // PG, TV-Y7, TV-PG
+	CertificationTeens               // PG-13, TV-14
+	CertificationMature              // R, TV-MA
+	CertificationAdult               // NC-17
+)
+
+// certificationLevels maps US movie and TV certifications (and common UK ones) to levels
+var certificationLevels = map[string]int{
+	"G":     CertificationAllAges,
+	"TV-Y":  CertificationAllAges,
+	"TV-G":  CertificationAllAges,
+	"U":     CertificationAllAges,
+	"PG":    CertificationChildren,
+	"TV-Y7": CertificationChildren,
+	"TV-PG": CertificationChildren,
+	"PG-13": CertificationTeens,
+	"TV-14": CertificationTeens,
+	"12A":   CertificationTeens,
+	"R":     CertificationMature,
+	"TV-MA": CertificationMature,
+	"NC-17": CertificationAdult,
+	"X":     CertificationAdult,
+}
+
+// CertificationLevel returns the level of a certification such as PG-13 or TV-MA.
+// Plain minimum ages used by other regions (e.g. "12", "FSK 16") are mapped by age.
+// It returns false for empty, unrated, or unknown certifications.
+func CertificationLevel(certification string) (int, bool) {
+	c := strings.ToUpper(strings.TrimSpace(certification))
+	if level, ok := certificationLevels[c]; ok {
+		return level, true
+	}
+
+	age, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(c, "FSK"), " "))
+	if err != nil || age < 0 {
+		return 0, false
+	}
+	switch {
+	case age <= 6:
+		return CertificationAllAges, true
+	case age <= 11:
+		return CertificationChildren, true
+	case age <= 15:
+		return CertificationTeens, true
+	case age <= 17:
+		return CertificationMature, true
+	default:
+		return CertificationAdult, true
+	}
+}
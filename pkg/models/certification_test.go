@@ -0,0 +1,35 @@
+package models
+
+import "testing"
+
+func TestCertificationLevel(t *testing.T) {
+	tests := []struct {
+		certification string
+		want          int
+		wantOK        bool
+	}{
+		{"G", CertificationAllAges, true},
+		{"tv-y", CertificationAllAges, true},
+		{"PG", CertificationChildren, true},
+		{"TV-PG", CertificationChildren, true},
+		{"PG-13", CertificationTeens, true},
+		{"TV-14", CertificationTeens, true},
+		{"R", CertificationMature, true},
+		{"TV-MA", CertificationMature, true},
+		{"NC-17", CertificationAdult, true},
+		{"FSK 16", CertificationMature, true},
+		{"12", CertificationTeens, true},
+		{"0", CertificationAllAges, true},
+		{"18", CertificationAdult, true},
+		{"", 0, false},
+		{"NR", 0, false},
+		{"Unrated", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := CertificationLevel(tt.certification)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("CertificationLevel(%q) = %d, %v; want %d, %v", tt.certification, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
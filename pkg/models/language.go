@@ -0,0 +1,41 @@
+package models
+
+import "strings"
+
+// languageCodes maps the language names Radarr/Sonarr report to the ISO 639-1 codes TMDB uses
+var languageCodes = map[string]string{
+	"arabic":     "ar",
+	"cantonese":  "cn", // TMDB's code for Cantonese
+	"chinese":    "zh",
+	"danish":     "da",
+	"dutch":      "nl",
+	"english":    "en",
+	"finnish":    "fi",
+	"french":     "fr",
+	"german":     "de",
+	"greek":      "el",
+	"hebrew":     "he",
+	"hindi":      "hi",
+	"hungarian":  "hu",
+	"indonesian": "id",
+	"italian":    "it",
+	"japanese":   "ja",
+	"korean":     "ko",
+	"norwegian":  "no",
+	"polish":     "pl",
+	"portuguese": "pt",
+	"russian":    "ru",
+	"spanish":    "es",
+	"swedish":    "sv",
+	"tamil":      "ta",
+	"telugu":     "te",
+	"thai":       "th",
+	"turkish":    "tr",
+	"vietnamese": "vi",
+}
+
+// LanguageCode returns the ISO 639-1 code for a language name such as "Korean",
+// or an empty string if the language is unknown
+func LanguageCode(name string) string {
+	return languageCodes[strings.ToLower(strings.TrimSpace(name))]
+}
@@ -2,7 +2,13 @@
 package models
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -27,10 +33,11 @@ const (
 
 // Media represents a media item in the local catalog
 type Media struct {
-	ID         int64       `json:"id" db:"id"`
-	ExternalID int64       `json:"external_id" db:"external_id"` // ID in source system (Radarr/Sonarr)
-	Source     MediaSource `json:"source" db:"source"`
-	MediaType  MediaType   `json:"media_type" db:"media_type"`
+	ID             int64       `json:"id" db:"id"`
+	ExternalID     int64       `json:"external_id" db:"external_id"` // ID in source system (Radarr/Sonarr)
+	Source         MediaSource `json:"source" db:"source"`
+	SourceInstance string      `json:"source_instance" db:"source_instance"` // Configured Radarr/Sonarr instance name
+	MediaType      MediaType   `json:"media_type" db:"media_type"`
 
 	// Basic metadata
 	Title    string `json:"title" db:"title"`
@@ -41,6 +48,12 @@ type Media struct {
 	// Genres stored as JSON array
 	Genres StringSlice `json:"genres" db:"genres"`
 
+	// Tag labels from Radarr/Sonarr, stored as JSON array
+	Tags StringSlice `json:"tags" db:"tags"`
+
+	// TMDB collection (franchise) a movie belongs to, 0 if none
+	CollectionTMDBID int64 `json:"collection_tmdb_id,omitempty" db:"collection_tmdb_id"`
+
 	// Ratings
 	IMDBRating float64 `json:"imdb_rating" db:"imdb_rating"`
 	TMDBRating float64 `json:"tmdb_rating" db:"tmdb_rating"`
@@ -55,17 +68,41 @@ type Media struct {
 	Path       string `json:"path" db:"path"`
 	HasFile    bool   `json:"has_file" db:"has_file"`
 	SizeOnDisk int64  `json:"size_on_disk" db:"size_on_disk"`
+	Resolution int    `json:"resolution" db:"resolution"` // vertical lines, e.g. 2160; 0 if unknown
+	Quality    string `json:"quality" db:"quality"`       // quality profile name, e.g. Bluray-2160p
+
+	// When the file (or series, for Sonarr) was imported into Radarr/Sonarr
+	AddedAt *time.Time `json:"added_at,omitempty" db:"added_at"`
+
+	// Artwork URLs from Radarr/Sonarr, empty if unknown
+	PosterURL string `json:"poster_url" db:"poster_url"`
+	FanartURL string `json:"fanart_url" db:"fanart_url"`
 
 	// Status
 	Status    string `json:"status" db:"status"`
 	Monitored bool   `json:"monitored" db:"monitored"`
 
+	// TMDB enrichment
+	Keywords         StringSlice `json:"keywords" db:"keywords"`
+	Certification    string      `json:"certification" db:"certification"`         // e.g. PG-13, TV-MA
+	OriginalLanguage string      `json:"original_language" db:"original_language"` // ISO 639-1, e.g. ko
+	Countries        StringSlice `json:"countries" db:"countries"`                 // ISO 3166-1 origin countries, e.g. KR
+	EnrichedAt       *time.Time  `json:"enriched_at,omitempty" db:"enriched_at"`
+
 	// Timestamps
 	SyncedAt  time.Time `json:"synced_at" db:"synced_at"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// AddedOrCreatedAt returns when the media was imported, or when it was first synced if unknown
+func (m *Media) AddedOrCreatedAt() time.Time {
+	if m.AddedAt != nil {
+		return *m.AddedAt
+	}
+	return m.CreatedAt
+}
+
 // StringSlice is a helper type for JSON arrays in the database
 type StringSlice []string
 
@@ -92,22 +129,156 @@ func (s StringSlice) Value() (interface{}, error) {
 	if s == nil {
 		return nil, nil
 	}
-	return json.Marshal(s)
+	// Stored as text so SQLite keeps it queryable as JSON instead of a BLOB
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
 }
 
+// PlaySource records how a play was observed
+type PlaySource string
+
+// Play source constants
+const (
+	PlaySourceLineup PlaySource = "lineup" // media was applied to a channel lineup
+	PlaySourcePlex   PlaySource = "plex"   // Plex reported the media actually aired
+)
+
 // PlayHistory represents a record of when media was played
 type PlayHistory struct {
-	ID        int64     `json:"id" db:"id"`
-	MediaID   int64     `json:"media_id" db:"media_id"`
-	ChannelID string    `json:"channel_id" db:"channel_id"`
-	ThemeName string    `json:"theme_name" db:"theme_name"`
-	PlayedAt  time.Time `json:"played_at" db:"played_at"`
+	ID        int64      `json:"id" db:"id"`
+	MediaID   int64      `json:"media_id" db:"media_id"`
+	ChannelID string     `json:"channel_id" db:"channel_id"`
+	ThemeName string     `json:"theme_name" db:"theme_name"`
+	PlayedAt  time.Time  `json:"played_at" db:"played_at"`
+	Source    PlaySource `json:"source" db:"source"`
 
 	// Denormalized for easy querying
 	MediaTitle string    `json:"media_title" db:"media_title"`
 	MediaType  MediaType `json:"media_type" db:"media_type"`
 }
 
+// WatchHistory records a title the household actually watched outside the channels
+type WatchHistory struct {
+	ID         int64     `json:"id" db:"id"`
+	MediaID    *int64    `json:"media_id,omitempty" db:"media_id"` // nil when no library match
+	Source     string    `json:"source" db:"source"`               // e.g. tautulli
+	ExternalID string    `json:"external_id" db:"external_id"`
+	Title      string    `json:"title" db:"title"`
+	MediaType  MediaType `json:"media_type" db:"media_type"`
+	UserName   string    `json:"user_name" db:"user_name"`
+	WatchedAt  time.Time `json:"watched_at" db:"watched_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// Generation records one playlist generation run
+type Generation struct {
+	ID         int64     `json:"id" db:"id"`
+	ThemeName  string    `json:"theme_name" db:"theme_name"`
+	ChannelID  string    `json:"channel_id" db:"channel_id"`
+	Generated  bool      `json:"generated" db:"generated"`
+	DryRun     bool      `json:"dry_run" db:"dry_run"`
+	ItemCount  int       `json:"item_count" db:"item_count"`
+	TotalScore float64   `json:"total_score" db:"total_score"`
+	DurationMS int64     `json:"duration_ms" db:"duration_ms"`
+	Error      string    `json:"error,omitempty" db:"error"`
+	SkipReason string    `json:"skip_reason,omitempty" db:"skip_reason"`
+	Attempts   int       `json:"attempts" db:"attempts"`
+	Degraded   string    `json:"degraded,omitempty" db:"degraded"` // LLM fallbacks taken, empty when none
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// BlocklistEntry keeps a title off every channel. Exactly one of MediaID and IMDBID is set.
+type BlocklistEntry struct {
+	ID        int64     `json:"id" db:"id"`
+	MediaID   *int64    `json:"media_id,omitempty" db:"media_id"`
+	IMDBID    *string   `json:"imdb_id,omitempty" db:"imdb_id"`
+	Reason    string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewBlocklistEntry builds an entry blocking either a media ID or an IMDB ID
+func NewBlocklistEntry(mediaID int64, imdbID, reason string) (*BlocklistEntry, error) {
+	switch {
+	case mediaID > 0 && imdbID != "":
+		return nil, errors.New("specify a media ID or an IMDB ID, not both")
+	case mediaID > 0:
+		return &BlocklistEntry{MediaID: &mediaID, Reason: reason}, nil
+	case imdbID != "":
+		return &BlocklistEntry{IMDBID: &imdbID, Reason: reason}, nil
+	default:
+		return nil, errors.New("a media ID or an IMDB ID is required")
+	}
+}
+
+// APIKeyRole is what an API key may do: each role may do everything the ones before it may
+type APIKeyRole string
+
+const (
+	// APIKeyRoleReadOnly may only read, with GET requests
+	APIKeyRoleReadOnly APIKeyRole = "read-only"
+	// APIKeyRoleOperator may also generate, sync, undo, and pause
+	APIKeyRoleOperator APIKeyRole = "operator"
+	// APIKeyRoleAdmin may also use /api/v1/admin routes
+	APIKeyRoleAdmin APIKeyRole = "admin"
+)
+
+// apiKeyRoleRanks orders the roles by privilege
+var apiKeyRoleRanks = map[APIKeyRole]int{
+	APIKeyRoleReadOnly: 1,
+	APIKeyRoleOperator: 2,
+	APIKeyRoleAdmin:    3,
+}
+
+// ParseAPIKeyRole validates a role name
+func ParseAPIKeyRole(s string) (APIKeyRole, error) {
+	role := APIKeyRole(s)
+	if _, ok := apiKeyRoleRanks[role]; !ok {
+		return "", fmt.Errorf("invalid role %q (must be read-only, operator, or admin)", s)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role may do what required may
+func (r APIKeyRole) Allows(required APIKeyRole) bool {
+	rank, ok := apiKeyRoleRanks[r]
+	return ok && rank >= apiKeyRoleRanks[required]
+}
+
+// APIKey is a stored HTTP API key. The key itself is only shown when created.
+type APIKey struct {
+	ID         int64      `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	Role       APIKeyRole `json:"role" db:"role"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// NewAPIKey generates a random API key and returns it with the record storing its hash. The
+// key has the admin role unless Role is changed before it is stored.
+func NewAPIKey(name string) (*APIKey, string, error) {
+	if name == "" {
+		return nil, "", errors.New("an API key name is required")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	key := "pd_" + hex.EncodeToString(b)
+
+	return &APIKey{Name: name, Role: APIKeyRoleAdmin, KeyHash: HashAPIKey(key)}, key, nil
+}
+
+// HashAPIKey returns the hex SHA-256 hash stored for an API key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
 // MediaCooldown tracks when media can be replayed
 type MediaCooldown struct {
 	ID           int64     `json:"id" db:"id"`
@@ -135,11 +306,85 @@ func (c *MediaCooldown) DaysRemaining() int {
 	return int(remaining.Hours() / 24)
 }
 
+// ChannelSnapshot stores a channel lineup captured before it was overwritten
+type ChannelSnapshot struct {
+	ID           int64      `json:"id" db:"id"`
+	ChannelID    string     `json:"channel_id" db:"channel_id"`
+	ThemeName    string     `json:"theme_name" db:"theme_name"`
+	Programming  []byte     `json:"-" db:"programming"` // raw Tunarr programming JSON
+	ProgramCount int        `json:"program_count" db:"program_count"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	RestoredAt   *time.Time `json:"restored_at,omitempty" db:"restored_at"`
+
+	// Hash of the lineup applied over this snapshot
+	LineupHash string `json:"lineup_hash,omitempty" db:"lineup_hash"`
+}
+
+// LineupItem is one title of the lineup last applied to a channel
+type LineupItem struct {
+	ID        int64     `json:"id" db:"id"`
+	ChannelID string    `json:"channel_id" db:"channel_id"`
+	ThemeName string    `json:"theme_name" db:"theme_name"`
+	Position  int       `json:"position" db:"position"`           // 1-based play order
+	MediaID   *int64    `json:"media_id,omitempty" db:"media_id"` // nil once the media is removed
+	Title     string    `json:"title" db:"title"`
+	Year      int       `json:"year" db:"year"`
+	MediaType MediaType `json:"media_type" db:"media_type"`
+	Runtime   int       `json:"runtime" db:"runtime"` // minutes
+	AirsAt    time.Time `json:"airs_at" db:"airs_at"` // Estimated next airing after the lineup was applied
+	AppliedAt time.Time `json:"applied_at" db:"applied_at"`
+}
+
+// ListSource represents where an imported list came from
+type ListSource string
+
+// List source constants
+const (
+	ListSourceMDBList ListSource = "mdblist"
+	ListSourceIMDb    ListSource = "imdb"
+)
+
+// List is a static title list imported for use as a theme filter
+type List struct {
+	ID        int64      `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Source    ListSource `json:"source" db:"source"`
+	URL       string     `json:"url" db:"url"`
+	ItemCount int        `json:"item_count" db:"item_count"`
+	SyncedAt  time.Time  `json:"synced_at" db:"synced_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ListItem is a single entry of an imported list
+type ListItem struct {
+	ID        int64     `json:"id" db:"id"`
+	ListID    int64     `json:"list_id" db:"list_id"`
+	Rank      int       `json:"rank" db:"rank"`
+	Title     string    `json:"title" db:"title"`
+	Year      int       `json:"year" db:"year"`
+	MediaType MediaType `json:"media_type" db:"media_type"`
+	IMDBID    string    `json:"imdb_id" db:"imdb_id"`
+	TMDBID    int64     `json:"tmdb_id" db:"tmdb_id"`
+	TVDBID    int64     `json:"tvdb_id" db:"tvdb_id"`
+}
+
+// Collection is a Radarr (TMDB) collection such as a franchise or trilogy
+type Collection struct {
+	ID        int64     `json:"id" db:"id"`
+	TMDBID    int64     `json:"tmdb_id" db:"tmdb_id"`
+	Title     string    `json:"title" db:"title"`
+	SyncedAt  time.Time `json:"synced_at" db:"synced_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
 // MediaWithScore represents media with a similarity/relevance score
 type MediaWithScore struct {
 	Media
 	Score       float64 `json:"score"`
 	MatchReason string  `json:"match_reason"`
+
+	// AirsAt is when the title is expected to air once its lineup is applied
+	AirsAt *time.Time `json:"airs_at,omitempty"`
 }
 
 // Channel represents a Tunarr channel
@@ -173,3 +418,169 @@ type Playlist struct {
 	TotalScore  float64          `json:"total_score"`
 	Duration    int              `json:"duration"` // Total duration in minutes
 }
+
+// SyncCheckpoint records how far an unfinished media sync of a source got
+type SyncCheckpoint struct {
+	Source    MediaSource `json:"source" db:"source"`
+	StartedAt time.Time   `json:"started_at" db:"started_at"`
+	Stored    int         `json:"stored" db:"stored"`
+	Total     int         `json:"total" db:"total"`
+	UpdatedAt time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// AutomationPause records that scheduled jobs and webhook-triggered refreshes are paused,
+// e.g. while Tunarr is under maintenance
+type AutomationPause struct {
+	Reason   string    `json:"reason,omitempty" db:"reason"`
+	PausedAt time.Time `json:"paused_at" db:"paused_at"`
+}
+
+// AuditActorType is what triggered an audited action
+type AuditActorType string
+
+const (
+	AuditActorCLI       AuditActorType = "cli"
+	AuditActorAPI       AuditActorType = "api"
+	AuditActorScheduler AuditActorType = "scheduler"
+)
+
+// Audited actions
+const (
+	AuditActionGenerate    = "generate"     // One theme
+	AuditActionGenerateAll = "generate_all" // Every theme due
+)
+
+// AuditEntry records who or what triggered a generation, and with which parameters
+type AuditEntry struct {
+	ID         int64           `json:"id" db:"id"`
+	ActorType  AuditActorType  `json:"actor_type" db:"actor_type"`
+	Actor      string          `json:"actor,omitempty" db:"actor"` // API key name, user@host of the CLI, or scheduler host
+	Action     string          `json:"action" db:"action"`
+	ThemeName  string          `json:"theme_name,omitempty" db:"theme_name"`
+	Parameters AuditParameters `json:"parameters,omitempty" db:"parameters"` // e.g. dry_run, force, themes
+	RequestID  string          `json:"request_id,omitempty" db:"request_id"` // Matches the request_id of its logs
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditParameters are the parameters of an audited action, stored as JSON
+type AuditParameters map[string]interface{}
+
+// Scan implements sql.Scanner for AuditParameters
+func (p *AuditParameters) Scan(src interface{}) error {
+	if src == nil {
+		*p = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	}
+	return json.Unmarshal(data, p)
+}
+
+// Value implements driver.Valuer for AuditParameters
+func (p AuditParameters) Value() (interface{}, error) {
+	if p == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// LLMUsage records one Ollama call made while generating a theme
+type LLMUsage struct {
+	ID                   int64     `json:"id" db:"id"`
+	GenerationID         *int64    `json:"generation_id,omitempty" db:"generation_id"` // nil when the run wasn't recorded
+	ThemeName            string    `json:"theme_name" db:"theme_name"`
+	Purpose              string    `json:"purpose" db:"purpose"` // e.g. refine, narrative_order
+	Model                string    `json:"model" db:"model"`
+	PromptTokens         int       `json:"prompt_tokens" db:"prompt_tokens"`
+	EvalTokens           int       `json:"eval_tokens" db:"eval_tokens"`
+	TotalDurationMS      int64     `json:"total_duration_ms" db:"total_duration_ms"`
+	LoadDurationMS       int64     `json:"load_duration_ms" db:"load_duration_ms"`
+	PromptEvalDurationMS int64     `json:"prompt_eval_duration_ms" db:"prompt_eval_duration_ms"`
+	EvalDurationMS       int64     `json:"eval_duration_ms" db:"eval_duration_ms"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+}
+
+// LLMTrace is the exact prompt and raw response of one Ollama call made while generating a
+// theme, recorded when llm.traces is enabled
+type LLMTrace struct {
+	ID           int64       `json:"id" db:"id"`
+	GenerationID *int64      `json:"generation_id,omitempty" db:"generation_id"` // nil when the run wasn't recorded
+	ThemeName    string      `json:"theme_name" db:"theme_name"`
+	Purpose      string      `json:"purpose" db:"purpose"` // e.g. refine, narrative_order
+	Model        string      `json:"model" db:"model"`
+	Messages     LLMMessages `json:"messages" db:"messages"`
+	Response     string      `json:"response" db:"response"`       // Raw answer, empty when the call failed
+	Error        string      `json:"error,omitempty" db:"error"`   // Why the call failed
+	DurationMS   int64       `json:"duration_ms" db:"duration_ms"` // Wall time of the call
+	RequestID    string      `json:"request_id,omitempty" db:"request_id"`
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+}
+
+// LLMMessage is one chat message sent to the LLM
+type LLMMessage struct {
+	Role    string `json:"role"` // system, user, assistant
+	Content string `json:"content"`
+}
+
+// LLMMessages are the messages of an LLM call, stored as JSON
+type LLMMessages []LLMMessage
+
+// Scan implements sql.Scanner for LLMMessages
+func (m *LLMMessages) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	}
+	return json.Unmarshal(data, m)
+}
+
+// Value implements driver.Valuer for LLMMessages
+func (m LLMMessages) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// LLMUsageTotals sums the Ollama usage of one theme
+type LLMUsageTotals struct {
+	ThemeName       string `json:"theme_name"`
+	Generations     int64  `json:"generations"` // recorded runs that called Ollama
+	Calls           int64  `json:"calls"`
+	PromptTokens    int64  `json:"prompt_tokens"`
+	EvalTokens      int64  `json:"eval_tokens"`
+	TotalDurationMS int64  `json:"total_duration_ms"`
+	EvalDurationMS  int64  `json:"eval_duration_ms"`
+}
+
+// Add sums other into t
+func (t *LLMUsageTotals) Add(other LLMUsageTotals) {
+	t.Generations += other.Generations
+	t.Calls += other.Calls
+	t.PromptTokens += other.PromptTokens
+	t.EvalTokens += other.EvalTokens
+	t.TotalDurationMS += other.TotalDurationMS
+	t.EvalDurationMS += other.EvalDurationMS
+}
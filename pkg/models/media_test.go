@@ -224,3 +224,26 @@ func TestPlayHistory_Fields(t *testing.T) {
 		t.Errorf("PlayedAt mismatch")
 	}
 }
+
+func TestAPIKeyRole(t *testing.T) {
+	if _, err := ParseAPIKeyRole("superuser"); err == nil {
+		t.Error("expected error for unknown role")
+	}
+	role, err := ParseAPIKeyRole("operator")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for required, want := range map[APIKeyRole]bool{
+		APIKeyRoleReadOnly: true,
+		APIKeyRoleOperator: true,
+		APIKeyRoleAdmin:    false,
+	} {
+		if got := role.Allows(required); got != want {
+			t.Errorf("operator allows %s = %v, want %v", required, got, want)
+		}
+	}
+	if APIKeyRole("").Allows(APIKeyRoleReadOnly) {
+		t.Error("expected an unknown role to allow nothing")
+	}
+}